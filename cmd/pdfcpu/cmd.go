@@ -115,7 +115,14 @@ func (m commandMap) process(cmdPrefix string, command string) (string, error) {
 	var cmdStr string
 
 	// Support command completion.
+	// An exact match wins over commands sharing its prefix, eg. encrypt vs. encryption.
+	if _, ok := m[cmdPrefix]; ok {
+		cmdStr = cmdPrefix
+	}
 	for k := range m {
+		if cmdStr == cmdPrefix {
+			break
+		}
 		if !strings.HasPrefix(k, cmdPrefix) {
 			continue
 		}
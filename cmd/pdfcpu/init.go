@@ -25,6 +25,7 @@ import (
 func initCommandMap() {
 	annotsCmdMap := newCommandMap()
 	for k, v := range map[string]command{
+		"add":    {processAddAnnotationsCommand, nil, "", ""},
 		"list":   {processListAnnotationsCommand, nil, "", ""},
 		"remove": {processRemoveAnnotationsCommand, nil, "", ""},
 	} {
@@ -67,6 +68,13 @@ func initCommandMap() {
 		portfolioCmdMap.register(k, v)
 	}
 
+	encryptionCmdMap := newCommandMap()
+	for k, v := range map[string]command{
+		"info": {processEncryptionInfoCommand, nil, "", ""},
+	} {
+		encryptionCmdMap.register(k, v)
+	}
+
 	permissionsCmdMap := newCommandMap()
 	for k, v := range map[string]command{
 		"list": {processListPermissionsCommand, nil, "", ""},
@@ -110,6 +118,15 @@ func initCommandMap() {
 		fontsCmdMap.register(k, v)
 	}
 
+	formCmdMap := newCommandMap()
+	for k, v := range map[string]command{
+		"list":   {processListFormFieldsCommand, nil, "", ""},
+		"export": {processExportFormFieldsCommand, nil, "", ""},
+		"fill":   {processFillFormCommand, nil, "", ""},
+	} {
+		formCmdMap.register(k, v)
+	}
+
 	keywordsCmdMap := newCommandMap()
 	for k, v := range map[string]command{
 		"list":   {processListKeywordsCommand, nil, "", ""},
@@ -133,36 +150,53 @@ func initCommandMap() {
 	for k, v := range map[string]command{
 		"annotations":   {nil, annotsCmdMap, usageAnnots, usageLongAnnots},
 		"attachments":   {nil, attachCmdMap, usageAttach, usageLongAttach},
+		"autocrop":      {processAutoCropCommand, nil, usageAutoCrop, usageLongAutoCrop},
 		"booklet":       {processBookletCommand, nil, usageBooklet, usageLongBooklet},
 		"boxes":         {nil, boxesCmdMap, usageBoxes, usageLongBoxes},
 		"changeopw":     {processChangeOwnerPasswordCommand, nil, usageChangeOwnerPW, usageLongChangeUserPW},
 		"changeupw":     {processChangeUserPasswordCommand, nil, usageChangeUserPW, usageLongChangeUserPW},
 		"collect":       {processCollectCommand, nil, usageCollect, usageLongCollect},
+		"clip":          {processClipCommand, nil, usageClip, usageLongClip},
 		"crop":          {processCropCommand, nil, usageCrop, usageLongCrop},
+		"decorate":      {processDecorateCommand, nil, usageDecorate, usageLongDecorate},
 		"decrypt":       {processDecryptCommand, nil, usageDecrypt, usageLongDecrypt},
+		"duplicates":    {processDuplicatesCommand, nil, usageDuplicates, usageLongDuplicates},
 		"encrypt":       {processEncryptCommand, nil, usageEncrypt, usageLongEncrypt},
+		"encryption":    {nil, encryptionCmdMap, usageEncryption, usageLongEncryption},
 		"extract":       {processExtractCommand, nil, usageExtract, usageLongExtract},
+		"features":      {processFeaturesCommand, nil, usageFeatures, usageLongFeatures},
 		"fonts":         {nil, fontsCmdMap, usageFonts, usageLongFonts},
+		"form":          {nil, formCmdMap, usageForm, usageLongForm},
 		"grid":          {processGridCommand, nil, usageGrid, usageLongGrid},
 		"help":          {printHelp, nil, "", ""},
 		"images":        {nil, imagesCmdMap, usageImages, usageLongImages},
 		"import":        {processImportImagesCommand, nil, usageImportImages, usageLongImportImages},
 		"info":          {processInfoCommand, nil, usageInfo, usageLongInfo},
+		"internalize":   {processInternalizeCommand, nil, usageInternalize, usageLongInternalize},
 		"keywords":      {nil, keywordsCmdMap, usageKeywords, usageLongKeywords},
+		"labels":        {processLabelsCommand, nil, usageLabels, usageLongLabels},
+		"manifest":      {processManifestCommand, nil, usageManifest, usageLongManifest},
 		"merge":         {processMergeCommand, nil, usageMerge, usageLongMerge},
 		"nup":           {processNUpCommand, nil, usageNUp, usageLongNUp},
 		"optimize":      {processOptimizeCommand, nil, usageOptimize, usageLongOptimize},
+		"orphans":       {processOrphansCommand, nil, usageOrphans, usageLongOrphans},
 		"pages":         {nil, pagesCmdMap, usagePages, usageLongPages},
 		"paper":         {printPaperSizes, nil, usagePaper, usageLongPaper},
 		"permissions":   {nil, permissionsCmdMap, usagePerm, usageLongPerm},
 		"portfolio":     {nil, portfolioCmdMap, usagePortfolio, usageLongPortfolio},
 		"properties":    {nil, propertiesCmdMap, usageProperties, usageLongProperties},
+		"resize":        {processResizeCommand, nil, usageResize, usageLongResize},
 		"rotate":        {processRotateCommand, nil, usageRotate, usageLongRotate},
+		"run":           {processRunCommand, nil, usageRun, usageLongRun},
 		"selectedpages": {printSelectedPages, nil, usageSelectedPages, usageLongSelectedPages},
+		"signatures":    {processValidateSignaturesCommand, nil, usageSignatures, usageLongSignatures},
+		"sizes":         {processPageSizesCommand, nil, usageSizes, usageLongSizes},
 		"split":         {processSplitCommand, nil, usageSplit, usageLongSplit},
 		"stamp":         {nil, stampCmdMap, usageStamp, usageLongStamp},
+		"template":      {processFillTemplateCommand, nil, usageTemplate, usageLongTemplate},
 		"trim":          {processTrimCommand, nil, usageTrim, usageLongTrim},
 		"validate":      {processValidateCommand, nil, usageValidate, usageLongValidate},
+		"verify":        {processVerifyCommand, nil, usageVerify, usageLongVerify},
 		"watermark":     {nil, watermarkCmdMap, usageWatermark, usageLongWatermark},
 		"version":       {printVersion, nil, usageVersion, usageLongVersion},
 	} {
@@ -174,7 +208,7 @@ func initFlags() {
 	statsUsage := "optimize: create a csv file for stats"
 	flag.StringVar(&fileStats, "stats", "", statsUsage)
 
-	modeUsage := "validate: strict|relaxed; extract: image|font|content|page|meta; encrypt: rc4|aes, stamp:text|image/pdf"
+	modeUsage := "validate: strict|relaxed|pedantic; extract: image|font|content|page|meta; encrypt: rc4|aes, stamp:text|image/pdf, rotate: page|content"
 	flag.StringVar(&mode, "mode", "", modeUsage)
 	flag.StringVar(&mode, "m", "", modeUsage)
 
@@ -182,8 +216,8 @@ func initFlags() {
 	flag.StringVar(&key, "key", "256", keyUsage)
 	flag.StringVar(&key, "k", "256", keyUsage)
 
-	permUsage := "encrypt, perm set: none|all"
-	flag.StringVar(&perm, "perm", "none", permUsage)
+	permUsage := "encrypt, perm set: none|all|print,modify,copy,annotate,forms,assemble"
+	flag.StringVar(&perm, "perm", "", permUsage)
 
 	unitUsage := "info: po|in|cm|mm"
 	flag.StringVar(&unit, "unit", "", unitUsage)
@@ -196,6 +230,15 @@ func initFlags() {
 	flag.BoolVar(&quiet, "quiet", false, "")
 	flag.BoolVar(&quiet, "q", false, "")
 
+	metricsUsage := "print operation timings, bytes read/written, objects parsed and cache hits"
+	flag.BoolVar(&metrics, "metrics", false, metricsUsage)
+
+	outlinesUsage := "merge: nested|flat|files|none"
+	flag.StringVar(&outlines, "outlines", "", outlinesUsage)
+
+	flag.BoolVar(&stampNames, "stampnames", false, "merge: stamp pages with the name of their file")
+	flag.BoolVar(&keepJS, "javascript", false, "merge: keep document level JavaScript of all files")
+
 	sortUsage := "sort files before merging"
 	flag.BoolVar(&sorted, "sort", false, sortUsage)
 	flag.BoolVar(&sorted, "s", false, sortUsage)
@@ -208,6 +251,29 @@ func initFlags() {
 	flag.BoolVar(&links, "links", false, linksUsage)
 	flag.BoolVar(&links, "l", false, linksUsage)
 
+	warningsUsage := "info: list non fatal anomalies found while reading"
+	flag.BoolVar(&warnings, "warnings", false, warningsUsage)
+	flag.BoolVar(&warnings, "w", false, warningsUsage)
+
+	jsonUsage := "annotations list, encryption info, extract tables, duplicates, features, form list, orphans, signatures, sizes, validate: output JSON"
+	flag.BoolVar(&jsonOut, "json", false, jsonUsage)
+	flag.BoolVar(&jsonOut, "j", false, jsonUsage)
+
+	dryRunUsage := "report what would change without writing anything"
+	flag.BoolVar(&dryRun, "dry-run", false, dryRunUsage)
+
+	backupUsage := "keep a copy of the original as .bak when writing in place"
+	flag.BoolVar(&backup, "backup", false, backupUsage)
+
+	incrementalUsage := "append changes as incremental update keeping the original bytes untouched"
+	flag.BoolVar(&incremental, "incremental", false, incrementalUsage)
+	flag.BoolVar(&incremental, "incr", false, incrementalUsage)
+
+	needAppearanceUsage := "form fill: leave the rendering of filled in fields to the viewer"
+	flag.BoolVar(&needAppearance, "needappearance", false, needAppearanceUsage)
+
+	flag.StringVar(&manifestFile, "manifest", "", "verify: the manifest file to check against")
+
 	flag.StringVar(&upw, "upw", "", "user password")
 	flag.StringVar(&opw, "opw", "", "owner password")
 
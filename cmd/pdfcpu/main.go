@@ -25,8 +25,12 @@ import (
 var (
 	fileStats, mode, selectedPages  string
 	upw, opw, key, perm, unit, conf string
+	manifestFile, outlines          string
 	verbose, veryVerbose            bool
-	links, quiet, sorted            bool
+	links, quiet, sorted, metrics   bool
+	warnings, jsonOut, dryRun       bool
+	backup, needAppearance          bool
+	incremental, stampNames, keepJS bool
 	needStackTrace                  = true
 	cmdMap                          commandMap
 )
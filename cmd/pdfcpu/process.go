@@ -87,9 +87,31 @@ func printVersion(conf *pdfcpu.Configuration) {
 	}
 }
 
+type metricsPrinter struct{}
+
+func (metricsPrinter) OperationCompleted(m pdfcpu.Metrics) {
+	fmt.Fprintln(os.Stdout, m)
+}
+
 func process(cmd *cli.Command) {
+	if metrics {
+		pdfcpu.SubscribeMetrics(metricsPrinter{})
+	}
+
+	cmd.DryRun = dryRun
+	if backup && cmd.Conf != nil {
+		cmd.Conf.BackupInPlace = true
+	}
+	if incremental && cmd.Conf != nil {
+		cmd.Conf.Incremental = true
+	}
+
 	out, err := cli.Process(cmd)
 	if err != nil {
+		// Reports explaining the error come first.
+		for _, s := range out {
+			fmt.Fprintln(os.Stdout, s)
+		}
 		if needStackTrace {
 			fmt.Fprintf(os.Stderr, "Fatal: %+v\n", err)
 		} else {
@@ -114,7 +136,7 @@ func processValidateCommand(conf *pdfcpu.Configuration) {
 	inFile := flag.Arg(0)
 	ensurePdfExtension(inFile)
 
-	if mode != "" && mode != "strict" && mode != "s" && mode != "relaxed" && mode != "r" {
+	if mode != "" && mode != "strict" && mode != "s" && mode != "relaxed" && mode != "r" && mode != "pedantic" && mode != "p" {
 		fmt.Fprintf(os.Stderr, "%s\n\n", usageValidate)
 		os.Exit(1)
 	}
@@ -124,13 +146,17 @@ func processValidateCommand(conf *pdfcpu.Configuration) {
 		conf.ValidationMode = pdfcpu.ValidationStrict
 	case "relaxed", "r":
 		conf.ValidationMode = pdfcpu.ValidationRelaxed
+	case "pedantic", "p":
+		conf.ValidationMode = pdfcpu.ValidationPedantic
 	}
 
 	if links {
 		conf.ValidateLinks = true
 	}
 
-	process(cli.ValidateCommand(inFile, conf))
+	cmd := cli.ValidateCommand(inFile, conf)
+	cmd.JSON = jsonOut
+	process(cmd)
 }
 
 func processOptimizeCommand(conf *pdfcpu.Configuration) {
@@ -203,6 +229,25 @@ func processMergeCommand(conf *pdfcpu.Configuration) {
 		os.Exit(1)
 	}
 
+	if outlines == "" {
+		outlines = "none"
+	}
+	switch extractModeCompletion(outlines, []string{"nested", "flat", "files", "none"}) {
+	case "nested":
+		conf.MergeOutlines = pdfcpu.MergeOutlinesNested
+	case "flat":
+		conf.MergeOutlines = pdfcpu.MergeOutlinesFlat
+	case "files":
+		conf.MergeOutlines = pdfcpu.MergeOutlinesFiles
+	case "none":
+		conf.MergeOutlines = pdfcpu.MergeOutlinesNone
+	default:
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageMerge)
+		os.Exit(1)
+	}
+	conf.MergeStampFileNames = stampNames
+	conf.MergeJavaScript = keepJS
+
 	filesIn := []string{}
 	outFile := ""
 	for i, arg := range flag.Args() {
@@ -260,7 +305,7 @@ func extractModeCompletion(modePrefix string, modes []string) string {
 }
 
 func processExtractCommand(conf *pdfcpu.Configuration) {
-	mode = extractModeCompletion(mode, []string{"image", "font", "page", "content", "meta"})
+	mode = extractModeCompletion(mode, []string{"image", "font", "page", "content", "text", "table", "svg", "meta"})
 	if len(flag.Args()) != 2 || mode == "" {
 		fmt.Fprintf(os.Stderr, "%s\n\n", usageExtract)
 		os.Exit(1)
@@ -292,6 +337,15 @@ func processExtractCommand(conf *pdfcpu.Configuration) {
 	case "content":
 		cmd = cli.ExtractContentCommand(inFile, outDir, pages, conf)
 
+	case "text":
+		cmd = cli.ExtractTextCommand(inFile, outDir, pages, conf)
+
+	case "table":
+		cmd = cli.ExtractTablesCommand(inFile, outDir, pages, jsonOut, conf)
+
+	case "svg":
+		cmd = cli.ExtractSVGCommand(inFile, outDir, pages, conf)
+
 	case "meta":
 		cmd = cli.ExtractMetadataCommand(inFile, outDir, conf)
 
@@ -458,6 +512,18 @@ func processListPermissionsCommand(conf *pdfcpu.Configuration) {
 	process(cli.ListPermissionsCommand(inFile, conf))
 }
 
+func processEncryptionInfoCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageEncryptionInfo)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	ensurePdfExtension(inFile)
+
+	process(cli.EncryptionInfoCommand(inFile, jsonOut, conf))
+}
+
 func permCompletion(permPrefix string) string {
 	var permStr string
 	for _, perm := range []string{"none", "all"} {
@@ -473,23 +539,36 @@ func permCompletion(permPrefix string) string {
 	return permStr
 }
 
-func processSetPermissionsCommand(conf *pdfcpu.Configuration) {
-	if perm != "" {
-		perm = permCompletion(perm)
+// parsePermFlag sets conf.Permissions for the -perm flag if present.
+func parsePermFlag(conf *pdfcpu.Configuration) error {
+	if perm == "" {
+		return nil
 	}
-	if len(flag.Args()) != 1 || selectedPages != "" ||
-		!(perm == "none" || perm == "all") {
+	if s := permCompletion(perm); s != "" {
+		perm = s
+	}
+	p, err := pdfcpu.ParsePermissions(perm)
+	if err != nil {
+		return err
+	}
+	conf.Permissions = p
+	return nil
+}
+
+func processSetPermissionsCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
 		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usagePermSet)
 		os.Exit(1)
 	}
 
+	if err := parsePermFlag(conf); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n\n", err)
+		os.Exit(1)
+	}
+
 	inFile := flag.Arg(0)
 	ensurePdfExtension(inFile)
 
-	if perm == "all" {
-		conf.Permissions = pdfcpu.PermissionsAll
-	}
-
 	process(cli.SetPermissionsCommand(inFile, "", conf))
 }
 
@@ -542,10 +621,10 @@ func validateEncryptModeFlag() {
 
 }
 
-func validateEncryptFlags() {
+func validateEncryptFlags(conf *pdfcpu.Configuration) {
 	validateEncryptModeFlag()
-	if perm != "none" && perm != "all" && perm != "" {
-		fmt.Fprintf(os.Stderr, "%s\n\n", "supported permissions: none,all default:none (viewing always allowed!)")
+	if err := parsePermFlag(conf); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n%s\n\n", err, "default: none (viewing always allowed!)")
 		os.Exit(1)
 	}
 }
@@ -562,17 +641,13 @@ func processEncryptCommand(conf *pdfcpu.Configuration) {
 		os.Exit(1)
 	}
 
-	validateEncryptFlags()
+	validateEncryptFlags(conf)
 
 	conf.EncryptUsingAES = mode != "rc4"
 
 	kl, _ := strconv.Atoi(key)
 	conf.EncryptKeyLength = kl
 
-	if perm == "all" {
-		conf.Permissions = pdfcpu.PermissionsAll
-	}
-
 	inFile := flag.Arg(0)
 	ensurePdfExtension(inFile)
 
@@ -932,7 +1007,11 @@ func abs(i int) int {
 }
 
 func processRotateCommand(conf *pdfcpu.Configuration) {
-	if len(flag.Args()) < 2 || len(flag.Args()) > 3 {
+	if mode == "" {
+		mode = "page"
+	}
+	mode = extractModeCompletion(mode, []string{"page", "content"})
+	if mode == "" || len(flag.Args()) < 2 || len(flag.Args()) > 3 {
 		fmt.Fprintf(os.Stderr, "%s\n\n", usageRotate)
 		os.Exit(1)
 	}
@@ -958,6 +1037,11 @@ func processRotateCommand(conf *pdfcpu.Configuration) {
 		os.Exit(1)
 	}
 
+	if mode == "content" {
+		process(cli.RotateContentCommand(inFile, outFile, rotation, selectedPages, conf))
+		return
+	}
+
 	process(cli.RotateCommand(inFile, outFile, rotation, selectedPages, conf))
 }
 
@@ -1176,9 +1260,53 @@ func processInfoCommand(conf *pdfcpu.Configuration) {
 
 	processDiplayUnit(conf)
 
+	conf.ListWarnings = warnings
+
 	process(cli.InfoCommand(inFile, selectedPages, conf))
 }
 
+func processManifestCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) != 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageManifest)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	ensurePdfExtension(inFile)
+
+	process(cli.ManifestCommand(inFile, flag.Arg(1), conf))
+}
+
+func processVerifyCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) != 1 || manifestFile == "" || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageVerify)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	ensurePdfExtension(inFile)
+
+	process(cli.VerifyCommand(inFile, manifestFile, conf))
+}
+
+func processInternalizeCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageInternalize)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	ensurePdfExtension(inFile)
+
+	outFile := inFile
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
+		ensurePdfExtension(outFile)
+	}
+
+	process(cli.InternalizeCommand(inFile, outFile, conf))
+}
+
 func processListFontsCommand(conf *pdfcpu.Configuration) {
 	process(cli.ListFontsCommand(conf))
 }
@@ -1210,6 +1338,167 @@ func processCreateCheatSheetFontsCommand(conf *pdfcpu.Configuration) {
 	process(cli.CreateCheatSheetsFontsCommand(fileNames, conf))
 }
 
+func processListFormFieldsCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageFormList)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	ensurePdfExtension(inFile)
+	process(cli.ListFormFieldsCommand(inFile, jsonOut, conf))
+}
+
+func processExportFormFieldsCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageFormExport)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	ensurePdfExtension(inFile)
+
+	jsonFile := strings.TrimSuffix(inFile, filepath.Ext(inFile)) + ".json"
+	if len(flag.Args()) == 2 {
+		jsonFile = flag.Arg(1)
+	}
+
+	process(cli.ExportFormFieldsCommand(inFile, jsonFile, conf))
+}
+
+func processFillFormCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) < 2 || len(flag.Args()) > 3 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageFormFill)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	ensurePdfExtension(inFile)
+
+	outFile := inFile
+	if len(flag.Args()) == 3 {
+		outFile = flag.Arg(2)
+		ensurePdfExtension(outFile)
+	}
+
+	process(cli.FillFormCommand(inFile, flag.Arg(1), outFile, needAppearance, conf))
+}
+
+func processFillTemplateCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) < 3 || len(flag.Args()) > 4 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageTemplate)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	ensurePdfExtension(inFile)
+
+	outDir := "."
+	if len(flag.Args()) == 4 {
+		outDir = flag.Arg(3)
+	}
+
+	process(cli.FillTemplateCommand(inFile, flag.Arg(1), flag.Arg(2), outDir, conf))
+}
+
+func processPageSizesCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) != 1 {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageSizes)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	ensurePdfExtension(inFile)
+
+	selectedPages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	process(cli.PageSizesCommand(inFile, selectedPages, jsonOut, conf))
+}
+
+func processOrphansCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageOrphans)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	ensurePdfExtension(inFile)
+
+	process(cli.OrphansCommand(inFile, jsonOut, conf))
+}
+
+func processResizeCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) < 1 || len(flag.Args()) > 3 {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageResize)
+		os.Exit(1)
+	}
+
+	processDiplayUnit(conf)
+
+	selectedPages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	res := pdfcpu.DefaultResizeConfig()
+	res.InpUnit = conf.Unit
+	argInd := 0
+
+	if !hasPdfExtension(flag.Arg(0)) {
+		// pdfcpu resize description inFile [outFile]
+		if err = pdfcpu.ParseResizeDetails(flag.Arg(0), res); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		argInd = 1
+	} // else first argument is inFile.
+
+	if len(flag.Args()) <= argInd {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageResize)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(argInd)
+	ensurePdfExtension(inFile)
+
+	outFile := ""
+	if len(flag.Args()) == argInd+2 {
+		outFile = flag.Arg(argInd + 1)
+		ensurePdfExtension(outFile)
+	}
+
+	process(cli.ResizeCommand(inFile, outFile, selectedPages, res, conf))
+}
+
+func processFeaturesCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageFeatures)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	ensurePdfExtension(inFile)
+
+	process(cli.FeaturesCommand(inFile, jsonOut, conf))
+}
+
+func processValidateSignaturesCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) < 1 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageSignatures)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	ensurePdfExtension(inFile)
+
+	process(cli.ValidateSignaturesCommand(inFile, flag.Arg(1), jsonOut, conf))
+}
+
 func processListKeywordsCommand(conf *pdfcpu.Configuration) {
 	if len(flag.Args()) != 1 || selectedPages != "" {
 		fmt.Fprintf(os.Stderr, "usage: %s\n", usageKeywordsList)
@@ -1504,8 +1793,43 @@ func processListAnnotationsCommand(conf *pdfcpu.Configuration) {
 		os.Exit(1)
 	}
 
-	process(cli.ListAnnotationsCommand(inFile, selectedPages, conf))
+	cmd := cli.ListAnnotationsCommand(inFile, selectedPages, conf)
+	cmd.JSON = jsonOut
+	process(cmd)
 }
+
+func processAddAnnotationsCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) < 2 || len(flag.Args()) > 3 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageAnnotsAdd)
+		os.Exit(1)
+	}
+
+	processDiplayUnit(conf)
+
+	selectedPages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	ar, err := pdfcpu.ParseAnnotationDetails(flag.Arg(0), conf.Unit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(1)
+	ensurePdfExtension(inFile)
+
+	outFile := ""
+	if len(flag.Args()) == 3 {
+		outFile = flag.Arg(2)
+		ensurePdfExtension(outFile)
+	}
+
+	process(cli.AddAnnotationsCommand(inFile, outFile, selectedPages, ar, conf))
+}
+
 func processRemoveAnnotationsCommand(conf *pdfcpu.Configuration) {
 	if len(flag.Args()) < 1 {
 		fmt.Fprintf(os.Stderr, "usage: %s\n", usageAnnotsRemove)
@@ -1520,6 +1844,7 @@ func processRemoveAnnotationsCommand(conf *pdfcpu.Configuration) {
 
 	inFile := ""
 	objNrs := []int{}
+	types := []string{}
 
 	for i, arg := range flag.Args() {
 		if i == 0 {
@@ -1529,12 +1854,26 @@ func processRemoveAnnotationsCommand(conf *pdfcpu.Configuration) {
 		}
 		i, err := strconv.Atoi(arg)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, "objNr has to be a positiv numeric value")
-			os.Exit(1)
+			if _, err := pdfcpu.ParseAnnotationType(arg); err != nil {
+				fmt.Fprintln(os.Stderr, "please provide positive object numbers or annotation types")
+				os.Exit(1)
+			}
+			types = append(types, arg)
+			continue
 		}
 		objNrs = append(objNrs, i)
 	}
 
+	if len(objNrs) > 0 && len(types) > 0 {
+		fmt.Fprintln(os.Stderr, "please provide either object numbers or annotation types")
+		os.Exit(1)
+	}
+
+	if len(types) > 0 {
+		process(cli.RemoveAnnotationsByTypeCommand(inFile, "", selectedPages, types, conf))
+		return
+	}
+
 	process(cli.RemoveAnnotationsCommand(inFile, "", selectedPages, objNrs, conf))
 }
 
@@ -1555,3 +1894,229 @@ func processListImagesCommand(conf *pdfcpu.Configuration) {
 
 	process(cli.ListImagesCommand(inFile, selectedPages, conf))
 }
+
+func processClipCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) < 1 || len(flag.Args()) > 3 {
+		fmt.Fprintf(os.Stderr, "%s\n", usageClip)
+		os.Exit(1)
+	}
+
+	processDiplayUnit(conf)
+
+	box, err := api.Box(flag.Arg(0), conf.Unit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem parsing region definition: %v\n", err)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(1)
+	ensurePdfExtension(inFile)
+
+	outFile := ""
+	if len(flag.Args()) == 3 {
+		outFile = flag.Arg(2)
+		ensurePdfExtension(outFile)
+	}
+
+	selectedPages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	process(cli.ClipCommand(inFile, outFile, selectedPages, box, conf))
+}
+
+func processLabelsCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) < 4 || len(flag.Args()) > 5 {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageLabels)
+		os.Exit(1)
+	}
+
+	processDiplayUnit(conf)
+
+	pages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	lbl := pdfcpu.DefaultLabelsConfig()
+	lbl.InpUnit = conf.Unit
+	argInd := 1
+
+	outFile := flag.Arg(0)
+	if !hasPdfExtension(outFile) {
+		// pdfcpu labels description outFile m n inFile
+		if err = pdfcpu.ParseLabelsDetails(flag.Arg(0), lbl); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		outFile = flag.Arg(1)
+		ensurePdfExtension(outFile)
+		argInd = 2
+	} // else first argument is outFile.
+
+	// pdfcpu labels outFile m n inFile
+	// If no optional 'description' argument provided use default labels configuration.
+
+	if len(flag.Args()) != argInd+3 {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageLabels)
+		os.Exit(1)
+	}
+
+	cols, err := strconv.Atoi(flag.Arg(argInd))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+	rows, err := strconv.Atoi(flag.Arg(argInd + 1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+	if err = pdfcpu.ParseLabelsGridDefinition(cols, rows, lbl); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(argInd + 2)
+	ensurePdfExtension(inFile)
+
+	process(cli.LabelsCommand(inFile, outFile, pages, lbl, conf))
+}
+
+func processDecorateCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) < 2 || len(flag.Args()) > 3 {
+		fmt.Fprintf(os.Stderr, "%s\n", usageDecorate)
+		os.Exit(1)
+	}
+
+	processDiplayUnit(conf)
+
+	dec, err := pdfcpu.ParseDecorationDetails(flag.Arg(0), conf.Unit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(1)
+	ensurePdfExtension(inFile)
+
+	outFile := ""
+	if len(flag.Args()) == 3 {
+		outFile = flag.Arg(2)
+		ensurePdfExtension(outFile)
+	}
+
+	selectedPages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	process(cli.DecorateCommand(inFile, outFile, selectedPages, dec, conf))
+}
+
+func processAutoCropCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) < 1 || len(flag.Args()) > 3 {
+		fmt.Fprintf(os.Stderr, "%s\n", usageAutoCrop)
+		os.Exit(1)
+	}
+
+	processDiplayUnit(conf)
+
+	pad := &pdfcpu.Padding{}
+	argInd := 0
+
+	if !hasPdfExtension(flag.Arg(0)) {
+		// pdfcpu autocrop padding inFile [outFile]
+		var err error
+		if pad, err = api.Padding(flag.Arg(0), conf.Unit); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		argInd = 1
+	}
+
+	if len(flag.Args()) <= argInd || len(flag.Args()) > argInd+2 {
+		fmt.Fprintf(os.Stderr, "%s\n", usageAutoCrop)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(argInd)
+	ensurePdfExtension(inFile)
+
+	outFile := ""
+	if len(flag.Args()) == argInd+2 {
+		outFile = flag.Arg(argInd + 1)
+		ensurePdfExtension(outFile)
+	}
+
+	selectedPages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	process(cli.AutoCropCommand(inFile, outFile, selectedPages, pad, conf))
+}
+
+func processDuplicatesCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) < 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n", usageDuplicates)
+		os.Exit(1)
+	}
+
+	threshold := pdfcpu.DefaultDuplicateThreshold
+	argInd := 0
+
+	if !hasPdfExtension(flag.Arg(0)) {
+		// pdfcpu duplicates threshold inFile...
+		f, err := strconv.ParseFloat(flag.Arg(0), 64)
+		if err != nil || f <= 0 || f > 1 {
+			fmt.Fprintf(os.Stderr, "invalid threshold: %s, must be a number in (0,1]\n", flag.Arg(0))
+			os.Exit(1)
+		}
+		threshold = f
+		argInd = 1
+	}
+
+	if len(flag.Args()) <= argInd {
+		fmt.Fprintf(os.Stderr, "%s\n", usageDuplicates)
+		os.Exit(1)
+	}
+
+	inFiles := flag.Args()[argInd:]
+	for _, inFile := range inFiles {
+		ensurePdfExtension(inFile)
+	}
+
+	process(cli.DuplicatesCommand(inFiles, threshold, jsonOut, conf))
+}
+
+func processRunCommand(conf *pdfcpu.Configuration) {
+	if len(flag.Args()) == 0 {
+		// pdfcpu run
+		process(cli.RunCommand("", "", "", conf))
+		return
+	}
+
+	if len(flag.Args()) < 2 || len(flag.Args()) > 3 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n", usageRun)
+		os.Exit(1)
+	}
+
+	name := flag.Arg(0)
+
+	inFile := flag.Arg(1)
+	ensurePdfExtension(inFile)
+
+	outFile := ""
+	if len(flag.Args()) == 3 {
+		outFile = flag.Arg(2)
+		ensurePdfExtension(outFile)
+	}
+
+	process(cli.RunCommand(name, inFile, outFile, conf))
+}
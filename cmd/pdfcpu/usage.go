@@ -25,37 +25,54 @@ Usage:
    
 The commands are:
 
-   annotations   list, remove page annotations
+   annotations   list, add, remove page annotations
    attachments   list, add, remove, extract embedded file attachments
+   autocrop      crop selected pages to their content
    booklet       arrange pages onto larger sheets of paper to make a booklet or zine
    boxes         list, add, remove page boundaries for selected pages
    changeopw     change owner password
    changeupw     change user password
+   clip          extract a region of selected pages into new pages
    collect       create custom sequence of selected pages
    crop          set cropbox for selected pages
+   decorate      add page background and page border
    decrypt       remove password protection
+   duplicates    report duplicate and near-duplicate pages
    encrypt       set password protection		
-   extract       extract images, fonts, content, pages or metadata
+   encryption    print security handler, algorithms, key length and permissions
+   extract       extract images, fonts, content, text, tables, pages or metadata
+   features      report PDF features affecting viewer compatibility
    fonts         install, list supported fonts, create cheat sheets
+   form          list, export, fill form fields
    grid          rearrange pages or images for enhanced browsing experience
    images        list images for selected pages
    import        import/convert images to PDF
    info          print file info
+   internalize   embed external stream data
    keywords      list, add, remove keywords
+   labels        repeat a page onto a grid of labels
+   manifest      create manifest for archival integrity checks
    merge         concatenate PDFs
    nup           rearrange pages or images for reduced number of pages
    optimize      optimize PDF by getting rid of redundant page resources
+   orphans       report objects unreachable from the trailer
    pages         insert, remove selected pages
    paper         print list of supported paper sizes
    permissions   list, set user access permissions
    portfolio     list, add, remove, extract portfolio entries with optional description
    properties    list, add, remove document properties
+   resize        scale selected pages to a paper size
    rotate        rotate pages
+   run           apply a registered custom operation
    selectedpages print definition of the -pages flag
+   signatures    validate digital signatures
+   sizes         report the bytes attributed to each page
    split         split up a PDF by span or bookmark
    stamp         add, remove, update Unicode text, image or PDF stamps for selected pages
+   template      fill named page regions with text or images per data record
    trim          create trimmed version of selected pages
    validate      validate PDF against PDF 32000-1:2008 (PDF 1.7)
+   verify        verify PDF against manifest
    version       print version
    watermark     add, remove, update Unicode text, image or PDF watermarks for selected pages
 
@@ -69,6 +86,10 @@ Use "pdfcpu help [command]" for more information about a command.`
 common flags: -v(erbose)  ... turn on logging
               -vv         ... verbose logging
               -q(uiet)    ... disable output
+              -metrics    ... print operation metrics
+              -dry-run    ... report what would change without writing anything
+              -backup     ... keep a copy of the original as .bak when writing in place
+              -incr       ... append changes as incremental update keeping the original bytes untouched
               -c(onf)     ... set or disable config dir: $path|disable
               -opw        ... owner password
               -upw        ... user password
@@ -77,18 +98,26 @@ common flags: -v(erbose)  ... turn on logging
                                                   cm ... centimetres
                                                   mm ... millimetres`
 
-	usageValidate = "usage: pdfcpu validate [-m(ode) strict|relaxed] [-l(inks)] inFile" + generalFlags
+	usageValidate = "usage: pdfcpu validate [-m(ode) strict|relaxed|pedantic] [-l(inks)] [-j(son)] inFile" + generalFlags
 
 	usageLongValidate = `Check inFile for specification compliance.
 
       mode ... validation mode
      links ... check for broken links
+      json ... report all findings as JSON instead of stopping at the first error
     inFile ... input pdf file
 		
 The validation modes are:
 
- strict ... validates against PDF 32000-1:2008 (PDF 1.7)
-relaxed ... (default) like strict but doesn't complain about common seen spec violations.`
+  strict ... validates against PDF 32000-1:2008 (PDF 1.7)
+ relaxed ... (default) like strict but doesn't complain about common seen spec violations.
+pedantic ... like strict but also flags deviations readers usually tolerate:
+             misplaced content stream operators, missing whitespace and exceeded implementation limits.
+
+Each finding of the JSON report carries the object number, its path in the document,
+the rule violated, a message and its severity.
+In relaxed mode read anomalies, document info problems and broken links are warnings, all other findings are errors.
+In strict and pedantic mode all findings are errors.`
 
 	usageOptimize     = "usage: pdfcpu optimize [-stats csvFile] inFile [outFile]" + generalFlags
 	usageLongOptimize = `Read inFile, remove redundant page resources like embedded fonts and images and write the result to outFile.
@@ -96,7 +125,12 @@ relaxed ... (default) like strict but doesn't complain about common seen spec vi
      stats ... appends a stats line to a csv file with information about the usage of root and page entries.
                useful for batch optimization and debugging PDFs.
     inFile ... input pdf file
-   outFile ... output pdf file`
+   outFile ... output pdf file
+
+Duplicate fonts and images get merged, unused page resources and unreferenced objects dropped
+and LZW encoded streams recompressed using Flate.
+Set compressStreams in your config file to also Flate encode uncompressed streams.
+The bytes saved are reported for fonts, images and other stream data.`
 
 	usageSplit     = "usage: pdfcpu split [-m(ode) span|bookmark] inFile outDir [span]" + generalFlags
 	usageLongSplit = `Generate a set of PDFs for the input file in outDir according to given span value or along bookmarks.
@@ -115,11 +149,14 @@ The split modes are:
                    span will be ignored.
                    Assumption: inFile contains an outline dictionary.`
 
-	usageMerge     = "usage: pdfcpu merge [-m(ode) create|append] [-s(ort)] outFile inFile..." + generalFlags
+	usageMerge     = "usage: pdfcpu merge [-m(ode) create|append] [-s(ort)] [-outlines nested|flat|files|none] [-stampnames] [-javascript] outFile inFile..." + generalFlags
 	usageLongMerge = `Concatenate a sequence of PDFs/inFiles into outFile.
 
       mode ... merge mode (defaults to create)
       sort ... sort inFiles by file name
+  outlines ... outline merge mode (defaults to none)
+stampnames ... stamp the pages of each inFile with its file name
+javascript ... keep the document level JavaScript of all inFiles, by default only the one of the first inFile is kept
    outFile ... output pdf file
     inFile ... a list of pdf files subject to concatenation.
     
@@ -128,7 +165,17 @@ The merge modes are:
     create ... outFile will be created and possibly overwritten (default).
 
     append ... if outFile does not exist, it will be created (like in default mode).
-               if outFile already exists, inFiles will be appended to outFile.`
+               if outFile already exists, inFiles will be appended to outFile.
+
+The outline merge modes are:
+
+    nested ... each inFile gets a top level bookmark titled by its file name holding its bookmarks (default).
+
+      flat ... the bookmarks of all inFiles are concatenated.
+
+     files ... like nested, but each inFile gets a top level bookmark even if no inFile has bookmarks.
+
+      none ... only the bookmarks of the first inFile are kept.`
 
 	usagePageSelection = `'-pages' selects pages for processing and is a comma separated list of expressions:
 
@@ -150,11 +197,12 @@ The merge modes are:
 
         e.g. -3,5,7- or 4-7,!6 or 1-,!5 or odd,n1`
 
-	usageExtract     = "usage: pdfcpu extract -m(ode) i(mage)|f(ont)|c(ontent)|p(age)|m(eta) [-p(ages) selectedPages] inFile outDir" + generalFlags
-	usageLongExtract = `Export inFile's images, fonts, content or pages into outDir.
+	usageExtract     = "usage: pdfcpu extract -m(ode) i(mage)|f(ont)|c(ontent)|te(xt)|ta(ble)|s(vg)|p(age)|m(eta) [-p(ages) selectedPages] [-j(son)] inFile outDir" + generalFlags
+	usageLongExtract = `Export inFile's images, fonts, content, text, tables, vector graphics or pages into outDir.
 
       mode ... extraction mode
      pages ... Please refer to "pdfcpu selectedpages"
+      json ... table: output JSON instead of CSV
     inFile ... input pdf file
     outDir ... output directory
 
@@ -163,6 +211,9 @@ The merge modes are:
   image ... extract images
    font ... extract font files (supported font types: TrueType)
 content ... extract raw page content
+   text ... extract text as JSON segmented into blocks, lines and words with bounding boxes
+  table ... extract tables detected by ruling lines or column alignment as CSV or JSON
+    svg ... extract pages as SVG vector graphics with editable text and embedded images
    page ... extract single page PDFs
    meta ... extract all metadata (page selection does not apply)
    
@@ -221,24 +272,52 @@ content ... extract raw page content
     `
 
 	usagePermList = "pdfcpu permissions list [-upw userpw] [-opw ownerpw] inFile"
-	usagePermSet  = "pdfcpu permissions set [-perm none|all] [-upw userpw] -opw ownerpw inFile" + generalFlags
+	usagePermSet  = "pdfcpu permissions set [-perm permissions] [-upw userpw] -opw ownerpw inFile" + generalFlags
 
 	usagePerm = "usage: " + usagePermList +
 		"\n       " + usagePermSet
 
 	usageLongPerm = `Manage user access permissions.
 
-      perm ... user access permissions
+      perm ... user access permissions (default=none)
+    inFile ... input pdf file
+
+` + usagePermissions
+
+	usageEncryptionInfo = "pdfcpu encryption info [-j(son)] [-upw userpw] [-opw ownerpw] inFile"
+
+	usageEncryption = "usage: " + usageEncryptionInfo + generalFlags
+
+	usageLongEncryption = `Print the security handler, V/R, algorithms per crypt filter, key length, metadata encryption and permissions.
+
+      json ... output JSON
     inFile ... input pdf file`
 
-	usageEncrypt     = "usage: pdfcpu encrypt [-m(ode) rc4|aes] [-key 40|128|256] [-perm none|all] [-upw userpw] -opw ownerpw inFile [outFile]" + generalFlags
+	usageEncrypt     = "usage: pdfcpu encrypt [-m(ode) rc4|aes] [-key 40|128|256] [-perm permissions] [-upw userpw] -opw ownerpw inFile [outFile]" + generalFlags
 	usageLongEncrypt = `Setup password protection based on user and owner password.
 
       mode ... algorithm (default=aes)
        key ... key length in bits (default=256)
-      perm ... user access permissions
+      perm ... user access permissions (default=none)
     inFile ... input pdf file
-   outFile ... output pdf file`
+   outFile ... output pdf file
+
+` + usagePermissions
+
+	usagePermissions = `    <permissions> is one of none, all or a comma separated list of:
+
+        print    ... print in high quality
+        modify   ... modify content
+        copy     ... copy or extract text and graphics
+        annotate ... add or modify annotations
+        forms    ... fill in form fields
+        assemble ... insert, rotate or delete pages
+
+    Viewing is always allowed.
+
+Examples:
+   pdfcpu encrypt -perm print,copy -opw secret in.pdf out.pdf
+`
 
 	usageDecrypt     = "usage: pdfcpu decrypt [-upw userpw] [-opw ownerpw] inFile [outFile]" + generalFlags
 	usageLongDecrypt = `Remove password protection and reset permissions.
@@ -366,6 +445,10 @@ content ... extract raw page content
 
    url:              Add link annotation for stamps only (omit https://)
 
+   if:               Apply to pages satisfying a condition only, eg. "pagecount > 10" or "page % 2 == 0"
+                     Available variables: page, pagecount
+                     Supported operators: + - * / % == != < <= > >= && || ! ( )
+
 A color value: 3 color intensities, where 0.0 < i < 1.0, eg 1.0, 
                or the hex RGB value: #RRGGBB, eg #FF0000 = red
 
@@ -395,7 +478,7 @@ e.g. "pos:bl, off: 20 5"   "rot:45"                 "op:0.5, sc:0.5 abs, rot:0"
      string ... display string for text based watermarks
        file ... image or pdf file
 description ... fontname, points, position, offset, scalefactor, aligntext, rotation, 
-                diagonal, opacity, rendermode, strokecolor, fillcolor, bgcolor, margins, border
+                diagonal, opacity, rendermode, strokecolor, fillcolor, bgcolor, margins, border, if
      inFile ... input pdf file
     outFile ... output pdf file
 
@@ -416,7 +499,7 @@ description ... fontname, points, position, offset, scalefactor, aligntext, rota
      string ... display string for text based watermarks
        file ... image or pdf file
 description ... fontname, points, position, offset, scalefactor, aligntext, rotation,
-                diagonal, opacity, rendermode, strokecolor, fillcolor, bgcolor, margins, border
+                diagonal, opacity, rendermode, strokecolor, fillcolor, bgcolor, margins, border, if
      inFile ... input pdf file
     outFile ... output pdf file
 
@@ -478,14 +561,24 @@ description ... dimensions, format, position, offset, scale factor, boxes
 
 `
 
-	usageRotate     = "usage: pdfcpu rotate [-p(ages) selectedPages] inFile rotation [outFile]" + generalFlags
+	usageRotate     = "usage: pdfcpu rotate [-p(ages) selectedPages] [-m(ode) page|content] inFile rotation [outFile]" + generalFlags
 	usageLongRotate = `Rotate selected pages by a multiple of 90 degrees. 
 
       pages ... Please refer to "pdfcpu selectedpages"
+       mode ... rotation mode (defaults to page)
      inFile ... input pdf file
    rotation ... a multiple of 90 degrees for clockwise rotation
     outFile ... output pdf file
 
+The rotation modes are:
+
+      page    ... Set the page attribute /Rotate (default).
+                  The page content remains untouched, viewers apply the rotation when displaying the page.
+
+      content ... Rotate the page content itself by rewriting the transformation matrix,
+                  the page boxes (width and height are swapped for 90 and 270 degrees) and any annotations.
+                  Use this for processors ignoring /Rotate.
+
 `
 
 	usageNUp     = "usage: pdfcpu nup [-p(ages) selectedPages] -- [description] outFile n inFile|imageFiles..." + generalFlags
@@ -742,12 +835,32 @@ Examples: pdfcpu grid out.pdf 1 10 in.pdf
 	usageSelectedPages     = "usage: pdfcpu selectedpages"
 	usageLongSelectedPages = "Print definition of the -pages flag."
 
-	usageInfo     = "usage: pdfcpu info [-p(ages) selectedPages] inFile" + generalFlags
+	usageInfo     = "usage: pdfcpu info [-p(ages) selectedPages] [-w(arnings)] inFile" + generalFlags
 	usageLongInfo = `Print info about a PDF file.
    
    pages ... Please refer to "pdfcpu selectedpages"
+warnings ... list non fatal anomalies found while reading
   inFile ... input pdf file`
 
+	usageManifest     = "usage: pdfcpu manifest inFile manifestFile" + generalFlags
+	usageLongManifest = `Write a manifest for inFile containing file hash, page count, signature status and attachment hashes.
+   
+      inFile ... input pdf file
+manifestFile ... output json file`
+
+	usageVerify     = "usage: pdfcpu verify -manifest manifestFile inFile" + generalFlags
+	usageLongVerify = `Verify inFile against a manifest created by "pdfcpu manifest".
+   
+    manifest ... manifest json file
+      inFile ... input pdf file`
+
+	usageInternalize     = "usage: pdfcpu internalize inFile [outFile]" + generalFlags
+	usageLongInternalize = `Read inFile, embed the data of all streams living in external files and write the result to outFile.
+External files are resolved relative to the directory of inFile.
+   
+ inFile ... input pdf file
+outFile ... output pdf file`
+
 	usageFontsList       = "pdfcpu fonts list"
 	usageFontsInstall    = "pdfcpu fonts install fontFiles..."
 	usageFontsCheatSheet = "pdfcpu fonts cheatsheet fontFiles..."
@@ -759,6 +872,30 @@ Examples: pdfcpu grid out.pdf 1 10 in.pdf
 Install given True Type fonts(.ttf) or True Type collections(.ttc) for usage in stamps/watermarks.
 Create single page PDF cheat sheets in current dir.`
 
+	usageFormList   = "pdfcpu form list   [-j(son)] inFile"
+	usageFormExport = "pdfcpu form export inFile [jsonFile]"
+	usageFormFill   = "pdfcpu form fill   [-needappearance] inFile jsonFile [outFile]" + generalFlags
+
+	usageForm = "usage: " + usageFormList +
+		"\n       " + usageFormExport +
+		"\n       " + usageFormFill
+
+	usageLongForm = `Manage the fields of an interactive form.
+
+              json ... list: output JSON
+    needappearance ... fill: leave the rendering of filled in fields to the viewer
+            inFile ... input pdf file
+          jsonFile ... form data, export defaults to inFile with extension .json
+           outFile ... output pdf file
+
+    The form data written by "form export" may be edited and fed back into "form fill".
+    Fields not mentioned in jsonFile keep their values.
+
+    Eg. export, edit and fill in a form:
+           pdfcpu form export in.pdf in.json
+           pdfcpu form fill in.pdf in.json out.pdf
+    `
+
 	usageKeywordsList   = "pdfcpu keywords list    inFile"
 	usageKeywordsAdd    = "pdfcpu keywords add     inFile keyword..."
 	usageKeywordsRemove = "pdfcpu keywords remove  inFile [keyword...]" + generalFlags
@@ -865,6 +1002,131 @@ Examples:
 
 ` + usageBoxDescription
 
+	usageAutoCrop     = "usage: pdfcpu autocrop [-p(ages) selectedPages] -- [padding] inFile [outFile]" + generalFlags
+	usageLongAutoCrop = `Set the cropbox of selected pages to the bounding box of their content.
+Useful for trimming whitespace from plots and scans. Blank pages remain unchanged.
+
+    pages ... Please refer to "pdfcpu selectedpages"
+  padding ... space to keep around the content in given display unit
+   inFile ... input pdf file
+  outFile ... output pdf file
+
+    <padding> consists of 1 to 4 values like:
+        
+        p       ... set all four sides
+        v h     ... set vertical, horizontal padding
+        t h b   ... set top, horizontal, bottom padding
+        t r b l ... set top, right, bottom, left padding
+
+    The cropbox never exceeds the mediabox.
+
+Examples:
+   pdfcpu autocrop in.pdf out.pdf
+      ... crop all pages to their content.
+
+   pdfcpu autocrop -u mm -- "5" in.pdf out.pdf
+      ... crop all pages to their content keeping 5mm of whitespace.
+
+`
+
+	usageClip     = "usage: pdfcpu clip [-p(ages) selectedPages] -- description inFile [outFile]" + generalFlags
+	usageLongClip = `Create a new document whose pages contain only a region of the selected pages.
+Useful for extracting figures or splitting up label sheets.
+
+        pages ... Please refer to "pdfcpu selectedpages"
+  description ... region definition abs. or rel. to the visible page area
+       inFile ... input pdf file
+      outFile ... output pdf file
+
+Examples:
+   pdfcpu clip -- "[0 0 300 400]" in.pdf out.pdf        ... extract a 300x400 points region located in lower left corner
+   pdfcpu clip -- "pos:tl, dim:50% 50%" in.pdf out.pdf  ... extract the top left quarter of each page
+
+` + usageBoxDescription
+
+	usageLabels     = "usage: pdfcpu labels [-p(ages) selectedPages] -- [description] outFile m n inFile" + generalFlags
+	usageLongLabels = `Generate label or ticket sheets.
+For each selected page an output sheet is created showing this page repeated onto a grid of labels.
+This is the complement of nup for repeated content eg. address labels, business cards or tickets.
+
+      pages ... Please refer to "pdfcpu selectedpages"
+description ... dimensions, formsize, margin, gutter, border
+    outFile ... output pdf file
+          m ... grid columns
+          n ... grid lines
+     inFile ... input pdf file
+
+    <description> is a comma separated configuration string containing:
+
+    optional entries:
+  
+        (defaults: "form:A4, ma:0, gu:0, bo:off")
+  
+    dimensions:   (width height) in given display unit eg. '400 200'
+    formsize:     The output sheet size, eg. A4, Letter, Legal...
+                  Append 'L' to enforce landscape mode. (eg. A3L)
+                  Append 'P' to enforce portrait mode. (eg. TabloidP)
+                  Only one of dimensions or format is allowed.
+                  Please refer to "pdfcpu paper" for a comprehensive list of defined paper sizes.
+                  "papersize" is also accepted.
+    margin:       outer sheet margin in given display unit
+    gutter:       spacing between adjacent labels in given display unit
+                  one value for both directions or (horizontal vertical) eg. '5 0'
+    border:       on/off true/false, draw label boundaries as cutting guides
+
+    Each label shows the source page centered. Pages exceeding a label are scaled down preserving their aspect ratio.
+
+Examples:
+   pdfcpu labels out.pdf 3 8 label.pdf
+      ... 3x8 labels on A4 in portrait mode.
+
+   pdfcpu labels -u mm -p 1 -- "form:Letter, ma:10, gu:5 0, bo:on" out.pdf 2 5 card.pdf
+      ... 2x5 business cards of page 1 on Letter using a 10mm sheet margin,
+          5mm horizontal gutters and cutting guides.
+
+`
+
+	usageDecorate     = "usage: pdfcpu decorate [-p(ages) selectedPages] -- description inFile [outFile]" + generalFlags
+	usageLongDecorate = `Add a solid or gradient page background and/or a page border rule to selected pages.
+The background is rendered underneath, the border on top of the page content.
+
+      pages ... Please refer to "pdfcpu selectedpages"
+description ... background and border configuration
+     inFile ... input pdf file
+    outFile ... output pdf file
+
+    <description> is a comma separated configuration string containing at least one of bgcolor or border:
+
+    backgroundcolor:  background color, see below
+                      "bgcolor" is also accepted.
+    gradient:         gradient end color, turns the background into a linear gradient starting with bgcolor
+    axis:             gradient axis, one of h(orizontal) ... left to right
+                                         v(ertical)   ... top to bottom (=default)
+    border:           width [color], eg. 2 or 2 #FF0000 or 2 .5 .5 .5
+    radius:           border corner radius
+    margins:          distance of the border rule to the page edges, set all four sides like:
+                          m       ... set all four margins
+                          v h     ... set vertical, horizontal margins
+                          t h b   ... set top, horizontal, bottom margins
+                          t r b l ... set top, right, bottom, left margins
+
+    All lengths are expressed in given display unit and relative to the visible page area.
+
+    A color value: 3 color intensities, where 0.0 < i < 1.0, eg 1.0, 
+                   or the hex RGB value: #RRGGBB, eg #FF0000 = red
+
+Examples:
+   pdfcpu decorate -- "bgcolor:#FFFFE0" in.pdf out.pdf
+      ... light yellow page background.
+
+   pdfcpu decorate -- "bg:1 1 1, gradient:.8 .8 1, axis:h" in.pdf out.pdf
+      ... horizontal gradient from white to light blue.
+
+   pdfcpu decorate -u mm -- "border:1 #0000FF, radius:5, margins:10" in.pdf out.pdf
+      ... blue 1mm border with rounded corners 10mm away from the page edges.
+
+`
+
 	usageBoxesList   = "pdfcpu boxes list    [-p(ages) selectedPages] -- [boxTypes] inFile"
 	usageBoxesAdd    = "pdfcpu boxes add     [-p(ages) selectedPages] -- description inFile [outFile]"
 	usageBoxesRemove = "pdfcpu boxes remove  [-p(ages) selectedPages] -- boxTypes inFile [outFile]" + generalFlags
@@ -897,23 +1159,54 @@ Examples:
      
 ` + usageBoxDescription
 
-	usageAnnotsList   = "pdfcpu annotations list   [-p(ages) selectedPages] inFile"
-	usageAnnotsRemove = "pdfcpu annotations remove [-p(ages) selectedPages] inFile [objNr...]" + generalFlags
+	usageAnnotsList   = "pdfcpu annotations list   [-p(ages) selectedPages] [-j(son)] inFile"
+	usageAnnotsAdd    = "pdfcpu annotations add    [-p(ages) selectedPages] [-u(nit)] -- description inFile [outFile]"
+	usageAnnotsRemove = "pdfcpu annotations remove [-p(ages) selectedPages] inFile [objNr...|type...]" + generalFlags
 
 	usageAnnots = "usage: " + usageAnnotsList +
+		"\n       " + usageAnnotsAdd +
 		"\n       " + usageAnnotsRemove
 
 	usageLongAnnots = `Manage annotations.
    
-      pages ... Please refer to "pdfcpu selectedpages"
-     inFile ... input pdf file
-      objNr ... annotation dict objNr
+        pages ... Please refer to "pdfcpu selectedpages"
+         json ... list annotations as JSON including type, rect, id, contents and flags
+         unit ... display unit for rect: po(ints), in(ches), cm, mm
+  description ... comma separated configuration string of an annotation to be added (see below)
+       inFile ... input pdf file
+      outFile ... output pdf file
+        objNr ... annotation dict objNr
+         type ... annotation type eg. Link, Text, Highlight
    
+   <description> is a comma separated configuration string containing these optional entries:
+   
+      (defaults: open:off, opacity:1)
+
+      type:     link, text or highlight (required)
+      rect:     llx lly urx ury in display unit (required)
+      uri:      target of a link annotation (required for links)
+      contents: text of a text or highlight annotation
+      id:       annotation name unique for its page
+      title:    the author of a text or highlight annotation
+      icon:     text annotation icon: Comment, Key, Note, Help, NewParagraph, Paragraph, Insert
+      open:     on/off true/false t/f, display a text annotation initially open
+      color:    3 intensities 0.0 <= i <= 1.0 or #RRGGBB
+      opacity:  0.0 <= x <= 1.0
+
    Examples:
 
       pdfcpu annot list in.pdf
       pdfcpu annot list -pages 1-2 in.pdf
 
+      Add a link to the first page:
+         pdfcpu annot add -pages 1 -- "type:link, rect:100 100 200 120, uri:https://pdfcpu.io" in.pdf out.pdf
+
+      Add a sticky note:
+         pdfcpu annot add -pages 1 -- "type:text, rect:50 700 70 720, contents:Please review" in.pdf
+
+      Highlight a region on pages 1 and 2:
+         pdfcpu annot add -pages 1,2 -- "type:highlight, rect:72 600 300 614, color:#00FF00" in.pdf
+
       Remove all page annotations:
          pdfcpu annot remove in.pdf
       
@@ -922,6 +1215,9 @@ Examples:
 
       Remove annotations with obj# 37, 38 (see output of pdfcpu annot list)
          pdfcpu annot remove in.pdf 37 38
+
+      Remove all link and highlight annotations:
+         pdfcpu annot remove in.pdf Link Highlight
       `
 
 	usageImagesList = "pdfcpu images list [-p(ages) selectedPages] inFile" + generalFlags
@@ -935,4 +1231,177 @@ Examples:
     
     Example: pdfcpu images list -p "1-5" gallery.pdf
     `
+
+	usageDuplicates     = "usage: pdfcpu duplicates [-j(son)] [threshold] inFile..." + generalFlags
+	usageLongDuplicates = `Report duplicate and near-duplicate pages across one or more PDF files.
+Useful for deduplicating scanned bundles.
+
+Pages are compared by their normalized text ignoring case, punctuation and whitespace.
+Pages without text like scans are compared by a perceptual hash of their largest image.
+Blank pages are ignored. Each page is reported along with its most similar predecessor.
+
+     json ... output JSON
+threshold ... minimum similarity of near-duplicates in (0,1], default: 0.9
+   inFile ... input pdf file
+
+Examples:
+   pdfcpu duplicates scans1.pdf scans2.pdf
+      ... report duplicate pages across both files.
+
+   pdfcpu duplicates -j 0.75 in.pdf
+      ... report pages sharing at least 75% of their content as JSON.
+`
+
+	usageRun     = "usage: pdfcpu run [operation inFile [outFile]]" + generalFlags
+	usageLongRun = `Apply a custom operation registered by an application embedding pdfcpu.
+Run without arguments to list all registered operations.
+
+operation ... name of a registered operation
+   inFile ... input pdf file
+  outFile ... output pdf file
+
+Examples:
+   pdfcpu run
+      ... list all registered operations.
+
+   pdfcpu run redact in.pdf out.pdf
+      ... apply the operation "redact" to in.pdf and write the result to out.pdf.
+`
+
+	usageTemplate     = "usage: pdfcpu template inFile templateFile recordsFile [outDir]" + generalFlags
+	usageLongTemplate = `Fill named regions of inFile with text or images, once per data record.
+This is a lightweight alternative to mail merge based on form fields.
+
+      inFile ... input pdf file
+templateFile ... JSON region definitions
+ recordsFile ... JSON object or array of JSON objects mapping region names to values
+      outDir ... output directory, default: current directory
+
+    Each region is defined by:
+
+        name      ... region name used by records
+        page      ... page number
+        rect      ... llx lly urx ury relative to the lower left corner of the visible page
+        image     ... true for image regions, the record value is an image file name
+        pos       ... position anchor within rect: tl,tc,tr,l,c,r,bl,bc,br,full (default: tl)
+        justify   ... justify text using the width of rect
+        font      ... core or user font (default: Helvetica)
+        fontSize  ... font size in points (default: 12)
+        color     ... text color, #RRGGBB or r g b (default: black)
+        rtl       ... right to left user font
+
+    Text exceeding its region gets clipped. Regions missing in a record stay empty.
+    One file named after inFile followed by the record number is written per record.
+
+Examples:
+   template.json:
+      {"regions": [
+         {"name": "name", "page": 1, "rect": [50, 700, 300, 720], "fontSize": 14},
+         {"name": "photo", "page": 1, "rect": [400, 650, 500, 750], "image": true, "pos": "c"}
+      ]}
+
+   records.json:
+      [{"name": "Jane Doe", "photo": "jane.png"}, {"name": "John Doe", "photo": "john.png"}]
+
+   pdfcpu template in.pdf template.json records.json out
+      ... writes out/in_1.pdf and out/in_2.pdf.
+`
+
+	usageSizes     = "usage: pdfcpu sizes [-p(ages) selectedPages] [-j(son)] inFile" + generalFlags
+	usageLongSizes = `Report the bytes attributed to each page to find out which pages make a document huge.
+
+Every object reachable from a page including inherited resources is attributed to this page.
+Objects used by a single page only count as exclusive, objects also used by other pages
+or the rest of the document like shared fonts count as shared.
+Objects not used by any page like outlines or metadata are attributed to the document.
+
+ pages ... Please refer to "pdfcpu selectedpages"
+  json ... output JSON
+inFile ... input pdf file
+
+Examples:
+   pdfcpu sizes in.pdf
+      ... list exclusive and shared bytes for all pages.
+
+   pdfcpu sizes -p 1-10 -j in.pdf
+      ... output the report for the first 10 pages as JSON.
+`
+
+	usageOrphans     = "usage: pdfcpu orphans [-j(son)] inFile" + generalFlags
+	usageLongOrphans = `Report all objects present in inFile but unreachable from the trailer
+along with their types and sizes.
+
+Orphaned objects are typically leftovers of incremental updates or produced by sloppy producers.
+pdfcpu drops them whenever it writes a file.
+
+  json ... output JSON
+inFile ... input pdf file`
+
+	usageResize     = "usage: pdfcpu resize [-p(ages) selectedPages] -- [description] inFile [outFile]" + generalFlags
+	usageLongResize = `Scale selected pages to a paper size.
+The content of each page gets scaled to fit the new page size preserving its aspect ratio and centered.
+Page boxes and rotation are reset and page annotations are dropped.
+
+      pages ... Please refer to "pdfcpu selectedpages"
+description ... dimensions, formsize, enforce
+     inFile ... input pdf file
+    outFile ... output pdf file
+
+    <description> is a comma separated configuration string containing:
+
+    optional entries:
+
+        (defaults: "form:A4, enforce:off")
+
+    dimensions:   (width height) in given display unit eg. '400 200'
+    formsize:     The target page size, eg. A4, Letter, Legal...
+                  Append 'L' to enforce landscape mode. (eg. A3L)
+                  Append 'P' to enforce portrait mode. (eg. TabloidP)
+                  Only one of dimensions or format is allowed.
+                  Please refer to "pdfcpu paper" for a comprehensive list of defined paper sizes.
+    enforce:      on/off true/false t/f
+                  off: the target page size follows the orientation of each page.
+                  on: use the target page size as is.
+
+Examples:
+   pdfcpu resize in.pdf out.pdf
+      ... scale all pages to A4 keeping their orientation.
+
+   pdfcpu resize -p 1-3 -- "form:LetterL, enf:on" in.pdf out.pdf
+      ... scale the first 3 pages to Letter in landscape mode.
+`
+
+	usageSignatures     = "usage: pdfcpu signatures [-j(son)] inFile [trustStore]" + generalFlags
+	usageLongSignatures = `Validate all digital signatures of inFile.
+
+For each signature pdfcpu checks
+   - the document digest against the signed byte ranges,
+   - the signature against the signer certificate,
+   - the certificate chain against the trust store,
+   - whether the document has been updated incrementally after signing.
+
+Supported are adbe.pkcs7.detached, adbe.pkcs7.sha1, ETSI.CAdES.detached and adbe.x509.rsa_sha1 signatures.
+Revocation status and time stamp tokens are not checked.
+
+      json ... output JSON
+    inFile ... input pdf file
+trustStore ... certificate file or directory of PEM or DER encoded trusted root certificates,
+               defaults to the system trust store.
+
+Examples:
+   pdfcpu signatures contract.pdf
+      ... validate against the system trust store.
+
+   pdfcpu signatures -j contract.pdf roots.pem
+      ... validate against the root certificates in roots.pem and output JSON.
+`
+
+	usageFeatures     = "usage: pdfcpu features [-j(son)] inFile" + generalFlags
+	usageLongFeatures = `Report notable PDF features used by inFile in order to anticipate viewer compatibility:
+PDF version and the minimum version required by the content, encryption, linearization,
+xref and object streams, transparency, layers, JavaScript, embedded files, multimedia,
+forms, signatures, tagging and fonts by type.
+
+  json ... output JSON
+inFile ... input pdf file`
 )
@@ -39,6 +39,8 @@ func ListAnnotations(rs io.ReadSeeker, selectedPages []string, conf *pdfcpu.Conf
 	if err != nil {
 		return 0, nil, err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "list annotations")
+
 	if err := ctx.EnsurePageCount(); err != nil {
 		return 0, nil, err
 	}
@@ -60,17 +62,59 @@ func ListAnnotationsFile(inFile string, selectedPages []string, conf *pdfcpu.Con
 	return ListAnnotations(f, selectedPages, conf)
 }
 
+// AnnotationConfig returns a link, text or highlight annotation for desc.
+func AnnotationConfig(desc string) (pdfcpu.AnnotationRenderer, error) {
+	return pdfcpu.ParseAnnotationDetails(desc, pdfcpu.POINTS)
+}
+
+// Annotations returns the page annotations of rs for selected pages.
+func Annotations(rs io.ReadSeeker, selectedPages []string, conf *pdfcpu.Configuration) ([]pdfcpu.PageAnnotation, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: Annotations: Please provide rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+		conf.Cmd = pdfcpu.LISTANNOTATIONS
+	}
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "list annotations")
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.PageAnnotations(pages)
+}
+
+// AnnotationsFile returns the page annotations of inFile for selected pages.
+func AnnotationsFile(inFile string, selectedPages []string, conf *pdfcpu.Configuration) ([]pdfcpu.PageAnnotation, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Annotations(f, selectedPages, conf)
+}
+
 // AddAnnotations adds annotations for selected pages in rs and writes the result to w.
 func AddAnnotations(rs io.ReadSeeker, w io.Writer, selectedPages []string, ann pdfcpu.AnnotationRenderer, conf *pdfcpu.Configuration) error {
 	if conf == nil {
 		conf = pdfcpu.NewDefaultConfiguration()
-		conf.Cmd = pdfcpu.ADDANNOTATIONS
 	}
+	conf.Cmd = pdfcpu.ADDANNOTATIONS
 
 	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "add annotations")
 
 	if err := ctx.EnsurePageCount(); err != nil {
 		return err
@@ -104,13 +148,14 @@ func AddAnnotations(rs io.ReadSeeker, w io.Writer, selectedPages []string, ann p
 func AddAnnotationsAsIncrement(rws io.ReadWriteSeeker, selectedPages []string, ar pdfcpu.AnnotationRenderer, conf *pdfcpu.Configuration) error {
 	if conf == nil {
 		conf = pdfcpu.NewDefaultConfiguration()
-		conf.Cmd = pdfcpu.ADDANNOTATIONS
 	}
+	conf.Cmd = pdfcpu.ADDANNOTATIONS
 
 	ctx, _, _, err := readAndValidate(rws, conf, time.Now())
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "add annotations")
 
 	if *ctx.HeaderVersion < pdfcpu.V14 {
 		return errors.New("Increment writing not supported for PDF version < V1.4 (Hint: Use pdfcpu optimize then try again)")
@@ -190,7 +235,7 @@ func AddAnnotationsFile(inFile, outFile string, selectedPages []string, ar pdfcp
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -203,13 +248,14 @@ func AddAnnotationsFile(inFile, outFile string, selectedPages []string, ar pdfcp
 func AddAnnotationsMap(rs io.ReadSeeker, w io.Writer, m map[int][]pdfcpu.AnnotationRenderer, conf *pdfcpu.Configuration) error {
 	if conf == nil {
 		conf = pdfcpu.NewDefaultConfiguration()
-		conf.Cmd = pdfcpu.ADDANNOTATIONS
 	}
+	conf.Cmd = pdfcpu.ADDANNOTATIONS
 
 	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "add annotations")
 
 	if err := ctx.EnsurePageCount(); err != nil {
 		return err
@@ -239,13 +285,14 @@ func AddAnnotationsMapAsIncrement(rws io.ReadWriteSeeker, m map[int][]pdfcpu.Ann
 
 	if conf == nil {
 		conf = pdfcpu.NewDefaultConfiguration()
-		conf.Cmd = pdfcpu.ADDANNOTATIONS
 	}
+	conf.Cmd = pdfcpu.ADDANNOTATIONS
 
 	ctx, _, _, err := readAndValidate(rws, conf, time.Now())
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "add annotations")
 
 	if *ctx.HeaderVersion < pdfcpu.V14 {
 		return errors.New("Increment writing not supported for PDF version < V1.4 (Hint: Use pdfcpu optimize then try again)")
@@ -320,7 +367,7 @@ func AddAnnotationsMapFile(inFile, outFile string, m map[int][]pdfcpu.Annotation
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -334,13 +381,14 @@ func AddAnnotationsMapFile(inFile, outFile string, m map[int][]pdfcpu.Annotation
 func RemoveAnnotations(rs io.ReadSeeker, w io.Writer, selectedPages, ids []string, objNrs []int, conf *pdfcpu.Configuration) error {
 	if conf == nil {
 		conf = pdfcpu.NewDefaultConfiguration()
-		conf.Cmd = pdfcpu.REMOVEANNOTATIONS
 	}
+	conf.Cmd = pdfcpu.REMOVEANNOTATIONS
 
 	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "remove annotations")
 
 	if err := ctx.EnsurePageCount(); err != nil {
 		return err
@@ -375,13 +423,14 @@ func RemoveAnnotations(rs io.ReadSeeker, w io.Writer, selectedPages, ids []strin
 func RemoveAnnotationsAsIncrement(rws io.ReadWriteSeeker, selectedPages, ids []string, objNrs []int, conf *pdfcpu.Configuration) error {
 	if conf == nil {
 		conf = pdfcpu.NewDefaultConfiguration()
-		conf.Cmd = pdfcpu.REMOVEANNOTATIONS
 	}
+	conf.Cmd = pdfcpu.REMOVEANNOTATIONS
 
 	ctx, _, _, err := readAndValidate(rws, conf, time.Now())
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "remove annotations")
 
 	if *ctx.HeaderVersion < pdfcpu.V14 {
 		return errors.New("Increment writing unsupported for PDF version < V1.4 (Hint: Use pdfcpu optimize then try again)")
@@ -464,7 +513,7 @@ func RemoveAnnotationsFile(inFile, outFile string, selectedPages, ids []string,
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -472,3 +521,103 @@ func RemoveAnnotationsFile(inFile, outFile string, selectedPages, ids []string,
 
 	return RemoveAnnotations(f1, f2, selectedPages, ids, objNrs, conf)
 }
+
+// RemoveAnnotationsByType removes annotations of given types eg. "Link", "Highlight" for selected pages
+// from a PDF context read from rs and writes the result to w.
+func RemoveAnnotationsByType(rs io.ReadSeeker, w io.Writer, selectedPages, types []string, conf *pdfcpu.Configuration) error {
+	if len(types) == 0 {
+		return errors.New("pdfcpu: RemoveAnnotationsByType: missing annotation types")
+	}
+
+	tt := make([]pdfcpu.AnnotationType, len(types))
+	for i, s := range types {
+		t, err := pdfcpu.ParseAnnotationType(s)
+		if err != nil {
+			return err
+		}
+		tt[i] = t
+	}
+
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.REMOVEANNOTATIONS
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "remove annotations")
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return err
+	}
+
+	ok, err := ctx.RemoveAnnotationsByType(pages, tt, false)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("no annotation removed")
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	return WriteContext(ctx, w)
+}
+
+// RemoveAnnotationsByTypeFile removes annotations of given types for selected pages
+// from a PDF context read from inFile and writes the result to outFile.
+func RemoveAnnotationsByTypeFile(inFile, outFile string, selectedPages, types []string, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
+				return
+			}
+		}
+	}()
+
+	return RemoveAnnotationsByType(f1, f2, selectedPages, types, conf)
+}
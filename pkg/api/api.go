@@ -29,23 +29,65 @@
 // eg. for optimization:
 //  func OptimizeFile(inFile, outFile string, conf *pdf.Configuration) error
 //  func Optimize(rs io.ReadSeeker, w io.Writer, conf *pdf.Configuration) error
+//
+// Processing of pathological files may be cancelled or limited by a deadline
+// using ReadContextWithContext, ValidateWithContext or OptimizeWithContext.
+// A Context read this way stops validation, optimization and writing as well once the context.Context is done.
 package api
 
 import (
 	"bufio"
+	"context"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/pdfcpu/pdfcpu/pkg/log"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
 
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/validate"
 )
 
 // ReadContext uses an io.ReadSeeker to build an internal structure holding its cross reference table aka the Context.
 func ReadContext(rs io.ReadSeeker, conf *pdfcpu.Configuration) (*pdfcpu.Context, error) {
-	return pdfcpu.Read(rs, conf)
+	return ReadContextWithContext(context.Background(), rs, conf)
+}
+
+// ReadContextWithContext is like ReadContext but stops with c's error once c is done.
+// Validating, optimizing and writing the resulting Context stop accordingly.
+func ReadContextWithContext(c context.Context, rs io.ReadSeeker, conf *pdfcpu.Configuration) (*pdfcpu.Context, error) {
+	from := time.Now()
+	ctx, err := pdfcpu.ReadWithContext(c, rs, conf)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Timing.Start = from
+	ctx.Timing.DurRead = time.Since(from).Seconds()
+	return ctx, nil
+}
+
+// ReadContextPages is like ReadContext but only loads the trailer, the catalog, the page tree
+// and the objects used by selectedPages, eg. for rendering a preview of the first page.
+// ctx.Read.Pages holds the loaded pages. Anything else, like outlines or form fields, is not available.
+func ReadContextPages(rs io.ReadSeeker, selectedPages []string, conf *pdfcpu.Configuration) (*pdfcpu.Context, error) {
+	return ReadContextPagesWithContext(context.Background(), rs, selectedPages, conf)
+}
+
+// ReadContextPagesWithContext is like ReadContextPages but stops with c's error once c is done.
+func ReadContextPagesWithContext(c context.Context, rs io.ReadSeeker, selectedPages []string, conf *pdfcpu.Configuration) (*pdfcpu.Context, error) {
+	from := time.Now()
+	ctx, err := pdfcpu.ReadPages(c, rs, conf, func(pageCount int) (pdfcpu.IntSet, error) {
+		return PagesForPageSelection(pageCount, selectedPages, true)
+	})
+	if err != nil {
+		return nil, err
+	}
+	ctx.Timing.Start = from
+	ctx.Timing.DurRead = time.Since(from).Seconds()
+	return ctx, nil
 }
 
 // ReadContextFile returns inFile's validated context.
@@ -67,44 +109,188 @@ func ReadContextFile(inFile string) (*pdfcpu.Context, error) {
 
 // ValidateContext validates ctx.
 func ValidateContext(ctx *pdfcpu.Context) error {
+	from := time.Now()
+	defer func() { ctx.Timing.DurValidate += time.Since(from).Seconds() }()
 	return validate.XRefTable(ctx.XRefTable)
 }
 
 // OptimizeContext optimizes ctx.
 func OptimizeContext(ctx *pdfcpu.Context) error {
+	from := time.Now()
+	defer func() { ctx.Timing.DurOptimize += time.Since(from).Seconds() }()
 	return pdfcpu.OptimizeXRefTable(ctx)
 }
 
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
 // WriteContext writes ctx to w.
+// If ctx.Incremental is set the original file followed by an incremental update gets written.
 func WriteContext(ctx *pdfcpu.Context, w io.Writer) error {
+	from := time.Now()
+	defer func() { ctx.Timing.DurWrite += time.Since(from).Seconds() }()
 	if f, ok := w.(*os.File); ok {
 		// In order to retrieve the written file size.
 		ctx.Write.Fp = f
+	} else {
+		// In order to retrieve the written size.
+		cw := &countingWriter{w: w}
+		w = cw
+		defer func() { ctx.Write.FileSize = cw.n }()
 	}
 	ctx.Write.Writer = bufio.NewWriter(w)
 	defer ctx.Write.Flush()
+	if ctx.Incremental {
+		return pdfcpu.WriteIncrementalUpdate(ctx)
+	}
 	return pdfcpu.Write(ctx)
 }
 
+// WriteContextMulti writes ctx to all of ws in one pass, eg. to a file, a hash and an upload stream.
+// Writing stops at the first failing writer.
+func WriteContextMulti(ctx *pdfcpu.Context, ws ...io.Writer) error {
+	if len(ws) == 0 {
+		return errors.New("pdfcpu: WriteContextMulti: missing writer")
+	}
+	return WriteContext(ctx, io.MultiWriter(ws...))
+}
+
 // WriteIncrement writes a PDF increment for ctx to w.
 func WriteIncrement(ctx *pdfcpu.Context, w io.Writer) error {
+	from := time.Now()
+	defer func() { ctx.Timing.DurWrite += time.Since(from).Seconds() }()
 	ctx.Write.Writer = bufio.NewWriter(w)
 	defer ctx.Write.Flush()
 	return pdfcpu.WriteIncrement(ctx)
 }
 
 // WriteContextFile writes ctx to outFile.
-func WriteContextFile(ctx *pdfcpu.Context, outFile string) error {
-	f, err := os.Create(outFile)
+// An existing outFile is replaced atomically.
+func WriteContextFile(ctx *pdfcpu.Context, outFile string) (err error) {
+	f, err := ioutil.TempFile(filepath.Dir(outFile), filepath.Base(outFile)+".*.tmp")
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	tmpFile := f.Name()
+
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f.Close(); err != nil {
+			os.Remove(tmpFile)
+			return
+		}
+		err = replaceFile(tmpFile, outFile, ctx.Configuration)
+	}()
+
+	// Temp files are private, replaceFile applies the mode of an existing outFile.
+	if err = f.Chmod(0644); err != nil {
+		return err
+	}
+
 	return WriteContext(ctx, f)
 }
 
+// syncFile flushes the content of fileName to stable storage.
+func syncFile(fileName string) error {
+	f, err := os.OpenFile(fileName, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// backupFile keeps a copy of fileName named fileName.bak.
+func backupFile(fileName string, fi os.FileInfo) error {
+	bakFile := fileName + ".bak"
+	if err := os.Remove(bakFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.Link(fileName, bakFile); err == nil {
+		return nil
+	}
+
+	// Fall back to copying for file systems not supporting hard links.
+	bb, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(bakFile, bb, fi.Mode()); err != nil {
+		return err
+	}
+	// Undo the umask.
+	return os.Chmod(bakFile, fi.Mode())
+}
+
+// replaceFile atomically replaces fileName with tmpFile which has to live in the same directory.
+// The original is kept as fileName.bak if conf.BackupInPlace is set.
+func replaceFile(tmpFile, fileName string, conf *pdfcpu.Configuration) (err error) {
+	defer func() {
+		if err != nil {
+			os.Remove(tmpFile)
+		}
+	}()
+
+	if err = syncFile(tmpFile); err != nil {
+		return err
+	}
+
+	fi, err := os.Stat(fileName)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if fi != nil {
+		if err = os.Chmod(tmpFile, fi.Mode()); err != nil {
+			return err
+		}
+		if conf != nil && conf.BackupInPlace {
+			if err = backupFile(fileName, fi); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = os.Rename(tmpFile, fileName); err != nil {
+		return err
+	}
+
+	// Persist the rename, not supported on all platforms.
+	if d, err := os.Open(filepath.Dir(fileName)); err == nil {
+		d.Sync()
+		d.Close()
+	}
+
+	return nil
+}
+
 func readAndValidate(rs io.ReadSeeker, conf *pdfcpu.Configuration, from1 time.Time) (ctx *pdfcpu.Context, dur1, dur2 float64, err error) {
-	if ctx, err = ReadContext(rs, conf); err != nil {
+	return readAndValidateWithContext(context.Background(), rs, conf, from1)
+}
+
+func readAndValidateWithContext(c context.Context, rs io.ReadSeeker, conf *pdfcpu.Configuration, from1 time.Time) (ctx *pdfcpu.Context, dur1, dur2 float64, err error) {
+	if ctx, err = ReadContextWithContext(c, rs, conf); err != nil {
+		return nil, 0, 0, err
+	}
+
+	if err = pdfcpu.CheckSignatures(ctx); err != nil {
 		return nil, 0, 0, err
 	}
 
@@ -117,7 +303,7 @@ func readAndValidate(rs io.ReadSeeker, conf *pdfcpu.Configuration, from1 time.Ti
 
 	from2 := time.Now()
 
-	if err = validate.XRefTable(ctx.XRefTable); err != nil {
+	if err = ValidateContext(ctx); err != nil {
 		return nil, 0, 0, err
 	}
 
@@ -127,7 +313,11 @@ func readAndValidate(rs io.ReadSeeker, conf *pdfcpu.Configuration, from1 time.Ti
 }
 
 func readValidateAndOptimize(rs io.ReadSeeker, conf *pdfcpu.Configuration, from1 time.Time) (ctx *pdfcpu.Context, dur1, dur2, dur3 float64, err error) {
-	ctx, dur1, dur2, err = readAndValidate(rs, conf, from1)
+	return readValidateAndOptimizeWithContext(context.Background(), rs, conf, from1)
+}
+
+func readValidateAndOptimizeWithContext(c context.Context, rs io.ReadSeeker, conf *pdfcpu.Configuration, from1 time.Time) (ctx *pdfcpu.Context, dur1, dur2, dur3 float64, err error) {
+	ctx, dur1, dur2, err = readAndValidateWithContext(c, rs, conf, from1)
 	if err != nil {
 		return nil, 0, 0, 0, err
 	}
@@ -145,6 +335,7 @@ func readValidateAndOptimize(rs io.ReadSeeker, conf *pdfcpu.Configuration, from1
 func logOperationStats(ctx *pdfcpu.Context, op string, durRead, durVal, durOpt, durWrite, durTotal float64) {
 	log.Stats.Printf("XRefTable:\n%s\n", ctx)
 	pdfcpu.TimingStats(op, durRead, durVal, durOpt, durWrite, durTotal)
+	pdfcpu.PublishOperationMetrics(ctx, op, durRead, durVal, durOpt, durWrite, durTotal)
 	if ctx.Read.FileSize > 0 {
 		ctx.Read.LogStats(ctx.Optimized)
 		ctx.Write.LogStats()
@@ -43,6 +43,7 @@ func listAttachments(rs io.ReadSeeker, conf *pdfcpu.Configuration, withDesc, sor
 	if err != nil {
 		return nil, err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "list attachments")
 
 	fromWrite := time.Now()
 
@@ -113,6 +114,10 @@ func AddAttachments(rs io.ReadSeeker, w io.Writer, files []string, coll bool, co
 	if conf == nil {
 		conf = pdfcpu.NewDefaultConfiguration()
 	}
+	conf.Cmd = pdfcpu.ADDATTACHMENTS
+	if coll {
+		conf.Cmd = pdfcpu.ADDATTACHMENTSPORTFOLIO
+	}
 
 	fromStart := time.Now()
 	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
@@ -205,7 +210,7 @@ func AddAttachmentsFile(inFile, outFile string, files []string, coll bool, conf
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -225,6 +230,7 @@ func RemoveAttachments(rs io.ReadSeeker, w io.Writer, files []string, conf *pdfc
 	if conf == nil {
 		conf = pdfcpu.NewDefaultConfiguration()
 	}
+	conf.Cmd = pdfcpu.REMOVEATTACHMENTS
 
 	fromStart := time.Now()
 	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
@@ -287,7 +293,7 @@ func RemoveAttachmentsFile(inFile, outFile string, files []string, conf *pdfcpu.
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -309,6 +315,7 @@ func ExtractAttachmentsRaw(rs io.ReadSeeker, outDir string, fileNames []string,
 	if err != nil {
 		return nil, err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "extract attachments")
 
 	return ctx.ExtractAttachments(fileNames)
 }
@@ -338,6 +345,56 @@ func ExtractAttachments(rs io.ReadSeeker, outDir string, fileNames []string, con
 	return nil
 }
 
+// ExtractAttachmentsZip writes embedded files from a PDF context read from rs as zip archive to w.
+// If password is not empty the zip entries get AES-256 encrypted.
+func ExtractAttachmentsZip(rs io.ReadSeeker, w io.Writer, fileNames []string, password string, conf *pdfcpu.Configuration) error {
+	if w == nil {
+		return errors.New("pdfcpu: ExtractAttachmentsZip: Please provide w")
+	}
+
+	aa, err := ExtractAttachmentsRaw(rs, "", fileNames, conf)
+	if err != nil {
+		return err
+	}
+
+	var rnd io.Reader
+	if conf != nil {
+		rnd = conf.RandReader
+	}
+
+	zw := newZipWriter(w, password, rnd)
+
+	for _, a := range aa {
+		w1, err := zw.create(a.FileName)
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(w1, a); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// ExtractAttachment writes the content of the embedded file fileName of a PDF context read from rs to w.
+func ExtractAttachment(rs io.ReadSeeker, w io.Writer, fileName string, conf *pdfcpu.Configuration) error {
+	if w == nil {
+		return errors.New("pdfcpu: ExtractAttachment: Please provide w")
+	}
+
+	aa, err := ExtractAttachmentsRaw(rs, "", []string{fileName}, conf)
+	if err != nil {
+		return err
+	}
+	if len(aa) == 0 {
+		return errors.Errorf("pdfcpu: ExtractAttachment: %s not found", fileName)
+	}
+
+	_, err = io.Copy(w, aa[0])
+	return err
+}
+
 // ExtractAttachmentsFile extracts embedded files from a PDF context read from inFile into outDir.
 func ExtractAttachmentsFile(inFile, outDir string, files []string, conf *pdfcpu.Configuration) error {
 	f, err := os.Open(inFile)
@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// Padding parses a padding definition.
+func Padding(s string, u pdfcpu.DisplayUnit) (*pdfcpu.Padding, error) {
+	return pdfcpu.ParsePadding(s, u)
+}
+
+// AutoCrop sets the cropbox of selected pages of rs to the bounding box of their content enlarged by pad and writes the result to w.
+func AutoCrop(rs io.ReadSeeker, w io.Writer, selectedPages []string, pad *pdfcpu.Padding, conf *pdfcpu.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: AutoCrop: missing rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.AUTOCROP
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	from := time.Now()
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return err
+	}
+
+	if err = ctx.CropToContent(pages, pad); err != nil {
+		return err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	durCrop := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durCrop + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "autocrop, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// AutoCropFile sets the cropbox of selected pages of inFile to the bounding box of their content enlarged by pad and writes the result to outFile.
+func AutoCropFile(inFile, outFile string, selectedPages []string, pad *pdfcpu.Padding, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
+				return
+			}
+		}
+	}()
+
+	return AutoCrop(f1, f2, selectedPages, pad, conf)
+}
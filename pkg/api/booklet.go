@@ -92,6 +92,7 @@ func Booklet(rs io.ReadSeeker, w io.Writer, imgFiles, selectedPages []string, nu
 			return err
 		}
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "booklet")
 
 	if conf.ValidationMode != pdfcpu.ValidationNone {
 		if err = ValidateContext(ctx); err != nil {
@@ -118,7 +119,11 @@ func BookletFile(inFiles []string, outFile string, selectedPages []string, nup *
 		return err
 	}
 
-	if f2, err = os.Create(outFile); err != nil {
+	tmpFile := outFile
+	if f1 != nil && inFiles[0] == outFile {
+		tmpFile += ".tmp"
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
 		return err
 	}
 	log.CLI.Printf("writing %s...\n", outFile)
@@ -129,6 +134,9 @@ func BookletFile(inFiles []string, outFile string, selectedPages []string, nup *
 				f1.Close()
 			}
 			f2.Close()
+			if tmpFile != outFile {
+				os.Remove(tmpFile)
+			}
 			return
 		}
 		if f1 != nil {
@@ -136,9 +144,12 @@ func BookletFile(inFiles []string, outFile string, selectedPages []string, nup *
 				return
 			}
 		}
-		err = f2.Close()
-		return
-
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if tmpFile != outFile {
+			err = replaceFile(tmpFile, outFile, conf)
+		}
 	}()
 
 	return Booklet(f1, f2, inFiles, selectedPages, nup, conf)
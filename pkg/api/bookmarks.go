@@ -95,7 +95,7 @@ func AddBookmarksFile(inFile, outFile string, bms []pdf.Bookmark, conf *pdf.Conf
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -54,6 +54,8 @@ func ListBoxes(rs io.ReadSeeker, selectedPages []string, pb *pdfcpu.PageBoundari
 	if err != nil {
 		return nil, err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "list boxes")
+
 	if err := ctx.EnsurePageCount(); err != nil {
 		return nil, err
 	}
@@ -95,6 +97,8 @@ func AddBoxes(rs io.ReadSeeker, w io.Writer, selectedPages []string, pb *pdfcpu.
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "add boxes")
+
 	if err := ctx.EnsurePageCount(); err != nil {
 		return err
 	}
@@ -153,7 +157,7 @@ func AddBoxesFile(inFile, outFile string, selectedPages []string, pb *pdfcpu.Pag
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -176,6 +180,8 @@ func RemoveBoxes(rs io.ReadSeeker, w io.Writer, selectedPages []string, pb *pdfc
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "remove boxes")
+
 	if err := ctx.EnsurePageCount(); err != nil {
 		return err
 	}
@@ -234,7 +240,7 @@ func RemoveBoxesFile(inFile, outFile string, selectedPages []string, pb *pdfcpu.
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -257,6 +263,8 @@ func Crop(rs io.ReadSeeker, w io.Writer, selectedPages []string, b *pdfcpu.Box,
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "crop")
+
 	if err := ctx.EnsurePageCount(); err != nil {
 		return err
 	}
@@ -315,7 +323,7 @@ func CropFile(inFile, outFile string, selectedPages []string, b *pdfcpu.Box, con
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// ChangeReport summarizes the changes between the PDF read from before and the PDF read from after.
+// Pass a nil before for newly created documents.
+func ChangeReport(before, after io.ReadSeeker, conf *pdfcpu.Configuration) (*pdfcpu.ChangeReport, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+
+	// Reading for the report must not be subject to the permissions of the command in progress.
+	c := *conf
+	c.Cmd = pdfcpu.VALIDATE
+
+	var ctx1 *pdfcpu.Context
+	if before != nil {
+		ctx, err := ReadContext(before, &c)
+		if err != nil {
+			return nil, err
+		}
+		ctx1 = ctx
+	}
+
+	ctx2, err := ReadContext(after, &c)
+	if err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.NewChangeReport(ctx1, ctx2)
+}
+
+// ChangeReportFile summarizes the changes between inFile and outFile.
+// Pass an empty inFile for newly created documents.
+func ChangeReportFile(inFile, outFile string, conf *pdfcpu.Configuration) (*pdfcpu.ChangeReport, error) {
+	var before io.ReadSeeker
+
+	if inFile != "" {
+		f, err := os.Open(inFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		before = f
+	}
+
+	f, err := os.Open(outFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ChangeReport(before, f, conf)
+}
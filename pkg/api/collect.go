@@ -37,6 +37,7 @@ func Collect(rs io.ReadSeeker, w io.Writer, selectedPages []string, conf *pdfcpu
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "collect")
 
 	if err := ctx.EnsurePageCount(); err != nil {
 		return err
@@ -94,7 +95,7 @@ func CollectFile(inFile, outFile string, selectedPages []string, conf *pdfcpu.Co
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// EqualDocuments returns true if rs1 and rs2 share the same content.
+// Volatile metadata like file IDs, dates and the producer is ignored.
+func EqualDocuments(rs1, rs2 io.ReadSeeker, conf *pdfcpu.Configuration) (bool, error) {
+	if rs1 == nil || rs2 == nil {
+		return false, errors.New("pdfcpu: EqualDocuments: Please provide rs1 and rs2")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+
+	ctx1, _, _, err := readAndValidate(rs1, conf, time.Now())
+	if err != nil {
+		return false, err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx1, "compare")
+
+	ctx2, _, _, err := readAndValidate(rs2, conf, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	return pdfcpu.EqualDocuments(ctx1, ctx2)
+}
+
+// EqualDocumentsFile returns true if inFile1 and inFile2 share the same content.
+func EqualDocumentsFile(inFile1, inFile2 string, conf *pdfcpu.Configuration) (bool, error) {
+	f1, err := os.Open(inFile1)
+	if err != nil {
+		return false, err
+	}
+	defer f1.Close()
+
+	f2, err := os.Open(inFile2)
+	if err != nil {
+		return false, err
+	}
+	defer f2.Close()
+
+	return EqualDocuments(f1, f2, conf)
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// ContentHashes returns stable hashes of the content of rs and each of its pages.
+// Volatile metadata like file IDs, dates and the producer as well as the physical layout of the file is ignored.
+func ContentHashes(rs io.ReadSeeker, conf *pdfcpu.Configuration) (*pdfcpu.ContentHashes, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: ContentHashes: Please provide rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "content hashes")
+
+	return ctx.ContentHashes()
+}
+
+// ContentHashesFile returns stable hashes of the content of inFile and each of its pages.
+func ContentHashesFile(inFile string, conf *pdfcpu.Configuration) (*pdfcpu.ContentHashes, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ContentHashes(f, conf)
+}
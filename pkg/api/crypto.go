@@ -17,6 +17,10 @@
 package api
 
 import (
+	"io"
+	"os"
+	"time"
+
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pkg/errors"
 )
@@ -64,3 +68,30 @@ func ChangeOwnerPasswordFile(inFile, outFile string, pwOld, pwNew string, conf *
 	conf.OwnerPWNew = &pwNew
 	return OptimizeFile(inFile, outFile, conf)
 }
+
+// EncryptionInfo returns the security settings of rs.
+func EncryptionInfo(rs io.ReadSeeker, conf *pdfcpu.Configuration) (*pdfcpu.EncryptionInfo, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.ENCRYPTIONINFO
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "encryption info")
+
+	return pdfcpu.EncryptionDetails(ctx)
+}
+
+// EncryptionInfoFile returns the security settings of inFile.
+func EncryptionInfoFile(inFile string, conf *pdfcpu.Configuration) (*pdfcpu.EncryptionInfo, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return EncryptionInfo(f, conf)
+}
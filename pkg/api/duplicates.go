@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// DuplicatePages returns the duplicate and near-duplicate pages found across rsc.
+// Two pages are near-duplicates if their similarity reaches threshold (0 < threshold <= 1).
+// names optionally identifies the documents in the result.
+func DuplicatePages(rsc []io.ReadSeeker, names []string, threshold float64, conf *pdfcpu.Configuration) ([]pdfcpu.PageDuplicate, error) {
+	if len(rsc) == 0 {
+		return nil, errors.New("pdfcpu: DuplicatePages: Please provide rsc")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+		conf.Cmd = pdfcpu.DUPLICATES
+	}
+
+	ctxs := make([]*pdfcpu.Context, len(rsc))
+
+	for i, rs := range rsc {
+		ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			defer pdfcpu.PublishContextMetrics(ctx, "duplicates")
+		}
+		ctxs[i] = ctx
+	}
+
+	return pdfcpu.DuplicatePages(ctxs, names, threshold)
+}
+
+// DuplicatePagesFile returns the duplicate and near-duplicate pages found across inFiles.
+func DuplicatePagesFile(inFiles []string, threshold float64, conf *pdfcpu.Configuration) ([]pdfcpu.PageDuplicate, error) {
+	rsc := make([]io.ReadSeeker, len(inFiles))
+	names := make([]string, len(inFiles))
+
+	for i, fn := range inFiles {
+		f, err := os.Open(fn)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		rsc[i], names[i] = f, filepath.Base(fn)
+	}
+
+	log.CLI.Printf("looking for duplicate pages in %d file(s) ...\n", len(inFiles))
+
+	return DuplicatePages(rsc, names, threshold, conf)
+}
@@ -17,8 +17,12 @@
 package api
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
@@ -45,6 +49,7 @@ func ExtractImagesRaw(rs io.ReadSeeker, selectedPages []string, conf *pdfcpu.Con
 	if err != nil {
 		return nil, err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "extract images")
 
 	if err := ctx.EnsurePageCount(); err != nil {
 		return nil, err
@@ -83,6 +88,7 @@ func ExtractImages(rs io.ReadSeeker, selectedPages []string, digestImage func(pd
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "extract images")
 
 	if err := ctx.EnsurePageCount(); err != nil {
 		return err
@@ -146,6 +152,7 @@ func ExtractFonts(rs io.ReadSeeker, outDir, fileName string, selectedPages []str
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "extract fonts")
 
 	if err := ctx.EnsurePageCount(); err != nil {
 		return err
@@ -203,6 +210,15 @@ func ExtractFontsFile(inFile, outDir string, selectedPages []string, conf *pdfcp
 
 // ExtractPages generates single page PDF files from rs in outDir for selected pages.
 func ExtractPages(rs io.ReadSeeker, outDir, fileName string, selectedPages []string, conf *pdfcpu.Configuration) error {
+	return extractPages(rs, fileName, selectedPages, conf, func(fn string, ctxNew *pdfcpu.Context) error {
+		outFile := filepath.Join(outDir, fn)
+		log.CLI.Printf("writing %s\n", outFile)
+		return WriteContextFile(ctxNew, outFile)
+	})
+}
+
+// extractPages hands a single page context for each selected page of rs to writePage.
+func extractPages(rs io.ReadSeeker, fileName string, selectedPages []string, conf *pdfcpu.Configuration, writePage func(fileName string, ctx *pdfcpu.Context) error) error {
 	if rs == nil {
 		return errors.New("pdfcpu: ExtractPages: Please provide rs")
 	}
@@ -216,6 +232,7 @@ func ExtractPages(rs io.ReadSeeker, outDir, fileName string, selectedPages []str
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "extract pages")
 
 	if err := ctx.EnsurePageCount(); err != nil {
 		return err
@@ -237,9 +254,13 @@ func ExtractPages(rs io.ReadSeeker, outDir, fileName string, selectedPages []str
 		if err != nil {
 			return err
 		}
-		outFile := filepath.Join(outDir, fmt.Sprintf("%s_page_%d.pdf", fileName, i))
-		log.CLI.Printf("writing %s\n", outFile)
-		if err := WriteContextFile(ctxNew, outFile); err != nil {
+		fn := fmt.Sprintf("%s_page_%d.pdf", fileName, i)
+		if ctx.FileNameTemplate != "" {
+			if fn, err = templateFileName(ctx, fileName, i, i, ""); err != nil {
+				return err
+			}
+		}
+		if err := writePage(fn, ctxNew); err != nil {
 			return err
 		}
 	}
@@ -276,6 +297,7 @@ func ExtractContent(rs io.ReadSeeker, outDir, fileName string, selectedPages []s
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "extract content")
 
 	if err := ctx.EnsurePageCount(); err != nil {
 		return err
@@ -332,6 +354,282 @@ func ExtractContentFile(inFile, outDir string, selectedPages []string, conf *pdf
 	return ExtractContent(f, outDir, inFile, selectedPages, conf)
 }
 
+// ExtractText dumps the text of selected pages of rs segmented into blocks, lines and words as JSON files into outDir.
+func ExtractText(rs io.ReadSeeker, outDir, fileName string, selectedPages []string, conf *pdfcpu.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: ExtractText: Please provide rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+		conf.Cmd = pdfcpu.EXTRACTTEXT
+	}
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "extract text")
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	fromWrite := time.Now()
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return err
+	}
+
+	fileName = strings.TrimSuffix(filepath.Base(fileName), ".pdf")
+
+	for p, v := range pages {
+		if !v {
+			continue
+		}
+		pt, err := ctx.ExtractPageText(p)
+		if err != nil {
+			return err
+		}
+		bb, err := json.MarshalIndent(pt, "", "\t")
+		if err != nil {
+			return err
+		}
+		outFile := filepath.Join(outDir, fmt.Sprintf("%s_Text_page_%d.json", fileName, p))
+		log.CLI.Printf("writing %s\n", outFile)
+		if err := ioutil.WriteFile(outFile, bb, os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	durWrite := time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	pdfcpu.TimingStats("write text", durRead, durVal, durOpt, durWrite, durTotal)
+	return nil
+}
+
+// ExtractTextFile dumps the text of selected pages of inFile segmented into blocks, lines and words as JSON files into outDir.
+func ExtractTextFile(inFile, outDir string, selectedPages []string, conf *pdfcpu.Configuration) error {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	log.CLI.Printf("extracting text from %s into %s/ ...\n", inFile, outDir)
+	return ExtractText(f, outDir, inFile, selectedPages, conf)
+}
+
+// ExtractPageText returns the text of page pageNr of rs segmented into blocks, lines and words with their bounding boxes.
+func ExtractPageText(rs io.ReadSeeker, pageNr int, conf *pdfcpu.Configuration) (*pdfcpu.PageText, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: ExtractPageText: Please provide rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.EXTRACTTEXT
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "extract text")
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+	if pageNr < 1 || pageNr > ctx.PageCount {
+		return nil, errors.Errorf("pdfcpu: invalid page number: %d", pageNr)
+	}
+
+	return ctx.ExtractPageText(pageNr)
+}
+
+// ExtractSVG exports selected pages of rs as SVG images into outDir.
+func ExtractSVG(rs io.ReadSeeker, outDir, fileName string, selectedPages []string, conf *pdfcpu.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: ExtractSVG: Please provide rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+		conf.Cmd = pdfcpu.EXTRACTSVG
+	}
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "extract svg")
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	fromWrite := time.Now()
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return err
+	}
+
+	fileName = strings.TrimSuffix(filepath.Base(fileName), ".pdf")
+
+	for p, v := range pages {
+		if !v {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := ctx.WritePageSVG(p, &buf); err != nil {
+			return err
+		}
+		outFile := filepath.Join(outDir, fmt.Sprintf("%s_page_%d.svg", fileName, p))
+		log.CLI.Printf("writing %s\n", outFile)
+		if err := ioutil.WriteFile(outFile, buf.Bytes(), os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	durWrite := time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	pdfcpu.TimingStats("write svg", durRead, durVal, durOpt, durWrite, durTotal)
+	return nil
+}
+
+// ExtractSVGFile exports selected pages of inFile as SVG images into outDir.
+func ExtractSVGFile(inFile, outDir string, selectedPages []string, conf *pdfcpu.Configuration) error {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	log.CLI.Printf("extracting svg from %s into %s/ ...\n", inFile, outDir)
+	return ExtractSVG(f, outDir, inFile, selectedPages, conf)
+}
+
+// ExtractPageSVG writes page pageNr of rs as SVG image to w.
+func ExtractPageSVG(rs io.ReadSeeker, w io.Writer, pageNr int, conf *pdfcpu.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: ExtractPageSVG: Please provide rs")
+	}
+	if w == nil {
+		return errors.New("pdfcpu: ExtractPageSVG: Please provide w")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.EXTRACTSVG
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "extract svg")
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+	if pageNr < 1 || pageNr > ctx.PageCount {
+		return errors.Errorf("pdfcpu: invalid page number: %d", pageNr)
+	}
+
+	return ctx.WritePageSVG(pageNr, w)
+}
+
+func writeTablesCSV(outDir, fileName string, pt *pdfcpu.PageTables) error {
+	for i, t := range pt.Tables {
+		outFile := filepath.Join(outDir, fmt.Sprintf("%s_Table_page_%d_%d.csv", fileName, pt.Page, i+1))
+		log.CLI.Printf("writing %s\n", outFile)
+		f, err := os.Create(outFile)
+		if err != nil {
+			return err
+		}
+		w := csv.NewWriter(f)
+		if err := w.WriteAll(t.Rows); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExtractTables dumps the tables detected on selected pages of rs into outDir.
+// Each table is written to a CSV file unless jsonOut is set, in which case the tables of each page are written to a JSON file.
+func ExtractTables(rs io.ReadSeeker, outDir, fileName string, selectedPages []string, jsonOut bool, conf *pdfcpu.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: ExtractTables: Please provide rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+		conf.Cmd = pdfcpu.EXTRACTTABLES
+	}
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "extract tables")
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	fromWrite := time.Now()
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return err
+	}
+
+	fileName = strings.TrimSuffix(filepath.Base(fileName), ".pdf")
+
+	for p, v := range pages {
+		if !v {
+			continue
+		}
+		pt, err := ctx.ExtractPageTables(p)
+		if err != nil {
+			return err
+		}
+		if !jsonOut {
+			if err := writeTablesCSV(outDir, fileName, pt); err != nil {
+				return err
+			}
+			continue
+		}
+		bb, err := json.MarshalIndent(pt, "", "\t")
+		if err != nil {
+			return err
+		}
+		outFile := filepath.Join(outDir, fmt.Sprintf("%s_Tables_page_%d.json", fileName, p))
+		log.CLI.Printf("writing %s\n", outFile)
+		if err := ioutil.WriteFile(outFile, bb, os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	durWrite := time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	pdfcpu.TimingStats("write tables", durRead, durVal, durOpt, durWrite, durTotal)
+	return nil
+}
+
+// ExtractTablesFile dumps the tables detected on selected pages of inFile into outDir.
+func ExtractTablesFile(inFile, outDir string, selectedPages []string, jsonOut bool, conf *pdfcpu.Configuration) error {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	log.CLI.Printf("extracting tables from %s into %s/ ...\n", inFile, outDir)
+	return ExtractTables(f, outDir, inFile, selectedPages, jsonOut, conf)
+}
+
 // ExtractMetadata dumps all metadata dict entries for rs into outDir.
 func ExtractMetadata(rs io.ReadSeeker, outDir, fileName string, conf *pdfcpu.Configuration) error {
 	if rs == nil {
@@ -346,6 +644,7 @@ func ExtractMetadata(rs io.ReadSeeker, outDir, fileName string, conf *pdfcpu.Con
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "extract metadata")
 
 	fromWrite := time.Now()
 
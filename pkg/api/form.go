@@ -0,0 +1,273 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// FormFields returns the terminal fields of the interactive form of rs along with their values.
+func FormFields(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]pdfcpu.FormField, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: FormFields: missing rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.LISTFORMFIELDS
+
+	return formFields(rs, conf)
+}
+
+func formFields(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]pdfcpu.FormField, error) {
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "list form fields")
+
+	return ctx.FormFields()
+}
+
+// FormFieldsFile returns the terminal fields of the interactive form of inFile along with their values.
+func FormFieldsFile(inFile string, conf *pdfcpu.Configuration) ([]pdfcpu.FormField, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return FormFields(f, conf)
+}
+
+// ExportFormFields writes the form fields of rs as JSON to w.
+func ExportFormFields(rs io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: ExportFormFields: missing rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.EXPORTFORMFIELDS
+
+	ff, err := formFields(rs, conf)
+	if err != nil {
+		return err
+	}
+
+	bb, err := json.MarshalIndent(pdfcpu.FormData{Fields: ff}, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(bb)
+	return err
+}
+
+// ExportFormFieldsFile writes the form fields of inFile as JSON to jsonFile.
+func ExportFormFieldsFile(inFile, jsonFile string, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+	defer f1.Close()
+
+	log.CLI.Printf("writing %s...\n", jsonFile)
+	if f2, err = os.Create(jsonFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			os.Remove(jsonFile)
+			return
+		}
+		err = f2.Close()
+	}()
+
+	return ExportFormFields(f1, f2, conf)
+}
+
+// ReadFormData reads form field values in the JSON format written by ExportFormFields.
+func ReadFormData(r io.Reader) ([]pdfcpu.FormField, error) {
+	bb, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var fd pdfcpu.FormData
+	if err := json.Unmarshal(bb, &fd); err != nil {
+		return nil, errors.Wrap(err, "pdfcpu: invalid form data")
+	}
+
+	return fd.Fields, nil
+}
+
+// FillForm sets the values of the form fields of rs identified by the names of fields and writes the result to w.
+// Unless needAppearances is set the appearance streams of the filled in fields get regenerated.
+func FillForm(rs io.ReadSeeker, w io.Writer, fields []pdfcpu.FormField, needAppearances bool, conf *pdfcpu.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: FillForm: missing rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.FILLFORMFIELDS
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "fill form")
+
+	if err = ctx.FillFormFields(fields, needAppearances); err != nil {
+		return err
+	}
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	return WriteContext(ctx, w)
+}
+
+// FormFiller fills the interactive form of a template parsed once and kept in memory.
+// Each fill operates on a copy of the template, so any number of variants may be filled and written concurrently.
+type FormFiller struct {
+	ctx *pdfcpu.Context
+}
+
+// NewFormFiller reads, validates and optimizes the form template rs.
+// Incremental writing is not supported since variants must not access rs,
+// signed templates get refused under SignaturePolicyIncremental.
+func NewFormFiller(rs io.ReadSeeker, conf *pdfcpu.Configuration) (*FormFiller, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: NewFormFiller: missing rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	// The template context keeps conf, don't touch the caller's copy.
+	c := *conf
+	conf = &c
+	conf.Cmd = pdfcpu.FILLFORMFIELDS
+	conf.Incremental = false
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Incremental {
+		// Switched on by SignaturePolicyIncremental for a signed template.
+		return nil, pdfcpu.ErrSigned
+	}
+
+	if _, err := ctx.FormFields(); err != nil {
+		return nil, err
+	}
+
+	return &FormFiller{ctx: ctx}, nil
+}
+
+// Fill sets the values of the form fields of a copy of the template identified by the names of fields
+// and writes the result to w.
+// Unless needAppearances is set the appearance streams of the filled in fields get regenerated.
+// Fill is safe for concurrent use.
+func (ff *FormFiller) Fill(w io.Writer, fields []pdfcpu.FormField, needAppearances bool) error {
+	ctx := ff.ctx.Clone()
+	ctx.Timing = pdfcpu.OperationTiming{Start: time.Now()}
+	defer pdfcpu.PublishContextMetrics(ctx, "fill form")
+
+	if err := ctx.FillFormFields(fields, needAppearances); err != nil {
+		return err
+	}
+
+	if ctx.Configuration.ValidationMode != pdfcpu.ValidationNone {
+		if err := ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	return WriteContext(ctx, w)
+}
+
+// FillFormFile sets the values of the form fields of inFile according to the JSON form data in jsonFile
+// and writes the result to outFile.
+func FillFormFile(inFile, jsonFile, outFile string, needAppearances bool, conf *pdfcpu.Configuration) (err error) {
+	var f0, f1, f2 *os.File
+
+	if f0, err = os.Open(jsonFile); err != nil {
+		return err
+	}
+	fields, err := ReadFormData(f0)
+	f0.Close()
+	if err != nil {
+		return err
+	}
+
+	log.CLI.Printf("filling form of %s\n", inFile)
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
+				return
+			}
+		}
+	}()
+
+	return FillForm(f1, f2, fields, needAppearances, conf)
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// ListTabOrders returns a list of the tab orders for selected pages of rs.
+func ListTabOrders(rs io.ReadSeeker, selectedPages []string, conf *pdfcpu.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: ListTabOrders: missing rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+		conf.Cmd = pdfcpu.LISTFORMORDER
+	}
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "list tab orders")
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.ListTabOrders(pages)
+}
+
+// ListTabOrdersFile returns a list of the tab orders for selected pages of inFile.
+func ListTabOrdersFile(inFile string, selectedPages []string, conf *pdfcpu.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ListTabOrders(f, selectedPages, conf)
+}
+
+// SetTabOrder sets the tab order for selected pages of rs and writes the result to w.
+// tabs is one of R, C, S, A, W or its description eg. "row". An empty tabs removes the tab order.
+func SetTabOrder(rs io.ReadSeeker, w io.Writer, selectedPages []string, tabs string, conf *pdfcpu.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: SetTabOrder: missing rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.SETFORMORDER
+
+	tabs, err := pdfcpu.ParseTabOrder(tabs)
+	if err != nil {
+		return err
+	}
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "set tab order")
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return err
+	}
+
+	if err = ctx.SetTabOrder(pages, tabs); err != nil {
+		return err
+	}
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	return WriteContext(ctx, w)
+}
+
+// SetTabOrderFile sets the tab order for selected pages of inFile and writes the result to outFile.
+func SetTabOrderFile(inFile, outFile string, selectedPages []string, tabs string, conf *pdfcpu.Configuration) error {
+	log.CLI.Printf("setting tab order for %s\n", inFile)
+	var (
+		f1, f2 *os.File
+		err    error
+	)
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
+				return
+			}
+		}
+	}()
+
+	return SetTabOrder(f1, f2, selectedPages, tabs, conf)
+}
+
+// CalculationOrder returns the fully qualified names of the form fields of rs in calculation order.
+func CalculationOrder(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: CalculationOrder: missing rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+		conf.Cmd = pdfcpu.LISTFORMORDER
+	}
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "list calculation order")
+
+	return ctx.CalculationOrder()
+}
+
+// CalculationOrderFile returns the fully qualified names of the form fields of inFile in calculation order.
+func CalculationOrderFile(inFile string, conf *pdfcpu.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return CalculationOrder(f, conf)
+}
+
+// SetCalculationOrder sets the calculation order of the form fields of rs and writes the result to w.
+// Fields are identified by their fully qualified names. An empty list removes the calculation order.
+func SetCalculationOrder(rs io.ReadSeeker, w io.Writer, fieldNames []string, conf *pdfcpu.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: SetCalculationOrder: missing rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.SETFORMORDER
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "set calculation order")
+
+	if err = ctx.SetCalculationOrder(fieldNames); err != nil {
+		return err
+	}
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	return WriteContext(ctx, w)
+}
+
+// SetCalculationOrderFile sets the calculation order of the form fields of inFile and writes the result to outFile.
+func SetCalculationOrderFile(inFile, outFile string, fieldNames []string, conf *pdfcpu.Configuration) error {
+	log.CLI.Printf("setting calculation order for %s\n", inFile)
+	var (
+		f1, f2 *os.File
+		err    error
+	)
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
+				return
+			}
+		}
+	}()
+
+	return SetCalculationOrder(f1, f2, fieldNames, conf)
+}
@@ -38,6 +38,8 @@ func ListImages(rs io.ReadSeeker, selectedPages []string, conf *pdfcpu.Configura
 	if err != nil {
 		return nil, err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "list images")
+
 	if err := ctx.EnsurePageCount(); err != nil {
 		return nil, err
 	}
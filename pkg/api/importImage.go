@@ -56,6 +56,7 @@ func ImportImages(rs io.ReadSeeker, w io.Writer, imgs []io.Reader, imp *pdfcpu.I
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "import images")
 
 	pagesIndRef, err := ctx.Pages()
 	if err != nil {
@@ -155,7 +156,7 @@ func ImportImagesFile(imgFiles []string, outFile string, imp *pdfcpu.Import, con
 			if err = f1.Close(); err != nil {
 				return
 			}
-			if err = os.Rename(tmpFile, outFile); err != nil {
+			if err = replaceFile(tmpFile, outFile, conf); err != nil {
 				return
 			}
 		}
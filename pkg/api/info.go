@@ -36,6 +36,8 @@ func Info(rs io.ReadSeeker, selectedPages []string, conf *pdfcpu.Configuration)
 	if err != nil {
 		return nil, err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "info")
+
 	if err := ctx.EnsurePageCount(); err != nil {
 		return nil, err
 	}
@@ -58,3 +60,27 @@ func InfoFile(inFile string, selectedPages []string, conf *pdfcpu.Configuration)
 	defer f.Close()
 	return Info(f, selectedPages, conf)
 }
+
+// Warnings returns all non fatal anomalies found while reading and validating rs.
+func Warnings(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]string, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "warnings")
+
+	return ctx.Warnings, nil
+}
+
+// WarningsFile returns all non fatal anomalies found while reading and validating inFile.
+func WarningsFile(inFile string, conf *pdfcpu.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Warnings(f, conf)
+}
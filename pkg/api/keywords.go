@@ -40,6 +40,7 @@ func ListKeywords(rs io.ReadSeeker, conf *pdf.Configuration) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer pdf.PublishContextMetrics(ctx, "list keywords")
 
 	fromWrite := time.Now()
 	list, err := pdf.KeywordsList(ctx.XRefTable)
@@ -73,6 +74,7 @@ func AddKeywords(rs io.ReadSeeker, w io.Writer, files []string, conf *pdf.Config
 		// Validation loads infodict.
 		conf.ValidationMode = pdf.ValidationRelaxed
 	}
+	conf.Cmd = pdf.ADDKEYWORDS
 
 	fromStart := time.Now()
 	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
@@ -132,7 +134,7 @@ func AddKeywordsFile(inFile, outFile string, files []string, conf *pdf.Configura
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -149,6 +151,7 @@ func RemoveKeywords(rs io.ReadSeeker, w io.Writer, keywords []string, conf *pdf.
 		// Validation loads infodict.
 		conf.ValidationMode = pdf.ValidationRelaxed
 	}
+	conf.Cmd = pdf.REMOVEKEYWORDS
 
 	fromStart := time.Now()
 	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
@@ -211,7 +214,7 @@ func RemoveKeywordsFile(inFile, outFile string, keywords []string, conf *pdf.Con
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// Manifest returns a manifest for rs capturing file hash, page count, signature status and attachment hashes.
+func Manifest(rs io.ReadSeeker, conf *pdfcpu.Configuration) (*pdfcpu.Manifest, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: Manifest: Please provide rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+		conf.Cmd = pdfcpu.MANIFEST
+	}
+
+	fileHash, err := pdfcpu.FileHash(rs)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "manifest")
+
+	return ctx.Manifest(fileHash)
+}
+
+// ManifestFile writes a JSON manifest for inFile to manifestFile.
+func ManifestFile(inFile, manifestFile string, conf *pdfcpu.Configuration) error {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	m, err := Manifest(f, conf)
+	if err != nil {
+		return err
+	}
+
+	bb, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	log.CLI.Printf("writing %s...\n", manifestFile)
+	return ioutil.WriteFile(manifestFile, bb, 0644)
+}
+
+// Verify checks rs against a manifest generated earlier.
+func Verify(rs io.ReadSeeker, want pdfcpu.Manifest, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+		conf.Cmd = pdfcpu.VERIFY
+	}
+
+	m, err := Manifest(rs, conf)
+	if err != nil {
+		return err
+	}
+
+	return m.Verify(want)
+}
+
+// VerifyFile checks inFile against the JSON manifest in manifestFile.
+func VerifyFile(inFile, manifestFile string, conf *pdfcpu.Configuration) error {
+	bb, err := ioutil.ReadFile(manifestFile)
+	if err != nil {
+		return err
+	}
+
+	var want pdfcpu.Manifest
+	if err := json.Unmarshal(bb, &want); err != nil {
+		return errors.Wrapf(err, "pdfcpu: invalid manifest %s", manifestFile)
+	}
+
+	f, err := os.Open(inFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	log.CLI.Printf("verifying %s against %s...\n", inFile, manifestFile)
+	return Verify(f, want, conf)
+}
@@ -60,6 +60,7 @@ func Merge(rsc []io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) error {
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctxDest, "merge")
 
 	ctxDest.EnsureVersionForWriting()
 
@@ -189,7 +190,7 @@ func MergeAppendFile(inFiles []string, outFile string, conf *pdfcpu.Configuratio
 			}
 		}
 		if f1 != nil {
-			if err = os.Rename(tmpFile, outFile); err != nil {
+			if err = replaceFile(tmpFile, outFile, conf); err != nil {
 				return
 			}
 		}
@@ -0,0 +1,124 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// DocumentMetadata returns the document metadata of rs taken from the document information dict and the XMP metadata stream.
+func DocumentMetadata(rs io.ReadSeeker, conf *pdf.Configuration) (*pdf.DocumentMetadata, error) {
+	if conf == nil {
+		conf = pdf.NewDefaultConfiguration()
+	}
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer pdf.PublishContextMetrics(ctx, "document metadata")
+
+	return ctx.DocumentMetadata()
+}
+
+// DocumentMetadataFile returns the document metadata of inFile.
+func DocumentMetadataFile(inFile string, conf *pdf.Configuration) (*pdf.DocumentMetadata, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DocumentMetadata(f, conf)
+}
+
+// SetDocumentMetadata updates the document information dict and the XMP metadata stream of a PDF context read from rs
+// with all values set in m and writes the result to w.
+func SetDocumentMetadata(rs io.ReadSeeker, w io.Writer, m pdf.DocumentMetadata, conf *pdf.Configuration) error {
+	if conf == nil {
+		conf = pdf.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdf.ADDPROPERTIES
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	if err = ctx.SetDocumentMetadata(m); err != nil {
+		return err
+	}
+
+	durSet := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durSet + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "set metadata, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// SetDocumentMetadataFile updates the document metadata of inFile and writes the result to outFile.
+func SetDocumentMetadataFile(inFile, outFile string, m pdf.DocumentMetadata, conf *pdf.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = replaceFile(tmpFile, inFile, conf)
+		}
+	}()
+
+	return SetDocumentMetadata(f1, f2, m, conf)
+}
@@ -131,6 +131,7 @@ func NUp(rs io.ReadSeeker, w io.Writer, imgFiles, selectedPages []string, nup *p
 		}
 
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "nup")
 
 	if conf.ValidationMode != pdfcpu.ValidationNone {
 		if err = ValidateContext(ctx); err != nil {
@@ -158,7 +159,11 @@ func NUpFile(inFiles []string, outFile string, selectedPages []string, nup *pdfc
 		}
 	}
 
-	if f2, err = os.Create(outFile); err != nil {
+	tmpFile := outFile
+	if f1 != nil && inFiles[0] == outFile {
+		tmpFile += ".tmp"
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
 		return err
 	}
 	log.CLI.Printf("writing %s...\n", outFile)
@@ -169,6 +174,9 @@ func NUpFile(inFiles []string, outFile string, selectedPages []string, nup *pdfc
 				f1.Close()
 			}
 			f2.Close()
+			if tmpFile != outFile {
+				os.Remove(tmpFile)
+			}
 			return
 		}
 		if f1 != nil {
@@ -176,9 +184,12 @@ func NUpFile(inFiles []string, outFile string, selectedPages []string, nup *pdfc
 				return
 			}
 		}
-		err = f2.Close()
-		return
-
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if tmpFile != outFile {
+			err = replaceFile(tmpFile, outFile, conf)
+		}
 	}()
 
 	return NUp(f1, f2, inFiles, selectedPages, nup, conf)
@@ -0,0 +1,122 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// RunOperation applies the operation registered under name to rs and writes the result to w.
+func RunOperation(rs io.ReadSeeker, w io.Writer, name string, conf *pdfcpu.Configuration) error {
+	op, ok := pdfcpu.LookupOperation(name)
+	if !ok {
+		return errors.Errorf("pdfcpu: unknown operation: %s", name)
+	}
+
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.RUN
+
+	if conf.AuditLog && conf.AuditParameters == nil {
+		conf.AuditParameters = map[string]string{"operation": name}
+	}
+
+	if err := op.Validate(conf); err != nil {
+		return err
+	}
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, name)
+
+	from := time.Now()
+	if err := op.Execute(ctx); err != nil {
+		return errors.Wrapf(err, "pdfcpu: operation %s", name)
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	durRun := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durRun + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, name+", write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// RunOperationFile applies the operation registered under name to inFile and writes the result to outFile.
+func RunOperationFile(inFile, outFile, name string, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
+				return
+			}
+		}
+	}()
+
+	return RunOperation(f1, f2, name, conf)
+}
@@ -17,6 +17,7 @@
 package api
 
 import (
+	"context"
 	"io"
 	"os"
 	"time"
@@ -28,23 +29,55 @@ import (
 
 // Optimize reads a PDF stream from rs and writes the optimized PDF stream to w.
 func Optimize(rs io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) error {
+	_, err := OptimizeWithStats(rs, w, conf)
+	return err
+}
+
+// OptimizeWithContext reads a PDF stream from rs and writes the optimized PDF stream to w.
+// Processing stops with c's error once c is done.
+func OptimizeWithContext(c context.Context, rs io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) error {
+	_, err := optimizeWithStats(c, rs, w, conf)
+	return err
+}
+
+// OptimizeWithStats reads a PDF stream from rs, writes the optimized PDF stream to w
+// and returns the stream data saved per category.
+func OptimizeWithStats(rs io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) (*pdfcpu.OptimizationStats, error) {
+	return optimizeWithStats(context.Background(), rs, w, conf)
+}
+
+func optimizeWithStats(c context.Context, rs io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) (*pdfcpu.OptimizationStats, error) {
 	if conf == nil {
 		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	switch conf.Cmd {
+	case pdfcpu.ENCRYPT, pdfcpu.DECRYPT, pdfcpu.CHANGEUPW, pdfcpu.CHANGEOPW:
+		// Security handler changes get written by optimization, see EncryptFile.
+	default:
 		conf.Cmd = pdfcpu.OPTIMIZE
 	}
 
 	fromStart := time.Now()
 
-	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimizeWithContext(c, rs, conf, fromStart)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if ctx.ConsolidateResources {
+		// Share identical page resources and hoist common page attributes.
+		from := time.Now()
+		if err = ctx.ConsolidatePageTree(); err != nil {
+			return nil, err
+		}
+		durOpt += time.Since(from).Seconds()
 	}
 
 	log.Stats.Printf("XRefTable:\n%s\n", ctx)
 	fromWrite := time.Now()
 
 	if err = WriteContext(ctx, w); err != nil {
-		return err
+		return nil, err
 	}
 
 	durWrite := time.Since(fromWrite).Seconds()
@@ -55,21 +88,32 @@ func Optimize(rs io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) error {
 	if ctx.StatsFileName != "" {
 		err = pdfcpu.AppendStatsFile(ctx)
 		if err != nil {
-			return errors.Wrap(err, "Write stats failed.")
+			return nil, errors.Wrap(err, "Write stats failed.")
 		}
 	}
 
-	return nil
+	stats := ctx.OptimizationStats()
+	log.Stats.Printf("optimization %s\n", stats)
+
+	return &stats, nil
 }
 
 // OptimizeFile reads inFile and writes the optimized PDF to outFile.
 // If outFile is not provided then inFile gets overwritten
 // which leads to the same result as when inFile equals outFile.
-func OptimizeFile(inFile, outFile string, conf *pdfcpu.Configuration) (err error) {
+func OptimizeFile(inFile, outFile string, conf *pdfcpu.Configuration) error {
+	_, err := OptimizeFileWithStats(inFile, outFile, conf)
+	return err
+}
+
+// OptimizeFileWithStats reads inFile, writes the optimized PDF to outFile
+// and returns the stream data saved per category.
+// If outFile is not provided then inFile gets overwritten.
+func OptimizeFileWithStats(inFile, outFile string, conf *pdfcpu.Configuration) (stats *pdfcpu.OptimizationStats, err error) {
 	var f1, f2 *os.File
 
 	if f1, err = os.Open(inFile); err != nil {
-		return err
+		return nil, err
 	}
 
 	tmpFile := inFile + ".tmp"
@@ -81,7 +125,7 @@ func OptimizeFile(inFile, outFile string, conf *pdfcpu.Configuration) (err error
 	}
 
 	if f2, err = os.Create(tmpFile); err != nil {
-		return err
+		return nil, err
 	}
 
 	defer func() {
@@ -98,11 +142,11 @@ func OptimizeFile(inFile, outFile string, conf *pdfcpu.Configuration) (err error
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
 	}()
 
-	return Optimize(f1, f2, conf)
+	return OptimizeWithStats(f1, f2, conf)
 }
@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// OrphanedObjects returns all objects of rs unreachable from the trailer.
+// The report reflects the file as read, before any garbage collection on write.
+func OrphanedObjects(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]pdfcpu.OrphanedObject, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.ORPHANS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "orphans")
+
+	return ctx.OrphanedObjects()
+}
+
+// OrphanedObjectsFile returns all objects of inFile unreachable from the trailer.
+func OrphanedObjectsFile(inFile string, conf *pdfcpu.Configuration) ([]pdfcpu.OrphanedObject, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return OrphanedObjects(f, conf)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// PageSizes returns the bytes of rs attributed to selected pages, shared resources and the rest of the document.
+func PageSizes(rs io.ReadSeeker, selectedPages []string, conf *pdfcpu.Configuration) (*pdfcpu.PageSizeReport, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.PAGESIZES
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "page sizes")
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := ctx.PageSizes()
+	if err != nil {
+		return nil, err
+	}
+
+	pp := r.Pages[:0]
+	for _, ps := range r.Pages {
+		if pages[ps.PageNr] {
+			pp = append(pp, ps)
+		}
+	}
+	r.Pages = pp
+
+	return r, nil
+}
+
+// PageSizesFile returns the bytes of inFile attributed to selected pages, shared resources and the rest of the document.
+func PageSizesFile(inFile string, selectedPages []string, conf *pdfcpu.Configuration) (*pdfcpu.PageSizeReport, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return PageSizes(f, selectedPages, conf)
+}
@@ -41,6 +41,7 @@ func InsertPages(rs io.ReadSeeker, w io.Writer, selectedPages []string, before b
 	if err != nil {
 		return err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "insert pages")
 
 	if err := ctx.EnsurePageCount(); err != nil {
 		return err
@@ -105,7 +106,7 @@ func InsertPagesFile(inFile, outFile string, selectedPages []string, before bool
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -191,7 +192,7 @@ func RemovePagesFile(inFile, outFile string, selectedPages []string, conf *pdfcp
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -206,6 +207,8 @@ func PageCount(rs io.ReadSeeker, conf *pdfcpu.Configuration) (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "page count")
+
 	if err := ValidateContext(ctx); err != nil {
 		return 0, err
 	}
@@ -229,6 +232,7 @@ func PageDims(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]pdfcpu.Dim, error
 	if err != nil {
 		return nil, err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "page dims")
 
 	pd, err := ctx.PageDims()
 	if err != nil {
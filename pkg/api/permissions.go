@@ -38,6 +38,7 @@ func ListPermissions(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]string, er
 	if err != nil {
 		return nil, err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "list permissions")
 
 	fromList := time.Now()
 	list := pdfcpu.Permissions(ctx)
@@ -130,7 +131,7 @@ func SetPermissionsFile(inFile, outFile string, conf *pdfcpu.Configuration) (err
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -148,6 +149,8 @@ func GetPermissions(rs io.ReadSeeker, conf *pdfcpu.Configuration) (*int16, error
 	if err != nil {
 		return nil, err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "get permissions")
+
 	if ctx.E == nil {
 		// Full access - permissions don't apply.
 		return nil, nil
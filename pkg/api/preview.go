@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"image"
+	"io"
+	"strconv"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// Preview renders page pageNr of rs at a resolution of dpi using the registered rasterizer.
+// Only the objects used by this page get parsed and validation is skipped
+// which keeps the latency low for thumbnail services regardless of the document size.
+func Preview(rs io.ReadSeeker, pageNr, dpi int, conf *pdfcpu.Configuration) (image.Image, error) {
+	r, ok := pdfcpu.RegisteredRasterizer()
+	if !ok {
+		return nil, pdfcpu.ErrNoRasterizer
+	}
+
+	if pageNr < 1 {
+		return nil, errors.Errorf("pdfcpu: invalid page number: %d", pageNr)
+	}
+
+	if dpi <= 0 {
+		return nil, errors.Errorf("pdfcpu: invalid dpi: %d", dpi)
+	}
+
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.PREVIEW
+
+	ctx, err := ReadContextPages(rs, []string{strconv.Itoa(pageNr)}, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if pageNr > ctx.PageCount {
+		return nil, errors.Errorf("pdfcpu: invalid page number: %d", pageNr)
+	}
+
+	return r(ctx, pageNr, dpi)
+}
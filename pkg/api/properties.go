@@ -40,6 +40,7 @@ func ListProperties(rs io.ReadSeeker, conf *pdf.Configuration) ([]string, error)
 	if err != nil {
 		return nil, err
 	}
+	defer pdf.PublishContextMetrics(ctx, "list properties")
 
 	fromWrite := time.Now()
 	list, err := pdf.PropertiesList(ctx.XRefTable)
@@ -73,6 +74,7 @@ func AddProperties(rs io.ReadSeeker, w io.Writer, properties map[string]string,
 		// Validation loads infodict.
 		conf.ValidationMode = pdf.ValidationRelaxed
 	}
+	conf.Cmd = pdf.ADDPROPERTIES
 
 	fromStart := time.Now()
 	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
@@ -132,7 +134,7 @@ func AddPropertiesFile(inFile, outFile string, properties map[string]string, con
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -149,6 +151,7 @@ func RemoveProperties(rs io.ReadSeeker, w io.Writer, properties []string, conf *
 		// Validation loads infodict.
 		conf.ValidationMode = pdf.ValidationRelaxed
 	}
+	conf.Cmd = pdf.REMOVEPROPERTIES
 
 	fromStart := time.Now()
 	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
@@ -211,7 +214,7 @@ func RemovePropertiesFile(inFile, outFile string, properties []string, conf *pdf
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// RequiredVersion returns the minimum PDF version able to express the content of rs
+// along with the features responsible for it.
+func RequiredVersion(rs io.ReadSeeker, conf *pdfcpu.Configuration) (pdfcpu.Version, []pdfcpu.VersionFeature, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.INFO
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return pdfcpu.V10, nil, err
+	}
+
+	return ctx.RequiredVersion()
+}
+
+// RequiredVersionFile returns the minimum PDF version able to express the content of inFile
+// along with the features responsible for it.
+func RequiredVersionFile(inFile string, conf *pdfcpu.Configuration) (pdfcpu.Version, []pdfcpu.VersionFeature, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return pdfcpu.V10, nil, err
+	}
+	defer f.Close()
+	return RequiredVersion(f, conf)
+}
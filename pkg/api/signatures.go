@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"crypto/x509"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/signatures"
+	"github.com/pkg/errors"
+)
+
+// ValidateSignatures validates all digital signatures of rs.
+// Signer certificates are checked against roots or against the system trust store if roots is nil.
+func ValidateSignatures(rs io.ReadSeeker, roots *x509.CertPool, conf *pdfcpu.Configuration) ([]signatures.Result, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: ValidateSignatures: missing rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.VALIDATESIGNATURES
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "validate signatures")
+
+	return signatures.Validate(ctx, rs, roots)
+}
+
+// ValidateSignaturesFile validates all digital signatures of inFile.
+// trustStore is a certificate file or a directory of certificate files holding the trusted roots.
+// If trustStore is empty the system trust store is used.
+func ValidateSignaturesFile(inFile, trustStore string, conf *pdfcpu.Configuration) ([]signatures.Result, error) {
+	var roots *x509.CertPool
+	if trustStore != "" {
+		var err error
+		if roots, err = signatures.LoadTrustStore(trustStore); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ValidateSignatures(f, roots, conf)
+}
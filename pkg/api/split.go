@@ -17,6 +17,7 @@
 package api
 
 import (
+	"bytes"
 	"io"
 	"os"
 	"path/filepath"
@@ -26,6 +27,7 @@ import (
 
 	"github.com/pdfcpu/pdfcpu/pkg/log"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
 )
 
 func spanFileName(fileName string, from, thru int) string {
@@ -38,6 +40,19 @@ func spanFileName(fileName string, from, thru int) string {
 	return fn + "-" + strconv.Itoa(thru) + ".pdf"
 }
 
+// templateFileName returns the file name for pages from thru of ctx generated by the configured file name template.
+func templateFileName(ctx *pdfcpu.Context, fileName string, from, thru int, title string) (string, error) {
+	vars := map[string]interface{}{
+		"name":      strings.TrimSuffix(filepath.Base(fileName), ".pdf"),
+		"from":      from,
+		"thru":      thru,
+		"page":      from,
+		"pagecount": ctx.PageCount,
+		"title":     title,
+	}
+	return pdfcpu.ExpandTemplate(ctx.FileNameTemplate, vars)
+}
+
 func writeSpan(ctx *pdfcpu.Context, from, thru int, outDir, fileName string, forBookmark bool) error {
 	selectedPages := PagesForPageRange(from, thru)
 
@@ -62,66 +77,75 @@ func writeSpan(ctx *pdfcpu.Context, from, thru int, outDir, fileName string, for
 	return pdfcpu.Write(ctxDest)
 }
 
-func writePageSpan(ctx *pdfcpu.Context, from, thru int, outDir, fileName string, forBookmark bool) error {
-	selectedPages := PagesForPageRange(from, thru)
-
-	// Create context with copies of selectedPages.
-	ctxNew, err := ctx.ExtractPages(selectedPages, false)
-	if err != nil {
-		return err
-	}
+// PageSpan represents a sequence of pages split off a PDF file.
+type PageSpan struct {
+	From   int
+	Thru   int
+	Title  string    // Bookmark title when splitting along bookmarks.
+	Reader io.Reader // A self contained PDF holding pages From thru Thru.
+}
 
-	// Write context to file.
-	outFile := filepath.Join(outDir, fileName+".pdf")
-	if !forBookmark {
-		outFile = filepath.Join(outDir, spanFileName(fileName, from, thru))
+// pageSpans returns the page spans for ctx obeying given split span.
+func pageSpans(ctx *pdfcpu.Context, span int) ([]*PageSpan, error) {
+	if span < 0 {
+		return nil, errors.Errorf("pdfcpu: invalid split span: %d", span)
 	}
 
-	return WriteContextFile(ctxNew, outFile)
-}
+	var pss []*PageSpan
 
-func writePageSpansSplitAlongBookmarks(ctx *pdfcpu.Context, outDir string) error {
-	bms, err := ctx.BookmarksForOutline()
-	if err != nil {
-		return err
+	if span == 0 {
+		bms, err := ctx.BookmarksForOutline()
+		if err != nil {
+			return nil, err
+		}
+		for _, bm := range bms {
+			thru := bm.PageThru
+			if thru == 0 {
+				thru = ctx.PageCount
+			}
+			pss = append(pss, &PageSpan{From: bm.PageFrom, Thru: thru, Title: bm.Title})
+		}
+		return pss, nil
 	}
-	for _, bm := range bms {
-		fileName := strings.Replace(bm.Title, " ", "_", -1)
-		from := bm.PageFrom
-		thru := bm.PageThru
-		if thru == 0 {
+
+	// A possible last span has less than span pages.
+	for from := 1; from <= ctx.PageCount; from += span {
+		thru := from + span - 1
+		if thru > ctx.PageCount {
 			thru = ctx.PageCount
 		}
-		forBookmark := true
-		if err := writePageSpan(ctx, from, thru, outDir, fileName, forBookmark); err != nil {
-			return err
-		}
+		pss = append(pss, &PageSpan{From: from, Thru: thru})
 	}
-	return nil
+
+	return pss, nil
 }
 
-func writePageSpans(ctx *pdfcpu.Context, span int, outDir, fileName string) error {
-	if span == 0 {
-		return writePageSpansSplitAlongBookmarks(ctx, outDir)
+// forEachPageSpan calls write for each page span of ctx obeying given split span.
+func forEachPageSpan(ctx *pdfcpu.Context, span int, fileName string, write func(fn string, ctxNew *pdfcpu.Context) error) error {
+	pss, err := pageSpans(ctx, span)
+	if err != nil {
+		return err
 	}
 
-	forBookmark := false
-
-	for i := 0; i < ctx.PageCount/span; i++ {
-		start := i * span
-		from := start + 1
-		thru := start + span
-		if err := writePageSpan(ctx, from, thru, outDir, fileName, forBookmark); err != nil {
+	forBookmark := span == 0
+
+	for _, ps := range pss {
+		var fn string
+		switch {
+		case ctx.FileNameTemplate != "":
+			if fn, err = templateFileName(ctx, fileName, ps.From, ps.Thru, ps.Title); err != nil {
+				return err
+			}
+		case forBookmark:
+			fn = strings.Replace(ps.Title, " ", "_", -1) + ".pdf"
+		default:
+			fn = spanFileName(fileName, ps.From, ps.Thru)
+		}
+		ctxNew, err := ctx.ExtractPages(PagesForPageRange(ps.From, ps.Thru), false)
+		if err != nil {
 			return err
 		}
-	}
-
-	// A possible last file has less than span pages.
-	if ctx.PageCount%span > 0 {
-		start := (ctx.PageCount / span) * span
-		from := start + 1
-		thru := ctx.PageCount
-		if err := writePageSpan(ctx, from, thru, outDir, fileName, forBookmark); err != nil {
+		if err := write(fn, ctxNew); err != nil {
 			return err
 		}
 	}
@@ -129,6 +153,12 @@ func writePageSpans(ctx *pdfcpu.Context, span int, outDir, fileName string) erro
 	return nil
 }
 
+func writePageSpans(ctx *pdfcpu.Context, span int, outDir, fileName string) error {
+	return forEachPageSpan(ctx, span, fileName, func(fn string, ctxNew *pdfcpu.Context) error {
+		return WriteContextFile(ctxNew, filepath.Join(outDir, fn))
+	})
+}
+
 // Split generates a sequence of PDF files in outDir for the PDF stream read from rs obeying given split span.
 // If span == 1 splitting results in single page PDFs.
 // If span == 0 we split along given bookmarks (level 1 only).
@@ -163,6 +193,92 @@ func Split(rs io.ReadSeeker, outDir, fileName string, span int, conf *pdfcpu.Con
 	return nil
 }
 
+// SplitRaw returns the page spans of the PDF stream read from rs obeying given split span
+// each of them holding a self contained PDF.
+// If span == 1 splitting results in single page PDFs.
+// If span == 0 we split along given bookmarks (level 1 only).
+func SplitRaw(rs io.ReadSeeker, span int, conf *pdfcpu.Configuration) ([]*PageSpan, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: SplitRaw: Please provide rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.SPLIT
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "split")
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	pss, err := pageSpans(ctx, span)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ps := range pss {
+		ctxNew, err := ctx.ExtractPages(PagesForPageRange(ps.From, ps.Thru), false)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := WriteContext(ctxNew, &buf); err != nil {
+			return nil, err
+		}
+		ps.Reader = &buf
+	}
+
+	return pss, nil
+}
+
+// SplitZip writes the sequence of PDF files for the PDF stream read from rs obeying given split span
+// as zip archive to w instead of creating files in a directory.
+// If password is not empty the zip entries get AES-256 encrypted.
+// If span == 1 splitting results in single page PDFs.
+// If span == 0 we split along given bookmarks (level 1 only).
+func SplitZip(rs io.ReadSeeker, w io.Writer, fileName string, span int, password string, conf *pdfcpu.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: SplitZip: Please provide rs")
+	}
+	if w == nil {
+		return errors.New("pdfcpu: SplitZip: Please provide w")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.SPLIT
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return err
+	}
+	defer pdfcpu.PublishContextMetrics(ctx, "split")
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	zw := newZipWriter(w, password, ctx.Rand())
+
+	err = forEachPageSpan(ctx, span, fileName, func(fn string, ctxNew *pdfcpu.Context) error {
+		w1, err := zw.create(fn)
+		if err != nil {
+			return err
+		}
+		return WriteContext(ctxNew, w1)
+	})
+	if err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
 // SplitFile generates a sequence of PDF files in outDir for inFile obeying given split span.
 // If span == 1 splitting results in single page PDFs.
 // If span == 0 we split along given bookmarks (level 1 only).
@@ -109,7 +109,7 @@ func AddWatermarksMapFile(inFile, outFile string, m map[int]*pdfcpu.Watermark, c
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -196,7 +196,7 @@ func AddWatermarksSliceMapFile(inFile, outFile string, m map[int][]*pdfcpu.Water
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -291,7 +291,7 @@ func AddWatermarksFile(inFile, outFile string, selectedPages []string, wm *pdfcp
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -382,7 +382,7 @@ func RemoveWatermarksFile(inFile, outFile string, selectedPages []string, conf *
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
@@ -397,6 +397,8 @@ func HasWatermarks(rs io.ReadSeeker, conf *pdfcpu.Configuration) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	defer pdfcpu.PublishContextMetrics(ctx, "has watermarks")
+
 	if err := ctx.DetectWatermarks(); err != nil {
 		return false, err
 	}
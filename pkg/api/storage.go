@@ -0,0 +1,58 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/storage"
+)
+
+// ReadContextStorage reads the PDF file name from s, eg. an object in a S3 bucket, and returns its context.
+func ReadContextStorage(s storage.Storage, name string, conf *pdfcpu.Configuration) (*pdfcpu.Context, error) {
+	f, err := s.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+
+	return ReadContext(f, conf)
+}
+
+// WriteContextStorage writes ctx as name to s.
+// If writing fails no partial file gets stored provided the writer of s implements storage.ErrorCloser.
+func WriteContextStorage(ctx *pdfcpu.Context, s storage.Storage, name string) (err error) {
+	w, err := s.Create(name)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if ec, ok := w.(storage.ErrorCloser); ok && err != nil {
+			ec.CloseWithError(err)
+			return
+		}
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	return WriteContext(ctx, w)
+}
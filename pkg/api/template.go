@@ -0,0 +1,226 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// ReadTemplate reads a JSON template definition like:
+//
+//	{"regions": [
+//		{"name": "name", "page": 1, "rect": [50, 700, 300, 720], "font": "Helvetica-Bold", "fontSize": 14},
+//		{"name": "photo", "page": 1, "rect": [400, 650, 500, 750], "image": true, "pos": "c"}
+//	]}
+func ReadTemplate(r io.Reader) (*pdfcpu.Template, error) {
+	bb, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var t pdfcpu.Template
+	if err := json.Unmarshal(bb, &t); err != nil {
+		return nil, errors.Wrap(err, "pdfcpu: invalid template")
+	}
+
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// ReadTemplateRecords reads a JSON object or an array of JSON objects mapping region names to values.
+// The value of an image region is the name of an image file.
+func ReadTemplateRecords(r io.Reader) ([]pdfcpu.TemplateRecord, error) {
+	bb, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	bb = bytes.TrimSpace(bb)
+
+	if len(bb) > 0 && bb[0] == '{' {
+		var rec pdfcpu.TemplateRecord
+		if err := json.Unmarshal(bb, &rec); err != nil {
+			return nil, errors.Wrap(err, "pdfcpu: invalid template record")
+		}
+		return []pdfcpu.TemplateRecord{rec}, nil
+	}
+
+	var recs []pdfcpu.TemplateRecord
+	if err := json.Unmarshal(bb, &recs); err != nil {
+		return nil, errors.Wrap(err, "pdfcpu: invalid template records")
+	}
+
+	return recs, nil
+}
+
+func openTemplateImages(t *pdfcpu.Template, record pdfcpu.TemplateRecord) (map[string]io.Reader, func(), error) {
+	images := map[string]io.Reader{}
+	var ff []*os.File
+
+	closeAll := func() {
+		for _, f := range ff {
+			f.Close()
+		}
+	}
+
+	for _, tr := range t.Regions {
+		fileName, ok := record[tr.Name]
+		if !tr.Image || !ok {
+			continue
+		}
+		if _, ok := images[fileName]; ok {
+			continue
+		}
+		f, err := os.Open(fileName)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		ff = append(ff, f)
+		images[fileName] = f
+	}
+
+	return images, closeAll, nil
+}
+
+// FillTemplate renders the values of record into the regions of t defined for rs and writes the result to w.
+// The values of image regions are image file names.
+func FillTemplate(rs io.ReadSeeker, w io.Writer, t *pdfcpu.Template, record pdfcpu.TemplateRecord, conf *pdfcpu.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: FillTemplate: missing rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.FILLTEMPLATE
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	images, closeImages, err := openTemplateImages(t, record)
+	if err != nil {
+		return err
+	}
+	defer closeImages()
+
+	from := time.Now()
+
+	if err = ctx.FillTemplate(t, record, images); err != nil {
+		return err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	durFill := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durFill + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "fill template, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+func fillTemplateFile(f *os.File, outFile string, t *pdfcpu.Template, record pdfcpu.TemplateRecord, conf *pdfcpu.Configuration) (err error) {
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	log.CLI.Printf("writing %s...\n", outFile)
+	f1, err := os.Create(outFile)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f1.Close()
+			os.Remove(outFile)
+			return
+		}
+		err = f1.Close()
+	}()
+
+	return FillTemplate(f, f1, t, record, conf)
+}
+
+// FillTemplateFile fills the regions defined by the JSON template in templateFile for inFile
+// with each record of the JSON records in recordsFile and writes one file per record to outDir.
+// The files are named after inFile followed by the record number.
+func FillTemplateFile(inFile, templateFile, recordsFile, outDir string, conf *pdfcpu.Configuration) ([]string, error) {
+	f0, err := os.Open(templateFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f0.Close()
+
+	t, err := ReadTemplate(f0)
+	if err != nil {
+		return nil, err
+	}
+
+	f1, err := os.Open(recordsFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f1.Close()
+
+	recs, err := ReadTemplateRecords(f1)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fn := strings.TrimSuffix(filepath.Base(inFile), ".pdf")
+
+	var outFiles []string
+	for i, rec := range recs {
+		outFile := filepath.Join(outDir, fn+"_"+strconv.Itoa(i+1)+".pdf")
+		if err := fillTemplateFile(f, outFile, t, rec, conf); err != nil {
+			return outFiles, err
+		}
+		outFiles = append(outFiles, outFile)
+	}
+
+	return outFiles, nil
+}
@@ -17,8 +17,11 @@ limitations under the License.
 package test
 
 import (
+	"bytes"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
 	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
@@ -309,3 +312,125 @@ func TestAddRemoveAnnotationsAsIncrements(t *testing.T) {
 		t.Fatalf("%s list: %v\n", msg, err)
 	}
 }
+
+func TestAddRemoveAnnotationsByType(t *testing.T) {
+	msg := "TestAddRemoveAnnotationsByType"
+
+	fn := "test.pdf"
+	copyFile(t, filepath.Join(inDir, fn), filepath.Join(outDir, fn))
+	inFile := filepath.Join(outDir, fn)
+
+	for _, desc := range []string{
+		"type:link, rect:100 100 200 120, uri:https://pdfcpu.io",
+		"type:text, rect:50 700 70 720, contents:Please review, id:note",
+		"type:highlight, rect:72 600 300 614, color:#00FF00, opacity:.5",
+	} {
+		ar, err := api.AnnotationConfig(desc)
+		if err != nil {
+			t.Fatalf("%s config %s: %v\n", msg, desc, err)
+		}
+		if err := api.AddAnnotationsFile(inFile, "", []string{"1"}, ar, nil, false); err != nil {
+			t.Fatalf("%s add %s: %v\n", msg, desc, err)
+		}
+	}
+
+	aa, err := api.AnnotationsFile(inFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s annotations: %v\n", msg, err)
+	}
+	if len(aa) != 3 {
+		t.Fatalf("%s: want 3 annotations, got %d\n", msg, len(aa))
+	}
+	for _, a := range aa {
+		if a.Page != 1 || a.ObjNr == 0 || a.Flags&pdf.AnnPrint == 0 {
+			t.Fatalf("%s: unexpected annotation: %+v\n", msg, a)
+		}
+		if a.Type == "Text" && (a.ID != "note" || a.Contents != "Please review") {
+			t.Fatalf("%s: unexpected text annotation: %+v\n", msg, a)
+		}
+	}
+
+	if err := api.RemoveAnnotationsByTypeFile(inFile, "", nil, []string{"highlight", "Link"}, nil); err != nil {
+		t.Fatalf("%s remove: %v\n", msg, err)
+	}
+
+	aa, err = api.AnnotationsFile(inFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s annotations: %v\n", msg, err)
+	}
+	if len(aa) != 1 || aa[0].Type != "Text" {
+		t.Fatalf("%s: want the text annotation only, got %+v\n", msg, aa)
+	}
+
+	// There is nothing left to remove.
+	if err := api.RemoveAnnotationsByTypeFile(inFile, "", nil, []string{"Link"}, nil); err == nil {
+		t.Fatalf("%s remove: missing error\n", msg)
+	}
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestAnnotationsClock(t *testing.T) {
+	msg := "TestAnnotationsClock"
+
+	clock := fixedClock(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+	want := pdf.DateString(clock.Now())
+
+	inFile := filepath.Join(inDir, "test.pdf")
+
+	addAnnotation := func() []byte {
+		t.Helper()
+		conf := pdf.NewDefaultConfiguration()
+		conf.Cmd = pdf.ADDANNOTATIONS
+		conf.Clock = clock
+		rs, err := os.Open(inFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		defer rs.Close()
+		var buf bytes.Buffer
+		if err := api.AddAnnotations(rs, &buf, []string{"1"}, textAnn, conf); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return buf.Bytes()
+	}
+
+	bb := addAnnotation()
+
+	// The same clock produces the same file.
+	if !bytes.Equal(bb, addAnnotation()) {
+		t.Fatalf("%s: output not reproducible\n", msg)
+	}
+
+	ctx, err := api.ReadContext(bytes.NewReader(bb), pdf.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, err := ctx.DereferenceDict(*ctx.Info)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if s := d.StringEntry("ModDate"); s == nil || *s != want {
+		t.Errorf("%s: ModDate want %s, got %v\n", msg, want, s)
+	}
+
+	d, _, _, err = ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	annots, err := ctx.DereferenceArray(d["Annots"])
+	if err != nil || len(annots) != 1 {
+		t.Fatalf("%s: want 1 annotation, got %v %v\n", msg, annots, err)
+	}
+	d, err = ctx.DereferenceDict(annots[0])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if s := d.StringEntry("CreationDate"); s == nil || *s != want {
+		t.Errorf("%s: CreationDate want %s, got %v\n", msg, want, s)
+	}
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package test
 
 import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -153,6 +156,250 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestValidateWithObjectStreamCache(t *testing.T) {
+	msg := "TestValidateWithObjectStreamCache"
+	inFile := filepath.Join(inDir, "TheGoProgrammingLanguageCh1.pdf")
+
+	// Keep at most one decoded object stream in memory.
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.ObjectStreamCacheSize = 1
+
+	if err := api.ValidateFile(inFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+func readContextWithWorkers(t *testing.T, bb []byte, n int) (*pdfcpu.Context, error) {
+	t.Helper()
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.ReadWorkers = n
+	return api.ReadContext(bytes.NewReader(bb), conf)
+}
+
+func TestReadConcurrently(t *testing.T) {
+	msg := "TestReadConcurrently"
+
+	files, err := ioutil.ReadDir(inDir)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, f := range files {
+		if !isPDF(f.Name()) {
+			continue
+		}
+		bb, err := ioutil.ReadFile(filepath.Join(inDir, f.Name()))
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		ctx1, err1 := readContextWithWorkers(t, bb, 1)
+		ctx2, err2 := readContextWithWorkers(t, bb, 4)
+		if (err1 == nil) != (err2 == nil) {
+			t.Fatalf("%s: %s: want %v, got %v\n", msg, f.Name(), err1, err2)
+		}
+		if err1 != nil {
+			continue
+		}
+
+		// Both readers produce the same warnings in the same order.
+		if strings.Join(ctx1.Warnings, "\n") != strings.Join(ctx2.Warnings, "\n") {
+			t.Fatalf("%s: %s: want warnings %v, got %v\n", msg, f.Name(), ctx1.Warnings, ctx2.Warnings)
+		}
+
+		// Both readers produce the same objects.
+		if len(ctx1.Table) != len(ctx2.Table) {
+			t.Fatalf("%s: %s: want %d objects, got %d\n", msg, f.Name(), len(ctx1.Table), len(ctx2.Table))
+		}
+		for objNr, e1 := range ctx1.Table {
+			e2, ok := ctx2.Table[objNr]
+			if !ok || e1.Object == nil != (e2.Object == nil) {
+				t.Fatalf("%s: %s: obj#%d differs\n", msg, f.Name(), objNr)
+			}
+			if e1.Object == nil {
+				continue
+			}
+			if _, ok := e1.Object.(pdfcpu.ObjectStreamDict); ok {
+				continue
+			}
+			if e1.Object.PDFString() != e2.Object.PDFString() {
+				t.Fatalf("%s: %s: obj#%d differs\n", msg, f.Name(), objNr)
+			}
+			if sd1, ok := e1.Object.(pdfcpu.StreamDict); ok {
+				if sd2 := e2.Object.(pdfcpu.StreamDict); !bytes.Equal(sd1.Raw, sd2.Raw) {
+					t.Fatalf("%s: %s: obj#%d stream data differs\n", msg, f.Name(), objNr)
+				}
+			}
+		}
+	}
+}
+
+func traceEventCount(ctx *pdfcpu.Context, t pdfcpu.TraceEventType) int {
+	c := 0
+	for _, e := range ctx.Read.Trace {
+		if e.Type == t {
+			c++
+		}
+	}
+	return c
+}
+
+func TestParserTrace(t *testing.T) {
+	msg := "TestParserTrace"
+	inFile := filepath.Join(inDir, "read.go.pdf")
+
+	bb, err := ioutil.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Tracing is opt-in.
+	ctx, err := api.ReadContext(bytes.NewReader(bb), pdfcpu.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ctx.Read.Trace) > 0 {
+		t.Fatalf("%s: unexpected trace events\n", msg)
+	}
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.TraceParser = true
+	if ctx, err = api.ReadContext(bytes.NewReader(bb), conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for _, et := range []pdfcpu.TraceEventType{pdfcpu.TraceStartXRef, pdfcpu.TraceXRefSection, pdfcpu.TraceTrailer, pdfcpu.TraceObject} {
+		if traceEventCount(ctx, et) == 0 {
+			t.Errorf("%s: missing trace event %s\n", msg, et)
+		}
+	}
+	if traceEventCount(ctx, pdfcpu.TraceXRefRebuild) > 0 {
+		t.Errorf("%s: unexpected xref rebuild\n", msg)
+	}
+
+	// Corrupt the startxref offset to force a reconstruction of the xref table.
+	i := bytes.LastIndex(bb, []byte("startxref"))
+	bb = append(bb[:i:i], []byte("startxref\n9\n%%EOF\n")...)
+	if ctx, err = api.ReadContext(bytes.NewReader(bb), conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if traceEventCount(ctx, pdfcpu.TraceXRefRebuild) == 0 {
+		t.Errorf("%s: missing trace event %s\n", msg, pdfcpu.TraceXRefRebuild)
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	msg := "TestWarnings"
+	inFile := filepath.Join(inDir, "read.go.pdf")
+
+	bb, err := ioutil.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ww, err := api.Warnings(bytes.NewReader(bb), nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ww) > 0 {
+		t.Fatalf("%s: unexpected warnings: %v\n", msg, ww)
+	}
+
+	// Introduce anomalies without affecting any object offsets.
+	for _, r := range []struct{ old, new string }{
+		{"/Producer (Skia/PDF m80)", "/Creator  (Skia/PDF m80)"},
+		{"/ModDate (D:", "/ModDate   ("},
+		{"/Length 3593>>", "/Length 0000>>"},
+	} {
+		if !bytes.Contains(bb, []byte(r.old)) {
+			t.Fatalf("%s: missing %s\n", msg, r.old)
+		}
+		bb = bytes.Replace(bb, []byte(r.old), []byte(r.new), 1)
+	}
+
+	if ww, err = api.Warnings(bytes.NewReader(bb), nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, s := range []string{"duplicate dict key Creator", "invalid date", "corrected Length 0 to 3593"} {
+		found := false
+		for _, w := range ww {
+			if strings.Contains(w, s) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("%s: missing warning %q in %v\n", msg, s, ww)
+		}
+	}
+}
+
+type metricsCollector struct {
+	mm []pdfcpu.Metrics
+}
+
+func (mc *metricsCollector) OperationCompleted(m pdfcpu.Metrics) {
+	mc.mm = append(mc.mm, m)
+}
+
+func TestMetrics(t *testing.T) {
+	msg := "TestMetrics"
+	inFile := filepath.Join(inDir, "TheGoProgrammingLanguageCh1.pdf")
+	outFile := filepath.Join(outDir, "metrics.pdf")
+
+	mc := &metricsCollector{}
+	pdfcpu.SubscribeMetrics(mc)
+	defer pdfcpu.UnsubscribeMetrics(mc)
+
+	if err := api.OptimizeFile(inFile, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if len(mc.mm) != 1 {
+		t.Fatalf("%s: want 1 metrics notification, got %d\n", msg, len(mc.mm))
+	}
+
+	m := mc.mm[0]
+	if m.BytesRead == 0 || m.BytesWritten == 0 || m.ObjectsParsed == 0 || m.CacheMisses == 0 {
+		t.Fatalf("%s: incomplete metrics:\n%s\n", msg, m)
+	}
+}
+
+func TestMetricsMergeAndInfo(t *testing.T) {
+	msg := "TestMetricsMergeAndInfo"
+	inFiles := []string{
+		filepath.Join(inDir, "Acroforms2.pdf"),
+		filepath.Join(inDir, "adobe_errata.pdf"),
+	}
+	outFile := filepath.Join(outDir, "metricsMerge.pdf")
+
+	mc := &metricsCollector{}
+	pdfcpu.SubscribeMetrics(mc)
+	defer pdfcpu.UnsubscribeMetrics(mc)
+
+	if err := api.MergeCreateFile(inFiles, outFile, nil); err != nil {
+		t.Fatalf("%s: merge: %v\n", msg, err)
+	}
+
+	if _, err := api.InfoFile(outFile, nil, nil); err != nil {
+		t.Fatalf("%s: info: %v\n", msg, err)
+	}
+
+	if len(mc.mm) != 2 {
+		t.Fatalf("%s: want 2 metrics notifications, got %d\n", msg, len(mc.mm))
+	}
+
+	m := mc.mm[0]
+	if m.Operation != "merge" || m.BytesRead == 0 || m.BytesWritten == 0 || m.DurWrite == 0 || m.DurTotal == 0 {
+		t.Fatalf("%s: incomplete merge metrics:\n%s\n", msg, m)
+	}
+
+	m = mc.mm[1]
+	if m.Operation != "info" || m.BytesRead == 0 || m.BytesWritten != 0 || m.ObjectsParsed == 0 {
+		t.Fatalf("%s: incomplete info metrics:\n%s\n", msg, m)
+	}
+}
+
 func TestManipulateContext(t *testing.T) {
 	msg := "TestManipulateContext"
 	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
@@ -181,6 +428,103 @@ func TestManipulateContext(t *testing.T) {
 	}
 }
 
+func TestWriteContextFileTempFile(t *testing.T) {
+	msg := "TestWriteContextFileTempFile"
+	dir := t.TempDir()
+	outFile := filepath.Join(dir, "out.pdf")
+
+	// A user file named like a fixed temp file survives.
+	userFile := outFile + ".tmp"
+	if err := ioutil.WriteFile(userFile, []byte("user data"), 0644); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(filepath.Join(inDir, "Acroforms2.pdf"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	bb, err := ioutil.ReadFile(userFile)
+	if err != nil || string(bb) != "user data" {
+		t.Fatalf("%s: %s clobbered: %v\n", msg, userFile, err)
+	}
+
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(fis) != 2 {
+		t.Fatalf("%s: want out.pdf and out.pdf.tmp, got %d files\n", msg, len(fis))
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+func TestReadWriteInMemory(t *testing.T) {
+	msg := "TestReadWriteInMemory"
+	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
+
+	// Put inFile into a zip archive held in memory.
+	bb, err := ioutil.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	w, err := zw.Create("in.pdf")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if _, err := w.Write(bb); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Read the PDF Context from the zip entry without touching the file system.
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rc, err := zr.File[0].Open()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer rc.Close()
+	if bb, err = ioutil.ReadAll(rc); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContext(bytes.NewReader(bb), pdfcpu.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("%s: ReadContext: %v\n", msg, err)
+	}
+	if err := api.ValidateContext(ctx); err != nil {
+		t.Fatalf("%s: ValidateContext: %v\n", msg, err)
+	}
+
+	// Write the PDF Context to memory and read it back in.
+	out := &bytes.Buffer{}
+	if err := api.WriteContext(ctx, out); err != nil {
+		t.Fatalf("%s: WriteContext: %v\n", msg, err)
+	}
+	ctx1, err := api.ReadContext(bytes.NewReader(out.Bytes()), pdfcpu.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("%s: ReadContext: %v\n", msg, err)
+	}
+	if err := api.ValidateContext(ctx1); err != nil {
+		t.Fatalf("%s: ValidateContext: %v\n", msg, err)
+	}
+	if ctx1.PageCount != ctx.PageCount {
+		t.Fatalf("%s: want %d pages, got %d\n", msg, ctx.PageCount, ctx1.PageCount)
+	}
+}
+
 func TestInfo(t *testing.T) {
 	msg := "TestInfo"
 	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
@@ -189,3 +533,99 @@ func TestInfo(t *testing.T) {
 		t.Fatalf("%s: %v\n", msg, err)
 	}
 }
+
+func TestWriteContextMulti(t *testing.T) {
+	msg := "TestWriteContextMulti"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "multi.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	f, err := os.Create(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+
+	// Write to a file, a hash and a buffer in one pass.
+	h := sha256.New()
+	var buf bytes.Buffer
+	if err := api.WriteContextMulti(ctx, f, h, &buf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if ctx.Write.FileSize != int64(buf.Len()) {
+		t.Fatalf("%s: want file size %d, got %d\n", msg, buf.Len(), ctx.Write.FileSize)
+	}
+
+	if sum := sha256.Sum256(buf.Bytes()); !bytes.Equal(sum[:], h.Sum(nil)) {
+		t.Fatalf("%s: hash mismatch\n", msg)
+	}
+
+	bb, err := ioutil.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.Equal(bb, buf.Bytes()) {
+		t.Fatalf("%s: file content differs\n", msg)
+	}
+}
+
+func TestWriteObjectStreams(t *testing.T) {
+	msg := "TestWriteObjectStreams"
+	inFile := filepath.Join(inDir, "TheGoProgrammingLanguageCh1.pdf")
+
+	write := func(conf *pdfcpu.Configuration) (*pdfcpu.Context, int) {
+		t.Helper()
+		ctx, err := api.ReadContextFile(inFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		ctx.Configuration = conf
+		var buf bytes.Buffer
+		if err := api.WriteContext(ctx, &buf); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		ctx, err = api.ReadContext(bytes.NewReader(buf.Bytes()), pdfcpu.NewDefaultConfiguration())
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if err := api.ValidateContext(ctx); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return ctx, buf.Len()
+	}
+
+	// The outline is compressed into an object stream by default.
+	ctx, size := write(pdfcpu.NewDefaultConfiguration())
+	if !ctx.Read.UsingXRefStreams {
+		t.Fatalf("%s: want xref stream\n", msg)
+	}
+	ir := ctx.RootDict.IndirectRefEntry("Outlines")
+	if ir == nil {
+		t.Fatalf("%s: missing outlines\n", msg)
+	}
+	if e, ok := ctx.FindTableEntryForIndRef(ir); !ok || e.ObjectStream == nil {
+		t.Fatalf("%s: outlines not compressed\n", msg)
+	}
+
+	// Fall back to a classic xref table.
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.WriteXRefStream = false
+	ctx, size1 := write(conf)
+	if ctx.Read.UsingXRefStreams {
+		t.Fatalf("%s: want xref table\n", msg)
+	}
+	for objNr, e := range ctx.Table {
+		if e.ObjectStream != nil {
+			t.Fatalf("%s: obj#%d compressed\n", msg, objNr)
+		}
+	}
+
+	if size >= size1 {
+		t.Fatalf("%s: want compressed output smaller than %d bytes, got %d\n", msg, size1, size)
+	}
+}
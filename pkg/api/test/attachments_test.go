@@ -17,7 +17,10 @@ limitations under the License.
 package test
 
 import (
+	"bytes"
+	"io"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -91,6 +94,58 @@ func TestAttachments(t *testing.T) {
 		t.Fatalf("%s extract one attachment: %v\n", msg, err)
 	}
 
+	// Extract 1 attachment into a buffer.
+	f, err := os.Open(fileName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+	var buf bytes.Buffer
+	if err := api.ExtractAttachment(f, &buf, "test.wav", nil); err != nil {
+		t.Fatalf("%s extract attachment into buffer: %v\n", msg, err)
+	}
+	bb, err := ioutil.ReadFile(filepath.Join(outDir, "test.wav"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.Equal(buf.Bytes(), bb) {
+		t.Fatalf("%s: extracted attachment corrupted\n", msg)
+	}
+	if err := api.ExtractAttachment(f, &buf, "missing.txt", nil); err == nil {
+		t.Fatalf("%s extract missing attachment: missing error\n", msg)
+	}
+
+	// Extract all attachments into an encrypted zip archive.
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	var zipBuf bytes.Buffer
+	if err := api.ExtractAttachmentsZip(f, &zipBuf, nil, "secret", nil); err != nil {
+		t.Fatalf("%s extract attachments into zip: %v\n", msg, err)
+	}
+	zr := readZip(t, zipBuf.Bytes())
+	if len(zr.File) != 4 {
+		t.Fatalf("%s: want 4 zip entries, got %d\n", msg, len(zr.File))
+	}
+	zr.RegisterDecompressor(99, aesDecompressor("secret"))
+	for _, zf := range zr.File {
+		if zf.Name != "test.wav" {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		bb1, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if !bytes.Equal(bb1, bb) {
+			t.Fatalf("%s: zipped attachment corrupted\n", msg)
+		}
+	}
+
 	// Remove 1 attachment.
 	if err := api.RemoveAttachmentsFile(fileName, "", []string{"golang.pdf"}, nil); err != nil {
 		t.Fatalf("%s remove one attachment: %v\n", msg, err)
@@ -109,6 +164,57 @@ func TestAttachments(t *testing.T) {
 	}
 }
 
+func TestAttachmentCheckSums(t *testing.T) {
+	msg := "TestAttachmentCheckSums"
+
+	if err := prepareForAttachmentTest(t); err != nil {
+		t.Fatalf("%s prepare for attachments: %v\n", msg, err)
+	}
+
+	fileName := filepath.Join(outDir, "go.pdf")
+	files := []string{filepath.Join(outDir, "golang.pdf"), filepath.Join(outDir, "test.wav")}
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.AttachmentCheckSums = true
+	if err := api.AddAttachmentsFile(fileName, "", files, false, conf); err != nil {
+		t.Fatalf("%s add attachments: %v\n", msg, err)
+	}
+
+	// Strict validation verifies size and checksum of the embedded files.
+	conf = pdfcpu.NewDefaultConfiguration()
+	conf.ValidationMode = pdfcpu.ValidationStrict
+	if err := api.ValidateFile(fileName, conf); err != nil {
+		t.Fatalf("%s: validate: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s: read context: %v\n", msg, err)
+	}
+
+	_, o, err := ctx.SearchEmbeddedFilesNameTreeNodeByContent("test.wav")
+	if err != nil || o == nil {
+		t.Fatalf("%s: find test.wav: %v\n", msg, err)
+	}
+	d, err := ctx.DereferenceDict(o)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd, _, err := ctx.DereferenceStreamDict(d.DictEntry("EF")["F"])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := ctx.CheckEmbeddedFileParams(sd); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Detect a corrupt checksum.
+	sd.DictEntry("Params").Update("CheckSum", pdfcpu.NewHexLiteral(make([]byte, 16)))
+	if err := ctx.CheckEmbeddedFileParams(sd); err == nil {
+		t.Fatalf("%s: checksum mismatch not detected\n", msg)
+	}
+}
+
 // timeEqualsTimeFromDateTime returns true if t1 equals t2
 // working on the assumption that t2 is restored from a PDF
 // date string that does not have a way to include nanoseconds.
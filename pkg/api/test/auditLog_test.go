@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/testpdf"
+)
+
+func TestAuditLog(t *testing.T) {
+	msg := "TestAuditLog"
+	inFile := filepath.Join(outDir, "audit.pdf")
+	outFile := filepath.Join(outDir, "auditOut.pdf")
+
+	if err := testpdf.File(inFile, testpdf.Page{Text: "Hello World"}); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ee, err := api.AuditLogFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ee) > 0 {
+		t.Fatalf("%s: want no audit log, got %v\n", msg, ee)
+	}
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.Cmd = pdfcpu.ROTATE
+	conf.AuditLog = true
+	conf.AuditParameters = map[string]string{"rotation": "90"}
+	if err := api.RotateFile(inFile, outFile, 90, nil, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	conf = pdfcpu.NewDefaultConfiguration()
+	conf.Cmd = pdfcpu.ADDKEYWORDS
+	conf.AuditLog = true
+	if err := api.AddKeywordsFile(outFile, "", []string{"audit"}, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ee, err = api.AuditLogFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ee) != 2 {
+		t.Fatalf("%s: want 2 audit log entries, got %v\n", msg, ee)
+	}
+	if ee[0].Operation != "rotate" || ee[0].Parameters["rotation"] != "90" || ee[1].Operation != "add keywords" {
+		t.Fatalf("%s: unexpected audit log: %v\n", msg, ee)
+	}
+	if ee[0].Version != "pdfcpu "+pdfcpu.VersionStr || ee[0].Timestamp == "" {
+		t.Fatalf("%s: incomplete audit log entry: %v\n", msg, ee[0])
+	}
+
+	// The audit log is kept in a single attachment.
+	aa, err := api.ListAttachmentsFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(aa) != 1 {
+		t.Fatalf("%s: want 1 attachment, got %v\n", msg, aa)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestContentBox(t *testing.T) {
+	msg := "TestContentBox"
+
+	// Blank pages have no content box.
+	ctx, err := api.ReadContextFile(filepath.Join(inDir, "empty.pdf"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	r, err := ctx.ContentBox(1)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if r != nil {
+		t.Fatalf("%s: got %v, want nil\n", msg, r)
+	}
+
+	// Draw a 2 points wide border 100 points away from the edges of a 400x600 page.
+	inFile := filepath.Join(inDir, "empty.pdf")
+	outFile := filepath.Join(outDir, "contentBox.pdf")
+	dec, err := api.Decoration("border:2, margins:100", pdfcpu.POINTS)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.DecorateFile(inFile, outFile, nil, dec, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if r, err = ctx.ContentBox(1); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	want := pdfcpu.Rect(100, 100, 300, 500)
+	if r == nil ||
+		math.Abs(r.LL.X-want.LL.X) > .01 || math.Abs(r.LL.Y-want.LL.Y) > .01 ||
+		math.Abs(r.UR.X-want.UR.X) > .01 || math.Abs(r.UR.Y-want.UR.Y) > .01 {
+		t.Fatalf("%s: got %v, want %v\n", msg, r, want)
+	}
+}
+
+func TestAutoCrop(t *testing.T) {
+	msg := "TestAutoCrop"
+
+	// Start out with a 200x400 points content box.
+	inFile := filepath.Join(outDir, "autoCropIn.pdf")
+	dec, err := api.Decoration("border:2, margins:100", pdfcpu.POINTS)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.DecorateFile(filepath.Join(inDir, "empty.pdf"), inFile, nil, dec, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, tt := range []struct {
+		padding string
+		w, h    float64
+	}{
+		{"", 200, 400},
+		{"10", 220, 420},
+		{"10 20", 240, 420},
+		{"0 0 0 1000", 300, 400},
+	} {
+		outFile := filepath.Join(outDir, "autoCrop.pdf")
+
+		pad, err := api.Padding(tt.padding, pdfcpu.POINTS)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.padding, err)
+		}
+
+		if err := api.AutoCropFile(inFile, outFile, nil, pad, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.padding, err)
+		}
+
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.padding, err)
+		}
+
+		dims, err := api.PageDimsFile(outFile)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.padding, err)
+		}
+		if math.Abs(dims[0].Width-tt.w) > .01 || math.Abs(dims[0].Height-tt.h) > .01 {
+			t.Fatalf("%s %s: got %.2fx%.2f, want %.2fx%.2f\n", msg, tt.padding, dims[0].Width, dims[0].Height, tt.w, tt.h)
+		}
+	}
+
+	// Real world content.
+	inFile = filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "autoCrop.pdf")
+	if err := api.AutoCropFile(inFile, outFile, nil, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
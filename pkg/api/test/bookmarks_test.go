@@ -75,3 +75,54 @@ func TestAddBookmarkTree2Levels(t *testing.T) {
 		t.Fatalf("%s addBookmarks: %v\n", msg, err)
 	}
 }
+
+func bookmarkTitles(bms []pdfcpu.Bookmark) []string {
+	var ss []string
+	for _, bm := range bms {
+		ss = append(ss, bm.Title)
+		ss = append(ss, bookmarkTitles(bm.Children)...)
+	}
+	return ss
+}
+
+func TestBookmarksLocalized(t *testing.T) {
+	msg := "TestBookmarksLocalized"
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+	outFile := filepath.Join("..", "..", "samples", "bookmarks", "bookmarkLocalized.pdf")
+
+	bms := []pdfcpu.Bookmark{
+		{PageFrom: 1, Title: "第一章 概要",
+			Children: []pdfcpu.Bookmark{
+				{PageFrom: 2, Title: "第一節 はじめに"},
+				{PageFrom: 3, Title: "제2절 배경"},
+			}},
+		{PageFrom: 5, Title: "الفصل الأول: مقدمة",
+			Children: []pdfcpu.Bookmark{
+				{PageFrom: 6, Title: "القسم ١"},
+			}},
+		{PageFrom: 8, Title: "Grüße (Latin)"},
+	}
+
+	if err := api.AddBookmarksFile(inFile, outFile, bms, nil); err != nil {
+		t.Fatalf("%s addBookmarks: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s read: %v\n", msg, err)
+	}
+	got, err := ctx.BookmarksForOutline()
+	if err != nil {
+		t.Fatalf("%s bookmarks: %v\n", msg, err)
+	}
+
+	want, have := bookmarkTitles(bms), bookmarkTitles(got)
+	if len(want) != len(have) {
+		t.Fatalf("%s: want %v, got %v\n", msg, want, have)
+	}
+	for i := range want {
+		if want[i] != have[i] {
+			t.Errorf("%s: want %q, got %q\n", msg, want[i], have[i])
+		}
+	}
+}
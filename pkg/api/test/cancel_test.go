@@ -0,0 +1,144 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+func TestCancelRead(t *testing.T) {
+	msg := "TestCancelRead"
+	inFile := filepath.Join(inDir, "gobook.0.pdf")
+
+	f, err := os.Open(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+
+	c, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = api.ValidateWithContext(c, f, pdfcpu.NewDefaultConfiguration())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("%s: want %v, got %v\n", msg, context.Canceled, err)
+	}
+}
+
+func TestCancelWrite(t *testing.T) {
+	msg := "TestCancelWrite"
+	inFile := filepath.Join(inDir, "gobook.0.pdf")
+
+	bb, err := ioutil.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	c, cancel := context.WithCancel(context.Background())
+
+	ctx, err := api.ReadContextWithContext(c, bytes.NewReader(bb), pdfcpu.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err = api.ValidateContext(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// The Context read keeps c.
+	cancel()
+
+	if err = api.WriteContext(ctx, ioutil.Discard); !errors.Is(err, context.Canceled) {
+		t.Fatalf("%s: want %v, got %v\n", msg, context.Canceled, err)
+	}
+}
+
+func TestOptimizeDeadline(t *testing.T) {
+	msg := "TestOptimizeDeadline"
+	inFile := filepath.Join(inDir, "gobook.0.pdf")
+
+	f, err := os.Open(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+
+	c, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	err = api.OptimizeWithContext(c, f, ioutil.Discard, pdfcpu.NewDefaultConfiguration())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("%s: want %v, got %v\n", msg, context.DeadlineExceeded, err)
+	}
+}
+
+func TestConfigurationLoggers(t *testing.T) {
+	msg := "TestConfigurationLoggers"
+	inFile := filepath.Join(inDir, "gobook.0.pdf")
+
+	// Package level loggers stay in place and see nothing logged on behalf of a configuration.
+	var global int32
+	log.SetLoggers(log.FuncLoggers(func(subsystem, msg string) {
+		atomic.AddInt32(&global, 1)
+	}, "read"))
+	defer log.DisableLoggers()
+
+	var counts [2]int32
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+
+	for i := range counts {
+		loggers := log.FuncLoggers(func(i int) log.MessageFunc {
+			return func(subsystem, msg string) {
+				atomic.AddInt32(&counts[i], 1)
+			}
+		}(i), "read")
+		conf := pdfcpu.NewDefaultConfiguration()
+		conf.Loggers = &loggers
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = api.ValidateFile(inFile, conf)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range counts {
+		if errs[i] != nil {
+			t.Fatalf("%s: %v\n", msg, errs[i])
+		}
+	}
+	if counts[0] == 0 || counts[0] != counts[1] {
+		t.Fatalf("%s: want the same number of read messages for each configuration, got %v\n", msg, counts)
+	}
+	if global != 0 {
+		t.Fatalf("%s: %d read messages logged via package level logger\n", msg, global)
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func writeCanonical(t *testing.T, inFile string) []byte {
+	t.Helper()
+
+	f, err := os.Open(inFile)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	defer f.Close()
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.Canonical = true
+
+	ctx, err := api.ReadContext(f, conf)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	var buf bytes.Buffer
+	if err := api.WriteContext(ctx, &buf); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestCanonicalWrite(t *testing.T) {
+	msg := "TestCanonicalWrite"
+	inFile := filepath.Join(inDir, "gobook.0.pdf")
+
+	bb1 := writeCanonical(t, inFile)
+	bb2 := writeCanonical(t, inFile)
+
+	if bytes.Contains(bb1, []byte("/ObjStm")) || bytes.Contains(bb1, []byte("/XRef")) {
+		t.Fatalf("%s: canonical output uses object or xref streams\n", msg)
+	}
+
+	if err := api.Validate(bytes.NewReader(bb1), nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Two writes only differ in modification date and file ID.
+	lines1 := strings.Split(string(bb1), "\n")
+	lines2 := strings.Split(string(bb2), "\n")
+	if len(lines1) != len(lines2) {
+		t.Fatalf("%s: line count differs: %d != %d\n", msg, len(lines1), len(lines2))
+	}
+	for i := range lines1 {
+		if lines1[i] == lines2[i] || strings.Contains(lines1[i], "/ModDate ") || strings.Contains(lines1[i], "/ID ") {
+			continue
+		}
+		t.Fatalf("%s: line %d differs:\n%.80q\n%.80q\n", msg, i+1, lines1[i], lines2[i])
+	}
+}
+
+func TestStringForm(t *testing.T) {
+	msg := "TestStringForm"
+	inFile := filepath.Join(inDir, "test.pdf")
+
+	write := func(form int) []byte {
+		t.Helper()
+		f, err := os.Open(inFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		defer f.Close()
+		conf := pdfcpu.NewDefaultConfiguration()
+		conf.Canonical = true
+		conf.StringForm = form
+		var buf bytes.Buffer
+		if err := api.Optimize(f, &buf, conf); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		bb := buf.Bytes()
+		if err := api.Validate(bytes.NewReader(bb), nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return bb
+	}
+
+	producer := func(bb []byte) string {
+		t.Helper()
+		ctx, err := api.ReadContext(bytes.NewReader(bb), pdfcpu.NewDefaultConfiguration())
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		d, err := ctx.DereferenceDict(*ctx.Info)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		s, err := d.StringEntryBytes("Producer")
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return string(s)
+	}
+
+	bb := write(pdfcpu.StringFormHex)
+	if !bytes.Contains(bb, []byte("/Producer <")) || !bytes.Contains(bb, []byte("/ID [<")) {
+		t.Errorf("%s: hex strings expected\n", msg)
+	}
+	want := producer(bb)
+	if !strings.HasPrefix(want, "pdfcpu") {
+		t.Errorf("%s: unexpected producer: %s\n", msg, want)
+	}
+
+	bb = write(pdfcpu.StringFormLiteral)
+	if !bytes.Contains(bb, []byte("/Producer (")) || !bytes.Contains(bb, []byte("/ID [(")) {
+		t.Errorf("%s: literal strings expected\n", msg)
+	}
+	if got := producer(bb); got != want {
+		t.Errorf("%s: producer want %s, got %s\n", msg, want, got)
+	}
+
+	// The binary file ID gets written as hex string.
+	bb = write(pdfcpu.StringFormAuto)
+	if !bytes.Contains(bb, []byte("/Producer (")) || !bytes.Contains(bb, []byte("/ID [<")) {
+		t.Errorf("%s: literal producer and hex file ID expected\n", msg)
+	}
+	if got := producer(bb); got != want {
+		t.Errorf("%s: producer want %s, got %s\n", msg, want, got)
+	}
+}
+
+func TestFloatPrecision(t *testing.T) {
+	msg := "TestFloatPrecision"
+	inFile := filepath.Join(inDir, "test.pdf")
+
+	write := func(canonical bool, precision int) []byte {
+		t.Helper()
+		f, err := os.Open(inFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		defer f.Close()
+		conf := pdfcpu.NewDefaultConfiguration()
+		conf.Canonical = canonical
+		conf.FloatPrecision = precision
+		conf.TrimFloatZeros = true
+		ctx, err := api.ReadContext(f, conf)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		d, _, _, err := ctx.PageDict(1, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		d.Update("MediaBox", pdfcpu.Array{pdfcpu.Integer(0), pdfcpu.Float(0.5), pdfcpu.Float(595.2755905511812), pdfcpu.Float(841.8897637795276)})
+		var buf bytes.Buffer
+		if err := api.WriteContext(ctx, &buf); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return buf.Bytes()
+	}
+
+	mediaBox := func(bb []byte) pdfcpu.Array {
+		t.Helper()
+		ctx, err := api.ReadContext(bytes.NewReader(bb), pdfcpu.NewDefaultConfiguration())
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		d, _, _, err := ctx.PageDict(1, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return d.ArrayEntry("MediaBox")
+	}
+
+	// By default numbers read back unchanged.
+	bb := write(false, 0)
+	if got := mediaBox(bb).PDFString(); got != "[0 0.5 595.2755905511812 841.8897637795276]" {
+		t.Errorf("%s: got %s\n", msg, got)
+	}
+
+	// Canonical writing normalizes to 12 decimal places.
+	bb = write(true, 0)
+	if got := mediaBox(bb).PDFString(); got != "[0 0.5 595.275590551181 841.889763779528]" {
+		t.Errorf("%s: canonical: got %s\n", msg, got)
+	}
+
+	// Object streams and the classic layout both honour the precision.
+	for _, canonical := range []bool{false, true} {
+		bb := write(canonical, 2)
+		if got := mediaBox(bb).PDFString(); got != "[0 0.5 595.28 841.89]" {
+			t.Errorf("%s: canonical=%t: got %s\n", msg, canonical, got)
+		}
+		if canonical && !bytes.Contains(bb, []byte("595.28 ")) {
+			t.Errorf("%s: rounded MediaBox expected\n", msg)
+		}
+	}
+}
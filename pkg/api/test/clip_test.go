@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestClip(t *testing.T) {
+	msg := "TestClip"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+
+	dims, err := api.PageDimsFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, tt := range []struct {
+		s             string
+		selectedPages []string
+		w, h          float64
+	}{
+		{"[0 0 200 100]", nil, 200, 100},
+		{"pos:tl, dim:50% 50%", []string{"1"}, dims[0].Width / 2, dims[0].Height / 2},
+	} {
+		outFile := filepath.Join(outDir, "clip.pdf")
+
+		box, err := api.Box(tt.s, pdfcpu.POINTS)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		if err := api.ClipFile(inFile, outFile, tt.selectedPages, box, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.s, err)
+		}
+
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.s, err)
+		}
+
+		dd, err := api.PageDimsFile(outFile)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.s, err)
+		}
+
+		want := len(dims)
+		if tt.selectedPages != nil {
+			want = len(tt.selectedPages)
+		}
+		if len(dd) != want {
+			t.Fatalf("%s %s: got %d pages, want %d\n", msg, tt.s, len(dd), want)
+		}
+
+		for _, d := range dd {
+			if math.Abs(d.Width-tt.w) > .01 || math.Abs(d.Height-tt.h) > .01 {
+				t.Fatalf("%s %s: got %.2fx%.2f, want %.2fx%.2f\n", msg, tt.s, d.Width, d.Height, tt.w, tt.h)
+			}
+		}
+	}
+
+	// Regions exceeding the page are rejected.
+	box, err := api.Box("[0 0 10000 100]", pdfcpu.POINTS)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ClipFile(inFile, filepath.Join(outDir, "clip.pdf"), nil, box, nil); err == nil {
+		t.Fatalf("%s: expected error for invalid region\n", msg)
+	}
+}
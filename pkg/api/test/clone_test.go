@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestCloneBranches(t *testing.T) {
+	msg := "TestCloneBranches"
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateContext(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Branch 1 gets bookmarks, branch 2 gets a new title.
+	ctx1, ctx2 := ctx.Clone(), ctx.Clone()
+
+	if err := ctx1.AddBookmarks([]pdfcpu.Bookmark{{PageFrom: 2, Title: "Branch 1"}}); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := ctx2.SetDocumentMetadata(pdfcpu.DocumentMetadata{Title: "Branch 2"}); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	var out [3]bytes.Buffer
+	for i, c := range []*pdfcpu.Context{ctx1, ctx2, ctx} {
+		if err := api.WriteContext(c, &out[i]); err != nil {
+			t.Fatalf("%s: write %d: %v\n", msg, i, err)
+		}
+	}
+
+	for i, want := range []struct {
+		bookmarks bool
+		title     string
+	}{
+		{true, ctx.Title},
+		{false, "Branch 2"},
+		{false, ctx.Title},
+	} {
+		c, err := api.ReadContext(bytes.NewReader(out[i].Bytes()), pdfcpu.NewDefaultConfiguration())
+		if err != nil {
+			t.Fatalf("%s: read %d: %v\n", msg, i, err)
+		}
+		if err := api.ValidateContext(c); err != nil {
+			t.Fatalf("%s: validate %d: %v\n", msg, i, err)
+		}
+		root, err := c.Catalog()
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if _, found := root.Find("Outlines"); found != want.bookmarks {
+			t.Errorf("%s: branch %d: want outline: %t\n", msg, i, want.bookmarks)
+		}
+		md, err := c.DocumentMetadata()
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if md.Title != want.title {
+			t.Errorf("%s: branch %d: want title %q, got %q\n", msg, i, want.title, md.Title)
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 )
 
 func TestCollect(t *testing.T) {
@@ -69,3 +70,71 @@ func TestCollectLowLevel(t *testing.T) {
 		t.Fatalf("%s write: %v\n", msg, err)
 	}
 }
+
+func TestCollectRemapsPageRefs(t *testing.T) {
+	msg := "TestCollectRemapsPageRefs"
+
+	for _, tt := range []struct {
+		fileName string
+		pages    []string
+	}{
+		{"BuildingWebappsWithGo.pdf", []string{"1-5", "3"}},
+		{"TheGoProgrammingLanguageCh1.pdf", []string{"2", "1"}},
+		{"CenterOfWhy.pdf", []string{"even"}},
+	} {
+		inFile := filepath.Join(inDir, tt.fileName)
+		outFile := filepath.Join(outDir, "remapped.pdf")
+
+		if err := api.CollectFile(inFile, outFile, tt.pages, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.fileName, err)
+		}
+
+		ctx, err := api.ReadContextFile(outFile)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.fileName, err)
+		}
+
+		pageObjNrs := map[int]bool{}
+		for i := 1; i <= ctx.PageCount; i++ {
+			_, ir, _, err := ctx.PageDict(i, false)
+			if err != nil {
+				t.Fatalf("%s %s: %v\n", msg, tt.fileName, err)
+			}
+			pageObjNrs[ir.ObjectNumber.Value()] = true
+		}
+
+		for i := 1; i <= ctx.PageCount; i++ {
+			d, ir, _, _ := ctx.PageDict(i, false)
+
+			if _, found := d.Find("StructParents"); found {
+				t.Fatalf("%s %s: page %d: unexpected StructParents\n", msg, tt.fileName, i)
+			}
+
+			annots, err := ctx.DereferenceArray(d["Annots"])
+			if err != nil {
+				t.Fatalf("%s %s: %v\n", msg, tt.fileName, err)
+			}
+
+			for _, o := range annots {
+				annot, err := ctx.DereferenceDict(o)
+				if err != nil {
+					t.Fatalf("%s %s: %v\n", msg, tt.fileName, err)
+				}
+
+				if p := annot.IndirectRefEntry("P"); p != nil && *p != *ir {
+					t.Fatalf("%s %s: page %d: annotation /P refers to obj#%d\n", msg, tt.fileName, i, p.ObjectNumber)
+				}
+
+				dest, err := ctx.Dereference(annot["Dest"])
+				if err != nil {
+					t.Fatalf("%s %s: %v\n", msg, tt.fileName, err)
+				}
+				if a, ok := dest.(pdfcpu.Array); ok && len(a) > 0 {
+					if p, ok := a[0].(pdfcpu.IndirectRef); ok && !pageObjNrs[p.ObjectNumber.Value()] {
+						t.Fatalf("%s %s: page %d: destination refers to obj#%d\n", msg, tt.fileName, i, p.ObjectNumber)
+					}
+				}
+			}
+		}
+	}
+}
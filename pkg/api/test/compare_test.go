@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestEqualDocuments(t *testing.T) {
+	msg := "TestEqualDocuments"
+
+	for _, fileName := range []string{"Acroforms2.pdf", "go.pdf", "TheGoProgrammingLanguageCh1.pdf", "testImage.pdf"} {
+		inFile := filepath.Join(inDir, fileName)
+		outFile := filepath.Join(outDir, "equal_"+fileName)
+
+		// Optimizing must not change content.
+		if err := api.OptimizeFile(inFile, outFile, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fileName, err)
+		}
+		ok, err := api.EqualDocumentsFile(inFile, outFile, nil)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fileName, err)
+		}
+		if !ok {
+			t.Fatalf("%s %s: optimized file differs\n", msg, fileName)
+		}
+
+		// Rotating does.
+		if err := api.RotateFile(outFile, "", 90, nil, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fileName, err)
+		}
+		ok, err = api.EqualDocumentsFile(inFile, outFile, nil)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fileName, err)
+		}
+		if ok {
+			t.Fatalf("%s %s: rotated file equals original\n", msg, fileName)
+		}
+	}
+}
+
+func TestContentHashes(t *testing.T) {
+	msg := "TestContentHashes"
+
+	for _, fileName := range []string{"Acroforms2.pdf", "go.pdf", "TheGoProgrammingLanguageCh1.pdf", "testImage.pdf"} {
+		inFile := filepath.Join(inDir, fileName)
+		outFile := filepath.Join(outDir, "hash_"+fileName)
+
+		want, err := api.ContentHashesFile(inFile, nil)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fileName, err)
+		}
+
+		// Rewriting without object streams changes the file layout but not the content.
+		conf := pdfcpu.NewDefaultConfiguration()
+		conf.WriteObjectStream = false
+		conf.WriteXRefStream = false
+		if err := api.OptimizeFile(inFile, outFile, conf); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fileName, err)
+		}
+		got, err := api.ContentHashesFile(outFile, nil)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fileName, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s %s: rewritten file hashes differ\n", msg, fileName)
+		}
+
+		// Rotating the first page changes its hash and the document hash only.
+		if err := api.RotateFile(outFile, "", 90, []string{"1"}, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fileName, err)
+		}
+		got, err = api.ContentHashesFile(outFile, nil)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fileName, err)
+		}
+		if got.Document == want.Document || got.Pages[0] == want.Pages[0] || !reflect.DeepEqual(got.Pages[1:], want.Pages[1:]) {
+			t.Fatalf("%s %s: unexpected hashes after rotating page 1\n", msg, fileName)
+		}
+	}
+}
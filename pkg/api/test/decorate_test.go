@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestDecorate(t *testing.T) {
+	msg := "TestDecorate"
+
+	for _, tt := range []struct {
+		inFile        string
+		selectedPages []string
+		desc          string
+		u             pdfcpu.DisplayUnit
+	}{
+		{"Acroforms2.pdf", nil, "bgcolor:#FFFFE0", pdfcpu.POINTS},
+		{"Acroforms2.pdf", nil, "bg:1 1 1, gradient:.8 .8 1, axis:h", pdfcpu.POINTS},
+		{"5116.DCT_Filter.pdf", []string{"1-2"}, "border:1 #0000FF, radius:5, margins:10", pdfcpu.MILLIMETRES},
+		{"test.pdf", nil, "bgcolor:#E0E0E0, gr:#FFFFFF, border:3, margins:20 10", pdfcpu.POINTS},
+	} {
+		inFile := filepath.Join(inDir, tt.inFile)
+		outFile := filepath.Join(outDir, "decorate.pdf")
+
+		dec, err := api.Decoration(tt.desc, tt.u)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.desc, err)
+		}
+
+		if err := api.DecorateFile(inFile, outFile, tt.selectedPages, dec, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.desc, err)
+		}
+
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.desc, err)
+		}
+	}
+
+	// Either background or border are required.
+	if _, err := api.Decoration("radius:5", pdfcpu.POINTS); err == nil {
+		t.Fatalf("%s: expected error for missing background and border\n", msg)
+	}
+
+	// Border and margins exceeding the page are rejected.
+	dec, err := api.Decoration("border:1, margins:1000", pdfcpu.POINTS)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	inFile := filepath.Join(inDir, "test.pdf")
+	if err := api.DecorateFile(inFile, filepath.Join(outDir, "decorate.pdf"), nil, dec, nil); err == nil {
+		t.Fatalf("%s: expected error for invalid margins\n", msg)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/testpdf"
+)
+
+const duplicatesText = `Lorem ipsum dolor sit amet, consectetur adipiscing elit,
+sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.
+Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris
+nisi ut aliquip ex ea commodo consequat. Duis aute irure dolor in
+reprehenderit in voluptate velit esse cillum dolore eu fugiat nulla`
+
+func TestDuplicatePages(t *testing.T) {
+	msg := "TestDuplicatePages"
+
+	red := pdfcpu.SimpleColor{R: 1}
+	scan := testpdf.Image{Width: 16, Height: 16, Color: red, Rect: pdfcpu.Rect(0, 0, 595, 842)}
+
+	rs1, err := testpdf.Reader(
+		testpdf.Page{Text: duplicatesText},
+		testpdf.Page{Text: "Something completely different."},
+		testpdf.Page{},
+		testpdf.Page{Images: []testpdf.Image{scan}},
+	)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	rs2, err := testpdf.Reader(
+		// Same text ignoring case, punctuation and line breaks.
+		testpdf.Page{Text: "LOREM IPSUM dolor sit amet\n" + duplicatesText[28:]},
+		// One word changed.
+		testpdf.Page{Text: duplicatesText[:len(duplicatesText)-5] + "pariatur"},
+		testpdf.Page{},
+		testpdf.Page{Images: []testpdf.Image{scan}},
+	)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	dups, err := api.DuplicatePages([]io.ReadSeeker{rs1, rs2}, []string{"a.pdf", "b.pdf"}, pdfcpu.DefaultDuplicateThreshold, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	want := []struct {
+		page1, page2  pdfcpu.PageRef
+		exact, raster bool
+	}{
+		{pdfcpu.PageRef{File: "a.pdf", Page: 1}, pdfcpu.PageRef{File: "b.pdf", Page: 1}, true, false},
+		{pdfcpu.PageRef{File: "a.pdf", Page: 1}, pdfcpu.PageRef{File: "b.pdf", Page: 2}, false, false},
+		{pdfcpu.PageRef{File: "a.pdf", Page: 4}, pdfcpu.PageRef{File: "b.pdf", Page: 4}, true, true},
+	}
+
+	if len(dups) != len(want) {
+		t.Fatalf("%s: want %d duplicates, got %v\n", msg, len(want), dups)
+	}
+
+	for i, w := range want {
+		d := dups[i]
+		if d.Page1 != w.page1 || d.Page2 != w.page2 || d.Raster != w.raster {
+			t.Errorf("%s: want %s duplicating %s, got %s\n", msg, w.page2, w.page1, d)
+		}
+		if exact := d.Similarity == 1; exact != w.exact || d.Similarity < pdfcpu.DefaultDuplicateThreshold {
+			t.Errorf("%s: %s: unexpected similarity %.2f\n", msg, d.Page2, d.Similarity)
+		}
+	}
+}
+
+func TestDuplicatePagesFile(t *testing.T) {
+	msg := "TestDuplicatePagesFile"
+
+	inFile := filepath.Join(outDir, "duplicates.pdf")
+	if err := testpdf.File(inFile, testpdf.Page{Text: "Hello World"}, testpdf.Page{Text: "Goodbye"}, testpdf.Page{Text: "hello, world!"}); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	dups, err := api.DuplicatePagesFile([]string{inFile}, 1, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(dups) != 1 || dups[0].Page1.Page != 1 || dups[0].Page2.Page != 3 || dups[0].Page2.File != "duplicates.pdf" {
+		t.Fatalf("%s: want page 3 duplicating page 1, got %v\n", msg, dups)
+	}
+
+	if _, err := api.DuplicatePagesFile([]string{inFile}, 1.5, nil); err == nil {
+		t.Fatalf("%s: want error for invalid threshold\n", msg)
+	}
+}
@@ -17,12 +17,21 @@ limitations under the License.
 package test
 
 import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	mrand "math/rand"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
 	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/testpdf"
+	"github.com/pkg/errors"
 )
 
 func confForAlgorithm(aes bool, keyLength int, upw, opw string) *pdf.Configuration {
@@ -185,3 +194,549 @@ func TestEncryption(t *testing.T) {
 		testEncryption(t, fileName, "aes", 256)
 	}
 }
+
+func TestEncryptionInfo(t *testing.T) {
+	msg := "TestEncryptionInfo"
+	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	ei, err := api.EncryptionInfoFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ei.Encrypted {
+		t.Fatalf("%s: %s is not encrypted\n", msg, inFile)
+	}
+
+	for _, tt := range []struct {
+		aes       bool
+		keyLength int
+		v         int
+		algorithm string
+	}{
+		{false, 40, 1, "RC4 40 bit"},
+		{false, 128, 4, "RC4 128 bit"},
+		{true, 128, 4, "AES 128 bit"},
+		{true, 256, 5, "AES 256 bit"},
+	} {
+		conf := confForAlgorithm(tt.aes, tt.keyLength, "upw", "opw")
+		if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+			t.Fatalf("%s: encrypt %s: %v\n", msg, outFile, err)
+		}
+
+		conf = confForAlgorithm(tt.aes, tt.keyLength, "upw", "opw")
+		ei, err := api.EncryptionInfoFile(outFile, conf)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if !ei.Encrypted || ei.Filter != "Standard" || ei.V != tt.v || ei.KeyLength != tt.keyLength || ei.Algorithm != tt.algorithm {
+			t.Fatalf("%s: unexpected encryption info: %+v\n", msg, ei)
+		}
+		if ei.Permissions == nil || ei.Permissions.Bits != 0 || ei.Permissions.Print {
+			t.Fatalf("%s: expected permissions none: %+v\n", msg, ei.Permissions)
+		}
+	}
+}
+
+func TestEncryptSelectedPermissions(t *testing.T) {
+	msg := "TestEncryptSelectedPermissions"
+	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	perms, err := pdf.ParsePermissions("print, copy")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, keyLength := range []int{128, 256} {
+		conf := confForAlgorithm(true, keyLength, "upw", "opw")
+		conf.Permissions = perms
+		if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+			t.Fatalf("%s: encrypt %s: %v\n", msg, outFile, err)
+		}
+
+		conf = confForAlgorithm(true, keyLength, "upw", "opw")
+		ei, err := api.EncryptionInfoFile(outFile, conf)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		p := ei.Permissions
+		if p == nil || !p.Print || !p.PrintHighQuality || !p.Extract || !p.ExtractAccessibility ||
+			p.Modify || p.Annotate || p.FillForms || p.Assemble {
+			t.Fatalf("%s: expected print and copy permissions: %+v\n", msg, p)
+		}
+	}
+}
+
+func TestEmptyUserPassword(t *testing.T) {
+	msg := "TestEmptyUserPassword"
+	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	// Encrypt using the owner password only.
+	conf := confForAlgorithm(false, 40, "", "opw")
+	if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s: encrypt %s: %v\n", msg, outFile, err)
+	}
+
+	// A wrong user password falls back to the empty user password.
+	conf = confForAlgorithm(false, 40, "upwWrong", "")
+	ei, err := api.EncryptionInfoFile(outFile, conf)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !ei.EmptyUserPW {
+		t.Fatalf("%s: expected empty user password\n", msg)
+	}
+
+	ss, err := api.InfoFile(outFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !strings.Contains(strings.Join(ss, "\n"), "Open protected: No (empty user password)") {
+		t.Fatalf("%s: info misses empty user password\n", msg)
+	}
+
+	ww, err := api.WarningsFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ww) == 0 || !strings.HasPrefix(ww[0], "weak encryption: RC4 40 bit") {
+		t.Fatalf("%s: missing weak encryption warning: %v\n", msg, ww)
+	}
+
+	// The fallback gets reported.
+	ww, err = api.WarningsFile(outFile, confForAlgorithm(false, 40, "upwWrong", ""))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !strings.Contains(strings.Join(ww, "\n"), "incorrect user password") {
+		t.Fatalf("%s: missing incorrect user password warning: %v\n", msg, ww)
+	}
+
+	// Encrypt using both passwords.
+	conf = confForAlgorithm(true, 256, "upw", "opw")
+	if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s: encrypt %s: %v\n", msg, outFile, err)
+	}
+
+	conf = confForAlgorithm(true, 256, "upwWrong", "")
+	if _, err := api.EncryptionInfoFile(outFile, conf); err == nil {
+		t.Fatalf("%s: expected error for wrong user password\n", msg)
+	}
+
+	conf = confForAlgorithm(true, 256, "upw", "")
+	if ei, err = api.EncryptionInfoFile(outFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ei.EmptyUserPW {
+		t.Fatalf("%s: unexpected empty user password\n", msg)
+	}
+}
+
+func TestUnicodePasswordAES256(t *testing.T) {
+	msg := "TestUnicodePasswordAES256"
+	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	// Passwords using a precomposed character and a no-break space.
+	upw, opw := "grüße", "你好\u00A0opw"
+
+	conf := confForAlgorithm(true, 256, upw, opw)
+	if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s: encrypt %s: %v\n", msg, outFile, err)
+	}
+
+	ei, err := api.EncryptionInfoFile(outFile, confForAlgorithm(true, 256, upw, ""))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ei.V != 5 || ei.R != 6 {
+		t.Fatalf("%s: got V=%d R=%d, want V=5 R=6\n", msg, ei.V, ei.R)
+	}
+
+	// SASLprep normalizes decomposed input and maps non-ASCII spaces.
+	for _, pw := range []struct{ upw, opw string }{
+		{"gru\u0308ße", ""},
+		{"", "你好 opw"},
+	} {
+		conf = confForAlgorithm(true, 256, pw.upw, pw.opw)
+		if err := api.ValidateFile(outFile, conf); err != nil {
+			t.Fatalf("%s: validate upw=%q opw=%q: %v\n", msg, pw.upw, pw.opw, err)
+		}
+	}
+
+	conf = confForAlgorithm(true, 256, "grusse", "")
+	if err := api.ValidateFile(outFile, conf); err == nil {
+		t.Fatalf("%s: expected error for wrong user password\n", msg)
+	}
+
+	// Revision 6 is declared via Adobe extension level 8 to PDF 1.7.
+	conf = confForAlgorithm(true, 256, upw, "")
+	f, err := os.Open(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+	ctx, err := api.ReadContext(f, conf)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ext, err := ctx.DereferenceDict(ctx.RootDict["Extensions"])
+	if err != nil || ext == nil {
+		t.Fatalf("%s: missing extensions dict: %v\n", msg, err)
+	}
+	adbe, err := ctx.DereferenceDict(ext["ADBE"])
+	if err != nil || adbe == nil {
+		t.Fatalf("%s: missing ADBE extension: %v\n", msg, err)
+	}
+	if l := adbe.IntEntry("ExtensionLevel"); l == nil || *l < 8 {
+		t.Fatalf("%s: want ADBE extension level 8, got %v\n", msg, l)
+	}
+
+	conf = confForAlgorithm(true, 256, "", opw)
+	if err := api.DecryptFile(outFile, "", conf); err != nil {
+		t.Fatalf("%s: decrypt %s: %v\n", msg, outFile, err)
+	}
+}
+
+func TestUnicodePasswordAES256Fixture(t *testing.T) {
+	msg := "TestUnicodePasswordAES256Fixture"
+
+	// Encrypted independently of pdfcpu using V5 R6 with
+	// user password "Ju\u0308rgen" and owner password "\uFB01le\u20AC".
+	inFile := filepath.Join(inDir, "encrypted", "aes256R6Unicode.pdf")
+	outFile := filepath.Join(outDir, "aes256R6UnicodeDecrypted.pdf")
+
+	for _, pw := range []struct{ upw, opw string }{
+		{"Ju\u0308rgen", ""},
+		{"Jürgen", ""},
+		{"", "\uFB01le\u20AC"},
+		{"", "file\u20AC"},
+	} {
+		conf := confForAlgorithm(true, 256, pw.upw, pw.opw)
+
+		ei, err := api.EncryptionInfoFile(inFile, conf)
+		if err != nil {
+			t.Fatalf("%s: upw=%q opw=%q: %v\n", msg, pw.upw, pw.opw, err)
+		}
+		if ei.V != 5 || ei.R != 6 {
+			t.Fatalf("%s: got V=%d R=%d, want V=5 R=6\n", msg, ei.V, ei.R)
+		}
+
+		if err := api.DecryptFile(inFile, outFile, conf); err != nil {
+			t.Fatalf("%s: decrypt upw=%q opw=%q: %v\n", msg, pw.upw, pw.opw, err)
+		}
+
+		ctx, err := api.ReadContextFile(outFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if ctx.Title != "R6 Unicode passwords" {
+			t.Fatalf("%s: got title %q\n", msg, ctx.Title)
+		}
+		d, _, _, err := ctx.PageDict(1, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		bb, err := ctx.PageContent(d)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if !bytes.Contains(bb, []byte("(Hello R6) Tj")) {
+			t.Fatalf("%s: got content %q\n", msg, bb)
+		}
+	}
+
+	for _, pw := range []string{"Jurgen", "file"} {
+		conf := confForAlgorithm(true, 256, pw, "")
+		if err := api.DecryptFile(inFile, outFile, conf); err == nil {
+			t.Fatalf("%s: expected error for wrong password %q\n", msg, pw)
+		}
+	}
+}
+
+// writePDFWithIdentityCryptFilter writes a PDF to inFile whose metadata stream uses the "Identity" crypt filter
+// and returns the encoded stream data.
+func writePDFWithIdentityCryptFilter(t *testing.T, inFile string, content []byte) []byte {
+	t.Helper()
+
+	f, err := filter.NewFilter(filter.Flate, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := f.Encode(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, err := testpdf.Context(testpdf.Page{Text: "Hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := int64(len(raw))
+	sd := pdf.StreamDict{Dict: pdf.NewDict(), Raw: raw, StreamLength: &l}
+	sd.InsertName("Type", "Metadata")
+	sd.InsertName("Subtype", "XML")
+	sd.InsertInt("Length", len(raw))
+	sd.Insert("Filter", pdf.NewNameArray("Crypt", filter.Flate))
+	sd.FilterPipeline = []pdf.PDFFilter{{Name: "Crypt"}, {Name: filter.Flate}}
+
+	ir, err := ctx.IndRefForNewObject(sd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootDict.Insert("Metadata", *ir)
+
+	if err := api.WriteContextFile(ctx, inFile); err != nil {
+		t.Fatal(err)
+	}
+
+	return raw
+}
+
+func TestIdentityCryptFilter(t *testing.T) {
+	msg := "TestIdentityCryptFilter"
+	content := []byte("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\"></x:xmpmeta>")
+	inFile := filepath.Join(outDir, "identityCryptFilter.pdf")
+	outFile := filepath.Join(outDir, "identityCryptFilterEnc.pdf")
+
+	raw := writePDFWithIdentityCryptFilter(t, inFile, content)
+
+	for _, keyLength := range []int{128, 256} {
+		conf := confForAlgorithm(true, keyLength, "upw", "opw")
+		if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+			t.Fatalf("%s: encrypt %s: %v\n", msg, outFile, err)
+		}
+
+		// Streams using the "Identity" crypt filter are written as is.
+		bb, err := ioutil.ReadFile(outFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if !bytes.Contains(bb, raw) {
+			t.Fatalf("%s: AES-%d: stream using identity crypt filter got encrypted\n", msg, keyLength)
+		}
+
+		// and must not get decrypted.
+		conf = confForAlgorithm(true, keyLength, "upw", "")
+		ctx, err := api.ReadContext(bytes.NewReader(bb), conf)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		sd := metadataStream(t, ctx)
+		if err := sd.Decode(); err != nil {
+			t.Fatalf("%s: AES-%d: %v\n", msg, keyLength, err)
+		}
+		if !bytes.Equal(sd.Content, content) {
+			t.Fatalf("%s: AES-%d: got %q, want %q\n", msg, keyLength, sd.Content, content)
+		}
+	}
+}
+
+func TestReadEncrypted(t *testing.T) {
+	msg := "TestReadEncrypted"
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+	outFile := filepath.Join(outDir, "CenterOfWhyEncrypted.pdf")
+
+	for _, tt := range []struct {
+		aes       bool
+		keyLength int
+	}{
+		{false, 40},
+		{false, 128},
+		{true, 128},
+		{true, 256},
+	} {
+		conf := confForAlgorithm(tt.aes, tt.keyLength, "upw", "opw")
+		if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+			t.Fatalf("%s: encrypt %s: %v\n", msg, outFile, err)
+		}
+
+		// Strings and streams get decrypted transparently while reading.
+		bb, err := ioutil.ReadFile(outFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		conf = confForAlgorithm(tt.aes, tt.keyLength, "", "opw")
+		ctx, err := api.ReadContext(bytes.NewReader(bb), conf)
+		if err != nil {
+			t.Fatalf("%s: read %s: %v\n", msg, outFile, err)
+		}
+		if err := api.ValidateContext(ctx); err != nil {
+			t.Fatalf("%s: validate %s: %v\n", msg, outFile, err)
+		}
+		pt, err := ctx.ExtractPageText(1)
+		if err != nil {
+			t.Fatalf("%s: extract text %s: %v\n", msg, outFile, err)
+		}
+		if got, want := pt.Blocks[0].Lines[0].Text, "The Center of “Why?”"; got != want {
+			t.Fatalf("%s: want %q, got %q\n", msg, want, got)
+		}
+
+		// Validation and optimization work on encrypted input given the user password.
+		conf = confForAlgorithm(tt.aes, tt.keyLength, "upw", "")
+		if err := api.ValidateFile(outFile, conf); err != nil {
+			t.Fatalf("%s: validate %s: %v\n", msg, outFile, err)
+		}
+		conf = confForAlgorithm(tt.aes, tt.keyLength, "upw", "opw")
+		if err := api.OptimizeFile(outFile, "", conf); err != nil {
+			t.Fatalf("%s: optimize %s: %v\n", msg, outFile, err)
+		}
+	}
+}
+
+// denyAll refuses any command unless the owner password has been supplied.
+type denyAll struct{}
+
+func (denyAll) Permit(cmd pdf.CommandMode, enc *pdf.Enc, ownerPW bool) error {
+	if ownerPW {
+		return nil
+	}
+	return pdf.ErrPermissionDenied
+}
+
+func TestPermissionPolicy(t *testing.T) {
+	msg := "TestPermissionPolicy"
+	inFile := filepath.Join(outDir, "permissionPolicy.pdf")
+	encFile := filepath.Join(outDir, "permissionPolicyEnc.pdf")
+
+	if err := testpdf.File(inFile, testpdf.Page{Text: "Hello World"}); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Encrypt without granting the copy permission.
+	conf := confForAlgorithm(true, 256, "upw", "opw")
+	conf.Permissions = pdf.PermissionsNone
+	if err := api.EncryptFile(inFile, encFile, conf); err != nil {
+		t.Fatalf("%s: encrypt %s: %v\n", msg, encFile, err)
+	}
+
+	extractText := func(upw, opw string, f func(*pdf.Configuration)) error {
+		conf := confForAlgorithm(true, 256, upw, opw)
+		conf.Cmd = pdf.EXTRACTTEXT
+		if f != nil {
+			f(conf)
+		}
+		return api.ExtractTextFile(encFile, outDir, nil, conf)
+	}
+
+	if err := extractText("upw", "", nil); err != pdf.ErrPermissionDenied {
+		t.Fatalf("%s: want %v, got %v\n", msg, pdf.ErrPermissionDenied, err)
+	}
+
+	// The owner password overrides permissions.
+	if err := extractText("", "opw", nil); err != nil {
+		t.Fatalf("%s: extract text using opw: %v\n", msg, err)
+	}
+
+	if err := extractText("upw", "", func(conf *pdf.Configuration) { conf.IgnorePermissions = true }); err != nil {
+		t.Fatalf("%s: extract text ignoring permissions: %v\n", msg, err)
+	}
+
+	// A custom policy is consulted for any command.
+	conf = confForAlgorithm(true, 256, "upw", "")
+	conf.Cmd = pdf.VALIDATE
+	conf.PermissionPolicy = denyAll{}
+	if err := api.ValidateFile(encFile, conf); err != pdf.ErrPermissionDenied {
+		t.Fatalf("%s: want %v, got %v\n", msg, pdf.ErrPermissionDenied, err)
+	}
+	conf.OwnerPW = "opw"
+	if err := api.ValidateFile(encFile, conf); err != nil {
+		t.Fatalf("%s: validate using opw: %v\n", msg, err)
+	}
+}
+
+func TestFillFormsPermission(t *testing.T) {
+	msg := "TestFillFormsPermission"
+	inFile := createAcroFormDemo(t)
+	fields := []pdf.FormField{{Name: "inputField", Value: "Filled in"}}
+
+	fillForm := func(perms int16) error {
+		encFile := filepath.Join(outDir, "fillFormsPermissionEnc.pdf")
+		conf := confForAlgorithm(true, 256, "upw", "opw")
+		conf.Permissions = perms
+		if err := api.EncryptFile(inFile, encFile, conf); err != nil {
+			t.Fatalf("%s: encrypt %s: %v\n", msg, encFile, err)
+		}
+		bb, err := ioutil.ReadFile(encFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		conf = confForAlgorithm(true, 256, "upw", "")
+		// The XFA array of the demo form comes out of decryption as hex strings.
+		conf.ValidationMode = pdf.ValidationNone
+		var buf bytes.Buffer
+		return api.FillForm(bytes.NewReader(bb), &buf, fields, false, conf)
+	}
+
+	// Bit 9 grants filling in form fields even without the modify permission.
+	if err := fillForm(pdf.PermissionsNone | pdf.PermissionFillForms); err != nil {
+		t.Fatalf("%s: fill form permitting forms only: %v\n", msg, err)
+	}
+
+	if err := fillForm(pdf.PermissionsNone); err != pdf.ErrPermissionDenied {
+		t.Fatalf("%s: want %v, got %v\n", msg, pdf.ErrPermissionDenied, err)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("no entropy")
+}
+
+func TestEncryptRandReader(t *testing.T) {
+	msg := "TestEncryptRandReader"
+
+	inFile := filepath.Join(inDir, "test.pdf")
+	clock := fixedClock(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	encrypt := func(rnd io.Reader) ([]byte, error) {
+		conf := pdf.NewAESConfiguration("upw", "opw", 256)
+		conf.Cmd = pdf.ENCRYPT
+		conf.Clock = clock
+		conf.RandReader = rnd
+		f, err := os.Open(inFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		defer f.Close()
+		var buf bytes.Buffer
+		err = api.Optimize(f, &buf, conf)
+		return buf.Bytes(), err
+	}
+
+	// The same random source produces the same file.
+	bb1, err := encrypt(mrand.New(mrand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	bb2, err := encrypt(mrand.New(mrand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.Equal(bb1, bb2) {
+		t.Fatalf("%s: output not reproducible\n", msg)
+	}
+
+	conf := pdf.NewAESConfiguration("upw", "opw", 256)
+	if err := api.Validate(bytes.NewReader(bb1), conf); err != nil {
+		t.Fatalf("%s validate: %v\n", msg, err)
+	}
+
+	// No randomness is drawn from anywhere else.
+	if _, err := encrypt(failingReader{}); err == nil {
+		t.Fatalf("%s: want error for failing random source\n", msg)
+	}
+}
@@ -17,9 +17,16 @@ limitations under the License.
 package test
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -194,6 +201,238 @@ func TestExtractContentLowLevel(t *testing.T) {
 	t.Logf("Page content (PDF-syntax) for page %d:\n%s", i, string(bb))
 }
 
+func TestExtractText(t *testing.T) {
+	msg := "TestExtractText"
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+	if err := api.ExtractTextFile(inFile, outDir, []string{"1"}, nil); err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+
+	bb, err := ioutil.ReadFile(filepath.Join(outDir, "CenterOfWhy_Text_page_1.json"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	var pt pdfcpu.PageText
+	if err := json.Unmarshal(bb, &pt); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if pt.Page != 1 || len(pt.Blocks) == 0 {
+		t.Fatalf("%s: unexpected page text: %v\n", msg, pt)
+	}
+
+	l := pt.Blocks[0].Lines[0]
+	if want := "The Center of “Why?”"; l.Text != want {
+		t.Fatalf("%s: want %q, got %q\n", msg, want, l.Text)
+	}
+	if len(l.Words) != 4 || l.Words[3].Text != "“Why?”" {
+		t.Fatalf("%s: unexpected words: %v\n", msg, l.Words)
+	}
+
+	// Tagged content follows the logical order of the structure tree:
+	// The dedication placed at the top of page 2 comes after the body text.
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	pt1, err := ctx.ExtractPageText(2)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !pt1.Tagged || pt1.Blocks[0].Role != "P" {
+		t.Fatalf("%s: missing tagged content\n", msg)
+	}
+	i, j := -1, -1
+	for k, b := range pt1.Blocks {
+		switch {
+		case strings.HasPrefix(b.Lines[0].Text, "Books and more Books"):
+			i = k
+		case strings.HasPrefix(b.Lines[0].Text, "Dedicated to"):
+			j = k
+		}
+	}
+	if i < 0 || j < i {
+		t.Fatalf("%s: unexpected block order %d %d\n", msg, i, j)
+	}
+}
+
+func TestExtractPageText(t *testing.T) {
+	msg := "TestExtractPageText"
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+
+	f, err := os.Open(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+
+	pt, err := api.ExtractPageText(f, 1, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if s := pt.String(); !strings.HasPrefix(s, "The Center of “Why?”\n") {
+		t.Fatalf("%s: unexpected plain text: %q\n", msg, s)
+	}
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	gg, err := ctx.ExtractPageGlyphs(1)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	// Skip leading white space.
+	for len(gg) > 0 && strings.TrimSpace(gg[0].Text) == "" {
+		gg = gg[1:]
+	}
+	if len(gg) < 3 || gg[0].Text+gg[1].Text+gg[2].Text != "The" {
+		t.Fatalf("%s: unexpected glyphs\n", msg)
+	}
+
+	// Glyphs of a word share their baseline and advance left to right.
+	for i := 1; i < 3; i++ {
+		if gg[i].Origin[1] != gg[0].Origin[1] || gg[i].Origin[0] <= gg[i-1].Origin[0] || gg[i].Dir != 0 {
+			t.Fatalf("%s: unexpected glyph position: %v\n", msg, gg[i])
+		}
+	}
+	if w := pt.Blocks[0].Lines[0].Words[0]; gg[0].BBox[0] != w.BBox[0] {
+		t.Fatalf("%s: want glyph box starting at %.2f, got %.2f\n", msg, w.BBox[0], gg[0].BBox[0])
+	}
+
+	if _, err := api.ExtractPageText(f, 99, nil); err == nil {
+		t.Fatalf("%s: want error for invalid page\n", msg)
+	}
+}
+
+// svgContent returns the text and the number of images of an SVG document.
+func svgContent(t *testing.T, bb []byte) (string, int) {
+	t.Helper()
+
+	var (
+		sb     strings.Builder
+		images int
+		inText bool
+	)
+
+	d := xml.NewDecoder(bytes.NewReader(bb))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid svg: %v\n", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			inText = tok.Name.Local == "text"
+			if tok.Name.Local == "image" {
+				images++
+			}
+		case xml.EndElement:
+			if tok.Name.Local == "text" {
+				sb.WriteString("\n")
+			}
+			inText = false
+		case xml.CharData:
+			if inText {
+				sb.Write(tok)
+			}
+		}
+	}
+
+	return sb.String(), images
+}
+
+func TestExtractSVG(t *testing.T) {
+	msg := "TestExtractSVG"
+	inFile := filepath.Join(inDir, "adobe_errata.pdf")
+
+	if err := api.ExtractSVGFile(inFile, outDir, []string{"1-2"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for _, fn := range []string{"adobe_errata_page_1.svg", "adobe_errata_page_2.svg"} {
+		bb, err := ioutil.ReadFile(filepath.Join(outDir, fn))
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		svgContent(t, bb)
+	}
+
+	f, err := os.Open(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+
+	var buf bytes.Buffer
+	if err := api.ExtractPageSVG(f, &buf, 2, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if s, _ := svgContent(t, buf.Bytes()); !strings.Contains(s, "© 2007 Adobe Systems Incorporated. All rights reserved.") {
+		t.Fatalf("%s: missing text\n", msg)
+	}
+
+	// Images get embedded.
+	f1, err := os.Open(filepath.Join(inDir, "CenterOfWhy.pdf"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f1.Close()
+
+	buf.Reset()
+	if err := api.ExtractPageSVG(f1, &buf, 1, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	s, images := svgContent(t, buf.Bytes())
+	if images == 0 {
+		t.Fatalf("%s: missing image\n", msg)
+	}
+	if !strings.Contains(s, "The Center of") {
+		t.Fatalf("%s: missing text\n", msg)
+	}
+}
+
+func TestExtractTables(t *testing.T) {
+	msg := "TestExtractTables"
+	inFile := filepath.Join(inDir, "BuildingWebappsWithGo.pdf")
+
+	// Extract the table on page 3 as CSV.
+	if err := api.ExtractTablesFile(inFile, outDir, []string{"3"}, false, nil); err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+	f, err := os.Open(filepath.Join(outDir, "BuildingWebappsWithGo_Table_page_3_1.csv"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(rows) != 6 || !reflect.DeepEqual(rows[0], []string{"Name", "Import Path", "Description"}) {
+		t.Fatalf("%s: unexpected rows: %v\n", msg, rows)
+	}
+
+	// Extract the same table as JSON.
+	if err := api.ExtractTablesFile(inFile, outDir, []string{"3"}, true, nil); err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+	bb, err := ioutil.ReadFile(filepath.Join(outDir, "BuildingWebappsWithGo_Tables_page_3.json"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	var pt pdfcpu.PageTables
+	if err := json.Unmarshal(bb, &pt); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(pt.Tables) != 1 || !pt.Tables[0].Ruled || !reflect.DeepEqual(pt.Tables[0].Rows, rows) {
+		t.Fatalf("%s: unexpected tables: %v\n", msg, pt.Tables)
+	}
+}
+
 func TestExtractMetadata(t *testing.T) {
 	msg := "TestExtractMetadata"
 	// Extract all metadata into outDir.
@@ -0,0 +1,64 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestFeatures(t *testing.T) {
+	msg := "TestFeatures"
+
+	r, err := api.FeaturesFile(filepath.Join(inDir, "WaldenFull.pdf"), nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !r.Linearized || !r.XRefStreams || !r.Transparency || r.Layers != 1 || !r.Tagged || len(r.Fonts) == 0 || r.Encryption != "" {
+		t.Fatalf("%s: unexpected report: %+v\n", msg, r)
+	}
+	if len(r.List()) < 15 {
+		t.Fatalf("%s: incomplete listing: %v\n", msg, r.List())
+	}
+
+	r, err = api.FeaturesFile(filepath.Join(inDir, "test.pdf"), nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if r.Transparency || r.Layers != 0 || r.JavaScript || r.Forms || r.Tagged || r.EmbeddedFiles != 0 {
+		t.Fatalf("%s: unexpected report: %+v\n", msg, r)
+	}
+
+	// Encryption gets reported by algorithm.
+	outFile := filepath.Join(outDir, "features.pdf")
+	conf := confForAlgorithm(true, 256, "", "opw")
+	if err := api.EncryptFile(filepath.Join(inDir, "test.pdf"), outFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	conf = pdfcpu.NewDefaultConfiguration()
+	conf.OwnerPW = "opw"
+	r, err = api.FeaturesFile(outFile, conf)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if r.Encryption != "AES 256 bit" {
+		t.Fatalf("%s: got encryption %q\n", msg, r.Encryption)
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/testpdf"
+)
+
+const customFilter = "ReverseDecode"
+
+// pdfWithCustomFilter returns a PDF containing a stream encoded with the filter filterName.
+func pdfWithCustomFilter(t *testing.T, filterName string, content []byte) []byte {
+	t.Helper()
+
+	ctx, err := testpdf.Context(testpdf.Page{Text: "Hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw := reverse(content)
+	l := int64(len(raw))
+	sd := pdfcpu.StreamDict{Dict: pdfcpu.NewDict(), Raw: raw, StreamLength: &l}
+	sd.InsertName("Filter", filterName)
+	sd.InsertInt("Length", len(raw))
+	sd.FilterPipeline = []pdfcpu.PDFFilter{{Name: filterName}}
+
+	ir, err := ctx.IndRefForNewObject(sd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Writing only considers known root dict entries.
+	rootDict.Insert("Metadata", *ir)
+
+	var buf bytes.Buffer
+	if err := api.WriteContext(ctx, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func reverse(bb []byte) []byte {
+	rr := make([]byte, len(bb))
+	for i, b := range bb {
+		rr[len(bb)-1-i] = b
+	}
+	return rr
+}
+
+func customStream(t *testing.T, ctx *pdfcpu.Context) *pdfcpu.StreamDict {
+	t.Helper()
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, _, err := ctx.DereferenceStreamDict(rootDict["Metadata"])
+	if err != nil || sd == nil {
+		t.Fatalf("missing metadata stream: %v\n", err)
+	}
+	return sd
+}
+
+func TestFilterPolicy(t *testing.T) {
+	msg := "TestFilterPolicy"
+	content := []byte("Some content encoded by a custom filter.")
+	bb := pdfWithCustomFilter(t, customFilter, content)
+
+	// Preserve the raw stream data.
+	conf := pdfcpu.NewDefaultConfiguration()
+	ctx, err := api.ReadContext(bytes.NewReader(bb), conf)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if sd := customStream(t, ctx); sd.Content != nil {
+		t.Fatalf("%s: preserve: unexpected content\n", msg)
+	}
+
+	var buf bytes.Buffer
+	if err := api.WriteContext(ctx, &buf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ctx, err = api.ReadContext(bytes.NewReader(buf.Bytes()), conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if sd := customStream(t, ctx); !bytes.Equal(sd.Raw, reverse(content)) {
+		t.Fatalf("%s: preserve: raw stream data corrupted\n", msg)
+	}
+
+	// Fail on unsupported filters.
+	conf = pdfcpu.NewDefaultConfiguration()
+	conf.FilterPolicy = pdfcpu.FilterPolicyError
+	if _, err = api.ReadContext(bytes.NewReader(bb), conf); err == nil {
+		t.Fatalf("%s: error: missing error\n", msg)
+	}
+
+	// Decode using an external decoder.
+	filter.RegisterDecoder(customFilter, func(r io.Reader, parms map[string]int) (io.Reader, error) {
+		bb, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(reverse(bb)), nil
+	})
+	defer filter.RegisterDecoder(customFilter, nil)
+
+	conf = pdfcpu.NewDefaultConfiguration()
+	conf.FilterPolicy = pdfcpu.FilterPolicyDecode
+	if ctx, err = api.ReadContext(bytes.NewReader(bb), conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if sd := customStream(t, ctx); !bytes.Equal(sd.Content, content) {
+		t.Fatalf("%s: decode: got %q, want %q\n", msg, sd.Content, content)
+	}
+}
+
+func TestMisspelledFilter(t *testing.T) {
+	msg := "TestMisspelledFilter"
+	bb := pdfWithCustomFilter(t, "FlateDecod", []byte("Some content."))
+
+	// Misspelled filter names are not subject to the filter policy.
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.FilterPolicy = pdfcpu.FilterPolicyError
+	ctx, err := api.ReadContext(bytes.NewReader(bb), conf)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// but get reported as invalid when decoding.
+	err = customStream(t, ctx).Decode()
+	if err == nil || err == filter.ErrUnsupportedFilter {
+		t.Fatalf("%s: want invalid filter error, got %v\n", msg, err)
+	}
+}
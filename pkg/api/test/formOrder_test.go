@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func createAcroFormDemo(t *testing.T) string {
+	t.Helper()
+	xRefTable, err := pdfcpu.CreateAcroFormDemoXRef()
+	if err != nil {
+		t.Fatalf("createAcroFormDemo: %v\n", err)
+	}
+	fileName := filepath.Join(outDir, "AcroFormDemo.pdf")
+	if err := api.CreatePDFFile(xRefTable, fileName, nil); err != nil {
+		t.Fatalf("createAcroFormDemo: %v\n", err)
+	}
+	return fileName
+}
+
+func TestTabOrder(t *testing.T) {
+	msg := "TestTabOrder"
+	inFile := createAcroFormDemo(t)
+	outFile := filepath.Join(outDir, "TabOrder.pdf")
+
+	if err := api.SetTabOrderFile(inFile, outFile, nil, "column", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ss, err := api.ListTabOrdersFile(outFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if want := []string{"page 1: C (column)"}; !reflect.DeepEqual(ss, want) {
+		t.Fatalf("%s: want %v, got %v\n", msg, want, ss)
+	}
+
+	// The tab order survives page manipulation.
+	collFile := filepath.Join(outDir, "TabOrderCollected.pdf")
+	if err := api.CollectFile(outFile, collFile, []string{"1", "1"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ss, err = api.ListTabOrdersFile(collFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if want := []string{"page 1: C (column)", "page 2: C (column)"}; !reflect.DeepEqual(ss, want) {
+		t.Fatalf("%s: want %v, got %v\n", msg, want, ss)
+	}
+
+	if err := api.SetTabOrderFile(outFile, "", nil, "", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ss, err = api.ListTabOrdersFile(outFile, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if want := []string{"page 1: none"}; !reflect.DeepEqual(ss, want) {
+		t.Fatalf("%s: want %v, got %v\n", msg, want, ss)
+	}
+}
+
+func TestCalculationOrder(t *testing.T) {
+	msg := "TestCalculationOrder"
+	inFile := createAcroFormDemo(t)
+	outFile := filepath.Join(outDir, "CalculationOrder.pdf")
+
+	ss, err := api.CalculationOrderFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if want := []string{"inputField"}; !reflect.DeepEqual(ss, want) {
+		t.Fatalf("%s: want %v, got %v\n", msg, want, ss)
+	}
+
+	want := []string{"CheckBox", "inputField"}
+	if err := api.SetCalculationOrderFile(inFile, outFile, want, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ss, err = api.CalculationOrderFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !reflect.DeepEqual(ss, want) {
+		t.Fatalf("%s: want %v, got %v\n", msg, want, ss)
+	}
+
+	if err := api.SetCalculationOrderFile(inFile, filepath.Join(outDir, "CalculationOrderUnknown.pdf"), []string{"unknown"}, nil); err == nil {
+		t.Fatalf("%s: missing error for unknown field\n", msg)
+	}
+
+	// Collecting the form page keeps form and calculation order.
+	collFile := filepath.Join(outDir, "CalculationOrderCollected.pdf")
+	if err := api.CollectFile(outFile, collFile, []string{"1"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ss, err = api.CalculationOrderFile(collFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !reflect.DeepEqual(ss, want) {
+		t.Fatalf("%s: want %v, got %v\n", msg, want, ss)
+	}
+	if got := fieldNames(t, collFile); !reflect.DeepEqual(got, fieldNames(t, outFile)) {
+		t.Fatalf("%s: want fields %v, got %v\n", msg, fieldNames(t, outFile), got)
+	}
+
+	// Removing the form page removes its fields and their calculation order.
+	mergedFile := filepath.Join(outDir, "CalculationOrderMerged.pdf")
+	if err := api.MergeCreateFile([]string{outFile, filepath.Join(inDir, "CenterOfWhy.pdf")}, mergedFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.RemovePagesFile(mergedFile, "", []string{"1"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ss, err = api.CalculationOrderFile(mergedFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want empty calculation order, got %v\n", msg, ss)
+	}
+	if got := fieldNames(t, mergedFile); len(got) > 0 {
+		t.Fatalf("%s: want no fields, got %v\n", msg, got)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func pageXObjectCount(t *testing.T, ctx *pdfcpu.Context, pageNr int) int {
+	t.Helper()
+	d, _, _, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := ctx.DereferenceDict(d["Resources"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	xo, err := ctx.DereferenceDict(res["XObject"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(xo)
+}
+
+func TestFormXObject(t *testing.T) {
+	msg := "TestFormXObject"
+	srcFile := filepath.Join(inDir, "Acroforms2.pdf")
+	destFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
+	outFile := filepath.Join(outDir, "formXObject.pdf")
+
+	ctxSrc, err := api.ReadContextFile(srcFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctxDest, err := api.ReadContextFile(destFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	n := pageXObjectCount(t, ctxDest, 1)
+
+	// Capture a complete page and the lower left quarter of it.
+	fx1, err := ctxSrc.ExtractFormXObject(1, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	r := pdfcpu.Rect(0, 0, fx1.Width()/2, fx1.Height()/2)
+	fx2, err := ctxSrc.ExtractFormXObject(1, r)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if fx2.Width() != r.Width() || fx2.Height() != r.Height() {
+		t.Fatalf("%s: got region %v, want %v\n", msg, fx2.BBox, r)
+	}
+
+	if _, err := ctxSrc.ExtractFormXObject(1, pdfcpu.Rect(0, 0, 2*fx1.Width(), 10)); err == nil {
+		t.Fatalf("%s: expected error for invalid region\n", msg)
+	}
+
+	// Place the page twice and the region once onto another document.
+	for _, p := range []struct {
+		fx *pdfcpu.FormXObject
+		t  pdfcpu.Transform
+	}{
+		{fx1, pdfcpu.NewTransform(.5, .5, 0, 0, 0)},
+		{fx1, pdfcpu.NewTransform(.5, .5, 90, 300, 0)},
+		{fx2, pdfcpu.IdentityTransform},
+	} {
+		if err := ctxDest.PlaceFormXObject(p.fx, 1, p.t); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+
+	// Place the region onto its source document.
+	if err := ctxSrc.PlaceFormXObject(fx2, 1, pdfcpu.IdentityTransform); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.WriteContextFile(ctxDest, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if got := pageXObjectCount(t, ctx, 1); got != n+3 {
+		t.Fatalf("%s: got %d xobjects, want %d\n", msg, got, n+3)
+	}
+
+	outFile = filepath.Join(outDir, "formXObjectSrc.pdf")
+	if err := api.WriteContextFile(ctxSrc, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func formFieldsByName(t *testing.T, fileName string) map[string]pdfcpu.FormField {
+	t.Helper()
+	ff, err := api.FormFieldsFile(fileName, nil)
+	if err != nil {
+		t.Fatalf("formFieldsByName: %v\n", err)
+	}
+	m := map[string]pdfcpu.FormField{}
+	for _, f := range ff {
+		m[f.Name] = f
+	}
+	return m
+}
+
+func TestFormFields(t *testing.T) {
+	msg := "TestFormFields"
+	inFile := createAcroFormDemo(t)
+
+	m := formFieldsByName(t, inFile)
+
+	for name, want := range map[string]pdfcpu.FormField{
+		"inputField":  {Name: "inputField", Type: pdfcpu.FieldText, Value: "Default value"},
+		"CheckBox":    {Name: "CheckBox", Type: pdfcpu.FieldCheckBox, Value: "Yes", Options: []string{"Yes"}},
+		"Terms":       {Name: "Terms", Type: pdfcpu.FieldCheckBox, Value: "Off", Options: []string{"Accepted"}},
+		"Credit card": {Name: "Credit card", Type: pdfcpu.FieldRadio, Value: "card1", Options: []string{"card1", "card2"}},
+		"Signature":   {Name: "Signature", Type: pdfcpu.FieldSignature},
+		"Reset":       {Name: "Reset", Type: pdfcpu.FieldButton},
+	} {
+		got, ok := m[name]
+		if !ok {
+			t.Fatalf("%s: missing field %s\n", msg, name)
+		}
+		if got.String() != want.String() {
+			t.Errorf("%s: want %s, got %s\n", msg, want, got)
+		}
+	}
+}
+
+func TestExportFormFieldsPermissions(t *testing.T) {
+	msg := "TestExportFormFieldsPermissions"
+	inFile := createAcroFormDemo(t)
+	encFile := filepath.Join(outDir, "AcroFormDemoEncrypted.pdf")
+
+	// Encrypt granting no permissions.
+	if err := api.EncryptFile(inFile, encFile, pdfcpu.NewAESConfiguration("upw", "opw", 256)); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Exporting field values needs the extract permission.
+	conf := pdfcpu.NewAESConfiguration("upw", "", 256)
+	if err := api.ExportFormFieldsFile(encFile, filepath.Join(outDir, "AcroFormDemoEncrypted.json"), conf); err == nil {
+		t.Fatalf("%s: export without extract permission succeeded\n", msg)
+	}
+	if conf.Cmd != pdfcpu.EXPORTFORMFIELDS {
+		t.Fatalf("%s: want command mode %d, got %d\n", msg, pdfcpu.EXPORTFORMFIELDS, conf.Cmd)
+	}
+}
+
+func TestFillForm(t *testing.T) {
+	msg := "TestFillForm"
+	inFile := createAcroFormDemo(t)
+	jsonFile := filepath.Join(outDir, "AcroFormDemo.json")
+	outFile := filepath.Join(outDir, "AcroFormDemoFilled.pdf")
+
+	if err := api.ExportFormFieldsFile(inFile, jsonFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	bb, err := ioutil.ReadFile(jsonFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	fields, err := api.ReadFormData(bytes.NewReader(bb))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Edit the exported form data.
+	var fill []pdfcpu.FormField
+	for _, f := range fields {
+		switch f.Name {
+		case "inputField":
+			f.Value = "Grüße (a) b"
+		case "CheckBox":
+			f.Value = "Off"
+		case "Terms":
+			f.Value = "Accepted"
+		case "Credit card":
+			f.Value = "card2"
+		default:
+			continue
+		}
+		fill = append(fill, f)
+	}
+
+	bb, err = json.Marshal(pdfcpu.FormData{Fields: fill})
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := ioutil.WriteFile(jsonFile, bb, 0644); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.FillFormFile(inFile, jsonFile, outFile, false, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	m := formFieldsByName(t, outFile)
+	for name, want := range map[string]string{"inputField": "Grüße (a) b", "CheckBox": "Off", "Terms": "Accepted", "Credit card": "card2"} {
+		if got := m[name].Value; got != want {
+			t.Errorf("%s: %s: want %q, got %q\n", msg, name, want, got)
+		}
+	}
+
+	// The text field has a new appearance displaying its value.
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	root, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	af, err := ctx.DereferenceDict(root["AcroForm"])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if _, found := af.Find("XFA"); found {
+		t.Errorf("%s: XFA not removed\n", msg)
+	}
+	fields1, err := ctx.DereferenceArray(af["Fields"])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, err := ctx.DereferenceDict(fields1[0])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd, _, err := ctx.DereferenceStreamDict(d.DictEntry("AP")["N"])
+	if err != nil || sd == nil {
+		t.Fatalf("%s: missing appearance: %v\n", msg, err)
+	}
+	if err := sd.Decode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if want := []byte("(Gr\xfc\xdfe \\(a\\) b) Tj"); !bytes.Contains(sd.Content, want) {
+		t.Errorf("%s: want appearance showing %q, got %q\n", msg, want, sd.Content)
+	}
+
+	// With NeedAppearances the appearance is left to the viewer.
+	if err := api.FillFormFile(inFile, jsonFile, outFile, true, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if root, err = ctx.Catalog(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if af, err = ctx.DereferenceDict(root["AcroForm"]); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if b := af.BooleanEntry("NeedAppearances"); b == nil || !*b {
+		t.Errorf("%s: NeedAppearances not set\n", msg)
+	}
+
+	if bb, err = ioutil.ReadFile(inFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for _, f := range []pdfcpu.FormField{
+		{Name: "unknown", Value: "x"},
+		{Name: "CheckBox", Value: "Maybe"},
+		{Name: "Credit card", Value: "card3"},
+		{Name: "Reset", Value: "x"},
+	} {
+		var buf bytes.Buffer
+		if err := api.FillForm(bytes.NewReader(bb), &buf, []pdfcpu.FormField{f}, false, nil); err == nil {
+			t.Errorf("%s: %s: missing error\n", msg, f.Name)
+		}
+	}
+}
+
+func TestFormFillerConcurrent(t *testing.T) {
+	msg := "TestFormFillerConcurrent"
+	inFile := createAcroFormDemo(t)
+
+	bb, err := ioutil.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ff, err := api.NewFormFiller(bytes.NewReader(bb), nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	const n = 8
+	out := make([]bytes.Buffer, n)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fields := []pdfcpu.FormField{
+				{Name: "inputField", Value: fmt.Sprintf("Variant %d", i)},
+				{Name: "Credit card", Value: []string{"card1", "card2"}[i%2]},
+			}
+			errs[i] = ff.Fill(&out[i], fields, false)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			t.Fatalf("%s: variant %d: %v\n", msg, i, errs[i])
+		}
+		ff, err := api.FormFields(bytes.NewReader(out[i].Bytes()), nil)
+		if err != nil {
+			t.Fatalf("%s: variant %d: %v\n", msg, i, err)
+		}
+		m := map[string]string{}
+		for _, f := range ff {
+			m[f.Name] = f.Value
+		}
+		if want := fmt.Sprintf("Variant %d", i); m["inputField"] != want {
+			t.Errorf("%s: want %q, got %q\n", msg, want, m["inputField"])
+		}
+		if want := []string{"card1", "card2"}[i%2]; m["Credit card"] != want {
+			t.Errorf("%s: want %q, got %q\n", msg, want, m["Credit card"])
+		}
+	}
+
+	// The template remains untouched.
+	var buf bytes.Buffer
+	if err := ff.Fill(&buf, nil, false); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	fields, err := api.FormFields(bytes.NewReader(buf.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for _, f := range fields {
+		if f.Name == "inputField" && f.Value != "Default value" {
+			t.Errorf("%s: template modified: %q\n", msg, f.Value)
+		}
+	}
+}
+
+func TestFormFillerKeepsConf(t *testing.T) {
+	msg := "TestFormFillerKeepsConf"
+	inFile := createAcroFormDemo(t)
+
+	bb, err := ioutil.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.Cmd = pdfcpu.VALIDATE
+	conf.Incremental = true
+
+	if _, err := api.NewFormFiller(bytes.NewReader(bb), conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if conf.Cmd != pdfcpu.VALIDATE || !conf.Incremental {
+		t.Errorf("%s: caller's configuration modified\n", msg)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func addKeywordIncrementally(t *testing.T, msg string, bb []byte, keyword string) []byte {
+	t.Helper()
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.Incremental = true
+
+	var buf bytes.Buffer
+	if err := api.AddKeywords(bytes.NewReader(bb), &buf, []string{keyword}, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// The original bytes are kept untouched.
+	if !bytes.HasPrefix(buf.Bytes(), bb) {
+		t.Fatalf("%s: original bytes modified\n", msg)
+	}
+
+	// Only the modified info dict and the new xref section get appended.
+	if n := buf.Len() - len(bb); n == 0 || n > 2048 {
+		t.Fatalf("%s: unexpected increment size %d\n", msg, n)
+	}
+
+	return buf.Bytes()
+}
+
+func TestIncrementalUpdate(t *testing.T) {
+	// go.pdf uses an xref table, bookletTest.pdf uses xref and object streams.
+	for _, fileName := range []string{"go.pdf", "bookletTest.pdf"} {
+		msg := "TestIncrementalUpdate " + fileName
+
+		bb, err := ioutil.ReadFile(filepath.Join(inDir, fileName))
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		bb = addKeywordIncrementally(t, msg, bb, "first")
+		bb = addKeywordIncrementally(t, msg, bb, "second")
+
+		if err := api.Validate(bytes.NewReader(bb), nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		kw, err := api.ListKeywords(bytes.NewReader(bb), nil)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if want := []string{"first", "second"}; !reflect.DeepEqual(kw, want) {
+			t.Fatalf("%s: want %v, got %v\n", msg, want, kw)
+		}
+	}
+}
+
+func TestIncrementalUpdateEncrypt(t *testing.T) {
+	msg := "TestIncrementalUpdateEncrypt"
+
+	inFile := filepath.Join(inDir, "go.pdf")
+	outFile := filepath.Join(outDir, "goIncrEnc.pdf")
+
+	conf := pdfcpu.NewAESConfiguration("upw", "opw", 256)
+	conf.Incremental = true
+
+	if err := api.EncryptFile(inFile, outFile, conf); err == nil {
+		t.Fatalf("%s: missing error\n", msg)
+	}
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/testpdf"
+)
+
+// writePDFWithExternalMetadata writes a PDF to inFile whose metadata stream lives in the Flate encoded file extFile.
+func writePDFWithExternalMetadata(t *testing.T, inFile, extFile string, content []byte) {
+	t.Helper()
+
+	f, err := filter.NewFilter(filter.Flate, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := f.Encode(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bb, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(extFile, bb, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, err := testpdf.Context(testpdf.Page{Text: "Hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := int64(0)
+	sd := pdfcpu.StreamDict{Dict: pdfcpu.NewDict(), StreamLength: &l}
+	sd.InsertName("Type", "Metadata")
+	sd.InsertName("Subtype", "XML")
+	sd.InsertInt("Length", 0)
+	sd.InsertString("F", filepath.Base(extFile))
+	sd.InsertName("FFilter", filter.Flate)
+
+	ir, err := ctx.IndRefForNewObject(sd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootDict.Insert("Metadata", *ir)
+
+	if err := api.WriteContextFile(ctx, inFile); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func metadataStream(t *testing.T, ctx *pdfcpu.Context) *pdfcpu.StreamDict {
+	t.Helper()
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, _, err := ctx.DereferenceStreamDict(rootDict["Metadata"])
+	if err != nil || sd == nil {
+		t.Fatalf("missing metadata stream: %v\n", err)
+	}
+	return sd
+}
+
+func TestInternalizeStreams(t *testing.T) {
+	msg := "TestInternalizeStreams"
+	content := []byte("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\"></x:xmpmeta>")
+	inFile := filepath.Join(outDir, "externalStream.pdf")
+	extFile := filepath.Join(outDir, "externalStream.xml.z")
+	outFile := filepath.Join(outDir, "internalizedStream.pdf")
+
+	writePDFWithExternalMetadata(t, inFile, extFile, content)
+
+	// Resolve external stream data on read.
+	bb, err := ioutil.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.ResolveExternalStreams = true
+	conf.FileSystem = os.DirFS(outDir)
+	ctx, err := api.ReadContext(bytes.NewReader(bb), conf)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd := metadataStream(t, ctx)
+	if !sd.IsExternal() {
+		t.Fatalf("%s: expected external stream\n", msg)
+	}
+	if !bytes.Equal(sd.Content, content) {
+		t.Fatalf("%s: read: got %q, want %q\n", msg, sd.Content, content)
+	}
+
+	// Embed external stream data.
+	if err := api.InternalizeStreamsFile(inFile, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := os.Remove(extFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd = metadataStream(t, ctx)
+	if sd.IsExternal() {
+		t.Fatalf("%s: expected internal stream\n", msg)
+	}
+	if err := sd.Decode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.Equal(sd.Content, content) {
+		t.Fatalf("%s: internalize: got %q, want %q\n", msg, sd.Content, content)
+	}
+}
+
+// countingFS counts the files accessed.
+type countingFS struct {
+	fstest.MapFS
+	opened int
+}
+
+func (fsys *countingFS) Open(name string) (fs.File, error) {
+	fsys.opened++
+	return fsys.MapFS.Open(name)
+}
+
+func (fsys *countingFS) Stat(name string) (fs.FileInfo, error) {
+	fsys.opened++
+	return fsys.MapFS.Stat(name)
+}
+
+func TestExternalStreamsNotResolvedByDefault(t *testing.T) {
+	msg := "TestExternalStreamsNotResolvedByDefault"
+	content := []byte("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\"></x:xmpmeta>")
+	inFile := filepath.Join(outDir, "externalStreamDefault.pdf")
+	extFile := filepath.Join(outDir, "externalStreamDefault.xml.z")
+
+	writePDFWithExternalMetadata(t, inFile, extFile, content)
+
+	bb, err := ioutil.ReadFile(extFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	fsys := &countingFS{MapFS: fstest.MapFS{filepath.Base(extFile): &fstest.MapFile{Data: bb}}}
+
+	// Neither the configured file system nor the directory of inFile get accessed.
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.FileSystem = fsys
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if sd := metadataStream(t, ctx); len(sd.Content) > 0 {
+		t.Fatalf("%s: unexpected external stream data: %q\n", msg, sd.Content)
+	}
+
+	f, err := os.Open(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+	if ctx, err = api.ReadContext(f, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if sd := metadataStream(t, ctx); len(sd.Content) > 0 {
+		t.Fatalf("%s: unexpected external stream data: %q\n", msg, sd.Content)
+	}
+	if fsys.opened > 0 {
+		t.Fatalf("%s: file system accessed %d time(s)\n", msg, fsys.opened)
+	}
+}
+
+func TestExternalStreamsConfinedToBaseDir(t *testing.T) {
+	msg := "TestExternalStreamsConfinedToBaseDir"
+
+	for _, fileName := range []string{"/etc/passwd", "../externalStream.xml.z", "a/../../externalStream.xml.z"} {
+		ctx, err := testpdf.Context(testpdf.Page{Text: "Hello"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		l := int64(0)
+		sd := pdfcpu.StreamDict{Dict: pdfcpu.NewDict(), StreamLength: &l}
+		sd.InsertInt("Length", 0)
+		sd.InsertString("F", fileName)
+		if _, err := ctx.IndRefForNewObject(sd); err != nil {
+			t.Fatal(err)
+		}
+		fsys := &countingFS{MapFS: fstest.MapFS{}}
+		ctx.FileSystem = fsys
+
+		if _, err := pdfcpu.InternalizeStreams(ctx); err == nil {
+			t.Fatalf("%s: %s: expected error\n", msg, fileName)
+		}
+		if fsys.opened > 0 {
+			t.Fatalf("%s: %s: file system accessed\n", msg, fileName)
+		}
+	}
+}
+
+func TestExternalStreamsSymlink(t *testing.T) {
+	msg := "TestExternalStreamsSymlink"
+	content := []byte("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\"></x:xmpmeta>")
+	dir, outsideDir := t.TempDir(), t.TempDir()
+	inFile := filepath.Join(dir, "externalStreamSymlink.pdf")
+	extFile := filepath.Join(dir, "externalStreamSymlink.xml.z")
+	outsideFile := filepath.Join(outsideDir, "secret.xml.z")
+
+	writePDFWithExternalMetadata(t, inFile, extFile, content)
+
+	// Replace the external file by a symbolic link to a file outside the directory of inFile.
+	if err := os.Rename(extFile, outsideFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := os.Symlink(outsideFile, extFile); err != nil {
+		t.Skipf("%s: %v\n", msg, err)
+	}
+
+	if err := api.InternalizeStreamsFile(inFile, filepath.Join(dir, "internalized.pdf"), nil); err == nil {
+		t.Fatalf("%s: symbolic link followed\n", msg)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestLabels(t *testing.T) {
+	msg := "TestLabels"
+
+	// Create a small label to be repeated.
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	labelFile := filepath.Join(outDir, "label.pdf")
+
+	box, err := api.Box("[0 0 180 90]", pdfcpu.POINTS)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ClipFile(inFile, labelFile, []string{"1"}, box, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, tt := range []struct {
+		desc       string
+		cols, rows int
+		w, h       float64
+	}{
+		{"", 3, 8, 595, 842},
+		{"form:LetterL, ma:10, gu:5 0, bo:on", 2, 5, 792, 612},
+		{"d:400 300, gutter:2", 2, 3, 400, 300},
+	} {
+		outFile := filepath.Join(outDir, "labels.pdf")
+
+		lbl, err := api.LabelsConfig(tt.cols, tt.rows, tt.desc)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.desc, err)
+		}
+
+		if err := api.LabelsFile(labelFile, outFile, nil, lbl, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.desc, err)
+		}
+
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.desc, err)
+		}
+
+		dd, err := api.PageDimsFile(outFile)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.desc, err)
+		}
+
+		if len(dd) != 1 {
+			t.Fatalf("%s %s: got %d pages, want 1\n", msg, tt.desc, len(dd))
+		}
+
+		if math.Abs(dd[0].Width-tt.w) > 1 || math.Abs(dd[0].Height-tt.h) > 1 {
+			t.Fatalf("%s %s: got %.2fx%.2f, want %.2fx%.2f\n", msg, tt.desc, dd[0].Width, dd[0].Height, tt.w, tt.h)
+		}
+	}
+
+	// Margins and gutters exceeding the sheet are rejected.
+	lbl, err := api.LabelsConfig(3, 8, "d:100 100, gu:50")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.LabelsFile(labelFile, filepath.Join(outDir, "labels.pdf"), nil, lbl, nil); err == nil {
+		t.Fatalf("%s: expected error for invalid gutter\n", msg)
+	}
+
+	// Invalid grids are rejected.
+	if _, err := api.LabelsConfig(0, 8, ""); err == nil {
+		t.Fatalf("%s: expected error for invalid grid\n", msg)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+func TestManifest(t *testing.T) {
+	msg := "TestManifest"
+
+	fileName := filepath.Join(outDir, "manifest.pdf")
+	if err := copyFile(t, filepath.Join(inDir, "go.pdf"), fileName); err != nil {
+		t.Fatalf("%s copyFile: %v\n", msg, err)
+	}
+
+	// Attach a file so the manifest covers attachment hashes.
+	if err := api.AddAttachmentsFile(fileName, "", []string{filepath.Join(inDir, "golang.pdf")}, false, nil); err != nil {
+		t.Fatalf("%s add attachments: %v\n", msg, err)
+	}
+
+	manifestFile := filepath.Join(outDir, "manifest.json")
+	if err := api.ManifestFile(fileName, manifestFile, nil); err != nil {
+		t.Fatalf("%s manifest: %v\n", msg, err)
+	}
+
+	if err := api.VerifyFile(fileName, manifestFile, nil); err != nil {
+		t.Fatalf("%s verify: %v\n", msg, err)
+	}
+
+	// Any modification must be detected.
+	if err := api.AddAttachmentsFile(fileName, "", []string{filepath.Join(inDir, "T4.pdf")}, false, nil); err != nil {
+		t.Fatalf("%s add attachments: %v\n", msg, err)
+	}
+
+	err := api.VerifyFile(fileName, manifestFile, nil)
+	if err == nil {
+		t.Fatalf("%s verify: missing error for modified file\n", msg)
+	}
+	for _, s := range []string{"file hash mismatch", "unexpected attachment: T4.pdf"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("%s verify: want %q in %v\n", msg, s, err)
+		}
+	}
+}
@@ -22,9 +22,13 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/testpdf"
 )
 
 func TestMergeCreate(t *testing.T) {
@@ -91,3 +95,537 @@ func TestMergeToBuf(t *testing.T) {
 		t.Fatalf("%s: write: %v\n", msg, err)
 	}
 }
+
+func imageStreams(t *testing.T, fileName string) map[string]bool {
+	t.Helper()
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", fileName, err)
+	}
+	m := map[string]bool{}
+	for _, entry := range ctx.Table {
+		if entry == nil || entry.Free {
+			continue
+		}
+		sd, ok := entry.Object.(pdfcpu.StreamDict)
+		if !ok {
+			continue
+		}
+		if st := sd.Subtype(); st != nil && *st == "Image" {
+			m[string(sd.Raw)] = true
+		}
+	}
+	return m
+}
+
+func TestMergeCopiesRawImageStreams(t *testing.T) {
+	msg := "TestMergeCopiesRawImageStreams"
+	inFiles := []string{
+		filepath.Join(inDir, "testImage.pdf"),
+		filepath.Join(inDir, "Acroforms2.pdf"),
+	}
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	if err := api.MergeCreateFile(inFiles, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Untouched image streams are expected to be written without any decode/encode round trip.
+	want := imageStreams(t, inFiles[0])
+	if len(want) == 0 {
+		t.Fatalf("%s: no images found in %s\n", msg, inFiles[0])
+	}
+	got := imageStreams(t, outFile)
+	for raw := range want {
+		if !got[raw] {
+			t.Fatalf("%s: encoded image stream not copied verbatim\n", msg)
+		}
+	}
+}
+
+func fieldNames(t *testing.T, fileName string) []string {
+	t.Helper()
+	msg := "fieldNames"
+
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s catalog: %v\n", msg, err)
+	}
+
+	d, err := ctx.DereferenceDict(rootDict["AcroForm"])
+	if err != nil || d == nil {
+		t.Fatalf("%s: missing AcroForm: %v\n", msg, err)
+	}
+
+	fields, err := ctx.DereferenceArray(d["Fields"])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ss := []string{}
+	for _, o := range fields {
+		d, err := ctx.DereferenceDict(o)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		s, err := ctx.DereferenceText(d["T"])
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		ss = append(ss, s)
+	}
+
+	return ss
+}
+
+func TestMergeAcroForms(t *testing.T) {
+	msg := "TestMergeAcroForms"
+
+	xRefTable, err := pdfcpu.CreateAcroFormDemoXRef()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	inFile := filepath.Join(outDir, "AcroFormDemo.pdf")
+	if err := api.CreatePDFFile(xRefTable, inFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	inFiles := []string{inFile, inFile}
+	outFile := filepath.Join(outDir, "MergedAcroForms.pdf")
+
+	// Merging a form with itself results in colliding field names.
+	if err := api.MergeCreateFile(inFiles, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	want := fieldNames(t, inFile)
+	if len(want) == 0 {
+		t.Fatalf("%s: missing fields\n", msg)
+	}
+
+	got := fieldNames(t, outFile)
+
+	if len(got) != 2*len(want) {
+		t.Fatalf("%s: want %d fields, got %d\n", msg, 2*len(want), len(got))
+	}
+
+	m := map[string]bool{}
+	for i, s := range got {
+		if m[s] {
+			t.Fatalf("%s: duplicate field name: %s\n", msg, s)
+		}
+		m[s] = true
+		if i >= len(want) && s != want[i-len(want)]+"_1" {
+			t.Fatalf("%s: want field name %s_1, got %s\n", msg, want[i-len(want)], s)
+		}
+	}
+}
+
+func TestMergeOutlines(t *testing.T) {
+	msg := "TestMergeOutlines"
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+
+	// Prepare 2 files with bookmarks.
+	inFile1 := filepath.Join(outDir, "bookmarks1.pdf")
+	bms := []pdfcpu.Bookmark{
+		{PageFrom: 1, Title: "Page 1"},
+		{PageFrom: 2, Title: "Page 2"},
+	}
+	if err := api.AddBookmarksFile(inFile, inFile1, bms, nil); err != nil {
+		t.Fatalf("%s addBookmarks: %v\n", msg, err)
+	}
+
+	inFile2 := filepath.Join(outDir, "bookmarks2.pdf")
+	bms = []pdfcpu.Bookmark{
+		{PageFrom: 1, Title: "Page 1"},
+		{PageFrom: 3, Title: "Page 3"},
+		{PageFrom: 5, Title: "Page 5"},
+	}
+	if err := api.AddBookmarksFile(inFile, inFile2, bms, nil); err != nil {
+		t.Fatalf("%s addBookmarks: %v\n", msg, err)
+	}
+
+	dims, err := api.PageDimsFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	pageCount := len(dims)
+
+	for _, tt := range []struct {
+		mode      int
+		pagesFrom []int
+		titles    []string
+	}{
+		{pdfcpu.MergeOutlinesNested, []int{1, pageCount + 1}, []string{"bookmarks1.pdf", "bookmarks2.pdf"}},
+		{pdfcpu.MergeOutlinesFlat, []int{1, 2, pageCount + 1, pageCount + 3, pageCount + 5}, nil},
+		{pdfcpu.MergeOutlinesNone, []int{1, 2}, nil},
+	} {
+		outFile := filepath.Join(outDir, "MergedOutlines.pdf")
+
+		conf := pdfcpu.NewDefaultConfiguration()
+		conf.MergeOutlines = tt.mode
+
+		if err := api.MergeCreateFile([]string{inFile1, inFile2}, outFile, conf); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		ctx, err := api.ReadContextFile(outFile)
+		if err != nil {
+			t.Fatalf("%s readContext: %v\n", msg, err)
+		}
+
+		bms, err := ctx.BookmarksForOutline()
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		if len(bms) != len(tt.pagesFrom) {
+			t.Fatalf("%s mode %d: want %d bookmarks, got %d\n", msg, tt.mode, len(tt.pagesFrom), len(bms))
+		}
+
+		for i, bm := range bms {
+			if bm.PageFrom != tt.pagesFrom[i] {
+				t.Fatalf("%s mode %d: bookmark %d: want page %d, got %d\n", msg, tt.mode, i, tt.pagesFrom[i], bm.PageFrom)
+			}
+			if tt.titles != nil && bm.Title != tt.titles[i] {
+				t.Fatalf("%s mode %d: bookmark %d: want title %s, got %s\n", msg, tt.mode, i, tt.titles[i], bm.Title)
+			}
+		}
+
+		if tt.mode == pdfcpu.MergeOutlinesNested && len(bms[1].Children) != 3 {
+			t.Fatalf("%s: want 3 nested bookmarks, got %d\n", msg, len(bms[1].Children))
+		}
+	}
+}
+
+func TestMergeOutlinesCreatedOnDemand(t *testing.T) {
+	msg := "TestMergeOutlinesCreatedOnDemand"
+
+	inFile1 := filepath.Join(outDir, "noOutline1.pdf")
+	inFile2 := filepath.Join(outDir, "noOutline2.pdf")
+	for _, inFile := range []string{inFile1, inFile2} {
+		if err := testpdf.File(inFile, testpdf.Pages(2)...); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+
+	inFile3 := filepath.Join(outDir, "withOutline.pdf")
+	bms := []pdfcpu.Bookmark{{PageFrom: 1, Title: "Page 1"}}
+	if err := api.AddBookmarksFile(inFile1, inFile3, bms, nil); err != nil {
+		t.Fatalf("%s addBookmarks: %v\n", msg, err)
+	}
+
+	for _, tt := range []struct {
+		mode    int
+		inFiles []string
+		titles  []string
+	}{
+		// The default leaves outlines alone.
+		{pdfcpu.MergeOutlinesNone, []string{inFile1, inFile3}, nil},
+		{pdfcpu.MergeOutlinesNested, []string{inFile1, inFile2}, nil},
+		{pdfcpu.MergeOutlinesFlat, []string{inFile1, inFile2}, nil},
+		{pdfcpu.MergeOutlinesNested, []string{inFile1, inFile2, inFile3}, []string{"noOutline1.pdf", "noOutline2.pdf", "withOutline.pdf"}},
+		{pdfcpu.MergeOutlinesFiles, []string{inFile1, inFile2}, []string{"noOutline1.pdf", "noOutline2.pdf"}},
+	} {
+		outFile := filepath.Join(outDir, "MergedOutlinesOnDemand.pdf")
+
+		conf := pdfcpu.NewDefaultConfiguration()
+		conf.MergeOutlines = tt.mode
+
+		if err := api.MergeCreateFile(tt.inFiles, outFile, conf); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		ctx, err := api.ReadContextFile(outFile)
+		if err != nil {
+			t.Fatalf("%s readContext: %v\n", msg, err)
+		}
+
+		if tt.titles == nil {
+			if _, found := ctx.RootDict.Find("Outlines"); found {
+				t.Fatalf("%s mode %d: unexpected outline\n", msg, tt.mode)
+			}
+			continue
+		}
+
+		bms, err := ctx.BookmarksForOutline()
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if len(bms) != len(tt.titles) {
+			t.Fatalf("%s mode %d: want %d bookmarks, got %d\n", msg, tt.mode, len(tt.titles), len(bms))
+		}
+		for i, bm := range bms {
+			if bm.Title != tt.titles[i] || bm.PageFrom != 2*i+1 {
+				t.Fatalf("%s mode %d: bookmark %d: want %s on page %d, got %s on page %d\n", msg, tt.mode, i, tt.titles[i], 2*i+1, bm.Title, bm.PageFrom)
+			}
+		}
+	}
+}
+
+func TestMergeStampFileNames(t *testing.T) {
+	msg := "TestMergeStampFileNames"
+
+	inFile1 := filepath.Join(outDir, "evidence1.pdf")
+	inFile2 := filepath.Join(outDir, "evidence 100%.pdf")
+	for _, inFile := range []string{inFile1, inFile2} {
+		if err := testpdf.File(inFile, testpdf.Pages(2)...); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+	outFile := filepath.Join(outDir, "MergedStamped.pdf")
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.MergeOutlines = pdfcpu.MergeOutlinesFiles
+	conf.MergeStampFileNames = true
+
+	if err := api.MergeCreateFile([]string{inFile1, inFile2, inFile1}, outFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+
+	want := []string{"evidence1.pdf", "evidence 100%.pdf", "evidence1.pdf"}
+
+	bms, err := ctx.BookmarksForOutline()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(bms) != len(want) {
+		t.Fatalf("%s: want %d bookmarks, got %d\n", msg, len(want), len(bms))
+	}
+
+	for i, fn := range want {
+		if bms[i].Title != fn || bms[i].PageFrom != 2*i+1 {
+			t.Errorf("%s: bookmark %d: want %s on page %d, got %s on page %d\n", msg, i, fn, 2*i+1, bms[i].Title, bms[i].PageFrom)
+		}
+		for _, pageNr := range []int{2*i + 1, 2*i + 2} {
+			pt, err := ctx.ExtractPageText(pageNr)
+			if err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+			if !strings.Contains(pt.String(), fn) {
+				t.Errorf("%s: page %d: missing stamp %q in:\n%s\n", msg, pageNr, fn, pt)
+			}
+		}
+	}
+}
+
+func TestMergeAttachments(t *testing.T) {
+	msg := "TestMergeAttachments"
+
+	// Two files attaching different notes.txt and the same test.wav.
+	var inFiles []string
+	for i, note := range []string{"first", "second"} {
+		dir := filepath.Join(outDir, "mergeAttachments", strconv.Itoa(i+1))
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		noteFile := filepath.Join(dir, "notes.txt")
+		if err := ioutil.WriteFile(noteFile, []byte(note), os.ModePerm); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		inFile := filepath.Join(dir, "in.pdf")
+		files := []string{noteFile, filepath.Join(resDir, "test.wav")}
+		if err := api.AddAttachmentsFile(filepath.Join(inDir, "go.pdf"), inFile, files, false, nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		inFiles = append(inFiles, inFile)
+	}
+
+	outFile := filepath.Join(outDir, "MergedAttachments.pdf")
+	if err := api.MergeCreateFile(inFiles, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	f, err := os.Open(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+
+	aa, err := api.ExtractAttachmentsRaw(f, "", nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	got := map[string]string{}
+	for _, a := range aa {
+		bb, err := ioutil.ReadAll(a)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		got[a.FileName] = string(bb)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("%s: want 3 attachments, got %d: %v\n", msg, len(got), aa)
+	}
+	if got["notes.txt"] != "first" || got["notes (2).txt"] != "second" {
+		t.Fatalf("%s: unexpected notes: %q, %q\n", msg, got["notes.txt"], got["notes (2).txt"])
+	}
+	if _, ok := got["test.wav"]; !ok {
+		t.Fatalf("%s: missing test.wav\n", msg)
+	}
+}
+
+func TestMergeJavaScript(t *testing.T) {
+	msg := "TestMergeJavaScript"
+
+	withScripts := func(fileName string, scripts map[string]string) string {
+		t.Helper()
+		ctx, err := api.ReadContextFile(filepath.Join(inDir, "go.pdf"))
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if err := ctx.LocateNameTree("JavaScript", true); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		for k, js := range scripts {
+			d := pdfcpu.Dict{"S": pdfcpu.Name("JavaScript"), "JS": pdfcpu.StringLiteral(js)}
+			ir, err := ctx.IndRefForNewObject(d)
+			if err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+			if err := ctx.Names["JavaScript"].Add(ctx.XRefTable, k, *ir); err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+		}
+		outFile := filepath.Join(outDir, fileName)
+		if err := api.WriteContextFile(ctx, outFile); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return outFile
+	}
+
+	inFiles := []string{
+		withScripts("jsA.pdf", map[string]string{"init": "var a = 1;", "shared": "var s = 1;"}),
+		withScripts("jsB.pdf", map[string]string{"init": "var b = 2;", "shared": "var s = 1;"}),
+	}
+	outFile := filepath.Join(outDir, "MergedJavaScript.pdf")
+
+	for _, tt := range []struct {
+		keep bool
+		want []string
+	}{
+		{false, []string{"init", "shared"}},
+		{true, []string{"init", "init (2)", "shared"}},
+	} {
+		conf := pdfcpu.NewDefaultConfiguration()
+		conf.MergeJavaScript = tt.keep
+
+		if err := api.MergeCreateFile(inFiles, outFile, conf); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		ctx, err := api.ReadContextFile(outFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if err := ctx.LocateNameTree("JavaScript", false); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		keys, err := ctx.Names["JavaScript"].KeyList()
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		for i, k := range keys {
+			if keys[i], err = pdfcpu.StringLiteralToString(pdfcpu.StringLiteral(k)); err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+		}
+		if strings.Join(keys, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s keep=%t: want %v, got %v\n", msg, tt.keep, tt.want, keys)
+		}
+	}
+}
+
+func TestMergeStructTrees(t *testing.T) {
+	msg := "TestMergeStructTrees"
+	inFiles := []string{
+		filepath.Join(inDir, "CenterOfWhy.pdf"),
+		filepath.Join(inDir, "Acroforms2.pdf"),
+		filepath.Join(inDir, "CenterOfWhy.pdf"),
+	}
+	outFile := filepath.Join(outDir, "MergeStructTrees.pdf")
+
+	if err := api.MergeCreateFile(inFiles, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Both copies of the tagged input share one structure tree with disjoint parent tree keys.
+	if got, want := checkStructTree(t, outFile), 2*checkStructTree(t, inFiles[0]); got != want {
+		t.Fatalf("%s: want %d parent tree entries, got %d\n", msg, want, got)
+	}
+}
+
+func TestMergeNames(t *testing.T) {
+	msg := "TestMergeNames"
+
+	attach := func(fileName string, ids ...string) string {
+		t.Helper()
+		inFile := filepath.Join(outDir, fileName)
+		if err := testpdf.File(inFile, testpdf.Page{Text: fileName}); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		var files []string
+		for _, id := range ids {
+			fn := filepath.Join(outDir, id)
+			if err := ioutil.WriteFile(fn, []byte(fileName+" "+id), os.ModePerm); err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+			files = append(files, fn)
+		}
+		if err := api.AddAttachmentsFile(inFile, "", files, false, nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return inFile
+	}
+
+	inFiles := []string{
+		attach("namesA.pdf", "a.txt", "shared.txt"),
+		attach("namesB.pdf", "b.txt", "shared.txt"),
+	}
+	outFile := filepath.Join(outDir, "namesMerged.pdf")
+
+	if err := api.MergeCreateFile(inFiles, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Embedded files of all merged files survive, on collision the later ones get renamed.
+	aa, err := api.ListAttachmentsFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(aa) != 4 {
+		t.Fatalf("%s: want 4 attachments, got %v\n", msg, aa)
+	}
+
+	if err := api.ExtractAttachmentsFile(outFile, outDir, []string{"shared.txt", "shared (2).txt"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for fn, want := range map[string]string{"shared.txt": "namesA.pdf shared.txt", "shared (2).txt": "namesB.pdf shared.txt"} {
+		bb, err := ioutil.ReadFile(filepath.Join(outDir, fn))
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if string(bb) != want {
+			t.Fatalf("%s: %s: want %q, got %q\n", msg, fn, want, bb)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func xmpMetadata(t *testing.T, fileName string) string {
+	t.Helper()
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", fileName, err)
+	}
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", fileName, err)
+	}
+	sd, _, err := ctx.DereferenceStreamDict(rootDict["Metadata"])
+	if err != nil || sd == nil {
+		t.Fatalf("%s: missing XMP metadata: %v\n", fileName, err)
+	}
+	if err := sd.Decode(); err != nil {
+		t.Fatalf("%s: %v\n", fileName, err)
+	}
+	return string(sd.Content)
+}
+
+func TestDocumentMetadata(t *testing.T) {
+	msg := "TestDocumentMetadata"
+
+	inFile := filepath.Join(inDir, "test.pdf")
+	outFile := filepath.Join(outDir, "metadata.pdf")
+
+	creationDate := time.Date(2020, 3, 17, 10, 30, 0, 0, time.UTC)
+
+	m := pdfcpu.DocumentMetadata{
+		Title:        "Grüße & more",
+		Author:       "Jane Doe",
+		Keywords:     "pdf, metadata",
+		CreationDate: creationDate,
+	}
+
+	if err := api.SetDocumentMetadataFile(inFile, outFile, m, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	md, err := api.DocumentMetadataFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if md.Title != m.Title || md.Author != m.Author || md.Keywords != m.Keywords {
+		t.Fatalf("%s: want %+v, got %+v\n", msg, m, *md)
+	}
+	if !md.CreationDate.Equal(creationDate) {
+		t.Fatalf("%s: creation date: want %v, got %v\n", msg, creationDate, md.CreationDate)
+	}
+	if !strings.HasPrefix(md.Producer, "pdfcpu") || md.ModDate.IsZero() {
+		t.Fatalf("%s: missing producer or modification date: %+v\n", msg, *md)
+	}
+
+	// The XMP metadata stream mirrors the info dict.
+	s := xmpMetadata(t, outFile)
+	for _, want := range []string{
+		"<dc:title><rdf:Alt><rdf:li xml:lang=\"x-default\">Grüße &amp; more</rdf:li></rdf:Alt></dc:title>",
+		"<dc:creator><rdf:Seq><rdf:li>Jane Doe</rdf:li></rdf:Seq></dc:creator>",
+		"<pdf:Keywords>pdf, metadata</pdf:Keywords>",
+		"<xmp:CreateDate>2020-03-17T10:30:00Z</xmp:CreateDate>",
+		"<pdf:Producer>" + md.Producer + "</pdf:Producer>",
+	} {
+		if !strings.Contains(s, want) {
+			t.Fatalf("%s: missing %s in XMP:\n%s\n", msg, want, s)
+		}
+	}
+
+	// Updates replace existing XMP values.
+	if err := api.SetDocumentMetadataFile(outFile, "", pdfcpu.DocumentMetadata{Title: "New Title"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	s = xmpMetadata(t, outFile)
+	if strings.Count(s, "<dc:title>") != 1 || !strings.Contains(s, "New Title") || !strings.Contains(s, "Jane Doe") {
+		t.Fatalf("%s: unexpected XMP:\n%s\n", msg, s)
+	}
+
+	// Values missing in the info dict are taken from XMP.
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, err := ctx.DereferenceDict(*ctx.Info)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Delete("Title")
+	md, err = ctx.DocumentMetadata()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if md.Title != "New Title" {
+		t.Fatalf("%s: want title from XMP, got %q\n", msg, md.Title)
+	}
+}
+
+func TestDocumentMetadataLocalized(t *testing.T) {
+	msg := "TestDocumentMetadataLocalized"
+
+	inFile := filepath.Join(inDir, "test.pdf")
+	outFile := filepath.Join(outDir, "metadataLocalized.pdf")
+
+	m := pdfcpu.DocumentMetadata{
+		Title:    "文書のタイトル",
+		Author:   "محمد عبد الله",
+		Subject:  "中文主题 (测试)",
+		Keywords: "키워드",
+	}
+
+	if err := api.SetDocumentMetadataFile(inFile, outFile, m, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Non Latin keywords and properties survive a round trip as well.
+	if err := api.AddKeywordsFile(outFile, "", []string{"日本語"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.AddPropertiesFile(outFile, "", map[string]string{"lang": "العربية"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	md, err := api.DocumentMetadataFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if md.Title != m.Title || md.Author != m.Author || md.Subject != m.Subject {
+		t.Fatalf("%s: want %+v, got %+v\n", msg, m, *md)
+	}
+
+	kw, err := api.ListKeywordsFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !strings.Contains(strings.Join(kw, ","), "日本語") {
+		t.Fatalf("%s: keywords: %v\n", msg, kw)
+	}
+
+	pp, err := api.ListPropertiesFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(pp) != 1 || pp[0] != "lang = العربية" {
+		t.Fatalf("%s: properties: %v\n", msg, pp)
+	}
+}
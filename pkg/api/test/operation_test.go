@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/testpdf"
+	"github.com/pkg/errors"
+)
+
+// upsideDown rotates all pages by 180 degrees.
+type upsideDown struct{}
+
+func (upsideDown) Name() string { return "upsideDown" }
+
+func (upsideDown) Validate(conf *pdfcpu.Configuration) error {
+	if conf.ValidationMode == pdfcpu.ValidationNone {
+		return errors.New("upsideDown: validation required")
+	}
+	return nil
+}
+
+func (upsideDown) Execute(ctx *pdfcpu.Context) error {
+	pages := pdfcpu.IntSet{}
+	for i := 1; i <= ctx.PageCount; i++ {
+		pages[i] = true
+	}
+	return pdfcpu.RotatePages(ctx, pages, 180)
+}
+
+func TestRunOperation(t *testing.T) {
+	msg := "TestRunOperation"
+	inFile := filepath.Join(outDir, "operation.pdf")
+	outFile := filepath.Join(outDir, "operationOut.pdf")
+
+	if err := testpdf.File(inFile, testpdf.Page{Text: "Hello"}, testpdf.Page{Text: "World"}); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.RunOperationFile(inFile, outFile, "upsideDown", nil); err == nil {
+		t.Fatalf("%s: want error for unregistered operation\n", msg)
+	}
+
+	pdfcpu.RegisterOperation(upsideDown{})
+	defer pdfcpu.UnregisterOperation("upsideDown")
+
+	if ss := pdfcpu.OperationNames(); len(ss) != 1 || ss[0] != "upsideDown" {
+		t.Fatalf("%s: want [upsideDown], got %v\n", msg, ss)
+	}
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.ValidationMode = pdfcpu.ValidationNone
+	if err := api.RunOperationFile(inFile, outFile, "upsideDown", conf); err == nil {
+		t.Fatalf("%s: want validation error\n", msg)
+	}
+
+	if err := api.RunOperationFile(inFile, outFile, "upsideDown", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for i := 1; i <= ctx.PageCount; i++ {
+		d, _, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if r := d.IntEntry("Rotate"); r == nil || *r != 180 {
+			t.Fatalf("%s: page %d: want rotation 180, got %v\n", msg, i, r)
+		}
+	}
+}
@@ -17,10 +17,14 @@ limitations under the License.
 package test
 
 import (
+	"bytes"
 	"path/filepath"
 	"testing"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/testpdf"
 )
 
 func TestOptimize(t *testing.T) {
@@ -41,3 +45,199 @@ func TestOptimize(t *testing.T) {
 		t.Fatalf("%s: %v\n", msg, err)
 	}
 }
+
+// pageAttrsHoisted returns true if the first page inherits its media box.
+func pageAttrsHoisted(t *testing.T, bb []byte) bool {
+	t.Helper()
+	ctx, err := api.ReadContext(bytes.NewReader(bb), pdfcpu.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, found := d.Find("MediaBox")
+	return !found
+}
+
+func TestOptimizeConsolidateResources(t *testing.T) {
+	msg := "TestOptimizeConsolidateResources"
+
+	bb, err := testpdf.Bytes(testpdf.Pages(3)...)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Commands other than optimize leave the page tree alone.
+	var buf bytes.Buffer
+	if err := api.Rotate(bytes.NewReader(bb), &buf, 90, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if pageAttrsHoisted(t, buf.Bytes()) {
+		t.Fatalf("%s: rotate: page attributes hoisted\n", msg)
+	}
+
+	// So does optimize by default.
+	buf.Reset()
+	if err := api.Optimize(bytes.NewReader(bb), &buf, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if pageAttrsHoisted(t, buf.Bytes()) {
+		t.Fatalf("%s: optimize: page attributes hoisted\n", msg)
+	}
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.ConsolidateResources = true
+	buf.Reset()
+	if err := api.Optimize(bytes.NewReader(bb), &buf, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !pageAttrsHoisted(t, buf.Bytes()) {
+		t.Fatalf("%s: optimize: page attributes not hoisted\n", msg)
+	}
+}
+
+// pageContentStream returns the content stream of the first page.
+func pageContentStream(t *testing.T, ctx *pdfcpu.Context) *pdfcpu.StreamDict {
+	t.Helper()
+	d, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ir, ok := d["Contents"].(pdfcpu.IndirectRef)
+	if !ok {
+		t.Fatal("missing page content")
+	}
+	sd, ok := ctx.Table[ir.ObjectNumber.Value()].Object.(pdfcpu.StreamDict)
+	if !ok {
+		t.Fatal("missing page content stream")
+	}
+	if err := sd.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	return &sd
+}
+
+func TestOptimizeLZW(t *testing.T) {
+	msg := "TestOptimizeLZW"
+
+	bb, err := testpdf.Bytes(testpdf.Page{Text: "LZW"})
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContext(bytes.NewReader(bb), pdfcpu.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Re-encode the page content using LZW.
+	d, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	objNr := d["Contents"].(pdfcpu.IndirectRef).ObjectNumber.Value()
+	sd := pageContentStream(t, ctx)
+	want := sd.Content
+	parms := pdfcpu.Dict{"EarlyChange": pdfcpu.Integer(0)}
+	sd.Update("Filter", pdfcpu.Name(filter.LZW))
+	sd.Update("DecodeParms", parms)
+	sd.FilterPipeline = []pdfcpu.PDFFilter{{Name: filter.LZW, DecodeParms: parms}}
+	if err := sd.Encode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ctx.Table[objNr].Object = *sd
+
+	var buf bytes.Buffer
+	if err := api.WriteContext(ctx, &buf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Optimize rewrites LZW streams as Flate streams.
+	var buf1 bytes.Buffer
+	if err := api.Optimize(bytes.NewReader(buf.Bytes()), &buf1, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err = api.ReadContext(bytes.NewReader(buf1.Bytes()), pdfcpu.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd = pageContentStream(t, ctx)
+	if len(sd.FilterPipeline) != 1 || sd.FilterPipeline[0].Name != filter.Flate || sd.Dict["DecodeParms"] != nil {
+		t.Fatalf("%s: want FlateDecode, got %v\n", msg, sd.Dict)
+	}
+	if !bytes.Equal(sd.Content, want) {
+		t.Fatalf("%s: page content changed\n", msg)
+	}
+}
+
+func TestOptimizeCompressStreams(t *testing.T) {
+	msg := "TestOptimizeCompressStreams"
+
+	bb, err := testpdf.Bytes(testpdf.Page{Text: "Compress"})
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContext(bytes.NewReader(bb), pdfcpu.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Store a compressible page content uncompressed.
+	d, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	objNr := d["Contents"].(pdfcpu.IndirectRef).ObjectNumber.Value()
+	sd := pageContentStream(t, ctx)
+	want := bytes.Repeat(sd.Content, 50)
+	sd.Content = want
+	sd.Delete("Filter")
+	sd.Delete("DecodeParms")
+	sd.FilterPipeline = nil
+	if err := sd.Encode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ctx.Table[objNr].Object = *sd
+
+	var buf bytes.Buffer
+	if err := api.WriteContext(ctx, &buf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	optimize := func(conf *pdfcpu.Configuration) (*pdfcpu.StreamDict, *pdfcpu.OptimizationStats) {
+		t.Helper()
+		var buf1 bytes.Buffer
+		stats, err := api.OptimizeWithStats(bytes.NewReader(buf.Bytes()), &buf1, conf)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		ctx, err := api.ReadContext(bytes.NewReader(buf1.Bytes()), pdfcpu.NewDefaultConfiguration())
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return pageContentStream(t, ctx), stats
+	}
+
+	// By default uncompressed streams stay untouched.
+	sd, _ = optimize(nil)
+	if len(sd.FilterPipeline) != 0 {
+		t.Fatalf("%s: want no filter, got %v\n", msg, sd.Dict)
+	}
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.CompressStreams = true
+	sd, stats := optimize(conf)
+	if len(sd.FilterPipeline) != 1 || sd.FilterPipeline[0].Name != filter.Flate {
+		t.Fatalf("%s: want FlateDecode, got %v\n", msg, sd.Dict)
+	}
+	if !bytes.Equal(sd.Content, want) {
+		t.Fatalf("%s: page content changed\n", msg)
+	}
+	if stats.Other <= 0 || stats.Total() != stats.Fonts+stats.Images+stats.Other {
+		t.Fatalf("%s: unexpected stats: %s\n", msg, stats)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+// appendOrphan appends an incremental update to bb adding an unreferenced font dict and returns its object number.
+func appendOrphan(t *testing.T, msg string, bb []byte) ([]byte, int) {
+	t.Helper()
+
+	ctx, err := api.ReadContext(bytes.NewReader(bb), nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	m := regexp.MustCompile(`startxref\s+(\d+)`).FindAllSubmatch(bb, -1)
+	prev, err := strconv.Atoi(string(m[len(m)-1][1]))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	objNr := *ctx.Size
+	off := len(bb)
+	obj := fmt.Sprintf("\n%d 0 obj\n<</Type/Font/Subtype/Type1/BaseFont/Helvetica>>\nendobj\n", objNr)
+	xref := fmt.Sprintf("xref\n0 1\n0000000000 65535 f \n%d 1\n%010d 00000 n \ntrailer\n<</Size %d/Root %s/Prev %d>>\nstartxref\n%d\n%%%%EOF\n",
+		objNr, off+1, objNr+1, ctx.Root, prev, off+len(obj))
+
+	return append(bb, []byte(obj+xref)...), objNr
+}
+
+func TestOrphanedObjects(t *testing.T) {
+	msg := "TestOrphanedObjects"
+
+	bb, err := ioutil.ReadFile(filepath.Join(inDir, "go.pdf"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	oo, err := api.OrphanedObjects(bytes.NewReader(bb), nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(oo) > 0 {
+		t.Fatalf("%s: want no orphans, got %v\n", msg, oo)
+	}
+
+	bb, objNr := appendOrphan(t, msg, bb)
+
+	oo, err = api.OrphanedObjects(bytes.NewReader(bb), nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(oo) != 1 || oo[0].ObjNr != objNr || oo[0].Type != "Font/Type1" || oo[0].Size == 0 {
+		t.Fatalf("%s: want orphaned font obj#%d, got %v\n", msg, objNr, oo)
+	}
+
+	// Orphans get dropped on write.
+	var buf bytes.Buffer
+	if err := api.Optimize(bytes.NewReader(bb), &buf, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if oo, err = api.OrphanedObjects(bytes.NewReader(buf.Bytes()), nil); err != nil || len(oo) > 0 {
+		t.Fatalf("%s: want no orphans after write, got %v %v\n", msg, oo, err)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/testpdf"
+)
+
+func containsObjNr(objNrs []int, objNr int) bool {
+	for _, i := range objNrs {
+		if i == objNr {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPageObjects(t *testing.T) {
+	msg := "TestPageObjects"
+
+	ctx, err := testpdf.Context(testpdf.Pages(2)...)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	pageDict := func(pageNr int) (pdfcpu.Dict, int) {
+		t.Helper()
+		d, ir, _, err := ctx.PageDict(pageNr, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return d, ir.ObjectNumber.Value()
+	}
+
+	fontRef := func(d pdfcpu.Dict) pdfcpu.IndirectRef {
+		t.Helper()
+		fontDict := d.DictEntry("Resources").DictEntry("Font")
+		for _, o := range fontDict {
+			return o.(pdfcpu.IndirectRef)
+		}
+		t.Fatalf("%s: missing font\n", msg)
+		return pdfcpu.IndirectRef{}
+	}
+
+	d1, objNr1 := pageDict(1)
+	d2, objNr2 := pageDict(2)
+	contents1 := d1.IndirectRefEntry("Contents").ObjectNumber.Value()
+	font1 := fontRef(d1)
+
+	// Let page 2 share the font of page 1.
+	fontDict2 := d2.DictEntry("Resources").DictEntry("Font")
+	for k := range fontDict2 {
+		fontDict2[k] = font1
+	}
+
+	po, err := ctx.PageObjects(1)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !containsObjNr(po.Exclusive, objNr1) || !containsObjNr(po.Exclusive, contents1) {
+		t.Fatalf("%s: page dict and content must be exclusive: %v\n", msg, po.Exclusive)
+	}
+	if containsObjNr(po.Exclusive, objNr2) || containsObjNr(po.Shared, objNr2) {
+		t.Fatalf("%s: other page reachable: %v\n", msg, po)
+	}
+	if len(po.Shared) != 1 || po.Shared[0] != font1.ObjectNumber.Value() {
+		t.Fatalf("%s: want shared font obj#%d, got %v\n", msg, font1.ObjectNumber, po.Shared)
+	}
+
+	po, err = ctx.PageObjects(2)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(po.Exclusive) != 2 || !containsObjNr(po.Exclusive, objNr2) {
+		t.Fatalf("%s: want page dict and content exclusive, got %v\n", msg, po.Exclusive)
+	}
+
+	if _, err := ctx.PageObjects(3); err == nil {
+		t.Fatalf("%s: want error for invalid page\n", msg)
+	}
+}
+
+func TestPageObjectsFormFields(t *testing.T) {
+	msg := "TestPageObjectsFormFields"
+	inFile := createAcroFormDemo(t)
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	po, err := ctx.PageObjects(1)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(po.Exclusive) == 0 {
+		t.Fatalf("%s: missing exclusive objects\n", msg)
+	}
+
+	// The form fields are reachable from the catalog too.
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, err := ctx.DereferenceDict(rootDict["AcroForm"])
+	if err != nil || d == nil {
+		t.Fatalf("%s: missing form: %v\n", msg, err)
+	}
+	fields, err := ctx.DereferenceArray(d["Fields"])
+	if err != nil || len(fields) == 0 {
+		t.Fatalf("%s: missing form fields\n", msg)
+	}
+	for _, o := range fields {
+		ir := o.(pdfcpu.IndirectRef)
+		if containsObjNr(po.Exclusive, ir.ObjectNumber.Value()) {
+			t.Fatalf("%s: field obj#%d must not be exclusive\n", msg, ir.ObjectNumber)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/testpdf"
+)
+
+func checkPageSizeReport(t *testing.T, msg string, r *pdfcpu.PageSizeReport) {
+	t.Helper()
+	sum := r.Shared + r.Document
+	for _, ps := range r.Pages {
+		if ps.Exclusive <= 0 {
+			t.Fatalf("%s: page %d: missing exclusive bytes\n", msg, ps.PageNr)
+		}
+		sum += ps.Exclusive
+	}
+	if sum != r.Total {
+		t.Fatalf("%s: sizes don't add up: %d != %d\n", msg, sum, r.Total)
+	}
+}
+
+func TestPageSizes(t *testing.T) {
+	msg := "TestPageSizes"
+
+	ctx, err := testpdf.Context(testpdf.Pages(3)...)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	d1, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, _, err := ctx.PageDict(2, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Let page 2 share the fonts of page 1.
+	d.DictEntry("Resources")["Font"] = d1.DictEntry("Resources").DictEntry("Font")
+
+	// Blow up the content stream of page 2.
+	ir := d.IndirectRefEntry("Contents")
+	entry, _ := ctx.FindTableEntryForIndRef(ir)
+	sd := entry.Object.(pdfcpu.StreamDict)
+	sd.Raw = make([]byte, 100000)
+	l := int64(len(sd.Raw))
+	sd.StreamLength = &l
+	entry.Object = sd
+
+	r, err := ctx.PageSizes()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(r.Pages) != 3 {
+		t.Fatalf("%s: want 3 pages, got %d\n", msg, len(r.Pages))
+	}
+	checkPageSizeReport(t, msg, r)
+
+	if ps := r.Largest(); ps.PageNr != 2 || ps.Exclusive < l {
+		t.Fatalf("%s: want page 2 largest, got %+v\n", msg, *ps)
+	}
+
+	if r.Shared == 0 || r.Pages[0].Shared != r.Shared || r.Pages[1].Shared != r.Shared || r.Pages[2].Shared != 0 {
+		t.Fatalf("%s: missing shared font: %+v\n", msg, *r)
+	}
+
+	// Page selection.
+	inFile := filepath.Join(inDir, "TheGoProgrammingLanguageCh1.pdf")
+	r, err = api.PageSizesFile(inFile, []string{"2-3"}, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(r.Pages) != 2 || r.Pages[0].PageNr != 2 {
+		t.Fatalf("%s: want pages 2-3, got %+v\n", msg, r.Pages)
+	}
+	if r.Total == 0 {
+		t.Fatalf("%s: missing total\n", msg)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// blankRasterizer renders a blank image sized according to the media box of a page.
+func blankRasterizer(ctx *pdfcpu.Context, pageNr, dpi int) (image.Image, error) {
+	if !ctx.Read.Pages[pageNr] {
+		return nil, errors.Errorf("page %d not loaded", pageNr)
+	}
+	dims, err := ctx.PageDims()
+	if err != nil {
+		return nil, err
+	}
+	dim := dims[pageNr-1]
+	w, h := int(dim.Width*float64(dpi)/72), int(dim.Height*float64(dpi)/72)
+	return image.NewGray(image.Rect(0, 0, w, h)), nil
+}
+
+func TestPreview(t *testing.T) {
+	msg := "TestPreview"
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+
+	f, err := os.Open(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+
+	if _, err := api.Preview(f, 1, 72, nil); err != pdfcpu.ErrNoRasterizer {
+		t.Fatalf("%s: want %v, got %v\n", msg, pdfcpu.ErrNoRasterizer, err)
+	}
+
+	pdfcpu.RegisterRasterizer(blankRasterizer)
+	defer pdfcpu.RegisterRasterizer(nil)
+
+	img, err := api.Preview(f, 1, 144, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if img.Bounds().Dx() == 0 || img.Bounds().Dy() == 0 {
+		t.Fatalf("%s: empty image: %v\n", msg, img.Bounds())
+	}
+
+	if _, err := api.Preview(f, 1000, 72, nil); err == nil {
+		t.Fatalf("%s: want error for invalid page number\n", msg)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func loadedObjects(ctx *pdfcpu.Context) int {
+	c := 0
+	for _, e := range ctx.Table {
+		if !e.Free && e.Object != nil {
+			c++
+		}
+	}
+	return c
+}
+
+func TestReadContextPages(t *testing.T) {
+	msg := "TestReadContextPages"
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+
+	f, err := os.Open(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.Cmd = pdfcpu.TRIM
+
+	ctx, err := api.ReadContextPages(f, []string{"1"}, conf)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctxFull, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if ctx.PageCount != ctxFull.PageCount {
+		t.Fatalf("%s: pageCount: want %d, got %d\n", msg, ctxFull.PageCount, ctx.PageCount)
+	}
+	if len(ctx.Read.Pages) != 1 || !ctx.Read.Pages[1] {
+		t.Fatalf("%s: loaded pages: want [1], got %v\n", msg, ctx.Read.Pages)
+	}
+	if n, nFull := loadedObjects(ctx), loadedObjects(ctxFull); n >= nFull {
+		t.Fatalf("%s: %d objects loaded, full read: %d\n", msg, n, nFull)
+	}
+
+	d, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	bb, err := ctx.PageContent(d)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, _, err = ctxFull.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	bbFull, err := ctxFull.PageContent(d)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.Equal(bb, bbFull) {
+		t.Fatalf("%s: page content differs from full read\n", msg)
+	}
+
+	// Write the loaded page as a single page document.
+	var buf bytes.Buffer
+	ctx.Write.SelectedPages = ctx.Read.Pages
+	if err := api.WriteContext(ctx, &buf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.Validate(bytes.NewReader(buf.Bytes()), nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	n, err := api.PageCount(bytes.NewReader(buf.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if n != 1 {
+		t.Fatalf("%s: pageCount: want 1, got %d\n", msg, n)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/testpdf"
+)
+
+func readRepaired(t *testing.T, msg string, bb []byte, conf *pdfcpu.Configuration, pageCount int) *pdfcpu.Context {
+	t.Helper()
+
+	ctx, err := api.ReadContext(bytes.NewReader(bb), conf)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !ctx.Read.Repaired {
+		t.Fatalf("%s: xref table not rebuilt\n", msg)
+	}
+	if err := api.ValidateContext(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ctx.PageCount != pageCount {
+		t.Fatalf("%s: want %d pages, got %d\n", msg, pageCount, ctx.PageCount)
+	}
+
+	// A repaired file can be written and read back.
+	var buf bytes.Buffer
+	if err := api.WriteContext(ctx, &buf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if _, err := api.ReadContext(bytes.NewReader(buf.Bytes()), pdfcpu.NewDefaultConfiguration()); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	return ctx
+}
+
+func TestRepairXRefTable(t *testing.T) {
+	msg := "TestRepairXRefTable"
+
+	bb, err := ioutil.ReadFile(filepath.Join(inDir, "read.go.pdf"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContext(bytes.NewReader(bb), pdfcpu.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateContext(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	pageCount := ctx.PageCount
+
+	// Missing xref section and trailer.
+	i := bytes.LastIndex(bb, []byte("xref"))
+	corrupt := append(bb[:i:i], []byte("%%EOF\n")...)
+	ctx = readRepaired(t, msg+" missing xref", corrupt, pdfcpu.NewDefaultConfiguration(), pageCount)
+	if len(ctx.Warnings) == 0 || !strings.Contains(ctx.Warnings[0], "corrupt xref table") {
+		t.Fatalf("%s: missing warning, got %v\n", msg, ctx.Warnings)
+	}
+
+	// Truncated trailer.
+	i = bytes.LastIndex(bb, []byte("/Root"))
+	readRepaired(t, msg+" truncated trailer", bb[:i:i], pdfcpu.NewDefaultConfiguration(), pageCount)
+
+	// Intact file, forced repair.
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.Repair = true
+	ctx = readRepaired(t, msg+" forced", bb, conf, pageCount)
+	if len(ctx.Warnings) > 0 {
+		t.Fatalf("%s: unexpected warnings: %v\n", msg, ctx.Warnings)
+	}
+}
+
+func TestRepairObjectStreams(t *testing.T) {
+	msg := "TestRepairObjectStreams"
+
+	// Written using object streams and an xref stream.
+	bb, err := testpdf.Bytes(testpdf.Pages(3)...)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Point startxref to garbage.
+	i := bytes.LastIndex(bb, []byte("startxref"))
+	corrupt := append(bb[:i:i], []byte("startxref\n42\n%%EOF\n")...)
+	readRepaired(t, msg, corrupt, pdfcpu.NewDefaultConfiguration(), 3)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func headerVersion(t *testing.T, bb []byte) string {
+	t.Helper()
+	if !bytes.HasPrefix(bb, []byte("%PDF-")) || len(bb) < 8 {
+		t.Fatalf("missing header")
+	}
+	return string(bb[5:8])
+}
+
+func TestRequiredVersion(t *testing.T) {
+	msg := "TestRequiredVersion"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+
+	v, ff, err := api.RequiredVersionFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if v < pdfcpu.V12 || len(ff) == 0 || ff[0].Version != v {
+		t.Fatalf("%s: unexpected result: %s %v\n", msg, v, ff)
+	}
+	t.Logf("%s: %s %v", msg, v, ff)
+
+	bb, err := os.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// auto writes the minimum version including object streams.
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.TargetVersion = "auto"
+	var buf bytes.Buffer
+	if err := api.Optimize(bytes.NewReader(bb), &buf, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	want := v
+	if want < pdfcpu.V15 {
+		want = pdfcpu.V15
+	}
+	if got := headerVersion(t, buf.Bytes()); got != want.String() {
+		t.Fatalf("%s auto: got %s, want %s\n", msg, got, want)
+	}
+
+	// Writing the required version falls back to a classic xref table below PDF 1.5.
+	conf = pdfcpu.NewDefaultConfiguration()
+	conf.TargetVersion = v.String()
+	buf.Reset()
+	if err := api.Optimize(bytes.NewReader(bb), &buf, conf); err != nil {
+		t.Fatalf("%s %s: %v\n", msg, v, err)
+	}
+	if got := headerVersion(t, buf.Bytes()); got != v.String() {
+		t.Fatalf("%s: got %s, want %s\n", msg, got, v)
+	}
+	if v < pdfcpu.V15 && bytes.Contains(buf.Bytes(), []byte("/XRef")) {
+		t.Fatalf("%s %s: unexpected xref stream\n", msg, v)
+	}
+	if err := api.Validate(bytes.NewReader(buf.Bytes()), nil); err != nil {
+		t.Fatalf("%s %s: %v\n", msg, v, err)
+	}
+
+	// A version below the required one can't express the content.
+	conf = pdfcpu.NewDefaultConfiguration()
+	conf.TargetVersion = "1.0"
+	buf.Reset()
+	if err := api.Optimize(bytes.NewReader(bb), &buf, conf); err == nil {
+		t.Fatalf("%s 1.0: missing error\n", msg)
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestResize(t *testing.T) {
+	msg := "TestResize"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "resize.pdf")
+
+	dims, err := api.PageDimsFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	a5 := *pdfcpu.PaperSize["A5"]
+	letter := *pdfcpu.PaperSize["Letter"]
+
+	for _, tt := range []struct {
+		desc          string
+		selectedPages []string
+		w, h          float64 // Dimensions of page 1.
+	}{
+		// The target size follows the orientation of each page.
+		{"form:A5", nil, a5.Width, a5.Height},
+		{"form:A5L", nil, a5.Width, a5.Height},
+		// The target size is used as is.
+		{"form:LetterL, enforce:on", []string{"1"}, letter.Height, letter.Width},
+		{"dim:300 300", nil, 300, 300},
+	} {
+		res, err := api.ResizeConfig(tt.desc)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.desc, err)
+		}
+
+		if err := api.ResizeFile(inFile, outFile, tt.selectedPages, res, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.desc, err)
+		}
+
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.desc, err)
+		}
+
+		dd, err := api.PageDimsFile(outFile)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.desc, err)
+		}
+		if len(dd) != len(dims) {
+			t.Fatalf("%s %s: got %d pages, want %d\n", msg, tt.desc, len(dd), len(dims))
+		}
+
+		w, h := tt.w, tt.h
+		if !res.EnforceOrient && dims[0].Landscape() {
+			w, h = h, w
+		}
+		if math.Abs(dd[0].Width-w) > .01 || math.Abs(dd[0].Height-h) > .01 {
+			t.Fatalf("%s %s: got %.2fx%.2f, want %.2fx%.2f\n", msg, tt.desc, dd[0].Width, dd[0].Height, w, h)
+		}
+
+		// Unselected pages remain untouched.
+		if tt.selectedPages != nil {
+			for i := 1; i < len(dd); i++ {
+				if dd[i] != dims[i] {
+					t.Fatalf("%s %s: page %d: got %v, want %v\n", msg, tt.desc, i+1, dd[i], dims[i])
+				}
+			}
+		}
+	}
+}
@@ -17,10 +17,14 @@ limitations under the License.
 package test
 
 import (
+	"bytes"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 )
 
 func TestRotate(t *testing.T) {
@@ -41,3 +45,96 @@ func TestRotate(t *testing.T) {
 		t.Fatalf("%s: %v\n", msg, err)
 	}
 }
+
+func TestRotateInPlaceBackup(t *testing.T) {
+	msg := "TestRotateInPlaceBackup"
+	inFile := filepath.Join(outDir, "RotateInPlace.pdf")
+	if err := copyFile(t, filepath.Join(inDir, "Acroforms2.pdf"), inFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := os.Chmod(inFile, 0600); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	bb, err := ioutil.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Rotate inFile in place and keep the original as inFile.bak.
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.BackupInPlace = true
+	if err := api.RotateFile(inFile, inFile, 90, nil, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	bak, err := ioutil.ReadFile(inFile + ".bak")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.Equal(bb, bak) {
+		t.Fatalf("%s: backup differs from original\n", msg)
+	}
+
+	fi, err := os.Stat(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Fatalf("%s: want mode 0600, got %v\n", msg, fi.Mode().Perm())
+	}
+
+	if _, err := os.Stat(inFile + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("%s: temp file left behind\n", msg)
+	}
+
+	// A failing operation leaves inFile untouched.
+	bb, err = ioutil.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.RotateFile(inFile, "", 45, nil, nil); err == nil {
+		t.Fatalf("%s: want error for invalid rotation\n", msg)
+	}
+	bb1, err := ioutil.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.Equal(bb, bb1) {
+		t.Fatalf("%s: failed operation modified %s\n", msg, inFile)
+	}
+}
+
+func TestRotateContent(t *testing.T) {
+	msg := "TestRotateContent"
+	fileName := "Acroforms2.pdf"
+	inFile := filepath.Join(inDir, fileName)
+	outFile := filepath.Join(outDir, "RotateContent.pdf")
+
+	dims, err := api.PageDimsFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Rotate the content of all pages of inFile clockwise by 90 degrees and write the result to outFile.
+	// Unlike api.RotateFile this rewrites the page content and swaps page width and height.
+	if err := api.RotateContentFile(inFile, outFile, 90, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	dims1, err := api.PageDimsFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for i, d := range dims {
+		if d.Width != dims1[i].Height || d.Height != dims1[i].Width {
+			t.Fatalf("%s: page %d: want %s, got %s\n", msg, i+1, pdfcpu.Dim{Width: d.Height, Height: d.Width}, dims1[i])
+		}
+	}
+
+	// Rotate the content of the first page by 180 degrees.
+	if err := api.RotateContentFile(outFile, "", 180, []string{"1"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
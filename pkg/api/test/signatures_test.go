@@ -0,0 +1,445 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// Minimal CMS structures for producing a detached signature, see RFC 5652.
+
+type testAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+type testIssuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+type testSignerInfo struct {
+	Version            int
+	SID                testIssuerAndSerial
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+}
+
+type testSignedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo struct{ ContentType asn1.ObjectIdentifier }
+	Certificates     asn1.RawValue
+	SignerInfos      []testSignerInfo `asn1:"set"`
+}
+
+type testContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue
+}
+
+const sigContentsLen = 8192
+
+func testSigner(t *testing.T) (*rsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	return testSignerValidFor(t, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+}
+
+// testSignerValidFor returns a self signed signer certificate valid from notBefore until notAfter.
+func testSignerValidFor(t *testing.T, notBefore, notAfter time.Time) (*rsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "pdfcpu test signer"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key, cert
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	b, err := asn1.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// cmsSignature returns a DER encoded detached CMS signature of digest claiming signingTime.
+func cmsSignature(t *testing.T, key *rsa.PrivateKey, cert *x509.Certificate, digest []byte, signingTime time.Time) []byte {
+	t.Helper()
+
+	oidData := asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData := asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidSHA256 := asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidRSA := asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+
+	attrs := []testAttribute{
+		{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}, []asn1.RawValue{{FullBytes: mustMarshal(t, oidData)}}},
+		{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}, []asn1.RawValue{{FullBytes: mustMarshal(t, digest)}}},
+		{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}, []asn1.RawValue{{FullBytes: mustMarshal(t, signingTime.UTC())}}},
+	}
+
+	signedAttrs, err := asn1.MarshalWithParams(attrs, "set")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := sha256.Sum256(signedAttrs)
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, h[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Signed attributes are implicitly tagged [0].
+	signedAttrs[0] = 0xA0
+
+	sd := testSignedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: oidSHA256}},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: cert.Raw},
+		SignerInfos: []testSignerInfo{{
+			Version:            1,
+			SID:                testIssuerAndSerial{Issuer: asn1.RawValue{FullBytes: cert.RawIssuer}, Serial: cert.SerialNumber},
+			DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+			SignedAttrs:        asn1.RawValue{FullBytes: signedAttrs},
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidRSA},
+			Signature:          sig,
+		}},
+	}
+	sd.EncapContentInfo.ContentType = oidData
+
+	return mustMarshal(t, testContentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: mustMarshal(t, sd)},
+	})
+}
+
+// signPDF returns inFile carrying a detached signature in the signature field "Signature1".
+func signPDF(t *testing.T, inFile string, key *rsa.PrivateKey, cert *x509.Certificate) []byte {
+	t.Helper()
+	return signPDFAt(t, inFile, key, cert, time.Now())
+}
+
+// signPDFAt returns inFile carrying a detached signature claiming signingTime in the signature field "Signature1".
+func signPDFAt(t *testing.T, inFile string, key *rsa.PrivateKey, cert *x509.Certificate, signingTime time.Time) []byte {
+	t.Helper()
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigDict := pdfcpu.Dict(map[string]pdfcpu.Object{
+		"Type":      pdfcpu.Name("Sig"),
+		"Filter":    pdfcpu.Name("Adobe.PPKLite"),
+		"SubFilter": pdfcpu.Name("adbe.pkcs7.detached"),
+		"ByteRange": pdfcpu.NewIntegerArray(0, 1000000000, 1000000000, 1000000000),
+		"Contents":  pdfcpu.HexLiteral(strings.Repeat("0", sigContentsLen)),
+		"Reason":    pdfcpu.StringLiteral("Testing"),
+	})
+	sigIndRef, err := ctx.IndRefForNewObject(sigDict)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fieldDict := pdfcpu.Dict(map[string]pdfcpu.Object{
+		"FT":      pdfcpu.Name("Sig"),
+		"T":       pdfcpu.StringLiteral("Signature1"),
+		"V":       *sigIndRef,
+		"Type":    pdfcpu.Name("Annot"),
+		"Subtype": pdfcpu.Name("Widget"),
+		"Rect":    pdfcpu.NewIntegerArray(0, 0, 0, 0),
+	})
+	fieldIndRef, err := ctx.IndRefForNewObject(fieldDict)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootDict["AcroForm"] = pdfcpu.Dict(map[string]pdfcpu.Object{
+		"Fields":   pdfcpu.Array{*fieldIndRef},
+		"SigFlags": pdfcpu.Integer(3),
+	})
+
+	// The signature dict must not end up in an object stream.
+	ctx.WriteObjectStream = false
+
+	var buf bytes.Buffer
+	if err := api.WriteContext(ctx, &buf); err != nil {
+		t.Fatal(err)
+	}
+	bb := buf.Bytes()
+
+	// Fill in the byte range excluding the /Contents hex string.
+	i := bytes.Index(bb, []byte("<"+strings.Repeat("0", sigContentsLen)+">"))
+	j := i + sigContentsLen + 2
+	k := bytes.Index(bb, []byte("[0 1000000000 1000000000 1000000000]"))
+	if i < 0 || k < 0 {
+		t.Fatal("signature placeholders not found")
+	}
+	br := fmt.Sprintf("[0 %d %d %d]", i, j, len(bb)-j)
+	br += strings.Repeat(" ", 36-len(br))
+	copy(bb[k:], br)
+
+	digest := sha256.New()
+	digest.Write(bb[:i])
+	digest.Write(bb[j:])
+
+	s := hex.EncodeToString(cmsSignature(t, key, cert, digest.Sum(nil), signingTime))
+	if len(s) > sigContentsLen {
+		t.Fatal("signature too large")
+	}
+	copy(bb[i+1:], s)
+
+	return bb
+}
+
+func TestValidateSignatures(t *testing.T) {
+	msg := "TestValidateSignatures"
+
+	key, cert := testSigner(t)
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	bb := signPDF(t, filepath.Join(inDir, "test.pdf"), key, cert)
+
+	rr, err := api.ValidateSignatures(bytes.NewReader(bb), roots, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(rr) != 1 {
+		t.Fatalf("%s: want 1 signature, got %d\n", msg, len(rr))
+	}
+	r := rr[0]
+	if !r.Valid() || r.ModifiedAfterSigning || r.Field != "Signature1" || r.Reason != "Testing" ||
+		!strings.Contains(r.Signer, "pdfcpu test signer") || r.SigningTime.IsZero() {
+		t.Fatalf("%s: unexpected result: %+v\n", msg, r)
+	}
+
+	// The self signed certificate is unknown to the system trust store.
+	rr, err = api.ValidateSignatures(bytes.NewReader(bb), nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if r := rr[0]; r.Trusted || !r.DigestValid || !r.SignatureValid {
+		t.Fatalf("%s: unexpected result: %+v\n", msg, r)
+	}
+
+	// An incremental update after signing keeps the signature intact.
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.Incremental = true
+	var buf bytes.Buffer
+	if err := api.AddKeywords(bytes.NewReader(bb), &buf, []string{"signed"}, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rr, err = api.ValidateSignatures(bytes.NewReader(buf.Bytes()), roots, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if r := rr[0]; !r.Valid() || !r.ModifiedAfterSigning || r.SignedBytes != int64(len(bb)) || len(r.ChangedObjects) == 0 {
+		t.Fatalf("%s: unexpected result after incremental update: %+v\n", msg, r)
+	}
+
+	// Any change of the signed bytes breaks the signature.
+	bb[len(bb)-1] = ' '
+	rr, err = api.ValidateSignatures(bytes.NewReader(bb), roots, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if r := rr[0]; r.DigestValid || r.Valid() {
+		t.Fatalf("%s: tampered document passes: %+v\n", msg, r)
+	}
+}
+
+func TestValidateSignaturesExpiredCertificate(t *testing.T) {
+	msg := "TestValidateSignaturesExpiredCertificate"
+
+	// The signer certificate expired a day ago, the signature claims to predate the expiry.
+	key, cert := testSignerValidFor(t, time.Now().Add(-48*time.Hour), time.Now().Add(-24*time.Hour))
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	bb := signPDFAt(t, filepath.Join(inDir, "test.pdf"), key, cert, time.Now().Add(-36*time.Hour))
+
+	rr, err := api.ValidateSignatures(bytes.NewReader(bb), roots, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(rr) != 1 {
+		t.Fatalf("%s: want 1 signature, got %d\n", msg, len(rr))
+	}
+	if r := rr[0]; r.Trusted || r.Valid() || !r.DigestValid || !r.SignatureValid {
+		t.Fatalf("%s: backdated signature trusted: %+v\n", msg, r)
+	}
+}
+
+func TestSignaturePolicy(t *testing.T) {
+	msg := "TestSignaturePolicy"
+
+	key, cert := testSigner(t)
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	bb := signPDF(t, filepath.Join(inDir, "test.pdf"), key, cert)
+
+	newConf := func(cmd pdfcpu.CommandMode, policy int) *pdfcpu.Configuration {
+		conf := pdfcpu.NewDefaultConfiguration()
+		conf.Cmd = cmd
+		conf.SignaturePolicy = policy
+		return conf
+	}
+
+	rotate := func(policy int) error {
+		var buf bytes.Buffer
+		return api.Rotate(bytes.NewReader(bb), &buf, 90, nil, newConf(pdfcpu.ROTATE, policy))
+	}
+
+	annotate := func(conf *pdfcpu.Configuration) ([]byte, error) {
+		var buf bytes.Buffer
+		err := api.AddAnnotations(bytes.NewReader(bb), &buf, nil, textAnn, conf)
+		return buf.Bytes(), err
+	}
+
+	// By default signed documents get modified.
+	if err := rotate(pdfcpu.SignaturePolicyWarn); err != nil {
+		t.Fatalf("%s warn: %v\n", msg, err)
+	}
+
+	if err := rotate(pdfcpu.SignaturePolicyRefuse); err != pdfcpu.ErrSigned {
+		t.Fatalf("%s refuse: want %v, got %v\n", msg, pdfcpu.ErrSigned, err)
+	}
+	if _, err := annotate(newConf(pdfcpu.ADDANNOTATIONS, pdfcpu.SignaturePolicyRefuse)); err != pdfcpu.ErrSigned {
+		t.Fatalf("%s refuse: want %v, got %v\n", msg, pdfcpu.ErrSigned, err)
+	}
+
+	// Incremental updates leave the signed bytes untouched.
+	conf := newConf(pdfcpu.ADDANNOTATIONS, pdfcpu.SignaturePolicyRefuse)
+	conf.Incremental = true
+	if _, err := annotate(conf); err != nil {
+		t.Fatalf("%s refuse incremental: %v\n", msg, err)
+	}
+
+	// Read only commands always pass.
+	conf = newConf(pdfcpu.LISTANNOTATIONS, pdfcpu.SignaturePolicyRefuse)
+	if _, _, err := api.ListAnnotations(bytes.NewReader(bb), nil, conf); err != nil {
+		t.Fatalf("%s refuse list: %v\n", msg, err)
+	}
+
+	if err := rotate(pdfcpu.SignaturePolicyIncremental); err != pdfcpu.ErrSigned {
+		t.Fatalf("%s incremental: want %v, got %v\n", msg, pdfcpu.ErrSigned, err)
+	}
+
+	// Signature preserving commands switch to incremental update mode.
+	bb1, err := annotate(newConf(pdfcpu.ADDANNOTATIONS, pdfcpu.SignaturePolicyIncremental))
+	if err != nil {
+		t.Fatalf("%s incremental: %v\n", msg, err)
+	}
+	if !bytes.HasPrefix(bb1, bb) {
+		t.Fatalf("%s incremental: signed bytes modified\n", msg)
+	}
+	rr, err := api.ValidateSignatures(bytes.NewReader(bb1), roots, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if r := rr[0]; !r.Valid() || !r.ModifiedAfterSigning {
+		t.Fatalf("%s: unexpected result after incremental update: %+v\n", msg, r)
+	}
+}
+
+func TestSignaturePolicyWithoutCmd(t *testing.T) {
+	msg := "TestSignaturePolicyWithoutCmd"
+
+	key, cert := testSigner(t)
+	bb := signPDF(t, filepath.Join(inDir, "test.pdf"), key, cert)
+
+	// Entry points set the command mode of caller built configurations.
+	newConf := func() *pdfcpu.Configuration {
+		conf := pdfcpu.NewDefaultConfiguration()
+		conf.SignaturePolicy = pdfcpu.SignaturePolicyRefuse
+		return conf
+	}
+
+	var buf bytes.Buffer
+	if err := api.AddAnnotations(bytes.NewReader(bb), &buf, nil, textAnn, newConf()); err != pdfcpu.ErrSigned {
+		t.Fatalf("%s add: want %v, got %v\n", msg, pdfcpu.ErrSigned, err)
+	}
+	if err := api.RemoveAnnotations(bytes.NewReader(bb), &buf, nil, nil, nil, newConf()); err != pdfcpu.ErrSigned {
+		t.Fatalf("%s remove: want %v, got %v\n", msg, pdfcpu.ErrSigned, err)
+	}
+	if err := api.AddKeywords(bytes.NewReader(bb), &buf, []string{"pdfcpu"}, newConf()); err != pdfcpu.ErrSigned {
+		t.Fatalf("%s keywords: want %v, got %v\n", msg, pdfcpu.ErrSigned, err)
+	}
+	if _, err := api.OptimizeWithStats(bytes.NewReader(bb), &buf, newConf()); err != pdfcpu.ErrSigned {
+		t.Fatalf("%s optimize: want %v, got %v\n", msg, pdfcpu.ErrSigned, err)
+	}
+}
+
+func TestValidateSignaturesUnsigned(t *testing.T) {
+	msg := "TestValidateSignaturesUnsigned"
+
+	rr, err := api.ValidateSignaturesFile(filepath.Join(inDir, "Acroforms2.pdf"), "", nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(rr) != 0 {
+		t.Fatalf("%s: want no signatures, got %v\n", msg, rr)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// aesDecompressor returns a zip decompressor for WinZip AES-256 encrypted entries.
+func aesDecompressor(password string) zip.Decompressor {
+	return func(r io.Reader) io.ReadCloser {
+		bb, err := ioutil.ReadAll(r)
+		if err != nil {
+			return ioutil.NopCloser(&errReader{err})
+		}
+		data, err := aesDecrypt(bb, password)
+		if err != nil {
+			return ioutil.NopCloser(&errReader{err})
+		}
+		return flate.NewReader(bytes.NewReader(data))
+	}
+}
+
+type errReader struct{ err error }
+
+func (r *errReader) Read(p []byte) (int, error) { return 0, r.err }
+
+func aesDecrypt(bb []byte, password string) ([]byte, error) {
+	if len(bb) < 28 {
+		return nil, errors.New("entry too short")
+	}
+	salt, pwv, data, mac := bb[:16], bb[16:18], bb[18:len(bb)-10], bb[len(bb)-10:]
+
+	dk := pbkdf2.Key([]byte(password), salt, 1000, 66, sha1.New)
+
+	if !bytes.Equal(dk[64:66], pwv) {
+		return nil, errors.New("wrong password")
+	}
+
+	m := hmac.New(sha1.New, dk[32:64])
+	m.Write(data)
+	if !bytes.Equal(m.Sum(nil)[:10], mac) {
+		return nil, errors.New("authentication failed")
+	}
+
+	b, err := aes.NewCipher(dk[:32])
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(data))
+	ctr, ks := make([]byte, 16), make([]byte, 16)
+	for i := 0; i < len(data); i += 16 {
+		binary.LittleEndian.PutUint64(ctr, uint64(i/16+1))
+		b.Encrypt(ks, ctr)
+		for j := i; j < i+16 && j < len(data); j++ {
+			out[j] = data[j] ^ ks[j-i]
+		}
+	}
+
+	return out, nil
+}
+
+// validateZipEntries validates all entries of zr as PDF files.
+func validateZipEntries(t *testing.T, msg string, zr *zip.Reader, password string) {
+	t.Helper()
+
+	if password != "" {
+		zr.RegisterDecompressor(99, aesDecompressor(password))
+	}
+
+	for _, f := range zr.File {
+		if password != "" && f.Method != 99 {
+			t.Fatalf("%s %s: entry not encrypted\n", msg, f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, f.Name, err)
+		}
+		bb, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, f.Name, err)
+		}
+		if err := api.Validate(bytes.NewReader(bb), nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, f.Name, err)
+		}
+	}
+}
+
+func TestSplitZip(t *testing.T) {
+	msg := "TestSplitZip"
+	fileName := "Acroforms2.pdf"
+	inFile := filepath.Join(inDir, fileName)
+
+	pageCount, err := api.PageCountFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, password := range []string{"", "secret"} {
+		f, err := os.Open(inFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		var buf bytes.Buffer
+		err = api.SplitZip(f, &buf, fileName, 1, password, nil)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		zr := readZip(t, buf.Bytes())
+		if len(zr.File) != pageCount {
+			t.Fatalf("%s: want %d entries, got %d\n", msg, pageCount, len(zr.File))
+		}
+		if zr.File[0].Name != "Acroforms2_1.pdf" {
+			t.Fatalf("%s: unexpected entry name: %s\n", msg, zr.File[0].Name)
+		}
+
+		validateZipEntries(t, msg, zr, password)
+	}
+}
+
+func TestSplitZipWrongPassword(t *testing.T) {
+	msg := "TestSplitZipWrongPassword"
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+
+	f, err := os.Open(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+
+	var buf bytes.Buffer
+	if err := api.SplitZip(f, &buf, "CenterOfWhy.pdf", 1, "secret", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	zr := readZip(t, buf.Bytes())
+	zr.RegisterDecompressor(99, aesDecompressor("guess"))
+	rc, err := zr.File[0].Open()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer rc.Close()
+	if _, err := ioutil.ReadAll(rc); err == nil {
+		t.Fatalf("%s: expected wrong password error\n", msg)
+	}
+}
@@ -17,10 +17,16 @@ limitations under the License.
 package test
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/testpdf"
 )
 
 func TestSplitSpan1(t *testing.T) {
@@ -86,3 +92,72 @@ func TestSplitLowLevel(t *testing.T) {
 		t.Fatalf("%s write: %v\n", msg, err)
 	}
 }
+
+func TestSplitRaw(t *testing.T) {
+	msg := "TestSplitRaw"
+
+	pp := make([]testpdf.Page, 5)
+	for i := range pp {
+		pp[i].Text = fmt.Sprintf("Page %d", i+1)
+	}
+	rs, err := testpdf.Reader(pp...)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	pss, err := api.SplitRaw(rs, 2, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	want := [][2]int{{1, 2}, {3, 4}, {5, 5}}
+	if len(pss) != len(want) {
+		t.Fatalf("%s: want %d page spans, got %d\n", msg, len(want), len(pss))
+	}
+
+	for i, ps := range pss {
+		if ps.From != want[i][0] || ps.Thru != want[i][1] {
+			t.Fatalf("%s: want span %d-%d, got %d-%d\n", msg, want[i][0], want[i][1], ps.From, ps.Thru)
+		}
+		bb, err := ioutil.ReadAll(ps.Reader)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		n, err := api.PageCount(bytes.NewReader(bb), nil)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if n != ps.Thru-ps.From+1 {
+			t.Fatalf("%s: span %d-%d: want %d pages, got %d\n", msg, ps.From, ps.Thru, ps.Thru-ps.From+1, n)
+		}
+	}
+
+	if _, err := api.SplitRaw(rs, -1, nil); err == nil {
+		t.Fatalf("%s: want error for invalid span\n", msg)
+	}
+}
+
+func TestSplitFileNameTemplate(t *testing.T) {
+	msg := "TestSplitFileNameTemplate"
+	inFile := filepath.Join(outDir, "template.pdf")
+
+	pp := make([]testpdf.Page, 5)
+	for i := range pp {
+		pp[i].Text = fmt.Sprintf("Page %d", i+1)
+	}
+	if err := testpdf.File(inFile, pp...); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.FileNameTemplate = "{name}_part{(from+1)/2}_of_{pagecount}.pdf"
+	if err := api.SplitFile(inFile, outDir, 2, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, fn := range []string{"template_part1_of_5.pdf", "template_part2_of_5.pdf", "template_part3_of_5.pdf"} {
+		if _, err := os.Stat(filepath.Join(outDir, fn)); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+}
@@ -17,12 +17,16 @@ limitations under the License.
 package test
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/testpdf"
 )
 
 func testAddWatermarks(t *testing.T, msg, inFile, outFile string, selectedPages []string, mode, modeParam, desc string, onTop bool) {
@@ -548,3 +552,77 @@ func TestStampingLifecyle(t *testing.T) {
 		t.Fatalf("Watermarks found: %s\n", outFile)
 	}
 }
+
+func TestConditionalStamp(t *testing.T) {
+	msg := "TestConditionalStamp"
+	inFile := filepath.Join(outDir, "conditional.pdf")
+	outFile := filepath.Join(outDir, "conditionalOut.pdf")
+
+	if err := testpdf.File(inFile, testpdf.Page{Text: "Hello"}, testpdf.Page{Text: "World"}); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Stamp documents with more than 10 pages only.
+	wm, err := api.TextWatermark("Demo", "if:pagecount > 10", true, false, pdfcpu.POINTS)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.AddWatermarksFile(inFile, outFile, nil, wm, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ok := hasWatermarks(outFile, t); ok {
+		t.Fatalf("%s: watermarks found: %s\n", msg, outFile)
+	}
+
+	// Stamp even pages.
+	wm, err = api.TextWatermark("Demo", "if:page % 2 == 0", true, false, pdfcpu.POINTS)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.AddWatermarksFile(inFile, outFile, nil, wm, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ok := hasWatermarks(outFile, t); !ok {
+		t.Fatalf("%s: no watermarks found: %s\n", msg, outFile)
+	}
+}
+
+func TestAddWatermarksBrokenPDFStamp(t *testing.T) {
+	msg := "TestAddWatermarksBrokenPDFStamp"
+	dir := t.TempDir()
+	stampFile := filepath.Join(dir, "brokenStamp.pdf")
+	inFile := filepath.Join(dir, "in.pdf")
+	outFile := filepath.Join(dir, "out.pdf")
+
+	ctx, err := testpdf.Context(testpdf.Page{Text: "Stamp"})
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ctx.WriteObjectStream = false
+	var buf bytes.Buffer
+	if err := api.WriteContext(ctx, &buf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Break the page tree of the stamp file keeping all offsets intact.
+	bb := buf.Bytes()
+	i := bytes.Index(bb, []byte("/Count"))
+	if i < 0 {
+		t.Fatalf("%s: missing page count\n", msg)
+	}
+	copy(bb[i:], "/Cxunt")
+	if err := ioutil.WriteFile(stampFile, bb, 0644); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := copyFile(t, filepath.Join(inDir, "test.pdf"), inFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.AddPDFWatermarksFile(inFile, outFile, nil, true, stampFile+":1", "sc:.5", nil); err == nil {
+		t.Fatalf("%s: want error for broken stamp file\n", msg)
+	}
+	if _, err := os.Stat(outFile); !os.IsNotExist(err) {
+		t.Fatalf("%s: %s written without stamp\n", msg, outFile)
+	}
+}
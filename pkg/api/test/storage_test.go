@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/storage"
+)
+
+func TestStorage(t *testing.T) {
+	msg := "TestStorage"
+
+	ctx, err := api.ReadContextStorage(storage.Dir(inDir), "CenterOfWhy.pdf", nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.OptimizeContext(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.WriteContextStorage(ctx, storage.Dir(outDir), "storage/CenterOfWhy.pdf"); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.ValidateFile(filepath.Join(outDir, "storage", "CenterOfWhy.pdf"), nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+func TestStorageWriteFailure(t *testing.T) {
+	msg := "TestStorageWriteFailure"
+
+	ctx, err := api.ReadContextStorage(storage.Dir(inDir), "CenterOfWhy.pdf", nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	c, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctx.SetCancelContext(c)
+
+	if err := api.WriteContextStorage(ctx, storage.Dir(outDir), "storage/Canceled.pdf"); err == nil {
+		t.Fatalf("%s: want error\n", msg)
+	}
+
+	if _, err := os.Stat(filepath.Join(outDir, "storage", "Canceled.pdf")); !os.IsNotExist(err) {
+		t.Fatalf("%s: partial file stored: %v\n", msg, err)
+	}
+}
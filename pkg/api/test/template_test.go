@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+func pageText(t *testing.T, fileName string, pageNr int) string {
+	t.Helper()
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", fileName, err)
+	}
+	pt, err := ctx.ExtractPageText(pageNr)
+	if err != nil {
+		t.Fatalf("%s: %v\n", fileName, err)
+	}
+	var ss []string
+	for _, b := range pt.Blocks {
+		for _, l := range b.Lines {
+			ss = append(ss, l.Text)
+		}
+	}
+	return strings.Join(ss, "\n")
+}
+
+func TestFillTemplate(t *testing.T) {
+	msg := "TestFillTemplate"
+
+	tmpl := `{"regions": [
+		{"name": "name", "page": 1, "rect": [50, 700, 300, 730], "font": "Helvetica-Bold", "fontSize": 16, "color": "#0000FF"},
+		{"name": "address", "page": 1, "rect": [50, 600, 300, 690], "justify": true},
+		{"name": "photo", "page": 1, "rect": [400, 650, 500, 750], "image": true, "pos": "c"}
+	]}`
+
+	logo := filepath.Join(resDir, "logoSmall.png")
+	recs := `[
+		{"name": "Jane Doe", "address": "Main Street 1\nSpringfield", "photo": "` + logo + `"},
+		{"name": "John Doe"}
+	]`
+
+	templateFile := filepath.Join(outDir, "template.json")
+	if err := ioutil.WriteFile(templateFile, []byte(tmpl), 0644); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	recordsFile := filepath.Join(outDir, "records.json")
+	if err := ioutil.WriteFile(recordsFile, []byte(recs), 0644); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	inFile := filepath.Join(inDir, "test.pdf")
+	outFiles, err := api.FillTemplateFile(inFile, templateFile, recordsFile, outDir, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(outFiles) != 2 {
+		t.Fatalf("%s: want 2 files, got %v\n", msg, outFiles)
+	}
+
+	for i, want := range []string{"Jane Doe", "John Doe"} {
+		if err := api.ValidateFile(outFiles[i], nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, outFiles[i], err)
+		}
+		if s := pageText(t, outFiles[i], 1); !strings.Contains(s, want) {
+			t.Fatalf("%s %s: missing %q in:\n%s\n", msg, outFiles[i], want, s)
+		}
+	}
+
+	// Invalid templates are rejected.
+	for _, s := range []string{
+		`{"regions": []}`,
+		`{"regions": [{"name": "a", "page": 1, "rect": [0, 0, 0, 10]}]}`,
+		`{"regions": [{"name": "a", "page": 1, "rect": [0, 0, 10, 10]}, {"name": "a", "page": 1, "rect": [0, 0, 10, 10]}]}`,
+		`{"regions": [{"name": "a", "page": 1, "rect": [0, 0, 10, 10], "font": "Unknown"}]}`,
+	} {
+		if _, err := api.ReadTemplate(strings.NewReader(s)); err == nil {
+			t.Fatalf("%s: expected error for %s\n", msg, s)
+		}
+	}
+
+	// Regions beyond the last page are rejected.
+	tt, err := api.ReadTemplate(strings.NewReader(`{"regions": [{"name": "a", "page": 100, "rect": [0, 0, 10, 10]}]}`))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	f, err := os.Open(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+	if err := api.FillTemplate(f, ioutil.Discard, tt, map[string]string{"a": "x"}, nil); err == nil {
+		t.Fatalf("%s: expected error for page out of range\n", msg)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 )
 
 func TestTrim(t *testing.T) {
@@ -41,3 +42,112 @@ func TestTrim(t *testing.T) {
 		t.Fatalf("%s: %v\n", msg, err)
 	}
 }
+
+func checkStructKids(t *testing.T, ctx *pdfcpu.Context, o pdfcpu.Object, pages pdfcpu.IntSet, visited pdfcpu.IntSet) {
+	t.Helper()
+
+	if ir, ok := o.(pdfcpu.IndirectRef); ok {
+		if visited[ir.ObjectNumber.Value()] {
+			return
+		}
+		visited[ir.ObjectNumber.Value()] = true
+	}
+
+	o, err := ctx.Dereference(o)
+	if err != nil {
+		t.Fatalf("checkStructKids: %v\n", err)
+	}
+
+	switch o := o.(type) {
+	case pdfcpu.Array:
+		for _, o1 := range o {
+			checkStructKids(t, ctx, o1, pages, visited)
+		}
+	case pdfcpu.Dict:
+		if ir := o.IndirectRefEntry("Pg"); ir != nil && !pages[ir.ObjectNumber.Value()] {
+			t.Fatalf("checkStructKids: structure refers to missing page obj#%d\n", ir.ObjectNumber.Value())
+		}
+		checkStructKids(t, ctx, o["K"], pages, visited)
+	}
+}
+
+// checkStructTree verifies the structure tree of fileName refers to existing pages only
+// and the parent tree holds exactly the entries in use and returns the number of these entries.
+func checkStructTree(t *testing.T, fileName string) int {
+	t.Helper()
+
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("checkStructTree %s: %v\n", fileName, err)
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("checkStructTree %s: %v\n", fileName, err)
+	}
+
+	d, err := ctx.DereferenceDict(rootDict["StructTreeRoot"])
+	if err != nil || d == nil {
+		t.Fatalf("checkStructTree %s: missing StructTreeRoot %v\n", fileName, err)
+	}
+
+	pages := pdfcpu.IntSet{}
+	keys := pdfcpu.IntSet{}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		pageDict, ir, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			t.Fatalf("checkStructTree %s: %v\n", fileName, err)
+		}
+		pages[ir.ObjectNumber.Value()] = true
+		if k := pageDict.IntEntry("StructParents"); k != nil {
+			if keys[*k] {
+				t.Fatalf("checkStructTree %s: duplicate StructParents %d\n", fileName, *k)
+			}
+			keys[*k] = true
+		}
+		annots, _ := ctx.DereferenceArray(pageDict["Annots"])
+		for _, o := range annots {
+			annot, _ := ctx.DereferenceDict(o)
+			if k := annot.IntEntry("StructParent"); k != nil {
+				keys[*k] = true
+			}
+		}
+	}
+
+	pt, err := ctx.DereferenceDict(d["ParentTree"])
+	if err != nil || pt == nil {
+		t.Fatalf("checkStructTree %s: missing ParentTree %v\n", fileName, err)
+	}
+
+	nums := pt.ArrayEntry("Nums")
+	if len(nums) != 2*len(keys) {
+		t.Fatalf("checkStructTree %s: want %d parent tree entries, got %d\n", fileName, len(keys), len(nums)/2)
+	}
+	for i := 0; i < len(nums); i += 2 {
+		if k := int(nums[i].(pdfcpu.Integer)); !keys[k] {
+			t.Fatalf("checkStructTree %s: unused parent tree key %d\n", fileName, k)
+		}
+	}
+
+	checkStructKids(t, ctx, d["K"], pages, pdfcpu.IntSet{})
+
+	return len(keys)
+}
+
+func TestTrimStructTree(t *testing.T) {
+	msg := "TestTrimStructTree"
+	fileName := "CenterOfWhy.pdf"
+	inFile := filepath.Join(inDir, fileName)
+	outFile := filepath.Join(outDir, "CenterOfWhyTrimmed.pdf")
+
+	if err := api.TrimFile(inFile, outFile, []string{"2-3"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	checkStructTree(t, outFile)
+
+	if err := api.RemovePagesFile(inFile, outFile, []string{"1"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	checkStructTree(t, outFile)
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/testpdf"
+)
+
+// invalidPDF returns a document with an invalid first and third page, an invalid outline and an invalid info dict.
+func invalidPDF(t *testing.T) []byte {
+	t.Helper()
+	msg := "invalidPDF"
+
+	ctx, err := testpdf.Context(testpdf.Pages(3)...)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, pageNr := range []int{1, 3} {
+		d, _, _, err := ctx.PageDict(pageNr, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		d["Rotate"] = pdfcpu.Name("Left")
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	// An outline without last item.
+	first, err := ctx.IndRefForNewObject(pdfcpu.Dict{"Title": pdfcpu.StringLiteral("Item")})
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ir, err := ctx.IndRefForNewObject(pdfcpu.Dict{"Type": pdfcpu.Name("Outlines"), "First": *first})
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rootDict["Outlines"] = *ir
+
+	if ctx.Info, err = ctx.IndRefForNewObject(pdfcpu.Dict{"Title": pdfcpu.Integer(1)}); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	var buf bytes.Buffer
+	if err := api.WriteContext(ctx, &buf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	return buf.Bytes()
+}
+
+func TestValidationReport(t *testing.T) {
+	msg := "TestValidationReport"
+	bb := invalidPDF(t)
+
+	// Fail fast on the first error.
+	if err := api.Validate(bytes.NewReader(bb), nil); err == nil {
+		t.Fatalf("%s: want validation error\n", msg)
+	}
+
+	r, err := api.ValidateWithReport(bytes.NewReader(bb), nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if r.Valid || r.Mode != "relaxed" {
+		t.Fatalf("%s: want invalid relaxed report, got %v\n", msg, r.List())
+	}
+
+	want := map[string]string{
+		"Root/Pages/1":  pdfcpu.SeverityError,
+		"Root/Pages/3":  pdfcpu.SeverityError,
+		"Root/Outlines": pdfcpu.SeverityError,
+		"Trailer/Info":  pdfcpu.SeverityWarning,
+	}
+	if len(r.Findings) != len(want) {
+		t.Fatalf("%s: want %d findings, got %v\n", msg, len(want), r.List())
+	}
+	for _, f := range r.Findings {
+		if sev, ok := want[f.Path]; !ok || f.Severity != sev || f.Message == "" {
+			t.Fatalf("%s: unexpected finding: %s\n", msg, f)
+		}
+	}
+	if r.Errors() != 3 {
+		t.Fatalf("%s: want 3 errors, got %d\n", msg, r.Errors())
+	}
+
+	// All findings are errors in strict mode.
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.ValidationMode = pdfcpu.ValidationStrict
+	if r, err = api.ValidateWithReport(bytes.NewReader(bb), conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if r.Mode != "strict" || r.Errors() != len(r.Findings) || r.Errors() < 4 {
+		t.Fatalf("%s: unexpected strict report: %v\n", msg, r.List())
+	}
+
+	bb, err = json.Marshal(r)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	var r1 pdfcpu.ValidationReport
+	if err := json.Unmarshal(bb, &r1); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if r1.Valid || len(r1.Findings) != len(r.Findings) || r1.Findings[0].Rule == "" {
+		t.Fatalf("%s: JSON roundtrip failed: %s\n", msg, bb)
+	}
+}
+
+func TestValidationReportValid(t *testing.T) {
+	msg := "TestValidationReportValid"
+
+	bb, err := testpdf.Bytes(testpdf.Pages(2)...)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	r, err := api.ValidateWithReport(bytes.NewReader(bb), nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !r.Valid || len(r.Findings) != 0 {
+		t.Fatalf("%s: unexpected findings: %v\n", msg, r.List())
+	}
+}
+
+// sloppyPDF returns a document whose first page content and info dict violate pedantic rules only.
+func sloppyPDF(t *testing.T) []byte {
+	t.Helper()
+	msg := "sloppyPDF"
+
+	ctx, err := testpdf.Context(testpdf.Pages(2)...)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	d, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd, _ := ctx.NewStreamDictForBuf([]byte("q (Hello) Tj BT 10 10 Td ET"))
+	if err := sd.Encode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Update("Contents", *ir)
+
+	if ctx.Info, err = ctx.IndRefForNewObject(pdfcpu.Dict{strings.Repeat("K", 128): pdfcpu.StringLiteral("v")}); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	var buf bytes.Buffer
+	if err := api.WriteContext(ctx, &buf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	return buf.Bytes()
+}
+
+func pedanticFindings(r *pdfcpu.ValidationReport) map[string]int {
+	m := map[string]int{}
+	for _, f := range r.Findings {
+		if f.Rule == pdfcpu.RuleSyntax || f.Rule == pdfcpu.RuleLimits {
+			m[f.Rule]++
+		}
+	}
+	return m
+}
+
+func TestValidationReportPedantic(t *testing.T) {
+	msg := "TestValidationReportPedantic"
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.ValidationMode = pdfcpu.ValidationPedantic
+
+	// pdfcpu's own content streams are clean.
+	bb, err := testpdf.Bytes(testpdf.Pages(2)...)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	r, err := api.ValidateWithReport(bytes.NewReader(bb), conf)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if r.Mode != "pedantic" || len(pedanticFindings(r)) > 0 {
+		t.Fatalf("%s: unexpected findings: %v\n", msg, r.List())
+	}
+
+	bb = sloppyPDF(t)
+
+	conf = pdfcpu.NewDefaultConfiguration()
+	conf.ValidationMode = pdfcpu.ValidationStrict
+	rStrict, err := api.ValidateWithReport(bytes.NewReader(bb), conf)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(pedanticFindings(rStrict)) > 0 {
+		t.Fatalf("%s: unexpected strict findings: %v\n", msg, rStrict.List())
+	}
+
+	conf = pdfcpu.NewDefaultConfiguration()
+	conf.ValidationMode = pdfcpu.ValidationPedantic
+	if r, err = api.ValidateWithReport(bytes.NewReader(bb), conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Tj outside text object, unbalanced q and the long info dict key.
+	m := pedanticFindings(r)
+	if r.Valid || r.Errors() != len(r.Findings) || m[pdfcpu.RuleSyntax] != 2 || m[pdfcpu.RuleLimits] != 1 {
+		t.Fatalf("%s: unexpected pedantic report: %v\n", msg, r.List())
+	}
+	if len(r.Findings) != len(rStrict.Findings)+3 {
+		t.Fatalf("%s: want strict findings plus 3, got %v\n", msg, r.List())
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+var zipTestFiles = []string{"CenterOfWhy.pdf", "Acroforms2.pdf", "adobe_errata.pdf"}
+
+// testZip returns a zip archive of zipTestFiles and their total page count.
+func testZip(t *testing.T) (*zip.Reader, int) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	pageCount := 0
+
+	for _, fn := range zipTestFiles {
+		inFile := filepath.Join(inDir, fn)
+		bb, err := ioutil.ReadFile(inFile)
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+		n, err := api.PageCountFile(inFile)
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+		pageCount += n
+		w, err := zw.Create("in/" + fn)
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+		if _, err := w.Write(bb); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	return zr, pageCount
+}
+
+func readZip(t *testing.T, bb []byte) *zip.Reader {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(bb), int64(len(bb)))
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	return zr
+}
+
+func TestMergeZip(t *testing.T) {
+	msg := "TestMergeZip"
+	zr, pageCount := testZip(t)
+
+	var buf bytes.Buffer
+	if err := api.MergeZip(zr, &buf, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	n, err := api.PageCount(bytes.NewReader(buf.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if n != pageCount {
+		t.Fatalf("%s: pageCount: want %d, got %d\n", msg, pageCount, n)
+	}
+}
+
+func TestOptimizeZip(t *testing.T) {
+	msg := "TestOptimizeZip"
+	zr, _ := testZip(t)
+
+	var buf bytes.Buffer
+	if err := api.OptimizeZip(zr, &buf, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	zr = readZip(t, buf.Bytes())
+	if len(zr.File) != len(zipTestFiles) {
+		t.Fatalf("%s: want %d entries, got %d\n", msg, len(zipTestFiles), len(zr.File))
+	}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		bb, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if err := api.Validate(bytes.NewReader(bb), nil); err != nil {
+			t.Fatalf("%s: %s: %v\n", msg, f.Name, err)
+		}
+	}
+}
+
+func TestExtractPagesZip(t *testing.T) {
+	msg := "TestExtractPagesZip"
+	zr, _ := testZip(t)
+
+	var buf bytes.Buffer
+	if err := api.ExtractPagesZip(zr, &buf, []string{"1"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	zr = readZip(t, buf.Bytes())
+	if len(zr.File) != len(zipTestFiles) {
+		t.Fatalf("%s: want %d entries, got %d\n", msg, len(zipTestFiles), len(zr.File))
+	}
+	if want := "in/Acroforms2_page_1.pdf"; zr.File[0].Name != want {
+		t.Fatalf("%s: want %s, got %s\n", msg, want, zr.File[0].Name)
+	}
+}
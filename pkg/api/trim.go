@@ -99,7 +99,7 @@ func TrimFile(inFile, outFile string, selectedPages []string, conf *pdfcpu.Confi
 			return
 		}
 		if outFile == "" || inFile == outFile {
-			if err = os.Rename(tmpFile, inFile); err != nil {
+			if err = replaceFile(tmpFile, inFile, conf); err != nil {
 				return
 			}
 		}
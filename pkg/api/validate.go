@@ -17,6 +17,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -29,6 +30,11 @@ import (
 
 // Validate validates a PDF stream read from rs.
 func Validate(rs io.ReadSeeker, conf *pdfcpu.Configuration) error {
+	return ValidateWithContext(context.Background(), rs, conf)
+}
+
+// ValidateWithContext validates a PDF stream read from rs and stops with c's error once c is done.
+func ValidateWithContext(c context.Context, rs io.ReadSeeker, conf *pdfcpu.Configuration) error {
 	if conf == nil {
 		conf = pdfcpu.NewDefaultConfiguration()
 	}
@@ -40,7 +46,7 @@ func Validate(rs io.ReadSeeker, conf *pdfcpu.Configuration) error {
 
 	from1 := time.Now()
 
-	ctx, err := ReadContext(rs, conf)
+	ctx, err := ReadContextWithContext(c, rs, conf)
 	if err != nil {
 		return err
 	}
@@ -50,7 +56,7 @@ func Validate(rs io.ReadSeeker, conf *pdfcpu.Configuration) error {
 
 	if err = ValidateContext(ctx); err != nil {
 		s := ""
-		if conf.ValidationMode == pdfcpu.ValidationStrict {
+		if ctx.StrictValidation() {
 			s = " (try -mode=relaxed)"
 		}
 		err = errors.Wrap(err, fmt.Sprintf("validation error (obj#:%d)%s", ctx.CurObj, s))
@@ -61,6 +67,7 @@ func Validate(rs io.ReadSeeker, conf *pdfcpu.Configuration) error {
 
 	log.Stats.Printf("XRefTable:\n%s\n", ctx)
 	pdfcpu.ValidationTimingStats(dur1, dur2, dur)
+	pdfcpu.PublishOperationMetrics(ctx, "validate", dur1, dur2, 0, 0, dur)
 
 	// at this stage: no binary breakup available!
 	if ctx.Read.FileSize > 0 {
@@ -97,3 +104,60 @@ func ValidateFile(inFile string, conf *pdfcpu.Configuration) error {
 
 	return nil
 }
+
+// ValidateWithReport validates a PDF stream read from rs collecting all findings instead of failing on the first one.
+// conf.ValidationMode decides which findings are fatal.
+// The returned error is reserved for problems reading rs.
+func ValidateWithReport(rs io.ReadSeeker, conf *pdfcpu.Configuration) (*pdfcpu.ValidationReport, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.VALIDATE
+
+	if conf.ValidationMode == pdfcpu.ValidationNone {
+		return nil, errors.New("pdfcpu: validate: mode ValidationNone not allowed")
+	}
+
+	from1 := time.Now()
+
+	ctx, err := ReadContext(rs, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	dur1 := time.Since(from1).Seconds()
+	from2 := time.Now()
+
+	ctx.Report = pdfcpu.NewValidationReport(conf.ValidationMode)
+
+	if err = ValidateContext(ctx); err != nil {
+		// Validation could not proceed any further.
+		ctx.Report.Add(ctx.CurObj, "Root", "Root", err.Error())
+	}
+
+	dur2 := time.Since(from2).Seconds()
+	dur := time.Since(from1).Seconds()
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	pdfcpu.ValidationTimingStats(dur1, dur2, dur)
+	pdfcpu.PublishOperationMetrics(ctx, "validate", dur1, dur2, 0, 0, dur)
+
+	return ctx.Report, nil
+}
+
+// ValidateFileWithReport validates inFile collecting all findings instead of failing on the first one.
+func ValidateFileWithReport(inFile string, conf *pdfcpu.Configuration) (*pdfcpu.ValidationReport, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+
+	log.CLI.Printf("validating(mode=%s) %s ...\n", conf.ValidationModeString(), inFile)
+
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ValidateWithReport(f, conf)
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// Batch processing of PDF files bundled in a zip archive.
+// Archive entries are accessed via io/fs, so any fs.FS like a directory (os.DirFS) may serve as input as well.
+// Entries get processed in memory without staging them as temporary files.
+
+// pdfEntries returns the names of all PDF files in fsys in lexical order.
+func pdfEntries(fsys fs.FS) ([]string, error) {
+	var names []string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.EqualFold(path.Ext(name), ".pdf") {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, errors.New("pdfcpu: no PDF files found")
+	}
+	return names, nil
+}
+
+func readEntry(fsys fs.FS, name string) (io.ReadSeeker, error) {
+	bb, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(bb), nil
+}
+
+// entryConfiguration returns a copy of conf for processing a single entry.
+func entryConfiguration(conf *pdfcpu.Configuration) *pdfcpu.Configuration {
+	if conf == nil {
+		return nil
+	}
+	c := *conf
+	return &c
+}
+
+// MergeZip merges all PDF files of fsys in lexical order of their names and writes the result to w.
+// fsys is usually a *zip.Reader.
+func MergeZip(fsys fs.FS, w io.Writer, conf *pdfcpu.Configuration) error {
+	names, err := pdfEntries(fsys)
+	if err != nil {
+		return err
+	}
+
+	rsc := make([]io.ReadSeeker, len(names))
+	for i, name := range names {
+		if rsc[i], err = readEntry(fsys, name); err != nil {
+			return err
+		}
+	}
+
+	return Merge(rsc, w, conf)
+}
+
+// OptimizeZip optimizes all PDF files of fsys and writes them as zip archive to w.
+// fsys is usually a *zip.Reader.
+func OptimizeZip(fsys fs.FS, w io.Writer, conf *pdfcpu.Configuration) error {
+	names, err := pdfEntries(fsys)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+
+	for _, name := range names {
+		rs, err := readEntry(fsys, name)
+		if err != nil {
+			return err
+		}
+		w1, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if err := Optimize(rs, w1, entryConfiguration(conf)); err != nil {
+			return errors.Wrapf(err, "pdfcpu: %s", name)
+		}
+	}
+
+	return zw.Close()
+}
+
+// ExtractPagesZip generates single page PDF files for selected pages of all PDF files of fsys
+// and writes them as zip archive to w.
+// fsys is usually a *zip.Reader.
+func ExtractPagesZip(fsys fs.FS, w io.Writer, selectedPages []string, conf *pdfcpu.Configuration) error {
+	names, err := pdfEntries(fsys)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+
+	for _, name := range names {
+		rs, err := readEntry(fsys, name)
+		if err != nil {
+			return err
+		}
+		dir := path.Dir(name)
+		err = extractPages(rs, name, selectedPages, entryConfiguration(conf), func(fn string, ctx *pdfcpu.Context) error {
+			w1, err := zw.Create(path.Join(dir, fn))
+			if err != nil {
+				return err
+			}
+			return WriteContext(ctx, w1)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "pdfcpu: %s", name)
+		}
+	}
+
+	return zw.Close()
+}
+
+// MergeZipFile merges all PDF files of the zip archive inFile and writes the result to outFile.
+func MergeZipFile(inFile, outFile string, conf *pdfcpu.Configuration) (err error) {
+	zr, err := zip.OpenReader(inFile)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	f, err := os.Create(outFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	log.CLI.Printf("writing %s...\n", outFile)
+	return MergeZip(zr, f, conf)
+}
+
+// OptimizeZipFile optimizes all PDF files of the zip archive inFile and writes them to the zip archive outFile.
+func OptimizeZipFile(inFile, outFile string, conf *pdfcpu.Configuration) (err error) {
+	zr, err := zip.OpenReader(inFile)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	f, err := os.Create(outFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	log.CLI.Printf("writing %s...\n", outFile)
+	return OptimizeZip(zr, f, conf)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Password protected zip entries are encrypted using WinZip AES-256 encryption (AE-1)
+// supported by all major zip tools. The weak traditional PKWARE encryption is not supported.
+
+const (
+	zipMethodAES     = 99
+	zipAESKeyLen     = 32 // AES-256
+	zipAESSaltLen    = 16
+	zipAESMACLen     = 10
+	zipAESIterations = 1000
+)
+
+// zipAESExtra is the AES extra field: AE-1, AES-256, deflated.
+var zipAESExtra = []byte{0x01, 0x99, 0x07, 0x00, 0x01, 0x00, 'A', 'E', 0x03, 0x08, 0x00}
+
+// zipAESKeys derives the encryption key, the authentication key and the password verifier.
+func zipAESKeys(password string, salt []byte) (encKey, macKey, pwv []byte) {
+	dk := pbkdf2.Key([]byte(password), salt, zipAESIterations, 2*zipAESKeyLen+2, sha1.New)
+	return dk[:zipAESKeyLen], dk[zipAESKeyLen : 2*zipAESKeyLen], dk[2*zipAESKeyLen:]
+}
+
+// zipAESStream is AES in counter mode using a little endian counter starting at 1 as defined by WinZip.
+type zipAESStream struct {
+	b   cipher.Block
+	ctr [aes.BlockSize]byte
+	ks  [aes.BlockSize]byte
+	pos int
+}
+
+func newZipAESStream(key []byte) (*zipAESStream, error) {
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &zipAESStream{b: b, pos: aes.BlockSize}, nil
+}
+
+func (s *zipAESStream) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if s.pos == aes.BlockSize {
+			for j := range s.ctr {
+				s.ctr[j]++
+				if s.ctr[j] != 0 {
+					break
+				}
+			}
+			s.b.Encrypt(s.ks[:], s.ctr[:])
+			s.pos = 0
+		}
+		dst[i] = src[i] ^ s.ks[s.pos]
+		s.pos++
+	}
+}
+
+// zipAESWriter encrypts deflated entry data and appends the authentication code on Close.
+type zipAESWriter struct {
+	w      io.Writer
+	s      *zipAESStream
+	mac    hash.Hash
+	fw     *flate.Writer
+	prefix []byte // Salt and password verifier.
+}
+
+// writePrefix writes salt and password verifier ahead of the encrypted data.
+// This is deferred since zip.Writer creates the compressor before writing the local file header.
+func (zw *zipAESWriter) writePrefix() error {
+	if zw.prefix == nil {
+		return nil
+	}
+	_, err := zw.w.Write(zw.prefix)
+	zw.prefix = nil
+	return err
+}
+
+func (zw *zipAESWriter) encrypt(p []byte) (int, error) {
+	if err := zw.writePrefix(); err != nil {
+		return 0, err
+	}
+	buf := make([]byte, len(p))
+	zw.s.XORKeyStream(buf, p)
+	zw.mac.Write(buf)
+	return zw.w.Write(buf)
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+func (zw *zipAESWriter) Write(p []byte) (int, error) {
+	return zw.fw.Write(p)
+}
+
+func (zw *zipAESWriter) Close() error {
+	if err := zw.fw.Close(); err != nil {
+		return err
+	}
+	if err := zw.writePrefix(); err != nil {
+		return err
+	}
+	_, err := zw.w.Write(zw.mac.Sum(nil)[:zipAESMACLen])
+	return err
+}
+
+func newZipAESWriter(w io.Writer, password string, rnd io.Reader) (io.WriteCloser, error) {
+	salt := make([]byte, zipAESSaltLen)
+	if _, err := io.ReadFull(rnd, salt); err != nil {
+		return nil, err
+	}
+
+	encKey, macKey, pwv := zipAESKeys(password, salt)
+
+	s, err := newZipAESStream(encKey)
+	if err != nil {
+		return nil, err
+	}
+
+	zw := &zipAESWriter{w: w, s: s, mac: hmac.New(sha1.New, macKey), prefix: append(salt, pwv...)}
+	if zw.fw, err = flate.NewWriter(writerFunc(zw.encrypt), flate.DefaultCompression); err != nil {
+		return nil, err
+	}
+
+	return zw, nil
+}
+
+// zipWriter streams files into a zip archive, encrypted if a password is given.
+type zipWriter struct {
+	*zip.Writer
+	password string
+}
+
+// newZipWriter returns a zipWriter drawing salts from rnd, which defaults to crypto/rand.Reader.
+func newZipWriter(w io.Writer, password string, rnd io.Reader) *zipWriter {
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	zw := &zipWriter{Writer: zip.NewWriter(w), password: password}
+	if password != "" {
+		zw.RegisterCompressor(zipMethodAES, func(w io.Writer) (io.WriteCloser, error) {
+			return newZipAESWriter(w, password, rnd)
+		})
+	}
+	return zw
+}
+
+// create adds a file name to the archive and returns a writer for its content.
+func (zw *zipWriter) create(name string) (io.Writer, error) {
+	fh := &zip.FileHeader{Name: name, Method: zip.Deflate}
+	if zw.password != "" {
+		fh.Method = zipMethodAES
+		fh.Flags |= 0x1 // encrypted
+		fh.Extra = zipAESExtra
+	}
+	return zw.CreateHeader(fh)
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+)
+
+// zipAESKnownAnswer is a zip archive holding kat.txt stored (not deflated) and encrypted using AE-1 with AES-256
+// and the password "secret", created with bsdtar (libarchive 3.7.7):
+//
+//	bsdtar --format zip --options zip:encryption=aes256,zip:compression=store --passphrase secret -cf kat.zip kat.txt
+const zipAESKnownAnswer = "" +
+	"504b0304140009006300a3b6505d00000000000000000000000007002b006b61" +
+	"742e74787475780b000104000000000400000000019907000100414503000055" +
+	"540d0007d2aad26ad2aad26ad2aad26aac430cb85e7f888a2885c8a130faa9c9" +
+	"306c54f581ee6205317d023032020d44381f57bebc3624b2cb7b44297ebc6c5f" +
+	"d43e827908504b07082b85aba73500000019000000504b010214031400090063" +
+	"00a3b6505d2b85aba73500000019000000070023000000000000000000a48100" +
+	"0000006b61742e74787475780b00010400000000040000000001990700010041" +
+	"450300005554050001d2aad26a504b0506000000000100010058000000950000" +
+	"000000"
+
+const (
+	zipAESKnownPassword  = "secret"
+	zipAESKnownPlaintext = "pdfcpu known answer test\n"
+)
+
+// zipAESKnownEntry returns salt, password verifier, encrypted data and authentication code of the known answer entry.
+func zipAESKnownEntry(t *testing.T) (salt, pwv, data, mac []byte) {
+	t.Helper()
+
+	bb, err := hex.DecodeString(zipAESKnownAnswer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(bb), int64(len(bb)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := zr.File[0]
+	if f.Method != zipMethodAES {
+		t.Fatalf("want compression method %d, got %d\n", zipMethodAES, f.Method)
+	}
+	off, err := f.DataOffset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := bb[off : off+int64(f.CompressedSize64)]
+
+	n := len(raw)
+	return raw[:zipAESSaltLen], raw[zipAESSaltLen : zipAESSaltLen+2], raw[zipAESSaltLen+2 : n-zipAESMACLen], raw[n-zipAESMACLen:]
+}
+
+func TestZipAESKnownAnswer(t *testing.T) {
+	salt, pwv, data, mac := zipAESKnownEntry(t)
+
+	encKey, macKey, pwv1 := zipAESKeys(zipAESKnownPassword, salt)
+	if !bytes.Equal(pwv, pwv1) {
+		t.Fatalf("password verifier: want %x, got %x\n", pwv, pwv1)
+	}
+
+	m := hmac.New(sha1.New, macKey)
+	m.Write(data)
+	if got := m.Sum(nil)[:zipAESMACLen]; !bytes.Equal(mac, got) {
+		t.Fatalf("authentication code: want %x, got %x\n", mac, got)
+	}
+
+	s, err := newZipAESStream(encKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, len(data))
+	s.XORKeyStream(got, data)
+	if string(got) != zipAESKnownPlaintext {
+		t.Fatalf("decrypt: want %q, got %q\n", zipAESKnownPlaintext, got)
+	}
+}
+
+func TestZipAESWriterKnownAnswer(t *testing.T) {
+	salt, pwv, data, mac := zipAESKnownEntry(t)
+
+	// The key stream of the known answer entry.
+	ks := make([]byte, len(data))
+	for i := range data {
+		ks[i] = data[i] ^ zipAESKnownPlaintext[i]
+	}
+
+	var buf bytes.Buffer
+	w, err := newZipAESWriter(&buf, zipAESKnownPassword, bytes.NewReader(salt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(w, strings.NewReader(zipAESKnownPlaintext)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	bb := buf.Bytes()
+	if !bytes.Equal(bb[:zipAESSaltLen], salt) || !bytes.Equal(bb[zipAESSaltLen:zipAESSaltLen+2], pwv) {
+		t.Fatalf("salt and password verifier: want %x%x, got %x\n", salt, pwv, bb[:zipAESSaltLen+2])
+	}
+	enc, mac1 := bb[zipAESSaltLen+2:len(bb)-zipAESMACLen], bb[len(bb)-zipAESMACLen:]
+
+	// The deflated content xor'ed with the known key stream.
+	var deflated bytes.Buffer
+	fw, err := flate.NewWriter(&deflated, flate.DefaultCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write([]byte(zipAESKnownPlaintext))
+	fw.Close()
+	if len(enc) != deflated.Len() {
+		t.Fatalf("want %d encrypted bytes, got %d\n", deflated.Len(), len(enc))
+	}
+	n := len(ks)
+	if n > len(enc) {
+		n = len(enc)
+	}
+	for i := 0; i < n; i++ {
+		if enc[i]^ks[i] != deflated.Bytes()[i] {
+			t.Fatalf("encrypted byte %d does not match the known key stream\n", i)
+		}
+	}
+
+	// The authentication code covers the encrypted data using the known answer authentication key.
+	_, macKey, _ := zipAESKeys(zipAESKnownPassword, salt)
+	m := hmac.New(sha1.New, macKey)
+	m.Write(data)
+	if !bytes.Equal(m.Sum(nil)[:zipAESMACLen], mac) {
+		t.Fatalf("authentication key does not match the known answer\n")
+	}
+	m.Reset()
+	m.Write(enc)
+	if !bytes.Equal(m.Sum(nil)[:zipAESMACLen], mac1) {
+		t.Fatalf("authentication code: want %x, got %x\n", m.Sum(nil)[:zipAESMACLen], mac1)
+	}
+}
@@ -18,24 +18,49 @@ limitations under the License.
 package cli
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pkg/errors"
 )
 
 // Validate inFile against ISO-32000-1:2008.
+// The JSON variant reports all findings.
 func Validate(cmd *Command) ([]string, error) {
 	conf := cmd.Conf
 	if conf != nil && conf.ValidationMode == pdfcpu.ValidationNone {
 		return nil, errors.New("validate: mode == ValidationNone")
 	}
 
-	return nil, api.ValidateFile(*cmd.InFile, conf)
+	if !cmd.JSON {
+		return nil, api.ValidateFile(*cmd.InFile, conf)
+	}
+
+	r, err := api.ValidateFileWithReport(*cmd.InFile, conf)
+	if err != nil {
+		return nil, err
+	}
+	bb, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	out := []string{string(bb)}
+	if !r.Valid {
+		return out, errors.Errorf("validation failed: %d errors", r.Errors())
+	}
+	return out, nil
 }
 
 // Optimize inFile and write result to outFile.
+// It reports the stream data saved by category.
 func Optimize(cmd *Command) ([]string, error) {
-	return nil, api.OptimizeFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+	stats, err := api.OptimizeFileWithStats(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+	return []string{stats.String()}, nil
 }
 
 // Encrypt inFile and write result to outFile.
@@ -89,6 +114,11 @@ func Rotate(cmd *Command) ([]string, error) {
 	return nil, api.RotateFile(*cmd.InFile, *cmd.OutFile, cmd.Rotation, cmd.PageSelection, cmd.Conf)
 }
 
+// RotateContent rotates the content of selected pages of inFile and writes result to outFile.
+func RotateContent(cmd *Command) ([]string, error) {
+	return nil, api.RotateContentFile(*cmd.InFile, *cmd.OutFile, cmd.Rotation, cmd.PageSelection, cmd.Conf)
+}
+
 // AddWatermarks adds watermarks or stamps to selected pages of inFile and writes the result to outFile.
 func AddWatermarks(cmd *Command) ([]string, error) {
 	return nil, api.AddWatermarksFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.Watermark, cmd.Conf)
@@ -160,6 +190,57 @@ func ExtractContent(cmd *Command) ([]string, error) {
 	return nil, api.ExtractContentFile(*cmd.InFile, *cmd.OutDir, cmd.PageSelection, cmd.Conf)
 }
 
+// ExtractText dumps the text of selected pages of inFile segmented into blocks, lines and words as JSON files into outDir.
+func ExtractText(cmd *Command) ([]string, error) {
+	return nil, api.ExtractTextFile(*cmd.InFile, *cmd.OutDir, cmd.PageSelection, cmd.Conf)
+}
+
+// ExtractSVG exports selected pages of inFile as SVG images into outDir.
+func ExtractSVG(cmd *Command) ([]string, error) {
+	return nil, api.ExtractSVGFile(*cmd.InFile, *cmd.OutDir, cmd.PageSelection, cmd.Conf)
+}
+
+// ExtractTables dumps the tables detected on selected pages of inFile as CSV or JSON files into outDir.
+func ExtractTables(cmd *Command) ([]string, error) {
+	return nil, api.ExtractTablesFile(*cmd.InFile, *cmd.OutDir, cmd.PageSelection, cmd.JSON, cmd.Conf)
+}
+
+// Duplicates returns the duplicate and near-duplicate pages found across inFiles in human readable or JSON form.
+func Duplicates(cmd *Command) ([]string, error) {
+	dups, err := api.DuplicatePagesFile(cmd.InFiles, cmd.Threshold, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.JSON {
+		bb, err := json.MarshalIndent(dups, "", "\t")
+		if err != nil {
+			return nil, err
+		}
+		return []string{string(bb)}, nil
+	}
+	if len(dups) == 0 {
+		return []string{"no duplicate pages"}, nil
+	}
+	ss := make([]string, len(dups))
+	for i, d := range dups {
+		ss[i] = d.String()
+	}
+	return ss, nil
+}
+
+// Run applies a registered operation to inFile and writes the result to outFile.
+// Without an operation name all registered operations are listed.
+func Run(cmd *Command) ([]string, error) {
+	if cmd.Operation == "" {
+		ss := pdfcpu.OperationNames()
+		if len(ss) == 0 {
+			return []string{"no operations registered"}, nil
+		}
+		return ss, nil
+	}
+	return nil, api.RunOperationFile(*cmd.InFile, *cmd.OutFile, cmd.Operation, cmd.Conf)
+}
+
 // ExtractMetadata dumps all metadata dict entries for inFile into outDir.
 func ExtractMetadata(cmd *Command) ([]string, error) {
 	return nil, api.ExtractMetadataFile(*cmd.InFile, *cmd.OutDir, cmd.Conf)
@@ -260,14 +341,37 @@ func Crop(cmd *Command) ([]string, error) {
 	return nil, api.CropFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.Box, cmd.Conf)
 }
 
-// ListAnnotations returns inFile's page annotations.
+// ListAnnotations returns inFile's page annotations in human readable or JSON form.
 func ListAnnotations(cmd *Command) ([]string, error) {
-	_, ss, err := api.ListAnnotationsFile(*cmd.InFile, cmd.PageSelection, cmd.Conf)
-	return ss, err
+	if !cmd.JSON {
+		_, ss, err := api.ListAnnotationsFile(*cmd.InFile, cmd.PageSelection, cmd.Conf)
+		return ss, err
+	}
+
+	aa, err := api.AnnotationsFile(*cmd.InFile, cmd.PageSelection, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+	if aa == nil {
+		aa = []pdfcpu.PageAnnotation{}
+	}
+	bb, err := json.MarshalIndent(aa, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return []string{string(bb)}, nil
+}
+
+// AddAnnotations adds an annotation to selected pages of inFile and writes the result to outFile.
+func AddAnnotations(cmd *Command) ([]string, error) {
+	return nil, api.AddAnnotationsFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.Annotation, cmd.Conf, false)
 }
 
 // RemoveAnnotations deletes annotations from inFile's page tree and writes the result to outFile.
 func RemoveAnnotations(cmd *Command) ([]string, error) {
+	if len(cmd.StringVals) > 0 {
+		return nil, api.RemoveAnnotationsByTypeFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.StringVals, cmd.Conf)
+	}
 	return nil, api.RemoveAnnotationsFile(*cmd.InFile, "", cmd.PageSelection, nil, cmd.IntVals, cmd.Conf, false)
 }
 
@@ -275,3 +379,179 @@ func RemoveAnnotations(cmd *Command) ([]string, error) {
 func ListImages(cmd *Command) ([]string, error) {
 	return api.ListImagesFile(*cmd.InFile, cmd.PageSelection, cmd.Conf)
 }
+
+// Manifest writes a manifest for inFile to outFile.
+func Manifest(cmd *Command) ([]string, error) {
+	return nil, api.ManifestFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// Verify checks inFile against a manifest.
+func Verify(cmd *Command) ([]string, error) {
+	if err := api.VerifyFile(*cmd.InFile, *cmd.OutFile, cmd.Conf); err != nil {
+		return nil, err
+	}
+	return []string{"verification ok"}, nil
+}
+
+// Internalize embeds external stream data of inFile and writes the result to outFile.
+func Internalize(cmd *Command) ([]string, error) {
+	return nil, api.InternalizeStreamsFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// EncryptionInfo returns the security settings of inFile in human readable or JSON form.
+func EncryptionInfo(cmd *Command) ([]string, error) {
+	ei, err := api.EncryptionInfoFile(*cmd.InFile, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+	if !cmd.JSON {
+		return ei.List(), nil
+	}
+	bb, err := json.MarshalIndent(ei, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return []string{string(bb)}, nil
+}
+
+// ListFormFields returns the form fields of inFile in human readable or JSON form.
+func ListFormFields(cmd *Command) ([]string, error) {
+	ff, err := api.FormFieldsFile(*cmd.InFile, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.JSON {
+		bb, err := json.MarshalIndent(pdfcpu.FormData{Fields: ff}, "", "\t")
+		if err != nil {
+			return nil, err
+		}
+		return []string{string(bb)}, nil
+	}
+	ss := make([]string, len(ff))
+	for i, f := range ff {
+		ss[i] = f.String()
+	}
+	return ss, nil
+}
+
+// ExportFormFields writes the form fields of inFile as JSON to outFile.
+func ExportFormFields(cmd *Command) ([]string, error) {
+	return nil, api.ExportFormFieldsFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// FillForm fills in the form of inFile using the JSON form data in cmd.InFiles[0] and writes the result to outFile.
+func FillForm(cmd *Command) ([]string, error) {
+	return nil, api.FillFormFile(*cmd.InFile, cmd.InFiles[0], *cmd.OutFile, cmd.NeedAppearance, cmd.Conf)
+}
+
+// FillTemplate fills the template regions of inFile defined in cmd.InFiles[0] with each record of cmd.InFiles[1]
+// writing one file per record to outDir.
+func FillTemplate(cmd *Command) ([]string, error) {
+	_, err := api.FillTemplateFile(*cmd.InFile, cmd.InFiles[0], cmd.InFiles[1], *cmd.OutDir, cmd.Conf)
+	return nil, err
+}
+
+// PageSizes returns the bytes of inFile attributed to selected pages in human readable or JSON form.
+func PageSizes(cmd *Command) ([]string, error) {
+	r, err := api.PageSizesFile(*cmd.InFile, cmd.PageSelection, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.JSON {
+		bb, err := json.MarshalIndent(r, "", "\t")
+		if err != nil {
+			return nil, err
+		}
+		return []string{string(bb)}, nil
+	}
+	return r.List(), nil
+}
+
+// Orphans returns all objects of inFile unreachable from the trailer in human readable or JSON form.
+func Orphans(cmd *Command) ([]string, error) {
+	oo, err := api.OrphanedObjectsFile(*cmd.InFile, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.JSON {
+		bb, err := json.MarshalIndent(oo, "", "\t")
+		if err != nil {
+			return nil, err
+		}
+		return []string{string(bb)}, nil
+	}
+	if len(oo) == 0 {
+		return []string{"no orphaned objects"}, nil
+	}
+	var size int64
+	ss := make([]string, 0, len(oo)+1)
+	for _, o := range oo {
+		ss = append(ss, o.String())
+		size += o.Size
+	}
+	return append(ss, fmt.Sprintf("%d orphaned objects, %s", len(oo), pdfcpu.ByteSize(size))), nil
+}
+
+// ValidateSignatures validates all digital signatures of inFile and returns the results in human readable or JSON form.
+func ValidateSignatures(cmd *Command) ([]string, error) {
+	rr, err := api.ValidateSignaturesFile(*cmd.InFile, cmd.TrustStore, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.JSON {
+		bb, err := json.MarshalIndent(rr, "", "\t")
+		if err != nil {
+			return nil, err
+		}
+		return []string{string(bb)}, nil
+	}
+	if len(rr) == 0 {
+		return []string{"no signatures"}, nil
+	}
+	var ss []string
+	for _, r := range rr {
+		ss = append(ss, r.List()...)
+	}
+	return ss, nil
+}
+
+// Clip extracts a region of selected pages of inFile into new pages and writes the result to outFile.
+func Clip(cmd *Command) ([]string, error) {
+	return nil, api.ClipFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.Box, cmd.Conf)
+}
+
+// Labels tiles each selected page of inFile onto a sheet of labels and writes the result to outFile.
+func Labels(cmd *Command) ([]string, error) {
+	return nil, api.LabelsFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.Labels, cmd.Conf)
+}
+
+// Resize scales selected pages of inFile to a target page size and writes the result to outFile.
+func Resize(cmd *Command) ([]string, error) {
+	return nil, api.ResizeFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.Resize, cmd.Conf)
+}
+
+// Decorate adds a page background and/or a page border to selected pages of inFile and writes the result to outFile.
+func Decorate(cmd *Command) ([]string, error) {
+	return nil, api.DecorateFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.Decoration, cmd.Conf)
+}
+
+// AutoCrop sets the cropbox of selected pages of inFile to their content bounding box and writes the result to outFile.
+func AutoCrop(cmd *Command) ([]string, error) {
+	return nil, api.AutoCropFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.Padding, cmd.Conf)
+}
+
+// Features returns the notable PDF features used by inFile in human readable or JSON form.
+func Features(cmd *Command) ([]string, error) {
+	r, err := api.FeaturesFile(*cmd.InFile, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.JSON {
+		bb, err := json.MarshalIndent(r, "", "\t")
+		if err != nil {
+			return nil, err
+		}
+		return []string{string(bb)}, nil
+	}
+	return r.List(), nil
+}
@@ -18,6 +18,8 @@ package cli
 
 import (
 	"io"
+	"strconv"
+	"strings"
 
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 )
@@ -39,6 +41,11 @@ type Command struct {
 	Import         *pdfcpu.Import
 	Rotation       int
 	NUp            *pdfcpu.NUp
+	Labels         *pdfcpu.Labels
+	Resize         *pdfcpu.Resize
+	Annotation     pdfcpu.AnnotationRenderer
+	Decoration     *pdfcpu.Decoration
+	Padding        *pdfcpu.Padding
 	Input          io.ReadSeeker
 	Inputs         []io.ReadSeeker
 	Output         io.Writer
@@ -46,6 +53,34 @@ type Command struct {
 	Box            *pdfcpu.Box
 	PageBoundaries *pdfcpu.PageBoundaries
 	IntVals        []int
+	StringVals     []string
+	JSON           bool
+	Threshold      float64
+	Operation      string
+	DryRun         bool   // report what would change instead of writing any output.
+	NeedAppearance bool   // leave the rendering of filled in form fields to the viewer.
+	TrustStore     string // certificate file or directory of trusted roots for signature validation.
+}
+
+// auditParameters returns the parameters of cmd recorded in an audit log.
+func (cmd *Command) auditParameters() map[string]string {
+	m := map[string]string{}
+	if len(cmd.PageSelection) > 0 {
+		m["pages"] = strings.Join(cmd.PageSelection, ",")
+	}
+	if cmd.Span > 0 {
+		m["span"] = strconv.Itoa(cmd.Span)
+	}
+	if cmd.Rotation != 0 {
+		m["rotation"] = strconv.Itoa(cmd.Rotation)
+	}
+	if cmd.Operation != "" {
+		m["operation"] = cmd.Operation
+	}
+	if len(m) == 0 {
+		return nil
+	}
+	return m
 }
 
 var cmdMap = map[pdfcpu.CommandMode]func(cmd *Command) ([]string, error){
@@ -58,6 +93,11 @@ var cmdMap = map[pdfcpu.CommandMode]func(cmd *Command) ([]string, error){
 	pdfcpu.EXTRACTFONTS:            ExtractFonts,
 	pdfcpu.EXTRACTPAGES:            ExtractPages,
 	pdfcpu.EXTRACTCONTENT:          ExtractContent,
+	pdfcpu.EXTRACTTEXT:             ExtractText,
+	pdfcpu.EXTRACTTABLES:           ExtractTables,
+	pdfcpu.EXTRACTSVG:              ExtractSVG,
+	pdfcpu.DUPLICATES:              Duplicates,
+	pdfcpu.RUN:                     Run,
 	pdfcpu.EXTRACTMETADATA:         ExtractMetadata,
 	pdfcpu.TRIM:                    Trim,
 	pdfcpu.ADDWATERMARKS:           AddWatermarks,
@@ -96,8 +136,27 @@ var cmdMap = map[pdfcpu.CommandMode]func(cmd *Command) ([]string, error){
 	pdfcpu.REMOVEBOXES:             processPageBoundaries,
 	pdfcpu.CROP:                    processPageBoundaries,
 	pdfcpu.LISTANNOTATIONS:         processPageAnnotations,
+	pdfcpu.ADDANNOTATIONS:          processPageAnnotations,
 	pdfcpu.REMOVEANNOTATIONS:       processPageAnnotations,
 	pdfcpu.LISTIMAGES:              processImages,
+	pdfcpu.MANIFEST:                Manifest,
+	pdfcpu.VERIFY:                  Verify,
+	pdfcpu.INTERNALIZE:             Internalize,
+	pdfcpu.ENCRYPTIONINFO:          EncryptionInfo,
+	pdfcpu.CLIP:                    Clip,
+	pdfcpu.LABELS:                  Labels,
+	pdfcpu.DECORATE:                Decorate,
+	pdfcpu.AUTOCROP:                AutoCrop,
+	pdfcpu.ROTATECONTENT:           RotateContent,
+	pdfcpu.LISTFORMFIELDS:          processForm,
+	pdfcpu.EXPORTFORMFIELDS:        processForm,
+	pdfcpu.FILLFORMFIELDS:          processForm,
+	pdfcpu.FILLTEMPLATE:            FillTemplate,
+	pdfcpu.PAGESIZES:               PageSizes,
+	pdfcpu.ORPHANS:                 Orphans,
+	pdfcpu.RESIZE:                  Resize,
+	pdfcpu.VALIDATESIGNATURES:      ValidateSignatures,
+	pdfcpu.FEATURES:                Features,
 }
 
 // ValidateCommand creates a new command to validate a file.
@@ -225,6 +284,78 @@ func ExtractContentCommand(inFile string, outDir string, pageSelection []string,
 		Conf:          conf}
 }
 
+// ExtractTextCommand creates a new command to extract the segmented text of pages.
+func ExtractTextCommand(inFile string, outDir string, pageSelection []string, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.EXTRACTTEXT
+	return &Command{
+		Mode:          pdfcpu.EXTRACTTEXT,
+		InFile:        &inFile,
+		OutDir:        &outDir,
+		PageSelection: pageSelection,
+		Conf:          conf}
+}
+
+// ExtractSVGCommand creates a new command to export pages as SVG images.
+func ExtractSVGCommand(inFile string, outDir string, pageSelection []string, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.EXTRACTSVG
+	return &Command{
+		Mode:          pdfcpu.EXTRACTSVG,
+		InFile:        &inFile,
+		OutDir:        &outDir,
+		PageSelection: pageSelection,
+		Conf:          conf}
+}
+
+// ExtractTablesCommand creates a new command to extract the tables of pages as CSV or JSON.
+func ExtractTablesCommand(inFile string, outDir string, pageSelection []string, json bool, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.EXTRACTTABLES
+	return &Command{
+		Mode:          pdfcpu.EXTRACTTABLES,
+		InFile:        &inFile,
+		OutDir:        &outDir,
+		PageSelection: pageSelection,
+		JSON:          json,
+		Conf:          conf}
+}
+
+// DuplicatesCommand creates a new command to report duplicate and near-duplicate pages across inFiles.
+func DuplicatesCommand(inFiles []string, threshold float64, json bool, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.DUPLICATES
+	return &Command{
+		Mode:      pdfcpu.DUPLICATES,
+		InFiles:   inFiles,
+		Threshold: threshold,
+		JSON:      json,
+		Conf:      conf}
+}
+
+// RunCommand creates a new command to apply the operation registered under name to inFile.
+// An empty name lists all registered operations.
+func RunCommand(name, inFile, outFile string, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.RUN
+	return &Command{
+		Mode:      pdfcpu.RUN,
+		Operation: name,
+		InFile:    &inFile,
+		OutFile:   &outFile,
+		Conf:      conf}
+}
+
 // ExtractMetadataCommand creates a new command to extract metadata streams.
 func ExtractMetadataCommand(inFile string, outDir string, conf *pdfcpu.Configuration) *Command {
 	if conf == nil {
@@ -491,6 +622,21 @@ func RotateCommand(inFile, outFile string, rotation int, pageSelection []string,
 		Conf:          conf}
 }
 
+// RotateContentCommand creates a new command to rotate the content of pages.
+func RotateContentCommand(inFile, outFile string, rotation int, pageSelection []string, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.ROTATECONTENT
+	return &Command{
+		Mode:          pdfcpu.ROTATECONTENT,
+		InFile:        &inFile,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		Rotation:      rotation,
+		Conf:          conf}
+}
+
 // NUpCommand creates a new command to render PDFs or image files in n-up fashion.
 func NUpCommand(inFiles []string, outFile string, pageSelection []string, nUp *pdfcpu.NUp, conf *pdfcpu.Configuration) *Command {
 	if conf == nil {
@@ -722,6 +868,66 @@ func CropCommand(inFile, outFile string, pageSelection []string, box *pdfcpu.Box
 		Conf:          conf}
 }
 
+// AutoCropCommand creates a new command to crop selected pages to their content.
+func AutoCropCommand(inFile, outFile string, pageSelection []string, pad *pdfcpu.Padding, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.AUTOCROP
+	return &Command{
+		Mode:          pdfcpu.AUTOCROP,
+		InFile:        &inFile,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		Padding:       pad,
+		Conf:          conf}
+}
+
+// ClipCommand creates a new command to extract a region of selected pages into new pages.
+func ClipCommand(inFile, outFile string, pageSelection []string, box *pdfcpu.Box, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.CLIP
+	return &Command{
+		Mode:          pdfcpu.CLIP,
+		InFile:        &inFile,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		Box:           box,
+		Conf:          conf}
+}
+
+// LabelsCommand creates a new command to generate label sheets for selected pages.
+func LabelsCommand(inFile, outFile string, pageSelection []string, lbl *pdfcpu.Labels, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.LABELS
+	return &Command{
+		Mode:          pdfcpu.LABELS,
+		InFile:        &inFile,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		Labels:        lbl,
+		Conf:          conf}
+}
+
+// DecorateCommand creates a new command to add a page background and/or a page border to selected pages.
+func DecorateCommand(inFile, outFile string, pageSelection []string, dec *pdfcpu.Decoration, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.DECORATE
+	return &Command{
+		Mode:          pdfcpu.DECORATE,
+		InFile:        &inFile,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		Decoration:    dec,
+		Conf:          conf}
+}
+
 // ListAnnotationsCommand creates a new command to list annotations for selected pages.
 func ListAnnotationsCommand(inFile string, pageSelection []string, conf *pdfcpu.Configuration) *Command {
 	if conf == nil {
@@ -750,6 +956,36 @@ func RemoveAnnotationsCommand(inFile, outFile string, pageSelection []string, ob
 		Conf:          conf}
 }
 
+// AddAnnotationsCommand creates a new command to add an annotation to selected pages.
+func AddAnnotationsCommand(inFile, outFile string, pageSelection []string, ar pdfcpu.AnnotationRenderer, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.ADDANNOTATIONS
+	return &Command{
+		Mode:          pdfcpu.ADDANNOTATIONS,
+		InFile:        &inFile,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		Annotation:    ar,
+		Conf:          conf}
+}
+
+// RemoveAnnotationsByTypeCommand creates a new command to remove annotations of given types for selected pages.
+func RemoveAnnotationsByTypeCommand(inFile, outFile string, pageSelection, types []string, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.REMOVEANNOTATIONS
+	return &Command{
+		Mode:          pdfcpu.REMOVEANNOTATIONS,
+		InFile:        &inFile,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		StringVals:    types,
+		Conf:          conf}
+}
+
 // ListImagesCommand creates a new command to list annotations for selected pages.
 func ListImagesCommand(inFile string, pageSelection []string, conf *pdfcpu.Configuration) *Command {
 	if conf == nil {
@@ -762,3 +998,180 @@ func ListImagesCommand(inFile string, pageSelection []string, conf *pdfcpu.Confi
 		PageSelection: pageSelection,
 		Conf:          conf}
 }
+
+// ManifestCommand creates a new command to write a manifest for inFile.
+func ManifestCommand(inFile, manifestFile string, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.MANIFEST
+	return &Command{
+		Mode:    pdfcpu.MANIFEST,
+		InFile:  &inFile,
+		OutFile: &manifestFile,
+		Conf:    conf}
+}
+
+// VerifyCommand creates a new command to verify inFile against a manifest.
+func VerifyCommand(inFile, manifestFile string, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.VERIFY
+	return &Command{
+		Mode:    pdfcpu.VERIFY,
+		InFile:  &inFile,
+		OutFile: &manifestFile,
+		Conf:    conf}
+}
+
+// InternalizeCommand creates a new command to embed external stream data.
+func InternalizeCommand(inFile, outFile string, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.INTERNALIZE
+	return &Command{
+		Mode:    pdfcpu.INTERNALIZE,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
+}
+
+// EncryptionInfoCommand creates a new command to print the security settings of inFile.
+func EncryptionInfoCommand(inFile string, json bool, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.ENCRYPTIONINFO
+	return &Command{
+		Mode:   pdfcpu.ENCRYPTIONINFO,
+		InFile: &inFile,
+		JSON:   json,
+		Conf:   conf}
+}
+
+// ListFormFieldsCommand creates a new command to list the form fields of inFile.
+func ListFormFieldsCommand(inFile string, json bool, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.LISTFORMFIELDS
+	return &Command{
+		Mode:   pdfcpu.LISTFORMFIELDS,
+		InFile: &inFile,
+		JSON:   json,
+		Conf:   conf}
+}
+
+// ExportFormFieldsCommand creates a new command to export the form fields of inFile as JSON.
+func ExportFormFieldsCommand(inFile, jsonFile string, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.EXPORTFORMFIELDS
+	return &Command{
+		Mode:    pdfcpu.EXPORTFORMFIELDS,
+		InFile:  &inFile,
+		OutFile: &jsonFile,
+		Conf:    conf}
+}
+
+// FillFormCommand creates a new command to fill in the form of inFile using the JSON form data in jsonFile.
+func FillFormCommand(inFile, jsonFile, outFile string, needAppearance bool, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.FILLFORMFIELDS
+	return &Command{
+		Mode:           pdfcpu.FILLFORMFIELDS,
+		InFile:         &inFile,
+		InFiles:        []string{jsonFile},
+		OutFile:        &outFile,
+		NeedAppearance: needAppearance,
+		Conf:           conf}
+}
+
+// FillTemplateCommand creates a new command to fill the template regions of inFile defined in templateFile
+// with each record of recordsFile writing one file per record to outDir.
+func FillTemplateCommand(inFile, templateFile, recordsFile, outDir string, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.FILLTEMPLATE
+	return &Command{
+		Mode:    pdfcpu.FILLTEMPLATE,
+		InFile:  &inFile,
+		InFiles: []string{templateFile, recordsFile},
+		OutDir:  &outDir,
+		Conf:    conf}
+}
+
+// PageSizesCommand creates a new command to report the bytes of inFile attributed to selected pages.
+func PageSizesCommand(inFile string, pageSelection []string, json bool, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.PAGESIZES
+	return &Command{
+		Mode:          pdfcpu.PAGESIZES,
+		InFile:        &inFile,
+		PageSelection: pageSelection,
+		JSON:          json,
+		Conf:          conf}
+}
+
+// OrphansCommand creates a new command to report all objects of inFile unreachable from the trailer.
+func OrphansCommand(inFile string, json bool, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.ORPHANS
+	return &Command{
+		Mode:   pdfcpu.ORPHANS,
+		InFile: &inFile,
+		JSON:   json,
+		Conf:   conf}
+}
+
+// ResizeCommand creates a new command to scale selected pages to a target page size.
+func ResizeCommand(inFile, outFile string, pageSelection []string, res *pdfcpu.Resize, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.RESIZE
+	return &Command{
+		Mode:          pdfcpu.RESIZE,
+		InFile:        &inFile,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		Resize:        res,
+		Conf:          conf}
+}
+
+// ValidateSignaturesCommand creates a new command to validate all digital signatures of inFile.
+func ValidateSignaturesCommand(inFile, trustStore string, json bool, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.VALIDATESIGNATURES
+	return &Command{
+		Mode:       pdfcpu.VALIDATESIGNATURES,
+		InFile:     &inFile,
+		TrustStore: trustStore,
+		JSON:       json,
+		Conf:       conf}
+}
+
+// FeaturesCommand creates a new command to report the notable PDF features used by inFile.
+func FeaturesCommand(inFile string, json bool, conf *pdfcpu.Configuration) *Command {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.FEATURES
+	return &Command{
+		Mode:   pdfcpu.FEATURES,
+		InFile: &inFile,
+		JSON:   json,
+		Conf:   conf}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+func copyFile(src, dest string) error {
+	f1, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f1.Close()
+
+	f2, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f2, f1); err != nil {
+		f2.Close()
+		return err
+	}
+
+	return f2.Close()
+}
+
+func fileExists(fileName string) bool {
+	_, err := os.Stat(fileName)
+	return err == nil
+}
+
+// dryRunOutDir runs cmd against a temporary output dir and reports the files it would write.
+func dryRunOutDir(cmd *Command, f func(cmd *Command) ([]string, error), tmpDir string) ([]string, error) {
+	outDir := *cmd.OutDir
+	cmd.OutDir = &tmpDir
+
+	if _, err := f(cmd); err != nil {
+		return nil, err
+	}
+
+	fis, err := ioutil.ReadDir(tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	ss := []string{fmt.Sprintf("dry run: would write %d file(s) to %s", len(fis), outDir)}
+	for _, fi := range fis {
+		ss = append(ss, fmt.Sprintf("%s (%s)", fi.Name(), pdfcpu.ByteSize(fi.Size())))
+	}
+
+	return ss, nil
+}
+
+// dryRun processes cmd writing to a temporary location and reports what would change
+// instead of writing any output.
+func dryRun(cmd *Command, f func(cmd *Command) ([]string, error)) ([]string, error) {
+	if cmd.Mode == pdfcpu.VERIFY || cmd.Mode == pdfcpu.RUN && cmd.Operation == "" {
+		// Read only commands.
+		return f(cmd)
+	}
+
+	if cmd.OutDir == nil && cmd.OutFile == nil {
+		if pdfcpu.ReadOnlyCmd(cmd.Mode) {
+			return f(cmd)
+		}
+		// eg. install fonts writing into the font directory.
+		return nil, errors.Errorf("pdfcpu: dry run: unsupported for command mode %d writing no output file", cmd.Mode)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "pdfcpu_dryrun")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if cmd.OutDir != nil {
+		return dryRunOutDir(cmd, f, tmpDir)
+	}
+
+	outFile := *cmd.OutFile
+
+	var inFile string
+	if cmd.InFile != nil {
+		inFile = *cmd.InFile
+		if outFile == "" {
+			outFile = inFile
+		}
+	}
+
+	tmpFile := filepath.Join(tmpDir, filepath.Base(outFile))
+
+	// Commands without inFile like merge or import compare against an existing outFile
+	// since they either extend or overwrite it.
+	if inFile == "" && fileExists(outFile) {
+		if err := copyFile(outFile, tmpFile); err != nil {
+			return nil, err
+		}
+		inFile = outFile
+	}
+
+	cmd.OutFile = &tmpFile
+
+	out, err := f(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	out = append(out, fmt.Sprintf("dry run: %s not written", outFile))
+
+	if !strings.HasSuffix(strings.ToLower(tmpFile), ".pdf") {
+		fi, err := os.Stat(tmpFile)
+		if err != nil {
+			return nil, err
+		}
+		return append(out, fmt.Sprintf("size: %s (%d bytes)", pdfcpu.ByteSize(fi.Size()), fi.Size())), nil
+	}
+
+	r, err := api.ChangeReportFile(inFile, tmpFile, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(out, strings.Split(r.String(), "\n")...), nil
+}
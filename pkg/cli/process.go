@@ -31,11 +31,20 @@ func Process(cmd *Command) (out []string, err error) {
 
 	cmd.Conf.Cmd = cmd.Mode
 
-	if f, ok := cmdMap[cmd.Mode]; ok {
-		return f(cmd)
+	if cmd.Conf.AuditLog && cmd.Conf.AuditParameters == nil {
+		cmd.Conf.AuditParameters = cmd.auditParameters()
 	}
 
-	return nil, errors.Errorf("pdfcpu: process: Unknown command mode %d\n", cmd.Mode)
+	f, ok := cmdMap[cmd.Mode]
+	if !ok {
+		return nil, errors.Errorf("pdfcpu: process: Unknown command mode %d\n", cmd.Mode)
+	}
+
+	if cmd.DryRun {
+		return dryRun(cmd, f)
+	}
+
+	return f(cmd)
 }
 
 func processPageAnnotations(cmd *Command) (out []string, err error) {
@@ -44,6 +53,9 @@ func processPageAnnotations(cmd *Command) (out []string, err error) {
 	case pdfcpu.LISTANNOTATIONS:
 		out, err = ListAnnotations(cmd)
 
+	case pdfcpu.ADDANNOTATIONS:
+		out, err = AddAnnotations(cmd)
+
 	case pdfcpu.REMOVEANNOTATIONS:
 		out, err = RemoveAnnotations(cmd)
 	}
@@ -179,3 +191,20 @@ func processImages(cmd *Command) (out []string, err error) {
 
 	return nil, nil
 }
+
+func processForm(cmd *Command) (out []string, err error) {
+	switch cmd.Mode {
+
+	case pdfcpu.LISTFORMFIELDS:
+		out, err = ListFormFields(cmd)
+
+	case pdfcpu.EXPORTFORMFIELDS:
+		out, err = ExportFormFields(cmd)
+
+	case pdfcpu.FILLFORMFIELDS:
+		out, err = FillForm(cmd)
+
+	}
+
+	return out, err
+}
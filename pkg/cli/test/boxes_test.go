@@ -76,6 +76,32 @@ func TestCropCommand(t *testing.T) {
 	}
 }
 
+func TestClipCommand(t *testing.T) {
+	msg := "TestClipCommand"
+	inFile := filepath.Join(inDir, "test.pdf")
+	outFile := filepath.Join(outDir, "out.pdf")
+
+	for _, tt := range []struct {
+		s string
+		u pdfcpu.DisplayUnit
+	}{
+		{"[0 0 5 5]", pdfcpu.CENTIMETRES},
+		{"100", pdfcpu.POINTS},
+		{"dim:50% 50%", pdfcpu.POINTS},
+		{"pos:tl, off: 10 -10, dim:50% 50%", pdfcpu.POINTS},
+	} {
+		box, err := api.Box(tt.s, tt.u)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		cmd := cli.ClipCommand(inFile, outFile, nil, box, nil)
+		if _, err := cli.Process(cmd); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+}
+
 func TestAddBoxesCommand(t *testing.T) {
 	msg := "TestAddBoxesCommand"
 	inFile := filepath.Join(inDir, "test.pdf")
@@ -128,3 +154,28 @@ func TestAddRemoveBoxesCommand(t *testing.T) {
 		t.Fatalf("%s: %v\n", msg, err)
 	}
 }
+
+func TestAutoCropCommand(t *testing.T) {
+	msg := "TestAutoCropCommand"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "out.pdf")
+
+	for _, tt := range []struct {
+		s string
+		u pdfcpu.DisplayUnit
+	}{
+		{"", pdfcpu.POINTS},
+		{"1", pdfcpu.CENTIMETRES},
+		{"10 5 10 5", pdfcpu.POINTS},
+	} {
+		pad, err := api.Padding(tt.s, tt.u)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		cmd := cli.AutoCropCommand(inFile, outFile, nil, pad, nil)
+		if _, err := cli.Process(cmd); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/cli"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestDecorateCommand(t *testing.T) {
+	msg := "TestDecorateCommand"
+	inFile := filepath.Join(inDir, "test.pdf")
+	outFile := filepath.Join(outDir, "out.pdf")
+
+	for _, tt := range []struct {
+		s string
+		u pdfcpu.DisplayUnit
+	}{
+		{"bgcolor:.9 .9 .9", pdfcpu.POINTS},
+		{"bgcolor:#FFFFFF, gradient:#C0C0FF", pdfcpu.POINTS},
+		{"border:.5 #FF0000, radius:1, margins:1 2", pdfcpu.CENTIMETRES},
+	} {
+		dec, err := api.Decoration(tt.s, tt.u)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		cmd := cli.DecorateCommand(inFile, outFile, nil, dec, nil)
+		if _, err := cli.Process(cmd); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		if err := validateFile(t, outFile, nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+}
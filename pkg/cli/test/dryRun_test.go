@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/cli"
+)
+
+func TestDryRun(t *testing.T) {
+	msg := "TestDryRun"
+	inFile := filepath.Join(outDir, "dryRun.pdf")
+	if err := copyFile(t, filepath.Join(inDir, "Acroforms2.pdf"), inFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	bb, err := ioutil.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	n, err := api.PageCountFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Remove page 1 in place.
+	cmd := cli.RemovePagesCommand(inFile, "", []string{"1"}, nil)
+	cmd.DryRun = true
+	out, err := cli.Process(cmd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	bb1, err := ioutil.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.Equal(bb, bb1) {
+		t.Fatalf("%s: dry run modified %s\n", msg, inFile)
+	}
+
+	s := strings.Join(out, "\n")
+	if !strings.Contains(s, fmt.Sprintf("pages:   %d -> %d (-1)", n, n-1)) {
+		t.Fatalf("%s: unexpected report:\n%s\n", msg, s)
+	}
+
+	// Split into single page files.
+	dir := filepath.Join(outDir, "dryRunSplit")
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	cmd = cli.SplitCommand(inFile, dir, 1, nil)
+	cmd.DryRun = true
+	out, err = cli.Process(cmd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(fis) > 0 || len(out) != n+1 {
+		t.Fatalf("%s: unexpected split dry run: %d files written, report:\n%s\n", msg, len(fis), strings.Join(out, "\n"))
+	}
+}
+
+func TestDryRunWithoutOutput(t *testing.T) {
+	msg := "TestDryRunWithoutOutput"
+
+	for _, cmd := range []*cli.Command{
+		cli.InstallFontsCommand(nil, nil),
+		cli.CreateCheatSheetsFontsCommand(nil, nil),
+	} {
+		cmd.DryRun = true
+		if _, err := cli.Process(cmd); err == nil {
+			t.Fatalf("%s: want error for command mode %d\n", msg, cmd.Mode)
+		}
+	}
+
+	cmd := cli.InfoCommand(filepath.Join(inDir, "Acroforms2.pdf"), nil, nil)
+	cmd.DryRun = true
+	if _, err := cli.Process(cmd); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
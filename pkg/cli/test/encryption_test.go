@@ -120,13 +120,13 @@ func testEncryptDecryptUseCase1(t *testing.T, fileName string, aes bool, keyLeng
 		t.Fatalf("%s: %s change opw: %v\n", msg, outFile, err)
 	}
 
-	// Decrypt wrong upw
-	t.Log("Decrypt wrong upw fails")
+	// Decrypt wrong upw succeeds because of fallback to empty upw.
+	t.Log("Decrypt wrong upw succeeds on empty upw")
 	conf = confForAlgorithm(aes, keyLength)
 	conf.UserPW = "upwWrong"
-	cmd = cli.DecryptCommand(outFile, "", conf)
-	if _, err := cli.Process(cmd); err == nil {
-		t.Fatalf("%s: %s decrypt using wrong upw should fail\n", msg, outFile)
+	cmd = cli.DecryptCommand(outFile, filepath.Join(outDir, "testDecrypted.pdf"), conf)
+	if _, err := cli.Process(cmd); err != nil {
+		t.Fatalf("%s: %s decrypt wrong upw, empty upw: %v\n", msg, outFile, err)
 	}
 
 	// Decrypt wrong opw succeeds on empty upw
@@ -338,12 +338,12 @@ func testEncryptDecryptUseCase3(t *testing.T, fileName string, aes bool, keyLeng
 		t.Fatalf("%s: validate %s using opw: %v\n", msg, outFile, err)
 	}
 
-	// Validate wrong upw
-	t.Log("Validate wrong upw fails")
+	// Validate wrong upw succeeds with fallback to empty upw
+	t.Log("Validate wrong upw succeeds with fallback to empty upw")
 	conf = confForAlgorithm(aes, keyLength)
 	conf.UserPW = "upwWrong"
-	if err := validateFile(t, outFile, conf); err == nil {
-		t.Fatalf("%s: validate %s using wrong upw should fail!\n", msg, outFile)
+	if err := validateFile(t, outFile, conf); err != nil {
+		t.Fatalf("%s: validate %s using wrong upw succeeds falling back to empty upw: %v\n", msg, outFile, err)
 	}
 
 	// Validate no pw using empty upw
@@ -369,12 +369,12 @@ func testEncryptDecryptUseCase3(t *testing.T, fileName string, aes bool, keyLeng
 		t.Fatalf("%s: optimize %s using opw: %v\n", msg, outFile, err)
 	}
 
-	// Optimize wrong upw
-	t.Log("Optimize wrong upw fails")
+	// Optimize wrong upw succeeds with fallback to empty upw
+	t.Log("Optimize wrong upw succeeds with fallback to empty upw")
 	conf = confForAlgorithm(aes, keyLength)
 	conf.UserPW = "upwWrong"
-	if err := optimizeFile(t, outFile, conf); err == nil {
-		t.Fatalf("%s: optimize %s using wrong upw should fail!\n", msg, outFile)
+	if err := optimizeFile(t, outFile, conf); err != nil {
+		t.Fatalf("%s: optimize %s using wrong upw succeeds falling back to empty upw: %v\n", msg, outFile, err)
 	}
 
 	// Optimize empty upw
@@ -418,13 +418,13 @@ func testEncryptDecryptUseCase3(t *testing.T, fileName string, aes bool, keyLeng
 		t.Fatalf("%s: %s change opw: %v\n", msg, outFile, err)
 	}
 
-	// Decrypt wrong upw
-	t.Log("Decrypt wrong upw fails")
+	// Decrypt wrong upw succeeds because of fallback to empty upw.
+	t.Log("Decrypt wrong upw succeeds on empty upw")
 	conf = confForAlgorithm(aes, keyLength)
 	conf.UserPW = "upwWrong"
-	cmd = cli.DecryptCommand(outFile, "", conf)
-	if _, err := cli.Process(cmd); err == nil {
-		t.Fatalf("%s: %s decrypt using wrong upw should fail \n", msg, outFile)
+	cmd = cli.DecryptCommand(outFile, filepath.Join(outDir, "testDecrypted.pdf"), conf)
+	if _, err := cli.Process(cmd); err != nil {
+		t.Fatalf("%s: %s decrypt wrong upw, empty upw: %v\n", msg, outFile, err)
 	}
 
 	// Decrypt wrong opw succeeds because of fallback to empty upw.
@@ -87,6 +87,28 @@ func TestExtractContentCommand(t *testing.T) {
 	}
 }
 
+func TestExtractTextCommand(t *testing.T) {
+	msg := "TestExtractTextCommand"
+	// Extract text of all pages into outDir.
+	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
+	cmd := cli.ExtractTextCommand(inFile, outDir, nil, nil)
+	if _, err := cli.Process(cmd); err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+}
+
+func TestExtractTablesCommand(t *testing.T) {
+	msg := "TestExtractTablesCommand"
+	// Extract tables of all pages into outDir.
+	inFile := filepath.Join(inDir, "adobe_errata.pdf")
+	for _, json := range []bool{false, true} {
+		cmd := cli.ExtractTablesCommand(inFile, outDir, nil, json, nil)
+		if _, err := cli.Process(cmd); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, inFile, err)
+		}
+	}
+}
+
 func TestExtractMetadataCommand(t *testing.T) {
 	msg := "TestExtractMetadataCommand"
 	// Extract metadata into outDir.
@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/cli"
+)
+
+func TestLabelsCommand(t *testing.T) {
+	msg := "TestLabelsCommand"
+	inFile := filepath.Join(inDir, "test.pdf")
+
+	for _, tt := range []struct {
+		outFile       string
+		selectedPages []string
+		desc          string
+		cols, rows    int
+	}{
+		{"testLabels.pdf", []string{"1"}, "", 3, 8},
+		{"testLabelsBorder.pdf", []string{"1-2"}, "form:A4L, ma:20, gu:10, bo:on", 4, 2},
+	} {
+		outFile := filepath.Join(outDir, tt.outFile)
+
+		lbl, err := api.LabelsConfig(tt.cols, tt.rows, tt.desc)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, outFile, err)
+		}
+
+		cmd := cli.LabelsCommand(inFile, outFile, tt.selectedPages, lbl, nil)
+		if _, err := cli.Process(cmd); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, outFile, err)
+		}
+
+		if err := validateFile(t, outFile, nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+}
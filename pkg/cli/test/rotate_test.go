@@ -39,3 +39,20 @@ func TestRotateCommand(t *testing.T) {
 		t.Fatalf("%s: %v\n", msg, err)
 	}
 }
+
+// Rotate the content of the first 2 pages counterclockwise by 90 degrees.
+func TestRotateContentCommand(t *testing.T) {
+	msg := "TestRotateContentCommand"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "test.pdf")
+	rotation := -90
+
+	cmd := cli.RotateContentCommand(inFile, outFile, rotation, []string{"-2"}, nil)
+	if _, err := cli.Process(cmd); err != nil {
+		t.Fatalf("%s %s: %v\n", msg, outFile, err)
+	}
+
+	if err := validateFile(t, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
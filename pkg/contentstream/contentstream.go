@@ -0,0 +1,309 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package contentstream parses decoded PDF content streams into a sequence of operations and writes them back.
+//
+// Operands are represented using pdfcpu's object types:
+// pdfcpu.Integer, pdfcpu.Float, pdfcpu.Name, pdfcpu.StringLiteral, pdfcpu.HexLiteral,
+// pdfcpu.Boolean, pdfcpu.Array, pdfcpu.Dict and nil for null.
+package contentstream
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// Operation represents a content stream operator along with its operands.
+type Operation struct {
+	Operator string
+	Operands []pdfcpu.Object
+
+	// ImageData holds the data of an inline image for operator BI.
+	// The image dict is the single operand of BI.
+	ImageData []byte
+}
+
+func (op Operation) String() string {
+	var b bytes.Buffer
+	Format{}.writeOperation(&b, op)
+	return b.String()
+}
+
+type lexer struct {
+	bb []byte
+	i  int
+}
+
+func delimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func whitespace(c byte) bool {
+	return c == 0x00 || c == 0x09 || c == 0x0A || c == 0x0C || c == 0x0D || c == 0x20
+}
+
+func (l *lexer) eof() bool {
+	return l.i >= len(l.bb)
+}
+
+func (l *lexer) skipWhitespaceAndComments() {
+	for !l.eof() {
+		c := l.bb[l.i]
+		if whitespace(c) {
+			l.i++
+			continue
+		}
+		if c != '%' {
+			return
+		}
+		for !l.eof() && l.bb[l.i] != 0x0A && l.bb[l.i] != 0x0D {
+			l.i++
+		}
+	}
+}
+
+func (l *lexer) regular() string {
+	i := l.i
+	for !l.eof() && !whitespace(l.bb[l.i]) && !delimiter(l.bb[l.i]) {
+		l.i++
+	}
+	return string(l.bb[i:l.i])
+}
+
+// stringLiteral returns the raw content of a string literal including escape sequences.
+func (l *lexer) stringLiteral() (pdfcpu.Object, error) {
+	l.i++
+	i, depth := l.i, 1
+	for ; !l.eof(); l.i++ {
+		switch l.bb[l.i] {
+		case '\\':
+			l.i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				s := string(l.bb[i:l.i])
+				l.i++
+				return pdfcpu.StringLiteral(s), nil
+			}
+		}
+	}
+	return nil, errors.New("pdfcpu: contentstream: unterminated string literal")
+}
+
+func (l *lexer) hexLiteral() (pdfcpu.Object, error) {
+	l.i++
+	var b strings.Builder
+	for ; !l.eof(); l.i++ {
+		c := l.bb[l.i]
+		if c == '>' {
+			l.i++
+			return pdfcpu.HexLiteral(b.String()), nil
+		}
+		if whitespace(c) {
+			continue
+		}
+		if !strings.ContainsRune("0123456789abcdefABCDEF", rune(c)) {
+			return nil, errors.Errorf("pdfcpu: contentstream: corrupt hex literal at offset %d", l.i)
+		}
+		b.WriteByte(c)
+	}
+	return nil, errors.New("pdfcpu: contentstream: unterminated hex literal")
+}
+
+func (l *lexer) array() (pdfcpu.Object, error) {
+	l.i++
+	a := pdfcpu.Array{}
+	for {
+		l.skipWhitespaceAndComments()
+		if l.eof() {
+			return nil, errors.New("pdfcpu: contentstream: unterminated array")
+		}
+		if l.bb[l.i] == ']' {
+			l.i++
+			return a, nil
+		}
+		o, op, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if op != "" {
+			return nil, errors.Errorf("pdfcpu: contentstream: unexpected operator %s in array", op)
+		}
+		a = append(a, o)
+	}
+}
+
+// dictEntries parses key value pairs until end which is either ">>" or the inline image operator "ID".
+func (l *lexer) dictEntries(end string) (pdfcpu.Dict, error) {
+	d := pdfcpu.NewDict()
+	for {
+		l.skipWhitespaceAndComments()
+		if l.eof() {
+			return nil, errors.New("pdfcpu: contentstream: unterminated dict")
+		}
+		if bytes.HasPrefix(l.bb[l.i:], []byte(end)) {
+			l.i += len(end)
+			return d, nil
+		}
+		if l.bb[l.i] != '/' {
+			return nil, errors.Errorf("pdfcpu: contentstream: corrupt dict key at offset %d", l.i)
+		}
+		l.i++
+		k := l.regular()
+		l.skipWhitespaceAndComments()
+		v, op, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if op != "" {
+			return nil, errors.Errorf("pdfcpu: contentstream: missing value for dict key %s", k)
+		}
+		d[k] = v
+	}
+}
+
+// next returns either the next operand or the next operator.
+func (l *lexer) next() (pdfcpu.Object, string, error) {
+	c := l.bb[l.i]
+
+	switch c {
+
+	case '(':
+		o, err := l.stringLiteral()
+		return o, "", err
+
+	case '<':
+		if l.i+1 < len(l.bb) && l.bb[l.i+1] == '<' {
+			l.i += 2
+			d, err := l.dictEntries(">>")
+			return d, "", err
+		}
+		o, err := l.hexLiteral()
+		return o, "", err
+
+	case '[':
+		o, err := l.array()
+		return o, "", err
+
+	case '/':
+		l.i++
+		return pdfcpu.Name(l.regular()), "", nil
+
+	case ')', '>', ']', '{', '}':
+		return nil, "", errors.Errorf("pdfcpu: contentstream: unexpected %c at offset %d", c, l.i)
+	}
+
+	s := l.regular()
+
+	switch s {
+	case "true":
+		return pdfcpu.Boolean(true), "", nil
+	case "false":
+		return pdfcpu.Boolean(false), "", nil
+	case "null":
+		return nil, "", nil
+	}
+
+	if c == '+' || c == '-' || c == '.' || c >= '0' && c <= '9' {
+		if !strings.Contains(s, ".") {
+			if i, err := strconv.Atoi(s); err == nil {
+				return pdfcpu.Integer(i), "", nil
+			}
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return pdfcpu.Float(f), "", nil
+		}
+		return nil, "", errors.Errorf("pdfcpu: contentstream: corrupt number %s", s)
+	}
+
+	return nil, s, nil
+}
+
+// inlineImageData returns the data of an inline image following operator ID.
+func (l *lexer) inlineImageData() ([]byte, error) {
+	// A single white-space character follows ID.
+	l.i++
+
+	for j := l.i; j+1 < len(l.bb); j++ {
+		if l.bb[j] != 'E' || l.bb[j+1] != 'I' || !whitespace(l.bb[j-1]) {
+			continue
+		}
+		if j+2 < len(l.bb) && !whitespace(l.bb[j+2]) && !delimiter(l.bb[j+2]) {
+			continue
+		}
+		data := l.bb[l.i : j-1]
+		l.i = j + 2
+		return data, nil
+	}
+
+	return nil, errors.New("pdfcpu: contentstream: unterminated inline image")
+}
+
+// Parse returns the sequence of operations making up the decoded content stream bb.
+func Parse(bb []byte) ([]Operation, error) {
+	var (
+		ops  []Operation
+		opds []pdfcpu.Object
+	)
+
+	l := &lexer{bb: bb}
+
+	for {
+		l.skipWhitespaceAndComments()
+		if l.eof() {
+			break
+		}
+
+		o, op, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+
+		if op == "" {
+			opds = append(opds, o)
+			continue
+		}
+
+		if op == "BI" {
+			d, err := l.dictEntries("ID")
+			if err != nil {
+				return nil, err
+			}
+			data, err := l.inlineImageData()
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, Operation{Operator: op, Operands: []pdfcpu.Object{d}, ImageData: data})
+			opds = nil
+			continue
+		}
+
+		ops = append(ops, Operation{Operator: op, Operands: opds})
+		opds = nil
+	}
+
+	if len(opds) > 0 {
+		return nil, errors.New("pdfcpu: contentstream: operands missing an operator")
+	}
+
+	return ops, nil
+}
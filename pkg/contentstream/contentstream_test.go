@@ -0,0 +1,216 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contentstream_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/contentstream"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/testpdf"
+)
+
+const content = `q 1 0 0 1 72.5 -.5 cm % a comment
+/GS1 gs
+BT /F1 12 Tf (Hello \(World\)) Tj [(A) -120 <0041> 4.25] TJ ET
+/P <</MCID 0>> BDC 0 0 m 10 10 l S EMC
+BI /W 2 /H 1 /BPC 8 /CS /G ID ab
+EI
+/Im1 Do true false null d0 Q
+`
+
+func TestParse(t *testing.T) {
+	ops, err := contentstream.Parse([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []contentstream.Operation{
+		{Operator: "q"},
+		{Operator: "cm", Operands: []pdfcpu.Object{pdfcpu.Integer(1), pdfcpu.Integer(0), pdfcpu.Integer(0), pdfcpu.Integer(1), pdfcpu.Float(72.5), pdfcpu.Float(-.5)}},
+		{Operator: "gs", Operands: []pdfcpu.Object{pdfcpu.Name("GS1")}},
+		{Operator: "BT"},
+		{Operator: "Tf", Operands: []pdfcpu.Object{pdfcpu.Name("F1"), pdfcpu.Integer(12)}},
+		{Operator: "Tj", Operands: []pdfcpu.Object{pdfcpu.StringLiteral(`Hello \(World\)`)}},
+		{Operator: "TJ", Operands: []pdfcpu.Object{pdfcpu.Array{pdfcpu.StringLiteral("A"), pdfcpu.Integer(-120), pdfcpu.HexLiteral("0041"), pdfcpu.Float(4.25)}}},
+		{Operator: "ET"},
+		{Operator: "BDC", Operands: []pdfcpu.Object{pdfcpu.Name("P"), pdfcpu.Dict{"MCID": pdfcpu.Integer(0)}}},
+		{Operator: "m", Operands: []pdfcpu.Object{pdfcpu.Integer(0), pdfcpu.Integer(0)}},
+		{Operator: "l", Operands: []pdfcpu.Object{pdfcpu.Integer(10), pdfcpu.Integer(10)}},
+		{Operator: "S"},
+		{Operator: "EMC"},
+		{Operator: "BI", Operands: []pdfcpu.Object{pdfcpu.Dict{"W": pdfcpu.Integer(2), "H": pdfcpu.Integer(1), "BPC": pdfcpu.Integer(8), "CS": pdfcpu.Name("G")}}, ImageData: []byte("ab")},
+		{Operator: "Do", Operands: []pdfcpu.Object{pdfcpu.Name("Im1")}},
+		{Operator: "d0", Operands: []pdfcpu.Object{pdfcpu.Boolean(true), pdfcpu.Boolean(false), nil}},
+		{Operator: "Q"},
+	}
+
+	if !reflect.DeepEqual(ops, want) {
+		t.Fatalf("want:\n%v\ngot:\n%v\n", want, ops)
+	}
+
+	// Writing and parsing again is lossless.
+	ops1, err := contentstream.Parse(contentstream.Bytes(ops))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ops1, ops) {
+		t.Fatalf("round trip failed:\n%s\n", contentstream.Bytes(ops))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, s := range []string{
+		"(unterminated Tj",
+		"<00zz> Tj",
+		"[1 2 re] TJ",
+		"BI /W 2 ID abc",
+		"1 2",
+	} {
+		if _, err := contentstream.Parse([]byte(s)); err == nil {
+			t.Errorf("%s: want error\n", s)
+		}
+	}
+}
+
+func TestPageOperations(t *testing.T) {
+	ctx, err := testpdf.Context(testpdf.Page{Text: "Hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ops, err := contentstream.PageOperations(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Turn all text red.
+	var ops1 []contentstream.Operation
+	for _, op := range ops {
+		if op.Operator == "BT" {
+			ops1 = append(ops1, op, contentstream.Operation{Operator: "rg", Operands: []pdfcpu.Object{pdfcpu.Integer(1), pdfcpu.Integer(0), pdfcpu.Integer(0)}})
+			continue
+		}
+		ops1 = append(ops1, op)
+	}
+	if len(ops1) == len(ops) {
+		t.Fatalf("missing text object: %v\n", ops)
+	}
+
+	if err := contentstream.SetPageOperations(ctx, 1, ops1); err != nil {
+		t.Fatal(err)
+	}
+
+	ops2, err := contentstream.PageOperations(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ops2, ops1) {
+		t.Fatalf("want:\n%v\ngot:\n%v\n", ops1, ops2)
+	}
+
+	if _, err := contentstream.PageOperations(ctx, 2); err == nil {
+		t.Fatal("want error for unknown page")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	x, y := 0.1, 0.2
+	ops := []contentstream.Operation{
+		{Operator: "cm", Operands: []pdfcpu.Object{pdfcpu.Float(1), pdfcpu.Integer(0), pdfcpu.Float(x + y), pdfcpu.Float(-0.00001), pdfcpu.Float(72.5), pdfcpu.Float(1.0 / 3)}},
+	}
+
+	for _, tt := range []struct {
+		f    contentstream.Format
+		want string
+	}{
+		{contentstream.Format{}, "1.0 0 0.30000000000000004 -0.00001 72.5 0.3333333333333333 cm\n"},
+		{contentstream.Format{FloatPrecision: 3}, "1.000 0 0.300 0.000 72.500 0.333 cm\n"},
+		{contentstream.Format{FloatPrecision: 3, TrimFloatZeros: true}, "1.0 0 0.3 0.0 72.5 0.333 cm\n"},
+	} {
+		if got := string(tt.f.Bytes(ops)); got != tt.want {
+			t.Errorf("%+v: want %q, got %q\n", tt.f, tt.want, got)
+		}
+	}
+}
+
+func TestSplitContent(t *testing.T) {
+	ctx, err := testpdf.Context(testpdf.Page{Text: "Hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Operands and operators split across parts, a part ending with a comment.
+	a := pdfcpu.Array{}
+	for _, s := range []string{"q 0 0 m 10", "10 l S % comment", "Q"} {
+		sd, _ := ctx.NewStreamDictForBuf([]byte(s))
+		if err := sd.Encode(); err != nil {
+			t.Fatal(err)
+		}
+		ir, err := ctx.IndRefForNewObject(*sd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a = append(a, *ir)
+	}
+	d.Update("Contents", a)
+
+	ops, err := contentstream.PageOperations(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []contentstream.Operation{
+		{Operator: "q"},
+		{Operator: "m", Operands: []pdfcpu.Object{pdfcpu.Integer(0), pdfcpu.Integer(0)}},
+		{Operator: "l", Operands: []pdfcpu.Object{pdfcpu.Integer(10), pdfcpu.Integer(10)}},
+		{Operator: "S"},
+		{Operator: "Q"},
+	}
+	if !reflect.DeepEqual(ops, want) {
+		t.Fatalf("want:\n%v\ngot:\n%v\n", want, ops)
+	}
+
+	// Writing splits content exceeding the max content stream size.
+	ctx.MaxContentStreamSize = 10
+	if err := contentstream.SetPageOperations(ctx, 1, ops); err != nil {
+		t.Fatal(err)
+	}
+	if a := d.ArrayEntry("Contents"); len(a) != 3 {
+		t.Errorf("want 3 content streams, got %v\n", d["Contents"])
+	}
+	ops1, err := contentstream.PageOperations(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ops1, want) {
+		t.Fatalf("want:\n%v\ngot:\n%v\n", want, ops1)
+	}
+
+	// No limit writes a single content stream.
+	ctx.MaxContentStreamSize = 0
+	if err := contentstream.SetPageOperations(ctx, 1, ops); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := d["Contents"].(pdfcpu.IndirectRef); !ok {
+		t.Errorf("want single content stream, got %v\n", d["Contents"])
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contentstream
+
+import (
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+func pageDict(ctx *pdfcpu.Context, pageNr int) (pdfcpu.Dict, error) {
+	d, _, _, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.Errorf("pdfcpu: unknown page number: %d", pageNr)
+	}
+	return d, nil
+}
+
+// PageOperations returns the operations making up the content of page pageNr.
+func PageOperations(ctx *pdfcpu.Context, pageNr int) ([]Operation, error) {
+	d, err := pageDict(ctx, pageNr)
+	if err != nil {
+		return nil, err
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(bb)
+}
+
+func contentStream(ctx *pdfcpu.Context, bb []byte) (*pdfcpu.IndirectRef, error) {
+	sd, _ := ctx.NewStreamDictForBuf(bb)
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+	return ctx.IndRefForNewObject(*sd)
+}
+
+// SetPageOperations replaces the content of page pageNr by ops.
+// Numbers get written using the format configured for ctx.
+// Content exceeding the configured max content stream size gets split into an array of content streams.
+func SetPageOperations(ctx *pdfcpu.Context, pageNr int, ops []Operation) error {
+	d, err := pageDict(ctx, pageNr)
+	if err != nil {
+		return err
+	}
+
+	parts := FormatFor(ctx.Configuration).Parts(ops)
+
+	if len(parts) == 1 {
+		ir, err := contentStream(ctx, parts[0])
+		if err != nil {
+			return err
+		}
+		d.Update("Contents", *ir)
+		return nil
+	}
+
+	a := pdfcpu.Array{}
+	for _, bb := range parts {
+		ir, err := contentStream(ctx, bb)
+		if err != nil {
+			return err
+		}
+		a = append(a, *ir)
+	}
+	d.Update("Contents", a)
+
+	return nil
+}
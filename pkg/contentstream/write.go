@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contentstream
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// Format controls how numbers get written.
+// The zero value writes reals using as many decimal places as needed to read back the same value.
+type Format struct {
+	// Max number of decimal places for reals, 0 for as many as needed.
+	FloatPrecision int
+
+	// Drops trailing zeros from reals written using FloatPrecision.
+	TrimFloatZeros bool
+
+	// Max size in bytes of a content stream part, 0 for no limit.
+	MaxStreamSize int
+}
+
+// FormatFor returns the format configured in conf.
+func FormatFor(conf *pdfcpu.Configuration) Format {
+	return Format{
+		FloatPrecision: conf.FloatPrecision,
+		TrimFloatZeros: conf.TrimFloatZeros,
+		MaxStreamSize:  conf.MaxContentStreamSize,
+	}
+}
+
+func (f Format) writeOperand(b *bytes.Buffer, o pdfcpu.Object) {
+	switch o := o.(type) {
+
+	case nil:
+		b.WriteString("null")
+
+	case pdfcpu.Integer:
+		b.WriteString(strconv.Itoa(o.Value()))
+
+	case pdfcpu.Float:
+		b.WriteString(pdfcpu.FormatFloat(o.Value(), f.FloatPrecision, f.TrimFloatZeros))
+
+	case pdfcpu.Array:
+		b.WriteByte('[')
+		for i, o1 := range o {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			f.writeOperand(b, o1)
+		}
+		b.WriteByte(']')
+
+	case pdfcpu.Dict:
+		b.WriteString("<<")
+		f.writeDictEntries(b, o)
+		b.WriteString(">>")
+
+	default:
+		b.WriteString(o.PDFString())
+	}
+}
+
+func (f Format) writeDictEntries(b *bytes.Buffer, d pdfcpu.Dict) {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(pdfcpu.Name(k).PDFString())
+		b.WriteByte(' ')
+		f.writeOperand(b, d[k])
+	}
+}
+
+func (f Format) writeOperation(b *bytes.Buffer, op Operation) {
+	if op.Operator == "BI" {
+		b.WriteString("BI ")
+		if len(op.Operands) == 1 {
+			if d, ok := op.Operands[0].(pdfcpu.Dict); ok {
+				f.writeDictEntries(b, d)
+			}
+		}
+		b.WriteString(" ID ")
+		b.Write(op.ImageData)
+		b.WriteString("\nEI")
+		return
+	}
+
+	for _, o := range op.Operands {
+		f.writeOperand(b, o)
+		b.WriteByte(' ')
+	}
+	b.WriteString(op.Operator)
+}
+
+// Bytes returns the content stream made up of ops.
+func Bytes(ops []Operation) []byte {
+	return Format{}.Bytes(ops)
+}
+
+// Write writes the content stream made up of ops to w.
+func Write(w io.Writer, ops []Operation) error {
+	return Format{}.Write(w, ops)
+}
+
+// Bytes returns the content stream made up of ops with numbers written in f.
+func (f Format) Bytes(ops []Operation) []byte {
+	var b bytes.Buffer
+	for _, op := range ops {
+		f.writeOperation(&b, op)
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+// Parts returns the content stream made up of ops split into parts of at most f.MaxStreamSize bytes.
+// Parts get split at operation boundaries, an operation exceeding f.MaxStreamSize makes up a part of its own.
+func (f Format) Parts(ops []Operation) [][]byte {
+	var (
+		parts [][]byte
+		b     bytes.Buffer
+		op1   bytes.Buffer
+	)
+	for _, op := range ops {
+		op1.Reset()
+		f.writeOperation(&op1, op)
+		op1.WriteByte('\n')
+		if f.MaxStreamSize > 0 && b.Len() > 0 && b.Len()+op1.Len() > f.MaxStreamSize {
+			parts = append(parts, append([]byte(nil), b.Bytes()...))
+			b.Reset()
+		}
+		b.Write(op1.Bytes())
+	}
+	return append(parts, b.Bytes())
+}
+
+// Write writes the content stream made up of ops with numbers written in f to w.
+func (f Format) Write(w io.Writer, ops []Operation) error {
+	_, err := w.Write(f.Bytes(ops))
+	return err
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package corpus runs pdfcpu over a directory of PDF files
+// and summarizes which files could be processed and why the others failed.
+//
+// Every file is read, validated, written and read and validated again.
+// Failures are classified by the stage they occurred in and a normalized error message,
+// so users may assess how well pdfcpu copes with their own document sets.
+package corpus
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// Stage represents a processing step applied to a corpus file.
+type Stage int
+
+// The stages every corpus file passes.
+const (
+	Read Stage = iota
+	Validate
+	Write
+	Reread
+	Revalidate
+)
+
+func (s Stage) String() string {
+	switch s {
+	case Read:
+		return "read"
+	case Validate:
+		return "validate"
+	case Write:
+		return "write"
+	case Reread:
+		return "reread"
+	case Revalidate:
+		return "revalidate"
+	}
+	return "?"
+}
+
+// Result represents the outcome of processing a single file.
+type Result struct {
+	File  string
+	Stage Stage // The failing stage.
+	Err   error // nil on success.
+}
+
+// Class returns a key grouping similar failures.
+func (r Result) Class() string {
+	if r.Err == nil {
+		return ""
+	}
+	return r.Stage.String() + ": " + normalize(r.Err.Error())
+}
+
+// Report summarizes the results for a corpus.
+type Report struct {
+	Results  []Result
+	Passed   int
+	Failures map[string][]string // Failing files by failure class.
+}
+
+var reDigits = regexp.MustCompile(`[0-9]+`)
+
+// normalize removes file specific details like object numbers and offsets from an error message.
+func normalize(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	s = reDigits.ReplaceAllString(s, "#")
+	if len(s) > 120 {
+		s = s[:120]
+	}
+	return strings.TrimSpace(s)
+}
+
+func readAndValidate(bb []byte, conf *pdfcpu.Configuration) (ctx *pdfcpu.Context, stage Stage, err error) {
+	if ctx, err = api.ReadContext(bytes.NewReader(bb), conf); err != nil {
+		return nil, Read, err
+	}
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = api.ValidateContext(ctx); err != nil {
+			return nil, Validate, err
+		}
+	}
+	return ctx, 0, nil
+}
+
+func process(bb []byte, conf pdfcpu.Configuration) (stage Stage, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("unexpected panic attack: %v", r)
+		}
+	}()
+
+	c := conf
+	ctx, stage, err := readAndValidate(bb, &c)
+	if err != nil {
+		return stage, err
+	}
+
+	var buf bytes.Buffer
+	if err := api.WriteContext(ctx, &buf); err != nil {
+		return Write, err
+	}
+
+	c = conf
+	if _, stage, err = readAndValidate(buf.Bytes(), &c); err != nil {
+		return stage + Reread, err
+	}
+
+	return 0, nil
+}
+
+// File runs all stages for inFile.
+func File(inFile string, conf *pdfcpu.Configuration) Result {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+
+	bb, err := ioutil.ReadFile(inFile)
+	if err != nil {
+		return Result{File: inFile, Stage: Read, Err: err}
+	}
+
+	stage, err := process(bb, *conf)
+
+	return Result{File: inFile, Stage: stage, Err: err}
+}
+
+// Run processes all PDF files in dir and its subdirectories.
+func Run(dir string, conf *pdfcpu.Configuration) (*Report, error) {
+	r := &Report{Failures: map[string][]string{}}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.ToLower(filepath.Ext(path)) != ".pdf" {
+			return nil
+		}
+		res := File(path, conf)
+		r.Results = append(r.Results, res)
+		if res.Err == nil {
+			r.Passed++
+			return nil
+		}
+		c := res.Class()
+		r.Failures[c] = append(r.Failures[c], path)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Failed returns the number of failing files.
+func (r Report) Failed() int {
+	return len(r.Results) - r.Passed
+}
+
+// String returns a summary listing failure classes by decreasing frequency.
+func (r Report) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "files: %d, passed: %d, failed: %d\n", len(r.Results), r.Passed, r.Failed())
+
+	classes := make([]string, 0, len(r.Failures))
+	for c := range r.Failures {
+		classes = append(classes, c)
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		n1, n2 := len(r.Failures[classes[i]]), len(r.Failures[classes[j]])
+		if n1 != n2 {
+			return n1 > n2
+		}
+		return classes[i] < classes[j]
+	})
+
+	for _, c := range classes {
+		ff := r.Failures[c]
+		fmt.Fprintf(&sb, "%5d %s\n", len(ff), c)
+		for _, f := range ff {
+			fmt.Fprintf(&sb, "      %s\n", f)
+		}
+	}
+
+	return sb.String()
+}
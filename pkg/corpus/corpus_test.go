@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package corpus
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/testpdf"
+)
+
+func TestRun(t *testing.T) {
+	msg := "TestRun"
+
+	dir, err := ioutil.TempDir("", "corpus")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := testpdf.File(filepath.Join(dir, "a.pdf"), testpdf.Pages(2)...); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := testpdf.File(filepath.Join(sub, "b.pdf"), testpdf.Pages(1)...); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, fileName := range []string{"c.pdf", "d.pdf"} {
+		if err := ioutil.WriteFile(filepath.Join(sub, fileName), []byte("no pdf"), 0644); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+
+	// Non PDF files are ignored.
+	if err := ioutil.WriteFile(filepath.Join(dir, "e.txt"), []byte("no pdf"), 0644); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	r, err := Run(dir, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if len(r.Results) != 4 || r.Passed != 2 || r.Failed() != 2 {
+		t.Fatalf("%s: got %d files, %d passed, %d failed\n%s", msg, len(r.Results), r.Passed, r.Failed(), r)
+	}
+
+	if len(r.Failures) != 1 {
+		t.Fatalf("%s: want 1 failure class, got %d\n%s", msg, len(r.Failures), r)
+	}
+
+	for c, ff := range r.Failures {
+		if !strings.HasPrefix(c, "read: ") || len(ff) != 2 {
+			t.Errorf("%s: unexpected failure class %s: %v\n", msg, c, ff)
+		}
+	}
+}
@@ -19,75 +19,382 @@ package filter
 import (
 	"bytes"
 	"io"
+	"io/ioutil"
 
 	"github.com/pdfcpu/pdfcpu/pkg/log"
 	"github.com/pkg/errors"
-	"golang.org/x/image/ccitt"
 )
 
 type ccittDecode struct {
 	baseFilter
 }
 
-// Encode implements encoding for a CCITTDecode filter.
-func (f ccittDecode) Encode(r io.Reader) (io.Reader, error) {
-	// TODO
-	return nil, nil
+// Two dimensional coding modes, see ITU-T T.4 Table 4.
+const (
+	ccittPass = iota
+	ccittHorizontal
+	ccittV0
+	ccittVR1
+	ccittVR2
+	ccittVR3
+	ccittVL1
+	ccittVL2
+	ccittVL3
+)
+
+var ccittModeCodes = []ccittCode{
+	{ccittPass, "0001"},
+	{ccittHorizontal, "001"},
+	{ccittV0, "1"},
+	{ccittVR1, "011"},
+	{ccittVR2, "000011"},
+	{ccittVR3, "0000011"},
+	{ccittVL1, "010"},
+	{ccittVL2, "000010"},
+	{ccittVL3, "0000010"},
 }
 
-// Decode implements decoding for a CCITTDecode filter.
-func (f ccittDecode) Decode(r io.Reader) (io.Reader, error) {
+var ccittVerticalOffsets = map[int]int{
+	ccittV0:  0,
+	ccittVR1: 1,
+	ccittVR2: 2,
+	ccittVR3: 3,
+	ccittVL1: -1,
+	ccittVL2: -2,
+	ccittVL3: -3,
+}
 
-	log.Trace.Println("DecodeCCITT begin")
+var errCCITTEndOfBlock = errors.New("pdfcpu: ccitt: end of block")
+
+// ccittTable maps code length and code bits to a value.
+type ccittTable map[int]int
 
-	var ok bool
+func ccittKey(n int, bits uint32) int {
+	return n<<16 | int(bits)
+}
 
-	// <0 : Pure two-dimensional encoding (Group 4)
-	// =0 : Pure one-dimensional encoding (Group 3, 1-D)
-	// >0 : Mixed one- and two-dimensional encoding (Group 3, 2-D)
-	k := 0
-	k, ok = f.parms["K"]
-	if ok && k > 0 {
-		return nil, errors.New("pdfcpu: filter CCITTFax k > 0 currently unsupported")
+func newCCITTTable(codes []ccittCode) ccittTable {
+	t := ccittTable{}
+	for _, c := range codes {
+		var bits uint32
+		for i := 0; i < len(c.code); i++ {
+			bits = bits<<1 | uint32(c.code[i]-'0')
+		}
+		t[ccittKey(len(c.code), bits)] = c.run
 	}
+	return t
+}
 
-	cols := 1728
-	col, ok := f.parms["Columns"]
-	if ok {
-		cols = col
+var (
+	ccittWhite = newCCITTTable(ccittWhiteCodes)
+	ccittBlack = newCCITTTable(ccittBlackCodes)
+	ccittModes = newCCITTTable(ccittModeCodes)
+)
+
+type ccittBitReader struct {
+	bb  []byte
+	pos int // bit position
+}
+
+func (r *ccittBitReader) eof() bool {
+	return r.pos >= len(r.bb)*8
+}
+
+func (r *ccittBitReader) bit() (uint32, error) {
+	if r.eof() {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b := r.bb[r.pos/8] >> (7 - uint(r.pos%8)) & 1
+	r.pos++
+	return uint32(b), nil
+}
+
+func (r *ccittBitReader) align() {
+	r.pos = (r.pos + 7) / 8 * 8
+}
+
+// eol consumes an EOL code including any preceding fill bits.
+func (r *ccittBitReader) eol() bool {
+	i := r.pos
+	for ; i < len(r.bb)*8; i++ {
+		if r.bb[i/8]>>(7-uint(i%8))&1 == 1 {
+			break
+		}
+	}
+	if i-r.pos < 11 || i == len(r.bb)*8 {
+		return false
+	}
+	r.pos = i + 1
+	return true
+}
+
+// code reads the next code of t.
+func (r *ccittBitReader) code(t ccittTable) (int, error) {
+	var bits uint32
+	for n := 1; n <= 13; n++ {
+		b, err := r.bit()
+		if err != nil {
+			return 0, err
+		}
+		bits = bits<<1 | b
+		if v, ok := t[ccittKey(n, bits)]; ok {
+			return v, nil
+		}
+	}
+	return 0, errors.Errorf("pdfcpu: ccitt: invalid code at bit %d", r.pos)
+}
+
+// run reads a run length made up of make-up codes followed by a terminating code.
+func (r *ccittBitReader) run(white bool) (int, error) {
+	t := ccittBlack
+	if white {
+		t = ccittWhite
+	}
+	sum := 0
+	for {
+		v, err := r.code(t)
+		if err != nil {
+			return 0, err
+		}
+		sum += v
+		if v < 64 {
+			return sum, nil
+		}
+	}
+}
+
+type ccittDecoder struct {
+	r         ccittBitReader
+	k         int
+	cols      int
+	byteAlign bool
+}
+
+// decodeRow1D decodes a Modified Huffman coded row into its changing elements.
+func (d *ccittDecoder) decodeRow1D() ([]int, error) {
+	var changes []int
+	white := true
+	for a0 := 0; a0 < d.cols; white = !white {
+		n, err := d.r.run(white)
+		if err != nil {
+			return nil, err
+		}
+		a0 += n
+		if a0 > d.cols {
+			a0 = d.cols
+		}
+		changes = append(changes, a0)
+	}
+	return changes, nil
+}
+
+// decodeRow2D decodes a row coded relative to the changing elements of the reference row.
+func (d *ccittDecoder) decodeRow2D(ref []int) ([]int, error) {
+	ref = append(ref, d.cols, d.cols)
+
+	var changes []int
+	a0, white, i := -1, true, 0
+
+	for a0 < d.cols {
+
+		// b1 is the first changing element on the reference row right of a0 and of opposite color.
+		for i < len(ref)-2 && (ref[i] <= a0 || (i%2 == 0) != white) {
+			i++
+		}
+		b1, b2 := ref[i], ref[i+1]
+
+		mode, err := d.r.code(ccittModes)
+		if err != nil {
+			return nil, err
+		}
+
+		switch mode {
+
+		case ccittPass:
+			a0 = b2
+
+		case ccittHorizontal:
+			if a0 < 0 {
+				a0 = 0
+			}
+			n1, err := d.r.run(white)
+			if err != nil {
+				return nil, err
+			}
+			n2, err := d.r.run(!white)
+			if err != nil {
+				return nil, err
+			}
+			a1, a2 := a0+n1, a0+n1+n2
+			if a2 > d.cols {
+				a2 = d.cols
+			}
+			if a1 > a2 {
+				a1 = a2
+			}
+			changes = append(changes, a1, a2)
+			a0 = a2
+
+		default:
+			a1 := b1 + ccittVerticalOffsets[mode]
+			if a1 < 0 || a1 < a0 {
+				return nil, errors.New("pdfcpu: ccitt: corrupt vertical mode")
+			}
+			if a1 > d.cols {
+				a1 = d.cols
+			}
+			changes = append(changes, a1)
+			a0 = a1
+			white = !white
+		}
+
+		// Restart the search for b1 at the beginning of the current run.
+		if i > 0 {
+			i--
+		}
 	}
 
-	rows, ok := f.parms["Rows"]
-	if !ok {
-		return nil, errors.New("pdfcpu: ccitt: missing DecodeParam \"Rows\"")
+	return changes, nil
+}
+
+// decodeRow decodes the next row or returns errCCITTEndOfBlock.
+func (d *ccittDecoder) decodeRow(ref []int) ([]int, error) {
+	if d.k < 0 {
+		// Group 4
+		if d.byteAlign {
+			d.r.align()
+		}
+		if d.r.eof() || d.r.eol() {
+			return nil, errCCITTEndOfBlock
+		}
+		return d.decodeRow2D(ref)
 	}
 
-	blackIs1 := false
-	v, ok := f.parms["BlackIs1"]
-	if ok && v == 1 {
-		blackIs1 = true
+	// Group 3, rows may be preceded by EOL.
+	eol := d.r.eol()
+	if !eol && d.byteAlign {
+		d.r.align()
+		eol = d.r.eol()
+	}
+	if d.r.eof() {
+		return nil, errCCITTEndOfBlock
 	}
 
-	encodedByteAlign := false
-	v, ok = f.parms["EncodedByteAlign"]
-	if ok && v == 1 {
-		encodedByteAlign = true
+	if eol {
+		// RTC is made up of consecutive EOLs, each followed by a tag bit for mixed coding.
+		pos := d.r.pos
+		if d.k > 0 {
+			d.r.pos++
+		}
+		if d.r.eol() {
+			return nil, errCCITTEndOfBlock
+		}
+		d.r.pos = pos
 	}
 
-	opts := &ccitt.Options{Invert: blackIs1, Align: encodedByteAlign}
+	if d.k > 0 {
+		// Mixed 1D and 2D coding, each row is tagged.
+		b, err := d.r.bit()
+		if err != nil {
+			return nil, err
+		}
+		if b == 0 {
+			return d.decodeRow2D(ref)
+		}
+	}
 
-	mode := ccitt.Group3
-	if k < 0 {
-		mode = ccitt.Group4
+	return d.decodeRow1D()
+}
+
+// writeRow writes a row given by its changing elements packed 1 bit per pixel.
+func writeRow(w *bytes.Buffer, changes []int, cols int, blackIs1 bool) {
+	row := make([]byte, (cols+7)/8)
+
+	a0 := 0
+	for i, a1 := range changes {
+		if i%2 == 1 {
+			for x := a0; x < a1 && x < cols; x++ {
+				row[x/8] |= 0x80 >> uint(x%8)
+			}
+		}
+		a0 = a1
+	}
+
+	if !blackIs1 {
+		for i := range row {
+			row[i] = ^row[i]
+		}
+		// Clear the padding bits.
+		if cols%8 > 0 {
+			row[len(row)-1] &= 0xFF << uint(8-cols%8)
+		}
 	}
-	rd := ccitt.NewReader(r, ccitt.MSB, mode, cols, rows, opts)
 
-	var b bytes.Buffer
-	written, err := io.Copy(&b, rd)
+	w.Write(row)
+}
+
+func (f ccittDecode) Encode(r io.Reader) (io.Reader, error) {
+	return nil, nil
+}
+
+// Decode implements decoding for a CCITTFaxDecode filter supporting Group 3 (1D and mixed 1D/2D) and Group 4.
+func (f ccittDecode) Decode(r io.Reader) (io.Reader, error) {
+
+	log.Trace.Println("DecodeCCITT begin")
+
+	cols := 1728
+	if col, ok := f.parms["Columns"]; ok {
+		cols = col
+	}
+	if cols <= 0 {
+		return nil, errors.Errorf("pdfcpu: ccitt: invalid DecodeParam \"Columns\": %d", cols)
+	}
+
+	rows := f.parms["Rows"]
+
+	bb, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
-	log.Trace.Printf("DecodeCCITT: decoded %d bytes.\n", written)
+
+	d := ccittDecoder{
+		r:         ccittBitReader{bb: bb},
+		k:         f.parms["K"],
+		cols:      cols,
+		byteAlign: f.parms["EncodedByteAlign"] == 1,
+	}
+
+	blackIs1 := f.parms["BlackIs1"] == 1
+
+	var (
+		b   bytes.Buffer
+		ref []int
+		y   int
+	)
+
+	for ; rows == 0 || y < rows; y++ {
+		changes, err := d.decodeRow(ref)
+		if err == errCCITTEndOfBlock {
+			break
+		}
+		if err != nil {
+			if y == 0 {
+				return nil, err
+			}
+			// Keep the rows decoded so far.
+			log.Info.Printf("DecodeCCITT: row %d: %v\n", y, err)
+			break
+		}
+		writeRow(&b, changes, cols, blackIs1)
+		ref = changes
+	}
+
+	// Pad missing rows with white.
+	for ; y < rows; y++ {
+		writeRow(&b, nil, cols, blackIs1)
+	}
+
+	log.Trace.Printf("DecodeCCITT: decoded %d bytes.\n", b.Len())
 
 	return &b, nil
 }
@@ -0,0 +1,266 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"golang.org/x/image/ccitt"
+)
+
+type bitWriter struct {
+	bb []byte
+	n  int
+}
+
+func (w *bitWriter) write(code string) {
+	for i := 0; i < len(code); i++ {
+		if w.n%8 == 0 {
+			w.bb = append(w.bb, 0)
+		}
+		if code[i] == '1' {
+			w.bb[w.n/8] |= 0x80 >> uint(w.n%8)
+		}
+		w.n++
+	}
+}
+
+func (w *bitWriter) align() {
+	w.n = (w.n + 7) / 8 * 8
+}
+
+func codeFor(codes []ccittCode, run int) string {
+	for _, c := range codes {
+		if c.run == run {
+			return c.code
+		}
+	}
+	panic("no code")
+}
+
+func (w *bitWriter) run(n int, white bool) {
+	codes := ccittBlackCodes
+	if white {
+		codes = ccittWhiteCodes
+	}
+	for ; n >= 2560; n -= 2560 {
+		w.write(codeFor(codes, 2560))
+	}
+	if n >= 64 {
+		w.write(codeFor(codes, n/64*64))
+	}
+	w.write(codeFor(codes, n%64))
+}
+
+// changingElements returns the positions of the color changes of row starting with white.
+func changingElements(row []bool) []int {
+	var cc []int
+	black := false
+	for x, b := range row {
+		if b != black {
+			cc = append(cc, x)
+			black = b
+		}
+	}
+	return cc
+}
+
+func (w *bitWriter) row1D(row []bool) {
+	a0, white := 0, true
+	for _, a1 := range append(changingElements(row), len(row)) {
+		w.run(a1-a0, white)
+		a0, white = a1, !white
+	}
+}
+
+func (w *bitWriter) row2D(row []bool, ref []int) {
+	cols := len(row)
+	cur := append(changingElements(row), cols, cols)
+	ref = append(ref, cols, cols)
+
+	a0, white := -1, true
+	for a0 < cols {
+		i := 0
+		for i < len(cur)-2 && cur[i] <= a0 {
+			i++
+		}
+		a1, a2 := cur[i], cur[i+1]
+		j := 0
+		for j < len(ref)-2 && (ref[j] <= a0 || (j%2 == 0) != white) {
+			j++
+		}
+		b1, b2 := ref[j], ref[j+1]
+
+		switch {
+		case b2 < a1:
+			w.write(codeFor(ccittModeCodes, ccittPass))
+			a0 = b2
+		case a1-b1 >= -3 && a1-b1 <= 3:
+			for mode, d := range ccittVerticalOffsets {
+				if d == a1-b1 {
+					w.write(codeFor(ccittModeCodes, mode))
+				}
+			}
+			a0, white = a1, !white
+		default:
+			if a0 < 0 {
+				a0 = 0
+			}
+			w.write(codeFor(ccittModeCodes, ccittHorizontal))
+			w.run(a1-a0, white)
+			w.run(a2-a1, !white)
+			a0 = a2
+		}
+	}
+}
+
+// testImage returns rows of black (true) and white pixels.
+func testImage(cols, rows int) [][]bool {
+	img := make([][]bool, rows)
+	for y := range img {
+		img[y] = make([]bool, cols)
+		for x := range img[y] {
+			dx, dy := x-cols/2, y-rows/2
+			d := dx*dx + dy*dy
+			img[y][x] = d < rows*rows/5 && d > rows*rows/16 || x%37 < y%5 || y == 3 || x > cols-3
+		}
+	}
+	return img
+}
+
+func packed(img [][]bool, cols int) []byte {
+	var b bytes.Buffer
+	for _, row := range img {
+		writeRow(&b, append(changingElements(row), cols), cols, false)
+	}
+	return b.Bytes()
+}
+
+func encodeCCITT(img [][]bool, k int, eol, byteAlign bool) []byte {
+	w := &bitWriter{}
+	var ref []int
+	for y, row := range img {
+		if byteAlign {
+			w.align()
+		}
+		if eol {
+			w.write("000000000001")
+		}
+		switch {
+		case k < 0:
+			w.row2D(row, ref)
+		case k == 0:
+			w.row1D(row)
+		case y%k == 0:
+			w.write("1")
+			w.row1D(row)
+		default:
+			w.write("0")
+			w.row2D(row, ref)
+		}
+		ref = changingElements(row)
+	}
+
+	// RTC or EOFB
+	n := 6
+	if k < 0 {
+		n = 2
+	}
+	for i := 0; i < n; i++ {
+		w.write("000000000001")
+		if k > 0 {
+			w.write("1")
+		}
+	}
+	return w.bb
+}
+
+func TestCCITTDecode(t *testing.T) {
+	cols, rows := 203, 61
+	img := testImage(cols, rows)
+	want := packed(img, cols)
+
+	for _, tt := range []struct {
+		k              int
+		eol, byteAlign bool
+	}{
+		{-1, false, false},
+		{-1, false, true},
+		{0, false, false},
+		{0, true, false},
+		{0, true, true},
+		{2, false, false},
+		{4, true, false},
+		{4, true, true},
+	} {
+		enc := encodeCCITT(img, tt.k, tt.eol, tt.byteAlign)
+
+		byteAlign := 0
+		if tt.byteAlign {
+			byteAlign = 1
+		}
+		f := ccittDecode{baseFilter{map[string]int{"K": tt.k, "Columns": cols, "Rows": rows, "EncodedByteAlign": byteAlign}}}
+		r, err := f.Decode(bytes.NewReader(enc))
+		if err != nil {
+			t.Fatalf("K=%d: %v\n", tt.k, err)
+		}
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatalf("K=%d: %v\n", tt.k, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("K=%d eol=%t byteAlign=%t: decoded image mismatch\n", tt.k, tt.eol, tt.byteAlign)
+		}
+	}
+
+	// Cross check the test encoder with x/image/ccitt.
+	for _, k := range []int{-1, 0} {
+		mode := ccitt.Group4
+		if k == 0 {
+			mode = ccitt.Group3
+		}
+		enc := encodeCCITT(img, k, k == 0, false)
+		got, err := ioutil.ReadAll(ccitt.NewReader(bytes.NewReader(enc), ccitt.MSB, mode, cols, rows, nil))
+		if err != nil {
+			t.Fatalf("x/image K=%d: %v\n", k, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("x/image K=%d: decoded image mismatch\n", k)
+		}
+	}
+}
+
+func TestCCITTDecodeTruncated(t *testing.T) {
+	cols, rows := 100, 20
+	img := testImage(cols, rows)
+	enc := encodeCCITT(img, -1, false, false)
+
+	f := ccittDecode{baseFilter{map[string]int{"K": -1, "Columns": cols, "Rows": rows, "BlackIs1": 1}}}
+	r, err := f.Decode(bytes.NewReader(enc[:len(enc)/2]))
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if len(got) != rows*((cols+7)/8) {
+		t.Fatalf("want %d bytes, got %d\n", rows*((cols+7)/8), len(got))
+	}
+}
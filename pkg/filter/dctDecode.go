@@ -18,36 +18,31 @@ package filter
 
 import (
 	"bytes"
-	"encoding/gob"
-	"image/jpeg"
 	"io"
+	"io/ioutil"
 )
 
+// dctDecode passes JPEG data through unchanged.
+// Decoding the image samples is left to image processing.
 type dctDecode struct {
 	baseFilter
 }
 
+// passThrough returns the data of r unchanged.
+func passThrough(r io.Reader) (io.Reader, error) {
+	bb, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(bb), nil
+}
+
 // Encode implements encoding for a DCTDecode filter.
 func (f dctDecode) Encode(r io.Reader) (io.Reader, error) {
-
-	return nil, nil
+	return passThrough(r)
 }
 
 // Decode implements decoding for a DCTDecode filter.
 func (f dctDecode) Decode(r io.Reader) (io.Reader, error) {
-
-	im, err := jpeg.Decode(r)
-	if err != nil {
-		return nil, err
-	}
-
-	var b bytes.Buffer
-
-	enc := gob.NewEncoder(&b)
-
-	if err := enc.Encode(im); err != nil {
-		return nil, err
-	}
-
-	return &b, nil
+	return passThrough(r)
 }
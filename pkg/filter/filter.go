@@ -19,6 +19,8 @@ package filter
 
 import (
 	"io"
+	"strings"
+	"sync"
 
 	"github.com/pdfcpu/pdfcpu/pkg/log"
 	"github.com/pkg/errors"
@@ -35,8 +37,12 @@ const (
 	JBIG2     = "JBIG2Decode"
 	DCT       = "DCTDecode"
 	JPX       = "JPXDecode"
+	Crypt     = "Crypt"
 )
 
+// Abbreviated filter names, only valid for inline images. See also 8.9.7 in the PDF spec.
+var abbreviations = []string{"AHx", "A85", "LZW", "Fl", "RL", "CCF", "DCT"}
+
 // ErrUnsupportedFilter signals unsupported filter encountered.
 var ErrUnsupportedFilter = errors.New("pdfcpu: filter not supported")
 
@@ -72,8 +78,7 @@ func NewFilter(filterName string, parms map[string]int) (filter Filter, err erro
 		filter = dctDecode{baseFilter{parms}}
 
 	case JBIG2:
-		// Unsupported
-		fallthrough
+		filter = jbig2Decode{baseFilter{parms}}
 
 	case JPX:
 		// Unsupported
@@ -93,6 +98,114 @@ func List() []string {
 	return []string{ASCII85, ASCIIHex, RunLength, LZW, Flate}
 }
 
+// Supported returns true if pdfcpu is able to decode streams using filterName.
+// DCT and JBIG2 encoded data is passed through unchanged.
+func Supported(filterName string) bool {
+	switch filterName {
+	case ASCII85, ASCIIHex, RunLength, LZW, Flate, CCITTFax, JBIG2, DCT:
+		return true
+	}
+	return false
+}
+
+// Standard returns true if filterName denotes one of the filters defined by the PDF spec.
+func Standard(filterName string) bool {
+	switch filterName {
+	case ASCII85, ASCIIHex, RunLength, LZW, Flate, CCITTFax, JBIG2, DCT, JPX, Crypt:
+		return true
+	}
+	return false
+}
+
+// NonStandard returns true if filterName is a syntactically valid name of a filter not defined by the PDF spec.
+// Names resembling a standard filter name are considered misspelled and therefore not valid.
+func NonStandard(filterName string) bool {
+	if filterName == "" || Standard(filterName) {
+		return false
+	}
+
+	for i := 0; i < len(filterName); i++ {
+		c := filterName[i]
+		if c < 0x21 || c > 0x7E || strings.IndexByte("()<>[]{}/%#", c) >= 0 {
+			return false
+		}
+	}
+
+	for _, s := range abbreviations {
+		if strings.EqualFold(filterName, s) {
+			return false
+		}
+	}
+
+	for _, s := range []string{ASCII85, ASCIIHex, RunLength, LZW, Flate, CCITTFax, JBIG2, DCT, JPX, Crypt} {
+		if editDistance(strings.ToLower(filterName), strings.ToLower(s)) <= 2 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// editDistance returns the Levenshtein distance between s1 and s2.
+func editDistance(s1, s2 string) int {
+	d := make([]int, len(s2)+1)
+	for j := range d {
+		d[j] = j
+	}
+	for i := 1; i <= len(s1); i++ {
+		prev := d[0]
+		d[0] = i
+		for j := 1; j <= len(s2); j++ {
+			cur := d[j]
+			cost := 1
+			if s1[i-1] == s2[j-1] {
+				cost = 0
+			}
+			d[j] = min3(d[j]+1, d[j-1]+1, prev+cost)
+			prev = cur
+		}
+	}
+	return d[len(s2)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// Decoder decodes stream data for a filter pdfcpu does not support natively.
+type Decoder func(r io.Reader, parms map[string]int) (io.Reader, error)
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]Decoder{}
+)
+
+// RegisterDecoder registers an external decoder for filterName.
+// Registered decoders are used for reading if the configured filter policy asks for them.
+func RegisterDecoder(filterName string, d Decoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	if d == nil {
+		delete(decoders, filterName)
+		return
+	}
+	decoders[filterName] = d
+}
+
+// ExternalDecoder returns the decoder registered for filterName.
+func ExternalDecoder(filterName string) (Decoder, bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	d, ok := decoders[filterName]
+	return d, ok
+}
+
 type baseFilter struct {
 	parms map[string]int
 }
@@ -84,7 +84,7 @@ func TestFilterSupport(t *testing.T) {
 		{filter.Flate, nil},
 		{filter.CCITTFax, nil},
 		{filter.DCT, nil},
-		{filter.JBIG2, filter.ErrUnsupportedFilter},
+		{filter.JBIG2, nil},
 		{filter.JPX, filter.ErrUnsupportedFilter},
 		{"INVALID_FILTER", errors.New("Invalid filter: <INVALID_FILTER>")},
 	}
@@ -97,6 +97,51 @@ func TestFilterSupport(t *testing.T) {
 	}
 }
 
+func TestPassThroughFilters(t *testing.T) {
+	want := "\xFF\xD8\xFF\xE0 image data"
+	for _, filterName := range []string{filter.DCT, filter.JBIG2} {
+		f, err := filter.NewFilter(filterName, nil)
+		if err != nil {
+			t.Fatalf("%s: %v\n", filterName, err)
+		}
+		r, err := f.Decode(strings.NewReader(want))
+		if err != nil {
+			t.Fatalf("%s: %v\n", filterName, err)
+		}
+		bb, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatalf("%s: %v\n", filterName, err)
+		}
+		if string(bb) != want {
+			t.Errorf("%s: got % X, want % X\n", filterName, bb, want)
+		}
+	}
+}
+
+func TestNonStandardFilterNames(t *testing.T) {
+	for _, tt := range []struct {
+		filterName string
+		want       bool
+	}{
+		{filter.Flate, false},
+		{filter.JPX, false},
+		{"ReverseDecode", true},
+		{"ACME_Compress", true},
+		{"FlateDecod", false},
+		{"flatedecode", false},
+		{"DCTDecoder", false},
+		{"Fl", false},
+		{"AHx", false},
+		{"", false},
+		{"My Filter", false},
+		{"My(Filter)", false},
+	} {
+		if got := filter.NonStandard(tt.filterName); got != tt.want {
+			t.Errorf("NonStandard(%q): got %t, want %t\n", tt.filterName, got, tt.want)
+		}
+	}
+}
+
 func TestEncodeDecode(t *testing.T) {
 	for _, f := range filter.List() {
 		encodeDecodeUsingFilterNamed(t, f)
@@ -151,23 +151,41 @@ func validateRowFilter(f, p int) error {
 	return nil
 }
 
-func applyHorDiff(row []byte, colors int) ([]byte, error) {
-	// This works for 8 bits per color only.
-	for i := 1; i < len(row)/colors; i++ {
-		for j := 0; j < colors; j++ {
-			row[i*colors+j] += row[(i-1)*colors+j]
+func applyHorDiff(row []byte, colors, bpc, columns int) ([]byte, error) {
+	switch bpc {
+
+	case 8:
+		for i := colors; i < colors*columns; i++ {
+			row[i] += row[i-colors]
+		}
+
+	case 16:
+		for i := 2 * colors; i+1 < 2*colors*columns; i += 2 {
+			v := uint16(row[i])<<8 | uint16(row[i+1])
+			v += uint16(row[i-2*colors])<<8 | uint16(row[i-2*colors+1])
+			row[i], row[i+1] = byte(v>>8), byte(v)
+		}
+
+	default:
+		// 1, 2 or 4 bits per color component.
+		mask := byte(1<<uint(bpc) - 1)
+		shift := func(i int) uint { return uint(8 - bpc - i*bpc%8) }
+		for i := colors; i < colors*columns; i++ {
+			v := row[i*bpc/8]>>shift(i) + row[(i-colors)*bpc/8]>>shift(i-colors)
+			row[i*bpc/8] = row[i*bpc/8]&^(mask<<shift(i)) | (v&mask)<<shift(i)
 		}
 	}
+
 	return row, nil
 }
 
-func processRow(pr, cr []byte, p, colors, bytesPerPixel int) ([]byte, error) {
+func processRow(pr, cr []byte, p, colors, bpc, columns, bytesPerPixel int) ([]byte, error) {
 
 	//fmt.Printf("pr(%v) =\n%s\n", &pr, hex.Dump(pr))
 	//fmt.Printf("cr(%v) =\n%s\n", &cr, hex.Dump(cr))
 
 	if p == PredictorTIFF {
-		return applyHorDiff(cr, colors)
+		return applyHorDiff(cr, colors, bpc, columns)
 	}
 
 	// Apply the filter.
@@ -213,7 +231,7 @@ func processRow(pr, cr []byte, p, colors, bytesPerPixel int) ([]byte, error) {
 	return cdat, nil
 }
 
-func (f flate) parameters() (colors, bpc, columns int, err error) {
+func (f baseFilter) parameters() (colors, bpc, columns int, err error) {
 
 	// Colors, int
 	// The number of interleaved colour components per sample.
@@ -223,7 +241,7 @@ func (f flate) parameters() (colors, bpc, columns int, err error) {
 	if !found {
 		colors = 1
 	} else if colors == 0 {
-		return 0, 0, 0, errors.Errorf("pdfcpu: filter FlateDecode: \"Colors\" must be > 0")
+		return 0, 0, 0, errors.Errorf("pdfcpu: predictor: \"Colors\" must be > 0")
 	}
 
 	// BitsPerComponent, int
@@ -234,9 +252,7 @@ func (f flate) parameters() (colors, bpc, columns int, err error) {
 	if !found {
 		bpc = 8
 	} else if !intMemberOf(bpc, []int{1, 2, 4, 8, 16}) {
-		return 0, 0, 0, errors.Errorf("pdfcpu: filter FlateDecode: Unexpected \"BitsPerComponent\": %d", bpc)
-	} else if bpc != 8 {
-		return 0, 0, 0, errors.New("pdfcpu: filter FlateDecode: \"BitsPerComponent\" must be 8")
+		return 0, 0, 0, errors.Errorf("pdfcpu: predictor: Unexpected \"BitsPerComponent\": %d", bpc)
 	}
 
 	// Columns, int
@@ -249,8 +265,8 @@ func (f flate) parameters() (colors, bpc, columns int, err error) {
 	return colors, bpc, columns, nil
 }
 
-// decodePostProcess
-func (f flate) decodePostProcess(r io.Reader) (io.Reader, error) {
+// decodePostProcess reverses the optional prediction applied by FlateDecode and LZWDecode.
+func (f baseFilter) decodePostProcess(r io.Reader) (io.Reader, error) {
 
 	predictor, found := f.parms["Predictor"]
 	if !found || predictor == PredictorNo {
@@ -267,7 +283,7 @@ func (f flate) decodePostProcess(r io.Reader) (io.Reader, error) {
 			PredictorPaeth,
 			PredictorOptimum,
 		}) {
-		return nil, errors.Errorf("pdfcpu: filter FlateDecode: undefined \"Predictor\" %d", predictor)
+		return nil, errors.Errorf("pdfcpu: predictor: undefined \"Predictor\" %d", predictor)
 	}
 
 	colors, bpc, columns, err := f.parameters()
@@ -277,7 +293,9 @@ func (f flate) decodePostProcess(r io.Reader) (io.Reader, error) {
 
 	bytesPerPixel := (bpc*colors + 7) / 8
 
-	rowSize := bpc * colors * columns / 8
+	rowBytes := (bpc*colors*columns + 7) / 8
+
+	rowSize := rowBytes
 	if predictor != PredictorTIFF {
 		// PNG prediction uses a row filter byte prefixing the pixelbytes of a row.
 		rowSize++
@@ -305,10 +323,10 @@ func (f flate) decodePostProcess(r io.Reader) (io.Reader, error) {
 		}
 
 		if n != rowSize {
-			return nil, errors.Errorf("pdfcpu: filter FlateDecode: read error, expected %d bytes, got: %d", rowSize, n)
+			return nil, errors.Errorf("pdfcpu: predictor: read error, expected %d bytes, got: %d", rowSize, n)
 		}
 
-		d, err1 := processRow(pr, cr, predictor, colors, bytesPerPixel)
+		d, err1 := processRow(pr, cr, predictor, colors, bpc, columns, bytesPerPixel)
 		if err1 != nil {
 			return nil, err1
 		}
@@ -326,9 +344,9 @@ func (f flate) decodePostProcess(r io.Reader) (io.Reader, error) {
 		pr, cr = cr, pr
 	}
 
-	if b.Len()%(bpc*colors*columns/8) > 0 {
+	if b.Len()%rowBytes > 0 {
 		log.Info.Printf("failed postprocessing: %d %d\n", b.Len(), rowSize)
-		return nil, errors.New("pdfcpu: filter FlateDecode: postprocessing failed")
+		return nil, errors.New("pdfcpu: predictor: postprocessing failed")
 	}
 
 	return &b, nil
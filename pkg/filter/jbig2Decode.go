@@ -0,0 +1,35 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import "io"
+
+// jbig2Decode passes JBIG2 data through unchanged.
+// Any JBIG2Globals stream referenced in the decode parameters is left untouched.
+type jbig2Decode struct {
+	baseFilter
+}
+
+// Encode implements encoding for a JBIG2Decode filter.
+func (f jbig2Decode) Encode(r io.Reader) (io.Reader, error) {
+	return passThrough(r)
+}
+
+// Decode implements decoding for a JBIG2Decode filter.
+func (f jbig2Decode) Decode(r io.Reader) (io.Reader, error) {
+	return passThrough(r)
+}
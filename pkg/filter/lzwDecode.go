@@ -22,7 +22,6 @@ import (
 
 	"github.com/hhrutter/lzw"
 	"github.com/pdfcpu/pdfcpu/pkg/log"
-	"github.com/pkg/errors"
 )
 
 type lzwDecode struct {
@@ -58,11 +57,6 @@ func (f lzwDecode) Decode(r io.Reader) (io.Reader, error) {
 
 	log.Trace.Println("DecodeLZW begin")
 
-	p, found := f.parms["Predictor"]
-	if found && p > 1 {
-		return nil, errors.Errorf("DecodeLZW: unsupported predictor %d", p)
-	}
-
 	ec, ok := f.parms["EarlyChange"]
 	if !ok {
 		ec = 1
@@ -71,12 +65,6 @@ func (f lzwDecode) Decode(r io.Reader) (io.Reader, error) {
 	rc := lzw.NewReader(r, ec == 1)
 	defer rc.Close()
 
-	var b bytes.Buffer
-	written, err := io.Copy(&b, rc)
-	if err != nil {
-		return nil, err
-	}
-	log.Trace.Printf("DecodeLZW: decoded %d bytes.\n", written)
-
-	return &b, nil
+	// Optional decode parameters need postprocessing.
+	return f.decodePostProcess(rc)
 }
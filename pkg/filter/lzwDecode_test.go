@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func sample(row []byte, i, bpc int) int {
+	switch bpc {
+	case 8:
+		return int(row[i])
+	case 16:
+		return int(row[2*i])<<8 | int(row[2*i+1])
+	}
+	sh := uint(8 - bpc - i*bpc%8)
+	return int(row[i*bpc/8]>>sh) & (1<<uint(bpc) - 1)
+}
+
+func setSample(row []byte, i, bpc, v int) {
+	switch bpc {
+	case 8:
+		row[i] = byte(v)
+		return
+	case 16:
+		row[2*i], row[2*i+1] = byte(v>>8), byte(v)
+		return
+	}
+	mask := 1<<uint(bpc) - 1
+	sh := uint(8 - bpc - i*bpc%8)
+	row[i*bpc/8] = row[i*bpc/8]&^byte(mask<<sh) | byte((v&mask)<<sh)
+}
+
+// predict applies TIFF or PNG Up prediction to rows of raw data.
+func predict(raw []byte, predictor, colors, bpc, columns int) []byte {
+	rowBytes := (colors*bpc*columns + 7) / 8
+
+	var b bytes.Buffer
+	prev := make([]byte, rowBytes)
+	for y := 0; y*rowBytes < len(raw); y++ {
+		row := raw[y*rowBytes : (y+1)*rowBytes]
+		out := make([]byte, rowBytes)
+		if predictor == PredictorTIFF {
+			for i := 0; i < colors*columns; i++ {
+				v := sample(row, i, bpc)
+				if i >= colors {
+					v -= sample(row, i-colors, bpc)
+				}
+				setSample(out, i, bpc, v)
+			}
+		} else {
+			b.WriteByte(PNGUp)
+			for i := range row {
+				out[i] = row[i] - prev[i]
+			}
+		}
+		b.Write(out)
+		prev = row
+	}
+	return b.Bytes()
+}
+
+func TestLZWPredictors(t *testing.T) {
+	for _, tt := range []struct {
+		predictor, colors, bpc, columns int
+	}{
+		{PredictorTIFF, 3, 8, 17},
+		{PredictorTIFF, 1, 4, 15},
+		{PredictorTIFF, 2, 1, 13},
+		{PredictorTIFF, 1, 16, 9},
+		{PredictorUp, 3, 8, 17},
+		{PredictorOptimum, 1, 2, 21},
+	} {
+		rows := 7
+		rowBytes := (tt.colors*tt.bpc*tt.columns + 7) / 8
+		raw := make([]byte, rows*rowBytes)
+		for i := range raw {
+			raw[i] = byte(i * i / 3)
+		}
+		// Clear the padding bits of each row.
+		if pad := rowBytes*8 - tt.colors*tt.bpc*tt.columns; pad > 0 {
+			for y := 0; y < rows; y++ {
+				raw[(y+1)*rowBytes-1] &= 0xFF << uint(pad)
+			}
+		}
+
+		for _, ec := range []int{0, 1} {
+			parms := map[string]int{
+				"Predictor":        tt.predictor,
+				"Colors":           tt.colors,
+				"BitsPerComponent": tt.bpc,
+				"Columns":          tt.columns,
+				"EarlyChange":      ec,
+			}
+			f := lzwDecode{baseFilter{parms}}
+
+			enc, err := f.Encode(bytes.NewReader(predict(raw, tt.predictor, tt.colors, tt.bpc, tt.columns)))
+			if err != nil {
+				t.Fatalf("%v: %v\n", tt, err)
+			}
+			r, err := f.Decode(enc)
+			if err != nil {
+				t.Fatalf("%v: %v\n", tt, err)
+			}
+			got, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatalf("%v: %v\n", tt, err)
+			}
+			if !bytes.Equal(got, raw) {
+				t.Errorf("%v EarlyChange=%d: got\n% X\nwant\n% X\n", tt, ec, got, raw)
+			}
+		}
+	}
+}
@@ -20,13 +20,15 @@ import (
 	"bytes"
 	"io"
 	"io/ioutil"
+
+	"github.com/pkg/errors"
 )
 
 type runLengthDecode struct {
 	baseFilter
 }
 
-func (f runLengthDecode) decode(w io.ByteWriter, src []byte) {
+func (f runLengthDecode) decode(w io.ByteWriter, src []byte) error {
 
 	for i := 0; i < len(src); {
 		b := src[i]
@@ -37,12 +39,18 @@ func (f runLengthDecode) decode(w io.ByteWriter, src []byte) {
 		i++
 		if b < 0x80 {
 			c := int(b) + 1
+			if i+c > len(src) {
+				return errors.New("pdfcpu: runLength: truncated literal run")
+			}
 			for j := 0; j < c; j++ {
 				w.WriteByte(src[i])
 				i++
 			}
 			continue
 		}
+		if i >= len(src) {
+			return errors.New("pdfcpu: runLength: truncated repeat run")
+		}
 		c := 257 - int(b)
 		for j := 0; j < c; j++ {
 			w.WriteByte(src[i])
@@ -50,7 +58,7 @@ func (f runLengthDecode) decode(w io.ByteWriter, src []byte) {
 		i++
 	}
 
-	return
+	return nil
 }
 
 func (f runLengthDecode) encode(w io.ByteWriter, src []byte) {
@@ -58,6 +66,11 @@ func (f runLengthDecode) encode(w io.ByteWriter, src []byte) {
 	const maxLen = 0x80
 	const eod = 0x80
 
+	if len(src) == 0 {
+		w.WriteByte(eod)
+		return
+	}
+
 	i := 0
 	b := src[i]
 	start := i
@@ -135,7 +148,9 @@ func (f runLengthDecode) Decode(r io.Reader) (io.Reader, error) {
 	}
 
 	var b bytes.Buffer
-	f.decode(&b, p)
+	if err := f.decode(&b, p); err != nil {
+		return nil, err
+	}
 
 	return &b, nil
 }
@@ -76,3 +76,24 @@ func TestRunLengthEncoding(t *testing.T) {
 	}
 
 }
+
+func TestRunLengthDecodeCorrupt(t *testing.T) {
+
+	f := runLengthDecode{baseFilter{}}
+
+	for _, enc := range []string{
+		"\x02\x00\x01",
+		"\xFE",
+		"\x7F\x00",
+	} {
+		var raw bytes.Buffer
+		if err := f.decode(&raw, []byte(enc)); err == nil {
+			t.Errorf("% X: missing error for truncated data\n", enc)
+		}
+	}
+
+	var enc bytes.Buffer
+	f.encode(&enc, nil)
+	compare(t, enc.Bytes(), []byte("\x80"))
+
+}
@@ -28,6 +28,7 @@ import (
 	"strings"
 
 	"github.com/pdfcpu/pdfcpu/internal/corefont/metrics"
+	"github.com/pdfcpu/pdfcpu/pkg/log"
 	"github.com/pdfcpu/pdfcpu/pkg/types"
 )
 
@@ -223,6 +224,7 @@ func LineHeight(fontName string, fontSize int) float64 {
 	return UserSpaceUnits(fbb.Height(), fontSize)
 }
 
+// glyphSpaceWidth returns the width of text in glyph space units or 0 if fontName is not loaded.
 func glyphSpaceWidth(text, fontName string) int {
 	var w int
 	if IsCoreFont(fontName) {
@@ -232,8 +234,12 @@ func glyphSpaceWidth(text, fontName string) int {
 		}
 		return w
 	}
-	for _, r := range text {
-		w += CharWidth(fontName, r)
+	if !IsUserFont(fontName) {
+		log.Info.Printf("pdfcpu: user font not loaded: %s\n", fontName)
+		return 0
+	}
+	for _, g := range Shape(fontName, text) {
+		w += g.XAdvance
 	}
 	return w
 }
@@ -0,0 +1,332 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package font
+
+import (
+	"unicode"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Glyph represents a shaped glyph of a user font.
+// Advance and offsets are expressed in glyph space units.
+type Glyph struct {
+	GID              uint16
+	Cluster          int // Index of the first rune of the cluster this glyph belongs to.
+	XAdvance         int // Horizontal pen advance after rendering this glyph.
+	XOffset, YOffset int // Displacement of this glyph relative to the pen position.
+}
+
+// Shaper maps text to the glyphs of a user font applying the substitution and positioning rules of complex scripts.
+//
+// Shapers wrapping full featured shaping engines like HarfBuzz may be registered using SetShaper.
+type Shaper interface {
+	// Shape returns the glyphs for text in logical order.
+	Shape(fontName, text string) ([]Glyph, error)
+}
+
+// BasicShaper covers Arabic contextual forms and ligatures by means of the Unicode presentation forms
+// and the reordering of pre-base vowel signs of Indic scripts.
+// It relies on glyphs present in the font's cmap and does not process OpenType layout tables.
+type BasicShaper struct{}
+
+var shaper Shaper = BasicShaper{}
+
+// SetShaper registers the shaper used for rendering text using user fonts.
+// Passing nil restores the BasicShaper.
+func SetShaper(s Shaper) {
+	if s == nil {
+		s = BasicShaper{}
+	}
+	shaper = s
+}
+
+// Shape returns the glyphs for text rendered using the user font fontName in logical order.
+// If the registered shaper fails, text is shaped by the BasicShaper.
+func Shape(fontName, text string) []Glyph {
+	gg, err := shaper.Shape(fontName, text)
+	if err != nil {
+		gg, _ = BasicShaper{}.Shape(fontName, text)
+	}
+	return gg
+}
+
+// Arabic joining types.
+const (
+	joinNone = iota
+	joinRight
+	joinDual
+	joinCausing
+	joinTransparent
+)
+
+type arabicForms struct {
+	joining int
+	base    rune // Isolated presentation form followed by final, initial and medial forms.
+}
+
+var arabicLetters = map[rune]arabicForms{
+	0x0621: {joinNone, 0xFE80},
+	0x0622: {joinRight, 0xFE81},
+	0x0623: {joinRight, 0xFE83},
+	0x0624: {joinRight, 0xFE85},
+	0x0625: {joinRight, 0xFE87},
+	0x0626: {joinDual, 0xFE89},
+	0x0627: {joinRight, 0xFE8D},
+	0x0628: {joinDual, 0xFE8F},
+	0x0629: {joinRight, 0xFE93},
+	0x062A: {joinDual, 0xFE95},
+	0x062B: {joinDual, 0xFE99},
+	0x062C: {joinDual, 0xFE9D},
+	0x062D: {joinDual, 0xFEA1},
+	0x062E: {joinDual, 0xFEA5},
+	0x062F: {joinRight, 0xFEA9},
+	0x0630: {joinRight, 0xFEAB},
+	0x0631: {joinRight, 0xFEAD},
+	0x0632: {joinRight, 0xFEAF},
+	0x0633: {joinDual, 0xFEB1},
+	0x0634: {joinDual, 0xFEB5},
+	0x0635: {joinDual, 0xFEB9},
+	0x0636: {joinDual, 0xFEBD},
+	0x0637: {joinDual, 0xFEC1},
+	0x0638: {joinDual, 0xFEC5},
+	0x0639: {joinDual, 0xFEC9},
+	0x063A: {joinDual, 0xFECD},
+	0x0640: {joinCausing, 0},
+	0x0641: {joinDual, 0xFED1},
+	0x0642: {joinDual, 0xFED5},
+	0x0643: {joinDual, 0xFED9},
+	0x0644: {joinDual, 0xFEDD},
+	0x0645: {joinDual, 0xFEE1},
+	0x0646: {joinDual, 0xFEE5},
+	0x0647: {joinDual, 0xFEE9},
+	0x0648: {joinRight, 0xFEED},
+	0x0649: {joinRight, 0xFEEF},
+	0x064A: {joinDual, 0xFEF1},
+	0x067E: {joinDual, 0xFB56},
+	0x0686: {joinDual, 0xFB7A},
+	0x0698: {joinRight, 0xFB8A},
+	0x06A9: {joinDual, 0xFB8E},
+	0x06AF: {joinDual, 0xFB92},
+	0x06CC: {joinDual, 0xFBFC},
+}
+
+// Isolated forms of the lam alef ligatures by alef.
+var lamAlef = map[rune]rune{
+	0x0622: 0xFEF5,
+	0x0623: 0xFEF7,
+	0x0625: 0xFEF9,
+	0x0627: 0xFEFB,
+}
+
+const arabicLam = 0x0644
+
+func arabicJoining(r rune) int {
+	if f, ok := arabicLetters[r]; ok {
+		return f.joining
+	}
+	if unicode.Is(unicode.Mn, r) || r == 0x200D {
+		return joinTransparent
+	}
+	return joinNone
+}
+
+func joinsLeft(t int) bool {
+	return t == joinDual || t == joinCausing
+}
+
+func joinsRight(t int) bool {
+	return t == joinDual || t == joinRight || t == joinCausing
+}
+
+// neighbourJoining returns the joining type of the nearest non transparent rune in direction d.
+func neighbourJoining(rr []rune, i, d int) int {
+	for i += d; i >= 0 && i < len(rr); i += d {
+		if t := arabicJoining(rr[i]); t != joinTransparent {
+			return t
+		}
+	}
+	return joinNone
+}
+
+// Indic scripts sharing the ISCII based block layout along with their pre-base vowel signs.
+var indicPreBase = map[rune]bool{
+	0x093F: true, // Devanagari
+	0x09BF: true, // Bengali
+	0x09C7: true,
+	0x09C8: true,
+	0x0A3F: true, // Gurmukhi
+	0x0ABF: true, // Gujarati
+	0x0B47: true, // Oriya
+	0x0BC6: true, // Tamil
+	0x0BC7: true,
+	0x0BC8: true,
+	0x0D46: true, // Malayalam
+	0x0D47: true,
+	0x0D48: true,
+}
+
+func indicBlock(r rune) rune {
+	return r &^ 0x7F
+}
+
+func indicConsonant(r rune) bool {
+	o := r & 0x7F
+	return r >= 0x0900 && r < 0x0D80 && (o >= 0x15 && o <= 0x39 || o >= 0x58 && o <= 0x5F)
+}
+
+func indicVirama(r rune) bool {
+	return r >= 0x0900 && r < 0x0D80 && r&0x7F == 0x4D
+}
+
+func indicNukta(r rune) bool {
+	return r >= 0x0900 && r < 0x0D80 && r&0x7F == 0x3C
+}
+
+// syllableStart returns the index of the first consonant of the consonant cluster ending at i.
+func syllableStart(rr []rune, i int) int {
+	j := i
+	if j >= 0 && indicNukta(rr[j]) {
+		j--
+	}
+	if j < 0 || !indicConsonant(rr[j]) {
+		return -1
+	}
+	for j >= 2 && indicVirama(rr[j-1]) && indicConsonant(rr[j-2]) && indicBlock(rr[j-2]) == indicBlock(rr[j]) {
+		j -= 2
+	}
+	return j
+}
+
+type shapedRune struct {
+	r       rune
+	cluster int
+}
+
+// reorderIndic moves pre-base vowel signs in front of the consonant cluster they follow in logical order.
+func reorderIndic(rr []rune) []shapedRune {
+	sr := make([]shapedRune, len(rr))
+	for i, r := range rr {
+		sr[i] = shapedRune{r, i}
+	}
+	for i, r := range rr {
+		if !indicPreBase[r] {
+			continue
+		}
+		j := syllableStart(rr, i-1)
+		if j < 0 || indicBlock(rr[j]) != indicBlock(r) {
+			continue
+		}
+		copy(sr[j+1:i+1], sr[j:i])
+		sr[j] = shapedRune{r, j}
+		for k := j + 1; k <= i; k++ {
+			sr[k].cluster = j
+		}
+	}
+	return sr
+}
+
+// substituteArabic replaces Arabic letters by their contextual forms and lam alef sequences by ligatures
+// as long as the font provides corresponding glyphs.
+func substituteArabic(sr []shapedRune, chars map[uint32]uint16) []shapedRune {
+	rr := make([]rune, len(sr))
+	for i, s := range sr {
+		rr[i] = s.r
+	}
+
+	has := func(r rune) bool {
+		_, ok := chars[uint32(r)]
+		return ok
+	}
+
+	out := make([]shapedRune, 0, len(sr))
+	for i := 0; i < len(sr); i++ {
+		r := rr[i]
+		f, ok := arabicLetters[r]
+		if !ok || f.base == 0 {
+			out = append(out, sr[i])
+			continue
+		}
+
+		prev := f.joining != joinNone && joinsLeft(neighbourJoining(rr, i, -1))
+
+		if r == arabicLam && i+1 < len(rr) {
+			if lig, ok := lamAlef[rr[i+1]]; ok {
+				if prev {
+					lig++
+				}
+				if has(lig) {
+					out = append(out, shapedRune{lig, sr[i].cluster})
+					i++
+					continue
+				}
+			}
+		}
+
+		next := joinsLeft(f.joining) && joinsRight(neighbourJoining(rr, i, 1))
+
+		form := f.base
+		switch {
+		case prev && next:
+			form += 3
+		case next:
+			form += 2
+		case prev:
+			form++
+		}
+		if has(form) {
+			r = form
+		}
+		out = append(out, shapedRune{r, sr[i].cluster})
+	}
+	return out
+}
+
+// Shape returns the glyphs for text in logical order.
+// Runes not covered by the font are skipped.
+func (BasicShaper) Shape(fontName, text string) ([]Glyph, error) {
+	ttf, ok := UserFontMetrics[fontName]
+	if !ok {
+		return nil, errors.Errorf("pdfcpu: user font not loaded: %s", fontName)
+	}
+
+	sr := substituteArabic(reorderIndic([]rune(norm.NFC.String(text))), ttf.Chars)
+
+	gg := make([]Glyph, 0, len(sr))
+	for _, s := range sr {
+		gid, ok := ttf.Chars[uint32(s.r)]
+		if !ok {
+			continue
+		}
+		cluster := s.cluster
+		if len(gg) > 0 && unicode.Is(unicode.Mn, s.r) {
+			// Marks stay with their base.
+			cluster = gg[len(gg)-1].Cluster
+		}
+		gg = append(gg, Glyph{GID: gid, Cluster: cluster, XAdvance: ttf.GlyphWidth(gid)})
+	}
+	return gg, nil
+}
+
+// GlyphWidth returns the width of the glyph gid in glyph space units.
+func (fd TTFLight) GlyphWidth(gid uint16) int {
+	if int(gid) >= len(fd.GlyphWidths) {
+		return 0
+	}
+	return fd.GlyphWidths[gid]
+}
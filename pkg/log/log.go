@@ -18,8 +18,10 @@ limitations under the License.
 package log
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strings"
 )
 
 // Logger defines an interface for logging messages.
@@ -110,6 +112,150 @@ func SetCLILogger(log Logger) {
 	CLI.log = log
 }
 
+// Loggers holds a Logger for each of pdfcpu's subsystems.
+// A nil Logger turns off logging for the corresponding subsystem.
+type Loggers struct {
+	Debug    Logger
+	Info     Logger
+	Stats    Logger
+	Trace    Logger
+	Parse    Logger
+	Read     Logger
+	Validate Logger
+	Optimize Logger
+	Write    Logger
+	CLI      Logger
+}
+
+// SetLoggers sets the logger of every subsystem to the corresponding logger of l.
+func SetLoggers(l Loggers) {
+	SetDebugLogger(l.Debug)
+	SetInfoLogger(l.Info)
+	SetStatsLogger(l.Stats)
+	SetTraceLogger(l.Trace)
+	SetParseLogger(l.Parse)
+	SetReadLogger(l.Read)
+	SetValidateLogger(l.Validate)
+	SetOptimizeLogger(l.Optimize)
+	SetWriteLogger(l.Write)
+	SetCLILogger(l.CLI)
+}
+
+// ContextLoggers holds the info, read, validate, optimize and write loggers used while processing a single document.
+// The zero value logs via the package level loggers.
+type ContextLoggers struct {
+	info, read, validate, optimize, write *logger
+}
+
+// NewContextLoggers returns ContextLoggers using the loggers of l.
+// A nil l falls back to the package level loggers.
+func NewContextLoggers(l *Loggers) ContextLoggers {
+	if l == nil {
+		return ContextLoggers{}
+	}
+	return ContextLoggers{
+		info:     &logger{l.Info},
+		read:     &logger{l.Read},
+		validate: &logger{l.Validate},
+		optimize: &logger{l.Optimize},
+		write:    &logger{l.Write},
+	}
+}
+
+func pick(l, global *logger) *logger {
+	if l == nil {
+		return global
+	}
+	return l
+}
+
+// Info returns the info logger.
+func (c ContextLoggers) Info() Logger {
+	return pick(c.info, Info)
+}
+
+// Read returns the read logger.
+func (c ContextLoggers) Read() Logger {
+	return pick(c.read, Read)
+}
+
+// Validate returns the validate logger.
+func (c ContextLoggers) Validate() Logger {
+	return pick(c.validate, Validate)
+}
+
+// Optimize returns the optimize logger.
+func (c ContextLoggers) Optimize() Logger {
+	return pick(c.optimize, Optimize)
+}
+
+// Write returns the write logger.
+func (c ContextLoggers) Write() Logger {
+	return pick(c.write, Write)
+}
+
+// MessageFunc receives a log message without trailing newline along with the name of the subsystem logging it.
+type MessageFunc func(subsystem, msg string)
+
+// funcLogger adapts a MessageFunc to the Logger interface.
+type funcLogger struct {
+	subsystem string
+	f         MessageFunc
+}
+
+func (l funcLogger) Printf(format string, args ...interface{}) {
+	l.f(l.subsystem, strings.TrimSuffix(fmt.Sprintf(format, args...), "\n"))
+}
+
+func (l funcLogger) Println(args ...interface{}) {
+	l.f(l.subsystem, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (l funcLogger) Fatalf(format string, args ...interface{}) {
+	l.Printf(format, args...)
+	os.Exit(1)
+}
+
+func (l funcLogger) Fatalln(args ...interface{}) {
+	l.Println(args...)
+	os.Exit(1)
+}
+
+// FuncLoggers returns Loggers passing the messages of the given subsystems to f,
+// eg. for plugging in a structured logger. No subsystems means all subsystems except Parse and CLI.
+func FuncLoggers(f MessageFunc, subsystems ...string) Loggers {
+	if len(subsystems) == 0 {
+		subsystems = []string{"debug", "info", "stats", "trace", "read", "validate", "optimize", "write"}
+	}
+	var l Loggers
+	for _, s := range subsystems {
+		fl := funcLogger{subsystem: s, f: f}
+		switch s {
+		case "debug":
+			l.Debug = fl
+		case "info":
+			l.Info = fl
+		case "stats":
+			l.Stats = fl
+		case "trace":
+			l.Trace = fl
+		case "parse":
+			l.Parse = fl
+		case "read":
+			l.Read = fl
+		case "validate":
+			l.Validate = fl
+		case "optimize":
+			l.Optimize = fl
+		case "write":
+			l.Write = fl
+		case "cli":
+			l.CLI = fl
+		}
+	}
+	return l
+}
+
 // SetDefaultDebugLogger sets the default debug logger.
 func SetDefaultDebugLogger() {
 	SetDebugLogger(log.New(os.Stderr, "DEBUG: ", log.Ldate|log.Ltime))
@@ -193,6 +339,11 @@ func IsTraceLoggerEnabled() bool {
 	return Trace.log != nil
 }
 
+// IsParseLoggerEnabled returns true if the Parse Logger is enabled.
+func IsParseLoggerEnabled() bool {
+	return Parse.log != nil
+}
+
 // IsCLILoggerEnabled returns true if the CLI Logger is enabled.
 func IsCLILoggerEnabled() bool {
 	return CLI.log != nil
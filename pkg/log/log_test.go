@@ -30,3 +30,55 @@ func TestLog(t *testing.T) {
 	Debug.Println("Testlog")
 	DisableLoggers()
 }
+
+func TestFuncLoggers(t *testing.T) {
+
+	var got []string
+	SetLoggers(FuncLoggers(func(subsystem, msg string) {
+		got = append(got, subsystem+": "+msg)
+	}, "read", "write"))
+	defer DisableLoggers()
+
+	Read.Printf("obj#%d\n", 7)
+	Write.Println("done")
+	Validate.Println("ignored")
+
+	want := []string{"read: obj#7", "write: done"}
+	if len(got) != len(want) {
+		t.Fatalf("want %v, got %v\n", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("want %v, got %v\n", want, got)
+		}
+	}
+}
+
+func TestContextLoggers(t *testing.T) {
+
+	var global, own []string
+	SetLoggers(FuncLoggers(func(subsystem, msg string) {
+		global = append(global, subsystem+": "+msg)
+	}, "read", "write"))
+	defer DisableLoggers()
+
+	l := FuncLoggers(func(subsystem, msg string) {
+		own = append(own, subsystem+": "+msg)
+	}, "read")
+
+	// The zero value falls back to the package level loggers.
+	var c ContextLoggers
+	c.Read().Println("global")
+
+	c = NewContextLoggers(&l)
+	c.Read().Printf("obj#%d\n", 7)
+	c.Write().Println("ignored")
+	c.Info().Println("ignored")
+
+	if len(global) != 1 || global[0] != "read: global" {
+		t.Fatalf("want [read: global], got %v\n", global)
+	}
+	if len(own) != 1 || own[0] != "read: obj#7" {
+		t.Fatalf("want [read: obj#7], got %v\n", own)
+	}
+}
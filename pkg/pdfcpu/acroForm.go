@@ -0,0 +1,454 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// acroForm returns the interactive form dict of ctx.
+func (ctx *Context) acroForm() (Dict, error) {
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	return ctx.DereferenceDict(rootDict["AcroForm"])
+}
+
+// annotObjNrs returns the object numbers of all annotations of the pages objNrs.
+func (ctx *Context) annotObjNrs(objNrs []int) (IntSet, error) {
+	m := IntSet{}
+	for _, objNr := range objNrs {
+		d, err := ctx.DereferenceDict(*NewIndirectRef(objNr, 0))
+		if err != nil {
+			return nil, err
+		}
+		if d == nil {
+			continue
+		}
+		a, err := ctx.DereferenceArray(d["Annots"])
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range a {
+			if ir, ok := o.(IndirectRef); ok {
+				m[ir.ObjectNumber.Value()] = true
+			}
+		}
+	}
+	return m, nil
+}
+
+// pruneField removes all widgets of the field ir not contained in annots
+// and returns false if there is nothing left of this field.
+func (ctx *Context) pruneField(ir IndirectRef, annots, visited IntSet) (bool, error) {
+
+	objNr := ir.ObjectNumber.Value()
+	if visited[objNr] {
+		return false, errors.New("pdfcpu: corrupt field tree")
+	}
+	visited[objNr] = true
+
+	d, err := ctx.DereferenceDict(ir)
+	if err != nil || d == nil {
+		return false, err
+	}
+
+	kids := d.ArrayEntry("Kids")
+	if kids == nil {
+		// Terminal field merged with its widget.
+		return annots[objNr], nil
+	}
+
+	a := Array{}
+	for _, o := range kids {
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			continue
+		}
+		keep, err := ctx.pruneField(ir, annots, visited)
+		if err != nil {
+			return false, err
+		}
+		if keep {
+			a = append(a, ir)
+		}
+	}
+
+	if len(a) == 0 {
+		return false, nil
+	}
+
+	d["Kids"] = a
+
+	return true, nil
+}
+
+// pruneFields returns the fields having widgets contained in annots.
+func (ctx *Context) pruneFields(fields Array, annots IntSet) (Array, error) {
+	a := Array{}
+	visited := IntSet{}
+	for _, o := range fields {
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			continue
+		}
+		keep, err := ctx.pruneField(ir, annots, visited)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			a = append(a, ir)
+		}
+	}
+	return a, nil
+}
+
+// pruneCalculationOrder returns the calculation order co without fields which are gone.
+func (ctx *Context) pruneCalculationOrder(co Array, fields IntSet) Array {
+	a := Array{}
+	for _, o := range co {
+		if ir, ok := o.(IndirectRef); ok && fields[ir.ObjectNumber.Value()] {
+			a = append(a, ir)
+		}
+	}
+	return a
+}
+
+// fieldObjNrs returns the object numbers of all fields rooted at fields.
+func (ctx *Context) fieldObjNrs(fields Array, m IntSet) error {
+	for _, o := range fields {
+		ir, ok := o.(IndirectRef)
+		if !ok || m[ir.ObjectNumber.Value()] {
+			continue
+		}
+		m[ir.ObjectNumber.Value()] = true
+		d, err := ctx.DereferenceDict(ir)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			continue
+		}
+		if err := ctx.fieldObjNrs(d.ArrayEntry("Kids"), m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PruneAcroForm removes all form fields whose widgets are located on the pages objNrs
+// which are not going to be part of the result.
+// The calculation order of the remaining fields is preserved.
+func (ctx *Context) PruneAcroForm(objNrs IntSet) error {
+
+	d, err := ctx.acroForm()
+	if err != nil || d == nil || len(objNrs) == 0 {
+		return err
+	}
+
+	pageObjNrs, err := ctx.pageObjNrs()
+	if err != nil {
+		return err
+	}
+
+	kept := []int{}
+	for _, objNr := range pageObjNrs {
+		if !objNrs[objNr] {
+			kept = append(kept, objNr)
+		}
+	}
+
+	annots, err := ctx.annotObjNrs(kept)
+	if err != nil {
+		return err
+	}
+
+	fields, err := ctx.DereferenceArray(d["Fields"])
+	if err != nil {
+		return err
+	}
+
+	if fields, err = ctx.pruneFields(fields, annots); err != nil {
+		return err
+	}
+	d["Fields"] = fields
+
+	co, err := ctx.DereferenceArray(d["CO"])
+	if err != nil || co == nil {
+		return err
+	}
+
+	m := IntSet{}
+	if err := ctx.fieldObjNrs(fields, m); err != nil {
+		return err
+	}
+
+	if co = ctx.pruneCalculationOrder(co, m); len(co) == 0 {
+		d.Delete("CO")
+		return nil
+	}
+	d["CO"] = co
+
+	return nil
+}
+
+// migrateAcroForm adds the fields of ctx having widgets on the pages of ctxDest to the form of ctxDest.
+// migrated maps the object numbers of ctx onto the object numbers of the migrated objects.
+func migrateAcroForm(ctx, ctxDest *Context, pages []IndirectRef, migrated map[int]int) error {
+
+	dSrc, err := ctx.acroForm()
+	if err != nil || dSrc == nil {
+		return err
+	}
+
+	fieldsSrc, err := ctx.DereferenceArray(dSrc["Fields"])
+	if err != nil {
+		return err
+	}
+
+	// Widgets are migrated along with their pages taking their fields with them.
+	fields := Array{}
+	for _, o := range fieldsSrc {
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			continue
+		}
+		if objNr, found := migrated[ir.ObjectNumber.Value()]; found {
+			fields = append(fields, *NewIndirectRef(objNr, 0))
+		}
+	}
+
+	objNrs := make([]int, len(pages))
+	for i, ir := range pages {
+		objNrs[i] = ir.ObjectNumber.Value()
+	}
+
+	annots, err := ctxDest.annotObjNrs(objNrs)
+	if err != nil {
+		return err
+	}
+
+	if fields, err = ctxDest.pruneFields(fields, annots); err != nil {
+		return err
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	d, err := ctxDest.acroForm()
+	if err != nil {
+		return err
+	}
+
+	if d == nil {
+		d = Dict(map[string]Object{})
+		for _, k := range []string{"NeedAppearances", "SigFlags", "DA", "Q", "DR"} {
+			o, found := dSrc.Find(k)
+			if !found {
+				continue
+			}
+			if d[k], err = migrateObject(o, ctx, ctxDest, migrated); err != nil {
+				return err
+			}
+		}
+		ir, err := ctxDest.IndRefForNewObject(d)
+		if err != nil {
+			return err
+		}
+		rootDict, err := ctxDest.Catalog()
+		if err != nil {
+			return err
+		}
+		rootDict.Update("AcroForm", *ir)
+	}
+
+	fieldsDest, err := ctxDest.DereferenceArray(d["Fields"])
+	if err != nil {
+		return err
+	}
+	d["Fields"] = append(fieldsDest, fields...)
+
+	co, err := ctx.DereferenceArray(dSrc["CO"])
+	if err != nil || co == nil {
+		return err
+	}
+
+	m := IntSet{}
+	if err := ctxDest.fieldObjNrs(fields, m); err != nil {
+		return err
+	}
+
+	a := Array{}
+	for _, o := range co {
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			continue
+		}
+		if objNr, found := migrated[ir.ObjectNumber.Value()]; found && m[objNr] {
+			a = append(a, *NewIndirectRef(objNr, 0))
+		}
+	}
+
+	if len(a) > 0 {
+		coDest, err := ctxDest.DereferenceArray(d["CO"])
+		if err != nil {
+			return err
+		}
+		d["CO"] = append(coDest, a...)
+	}
+
+	return nil
+}
+
+// fieldName returns the fully qualified name of the field d.
+func (ctx *Context) fieldName(d Dict) (string, error) {
+
+	parts := []string{}
+	visited := IntSet{}
+
+	for d != nil {
+		if o, found := d.Find("T"); found {
+			s, err := ctx.DereferenceText(o)
+			if err != nil {
+				return "", err
+			}
+			parts = append([]string{s}, parts...)
+		}
+		ir := d.IndirectRefEntry("Parent")
+		if ir == nil {
+			break
+		}
+		if visited[ir.ObjectNumber.Value()] {
+			return "", errors.New("pdfcpu: corrupt field tree")
+		}
+		visited[ir.ObjectNumber.Value()] = true
+		var err error
+		if d, err = ctx.DereferenceDict(*ir); err != nil {
+			return "", err
+		}
+	}
+
+	return strings.Join(parts, "."), nil
+}
+
+// fieldsByName returns the indirect references of all named fields rooted at fields keyed by their fully qualified names.
+func (ctx *Context) fieldsByName(fields Array, m map[string]IndirectRef, visited IntSet) error {
+	for _, o := range fields {
+		ir, ok := o.(IndirectRef)
+		if !ok || visited[ir.ObjectNumber.Value()] {
+			continue
+		}
+		visited[ir.ObjectNumber.Value()] = true
+		d, err := ctx.DereferenceDict(ir)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			continue
+		}
+		if _, found := d.Find("T"); found {
+			s, err := ctx.fieldName(d)
+			if err != nil {
+				return err
+			}
+			if _, found := m[s]; !found {
+				m[s] = ir
+			}
+		}
+		if err := ctx.fieldsByName(d.ArrayEntry("Kids"), m, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CalculationOrder returns the fully qualified names of the fields in the order their calculation actions get executed.
+func (ctx *Context) CalculationOrder() ([]string, error) {
+
+	d, err := ctx.acroForm()
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	co, err := ctx.DereferenceArray(d["CO"])
+	if err != nil {
+		return nil, err
+	}
+
+	ss := []string{}
+	for _, o := range co {
+		d, err := ctx.DereferenceDict(o)
+		if err != nil {
+			return nil, err
+		}
+		if d == nil {
+			continue
+		}
+		s, err := ctx.fieldName(d)
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, s)
+	}
+
+	return ss, nil
+}
+
+// SetCalculationOrder sets the order in which the calculation actions of the fields fieldNames get executed.
+// Fields are identified by their fully qualified names. An empty list removes the calculation order.
+func (ctx *Context) SetCalculationOrder(fieldNames []string) error {
+
+	d, err := ctx.acroForm()
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.New("pdfcpu: no form available")
+	}
+
+	if len(fieldNames) == 0 {
+		d.Delete("CO")
+		return nil
+	}
+
+	fields, err := ctx.DereferenceArray(d["Fields"])
+	if err != nil {
+		return err
+	}
+
+	m := map[string]IndirectRef{}
+	if err := ctx.fieldsByName(fields, m, IntSet{}); err != nil {
+		return err
+	}
+
+	co := Array{}
+	for _, s := range fieldNames {
+		ir, found := m[s]
+		if !found {
+			return errors.Errorf("pdfcpu: unknown field: %s", s)
+		}
+		co = append(co, ir)
+	}
+
+	d["CO"] = co
+
+	return nil
+}
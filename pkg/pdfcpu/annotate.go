@@ -17,11 +17,11 @@ limitations under the License.
 package pdfcpu
 
 import (
+	"bytes"
 	"fmt"
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/pdfcpu/pdfcpu/pkg/log"
 	"github.com/pkg/errors"
@@ -84,7 +84,7 @@ var annotTypes = map[string]AnnotationType{
 	"Circle":         AnnCircle,
 	"Polygon":        AnnPolygon,
 	"PolyLine":       AnnPolyLine,
-	"HighLight":      AnnHighLight,
+	"Highlight":      AnnHighLight,
 	"Underline":      AnnUnderline,
 	"Squiggly":       AnnSquiggly,
 	"StrikeOut":      AnnStrikeOut,
@@ -114,7 +114,7 @@ var AnnotTypeStrings = map[AnnotationType]string{
 	AnnCircle:         "Circle",
 	AnnPolygon:        "Polygon",
 	AnnPolyLine:       "PolyLine",
-	AnnHighLight:      "HighLight",
+	AnnHighLight:      "Highlight",
 	AnnUnderline:      "Underline",
 	AnnSquiggly:       "Squiggly",
 	AnnStrikeOut:      "StrikeOut",
@@ -256,7 +256,7 @@ type MarkupAnnotation struct {
 	PopupIndRef  *IndirectRef // An indirect reference to a pop-up annotation for entering or editing the text associated with this annotation.
 	CA           *float64     // (Default: 1.0) The constant opacity value that shall be used in painting the annotation.
 	RC           string       // A rich text string that shall be displayed in the pop-up window when the annotation is opened.
-	CreationDate string       // The date and time when the annotation was created.
+	CreationDate string       // The date and time when the annotation was created, defaults to the time it gets added to a page.
 	Subj         string       // Text representing a short description of the subject being addressed by the annotation.
 }
 
@@ -275,13 +275,16 @@ func NewMarkupAnnotation(
 	ann := NewAnnotation(subType, rect, contents, pageIndRef, id, f, backgrCol)
 
 	return MarkupAnnotation{
-		Annotation:   ann,
-		T:            title,
-		PopupIndRef:  popupIndRef,
-		CreationDate: DateString(time.Now()),
-		CA:           ca,
-		RC:           rc,
-		Subj:         subject}
+		Annotation:  ann,
+		T:           title,
+		PopupIndRef: popupIndRef,
+		CA:          ca,
+		RC:          rc,
+		Subj:        subject}
+}
+
+func (ann MarkupAnnotation) creationDate() string {
+	return ann.CreationDate
 }
 
 // TextAnnotation represents a PDF text annotation aka "Sticky Note".
@@ -318,15 +321,17 @@ func (ann TextAnnotation) RenderDict(pageIndRef IndirectRef) Dict {
 		subject = ann.Subj
 	}
 	d := Dict(map[string]Object{
-		"Type":         Name("Annot"),
-		"Subtype":      Name(ann.TypeString()),
-		"Rect":         ann.Rect.Array(),
-		"P":            pageIndRef,
-		"F":            Integer(ann.F),
-		"CreationDate": StringLiteral(ann.CreationDate),
-		"Subj":         StringLiteral(subject),
-		"Open":         Boolean(ann.Open),
+		"Type":    Name("Annot"),
+		"Subtype": Name(ann.TypeString()),
+		"Rect":    ann.Rect.Array(),
+		"P":       pageIndRef,
+		"F":       Integer(ann.F),
+		"Subj":    StringLiteral(subject),
+		"Open":    Boolean(ann.Open),
 	})
+	if ann.CreationDate != "" {
+		d.InsertString("CreationDate", ann.CreationDate)
+	}
 	if ann.CA != nil {
 		d.Insert("CA", Float(*ann.CA))
 	}
@@ -422,6 +427,250 @@ func (ann LinkAnnotation) RenderDict(pageIndRef IndirectRef) Dict {
 	return d
 }
 
+// HighlightAnnotation represents a PDF highlight annotation.
+type HighlightAnnotation struct {
+	MarkupAnnotation
+	Quad QuadPoints // The highlighted regions, defaults to Rect.
+}
+
+// NewHighlightAnnotation returns a new highlight annotation.
+func NewHighlightAnnotation(
+	rect Rectangle,
+	quad QuadPoints,
+	contents, id, title string,
+	f AnnotationFlags,
+	col *SimpleColor,
+	ca *float64) HighlightAnnotation {
+
+	ma := NewMarkupAnnotation(AnnHighLight, rect, nil, contents, id, title, f, col, nil, ca, "", "")
+
+	return HighlightAnnotation{
+		MarkupAnnotation: ma,
+		Quad:             quad,
+	}
+}
+
+func (ann HighlightAnnotation) quadPoints() QuadPoints {
+	if len(ann.Quad) > 0 {
+		return ann.Quad
+	}
+	r := ann.Rect
+	// Upper left, upper right, lower left, lower right as expected by most viewers.
+	return QuadPoints{QuadLiteral{
+		P1: Point{r.LL.X, r.UR.Y},
+		P2: Point{r.UR.X, r.UR.Y},
+		P3: Point{r.LL.X, r.LL.Y},
+		P4: Point{r.UR.X, r.LL.Y},
+	}}
+}
+
+func (ann HighlightAnnotation) color() SimpleColor {
+	if ann.C != nil {
+		return *ann.C
+	}
+	return SimpleColor{R: 1, G: 1}
+}
+
+// RenderDict renders ann into a PDF annotation dict.
+func (ann HighlightAnnotation) RenderDict(pageIndRef IndirectRef) Dict {
+	c := ann.color()
+	qp := ann.quadPoints()
+
+	d := Dict(map[string]Object{
+		"Type":       Name("Annot"),
+		"Subtype":    Name(ann.TypeString()),
+		"Rect":       ann.Rect.Array(),
+		"P":          pageIndRef,
+		"F":          Integer(ann.F),
+		"C":          NewNumberArray(float64(c.R), float64(c.G), float64(c.B)),
+		"QuadPoints": qp.Array(),
+	})
+	if ann.CreationDate != "" {
+		d.InsertString("CreationDate", ann.CreationDate)
+	}
+	if ann.CA != nil {
+		d.Insert("CA", Float(*ann.CA))
+	}
+	if ann.Contents != "" {
+		d.InsertString("Contents", ann.Contents)
+	}
+	if ann.NM != "" {
+		d.InsertString("NM", ann.NM)
+	}
+	if ann.T != "" {
+		d.InsertString("T", ann.T)
+	}
+	return d
+}
+
+// creationDater is implemented by markup annotations carrying a creation date.
+type creationDater interface {
+	creationDate() string
+}
+
+// appearanceRenderer is implemented by annotations rendering their own normal appearance.
+type appearanceRenderer interface {
+	appearance(xRefTable *XRefTable) (*StreamDict, error)
+}
+
+func appearanceStreamDict(xRefTable *XRefTable, r Rectangle, buf []byte, resDict Dict) (*StreamDict, error) {
+	sd, _ := xRefTable.NewStreamDictForBuf(buf)
+	sd.InsertName("Type", "XObject")
+	sd.InsertName("Subtype", "Form")
+	sd.InsertInt("FormType", 1)
+	sd.Insert("BBox", r.Array())
+	sd.Insert("Matrix", NewIntegerArray(1, 0, 0, 1, 0, 0))
+	if resDict != nil {
+		sd.Insert("Resources", resDict)
+	}
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+	return sd, nil
+}
+
+// appearance renders a note icon filling ann's rect.
+func (ann TextAnnotation) appearance(xRefTable *XRefTable) (*StreamDict, error) {
+	c := SimpleColor{R: 1, G: 1}
+	if ann.C != nil {
+		c = *ann.C
+	}
+	r := ann.Rect
+	x, y, w, h := r.LL.X, r.LL.Y, r.Width(), r.Height()
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "q %.2f %.2f %.2f rg 0 G 1 w %.2f %.2f %.2f %.2f re B ", c.R, c.G, c.B, x+.5, y+.5, w-1, h-1)
+	for i := 1; i <= 3; i++ {
+		yl := y + h*float64(i)/4
+		fmt.Fprintf(&b, "%.2f %.2f m %.2f %.2f l ", x+w*.2, yl, x+w*.8, yl)
+	}
+	b.WriteString("S Q")
+
+	return appearanceStreamDict(xRefTable, r, b.Bytes(), nil)
+}
+
+// appearance renders ann's highlighted regions using the multiply blend mode.
+func (ann HighlightAnnotation) appearance(xRefTable *XRefTable) (*StreamDict, error) {
+	c := ann.color()
+
+	gs := Dict(map[string]Object{
+		"Type": Name("ExtGState"),
+		"BM":   Name("Multiply"),
+	})
+	if ann.CA != nil {
+		gs.Insert("ca", Float(*ann.CA))
+	}
+	resDict := Dict(map[string]Object{
+		"ExtGState": Dict(map[string]Object{"GS0": gs}),
+	})
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "q /GS0 gs %.2f %.2f %.2f rg ", c.R, c.G, c.B)
+	for _, ql := range ann.quadPoints() {
+		r := ql.EnclosingRectangle(0)
+		fmt.Fprintf(&b, "%.2f %.2f %.2f %.2f re ", r.LL.X, r.LL.Y, r.Width(), r.Height())
+	}
+	b.WriteString("f Q")
+
+	return appearanceStreamDict(xRefTable, ann.Rect, b.Bytes(), resDict)
+}
+
+// PageAnnotation describes an annotation of a page.
+type PageAnnotation struct {
+	Page     int             `json:"page"`
+	ObjNr    int             `json:"objNr,omitempty"` // 0 for annotation dicts not being indirect objects.
+	Type     string          `json:"type"`
+	Rect     Rectangle       `json:"rect"`
+	ID       string          `json:"id,omitempty"`
+	Contents string          `json:"contents,omitempty"`
+	Flags    AnnotationFlags `json:"flags"`
+}
+
+// ParseAnnotationType returns the annotation type for s ignoring case.
+func ParseAnnotationType(s string) (AnnotationType, error) {
+	for k, v := range annotTypes {
+		if strings.EqualFold(k, s) {
+			return v, nil
+		}
+	}
+	return 0, errors.Errorf("pdfcpu: unknown annotation type: %s", s)
+}
+
+// PageAnnotations returns the annotations of selected pages in page order.
+func (ctx *Context) PageAnnotations(selectedPages IntSet) ([]PageAnnotation, error) {
+	var aa []PageAnnotation
+
+	for pageNr := 1; pageNr <= ctx.PageCount; pageNr++ {
+		if selectedPages != nil {
+			if _, found := selectedPages[pageNr]; !found {
+				continue
+			}
+		}
+
+		pageDict, _, _, err := ctx.PageDict(pageNr, false)
+		if err != nil {
+			return nil, err
+		}
+
+		o, found := pageDict.Find("Annots")
+		if !found {
+			continue
+		}
+
+		annots, err := ctx.DereferenceArray(o)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, o := range annots {
+			var objNr int
+			if ir, ok := o.(IndirectRef); ok {
+				objNr = ir.ObjectNumber.Value()
+			}
+
+			d, err := ctx.DereferenceDict(o)
+			if err != nil {
+				return nil, err
+			}
+			if d == nil || d.Subtype() == nil {
+				continue
+			}
+
+			ar, err := ctx.Annotation(d)
+			if err != nil {
+				return nil, err
+			}
+
+			o, _ := d.Find("Rect")
+			arr, err := ctx.DereferenceArray(o)
+			if err != nil {
+				return nil, err
+			}
+			r, err := RectForArray(arr)
+			if err != nil {
+				return nil, err
+			}
+
+			var f AnnotationFlags
+			if i := d.IntEntry("F"); i != nil {
+				f = AnnotationFlags(*i)
+			}
+
+			aa = append(aa, PageAnnotation{
+				Page:     pageNr,
+				ObjNr:    objNr,
+				Type:     *d.Subtype(),
+				Rect:     *r,
+				ID:       ar.ID(),
+				Contents: ar.ContentString(),
+				Flags:    f,
+			})
+		}
+	}
+
+	return aa, nil
+}
+
 // AnnotationObjNrs returns a list of object numbers representing known annotation dict indirect references.
 func (ctx *Context) AnnotationObjNrs() ([]int, error) {
 	// Note: Not all cached annotations are based on IndRefs!
@@ -512,8 +761,29 @@ func (ctx *Context) findAnnotByObjNr(objNr int, annots Array) (int, error) {
 	return -1, nil
 }
 
-func (ctx *Context) createAnnot(ar AnnotationRenderer, pageIndRef *IndirectRef) (*IndirectRef, error) {
+func (ctx *Context) createAnnot(ar AnnotationRenderer, pageIndRef *IndirectRef, incr bool) (*IndirectRef, error) {
 	d := ar.RenderDict(*pageIndRef)
+
+	if cd, ok := ar.(creationDater); ok && cd.creationDate() == "" {
+		d.InsertString("CreationDate", DateString(ctx.Now()))
+	}
+
+	if apr, ok := ar.(appearanceRenderer); ok {
+		sd, err := apr.appearance(ctx.XRefTable)
+		if err != nil {
+			return nil, err
+		}
+		ir, err := ctx.IndRefForNewObject(*sd)
+		if err != nil {
+			return nil, err
+		}
+		if incr {
+			// Mark appearance stream obj for incremental writing.
+			ctx.Write.IncrementWithObjNr(ir.ObjectNumber.Value())
+		}
+		d.Insert("AP", Dict(map[string]Object{"N": *ir}))
+	}
+
 	return ctx.IndRefForNewObject(d)
 }
 
@@ -578,6 +848,9 @@ func (xRefTable *XRefTable) Annotation(d Dict) (AnnotationRenderer, error) {
 		}
 		ann = NewLinkAnnotation(*r, nil, uri, nm, f, nil)
 
+	case "Highlight":
+		ann = NewHighlightAnnotation(*r, nil, contents, nm, "", f, nil, nil)
+
 	case "Popup":
 		parentIndRef := d.IndirectRefEntry("Parent")
 		ann = NewPopupAnnotation(*r, nil, contents, nm, f, nil, parentIndRef)
@@ -690,7 +963,7 @@ func (ctx *Context) addAnnotationToDirectObj(
 // AddAnnotation adds ar to pageDict.
 func (ctx *Context) AddAnnotation(pageDictIndRef *IndirectRef, pageDict Dict, pageNr int, ar AnnotationRenderer, incr bool) (bool, error) {
 	// Create xreftable entry for annotation.
-	annotIndRef, err := ctx.createAnnot(ar, pageDictIndRef)
+	annotIndRef, err := ctx.createAnnot(ar, pageDictIndRef, incr)
 	if err != nil {
 		return false, err
 	}
@@ -1124,3 +1397,30 @@ func (ctx *Context) RemoveAnnotations(selectedPages IntSet, ids []string, objNrs
 
 	return removed, nil
 }
+
+// RemoveAnnotationsByType removes all annotations of given types for selected pages.
+func (ctx *Context) RemoveAnnotationsByType(selectedPages IntSet, types []AnnotationType, incr bool) (bool, error) {
+	aa, err := ctx.PageAnnotations(selectedPages)
+	if err != nil {
+		return false, err
+	}
+
+	var objNrs []int
+	for _, a := range aa {
+		if a.ObjNr == 0 {
+			continue
+		}
+		for _, t := range types {
+			if a.Type == AnnotTypeStrings[t] {
+				objNrs = append(objNrs, a.ObjNr)
+				break
+			}
+		}
+	}
+
+	if len(objNrs) == 0 {
+		return false, nil
+	}
+
+	return ctx.RemoveAnnotations(selectedPages, nil, objNrs, incr)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var errInvalidAnnotationConfig = errors.New("pdfcpu: invalid annotation configuration string")
+
+// annotationDetails collects the parameters of an annotation to be added.
+type annotationDetails struct {
+	typ      string
+	rect     *Rectangle
+	contents string
+	id       string
+	title    string
+	uri      string
+	icon     string
+	open     bool
+	col      *SimpleColor
+	opacity  *float64
+	unit     DisplayUnit
+}
+
+type annotationParamMap map[string]func(string, *annotationDetails) error
+
+var annParamMap = annotationParamMap{
+	"type":     parseAnnotationTypeParam,
+	"rect":     parseAnnotationRect,
+	"contents": func(s string, ad *annotationDetails) error { ad.contents = s; return nil },
+	"id":       func(s string, ad *annotationDetails) error { ad.id = s; return nil },
+	"title":    func(s string, ad *annotationDetails) error { ad.title = s; return nil },
+	"uri":      func(s string, ad *annotationDetails) error { ad.uri = s; return nil },
+	"icon":     func(s string, ad *annotationDetails) error { ad.icon = s; return nil },
+	"open":     parseAnnotationOpen,
+	"color":    parseAnnotationColor,
+	"opacity":  parseAnnotationOpacity,
+}
+
+// Handle applies parameter completion and if successful
+// parses the parameter values into ad.
+func (m annotationParamMap) Handle(paramPrefix, paramValueStr string, ad *annotationDetails) error {
+	var param string
+
+	// Completion support
+	for k := range m {
+		if !strings.HasPrefix(k, paramPrefix) {
+			continue
+		}
+		if len(param) > 0 {
+			return errors.Errorf("pdfcpu: ambiguous parameter prefix \"%s\"", paramPrefix)
+		}
+		param = k
+	}
+
+	if param == "" {
+		return errors.Errorf("pdfcpu: unknown parameter prefix \"%s\"", paramPrefix)
+	}
+
+	return m[param](paramValueStr, ad)
+}
+
+func parseAnnotationTypeParam(s string, ad *annotationDetails) error {
+	t, err := ParseAnnotationType(s)
+	if err != nil {
+		return err
+	}
+	switch t {
+	case AnnLink, AnnText, AnnHighLight:
+		ad.typ = AnnotTypeStrings[t]
+		return nil
+	}
+	return errors.Errorf("pdfcpu: annotation type, please provide one of: link, text, highlight")
+}
+
+func parseAnnotationRect(s string, ad *annotationDetails) error {
+	b, err := parseBoxByRectangle(s, ad.unit)
+	if err != nil {
+		return errors.Errorf("pdfcpu: annotation rect, please provide: \"llx lly urx ury\", got: %s", s)
+	}
+	ad.rect = b.Rect
+	return nil
+}
+
+func parseAnnotationOpen(s string, ad *annotationDetails) error {
+	switch strings.ToLower(s) {
+	case "on", "true", "t":
+		ad.open = true
+	case "off", "false", "f":
+		ad.open = false
+	default:
+		return errors.New("pdfcpu: annotation open, please provide one of: on/off true/false t/f")
+	}
+	return nil
+}
+
+func parseAnnotationColor(s string, ad *annotationDetails) error {
+	c, err := parseColor(s)
+	if err != nil {
+		return err
+	}
+	ad.col = &c
+	return nil
+}
+
+func parseAnnotationOpacity(s string, ad *annotationDetails) error {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 || f > 1 {
+		return errors.Errorf("pdfcpu: annotation opacity, please provide a value between 0.0 and 1.0, got: %s", s)
+	}
+	ad.opacity = &f
+	return nil
+}
+
+// ParseAnnotationDetails parses an annotation description into an annotation renderer.
+// Supported types are link, text and highlight, eg.
+//
+//	type:link, rect:100 100 200 120, uri:https://pdfcpu.io
+//	type:text, rect:50 700 70 720, contents:Please review, color:#FFFF00
+//	type:highlight, rect:72 600 300 614, color:0 1 0, opacity:.5
+func ParseAnnotationDetails(s string, u DisplayUnit) (AnnotationRenderer, error) {
+	if s == "" {
+		return nil, errInvalidAnnotationConfig
+	}
+
+	ad := &annotationDetails{unit: u}
+
+	for _, s := range strings.Split(s, ",") {
+
+		// Values may contain colons eg. uri:https://pdfcpu.io
+		ss := strings.SplitN(s, ":", 2)
+		if len(ss) != 2 {
+			return nil, errInvalidAnnotationConfig
+		}
+
+		paramPrefix := strings.TrimSpace(ss[0])
+		paramValueStr := strings.TrimSpace(ss[1])
+
+		if err := annParamMap.Handle(paramPrefix, paramValueStr, ad); err != nil {
+			return nil, err
+		}
+	}
+
+	if ad.typ == "" {
+		return nil, errors.New("pdfcpu: missing annotation type")
+	}
+
+	if ad.rect == nil {
+		return nil, errors.New("pdfcpu: missing annotation rect")
+	}
+
+	switch ad.typ {
+
+	case "Link":
+		if ad.uri == "" {
+			return nil, errors.New("pdfcpu: missing uri for link annotation")
+		}
+		return NewLinkAnnotation(*ad.rect, nil, ad.uri, ad.id, AnnPrint, ad.col), nil
+
+	case "Text":
+		name := "Note"
+		if ad.icon != "" {
+			name = ad.icon
+		}
+		return NewTextAnnotation(*ad.rect, ad.contents, ad.id, ad.title, AnnPrint|AnnNoZoom|AnnNoRotate, ad.col, ad.opacity, "", "", ad.open, name), nil
+	}
+
+	return NewHighlightAnnotation(*ad.rect, nil, ad.contents, ad.id, ad.title, AnnPrint, ad.col, ad.opacity), nil
+}
@@ -199,6 +199,12 @@ func (a Array) PDFString() string {
 			continue
 		}
 
+		ff, ok := entry.(formattedFloat)
+		if ok {
+			logstr = append(logstr, fmt.Sprintf("%s%s", sepstr, ff.PDFString()))
+			continue
+		}
+
 		b, ok := entry.(Boolean)
 		if ok {
 			logstr = append(logstr, fmt.Sprintf("%s%s", sepstr, b.PDFString()))
@@ -18,6 +18,7 @@ package pdfcpu
 
 import (
 	"bytes"
+	"crypto/md5"
 	"fmt"
 	"io"
 	"time"
@@ -51,6 +52,83 @@ func decodeFileSpecStreamDict(sd *StreamDict, id string) error {
 	return sd.Decode()
 }
 
+func embeddedFileCheckSum(xRefTable *XRefTable, o Object) ([]byte, error) {
+	o, err := xRefTable.Dereference(o)
+	if err != nil {
+		return nil, err
+	}
+
+	switch s := o.(type) {
+	case StringLiteral:
+		return Unescape(s.Value())
+	case HexLiteral:
+		return s.Bytes()
+	}
+
+	return nil, errors.New("pdfcpu: embeddedFileCheckSum: string expected")
+}
+
+// CheckEmbeddedFileParams verifies the optional Size and CheckSum entries of an embedded file stream
+// against its content. Embedded files using unsupported filters are skipped.
+func (xRefTable *XRefTable) CheckEmbeddedFileParams(sd *StreamDict) error {
+	d, err := xRefTable.DereferenceDict(sd.Dict["Params"])
+	if err != nil || d == nil {
+		return err
+	}
+
+	size := d.IntEntry("Size")
+	o, hasCheckSum := d.Find("CheckSum")
+	if size == nil && !hasCheckSum {
+		return nil
+	}
+
+	sd1 := *sd
+	if err := sd1.Decode(); err != nil {
+		if err == filter.ErrUnsupportedFilter {
+			return nil
+		}
+		return err
+	}
+
+	if size != nil && *size != len(sd1.Content) {
+		return errors.Errorf("pdfcpu: embedded file size mismatch: %d, want %d", len(sd1.Content), *size)
+	}
+
+	if hasCheckSum {
+		want, err := embeddedFileCheckSum(xRefTable, o)
+		if err != nil {
+			return err
+		}
+		if got := md5.Sum(sd1.Content); !bytes.Equal(got[:], want) {
+			return errors.New("pdfcpu: embedded file checksum mismatch")
+		}
+	}
+
+	return nil
+}
+
+// UpdateEmbeddedFileParams (re)computes and stores Size and CheckSum of an embedded file stream.
+func (xRefTable *XRefTable) UpdateEmbeddedFileParams(sd *StreamDict) error {
+	if err := sd.Decode(); err != nil {
+		return err
+	}
+
+	d, err := xRefTable.DereferenceDict(sd.Dict["Params"])
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		d = NewDict()
+		sd.Insert("Params", d)
+	}
+
+	sum := md5.Sum(sd.Content)
+	d.Update("Size", Integer(len(sd.Content)))
+	d.Update("CheckSum", NewHexLiteral(sum[:]))
+
+	return nil
+}
+
 func fileSpectStreamFileName(xRefTable *XRefTable, d Dict) (string, error) {
 	o, found := d.Find("UF")
 	if found {
@@ -189,7 +267,7 @@ func (ctx *Context) AddAttachment(a Attachment, useCollection bool) error {
 		}
 	}
 
-	ir, err := xRefTable.NewFileSpectDictForAttachment(a)
+	ir, err := xRefTable.NewFileSpectDictForAttachment(a, ctx.AttachmentCheckSums)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,192 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// AuditLogID is the id of the attachment holding the audit log.
+const AuditLogID = "pdfcpu_audit.json"
+
+// AuditEntry records an operation pdfcpu applied to a document.
+type AuditEntry struct {
+	Operation  string            `json:"operation"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+	Timestamp  string            `json:"timestamp"` // RFC 3339
+	Version    string            `json:"version"`   // pdfcpu version
+}
+
+var commandNames = map[CommandMode]string{
+	VALIDATE:                "validate",
+	OPTIMIZE:                "optimize",
+	SPLIT:                   "split",
+	MERGECREATE:             "merge create",
+	MERGEAPPEND:             "merge append",
+	EXTRACTIMAGES:           "extract images",
+	EXTRACTFONTS:            "extract fonts",
+	EXTRACTPAGES:            "extract pages",
+	EXTRACTCONTENT:          "extract content",
+	EXTRACTMETADATA:         "extract metadata",
+	TRIM:                    "trim",
+	ADDATTACHMENTS:          "add attachments",
+	ADDATTACHMENTSPORTFOLIO: "add portfolio entries",
+	REMOVEATTACHMENTS:       "remove attachments",
+	EXTRACTATTACHMENTS:      "extract attachments",
+	LISTATTACHMENTS:         "list attachments",
+	SETPERMISSIONS:          "set permissions",
+	LISTPERMISSIONS:         "list permissions",
+	ENCRYPT:                 "encrypt",
+	DECRYPT:                 "decrypt",
+	CHANGEUPW:               "change user password",
+	CHANGEOPW:               "change owner password",
+	ADDWATERMARKS:           "add watermarks",
+	REMOVEWATERMARKS:        "remove watermarks",
+	IMPORTIMAGES:            "import images",
+	INSERTPAGESBEFORE:       "insert pages before",
+	INSERTPAGESAFTER:        "insert pages after",
+	REMOVEPAGES:             "remove pages",
+	ROTATE:                  "rotate",
+	NUP:                     "nup",
+	BOOKLET:                 "booklet",
+	INFO:                    "info",
+	LISTKEYWORDS:            "list keywords",
+	ADDKEYWORDS:             "add keywords",
+	REMOVEKEYWORDS:          "remove keywords",
+	LISTPROPERTIES:          "list properties",
+	ADDPROPERTIES:           "add properties",
+	REMOVEPROPERTIES:        "remove properties",
+	COLLECT:                 "collect",
+	CROP:                    "crop",
+	LISTBOXES:               "list boxes",
+	ADDBOXES:                "add boxes",
+	REMOVEBOXES:             "remove boxes",
+	LISTANNOTATIONS:         "list annotations",
+	ADDANNOTATIONS:          "add annotations",
+	REMOVEANNOTATIONS:       "remove annotations",
+	ADDBOOKMARKS:            "add bookmarks",
+	LISTIMAGES:              "list images",
+	MANIFEST:                "manifest",
+	VERIFY:                  "verify",
+	INTERNALIZE:             "internalize",
+	ENCRYPTIONINFO:          "encryption info",
+	CLIP:                    "clip",
+	LABELS:                  "labels",
+	DECORATE:                "decorate",
+	AUTOCROP:                "autocrop",
+	ROTATECONTENT:           "rotate content",
+	LISTFORMORDER:           "list form order",
+	SETFORMORDER:            "set form order",
+	EXTRACTTEXT:             "extract text",
+	EXTRACTTABLES:           "extract tables",
+	DUPLICATES:              "duplicates",
+	RUN:                     "run",
+	LISTFORMFIELDS:          "list form fields",
+	EXPORTFORMFIELDS:        "export form fields",
+	FILLFORMFIELDS:          "fill form",
+	FILLTEMPLATE:            "fill template",
+	PAGESIZES:               "page sizes",
+	ORPHANS:                 "orphans",
+	RESIZE:                  "resize",
+	VALIDATESIGNATURES:      "validate signatures",
+	FEATURES:                "features",
+	PREVIEW:                 "preview",
+	EXTRACTSVG:              "extract svg",
+}
+
+func commandName(cmd CommandMode) string {
+	if s, ok := commandNames[cmd]; ok {
+		return s
+	}
+	return "command " + strconv.Itoa(int(cmd))
+}
+
+// auditLog returns the id of the name tree entry holding the audit log and its file spec dict.
+func (ctx *Context) auditLog() (*string, Object, error) {
+	if err := ctx.LocateNameTree("EmbeddedFiles", false); err != nil {
+		return nil, nil, err
+	}
+	if ctx.Names["EmbeddedFiles"] == nil {
+		return nil, nil, nil
+	}
+	return ctx.SearchEmbeddedFilesNameTreeNodeByContent(AuditLogID)
+}
+
+// AuditEntries returns the audit log of ctx in chronological order.
+func (ctx *Context) AuditEntries() ([]AuditEntry, error) {
+	k, o, err := ctx.auditLog()
+	if err != nil || k == nil {
+		return nil, err
+	}
+
+	sd, _, _, _, err := fileSpecStreamDictInfo(ctx.XRefTable, *k, o, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var ee []AuditEntry
+	if err := json.Unmarshal(sd.Content, &ee); err != nil {
+		return nil, err
+	}
+
+	return ee, nil
+}
+
+// appendAuditEntry records the command in progress in the audit log attached to ctx.
+func (ctx *Context) appendAuditEntry() error {
+	ee, err := ctx.AuditEntries()
+	if err != nil {
+		return err
+	}
+
+	now := ctx.Now()
+
+	ee = append(ee, AuditEntry{
+		Operation:  commandName(ctx.Cmd),
+		Parameters: ctx.AuditParameters,
+		Timestamp:  now.Format(time.RFC3339),
+		Version:    "pdfcpu " + VersionStr,
+	})
+
+	bb, err := json.MarshalIndent(ee, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	k, _, err := ctx.auditLog()
+	if err != nil {
+		return err
+	}
+	if k != nil {
+		empty, _, err := ctx.Names["EmbeddedFiles"].Remove(ctx.XRefTable, *k)
+		if err != nil {
+			return err
+		}
+		if empty {
+			if err := ctx.RemoveEmbeddedFilesNameTree(); err != nil {
+				return err
+			}
+		}
+	}
+
+	a := Attachment{Reader: bytes.NewReader(bb), ID: AuditLogID, Desc: "pdfcpu audit log", ModTime: &now}
+
+	return ctx.AddAttachment(a, false)
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/bidi"
+)
+
+// Glyphs are shown in visual order. Lines containing right-to-left scripts like Arabic or Hebrew
+// get reordered into logical order by resolving simplified embedding levels of the Unicode Bidirectional Algorithm
+// on the visual sequence and applying the reordering rule L2 which is its own inverse.
+// The base direction of a line is right-to-left if its language is written right-to-left
+// or if it contains more right-to-left than left-to-right characters.
+
+const (
+	bidiNeutral = iota
+	bidiL
+	bidiR
+	bidiNumber
+)
+
+// rtlLanguages are the primary language subtags of languages written right-to-left.
+var rtlLanguages = map[string]bool{
+	"ar": true, "arc": true, "dv": true, "fa": true, "he": true, "iw": true, "ks": true,
+	"ku": true, "ps": true, "sd": true, "syr": true, "ug": true, "ur": true, "yi": true,
+}
+
+// rtlLang returns true if the language identifier lang denotes a language written right-to-left.
+func rtlLang(lang string) bool {
+	s := strings.ToLower(lang)
+	if i := strings.IndexAny(s, "-_"); i >= 0 {
+		s = s[:i]
+	}
+	return rtlLanguages[s]
+}
+
+// bidiType returns the type of the first strong character of s.
+// Strings without strong characters are numbers if they contain any digits.
+func bidiType(s string) int {
+	t := bidiNeutral
+	for _, r := range s {
+		p, _ := bidi.LookupRune(r)
+		switch p.Class() {
+		case bidi.L:
+			return bidiL
+		case bidi.R, bidi.AL:
+			return bidiR
+		case bidi.EN, bidi.AN:
+			t = bidiNumber
+		}
+	}
+	return t
+}
+
+// bidiLevels resolves the embedding levels of a visual sequence of items of types tt on a line with base level base.
+func bidiLevels(tt []int, base int) []int {
+	baseType := bidiL
+	if base == 1 {
+		baseType = bidiR
+	}
+
+	// strong returns the type of the nearest item in direction d which is not skipped.
+	strong := func(i, d int, skip func(t int) bool) int {
+		for i += d; i >= 0 && i < len(tt); i += d {
+			if !skip(tt[i]) {
+				return tt[i]
+			}
+		}
+		return baseType
+	}
+
+	level := func(t int) int {
+		if t == bidiR {
+			return 1
+		}
+		// Left-to-right text is raised above right-to-left base level.
+		return 2 * base
+	}
+
+	lv := make([]int, len(tt))
+
+	for i, t := range tt {
+		switch t {
+
+		case bidiL, bidiR:
+			lv[i] = level(t)
+
+		case bidiNumber:
+			// Numbers are written left-to-right within right-to-left text.
+			nonStrong := func(t int) bool { return t == bidiNeutral || t == bidiNumber }
+			lv[i] = 2 * base
+			if strong(i, -1, nonStrong) == bidiR || strong(i, 1, nonStrong) == bidiR {
+				lv[i] = 2
+			}
+
+		default:
+			// Neutrals take the direction of matching surrounding text, numbers count as right-to-left.
+			neutral := func(t int) bool { return t == bidiNeutral }
+			asStrong := func(t int) int {
+				if t == bidiNumber {
+					return bidiR
+				}
+				return t
+			}
+			if t1 := asStrong(strong(i, -1, neutral)); t1 == asStrong(strong(i, 1, neutral)) {
+				lv[i] = level(t1)
+			} else {
+				lv[i] = base
+			}
+		}
+	}
+
+	return lv
+}
+
+// bidiReorder returns the indices of items of levels lv reordered by reversing any maximal run of items
+// at level k or higher, from the highest level down to 1.
+func bidiReorder(lv []int) []int {
+	idx := make([]int, len(lv))
+	max := 0
+	for i, l := range lv {
+		idx[i] = i
+		if l > max {
+			max = l
+		}
+	}
+
+	for k := max; k >= 1; k-- {
+		for i := 0; i < len(idx); {
+			if lv[idx[i]] < k {
+				i++
+				continue
+			}
+			j := i
+			for j < len(idx) && lv[idx[j]] >= k {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				idx[a], idx[b] = idx[b], idx[a]
+			}
+			i = j
+		}
+	}
+
+	return idx
+}
+
+// bidiBaseLevel returns the base level of l if it contains any right-to-left text.
+func (l *textLine) bidiBaseLevel() (int, bool) {
+	var nl, nr int
+	lang := ""
+	for _, g := range l.glyphs {
+		switch bidiType(g.s) {
+		case bidiL:
+			nl++
+		case bidiR:
+			nr++
+		}
+		if lang == "" {
+			lang = g.lang
+		}
+	}
+	if nr == 0 {
+		return 0, false
+	}
+	if rtlLang(lang) || nr > nl {
+		return 1, true
+	}
+	return 0, true
+}
+
+// logicalWords returns the words ww of a line with base level base in logical order.
+// ww and the glyph strings ss of each word are given in visual order.
+func logicalWords(ww []TextWord, ss [][]string, base int) []TextWord {
+	if len(ww) == 0 {
+		return ww
+	}
+
+	// Words are separated by neutral white space.
+	tt := make([]int, 2*len(ww)-1)
+	for i := range ww {
+		tt[2*i] = bidiType(ww[i].Text)
+	}
+	lv := bidiLevels(tt, base)
+
+	res := make([]TextWord, 0, len(ww))
+
+	for _, i := range bidiReorder(lv) {
+		if i%2 == 1 {
+			continue
+		}
+		w, gg := ww[i/2], ss[i/2]
+		ct := make([]int, len(gg))
+		for j, s := range gg {
+			ct[j] = bidiType(s)
+		}
+		var sb strings.Builder
+		for _, j := range bidiReorder(bidiLevels(ct, lv[i]%2)) {
+			sb.WriteString(gg[j])
+		}
+		w.Text = sb.String()
+		res = append(res, w)
+	}
+
+	return res
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"sort"
+	"strings"
+)
+
+// Canonical writing produces output that diffs cleanly in text tools:
+// Semantically equal documents result in the same dict layout, number formatting and object order.
+
+const canonicalIndent = "  "
+
+// canonicalFloat returns f using at most 12 decimal places without trailing zeros.
+func canonicalFloat(f float64) string {
+	return FormatFloat(f, 12, true)
+}
+
+// canonicalString returns the canonical PDF representation of o
+// with one dict entry per line indented according to the nesting level.
+func canonicalString(o Object, eol string, level int) string {
+
+	switch o := o.(type) {
+
+	case nil:
+		return "null"
+
+	case Float:
+		return canonicalFloat(o.Value())
+
+	case Dict:
+		if len(o) == 0 {
+			return "<<>>"
+		}
+		ss := []string{"<<"}
+		tab := strings.Repeat(canonicalIndent, level+1)
+		for _, k := range sortedKeys(o) {
+			ss = append(ss, eol+tab+"/"+k+" "+canonicalString(o[k], eol, level+1))
+		}
+		ss = append(ss, eol+strings.Repeat(canonicalIndent, level)+">>")
+		return strings.Join(ss, "")
+
+	case StreamDict:
+		return canonicalString(o.Dict, eol, level)
+
+	case Array:
+		ss := make([]string, len(o))
+		for i, v := range o {
+			ss[i] = canonicalString(v, eol, level)
+		}
+		return "[" + strings.Join(ss, " ") + "]"
+
+	}
+
+	return o.PDFString()
+}
+
+func sortedKeys(d Dict) []string {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pdfString returns the representation of o written to the PDF file.
+func (ctx *Context) pdfString(o Object) string {
+	o = ctx.writeForm(o)
+	if ctx.Canonical {
+		return canonicalString(o, ctx.Write.Eol, 0)
+	}
+	return o.PDFString()
+}
+
+// dictKeys returns the keys of d in the order referenced objects are written.
+func (ctx *Context) dictKeys(d Dict) []string {
+	if ctx.Canonical {
+		return sortedKeys(d)
+	}
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+	return keys
+}
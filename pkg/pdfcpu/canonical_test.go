@@ -0,0 +1,51 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import "testing"
+
+func TestCanonicalFloat(t *testing.T) {
+	for _, tt := range []struct {
+		f    float64
+		want string
+	}{
+		{1, "1.0"},
+		{0.5, "0.5"},
+		{-2.25, "-2.25"},
+		{0.1 + 0.2, "0.3"},
+		{-0.0000000000001, "0.0"},
+		{612.000000000001, "612.000000000001"},
+	} {
+		if got := canonicalFloat(tt.f); got != tt.want {
+			t.Errorf("canonicalFloat(%v): want %s, got %s\n", tt.f, tt.want, got)
+		}
+	}
+}
+
+func TestCanonicalString(t *testing.T) {
+	d := Dict(map[string]Object{
+		"Type":     Name("Page"),
+		"MediaBox": Array{Integer(0), Integer(0), Float(595.5), Float(842)},
+		"Resources": Dict(map[string]Object{
+			"Font": NewIndirectRef(5, 0),
+		}),
+	})
+	want := "<<\n  /MediaBox [0 0 595.5 842.0]\n  /Resources <<\n    /Font 5 0 R\n  >>\n  /Type /Page\n>>"
+	if got := canonicalString(d, "\n", 0); got != want {
+		t.Errorf("want:\n%s\ngot:\n%s\n", want, got)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+)
+
+// ChangeReport summarizes how an operation changes a document.
+type ChangeReport struct {
+	PagesBefore, PagesAfter     int
+	ObjectsBefore, ObjectsAfter int
+	ObjectsAdded                int
+	ObjectsRemoved              int
+	ObjectsChanged              int   // objects whose content differs.
+	SizeBefore, SizeAfter       int64 // file size in bytes.
+}
+
+func (r ChangeReport) String() string {
+	return fmt.Sprintf("pages:   %d -> %d (%+d)\n"+
+		"objects: %d -> %d (%d added, %d removed, %d rewritten)\n"+
+		"size:    %s -> %s (%d -> %d bytes)",
+		r.PagesBefore, r.PagesAfter, r.PagesAfter-r.PagesBefore,
+		r.ObjectsBefore, r.ObjectsAfter, r.ObjectsAdded, r.ObjectsRemoved, r.ObjectsChanged,
+		ByteSize(r.SizeBefore), ByteSize(r.SizeAfter), r.SizeBefore, r.SizeAfter)
+}
+
+// objectDigests returns a fingerprint for the content of each object of ctx.
+// Object streams and xref streams are skipped since they are artifacts of the file layout.
+func objectDigests(ctx *Context) map[int]string {
+	m := map[int]string{}
+
+	for objNr, e := range ctx.Table {
+		if objNr == 0 || e.Free || e.Object == nil {
+			continue
+		}
+
+		switch o := e.Object.(type) {
+
+		case ObjectStreamDict, XRefStreamDict:
+
+		case StreamDict:
+			// The stream length depends on the filters applied while writing.
+			d := o.Dict.Clone().(Dict)
+			d.Delete("Length")
+			m[objNr] = fmt.Sprintf("%s%x", d.PDFString(), sha256.Sum256(o.Raw))
+
+		default:
+			m[objNr] = o.PDFString()
+		}
+	}
+
+	return m
+}
+
+// NewChangeReport compares the document read into before with the result of an operation read into after.
+// before is nil for newly created documents.
+func NewChangeReport(before, after *Context) (*ChangeReport, error) {
+	r := &ChangeReport{}
+
+	if err := after.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+	r.PagesAfter = after.PageCount
+	r.SizeAfter = after.Read.FileSize
+
+	m2 := objectDigests(after)
+	r.ObjectsAfter = len(m2)
+
+	if before == nil {
+		r.ObjectsAdded = r.ObjectsAfter
+		return r, nil
+	}
+
+	if err := before.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+	r.PagesBefore = before.PageCount
+	r.SizeBefore = before.Read.FileSize
+
+	m1 := objectDigests(before)
+	r.ObjectsBefore = len(m1)
+
+	for objNr, s1 := range m1 {
+		s2, found := m2[objNr]
+		if !found {
+			r.ObjectsRemoved++
+			continue
+		}
+		if s1 != s2 {
+			r.ObjectsChanged++
+		}
+	}
+
+	r.ObjectsAdded = r.ObjectsAfter - (r.ObjectsBefore - r.ObjectsRemoved)
+
+	return r, nil
+}
+
+// ModifiedObjects compares the objects of after with those of before
+// and returns the numbers of all objects added and changed in after.
+func ModifiedObjects(before, after *Context) (added, changed []int) {
+	m1, m2 := objectDigests(before), objectDigests(after)
+
+	for objNr, s2 := range m2 {
+		s1, found := m1[objNr]
+		if !found {
+			added = append(added, objNr)
+			continue
+		}
+		if s1 != s2 {
+			changed = append(changed, objNr)
+		}
+	}
+
+	sort.Ints(added)
+	sort.Ints(changed)
+
+	return added, changed
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"github.com/pkg/errors"
+)
+
+func (ctx *Context) clipPage(fx *FormXObject, pagesDict Dict, pagesIndRef *IndirectRef) error {
+
+	formIndRef, err := ctx.formIndRef(fx)
+	if err != nil {
+		return err
+	}
+
+	resourceDict := Dict(
+		map[string]Object{
+			"XObject": Dict(map[string]Object{"Fx0": *formIndRef}),
+		},
+	)
+
+	contentsIndRef, err := ctx.contentStreamIndRef([]byte("/Fx0 Do"))
+	if err != nil {
+		return err
+	}
+
+	pageDict := Dict(
+		map[string]Object{
+			"Type":      Name("Page"),
+			"Parent":    *pagesIndRef,
+			"MediaBox":  RectForDim(fx.Width(), fx.Height()).Array(),
+			"Resources": resourceDict,
+			"Contents":  *contentsIndRef,
+		},
+	)
+
+	indRef, err := ctx.IndRefForNewObject(pageDict)
+	if err != nil {
+		return err
+	}
+
+	return AppendPageTree(indRef, 1, pagesDict)
+}
+
+// Clip replaces the pages of ctx by pages containing only the region described by b of each selected page.
+// b is relative to the visible area of a page taking any page rotation into account.
+// The clipped content is embedded as Form XObject with the region as bounding box.
+func (ctx *Context) Clip(selectedPages IntSet, b *Box) error {
+
+	if b == nil {
+		return errors.New("pdfcpu: missing region")
+	}
+
+	pagesDict := Dict(
+		map[string]Object{
+			"Type":  Name("Pages"),
+			"Count": Integer(0),
+		},
+	)
+
+	pagesIndRef, err := ctx.IndRefForNewObject(pagesDict)
+	if err != nil {
+		return err
+	}
+
+	pageNrs := sortSelectedPages(selectedPages)
+
+	for _, pageNr := range pageNrs {
+
+		fx, err := ctx.ExtractFormXObject(pageNr, nil)
+		if err != nil {
+			return err
+		}
+
+		// Resolve the region relative to the visible page area.
+		r := applyBox("ClipBox", b, NewDict(), RectForDim(fx.Width(), fx.Height()))
+
+		if fx, err = fx.Region(r); err != nil {
+			return err
+		}
+
+		if err = ctx.clipPage(fx, pagesDict, pagesIndRef); err != nil {
+			return err
+		}
+	}
+
+	// Replace original pagesDict.
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+
+	rootDict.Update("Pages", *pagesIndRef)
+	ctx.PageCount = len(pageNrs)
+
+	return nil
+}
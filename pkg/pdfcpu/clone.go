@@ -0,0 +1,308 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"reflect"
+)
+
+// Cloning a document copies the object graph and shares the stream data.
+// Stream data is never modified in place but replaced on change (copy on write),
+// so a parsed document may serve as template for any number of independently modified documents.
+
+// sharedBytes returns bb for sharing between clones.
+// The capacity gets clipped so that appending to bb always reallocates.
+func sharedBytes(bb []byte) []byte {
+	if bb == nil {
+		return nil
+	}
+	return bb[:len(bb):len(bb)]
+}
+
+func cloneStreamDict(sd StreamDict) StreamDict {
+	sd1 := sd.Clone().(StreamDict)
+	if sd.StreamLength != nil {
+		l := *sd.StreamLength
+		sd1.StreamLength = &l
+	}
+	sd1.Raw = sharedBytes(sd.Raw)
+	sd1.Content = sharedBytes(sd.Content)
+	return sd1
+}
+
+func cloneObject(o Object) Object {
+	switch o := o.(type) {
+	case nil:
+		return nil
+	case StreamDict:
+		return cloneStreamDict(o)
+	case ObjectStreamDict:
+		o.StreamDict = cloneStreamDict(o.StreamDict)
+		o.Prolog = sharedBytes(o.Prolog)
+		if o.ObjArray != nil {
+			o.ObjArray = o.ObjArray.Clone().(Array)
+		}
+		return o
+	case XRefStreamDict:
+		o.StreamDict = cloneStreamDict(o.StreamDict)
+		o.Objects = append([]int(nil), o.Objects...)
+		return o
+	default:
+		return o.Clone()
+	}
+}
+
+func cloneIntPtr(i *int) *int {
+	if i == nil {
+		return nil
+	}
+	j := *i
+	return &j
+}
+
+func cloneInt64Ptr(i *int64) *int64 {
+	if i == nil {
+		return nil
+	}
+	j := *i
+	return &j
+}
+
+func cloneIntSet(s IntSet) IntSet {
+	if s == nil {
+		return nil
+	}
+	s1 := IntSet{}
+	for k, v := range s {
+		s1[k] = v
+	}
+	return s1
+}
+
+func cloneXRefTableEntry(entry *XRefTableEntry) *XRefTableEntry {
+	if entry == nil {
+		return nil
+	}
+	e := *entry
+	e.Offset = cloneInt64Ptr(entry.Offset)
+	e.Generation = cloneIntPtr(entry.Generation)
+	e.ObjectStream = cloneIntPtr(entry.ObjectStream)
+	e.ObjectStreamInd = cloneIntPtr(entry.ObjectStreamInd)
+	e.Object = cloneObject(entry.Object)
+	return &e
+}
+
+// dictID identifies the map behind d.
+func dictID(d Dict) uintptr {
+	return reflect.ValueOf(d).Pointer()
+}
+
+// cloneNode clones a name tree node whose dicts are mapped to their clones via dicts.
+func cloneNode(n *Node, dicts map[uintptr]Dict) *Node {
+	if n == nil {
+		return nil
+	}
+	n1 := &Node{Kmin: n.Kmin, Kmax: n.Kmax}
+	if n.D != nil {
+		if d, ok := dicts[dictID(n.D)]; ok {
+			n1.D = d
+		} else {
+			n1.D = n.D.Clone().(Dict)
+		}
+	}
+	for _, kid := range n.Kids {
+		n1.Kids = append(n1.Kids, cloneNode(kid, dicts))
+	}
+	for _, e := range n.Names {
+		n1.Names = append(n1.Names, entry{e.k, cloneObject(e.v)})
+	}
+	return n1
+}
+
+// Clone returns an independent copy of xRefTable.
+// All dicts and arrays get copied whereas stream data is shared since it is treated as immutable.
+// xRefTable must not be modified while being cloned.
+func (xRefTable *XRefTable) Clone() *XRefTable {
+	x := &XRefTable{
+		Table:                   make(map[int]*XRefTableEntry, len(xRefTable.Table)),
+		Size:                    cloneIntPtr(xRefTable.Size),
+		PageCount:               xRefTable.PageCount,
+		Names:                   map[string]*Node{},
+		EncKey:                  sharedBytes(xRefTable.EncKey),
+		AES4Strings:             xRefTable.AES4Strings,
+		AES4Streams:             xRefTable.AES4Streams,
+		AES4EmbeddedStreams:     xRefTable.AES4EmbeddedStreams,
+		Title:                   xRefTable.Title,
+		Subject:                 xRefTable.Subject,
+		Keywords:                xRefTable.Keywords,
+		Author:                  xRefTable.Author,
+		Creator:                 xRefTable.Creator,
+		Producer:                xRefTable.Producer,
+		CreationDate:            xRefTable.CreationDate,
+		ModDate:                 xRefTable.ModDate,
+		Properties:              map[string]string{},
+		OffsetPrimaryHintTable:  cloneInt64Ptr(xRefTable.OffsetPrimaryHintTable),
+		OffsetOverflowHintTable: cloneInt64Ptr(xRefTable.OffsetOverflowHintTable),
+		LinearizationObjs:       cloneIntSet(xRefTable.LinearizationObjs),
+		PageAnnots:              map[int]PgAnnots{},
+		PageThumbs:              map[int]IndirectRef{},
+		Stats:                   PDFStats{rootAttrs: cloneIntSet(xRefTable.Stats.rootAttrs), pageAttrs: cloneIntSet(xRefTable.Stats.pageAttrs)},
+		Tagged:                  xRefTable.Tagged,
+		Warnings:                append([]string(nil), xRefTable.Warnings...),
+		Log:                     xRefTable.Log,
+		CurPage:                 xRefTable.CurPage,
+		CurObj:                  xRefTable.CurObj,
+		ValidationMode:          xRefTable.ValidationMode,
+		ValidateLinks:           xRefTable.ValidateLinks,
+		Valid:                   xRefTable.Valid,
+		URIs:                    map[int]map[string]string{},
+		Optimized:               xRefTable.Optimized,
+		Watermarked:             xRefTable.Watermarked,
+		cancelCtx:               xRefTable.cancelCtx,
+		clock:                   xRefTable.clock,
+		rnd:                     xRefTable.rnd,
+	}
+
+	dicts := map[uintptr]Dict{}
+	for objNr, entry := range xRefTable.Table {
+		e := cloneXRefTableEntry(entry)
+		if d, ok := entry.Object.(Dict); ok && d != nil {
+			dicts[dictID(d)] = e.Object.(Dict)
+		}
+		x.Table[objNr] = e
+	}
+
+	if xRefTable.Root != nil {
+		ir := *xRefTable.Root
+		x.Root = &ir
+	}
+	if xRefTable.RootDict != nil {
+		if d, ok := dicts[dictID(xRefTable.RootDict)]; ok {
+			x.RootDict = d
+		} else {
+			x.RootDict = xRefTable.RootDict.Clone().(Dict)
+		}
+	}
+
+	for k, n := range xRefTable.Names {
+		x.Names[k] = cloneNode(n, dicts)
+	}
+
+	if xRefTable.Encrypt != nil {
+		ir := *xRefTable.Encrypt
+		x.Encrypt = &ir
+	}
+	if xRefTable.E != nil {
+		e := *xRefTable.E
+		x.E = &e
+	}
+
+	if xRefTable.HeaderVersion != nil {
+		v := *xRefTable.HeaderVersion
+		x.HeaderVersion = &v
+	}
+	if xRefTable.RootVersion != nil {
+		v := *xRefTable.RootVersion
+		x.RootVersion = &v
+	}
+
+	if xRefTable.ID != nil {
+		x.ID = xRefTable.ID.Clone().(Array)
+	}
+	if xRefTable.Info != nil {
+		ir := *xRefTable.Info
+		x.Info = &ir
+	}
+	for k, v := range xRefTable.Properties {
+		x.Properties[k] = v
+	}
+
+	for pageNr, pa := range xRefTable.PageAnnots {
+		pa1 := PgAnnots{}
+		for t, am := range pa {
+			am1 := AnnotMap{}
+			for id, ar := range am {
+				am1[id] = ar
+			}
+			pa1[t] = am1
+		}
+		x.PageAnnots[pageNr] = pa1
+	}
+	for pageNr, ir := range xRefTable.PageThumbs {
+		x.PageThumbs[pageNr] = ir
+	}
+
+	if xRefTable.AdditionalStreams != nil {
+		a := xRefTable.AdditionalStreams.Clone().(Array)
+		x.AdditionalStreams = &a
+	}
+
+	for pageNr, m := range xRefTable.URIs {
+		m1 := map[string]string{}
+		for k, v := range m {
+			m1[k] = v
+		}
+		x.URIs[pageNr] = m1
+	}
+
+	if xRefTable.Report != nil {
+		r := *xRefTable.Report
+		r.Findings = append([]ValidationFinding(nil), r.Findings...)
+		x.Report = &r
+	}
+
+	return x
+}
+
+func (oc *OptimizationContext) clone() *OptimizationContext {
+	if oc == nil {
+		return nil
+	}
+	oc1 := *oc
+	oc1.PageFonts = make([]IntSet, len(oc.PageFonts))
+	for i, s := range oc.PageFonts {
+		oc1.PageFonts[i] = cloneIntSet(s)
+	}
+	oc1.PageImages = make([]IntSet, len(oc.PageImages))
+	for i, s := range oc.PageImages {
+		oc1.PageImages[i] = cloneIntSet(s)
+	}
+	oc1.DuplicateFontObjs = cloneIntSet(oc.DuplicateFontObjs)
+	oc1.DuplicateImageObjs = cloneIntSet(oc.DuplicateImageObjs)
+	oc1.DuplicateInfoObjects = cloneIntSet(oc.DuplicateInfoObjects)
+	oc1.NonReferencedObjs = append([]int(nil), oc.NonReferencedObjs...)
+	oc1.Cache = map[int]bool{}
+	oc1.NullObjNr = cloneIntPtr(oc.NullObjNr)
+	return &oc1
+}
+
+// Clone returns an independent copy of ctx ready for modification and writing.
+// Clones share the read context and the stream data of ctx, which must not be modified while being cloned.
+func (ctx *Context) Clone() *Context {
+	conf := *ctx.Configuration
+	return &Context{
+		Configuration:  &conf,
+		XRefTable:      ctx.XRefTable.Clone(),
+		Read:           ctx.Read,
+		Optimize:       ctx.Optimize.clone(),
+		Write:          NewWriteContext(conf.Eol),
+		outlinesNested: ctx.outlinesNested,
+		mergedFiles:    append([]mergedFile(nil), ctx.mergedFiles...),
+		mergeStamped:   ctx.mergeStamped,
+		Timing:         ctx.Timing,
+	}
+}
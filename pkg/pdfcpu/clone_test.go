@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestXRefTableClone(t *testing.T) {
+	ctx, err := CreateContextWithXRefTable(NewDefaultConfiguration(), PaperSize["A4"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sd, err := ctx.NewStreamDictForBuf([]byte("BT /F1 12 Tf (Hello) Tj ET"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sd.Encode(); err != nil {
+		t.Fatal(err)
+	}
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	objNr := ir.ObjectNumber.Value()
+
+	x := ctx.XRefTable.Clone()
+
+	// The catalog cache refers to the cloned catalog.
+	rootDict, err := x.Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootDict["Lang"] = StringLiteral("de")
+	if x.RootDict["Lang"] == nil {
+		t.Errorf("root dict cache not pointing to the cloned catalog\n")
+	}
+	if _, found := ctx.RootDict.Find("Lang"); found {
+		t.Errorf("catalog of source modified\n")
+	}
+
+	// Stream dicts get copied, stream data gets shared.
+	sd1, _, err := x.DereferenceStreamDict(*ir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &sd1.Raw[0] != &sd.Raw[0] {
+		t.Errorf("stream data not shared\n")
+	}
+	sd1.Update("Foo", Name("Bar"))
+	sd1.Content = append(sd1.Content, " 0 0 m"...)
+	x.Table[objNr].Object = *sd1
+
+	sd2, _, err := ctx.DereferenceStreamDict(*ir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, found := sd2.Find("Foo"); found {
+		t.Errorf("stream dict of source modified\n")
+	}
+	if string(sd2.Content) != "BT /F1 12 Tf (Hello) Tj ET" {
+		t.Errorf("stream data of source modified: %s\n", sd2.Content)
+	}
+
+	// New objects are local to the clone.
+	if _, err := x.IndRefForNewObject(Dict{}); err != nil {
+		t.Fatal(err)
+	}
+	if *x.Size != *ctx.Size+1 || len(x.Table) != len(ctx.Table)+1 {
+		t.Errorf("size: want %d, got %d\n", *ctx.Size+1, *x.Size)
+	}
+}
+
+func TestContextClone(t *testing.T) {
+	ctx, err := CreateContextWithXRefTable(NewDefaultConfiguration(), PaperSize["A4"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx1 := ctx.Clone()
+	ctx1.Cmd = OPTIMIZE
+	ctx1.PageCount++
+
+	if ctx.Cmd == OPTIMIZE || ctx.PageCount == ctx1.PageCount {
+		t.Errorf("source context modified\n")
+	}
+	if ctx1.Write == ctx.Write {
+		t.Errorf("write context shared\n")
+	}
+}
+
+// exclusiveReader fails reads overlapping with another read.
+type exclusiveReader struct {
+	busy int32
+}
+
+func (r *exclusiveReader) Read(p []byte) (int, error) {
+	if !atomic.CompareAndSwapInt32(&r.busy, 0, 1) {
+		return 0, errors.New("concurrent read")
+	}
+	defer atomic.StoreInt32(&r.busy, 0)
+	time.Sleep(time.Millisecond)
+	return len(p), nil
+}
+
+func TestContextCloneSharedRand(t *testing.T) {
+	ctx, err := CreateContextWithXRefTable(NewDefaultConfiguration(), PaperSize["A4"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.SetRand(&exclusiveReader{})
+
+	const n = 8
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int, c *Context) {
+			defer wg.Done()
+			_, errs[i] = io.ReadFull(c.Rand(), make([]byte, 16))
+		}(i, ctx.Clone())
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+var errCMapCorrupt = errors.New("pdfcpu: corrupt CMap")
+
+// cmapDecoder decodes a string shown using a predefined CMap into Unicode text.
+// A nil cmapDecoder represents a CMap without Unicode semantics like Identity-H.
+type cmapDecoder func(b []byte) (string, error)
+
+func decodeUCS2(b []byte) (string, error) {
+	if len(b)%2 > 0 {
+		return "", errCMapCorrupt
+	}
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	return string(utf16.Decode(u)), nil
+}
+
+func decodeUTF8(b []byte) (string, error) {
+	if !utf8.Valid(b) {
+		return "", errCMapCorrupt
+	}
+	return string(b), nil
+}
+
+func decodeUTF32(b []byte) (string, error) {
+	if len(b)%4 > 0 {
+		return "", errCMapCorrupt
+	}
+	var sb strings.Builder
+	for i := 0; i < len(b); i += 4 {
+		sb.WriteRune(rune(b[i])<<24 | rune(b[i+1])<<16 | rune(b[i+2])<<8 | rune(b[i+3]))
+	}
+	return sb.String(), nil
+}
+
+func decodeWith(enc encoding.Encoding) cmapDecoder {
+	return func(b []byte) (string, error) {
+		bb, err := enc.NewDecoder().Bytes(b)
+		if err != nil {
+			return "", err
+		}
+		return string(bb), nil
+	}
+}
+
+// decodeJIS decodes 7-bit JIS X 0208 codes as used by the CMaps H and V.
+func decodeJIS(b []byte) (string, error) {
+	bb := make([]byte, len(b))
+	for i, c := range b {
+		bb[i] = c | 0x80
+	}
+	return decodeWith(japanese.EUCJP)(bb)
+}
+
+// predefinedCMaps holds the predefined CMaps for CJK fonts of Table 118 with the decoders for their character codes.
+var predefinedCMaps = func() map[string]cmapDecoder {
+
+	m := map[string]cmapDecoder{}
+
+	add := func(dec cmapDecoder, names ...string) {
+		for _, s := range names {
+			// Horizontal and vertical writing modes share their codes.
+			m[s+"-H"] = dec
+			m[s+"-V"] = dec
+		}
+	}
+
+	// Unicode based CMaps.
+	add(decodeUCS2, "UniJIS-UCS2", "UniJIS-UCS2-HW", "UniGB-UCS2", "UniCNS-UCS2", "UniKS-UCS2")
+	add(decodeUCS2, "UniJIS-UTF16", "UniJIS2004-UTF16", "UniGB-UTF16", "UniCNS-UTF16", "UniKS-UTF16")
+	add(decodeUTF8, "UniJIS-UTF8", "UniJIS2004-UTF8", "UniGB-UTF8", "UniCNS-UTF8", "UniKS-UTF8")
+	add(decodeUTF32, "UniJIS-UTF32", "UniJIS2004-UTF32", "UniGB-UTF32", "UniCNS-UTF32", "UniKS-UTF32")
+
+	// Japanese
+	add(decodeWith(japanese.ShiftJIS), "83pv-RKSJ", "90ms-RKSJ", "90msp-RKSJ", "90pv-RKSJ", "Add-RKSJ", "Ext-RKSJ")
+	add(decodeWith(japanese.EUCJP), "EUC")
+	m["H"], m["V"] = decodeJIS, decodeJIS
+
+	// Simplified Chinese
+	add(decodeWith(simplifiedchinese.GBK), "GB-EUC", "GBpc-EUC", "GBK-EUC", "GBKp-EUC")
+	add(decodeWith(simplifiedchinese.GB18030), "GBK2K")
+
+	// Traditional Chinese
+	add(decodeWith(traditionalchinese.Big5), "B5pc", "ETen-B5", "ETenms-B5", "HKscs-B5")
+
+	// Korean
+	add(decodeWith(korean.EUCKR), "KSC-EUC", "KSCms-UHC", "KSCms-UHC-HW", "KSCpc-EUC")
+
+	// Identity mappings of 2-byte codes onto CIDs.
+	add(nil, "Identity")
+
+	return m
+}()
+
+// IsPredefinedCMap returns true if name is the name of a predefined CMap.
+func IsPredefinedCMap(name string) bool {
+	_, found := predefinedCMaps[name]
+	return found
+}
+
+type codespaceRange struct {
+	lo, hi []byte
+}
+
+func (r codespaceRange) contains(b []byte) bool {
+	if len(b) != len(r.lo) {
+		return false
+	}
+	for i, c := range b {
+		if c < r.lo[i] || c > r.hi[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// unicodeCMap represents the mapping of character codes onto Unicode text defined by a ToUnicode CMap.
+type unicodeCMap struct {
+	codespace []codespaceRange
+	m         map[string]string
+}
+
+// maxBFRange limits the number of codes of a single bfrange.
+const maxBFRange = 0x10000
+
+func utf16BE(b []byte) string {
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	return string(utf16.Decode(u))
+}
+
+func cmapHexBytes(o Object) ([]byte, error) {
+	hl, ok := o.(HexLiteral)
+	if !ok {
+		return nil, errCMapCorrupt
+	}
+	return hl.Bytes()
+}
+
+// cmapSections returns the bodies of all sections of s enclosed by begin<kw> and end<kw>.
+func cmapSections(s, kw string) []string {
+	ss := []string{}
+	for {
+		i := strings.Index(s, "begin"+kw)
+		if i < 0 {
+			return ss
+		}
+		s = s[i+len("begin"+kw):]
+		j := strings.Index(s, "end"+kw)
+		if j < 0 {
+			return ss
+		}
+		ss = append(ss, s[:j])
+		s = s[j+len("end"+kw):]
+	}
+}
+
+func cmapObjects(s string) ([]Object, error) {
+	oo := []Object{}
+	for {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return oo, nil
+		}
+		o, err := parseObject(&s)
+		if err != nil {
+			return nil, errCMapCorrupt
+		}
+		oo = append(oo, o)
+	}
+}
+
+func (cm *unicodeCMap) parseCodespaceRanges(s string) error {
+	oo, err := cmapObjects(s)
+	if err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(oo); i += 2 {
+		lo, err := cmapHexBytes(oo[i])
+		if err != nil {
+			return err
+		}
+		hi, err := cmapHexBytes(oo[i+1])
+		if err != nil {
+			return err
+		}
+		if len(lo) != len(hi) || len(lo) == 0 {
+			return errCMapCorrupt
+		}
+		cm.codespace = append(cm.codespace, codespaceRange{lo, hi})
+	}
+	return nil
+}
+
+func (cm *unicodeCMap) parseBFChars(s string) error {
+	oo, err := cmapObjects(s)
+	if err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(oo); i += 2 {
+		src, err := cmapHexBytes(oo[i])
+		if err != nil {
+			return err
+		}
+		dst, err := cmapHexBytes(oo[i+1])
+		if err != nil {
+			// Glyph names as destination are not supported.
+			continue
+		}
+		cm.m[string(src)] = utf16BE(dst)
+	}
+	return nil
+}
+
+// incrementCode returns b incremented by one as big endian number.
+func incrementCode(b []byte) []byte {
+	b1 := append([]byte{}, b...)
+	for i := len(b1) - 1; i >= 0; i-- {
+		b1[i]++
+		if b1[i] != 0 {
+			break
+		}
+	}
+	return b1
+}
+
+func (cm *unicodeCMap) parseBFRanges(s string) error {
+	oo, err := cmapObjects(s)
+	if err != nil {
+		return err
+	}
+	for i := 0; i+2 < len(oo); i += 3 {
+		lo, err := cmapHexBytes(oo[i])
+		if err != nil {
+			return err
+		}
+		hi, err := cmapHexBytes(oo[i+1])
+		if err != nil {
+			return err
+		}
+		if len(lo) != len(hi) {
+			return errCMapCorrupt
+		}
+
+		a, isArray := oo[i+2].(Array)
+		var dst []byte
+		if !isArray {
+			if dst, err = cmapHexBytes(oo[i+2]); err != nil {
+				return err
+			}
+		}
+
+		for j, code := 0, lo; j < maxBFRange && string(code) <= string(hi); j++ {
+			if isArray {
+				if j >= len(a) {
+					break
+				}
+				if b, err := cmapHexBytes(a[j]); err == nil {
+					cm.m[string(code)] = utf16BE(b)
+				}
+			} else {
+				cm.m[string(code)] = utf16BE(dst)
+				dst = incrementCode(dst)
+			}
+			if string(code) == string(hi) {
+				break
+			}
+			code = incrementCode(code)
+		}
+	}
+	return nil
+}
+
+// parseToUnicodeCMap parses the codespace ranges and the bfchar and bfrange mappings of a ToUnicode CMap.
+func parseToUnicodeCMap(bb []byte) (*unicodeCMap, error) {
+
+	s := string(bb)
+	cm := &unicodeCMap{m: map[string]string{}}
+
+	for _, s := range cmapSections(s, "codespacerange") {
+		if err := cm.parseCodespaceRanges(s); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range cmapSections(s, "bfchar") {
+		if err := cm.parseBFChars(s); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range cmapSections(s, "bfrange") {
+		if err := cm.parseBFRanges(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return cm, nil
+}
+
+// codeLength returns the length of the character code starting at b.
+// defLen applies in the absence of codespace ranges.
+func (cm *unicodeCMap) codeLength(b []byte, defLen int) int {
+	if len(cm.codespace) == 0 {
+		if defLen > len(b) {
+			return len(b)
+		}
+		return defLen
+	}
+	for n := 1; n <= 4 && n <= len(b); n++ {
+		for _, r := range cm.codespace {
+			if r.contains(b[:n]) {
+				return n
+			}
+		}
+	}
+	return 1
+}
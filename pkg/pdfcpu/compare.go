@@ -0,0 +1,353 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+)
+
+// The document info entries touched by any write.
+var volatileInfoKeys = NewStringSet([]string{"CreationDate", "ModDate", "Producer"})
+
+// The stream dict entries describing the encoding of a stream.
+var streamEncodingKeys = NewStringSet([]string{"Length", "Filter", "DecodeParms", "DL"})
+
+type objNrPair struct {
+	objNr1, objNr2 int
+}
+
+// docComparer compares objects of two different documents.
+type docComparer struct {
+	xRefTable1, xRefTable2 *XRefTable
+	visited                map[objNrPair]bool
+}
+
+func (dc *docComparer) equalObjects(o1, o2 Object) (bool, error) {
+	ir1, ok1 := o1.(IndirectRef)
+	ir2, ok2 := o2.(IndirectRef)
+	if ok1 && ok2 {
+		p := objNrPair{ir1.ObjectNumber.Value(), ir2.ObjectNumber.Value()}
+		if dc.visited[p] {
+			// Already compared or comparison in progress.
+			return true, nil
+		}
+		dc.visited[p] = true
+	}
+
+	o1, err := dc.xRefTable1.Dereference(o1)
+	if err != nil {
+		return false, err
+	}
+
+	o2, err = dc.xRefTable2.Dereference(o2)
+	if err != nil {
+		return false, err
+	}
+
+	if o1 == nil || o2 == nil {
+		return o1 == nil && o2 == nil, nil
+	}
+
+	if fmt.Sprintf("%T", o1) != fmt.Sprintf("%T", o2) {
+		return false, nil
+	}
+
+	switch o1 := o1.(type) {
+
+	case Dict:
+		return dc.equalDicts(o1, o2.(Dict), nil)
+
+	case StreamDict:
+		return dc.equalStreamDicts(o1, o2.(StreamDict))
+
+	case Array:
+		return dc.equalArrays(o1, o2.(Array))
+
+	}
+
+	return o1.PDFString() == o2.PDFString(), nil
+}
+
+func (dc *docComparer) equalArrays(a1, a2 Array) (bool, error) {
+	if len(a1) != len(a2) {
+		return false, nil
+	}
+
+	for i, o1 := range a1 {
+		ok, err := dc.equalObjects(o1, a2[i])
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// equalDicts compares d1 and d2 skipping all keys in ignore.
+func (dc *docComparer) equalDicts(d1, d2 Dict, ignore StringSet) (bool, error) {
+	for k := range d2 {
+		if _, found := d1[k]; !found && !ignore[k] {
+			return false, nil
+		}
+	}
+
+	for k, v1 := range d1 {
+		if ignore[k] {
+			continue
+		}
+		v2, found := d2[k]
+		if !found {
+			return false, nil
+		}
+		ok, err := dc.equalObjects(v1, v2)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// equalStreamDicts compares decoded stream content if possible, otherwise the raw stream data.
+func (dc *docComparer) equalStreamDicts(sd1, sd2 StreamDict) (bool, error) {
+	err1, err2 := sd1.Decode(), sd2.Decode()
+
+	if err1 == nil && err2 == nil {
+		ok, err := dc.equalDicts(sd1.Dict, sd2.Dict, streamEncodingKeys)
+		if err != nil || !ok {
+			return false, err
+		}
+		return bytes.Equal(sd1.Content, sd2.Content), nil
+	}
+
+	for _, err := range []error{err1, err2} {
+		if err != nil && err != filter.ErrUnsupportedFilter {
+			return false, err
+		}
+	}
+
+	ok, err := dc.equalDicts(sd1.Dict, sd2.Dict, nil)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	return bytes.Equal(sd1.Raw, sd2.Raw), nil
+}
+
+func infoText(xRefTable *XRefTable, o Object) (string, error) {
+	o, err := xRefTable.Dereference(o)
+	if err != nil || o == nil {
+		return "", err
+	}
+	switch o.(type) {
+	case StringLiteral, HexLiteral:
+		return Text(o)
+	}
+	return o.PDFString(), nil
+}
+
+func infoDict(xRefTable *XRefTable) (Dict, error) {
+	if xRefTable.Info == nil {
+		return nil, nil
+	}
+	return xRefTable.DereferenceDict(*xRefTable.Info)
+}
+
+func (dc *docComparer) equalInfoDicts() (bool, error) {
+	d1, err := infoDict(dc.xRefTable1)
+	if err != nil {
+		return false, err
+	}
+
+	d2, err := infoDict(dc.xRefTable2)
+	if err != nil {
+		return false, err
+	}
+
+	keys := StringSet{}
+	for k := range d1 {
+		keys[k] = true
+	}
+	for k := range d2 {
+		keys[k] = true
+	}
+
+	for k := range keys {
+		if volatileInfoKeys[k] {
+			continue
+		}
+		s1, err := infoText(dc.xRefTable1, d1[k])
+		if err != nil {
+			return false, err
+		}
+		s2, err := infoText(dc.xRefTable2, d2[k])
+		if err != nil {
+			return false, err
+		}
+		if s1 != s2 {
+			log.Info.Printf("EqualDocuments: info %s: %q != %q\n", k, s1, s2)
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func pageContent(xRefTable *XRefTable, d Dict) ([]byte, error) {
+	bb, err := xRefTable.PageContent(d)
+	if err == errNoContent {
+		return nil, nil
+	}
+	return bb, err
+}
+
+func cropBox(pAttrs *InheritedPageAttrs) *Rectangle {
+	if pAttrs.cropBox != nil {
+		return pAttrs.cropBox
+	}
+	return pAttrs.mediaBox
+}
+
+func equalRects(r1, r2 *Rectangle) bool {
+	if r1 == nil || r2 == nil {
+		return r1 == r2
+	}
+	return r1.equals(*r2)
+}
+
+// equalPageResources compares all resources referenced by content.
+func (dc *docComparer) equalPageResources(resDict1, resDict2 Dict, content []byte) (bool, error) {
+	prn, err := parseContent(string(content))
+	if err != nil {
+		// Fall back to comparing all resources.
+		return dc.equalObjects(resDict1, resDict2)
+	}
+
+	for resType := range resourceTypes {
+		names := prn.Resources(resType)
+		if len(names) == 0 {
+			continue
+		}
+		d1, err := dc.xRefTable1.DereferenceDict(resDict1[resType])
+		if err != nil {
+			return false, err
+		}
+		d2, err := dc.xRefTable2.DereferenceDict(resDict2[resType])
+		if err != nil {
+			return false, err
+		}
+		for name := range names {
+			ok, err := dc.equalObjects(d1[name], d2[name])
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				log.Info.Printf("EqualDocuments: resource %s/%s differs\n", resType, name)
+				return false, nil
+			}
+		}
+	}
+
+	return true, nil
+}
+
+func (dc *docComparer) equalPages(pageNr int) (bool, error) {
+	d1, _, pAttrs1, err := dc.xRefTable1.PageDict(pageNr, false)
+	if err != nil {
+		return false, err
+	}
+
+	d2, _, pAttrs2, err := dc.xRefTable2.PageDict(pageNr, false)
+	if err != nil {
+		return false, err
+	}
+
+	if d1 == nil || d2 == nil {
+		return d1 == nil && d2 == nil, nil
+	}
+
+	if !equalRects(pAttrs1.mediaBox, pAttrs2.mediaBox) || !equalRects(cropBox(pAttrs1), cropBox(pAttrs2)) {
+		log.Info.Printf("EqualDocuments: page %d: page boundaries differ\n", pageNr)
+		return false, nil
+	}
+
+	if pAttrs1.rotate%360 != pAttrs2.rotate%360 {
+		log.Info.Printf("EqualDocuments: page %d: rotation differs\n", pageNr)
+		return false, nil
+	}
+
+	bb1, err := pageContent(dc.xRefTable1, d1)
+	if err != nil {
+		return false, err
+	}
+
+	bb2, err := pageContent(dc.xRefTable2, d2)
+	if err != nil {
+		return false, err
+	}
+
+	if !bytes.Equal(bb1, bb2) {
+		log.Info.Printf("EqualDocuments: page %d: content differs\n", pageNr)
+		return false, nil
+	}
+
+	ok, err := dc.equalPageResources(pAttrs1.resources, pAttrs2.resources, bb1)
+	if err == nil && !ok {
+		log.Info.Printf("EqualDocuments: page %d: resources differ\n", pageNr)
+	}
+	return ok, err
+}
+
+// EqualDocuments returns true if ctx1 and ctx2 represent the same content.
+// Compared are the document info, page count, page boundaries and rotation,
+// page content and all page resources like fonts and images referenced by the content.
+// File IDs, creation and modification dates and the producer are ignored
+// as well as the physical layout of the files.
+func EqualDocuments(ctx1, ctx2 *Context) (bool, error) {
+	dc := docComparer{xRefTable1: ctx1.XRefTable, xRefTable2: ctx2.XRefTable, visited: map[objNrPair]bool{}}
+
+	if err := ctx1.EnsurePageCount(); err != nil {
+		return false, err
+	}
+
+	if err := ctx2.EnsurePageCount(); err != nil {
+		return false, err
+	}
+
+	if ctx1.PageCount != ctx2.PageCount {
+		log.Info.Printf("EqualDocuments: page count: %d != %d\n", ctx1.PageCount, ctx2.PageCount)
+		return false, nil
+	}
+
+	ok, err := dc.equalInfoDicts()
+	if err != nil || !ok {
+		return false, err
+	}
+
+	for i := 1; i <= ctx1.PageCount; i++ {
+		ok, err := dc.equalPages(i)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
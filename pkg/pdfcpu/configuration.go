@@ -19,11 +19,14 @@ package pdfcpu
 import (
 	_ "embed"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 
 	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pdfcpu/pdfcpu/pkg/log"
 )
 
 const (
@@ -35,6 +38,10 @@ const (
 
 	// ValidationNone bypasses validation.
 	ValidationNone
+
+	// ValidationPedantic extends strict validation by flagging deviations readers usually tolerate
+	// like misplaced content stream operators, missing whitespace and exceeded implementation limits.
+	ValidationPedantic
 )
 
 const (
@@ -50,6 +57,18 @@ const (
 
 )
 
+// User access permission bits for encrypted files, see PDF 32000-1:2008 Table 22.
+const (
+	PermissionPrint                int16 = 0x0004 // Bit 3: print, possibly degraded (see bit 12).
+	PermissionModify               int16 = 0x0008 // Bit 4: modify other than controlled by bits 6, 9, 11.
+	PermissionExtract              int16 = 0x0010 // Bit 5: copy or extract text and graphics.
+	PermissionAnnotate             int16 = 0x0020 // Bit 6: add or modify annotations.
+	PermissionFillForms            int16 = 0x0100 // Bit 9: fill in form fields.
+	PermissionExtractAccessibility int16 = 0x0200 // Bit 10: extract text and graphics for accessibility.
+	PermissionAssemble             int16 = 0x0400 // Bit 11: insert, rotate or delete pages, create bookmarks or thumbnails.
+	PermissionPrintHighQuality     int16 = 0x0800 // Bit 12: print in high quality.
+)
+
 // CommandMode specifies the operation being executed.
 type CommandMode int
 
@@ -106,6 +125,84 @@ const (
 	REMOVEANNOTATIONS
 	ADDBOOKMARKS
 	LISTIMAGES
+	MANIFEST
+	VERIFY
+	INTERNALIZE
+	ENCRYPTIONINFO
+	CLIP
+	LABELS
+	DECORATE
+	AUTOCROP
+	ROTATECONTENT
+	LISTFORMORDER
+	SETFORMORDER
+	EXTRACTTEXT
+	EXTRACTTABLES
+	DUPLICATES
+	RUN
+	LISTFORMFIELDS
+	EXPORTFORMFIELDS
+	FILLFORMFIELDS
+	FILLTEMPLATE
+	PAGESIZES
+	ORPHANS
+	RESIZE
+	VALIDATESIGNATURES
+	FEATURES
+	PREVIEW
+	EXTRACTSVG
+)
+
+const (
+	// FilterPolicyPreserve skips decoding of streams using unsupported filters and writes their raw data as is.
+	FilterPolicyPreserve int = iota
+
+	// FilterPolicyError fails reading files containing streams using unsupported filters.
+	FilterPolicyError
+
+	// FilterPolicyDecode decodes streams using unsupported filters with decoders registered via filter.RegisterDecoder.
+	FilterPolicyDecode
+)
+
+const (
+	// StringFormPreserve writes strings the way they have been read or created.
+	StringFormPreserve int = iota
+
+	// StringFormLiteral writes all strings as literal strings, eg. (pdfcpu).
+	StringFormLiteral
+
+	// StringFormHex writes all strings as hexadecimal strings, eg. <706466637075>.
+	StringFormHex
+
+	// StringFormAuto writes strings made up of printable ASCII as literal strings and any other string as hexadecimal string.
+	StringFormAuto
+)
+
+const (
+	// SignaturePolicyWarn modifies digitally signed documents recording a warning about the invalidated signatures.
+	SignaturePolicyWarn int = iota
+
+	// SignaturePolicyRefuse refuses to modify digitally signed documents unless writing an incremental update.
+	SignaturePolicyRefuse
+
+	// SignaturePolicyIncremental switches to incremental update mode for signature preserving changes
+	// like form filling and annotations and refuses any other modification of digitally signed documents.
+	SignaturePolicyIncremental
+)
+
+const (
+	// MergeOutlinesNone keeps the outline of the first merged file only.
+	MergeOutlinesNone int = iota
+
+	// MergeOutlinesNested adds a top level outline item for each merged file holding its outline.
+	MergeOutlinesNested
+
+	// MergeOutlinesFlat concatenates the outlines of all merged files.
+	MergeOutlinesFlat
+
+	// MergeOutlinesFiles is like MergeOutlinesNested but adds a top level outline item for each merged file
+	// even if none of the merged files has an outline.
+	MergeOutlinesFiles
 )
 
 // Configuration of a Context.
@@ -119,12 +216,73 @@ type Configuration struct {
 	// Enables decoding of all streams (fontfiles, images..) for logging purposes.
 	DecodeAllStreams bool
 
-	// Validate against ISO-32000: strict or relaxed
+	// Validate against ISO-32000: strict, relaxed or pedantic
 	ValidationMode int
 
+	// How to deal with streams using filters pdfcpu is unable to decode: preserve, error or decode.
+	FilterPolicy int
+
+	// Load the data of streams living in external files while reading.
+	// Off by default since a document could otherwise pull in arbitrary local files.
+	ResolveExternalStreams bool
+
+	// Resolves files referenced by the document like external stream data.
+	// Defaults to the directory of the input file, see DirFS.
+	FileSystem fs.FS
+
 	// Check for broken links in LinkedAnnotations/URIActions.
 	ValidateLinks bool
 
+	// Compute and store embedded file checksums when adding attachments.
+	AttachmentCheckSums bool
+
+	// Loggers for reading, validating, optimizing and writing documents processed using this configuration,
+	// eg. log.FuncLoggers for a structured logger. nil uses the package level loggers.
+	// Other subsystems always log via the package level loggers, see log.SetLoggers.
+	Loggers *log.Loggers
+
+	// Record parser decisions like xref repairs and stream length corrections in ReadContext.Trace.
+	TraceParser bool
+
+	// Rebuild the xref table by scanning the whole file for objects instead of trusting the file's xref sections.
+	// Files whose xref table fails to parse are always rebuilt, use this for xref tables pointing to garbage.
+	Repair bool
+
+	// Record each operation in a JSON audit log attached to the written file.
+	AuditLog bool
+
+	// Optional parameters of the operation in progress for the audit log.
+	AuditParameters map[string]string
+
+	// Template for the names of files written by split and extract pages, eg. "{name}_{from}-{thru}.pdf".
+	// Expressions in curly braces may use the variables name, from, thru, page, pagecount and title.
+	FileNameTemplate string
+
+	// Keep a copy of the original named <file>.bak when a file gets replaced in place.
+	BackupInPlace bool
+
+	// Append new and modified objects to the original file as an incremental update instead of rewriting the whole file.
+	Incremental bool
+
+	// Include warnings about non fatal anomalies found while reading in info output.
+	ListWarnings bool
+
+	// Remove page resources not referenced by any content stream during optimization.
+	OptimizeResourceDicts bool
+
+	// Share identical page resources and hoist common inheritable page attributes
+	// up the page tree when running optimize.
+	ConsolidateResources bool
+
+	// Flate encode uncompressed streams during optimization.
+	CompressStreams bool
+
+	// Max number of object streams holding decoded content while reading, 0 = no limit.
+	ObjectStreamCacheSize int
+
+	// Max number of goroutines parsing objects concurrently while reading, 0 = GOMAXPROCS.
+	ReadWorkers int
+
 	// End of line char sequence for writing.
 	Eol string
 
@@ -135,8 +293,37 @@ type Configuration struct {
 	WriteObjectStream bool
 
 	// Switches between xRefSection (<=V1.4) and objectStream/xRefStream (>=V1.5) writing.
+	// false falls back to a classic cross reference table and top level objects only for compatibility.
 	WriteXRefStream bool
 
+	// Turns on canonical writing for diff friendly output:
+	// sorted dict keys with one entry per line, normalized numbers and deterministic object order.
+	// true enforces WriteObjectStream and WriteXRefStream to false.
+	Canonical bool
+
+	// How to write strings: preserve, literal, hex or auto (literal for printable ASCII, hex for anything else).
+	StringForm int
+
+	// Max number of decimal places for real numbers written in object values
+	// and in content streams written from operations, see contentstream.FormatFor.
+	// Content generated by pdfcpu itself (stamps, watermarks, nup, booklets, annotations) keeps its fixed precision.
+	// 0 writes as many decimal places as needed to read back the same value, at most 12 when writing canonical.
+	FloatPrecision int
+
+	// Drops trailing zeros from real numbers written using FloatPrecision.
+	TrimFloatZeros bool
+
+	// Max size in bytes of content streams written from operations, see contentstream.SetPageOperations.
+	// Larger content gets split into several content streams at operation boundaries, 0 for no limit.
+	MaxContentStreamSize int
+
+	// PDF version written into the header:
+	// "" or "1.7": PDF 1.7
+	// "auto": the minimum version required by the features in use
+	// "1.x": PDF 1.x, fails if the document uses features beyond 1.x.
+	// Targets below 1.5 turn off object and xref streams.
+	TargetVersion string
+
 	// Turns on stats collection.
 	// TODO Decision - unused.
 	CollectStats bool
@@ -163,11 +350,35 @@ type Configuration struct {
 	// Supplied user access permissions, see Table 22
 	Permissions int16
 
+	// Process encrypted documents regardless of their user access permissions.
+	IgnorePermissions bool
+
+	// Source of creation and modification dates, annotation dates and file identifiers, defaults to SystemClock.
+	Clock Clock
+
+	// Source of randomness for encryption keys, initialization vectors, salts and font subset tags, defaults to crypto/rand.Reader.
+	RandReader io.Reader
+
+	// Decides whether a command may process an encrypted document, defaults to DefaultPermissionPolicy.
+	PermissionPolicy PermissionPolicy
+
+	// How to deal with modifications of digitally signed documents: warn, refuse or incremental.
+	SignaturePolicy int
+
 	// Command being executed.
 	Cmd CommandMode
 
 	// Display unit in effect.
 	Unit DisplayUnit
+
+	// How to combine outlines when merging: none, nested, flat or files.
+	MergeOutlines int
+
+	// Stamp the pages of each merged file with its file name.
+	MergeStampFileNames bool
+
+	// Keep the document level JavaScript of files merged into the first one, which is dropped by default.
+	MergeJavaScript bool
 }
 
 // ConfigPath defines the location of pdfcpu's configuration directory.
@@ -218,15 +429,22 @@ func newDefaultConfiguration() *Configuration {
 	// 		cli: supply -conf disable
 	// 		api: call api.DisableConfigDir()
 	return &Configuration{
-		Reader15:          true,
-		DecodeAllStreams:  false,
-		ValidationMode:    ValidationRelaxed,
-		Eol:               EolLF,
-		WriteObjectStream: true,
-		WriteXRefStream:   true,
-		EncryptUsingAES:   true,
-		EncryptKeyLength:  256,
-		Permissions:       PermissionsNone,
+		Reader15:              true,
+		DecodeAllStreams:      false,
+		ValidationMode:        ValidationRelaxed,
+		FilterPolicy:          FilterPolicyPreserve,
+		SignaturePolicy:       SignaturePolicyWarn,
+		OptimizeResourceDicts: false,
+		ConsolidateResources:  false,
+		CompressStreams:       false,
+		ObjectStreamCacheSize: 10,
+		Eol:                   EolLF,
+		WriteObjectStream:     true,
+		WriteXRefStream:       true,
+		TrimFloatZeros:        true,
+		EncryptUsingAES:       true,
+		EncryptKeyLength:      256,
+		Permissions:           PermissionsNone,
 	}
 }
 
@@ -278,30 +496,101 @@ func (c Configuration) String() string {
 		path = c.Path
 	}
 	return fmt.Sprintf("pdfcpu configuration:\n"+
-		"Path:              %s\n"+
-		"Reader15:          %t\n"+
-		"DecodeAllStreams:  %t\n"+
-		"ValidationMode:    %s\n"+
-		"Eol:               %s\n"+
-		"WriteObjectStream: %t\n"+
-		"WriteXrefStream:   %t\n"+
-		"EncryptUsingAES:   %t\n"+
-		"EncryptKeyLength:  %d\n"+
-		"Permissions:       %d\n"+
-		"Unit :             %s\n",
+		"Path:                  %s\n"+
+		"Reader15:              %t\n"+
+		"DecodeAllStreams:      %t\n"+
+		"ValidationMode:        %s\n"+
+		"FilterPolicy:          %s\n"+
+		"SignaturePolicy:       %s\n"+
+		"OptimizeResourceDicts: %t\n"+
+		"ConsolidateResources:  %t\n"+
+		"CompressStreams:       %t\n"+
+		"ObjectStreamCacheSize: %d\n"+
+		"ReadWorkers:           %d\n"+
+		"Eol:                   %s\n"+
+		"WriteObjectStream:     %t\n"+
+		"WriteXrefStream:       %t\n"+
+		"Canonical:             %t\n"+
+		"StringForm:            %s\n"+
+		"FloatPrecision:        %d\n"+
+		"TrimFloatZeros:        %t\n"+
+		"MaxContentStreamSize:  %d\n"+
+		"TargetVersion:         %s\n"+
+		"EncryptUsingAES:       %t\n"+
+		"EncryptKeyLength:      %d\n"+
+		"Permissions:           %d\n"+
+		"IgnorePermissions:     %t\n"+
+		"AuditLog:              %t\n"+
+		"FileNameTemplate:      %s\n"+
+		"BackupInPlace:         %t\n"+
+		"Incremental:           %t\n"+
+		"Unit :                 %s\n",
 		path,
 		c.Reader15,
 		c.DecodeAllStreams,
 		c.ValidationModeString(),
+		c.FilterPolicyString(),
+		c.SignaturePolicyString(),
+		c.OptimizeResourceDicts,
+		c.ConsolidateResources,
+		c.CompressStreams,
+		c.ObjectStreamCacheSize,
+		c.ReadWorkers,
 		c.EolString(),
 		c.WriteObjectStream,
 		c.WriteXRefStream,
+		c.Canonical,
+		c.StringFormString(),
+		c.FloatPrecision,
+		c.TrimFloatZeros,
+		c.MaxContentStreamSize,
+		c.TargetVersion,
 		c.EncryptUsingAES,
 		c.EncryptKeyLength,
 		c.Permissions,
+		c.IgnorePermissions,
+		c.AuditLog,
+		c.FileNameTemplate,
+		c.BackupInPlace,
+		c.Incremental,
 		c.UnitString())
 }
 
+// FilterPolicyString returns a string rep for the filter policy in effect.
+func (c *Configuration) FilterPolicyString() string {
+	switch c.FilterPolicy {
+	case FilterPolicyError:
+		return "error"
+	case FilterPolicyDecode:
+		return "decode"
+	}
+	return "preserve"
+}
+
+// StringFormString returns a string rep for the string output form in effect.
+func (c *Configuration) StringFormString() string {
+	switch c.StringForm {
+	case StringFormLiteral:
+		return "literal"
+	case StringFormHex:
+		return "hex"
+	case StringFormAuto:
+		return "auto"
+	}
+	return "preserve"
+}
+
+// SignaturePolicyString returns a string rep for the signature policy in effect.
+func (c *Configuration) SignaturePolicyString() string {
+	switch c.SignaturePolicy {
+	case SignaturePolicyRefuse:
+		return "refuse"
+	case SignaturePolicyIncremental:
+		return "incremental"
+	}
+	return "warn"
+}
+
 // EolString returns a string rep for the eol in effect.
 func (c *Configuration) EolString() string {
 	var s string
@@ -316,17 +605,24 @@ func (c *Configuration) EolString() string {
 	return s
 }
 
-// ValidationModeString returns a string rep for the validation mode in effect.
-func (c *Configuration) ValidationModeString() string {
-	if c.ValidationMode == ValidationStrict {
+func validationModeString(mode int) string {
+	if mode == ValidationStrict {
 		return "strict"
 	}
-	if c.ValidationMode == ValidationRelaxed {
+	if mode == ValidationRelaxed {
 		return "relaxed"
 	}
+	if mode == ValidationPedantic {
+		return "pedantic"
+	}
 	return "none"
 }
 
+// ValidationModeString returns a string rep for the validation mode in effect.
+func (c *Configuration) ValidationModeString() string {
+	return validationModeString(c.ValidationMode)
+}
+
 // UnitString returns a string rep for the display unit in effect.
 func (c *Configuration) UnitString() string {
 	var s string
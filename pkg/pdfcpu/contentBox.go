@@ -0,0 +1,1221 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"encoding/hex"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// The content box (aka ink box) of a page is the bounding box of all marks painted onto this page.
+// It is calculated by a light weight content stream interpreter tracking
+// the transformation matrix, clipping, line width and text state.
+// Paths and glyphs are measured using their control points and font metrics
+// which results in a conservative estimate.
+
+const maxFormNesting = 16
+
+const (
+	cbNumber = iota
+	cbName
+	cbString
+	cbArray
+	cbDict
+	cbOther
+)
+
+type cbOperand struct {
+	kind int
+	num  float64
+	s    string // name, decoded string or dict source.
+	arr  []cbOperand
+}
+
+type cbToken struct {
+	op  string // operator, empty for operands.
+	opd cbOperand
+}
+
+type contentLexer struct {
+	bb  []byte
+	pos int
+}
+
+func cbDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return cbWhitespace(c)
+}
+
+func cbWhitespace(c byte) bool {
+	switch c {
+	case 0x00, 0x09, 0x0A, 0x0C, 0x0D, 0x20:
+		return true
+	}
+	return false
+}
+
+func (l *contentLexer) eof() bool {
+	return l.pos >= len(l.bb)
+}
+
+func (l *contentLexer) skipWhitespaceAndComments() {
+	for !l.eof() {
+		c := l.bb[l.pos]
+		if cbWhitespace(c) {
+			l.pos++
+			continue
+		}
+		if c == '%' {
+			for !l.eof() && l.bb[l.pos] != 0x0A && l.bb[l.pos] != 0x0D {
+				l.pos++
+			}
+			continue
+		}
+		break
+	}
+}
+
+func (l *contentLexer) regular() string {
+	i := l.pos
+	for !l.eof() && !cbDelimiter(l.bb[l.pos]) {
+		l.pos++
+	}
+	return string(l.bb[i:l.pos])
+}
+
+func (l *contentLexer) stringLiteral() (string, error) {
+	// Skip '('
+	l.pos++
+	i := l.pos
+	for n := 1; ; l.pos++ {
+		if l.eof() {
+			return "", errStringLiteralCorrupt
+		}
+		switch l.bb[l.pos] {
+		case '\\':
+			l.pos++
+		case '(':
+			n++
+		case ')':
+			n--
+		}
+		if n == 0 {
+			break
+		}
+	}
+	raw := string(l.bb[i:l.pos])
+	l.pos++
+	bb, err := Unescape(raw)
+	if err != nil {
+		return "", err
+	}
+	return string(bb), nil
+}
+
+func (l *contentLexer) hexLiteral() (string, error) {
+	// Skip '<'
+	l.pos++
+	var bb []byte
+	for ; !l.eof() && l.bb[l.pos] != '>'; l.pos++ {
+		if !cbWhitespace(l.bb[l.pos]) {
+			bb = append(bb, l.bb[l.pos])
+		}
+	}
+	if l.eof() {
+		return "", errHexLiteralCorrupt
+	}
+	l.pos++
+	if len(bb)%2 == 1 {
+		bb = append(bb, '0')
+	}
+	b, err := hex.DecodeString(string(bb))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (l *contentLexer) skipDict() error {
+	for n := 0; !l.eof(); {
+		switch {
+		case l.bb[l.pos] == '(':
+			if _, err := l.stringLiteral(); err != nil {
+				return err
+			}
+			continue
+		case l.pos+1 < len(l.bb) && l.bb[l.pos] == '<' && l.bb[l.pos+1] == '<':
+			n++
+			l.pos += 2
+			continue
+		case l.pos+1 < len(l.bb) && l.bb[l.pos] == '>' && l.bb[l.pos+1] == '>':
+			n--
+			l.pos += 2
+			if n == 0 {
+				return nil
+			}
+			continue
+		}
+		l.pos++
+	}
+	return errDictionaryCorrupt
+}
+
+func (l *contentLexer) skipInlineImage() error {
+	// Skip the image dict up to ID.
+	for {
+		t, err := l.next()
+		if err != nil {
+			return err
+		}
+		if t == nil {
+			return errBIExpressionCorrupt
+		}
+		if t.op == "ID" {
+			break
+		}
+	}
+
+	// Skip the image data up to EI surrounded by white space.
+	for l.pos++; l.pos+2 <= len(l.bb); l.pos++ {
+		if l.bb[l.pos] == 'E' && l.bb[l.pos+1] == 'I' && cbWhitespace(l.bb[l.pos-1]) &&
+			(l.pos+2 == len(l.bb) || cbDelimiter(l.bb[l.pos+2])) {
+			l.pos += 2
+			return nil
+		}
+	}
+
+	return errBIExpressionCorrupt
+}
+
+func (l *contentLexer) array() ([]cbOperand, error) {
+	// Skip '['
+	l.pos++
+	a := []cbOperand{}
+	for {
+		l.skipWhitespaceAndComments()
+		if l.eof() {
+			return nil, errArrayCorrupt
+		}
+		if l.bb[l.pos] == ']' {
+			l.pos++
+			return a, nil
+		}
+		t, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if t == nil {
+			return nil, errArrayCorrupt
+		}
+		a = append(a, t.opd)
+	}
+}
+
+// next returns the next operand or operator or nil at the end of the content stream.
+func (l *contentLexer) next() (*cbToken, error) {
+	l.skipWhitespaceAndComments()
+	if l.eof() {
+		return nil, nil
+	}
+
+	c := l.bb[l.pos]
+
+	switch c {
+
+	case '/':
+		l.pos++
+		return &cbToken{opd: cbOperand{kind: cbName, s: l.regular()}}, nil
+
+	case '(':
+		s, err := l.stringLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return &cbToken{opd: cbOperand{kind: cbString, s: s}}, nil
+
+	case '<':
+		if l.pos+1 < len(l.bb) && l.bb[l.pos+1] == '<' {
+			i := l.pos
+			if err := l.skipDict(); err != nil {
+				return nil, err
+			}
+			return &cbToken{opd: cbOperand{kind: cbDict, s: string(l.bb[i:l.pos])}}, nil
+		}
+		s, err := l.hexLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return &cbToken{opd: cbOperand{kind: cbString, s: s}}, nil
+
+	case '[':
+		a, err := l.array()
+		if err != nil {
+			return nil, err
+		}
+		return &cbToken{opd: cbOperand{kind: cbArray, arr: a}}, nil
+
+	case ')', '>', ']', '{', '}':
+		// Skip stray delimiters.
+		l.pos++
+		return &cbToken{opd: cbOperand{kind: cbOther}}, nil
+	}
+
+	s := l.regular()
+
+	if c == '+' || c == '-' || c == '.' || c >= '0' && c <= '9' {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return &cbToken{opd: cbOperand{kind: cbOther}}, nil
+		}
+		return &cbToken{opd: cbOperand{kind: cbNumber, num: f}}, nil
+	}
+
+	switch s {
+	case "true", "false", "null":
+		return &cbToken{opd: cbOperand{kind: cbOther}}, nil
+	case "BI":
+		if err := l.skipInlineImage(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &cbToken{op: s}, nil
+}
+
+// cbFont holds the font metrics needed for measuring text.
+type cbFont struct {
+	composite bool            // 2 byte codes.
+	firstChar int             // simple fonts.
+	widths    []float64       // simple fonts, in glyph space.
+	cidWidths map[int]float64 // composite fonts, in glyph space.
+	missing   float64         // default width in glyph space.
+	coreFont  string          // standard 14 font without widths.
+	scale     float64         // glyph space to text space.
+	ascent    float64         // text space.
+	descent   float64         // text space.
+	vertical  bool            // composite fonts using writing mode 1.
+	vy, w1    float64         // vertical writing: position vector y and displacement, in glyph space.
+	dec       *TextDecoder    // text extraction only.
+}
+
+var defaultCBFont = &cbFont{missing: 500, scale: .001, ascent: .75, descent: -.25}
+
+func (f *cbFont) width(code int) float64 {
+	if f.composite {
+		if w, ok := f.cidWidths[code]; ok {
+			return w * f.scale
+		}
+		return f.missing * f.scale
+	}
+	if i := code - f.firstChar; i >= 0 && i < len(f.widths) {
+		return f.widths[i] * f.scale
+	}
+	if f.coreFont != "" {
+		return float64(font.CharWidth(f.coreFont, rune(code))) * f.scale
+	}
+	return f.missing * f.scale
+}
+
+type cbGState struct {
+	ctm  matrix
+	clip *Rectangle
+	lw   float64
+	// text state
+	font                   *cbFont
+	fs, tc, tw, th, tl, ts float64
+	tr                     int
+	mcid                   int    // marked content identifier, -1 for unmarked content.
+	lang                   string // language of the marked content.
+}
+
+type contentBoxer struct {
+	ctx   *Context
+	box   *Rectangle
+	fonts map[int]*cbFont // font metrics per font dict object number.
+	forms map[int]bool    // forms in process.
+	depth int
+	glyph func(gs *cbGState, gtm matrix, adv Point, r *Rectangle, code []byte) // optional glyph consumer.
+	rule  func(gs *cbGState, p1, p2 Point)                                     // optional consumer of painted line segments.
+	image func(gs *cbGState, sd *StreamDict, objNr int)                        // optional consumer of painted image XObjects.
+}
+
+func transformRect(m matrix, r *Rectangle) *Rectangle {
+	pp := []Point{
+		m.transform(Point{r.LL.X, r.LL.Y}),
+		m.transform(Point{r.UR.X, r.LL.Y}),
+		m.transform(Point{r.UR.X, r.UR.Y}),
+		m.transform(Point{r.LL.X, r.UR.Y}),
+	}
+	res := Rect(pp[0].X, pp[0].Y, pp[0].X, pp[0].Y)
+	for _, p := range pp[1:] {
+		res = unionRectPoint(res, p)
+	}
+	return res
+}
+
+func unionRectPoint(r *Rectangle, p Point) *Rectangle {
+	if r == nil {
+		return Rect(p.X, p.Y, p.X, p.Y)
+	}
+	return Rect(math.Min(r.LL.X, p.X), math.Min(r.LL.Y, p.Y), math.Max(r.UR.X, p.X), math.Max(r.UR.Y, p.Y))
+}
+
+func unionRect(r1, r2 *Rectangle) *Rectangle {
+	if r1 == nil {
+		return r2
+	}
+	if r2 == nil {
+		return r1
+	}
+	return Rect(math.Min(r1.LL.X, r2.LL.X), math.Min(r1.LL.Y, r2.LL.Y), math.Max(r1.UR.X, r2.UR.X), math.Max(r1.UR.Y, r2.UR.Y))
+}
+
+func intersectRect(r1, r2 *Rectangle) *Rectangle {
+	if r1 == nil || r2 == nil {
+		return nil
+	}
+	r := Rect(math.Max(r1.LL.X, r2.LL.X), math.Max(r1.LL.Y, r2.LL.Y), math.Min(r1.UR.X, r2.UR.X), math.Min(r1.UR.Y, r2.UR.Y))
+	if r.LL.X > r.UR.X || r.LL.Y > r.UR.Y {
+		return nil
+	}
+	return r
+}
+
+func (cb *contentBoxer) mark(gs *cbGState, r *Rectangle) {
+	if r = intersectRect(r, gs.clip); r != nil {
+		cb.box = unionRect(cb.box, r)
+	}
+}
+
+func (cb *contentBoxer) resource(res Dict, resType, name string) (Object, error) {
+	if res == nil {
+		return nil, nil
+	}
+	d, err := cb.ctx.DereferenceDict(res[resType])
+	if err != nil || d == nil {
+		return nil, err
+	}
+	return d[name], nil
+}
+
+func (cb *contentBoxer) cidWidths(d Dict) (map[int]float64, error) {
+	m := map[int]float64{}
+
+	a, err := cb.ctx.DereferenceArray(d["W"])
+	if err != nil || a == nil {
+		return m, err
+	}
+
+	// c [w1 w2 ... wn] or cFirst cLast w
+	for i := 0; i < len(a); {
+		c, err := cb.ctx.DereferenceNumber(a[i])
+		if err != nil || i+1 >= len(a) {
+			return m, err
+		}
+		o, err := cb.ctx.Dereference(a[i+1])
+		if err != nil {
+			return m, err
+		}
+		if ws, ok := o.(Array); ok {
+			for j, w := range ws {
+				f, err := cb.ctx.DereferenceNumber(w)
+				if err != nil {
+					return m, err
+				}
+				m[int(c)+j] = f
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(a) {
+			return m, nil
+		}
+		cLast, err := cb.ctx.DereferenceNumber(o)
+		if err != nil {
+			return m, err
+		}
+		w, err := cb.ctx.DereferenceNumber(a[i+2])
+		if err != nil {
+			return m, err
+		}
+		for j := int(c); j <= int(cLast); j++ {
+			m[j] = w
+		}
+		i += 3
+	}
+
+	return m, nil
+}
+
+func (cb *contentBoxer) fontDescriptorMetrics(d Dict, f *cbFont) error {
+	fd, err := cb.ctx.DereferenceDict(d["FontDescriptor"])
+	if err != nil || fd == nil {
+		return err
+	}
+
+	if o, found := fd.Find("MissingWidth"); found && !f.composite {
+		if f.missing, err = cb.ctx.DereferenceNumber(o); err != nil {
+			return err
+		}
+	}
+
+	asc, _ := cb.ctx.DereferenceNumber(fd["Ascent"])
+	desc, _ := cb.ctx.DereferenceNumber(fd["Descent"])
+	if asc > 0 {
+		f.ascent = asc * .001
+	}
+	if desc < 0 {
+		f.descent = desc * .001
+	}
+
+	return nil
+}
+
+// verticalWritingMode returns true if the CMap of the composite font d uses vertical writing mode.
+func (cb *contentBoxer) verticalWritingMode(d Dict) (bool, error) {
+	o, err := cb.ctx.Dereference(d["Encoding"])
+	if err != nil {
+		return false, err
+	}
+	switch o := o.(type) {
+	case Name:
+		return o == "V" || strings.HasSuffix(o.Value(), "-V"), nil
+	case StreamDict:
+		if wm := o.IntEntry("WMode"); wm != nil {
+			return *wm == 1, nil
+		}
+		if n := o.NameEntry("CMapName"); n != nil {
+			return strings.HasSuffix(*n, "-V"), nil
+		}
+	}
+	return false, nil
+}
+
+func (cb *contentBoxer) loadFont(d Dict) (*cbFont, error) {
+	f := &cbFont{scale: .001, ascent: .75, descent: -.25}
+
+	if subType := d.Subtype(); subType != nil && *subType == "Type0" {
+		f.composite = true
+		f.missing = 1000
+		a, err := cb.ctx.DereferenceArray(d["DescendantFonts"])
+		if err != nil || len(a) == 0 {
+			return f, err
+		}
+		df, err := cb.ctx.DereferenceDict(a[0])
+		if err != nil || df == nil {
+			return f, err
+		}
+		if o, found := df.Find("DW"); found {
+			if f.missing, err = cb.ctx.DereferenceNumber(o); err != nil {
+				return f, err
+			}
+		}
+		if f.cidWidths, err = cb.cidWidths(df); err != nil {
+			return f, err
+		}
+		if f.vertical, err = cb.verticalWritingMode(d); err != nil {
+			return f, err
+		}
+		f.vy, f.w1 = 880, -1000
+		if a, err := cb.ctx.DereferenceArray(df["DW2"]); err == nil && len(a) == 2 {
+			vy, err1 := cb.ctx.DereferenceNumber(a[0])
+			w1, err2 := cb.ctx.DereferenceNumber(a[1])
+			if err1 == nil && err2 == nil {
+				f.vy, f.w1 = vy, w1
+			}
+		}
+		return f, cb.fontDescriptorMetrics(df, f)
+	}
+
+	f.missing = 500
+
+	if a, err := cb.ctx.DereferenceArray(d["FontMatrix"]); err == nil && len(a) == 6 {
+		// Type3
+		if sc, err := cb.ctx.DereferenceNumber(a[0]); err == nil && sc != 0 {
+			f.scale = sc
+		}
+	}
+
+	if o, found := d.Find("FirstChar"); found {
+		fc, err := cb.ctx.DereferenceNumber(o)
+		if err != nil {
+			return f, err
+		}
+		f.firstChar = int(fc)
+	}
+
+	a, err := cb.ctx.DereferenceArray(d["Widths"])
+	if err != nil {
+		return f, err
+	}
+	for _, o := range a {
+		w, err := cb.ctx.DereferenceNumber(o)
+		if err != nil {
+			return f, err
+		}
+		f.widths = append(f.widths, w)
+	}
+
+	if len(f.widths) == 0 {
+		if bf := d.NameEntry("BaseFont"); bf != nil && font.IsCoreFont(*bf) {
+			f.coreFont = *bf
+		}
+	}
+
+	return f, cb.fontDescriptorMetrics(d, f)
+}
+
+func (cb *contentBoxer) font(res Dict, name string) (*cbFont, error) {
+	o, err := cb.resource(res, "Font", name)
+	if err != nil || o == nil {
+		return defaultCBFont, err
+	}
+
+	objNr := -1
+	if ir, ok := o.(IndirectRef); ok {
+		objNr = ir.ObjectNumber.Value()
+		if f, ok := cb.fonts[objNr]; ok {
+			return f, nil
+		}
+	}
+
+	d, err := cb.ctx.DereferenceDict(o)
+	if err != nil || d == nil {
+		return defaultCBFont, err
+	}
+
+	f, err := cb.loadFont(d)
+	if err != nil {
+		return nil, err
+	}
+
+	if cb.glyph != nil {
+		if f.dec, err = NewTextDecoder(cb.ctx.XRefTable, d); err != nil {
+			return nil, err
+		}
+	}
+
+	if objNr >= 0 {
+		cb.fonts[objNr] = f
+	}
+
+	return f, nil
+}
+
+// showText measures s and advances the text matrix.
+func (cb *contentBoxer) showText(gs *cbGState, tm *matrix, s string) {
+	f := gs.font
+	if f == nil {
+		f = defaultCBFont
+	}
+
+	n := 1
+	if f.composite {
+		n = 2
+	}
+
+	var codes []int
+	for i := 0; i+n <= len(s); i += n {
+		c := int(s[i])
+		if n == 2 {
+			c = c<<8 + int(s[i+1])
+		}
+		codes = append(codes, c)
+	}
+
+	if len(codes) == 0 {
+		return
+	}
+
+	if f.vertical {
+		cb.showVerticalText(gs, tm, f, s, codes)
+		return
+	}
+
+	var tx float64
+	for i, c := range codes {
+		w := f.width(c)*gs.fs + gs.tc
+		if !f.composite && c == 32 {
+			w += gs.tw
+		}
+		if cb.glyph != nil {
+			// Glyph matrix and box excluding character and word spacing.
+			gw := f.width(c) * gs.fs * gs.th
+			gtm := translation(tx, gs.ts).multiply(tm.multiply(gs.ctm))
+			r := Rect(0, f.descent*gs.fs, gw, f.ascent*gs.fs)
+			cb.glyph(gs, gtm, Point{gw, 0}, transformRect(gtm, r), []byte(s[i*n:i*n+n]))
+		}
+		tx += w * gs.th
+	}
+
+	if gs.tr != 3 && gs.tr != 7 {
+		// Glyphs are visible.
+		r := Rect(math.Min(0, tx), f.descent*gs.fs+gs.ts, math.Max(0, tx), f.ascent*gs.fs+gs.ts)
+		cb.mark(gs, transformRect(tm.multiply(gs.ctm), r))
+	}
+
+	*tm = translation(tx, 0).multiply(*tm)
+}
+
+// showVerticalText measures the glyphs of s using vertical writing mode.
+// Glyphs are centered horizontally on their vertical origin and advance downwards.
+func (cb *contentBoxer) showVerticalText(gs *cbGState, tm *matrix, f *cbFont, s string, codes []int) {
+	var (
+		ty  float64
+		box *Rectangle // text space.
+	)
+
+	vy := f.vy * f.scale * gs.fs
+	h := f.w1 * f.scale * gs.fs
+
+	for i, c := range codes {
+		gw := f.width(c) * gs.fs
+		r := Rect(-gw/2, f.descent*gs.fs-vy, gw/2, f.ascent*gs.fs-vy)
+		if cb.glyph != nil {
+			gtm := translation(0, ty).multiply(tm.multiply(gs.ctm))
+			cb.glyph(gs, gtm, Point{0, h}, transformRect(gtm, r), []byte(s[i*2:i*2+2]))
+		}
+		box = unionRect(box, Rect(r.LL.X, r.LL.Y+ty, r.UR.X, r.UR.Y+ty))
+		ty += h + gs.tc
+	}
+
+	if gs.tr != 3 && gs.tr != 7 {
+		// Glyphs are visible.
+		cb.mark(gs, transformRect(tm.multiply(gs.ctm), box))
+	}
+
+	*tm = translation(0, ty).multiply(*tm)
+}
+
+func translation(dx, dy float64) matrix {
+	m := identMatrix
+	m[2][0] = dx
+	m[2][1] = dy
+	return m
+}
+
+func operandMatrix(opds []cbOperand) matrix {
+	m := identMatrix
+	m[0][0], m[0][1] = opds[0].num, opds[1].num
+	m[1][0], m[1][1] = opds[2].num, opds[3].num
+	m[2][0], m[2][1] = opds[4].num, opds[5].num
+	return m
+}
+
+func numbers(opds []cbOperand, n int) bool {
+	if len(opds) < n {
+		return false
+	}
+	for _, o := range opds[len(opds)-n:] {
+		if o.kind != cbNumber {
+			return false
+		}
+	}
+	return true
+}
+
+func (cb *contentBoxer) form(gs cbGState, res Dict, name string) error {
+	o, err := cb.resource(res, "XObject", name)
+	if err != nil || o == nil {
+		return err
+	}
+
+	objNr := -1
+	if ir, ok := o.(IndirectRef); ok {
+		objNr = ir.ObjectNumber.Value()
+		if cb.forms[objNr] {
+			// Recursive form.
+			return nil
+		}
+	}
+
+	sd, _, err := cb.ctx.DereferenceStreamDict(o)
+	if err != nil || sd == nil {
+		return err
+	}
+
+	subType := sd.Subtype()
+	if subType == nil {
+		return nil
+	}
+
+	if *subType == "Image" {
+		cb.mark(&gs, transformRect(gs.ctm, Rect(0, 0, 1, 1)))
+		if cb.image != nil {
+			cb.image(&gs, sd, objNr)
+		}
+		return nil
+	}
+
+	if *subType != "Form" || cb.depth >= maxFormNesting {
+		return nil
+	}
+
+	if err := sd.Decode(); err != nil {
+		return err
+	}
+
+	if a, err := cb.ctx.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(a) == 6 {
+		m := identMatrix
+		for i, o := range a {
+			f, err := cb.ctx.DereferenceNumber(o)
+			if err != nil {
+				return err
+			}
+			m[i/2][i%2] = f
+		}
+		gs.ctm = m.multiply(gs.ctm)
+	}
+
+	if a, err := cb.ctx.DereferenceArray(sd.Dict["BBox"]); err == nil && len(a) == 4 {
+		bb, err := RectForArray(a)
+		if err != nil {
+			return err
+		}
+		gs.clip = intersectRect(gs.clip, transformRect(gs.ctm, bb))
+		if gs.clip == nil {
+			return nil
+		}
+	}
+
+	formRes := res
+	if d, err := cb.ctx.DereferenceDict(sd.Dict["Resources"]); err == nil && d != nil {
+		formRes = d
+	}
+
+	if objNr >= 0 {
+		cb.forms[objNr] = true
+		defer delete(cb.forms, objNr)
+	}
+
+	cb.depth++
+	defer func() { cb.depth-- }()
+
+	return cb.process(sd.Content, formRes, gs)
+}
+
+// markedContentProperties returns the property list opd, either inline or a named resource.
+func (cb *contentBoxer) markedContentProperties(res Dict, opd cbOperand) Dict {
+	switch opd.kind {
+	case cbDict:
+		o, err := parseObject(&opd.s)
+		if err != nil {
+			return nil
+		}
+		if d, ok := o.(Dict); ok {
+			return d
+		}
+	case cbName:
+		o, err := cb.resource(res, "Properties", opd.s)
+		if err != nil || o == nil {
+			return nil
+		}
+		if d, err := cb.ctx.DereferenceDict(o); err == nil {
+			return d
+		}
+	}
+	return nil
+}
+
+func (cb *contentBoxer) process(bb []byte, res Dict, gs cbGState) error {
+	var (
+		stack       []cbGState
+		opds        []cbOperand
+		path        *Rectangle
+		segs        []Point    // painted line segments in user space.
+		marks       []cbGState // graphics states at the start of enclosing marked content sequences.
+		cur, start  Point
+		clip        bool
+		tm, tlm     = identMatrix, identMatrix
+		strokeScale = func() float64 { return math.Sqrt(math.Abs(gs.ctm[0][0]*gs.ctm[1][1] - gs.ctm[0][1]*gs.ctm[1][0])) }
+	)
+
+	addPoint := func(x, y float64) {
+		cur = Point{x, y}
+		path = unionRectPoint(path, gs.ctm.transform(cur))
+	}
+
+	addSegment := func(p1, p2 Point) {
+		if cb.rule != nil {
+			segs = append(segs, gs.ctm.transform(p1), gs.ctm.transform(p2))
+		}
+	}
+
+	paint := func(fill, stroke bool) {
+		if cb.rule != nil && (fill || stroke) {
+			for i := 0; i+1 < len(segs); i += 2 {
+				cb.rule(&gs, segs[i], segs[i+1])
+			}
+		}
+		segs = nil
+		if path != nil {
+			r := path
+			if stroke {
+				d := gs.lw / 2 * strokeScale()
+				r = Rect(r.LL.X-d, r.LL.Y-d, r.UR.X+d, r.UR.Y+d)
+			}
+			if fill || stroke {
+				cb.mark(&gs, r)
+			}
+			if clip {
+				// A nil clip means everything gets clipped away.
+				gs.clip = intersectRect(gs.clip, path)
+			}
+		}
+		path, clip = nil, false
+	}
+
+	nextLine := func(tx, ty float64) {
+		tlm = translation(tx, ty).multiply(tlm)
+		tm = tlm
+	}
+
+	l := &contentLexer{bb: bb}
+
+	for {
+		t, err := l.next()
+		if err != nil {
+			return err
+		}
+		if t == nil {
+			return nil
+		}
+		if t.op == "" {
+			opds = append(opds, t.opd)
+			continue
+		}
+
+		n := len(opds)
+
+		switch t.op {
+
+		case "q":
+			stack = append(stack, gs)
+
+		case "Q":
+			if len(stack) > 0 {
+				gs = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+
+		case "cm":
+			if numbers(opds, 6) {
+				gs.ctm = operandMatrix(opds[n-6:]).multiply(gs.ctm)
+			}
+
+		case "w":
+			if numbers(opds, 1) {
+				gs.lw = opds[n-1].num
+			}
+
+		case "m":
+			if numbers(opds, 2) {
+				addPoint(opds[n-2].num, opds[n-1].num)
+				start = cur
+			}
+
+		case "l":
+			if numbers(opds, 2) {
+				addSegment(cur, Point{opds[n-2].num, opds[n-1].num})
+				addPoint(opds[n-2].num, opds[n-1].num)
+			}
+
+		case "c":
+			if numbers(opds, 6) {
+				for i := n - 6; i < n; i += 2 {
+					addPoint(opds[i].num, opds[i+1].num)
+				}
+			}
+
+		case "v", "y":
+			if numbers(opds, 4) {
+				for i := n - 4; i < n; i += 2 {
+					addPoint(opds[i].num, opds[i+1].num)
+				}
+			}
+
+		case "re":
+			if numbers(opds, 4) {
+				x, y, w, h := opds[n-4].num, opds[n-3].num, opds[n-2].num, opds[n-1].num
+				addSegment(Point{x, y}, Point{x + w, y})
+				addSegment(Point{x + w, y}, Point{x + w, y + h})
+				addSegment(Point{x + w, y + h}, Point{x, y + h})
+				addSegment(Point{x, y + h}, Point{x, y})
+				addPoint(x+w, y+h)
+				addPoint(x+w, y)
+				addPoint(x, y+h)
+				addPoint(x, y)
+				start = cur
+			}
+
+		case "h":
+			addSegment(cur, start)
+			cur = start
+
+		case "S", "s":
+			paint(false, true)
+
+		case "f", "F", "f*":
+			paint(true, false)
+
+		case "B", "B*", "b", "b*":
+			paint(true, true)
+
+		case "n":
+			paint(false, false)
+
+		case "W", "W*":
+			clip = true
+
+		case "sh":
+			cb.mark(&gs, gs.clip)
+
+		case "BI":
+			cb.mark(&gs, transformRect(gs.ctm, Rect(0, 0, 1, 1)))
+
+		case "Do":
+			if n > 0 && opds[n-1].kind == cbName {
+				if err := cb.form(gs, res, opds[n-1].s); err != nil {
+					return err
+				}
+			}
+
+		case "BT":
+			tm, tlm = identMatrix, identMatrix
+
+		case "BMC":
+			marks = append(marks, gs)
+
+		case "BDC":
+			marks = append(marks, gs)
+			if n >= 2 && cb.glyph != nil {
+				if d := cb.markedContentProperties(res, opds[n-1]); d != nil {
+					if id := d.IntEntry("MCID"); id != nil {
+						gs.mcid = *id
+					}
+					if s, err := Text(d["Lang"]); err == nil {
+						gs.lang = s
+					}
+				}
+			}
+
+		case "EMC":
+			if len(marks) > 0 {
+				gs.mcid, gs.lang = marks[len(marks)-1].mcid, marks[len(marks)-1].lang
+				marks = marks[:len(marks)-1]
+			}
+
+		case "Tf":
+			if n >= 2 && opds[n-2].kind == cbName && opds[n-1].kind == cbNumber {
+				if gs.font, err = cb.font(res, opds[n-2].s); err != nil {
+					return err
+				}
+				gs.fs = opds[n-1].num
+			}
+
+		case "Tc":
+			if numbers(opds, 1) {
+				gs.tc = opds[n-1].num
+			}
+
+		case "Tw":
+			if numbers(opds, 1) {
+				gs.tw = opds[n-1].num
+			}
+
+		case "Tz":
+			if numbers(opds, 1) {
+				gs.th = opds[n-1].num / 100
+			}
+
+		case "TL":
+			if numbers(opds, 1) {
+				gs.tl = opds[n-1].num
+			}
+
+		case "Ts":
+			if numbers(opds, 1) {
+				gs.ts = opds[n-1].num
+			}
+
+		case "Tr":
+			if numbers(opds, 1) {
+				gs.tr = int(opds[n-1].num)
+			}
+
+		case "Td":
+			if numbers(opds, 2) {
+				nextLine(opds[n-2].num, opds[n-1].num)
+			}
+
+		case "TD":
+			if numbers(opds, 2) {
+				gs.tl = -opds[n-1].num
+				nextLine(opds[n-2].num, opds[n-1].num)
+			}
+
+		case "Tm":
+			if numbers(opds, 6) {
+				tlm = operandMatrix(opds[n-6:])
+				tm = tlm
+			}
+
+		case "T*":
+			nextLine(0, -gs.tl)
+
+		case "Tj":
+			if n > 0 && opds[n-1].kind == cbString {
+				cb.showText(&gs, &tm, opds[n-1].s)
+			}
+
+		case "'":
+			nextLine(0, -gs.tl)
+			if n > 0 && opds[n-1].kind == cbString {
+				cb.showText(&gs, &tm, opds[n-1].s)
+			}
+
+		case "\"":
+			nextLine(0, -gs.tl)
+			if n >= 3 && numbers(opds[:n-1], 2) && opds[n-1].kind == cbString {
+				gs.tw, gs.tc = opds[n-3].num, opds[n-2].num
+				cb.showText(&gs, &tm, opds[n-1].s)
+			}
+
+		case "TJ":
+			if n > 0 && opds[n-1].kind == cbArray {
+				for _, o := range opds[n-1].arr {
+					switch o.kind {
+					case cbNumber:
+						if gs.font != nil && gs.font.vertical {
+							tm = translation(0, -o.num/1000*gs.fs).multiply(tm)
+							continue
+						}
+						tm = translation(-o.num/1000*gs.fs*gs.th, 0).multiply(tm)
+					case cbString:
+						cb.showText(&gs, &tm, o.s)
+					}
+				}
+			}
+		}
+
+		opds = opds[:0]
+	}
+}
+
+// ContentBox returns the bounding box of all marks painted onto page pageNr in default user space
+// clipped to the visible page area.
+// ContentBox returns nil for blank pages.
+func (ctx *Context) ContentBox(pageNr int) (*Rectangle, error) {
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, true)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.Errorf("pdfcpu: unknown page number: %d\n", pageNr)
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err != nil && err != errNoContent {
+		return nil, err
+	}
+
+	cb := &contentBoxer{ctx: ctx, fonts: map[int]*cbFont{}, forms: map[int]bool{}}
+
+	vp := viewPort(inhPAttrs)
+	gs := cbGState{ctm: identMatrix, clip: Rect(vp.LL.X, vp.LL.Y, vp.UR.X, vp.UR.Y), lw: 1, th: 1, mcid: -1}
+
+	if err := cb.process(bb, inhPAttrs.resources, gs); err != nil {
+		return nil, err
+	}
+
+	return cb.box, nil
+}
+
+// Padding represents the space to be kept around the content box when cropping to content.
+// Padding is relative to the visible page area taking any page rotation into account.
+type Padding struct {
+	Top, Right, Bot, Left float64
+}
+
+// ParsePadding parses a padding definition of 1,2,3 or 4 values in display unit u.
+func ParsePadding(s string, u DisplayUnit) (*Padding, error) {
+	if s == "" {
+		return &Padding{}, nil
+	}
+	t, r, b, l, err := parseMarginValues(s, u)
+	if err != nil {
+		return nil, errors.Errorf("pdfcpu: padding: %v", err)
+	}
+	return &Padding{Top: t, Right: r, Bot: b, Left: l}, nil
+}
+
+// userSpace returns left, bottom, right and top padding in user space for page rotation rot.
+func (pad Padding) userSpace(rot int) (float64, float64, float64, float64) {
+	switch rot {
+	case 90, -270:
+		return pad.Top, pad.Left, pad.Bot, pad.Right
+	case 180, -180:
+		return pad.Right, pad.Top, pad.Left, pad.Bot
+	case 270, -90:
+		return pad.Bot, pad.Right, pad.Top, pad.Left
+	}
+	return pad.Left, pad.Bot, pad.Right, pad.Top
+}
+
+// CropToContent sets the crop box of selected pages to their content box enlarged by pad.
+// The resulting crop box does not exceed the media box. Blank pages are left untouched.
+func (ctx *Context) CropToContent(selectedPages IntSet, pad *Padding) error {
+	if pad == nil {
+		pad = &Padding{}
+	}
+
+	for _, pageNr := range sortSelectedPages(selectedPages) {
+
+		r, err := ctx.ContentBox(pageNr)
+		if err != nil {
+			return err
+		}
+		if r == nil {
+			log.Info.Printf("page %d is blank, skipping\n", pageNr)
+			continue
+		}
+
+		d, _, inhPAttrs, err := ctx.PageDict(pageNr, false)
+		if err != nil {
+			return err
+		}
+
+		l, b, rt, t := pad.userSpace(inhPAttrs.rotate)
+		r = intersectRect(Rect(r.LL.X-l, r.LL.Y-b, r.UR.X+rt, r.UR.Y+t), inhPAttrs.mediaBox)
+		if r == nil {
+			continue
+		}
+
+		d.Update("CropBox", r.Array())
+	}
+
+	return nil
+}
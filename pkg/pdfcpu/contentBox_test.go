@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"math"
+	"testing"
+)
+
+func TestContentBoxProcessing(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want *Rectangle
+	}{
+		{"", nil},
+		{"q 2 0 0 2 10 10 cm 0 0 10 10 re f Q", Rect(10, 10, 30, 30)},
+		{"5 w 100 100 m 200 100 l S", Rect(97.5, 97.5, 202.5, 102.5)},
+		{"100 100 m 200 100 l n", nil},
+		{"0 0 5 5 re W n 0 0 100 100 re f", Rect(0, 0, 5, 5)},
+		{"q 0 0 5 5 re W n Q 0 0 100 100 re f", Rect(0, 0, 100, 100)},
+		{"% comment\n-10 -10 2000 2000 re f", Rect(0, 0, 500, 500)},
+		{"q 50 0 0 50 200 200 cm BI /W 1 /H 1 /BPC 8 /CS /G ID x EI Q", Rect(200, 200, 250, 250)},
+		{"BT /F1 10 Tf 100 100 Td (Hello) Tj ET", Rect(100, 97.5, 125, 107.5)},
+		{"BT /F1 10 Tf 100 100 Td [(Hel) -1000 <6C6F>] TJ ET", Rect(100, 97.5, 135, 107.5)},
+		{"BT /F1 10 Tf 3 Tr 100 100 Td (Hello) Tj ET", nil},
+		{"BT /F1 10 Tf 12 TL 100 100 Td (a) Tj T* (b) Tj ET", Rect(100, 85.5, 105, 107.5)},
+	} {
+		cb := &contentBoxer{fonts: map[int]*cbFont{}, forms: map[int]bool{}}
+		gs := cbGState{ctm: identMatrix, clip: Rect(0, 0, 500, 500), lw: 1, th: 1}
+
+		if err := cb.process([]byte(tt.s), nil, gs); err != nil {
+			t.Fatalf("%s: %v\n", tt.s, err)
+		}
+
+		got := cb.box
+		if got == nil || tt.want == nil {
+			if got != tt.want {
+				t.Fatalf("%s: got %v, want %v\n", tt.s, got, tt.want)
+			}
+			continue
+		}
+
+		if math.Abs(got.LL.X-tt.want.LL.X) > .001 || math.Abs(got.LL.Y-tt.want.LL.Y) > .001 ||
+			math.Abs(got.UR.X-tt.want.UR.X) > .001 || math.Abs(got.UR.Y-tt.want.UR.Y) > .001 {
+			t.Fatalf("%s: got %v, want %v\n", tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pkg/errors"
+)
+
+// ContentHashes represents stable hex encoded SHA-256 hashes of a document's content.
+// Pages[i] is the hash of page i+1.
+type ContentHashes struct {
+	Document string   `json:"document"`
+	Pages    []string `json:"pages"`
+}
+
+// contentHasher feeds a canonical serialization of objects into a hash
+// independent of object numbers, dict key order, stream encoding and number formatting.
+type contentHasher struct {
+	xRefTable *XRefTable
+	h         hash.Hash
+	visited   map[int]int // object number -> visit ordinal
+}
+
+func newContentHasher(xRefTable *XRefTable) *contentHasher {
+	return &contentHasher{xRefTable: xRefTable, h: sha256.New(), visited: map[int]int{}}
+}
+
+func (ch *contentHasher) sum() string {
+	return hex.EncodeToString(ch.h.Sum(nil))
+}
+
+func (ch *contentHasher) writeBytes(bb []byte) {
+	fmt.Fprintf(ch.h, "s%d:", len(bb))
+	ch.h.Write(bb)
+}
+
+func (ch *contentHasher) writeNumber(f float64) {
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		fmt.Fprintf(ch.h, "n%d;", int64(f))
+		return
+	}
+	fmt.Fprintf(ch.h, "n%s;", strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+func (ch *contentHasher) writeRect(r *Rectangle) {
+	if r == nil {
+		ch.h.Write([]byte("null;"))
+		return
+	}
+	for _, f := range []float64{r.LL.X, r.LL.Y, r.UR.X, r.UR.Y} {
+		ch.writeNumber(f)
+	}
+}
+
+func (ch *contentHasher) writeObject(o Object) error {
+	if ir, ok := o.(IndirectRef); ok {
+		objNr := ir.ObjectNumber.Value()
+		if i, found := ch.visited[objNr]; found {
+			// Back reference to an object already hashed or in progress.
+			fmt.Fprintf(ch.h, "@%d;", i)
+			return nil
+		}
+		ch.visited[objNr] = len(ch.visited)
+	}
+
+	o, err := ch.xRefTable.Dereference(o)
+	if err != nil {
+		return err
+	}
+
+	switch o := o.(type) {
+
+	case nil:
+		ch.h.Write([]byte("null;"))
+
+	case Dict:
+		return ch.writeDict(o, nil)
+
+	case StreamDict:
+		return ch.writeStreamDict(o)
+
+	case Array:
+		ch.h.Write([]byte("["))
+		for _, o1 := range o {
+			if err := ch.writeObject(o1); err != nil {
+				return err
+			}
+		}
+		ch.h.Write([]byte("]"))
+
+	case StringLiteral:
+		bb, err := Unescape(o.Value())
+		if err != nil {
+			return err
+		}
+		ch.writeBytes(bb)
+
+	case HexLiteral:
+		bb, err := o.Bytes()
+		if err != nil {
+			return err
+		}
+		ch.writeBytes(bb)
+
+	case Integer:
+		ch.writeNumber(float64(o))
+
+	case Float:
+		ch.writeNumber(o.Value())
+
+	default:
+		fmt.Fprintf(ch.h, "%s;", o.PDFString())
+	}
+
+	return nil
+}
+
+// writeDict writes the entries of d in key order skipping all keys in ignore.
+func (ch *contentHasher) writeDict(d Dict, ignore StringSet) error {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		if !ignore[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	ch.h.Write([]byte("<<"))
+	for _, k := range keys {
+		fmt.Fprintf(ch.h, "/%s ", k)
+		if err := ch.writeObject(d[k]); err != nil {
+			return err
+		}
+	}
+	ch.h.Write([]byte(">>"))
+
+	return nil
+}
+
+// writeStreamDict writes decoded stream content if possible, otherwise the raw stream data.
+func (ch *contentHasher) writeStreamDict(sd StreamDict) error {
+	err := sd.Decode()
+	if err == nil {
+		if err := ch.writeDict(sd.Dict, streamEncodingKeys); err != nil {
+			return err
+		}
+		ch.writeBytes(sd.Content)
+		return nil
+	}
+
+	if err != filter.ErrUnsupportedFilter {
+		return err
+	}
+
+	if err := ch.writeDict(sd.Dict, nil); err != nil {
+		return err
+	}
+	ch.writeBytes(sd.Raw)
+
+	return nil
+}
+
+// writePageResources writes all resources referenced by content in resource type and name order.
+func (ch *contentHasher) writePageResources(resDict Dict, content []byte) error {
+	prn, err := parseContent(string(content))
+	if err != nil {
+		// Fall back to all resources.
+		return ch.writeObject(resDict)
+	}
+
+	resTypes := make([]string, 0, len(resourceTypes))
+	for resType := range resourceTypes {
+		resTypes = append(resTypes, resType)
+	}
+	sort.Strings(resTypes)
+
+	for _, resType := range resTypes {
+		names := prn.Resources(resType)
+		if len(names) == 0 {
+			continue
+		}
+		d, err := ch.xRefTable.DereferenceDict(resDict[resType])
+		if err != nil {
+			return err
+		}
+		nn := make([]string, 0, len(names))
+		for name := range names {
+			nn = append(nn, name)
+		}
+		sort.Strings(nn)
+		for _, name := range nn {
+			fmt.Fprintf(ch.h, "/%s/%s ", resType, name)
+			if err := ch.writeObject(d[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// PageContentHash returns a stable hash of page pageNr covering
+// the page boundaries and rotation, the page content and all resources referenced by the content.
+// Pages compared equal by EqualDocuments share the same hash.
+func (ctx *Context) PageContentHash(pageNr int) (string, error) {
+	d, _, pAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return "", err
+	}
+	if d == nil {
+		return "", errors.Errorf("pdfcpu: unknown page number: %d\n", pageNr)
+	}
+
+	bb, err := pageContent(ctx.XRefTable, d)
+	if err != nil {
+		return "", err
+	}
+
+	ch := newContentHasher(ctx.XRefTable)
+
+	ch.writeRect(pAttrs.mediaBox)
+	ch.writeRect(cropBox(pAttrs))
+	ch.writeNumber(float64((pAttrs.rotate%360 + 360) % 360))
+	ch.writeBytes(bb)
+
+	if err := ch.writePageResources(pAttrs.resources, bb); err != nil {
+		return "", err
+	}
+
+	return ch.sum(), nil
+}
+
+// ContentHashes returns stable hashes for ctx and each of its pages suitable for caching and change detection.
+// The document hash covers the page hashes and the document info
+// excluding volatile metadata like file IDs, dates and the producer.
+// It does not depend on the physical layout of the file like object numbers, stream encoding or incremental updates.
+func (ctx *Context) ContentHashes() (*ContentHashes, error) {
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	ch := newContentHasher(ctx.XRefTable)
+	fmt.Fprintf(ch.h, "pages %d;", ctx.PageCount)
+
+	d, err := infoDict(ctx.XRefTable)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		if !volatileInfoKeys[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s, err := infoText(ctx.XRefTable, d[k])
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(ch.h, "/%s ", k)
+		ch.writeBytes([]byte(s))
+	}
+
+	hashes := &ContentHashes{Pages: make([]string, ctx.PageCount)}
+
+	for i := range hashes.Pages {
+		s, err := ctx.PageContentHash(i + 1)
+		if err != nil {
+			return nil, err
+		}
+		hashes.Pages[i] = s
+		ch.h.Write([]byte(s))
+	}
+
+	hashes.Document = ch.sum()
+
+	return hashes, nil
+}
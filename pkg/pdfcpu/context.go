@@ -23,6 +23,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/pdfcpu/pdfcpu/pkg/log"
 )
@@ -31,11 +32,16 @@ import (
 type Context struct {
 	*Configuration
 	*XRefTable
-	Read         *ReadContext
-	Optimize     *OptimizationContext
-	Write        *WriteContext
-	writingPages bool // true, when writing page dicts.
-	dest         bool // true when writing a destination within a page.
+	Read           *ReadContext
+	Optimize       *OptimizationContext
+	Write          *WriteContext
+	writingPages   bool         // true, when writing page dicts.
+	dest           bool         // true when writing a destination within a page.
+	outlinesNested bool         // true, when the outline of a merge destination got nested.
+	mergedFiles    []mergedFile // files merged into a destination without outline so far.
+	mergeStamped   bool         // true, once the pages of a merge destination got stamped with its file name.
+	Timing         OperationTiming
+	warnings       *[]string // collects warnings instead of recording them if set, see prefetchObjects.
 }
 
 // NewContext initializes a new Context.
@@ -52,17 +58,34 @@ func NewContext(rs io.ReadSeeker, conf *Configuration) (*Context, error) {
 
 	ctx := &Context{
 		conf,
-		newXRefTable(conf.ValidationMode, conf.ValidateLinks),
+		newXRefTable(conf),
 		rdCtx,
 		newOptimizationContext(),
 		NewWriteContext(conf.Eol),
 		false,
 		false,
+		false,
+		nil,
+		false,
+		OperationTiming{},
+		nil,
 	}
 
+	ctx.SetClock(conf.Clock)
+	ctx.SetRand(conf.RandReader)
+
 	return ctx, nil
 }
 
+// Warn records a non fatal anomaly.
+func (ctx *Context) Warn(format string, args ...interface{}) {
+	if ctx.warnings != nil {
+		*ctx.warnings = append(*ctx.warnings, fmt.Sprintf(format, args...))
+		return
+	}
+	ctx.XRefTable.Warn(format, args...)
+}
+
 // ResetWriteContext prepares an existing WriteContext for a new file to be written.
 func (ctx *Context) ResetWriteContext() {
 	ctx.Write = NewWriteContext(ctx.Write.Eol)
@@ -227,6 +250,20 @@ func (ctx *Context) addPermissionsToInfoDigest(ss *[]string) {
 	}
 }
 
+func (ctx *Context) addWarningsToInfoDigest(ss *[]string) {
+	if len(ctx.Warnings) == 0 {
+		*ss = append(*ss, fmt.Sprintf("%20s: %s", "Warnings", "None"))
+		return
+	}
+	for i, s := range ctx.Warnings {
+		if i == 0 {
+			*ss = append(*ss, fmt.Sprintf("%20s: %s", "Warnings", s))
+			continue
+		}
+		*ss = append(*ss, fmt.Sprintf("%20s  %s", "", s))
+	}
+}
+
 func (ctx *Context) addAttachmentsToInfoDigest(ss *[]string) error {
 	aa, err := ctx.ListAttachments()
 	if err != nil {
@@ -272,8 +309,17 @@ type ReadContext struct {
 	Hybrid              bool          // File is a hybrid PDF file.
 	UsingObjectStreams  bool          // File is using object streams.
 	ObjectStreams       IntSet        // All object numbers of any object streams found which need to be decoded.
+	objStreamCache      []int         // Object numbers of object streams holding decoded content, least recently used first.
+	ObjStmCacheHits     int           // Number of compressed objects served by an already decoded object stream.
+	ObjStmCacheMisses   int           // Number of object stream decodings.
+	ObjectsParsed       int           // Number of objects parsed from file.
 	UsingXRefStreams    bool          // File is using xref streams.
 	XRefStreams         IntSet        // All object numbers of any xref streams found.
+	Repaired            bool          // Xref table reconstructed by scanning the whole file.
+	Pages               IntSet        // Pages loaded by a partial read, nil if all objects have been loaded.
+	Trace               []TraceEvent  // Parser decisions, recorded if Configuration.TraceParser is set.
+	ra                  io.ReaderAt   // Input for concurrent readers, nil if unsupported by rs.
+	mu                  sync.Mutex    // Guards read statistics and the loading of referenced objects while parsing concurrently.
 }
 
 func newReadContext(rs io.ReadSeeker) (*ReadContext, error) {
@@ -290,9 +336,39 @@ func newReadContext(rs io.ReadSeeker) (*ReadContext, error) {
 	}
 	rdCtx.FileSize = fileSize
 
+	if f, ok := rs.(*os.File); ok {
+		rdCtx.FileName = f.Name()
+	}
+
+	if ra, ok := rs.(io.ReaderAt); ok {
+		rdCtx.ra = ra
+	}
+
 	return rdCtx, nil
 }
 
+func (rc *ReadContext) lock() {
+	if rc != nil {
+		rc.mu.Lock()
+	}
+}
+
+func (rc *ReadContext) unlock() {
+	if rc != nil {
+		rc.mu.Unlock()
+	}
+}
+
+// positionedReader returns a buffered reader positioned at offset.
+// Readers based on an io.ReaderAt are independent of each other and may be used concurrently.
+func positionedReader(ctx *Context, offset int64) (*bufio.Reader, error) {
+	rc := ctx.Read
+	if rc.ra == nil {
+		return newPositionedReader(ctx, rc.rs, &offset)
+	}
+	return bufio.NewReader(io.NewSectionReader(rc.ra, offset, rc.FileSize-offset)), nil
+}
+
 // IsObjectStreamObject returns true if object i is a an object stream.
 // All compressed objects are object streams.
 func (rc *ReadContext) IsObjectStreamObject(i int) bool {
@@ -402,6 +478,8 @@ type OptimizationContext struct {
 
 	Cache     map[int]bool // For visited objects during optimization.
 	NullObjNr *int         // objNr of a regular null object, to be used for fixing references to free objects.
+
+	binarySizes [3]int64 // Stream data by category before optimizing.
 }
 
 func newOptimizationContext() *OptimizationContext {
@@ -679,6 +757,7 @@ type WriteContext struct {
 	Increment           bool          // Write context as PDF increment.
 	ObjNrs              []int         // Increment candidate object numbers.
 	OffsetPrevXRef      *int64        // Increment trailer entry "Prev".
+	binarySizes         [3]int64      // Stream data written by category.
 }
 
 // NewWriteContext returns a new WriteContext.
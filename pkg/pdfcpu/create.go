@@ -27,7 +27,6 @@ import (
 
 	"github.com/pdfcpu/pdfcpu/pkg/font"
 	"github.com/pdfcpu/pdfcpu/pkg/types"
-	"golang.org/x/text/unicode/norm"
 )
 
 // HAlignment represents the horizontal alignment of text.
@@ -52,6 +51,9 @@ const (
 	AlignBottom
 )
 
+// kappa is the control point distance for approximating a quarter circle by a Bézier curve.
+const kappa = 0.5523
+
 // LineJoinStyle represents the shape to be used at the corners of paths that are stroked (see 8.4.3.4)
 type LineJoinStyle int
 
@@ -90,6 +92,7 @@ type TextDescriptor struct {
 	BorderStyle    LineJoinStyle // Border style, also visible if ShowBorder is false as long as ShowBackground is true.
 	BorderCol      SimpleColor   // Border color.
 	ParIndent      bool          // Indent first line of paragraphs or space between paragraphs.
+	Hyphenator     *Hyphenator   // Hyphenate words at the end of justified lines (optional).
 	ShowLineBB     bool          // Render line bounding boxes in black (for HAlign != AlignJustify only)
 	ShowMargins    bool          // Render all margins in light gray.
 	HairCross      bool          // Draw haircross at X,Y.
@@ -114,17 +117,18 @@ func (fm FontMap) EnsureKey(fontName string) string {
 type Page struct {
 	MediaBox *Rectangle
 	Fm       FontMap
+	Im       ImageMap
 	Buf      *bytes.Buffer
 }
 
 // NewPage creates a page for a mediaBox.
 func NewPage(mediaBox *Rectangle) Page {
-	return Page{MediaBox: mediaBox, Fm: FontMap{}, Buf: new(bytes.Buffer)}
+	return Page{MediaBox: mediaBox, Fm: FontMap{}, Im: ImageMap{}, Buf: new(bytes.Buffer)}
 }
 
 // NewPageWithBg creates a page for a mediaBox.
 func NewPageWithBg(mediaBox *Rectangle, c SimpleColor) Page {
-	p := Page{MediaBox: mediaBox, Fm: FontMap{}, Buf: new(bytes.Buffer)}
+	p := Page{MediaBox: mediaBox, Fm: FontMap{}, Im: ImageMap{}, Buf: new(bytes.Buffer)}
 	FillRect(p.Buf, mediaBox, c)
 	return p
 }
@@ -213,6 +217,36 @@ func DrawAndFillRect(w io.Writer, r *Rectangle) {
 	fmt.Fprintf(w, "%.2f %.2f %.2f %.2f re B ", r.LL.X, r.LL.Y, r.Width(), r.Height())
 }
 
+// DrawRoundedRect strokes a rectangular path for r with corners rounded by radius.
+func DrawRoundedRect(w io.Writer, r *Rectangle, radius float64) {
+	if radius <= 0 {
+		DrawRect(w, r)
+		return
+	}
+
+	rad := radius
+	if m := r.Width() / 2; rad > m {
+		rad = m
+	}
+	if m := r.Height() / 2; rad > m {
+		rad = m
+	}
+	k := rad * kappa
+
+	x0, y0, x1, y1 := r.LL.X, r.LL.Y, r.UR.X, r.UR.Y
+
+	fmt.Fprintf(w, "%.2f %.2f m ", x0+rad, y0)
+	fmt.Fprintf(w, "%.2f %.2f l ", x1-rad, y0)
+	fmt.Fprintf(w, "%.2f %.2f %.2f %.2f %.2f %.2f c ", x1-rad+k, y0, x1, y0+rad-k, x1, y0+rad)
+	fmt.Fprintf(w, "%.2f %.2f l ", x1, y1-rad)
+	fmt.Fprintf(w, "%.2f %.2f %.2f %.2f %.2f %.2f c ", x1, y1-rad+k, x1-rad+k, y1, x1-rad, y1)
+	fmt.Fprintf(w, "%.2f %.2f l ", x0+rad, y1)
+	fmt.Fprintf(w, "%.2f %.2f %.2f %.2f %.2f %.2f c ", x0+rad-k, y1, x0, y1-rad+k, x0, y1-rad)
+	fmt.Fprintf(w, "%.2f %.2f l ", x0, y0+rad)
+	fmt.Fprintf(w, "%.2f %.2f %.2f %.2f %.2f %.2f c ", x0, y0+rad-k, x0+rad-k, y0, x0+rad, y0)
+	fmt.Fprint(w, "s ")
+}
+
 // SetFillColor sets the fill color.
 func SetFillColor(w io.Writer, c SimpleColor) {
 	fmt.Fprintf(w, "%.2f %.2f %.2f rg ", c.R, c.G, c.B)
@@ -340,31 +374,141 @@ func DrawHairCross(w io.Writer, x, y float64, r *Rectangle) {
 	DrawLine(w, x1, r.LL.Y, x1, r.LL.Y+r.Height()) // Vertical line
 }
 
-func prepBytes(s, fontName string, rtl bool) string {
-	if font.IsUserFont(fontName) {
-		ttf := font.UserFontMetrics[fontName]
-		bb := []byte{}
-		if rtl {
-			s = reverse(s)
+// textRun collects the TJ operands for glyphs sharing the same text rise.
+type textRun struct {
+	rise int      // Text rise in glyph space units.
+	ops  []string // TJ operands.
+	bb   []byte   // Pending glyph bytes.
+	adj  int      // Pending horizontal adjustment in glyph space units.
+}
+
+func (r *textRun) flushBytes() {
+	if len(r.bb) > 0 {
+		s, _ := Escape(string(r.bb))
+		r.ops = append(r.ops, "("+*s+")")
+		r.bb = nil
+	}
+}
+
+func (r *textRun) flushAdj() {
+	if r.adj != 0 {
+		r.ops = append(r.ops, strconv.Itoa(-r.adj))
+		r.adj = 0
+	}
+}
+
+func (r *textRun) addBytes(bb []byte) {
+	r.flushAdj()
+	r.bb = append(r.bb, bb...)
+}
+
+func (r *textRun) shift(dx int) {
+	if dx != 0 {
+		r.flushBytes()
+		r.adj += dx
+	}
+}
+
+// textShow renders text in visual order as a sequence of text showing operators.
+// User fonts are shaped which takes care of glyph substitution and positioning for complex scripts.
+type textShow struct {
+	runs []*textRun
+}
+
+func (ts *textShow) run(rise int) *textRun {
+	if n := len(ts.runs); n > 0 && ts.runs[n-1].rise == rise {
+		return ts.runs[n-1]
+	}
+	r := &textRun{rise: rise}
+	ts.runs = append(ts.runs, r)
+	return r
+}
+
+func (ts *textShow) current() *textRun {
+	if n := len(ts.runs); n > 0 {
+		return ts.runs[n-1]
+	}
+	return ts.run(0)
+}
+
+// shift moves the text position by dx glyph space units.
+func (ts *textShow) shift(dx int) {
+	ts.current().shift(dx)
+}
+
+// reverseClusters reverses the order of glyph clusters keeping the order of glyphs within each cluster.
+func reverseClusters(gg []font.Glyph) []font.Glyph {
+	out := make([]font.Glyph, 0, len(gg))
+	for j := len(gg); j > 0; {
+		i := j - 1
+		for i > 0 && gg[i-1].Cluster == gg[j-1].Cluster {
+			i--
 		}
-		for _, r := range s {
-			gid, ok := ttf.Chars[uint32(r)]
-			if ok {
-				bb = append(bb, byte((gid>>8)&0xFF))
-				bb = append(bb, byte(gid&0xFF))
-				ttf.UsedGIDs[gid] = true
-			}
+		out = append(out, gg[i:j]...)
+		j = i
+	}
+	return out
+}
+
+func (ts *textShow) addText(s, fontName string, rtl bool) {
+	if !font.IsUserFont(fontName) {
+		ts.run(0).addBytes([]byte(s))
+		return
+	}
+
+	ttf := font.UserFontMetrics[fontName]
+	gg := font.Shape(fontName, s)
+	if rtl {
+		gg = reverseClusters(gg)
+	}
+
+	for _, g := range gg {
+		r := ts.run(g.YOffset)
+		r.shift(g.XOffset)
+		r.addBytes([]byte{byte((g.GID >> 8) & 0xFF), byte(g.GID & 0xFF)})
+		ttf.UsedGIDs[g.GID] = true
+		r.shift(g.XAdvance - ttf.GlyphWidth(g.GID) - g.XOffset)
+	}
+}
+
+// String returns the text showing operators for a font size of fontSize.
+func (ts *textShow) String(fontSize int) string {
+	n := 0
+	for _, r := range ts.runs {
+		r.flushBytes()
+		r.flushAdj()
+		n += len(r.ops)
+	}
+
+	if n == 0 {
+		return "() Tj"
+	}
+
+	if len(ts.runs) == 1 && ts.runs[0].rise == 0 && len(ts.runs[0].ops) == 1 && ts.runs[0].ops[0][0] == '(' {
+		return ts.runs[0].ops[0] + " Tj"
+	}
+
+	ss := []string{}
+	for _, r := range ts.runs {
+		if len(r.ops) == 0 {
+			continue
+		}
+		if r.rise != 0 {
+			ss = append(ss, fmt.Sprintf("%.2f Ts", font.UserSpaceUnits(float64(r.rise), fontSize)))
+		}
+		ss = append(ss, "[ "+strings.Join(r.ops, " ")+" ] TJ")
+		if r.rise != 0 {
+			ss = append(ss, "0 Ts")
 		}
-		s = string(bb)
 	}
-	s1, _ := Escape(s)
-	return *s1
+	return strings.Join(ss, " ")
 }
 
-func writeStringToBuf(w io.Writer, s string, x, y float64, td TextDescriptor) {
-	s = prepBytes(s, td.FontName, td.RTL)
-	fmt.Fprintf(w, "BT 0 Tw %.2f %.2f %.2f RG %.2f %.2f %.2f rg %.2f %.2f Td %d Tr (%s) Tj ET ",
-		td.StrokeCol.R, td.StrokeCol.G, td.StrokeCol.B, td.FillCol.R, td.FillCol.G, td.FillCol.B, x, y, td.RMode, s)
+func writeStringToBuf(w io.Writer, s string, x, y float64, fontSize int, td TextDescriptor) {
+	ts := textShow{}
+	ts.addText(s, td.FontName, td.RTL)
+	fmt.Fprintf(w, "BT 0 Tw %.2f %.2f %.2f RG %.2f %.2f %.2f rg %.2f %.2f Td %d Tr %s ET ",
+		td.StrokeCol.R, td.StrokeCol.G, td.StrokeCol.B, td.FillCol.R, td.FillCol.G, td.FillCol.B, x, y, td.RMode, ts.String(fontSize))
 }
 
 func setFont(w io.Writer, fontID string, fontSize float32) {
@@ -408,9 +552,7 @@ func horAdjustBoundingBoxForLines(r, box *Rectangle, dx, dy float64, x, y *float
 }
 
 func prepJustifiedLine(lines *[]string, strbuf []string, strWidth, w float64, fontSize int, fontName string, rtl bool) {
-	blank := prepBytes(" ", fontName, false)
-	var sb strings.Builder
-	sb.WriteString("[")
+	ts := textShow{}
 	wc := len(strbuf)
 	dx := font.GlyphSpaceUnits(float64((w-strWidth))/float64(wc-1), fontSize)
 	for i := 0; i < wc; i++ {
@@ -418,19 +560,19 @@ func prepJustifiedLine(lines *[]string, strbuf []string, strWidth, w float64, fo
 		if rtl {
 			j = wc - 1 - i
 		}
-		s := prepBytes(strbuf[j], fontName, rtl)
-		sb.WriteString(fmt.Sprintf(" (%s)", s))
+		ts.addText(strbuf[j], fontName, rtl)
 		if i < wc-1 {
-			sb.WriteString(fmt.Sprintf(" %d (%s)", -int(dx), blank))
+			ts.shift(int(dx))
+			ts.addText(" ", fontName, false)
 		}
 	}
-	sb.WriteString(" ] TJ")
-	*lines = append(*lines, sb.String())
+	*lines = append(*lines, ts.String(fontSize))
 }
 
 func newPrepJustifiedString(
 	fontName string,
-	fontSize int) func(lines *[]string, s string, w float64, fontName string, fontSize *int, lastline, parIndent, rtl bool) int {
+	fontSize int,
+	hy *Hyphenator) func(lines *[]string, s string, w float64, fontName string, fontSize *int, lastline, parIndent, rtl bool) int {
 
 	// Not yet rendered content.
 	strbuf := []string{}
@@ -450,14 +592,12 @@ func newPrepJustifiedString(
 
 		if len(s) == 0 {
 			if len(strbuf) > 0 {
-				s1 := prepBytes(strings.Join(strbuf, " "), fontName, rtl)
+				ts := textShow{}
 				if rtl {
-					dx := font.GlyphSpaceUnits(w-strWidth, *fontSize)
-					s = fmt.Sprintf("[ %d (%s) ] TJ ", -int(dx), s1)
-				} else {
-					s = fmt.Sprintf("(%s) Tj", s1)
+					ts.shift(int(font.GlyphSpaceUnits(w-strWidth, *fontSize)))
 				}
-				*lines = append(*lines, s)
+				ts.addText(strings.Join(strbuf, " "), fontName, rtl)
+				*lines = append(*lines, ts.String(*fontSize))
 				strbuf = []string{}
 				strWidth = 0
 			}
@@ -477,7 +617,8 @@ func newPrepJustifiedString(
 			ss[0] = identPrefix + ss[0]
 		}
 
-		for _, s1 := range ss {
+		for i := 0; i < len(ss); i++ {
+			s1 := ss[i]
 			s1Width := font.TextWidth(s1, fontName, *fontSize)
 			bw := 0.
 			if len(strbuf) > 0 {
@@ -488,6 +629,20 @@ func newPrepJustifiedString(
 				strbuf = append(strbuf, s1)
 				continue
 			}
+			if hy != nil {
+				// Fill up this line with the leading syllables of s1 and continue with the rest.
+				if head, tail := hy.hyphenateToFit(s1, w-strWidth-bw, fontName, *fontSize); head != "" {
+					strWidth += font.TextWidth(head, fontName, *fontSize) + bw
+					prepJustifiedLine(lines, append(strbuf, head), strWidth, w, *fontSize, fontName, rtl)
+					strbuf = []string{}
+					strWidth = 0
+					ss[i] = tail
+					i--
+					linefeeds++
+					indent = false
+					continue
+				}
+			}
 			// Ensure s1 fits into w.
 			fs := font.Size(s1, fontName, w)
 			if fs < *fontSize {
@@ -529,7 +684,7 @@ func preRenderJustifiedText(
 		}
 	}
 	ww -= mLeft + mRight + 2*borderWidth
-	prepJustifiedString := newPrepJustifiedString(td.FontName, *fontSize)
+	prepJustifiedString := newPrepJustifiedString(td.FontName, *fontSize, td.Hyphenator)
 	l := []string{}
 	for i, s := range *lines {
 		linefeeds := prepJustifiedString(&l, s, ww, td.FontName, fontSize, false, td.ParIndent, td.RTL)
@@ -717,16 +872,6 @@ func renderBackgroundAndBorder(w io.Writer, td TextDescriptor, borderWidth float
 	}
 }
 
-func reverse(s string) string {
-	inRunes := []rune(norm.NFC.String(s))
-	outRunes := make([]rune, len(inRunes))
-	iMax := len(inRunes) - 1
-	for i, r := range inRunes {
-		outRunes[iMax-i] = r
-	}
-	return string(outRunes)
-}
-
 func renderText(w io.Writer, lines []string, td TextDescriptor, x, y float64, fontSize int) {
 	lh := font.LineHeight(td.FontName, fontSize)
 	for _, s := range lines {
@@ -746,7 +891,7 @@ func renderText(w io.Writer, lines []string, td TextDescriptor, x, y float64, fo
 				SetStrokeColor(w, Black)
 				DrawRect(w, lineBB)
 			}
-			writeStringToBuf(w, s, x-dx, y, td)
+			writeStringToBuf(w, s, x-dx, y, fontSize, td)
 			y -= lh
 			continue
 		}
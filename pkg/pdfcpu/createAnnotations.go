@@ -18,7 +18,6 @@ package pdfcpu
 
 import (
 	"path/filepath"
-	"time"
 )
 
 // Functions needed to create a test.pdf that gets used for validation testing (see process_test.go)
@@ -542,7 +541,7 @@ func createFileAttachmentAnnotation(xRefTable *XRefTable, pageIndRef IndirectRef
 		return nil, err
 	}
 
-	now := StringLiteral(DateString(time.Now()))
+	now := StringLiteral(DateString(xRefTable.Now()))
 
 	d := Dict(
 		map[string]Object{
@@ -1195,7 +1194,7 @@ func createTrapNetAnnotation(xRefTable *XRefTable, pageIndRef IndirectRef, annot
 			"Border":       NewIntegerArray(0, 0, 3),
 			"C":            NewNumberArray(0.2, 0.8, 0.5),
 			"F":            Integer(0),
-			"LastModified": StringLiteral(DateString(time.Now())),
+			"LastModified": StringLiteral(DateString(xRefTable.Now())),
 			"FontFauxing":  Array{*ir},
 		},
 	)
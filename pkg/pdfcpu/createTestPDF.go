@@ -22,6 +22,8 @@ import (
 	"bytes"
 	"fmt"
 	"path/filepath"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
 )
 
 var (
@@ -1545,10 +1547,11 @@ func createOffAppearance(xRefTable *XRefTable, resourceDict Dict, w, h float64)
 	return xRefTable.IndRefForNewObject(*sd)
 }
 
-func createCheckBoxButtonField(xRefTable *XRefTable, pageAnnots *Array) (*IndirectRef, error) {
+// buttonAppearances returns the appearances for the on and off state of check boxes and radio buttons.
+func buttonAppearances(xRefTable *XRefTable, w, h float64) (*IndirectRef, *IndirectRef, error) {
 	fontDict, err := createFontDict(xRefTable, "ZapfDingbats")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	resDict := Dict(
@@ -1561,12 +1564,22 @@ func createCheckBoxButtonField(xRefTable *XRefTable, pageAnnots *Array) (*Indire
 		},
 	)
 
-	yesForm, err := createYesAppearance(xRefTable, resDict, 20.0, 20.0)
+	onForm, err := createYesAppearance(xRefTable, resDict, w, h)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	offForm, err := createOffAppearance(xRefTable, resDict, w, h)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	offForm, err := createOffAppearance(xRefTable, resDict, 20.0, 20.0)
+	return onForm, offForm, nil
+}
+
+// createCheckBoxButtonField creates a check box using onValue as the export value of its checked state.
+func createCheckBoxButtonField(xRefTable *XRefTable, pageAnnots *Array, name, onValue string, checked bool, r *Rectangle) (*IndirectRef, error) {
+	onForm, offForm, err := buttonAppearances(xRefTable, r.Width(), r.Height())
 	if err != nil {
 		return nil, err
 	}
@@ -1575,23 +1588,28 @@ func createCheckBoxButtonField(xRefTable *XRefTable, pageAnnots *Array) (*Indire
 		map[string]Object{
 			"N": Dict(
 				map[string]Object{
-					"Yes": *yesForm,
-					"Off": *offForm,
+					onValue: *onForm,
+					"Off":   *offForm,
 				},
 			),
 		},
 	)
 
+	state := "Off"
+	if checked {
+		state = onValue
+	}
+
 	d := Dict(
 		map[string]Object{
 			"FT":      Name("Btn"),
-			"Rect":    NewNumberArray(250, 300, 270, 320),
+			"Rect":    r.Array(),
 			"Type":    Name("Annot"),
 			"Subtype": Name("Widget"),
-			"T":       StringLiteral("CheckBox"),
-			"TU":      StringLiteral("CheckBox"),
-			"V":       Name("Yes"),
-			"AS":      Name("Yes"),
+			"T":       StringLiteral(name),
+			"TU":      StringLiteral(name),
+			"V":       Name(state),
+			"AS":      Name(state),
 			"AP":      apDict,
 		},
 	)
@@ -1606,19 +1624,25 @@ func createCheckBoxButtonField(xRefTable *XRefTable, pageAnnots *Array) (*Indire
 	return ir, nil
 }
 
-func createRadioButtonField(xRefTable *XRefTable, pageAnnots *Array) (*IndirectRef, error) {
+// createRadioButtonField creates a group of mutually exclusive radio buttons,
+// one widget for each export value laid out from left to right starting at r.
+// The widgets are kids of the field without names of their own, hence at most one of them is on.
+func createRadioButtonField(xRefTable *XRefTable, pageAnnots *Array, name string, values []string, selected string, r *Rectangle) (*IndirectRef, error) {
 	var flags uint32
-	flags = setBit(flags, 16)
+	flags = setBit(flags, 15) // NoToggleToOff
+	flags = setBit(flags, 16) // Radio
+
+	if selected == "" {
+		selected = "Off"
+	}
 
 	d := Dict(
 		map[string]Object{
-			"FT":   Name("Btn"),
-			"Ff":   Integer(flags),
-			"Rect": NewNumberArray(250, 400, 280, 420),
-			//"Type":    Name("Annot"),
-			//"Subtype": Name("Widget"),
-			"T": StringLiteral("Credit card"),
-			"V": Name("card1"),
+			"FT": Name("Btn"),
+			"Ff": Integer(flags),
+			"T":  StringLiteral(name),
+			"TU": StringLiteral(name),
+			"V":  Name(selected),
 		},
 	)
 
@@ -1627,91 +1651,84 @@ func createRadioButtonField(xRefTable *XRefTable, pageAnnots *Array) (*IndirectR
 		return nil, err
 	}
 
-	fontDict, err := createFontDict(xRefTable, "ZapfDingbats")
+	onForm, offForm, err := buttonAppearances(xRefTable, r.Width(), r.Height())
 	if err != nil {
 		return nil, err
 	}
 
-	resDict := Dict(
-		map[string]Object{
-			"Font": Dict(
-				map[string]Object{
-					"ZaDb": *fontDict,
-				},
-			),
-		},
-	)
+	kids := Array{}
+	dx := r.Width() + 20
 
-	selectedForm, err := createYesAppearance(xRefTable, resDict, 20.0, 20.0)
-	if err != nil {
-		return nil, err
-	}
+	for i, v := range values {
+		state := "Off"
+		if v == selected {
+			state = v
+		}
 
-	offForm, err := createOffAppearance(xRefTable, resDict, 20.0, 20.0)
-	if err != nil {
-		return nil, err
+		r1 := Rect(r.LL.X+float64(i)*dx, r.LL.Y, r.UR.X+float64(i)*dx, r.UR.Y)
+
+		w := Dict(
+			map[string]Object{
+				"Rect":    r1.Array(),
+				"Type":    Name("Annot"),
+				"Subtype": Name("Widget"),
+				"Parent":  *indRef,
+				"AS":      Name(state),
+				"AP": Dict(
+					map[string]Object{
+						"N": Dict(
+							map[string]Object{
+								v:     *onForm,
+								"Off": *offForm,
+							},
+						),
+					},
+				),
+			},
+		)
+
+		ir, err := xRefTable.IndRefForNewObject(w)
+		if err != nil {
+			return nil, err
+		}
+
+		kids = append(kids, *ir)
+		*pageAnnots = append(*pageAnnots, *ir)
 	}
 
-	r1 := Dict(
-		map[string]Object{
-			"Rect":    NewNumberArray(250, 400, 280, 420),
-			"Type":    Name("Annot"),
-			"Subtype": Name("Widget"),
-			"Parent":  *indRef,
-			"T":       StringLiteral("Radio1"),
-			"TU":      StringLiteral("Radio1"),
-			"AS":      Name("card1"),
-			"AP": Dict(
-				map[string]Object{
-					"N": Dict(
-						map[string]Object{
-							"card1": *selectedForm,
-							"Off":   *offForm,
-						},
-					),
-				},
-			),
-		},
-	)
+	d.Insert("Kids", kids)
 
-	indRefR1, err := xRefTable.IndRefForNewObject(r1)
+	return indRef, nil
+}
+
+// createSignatureField creates an unsigned signature field serving as placeholder for a signature to be applied later.
+func createSignatureField(xRefTable *XRefTable, pageAnnots *Array, name string, r *Rectangle) (*IndirectRef, error) {
+	fN, err := createNormalAppearanceForFormField(xRefTable, r.Width(), r.Height())
 	if err != nil {
 		return nil, err
 	}
 
-	r2 := Dict(
+	d := Dict(
 		map[string]Object{
-			"Rect":    NewNumberArray(300, 400, 330, 420),
+			"FT":      Name("Sig"),
+			"Rect":    r.Array(),
 			"Type":    Name("Annot"),
 			"Subtype": Name("Widget"),
-			"Parent":  *indRef,
-			"T":       StringLiteral("Radio2"),
-			"TU":      StringLiteral("Radio2"),
-			"AS":      Name("Off"),
-			"AP": Dict(
-				map[string]Object{
-					"N": Dict(
-						map[string]Object{
-							"card2": *selectedForm,
-							"Off":   *offForm,
-						},
-					),
-				},
-			),
+			"F":       Integer(AnnPrint),
+			"T":       StringLiteral(name),
+			"TU":      StringLiteral(name),
+			"AP":      Dict(map[string]Object{"N": *fN}),
 		},
 	)
 
-	indRefR2, err := xRefTable.IndRefForNewObject(r2)
+	ir, err := xRefTable.IndRefForNewObject(d)
 	if err != nil {
 		return nil, err
 	}
 
-	d.Insert("Kids", Array{*indRefR1, *indRefR2})
-
-	*pageAnnots = append(*pageAnnots, *indRefR1)
-	*pageAnnots = append(*pageAnnots, *indRefR2)
+	*pageAnnots = append(*pageAnnots, *ir)
 
-	return indRef, nil
+	return ir, nil
 }
 
 func createResetButton(xRefTable *XRefTable, pageAnnots *Array) (*IndirectRef, error) {
@@ -1844,12 +1861,22 @@ func createAcroFormDict(xRefTable *XRefTable, fontName string) (Dict, Array, err
 		return nil, nil, err
 	}
 
-	checkBox, err := createCheckBoxButtonField(xRefTable, &pageAnnots)
+	checkBox, err := createCheckBoxButtonField(xRefTable, &pageAnnots, "CheckBox", "Yes", true, Rect(250, 300, 270, 320))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	terms, err := createCheckBoxButtonField(xRefTable, &pageAnnots, "Terms", "Accepted", false, Rect(300, 300, 320, 320))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	radioButton, err := createRadioButtonField(xRefTable, &pageAnnots, "Credit card", []string{"card1", "card2"}, "card1", Rect(250, 400, 270, 420))
 	if err != nil {
 		return nil, nil, err
 	}
 
-	radioButton, err := createRadioButtonField(xRefTable, &pageAnnots)
+	signature, err := createSignatureField(xRefTable, &pageAnnots, "Signature", Rect(250, 200, 400, 240))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1871,8 +1898,9 @@ func createAcroFormDict(xRefTable *XRefTable, fontName string) (Dict, Array, err
 
 	d := Dict(
 		map[string]Object{
-			"Fields":          Array{*text, *checkBox, *radioButton, *resetButton, *submitButton}, // indRefs of fieldDicts
+			"Fields":          Array{*text, *checkBox, *terms, *radioButton, *signature, *resetButton, *submitButton}, // indRefs of fieldDicts
 			"NeedAppearances": Boolean(true),
+			"SigFlags":        Integer(1), // SignaturesExist
 			"CO":              Array{*text},
 			"XFA":             xfaArr,
 		},
@@ -1925,6 +1953,9 @@ func CreateContext(xRefTable *XRefTable, conf *Configuration) *Context {
 		conf = NewDefaultConfiguration()
 	}
 	xRefTable.ValidationMode = conf.ValidationMode
+	if conf.Loggers != nil {
+		xRefTable.Log = log.NewContextLoggers(conf.Loggers)
+	}
 	return &Context{
 		Configuration: conf,
 		XRefTable:     xRefTable,
@@ -1983,17 +2014,18 @@ func createDemoPage(xRefTable *XRefTable, parentPageIndRef IndirectRef, p Page)
 		},
 	)
 
-	fontRes, err := fontResources(xRefTable, p.Fm)
+	rm := NewResourceManager(xRefTable)
+
+	resDict, err := rm.PageResources(p)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(fontRes) > 0 {
-		resDict := Dict(
-			map[string]Object{
-				"Font": fontRes,
-			},
-		)
+	if err := rm.Finalize(); err != nil {
+		return nil, err
+	}
+
+	if len(resDict) > 0 {
 		pageDict.Insert("Resources", resDict)
 	}
 
@@ -23,15 +23,16 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/md5"
-	"crypto/rand"
 	"crypto/rc4"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/pdfcpu/pdfcpu/pkg/log"
 	"github.com/pkg/errors"
@@ -45,43 +46,90 @@ var (
 
 	nullPad32 = make([]byte, 32)
 
-	// Needed permission bits for pdfcpu commands.
-	perm = map[CommandMode]struct{ extract, modify int }{
-		VALIDATE:                {0, 0},
-		OPTIMIZE:                {0, 0},
-		SPLIT:                   {1, 0},
-		MERGECREATE:             {0, 0},
-		MERGEAPPEND:             {0, 0},
-		EXTRACTIMAGES:           {1, 0},
-		EXTRACTFONTS:            {1, 0},
-		EXTRACTPAGES:            {1, 0},
-		EXTRACTCONTENT:          {1, 0},
-		EXTRACTMETADATA:         {1, 0},
-		TRIM:                    {0, 1},
-		LISTATTACHMENTS:         {0, 0},
-		EXTRACTATTACHMENTS:      {1, 0},
-		ADDATTACHMENTS:          {0, 1},
-		ADDATTACHMENTSPORTFOLIO: {0, 1},
-		REMOVEATTACHMENTS:       {0, 1},
-		LISTPERMISSIONS:         {0, 0},
-		SETPERMISSIONS:          {0, 0},
-		ADDWATERMARKS:           {0, 1},
-		REMOVEWATERMARKS:        {0, 1},
-		INSERTPAGESBEFORE:       {0, 1},
-		INSERTPAGESAFTER:        {0, 1},
-		REMOVEPAGES:             {0, 1},
-		LISTKEYWORDS:            {0, 0},
-		ADDKEYWORDS:             {0, 1},
-		REMOVEKEYWORDS:          {0, 1},
-		LISTPROPERTIES:          {0, 0},
-		ADDPROPERTIES:           {0, 1},
-		REMOVEPROPERTIES:        {0, 1},
-		COLLECT:                 {1, 0},
-		CROP:                    {0, 1},
-		LISTBOXES:               {0, 0},
-		ADDBOXES:                {0, 1},
-		REMOVEBOXES:             {0, 1},
-		LISTIMAGES:              {0, 1},
+	// Needed permission bits for pdfcpu commands and whether a command leaves its input document untouched
+	// and writes nothing besides its output file or directory.
+	// Every CommandMode needs an entry, see ReadOnlyCmd.
+	perm = map[CommandMode]struct {
+		extract, modify int
+		readOnly        bool
+	}{
+		VALIDATE:                {0, 0, true},
+		OPTIMIZE:                {0, 0, false},
+		SPLIT:                   {1, 0, false},
+		MERGECREATE:             {0, 0, false},
+		MERGEAPPEND:             {0, 0, false},
+		EXTRACTIMAGES:           {1, 0, true},
+		EXTRACTFONTS:            {1, 0, true},
+		EXTRACTPAGES:            {1, 0, false},
+		EXTRACTCONTENT:          {1, 0, true},
+		EXTRACTMETADATA:         {1, 0, true},
+		TRIM:                    {0, 1, false},
+		LISTATTACHMENTS:         {0, 0, true},
+		EXTRACTATTACHMENTS:      {1, 0, true},
+		ADDATTACHMENTS:          {0, 1, false},
+		ADDATTACHMENTSPORTFOLIO: {0, 1, false},
+		REMOVEATTACHMENTS:       {0, 1, false},
+		LISTPERMISSIONS:         {0, 0, true},
+		SETPERMISSIONS:          {0, 0, false},
+		ADDWATERMARKS:           {0, 1, false},
+		REMOVEWATERMARKS:        {0, 1, false},
+		INSERTPAGESBEFORE:       {0, 1, false},
+		INSERTPAGESAFTER:        {0, 1, false},
+		REMOVEPAGES:             {0, 1, false},
+		LISTKEYWORDS:            {0, 0, true},
+		ADDKEYWORDS:             {0, 1, false},
+		REMOVEKEYWORDS:          {0, 1, false},
+		LISTPROPERTIES:          {0, 0, true},
+		ADDPROPERTIES:           {0, 1, false},
+		REMOVEPROPERTIES:        {0, 1, false},
+		COLLECT:                 {1, 0, false},
+		CROP:                    {0, 1, false},
+		LISTBOXES:               {0, 0, true},
+		ADDBOXES:                {0, 1, false},
+		REMOVEBOXES:             {0, 1, false},
+		LISTIMAGES:              {0, 1, true},
+		INTERNALIZE:             {0, 1, false},
+		CLIP:                    {1, 0, false},
+		LABELS:                  {1, 0, false},
+		DECORATE:                {0, 1, false},
+		AUTOCROP:                {0, 1, false},
+		ROTATECONTENT:           {0, 1, false},
+		LISTFORMORDER:           {0, 0, true},
+		SETFORMORDER:            {0, 1, false},
+		EXTRACTTEXT:             {1, 0, true},
+		EXTRACTTABLES:           {1, 0, true},
+		DUPLICATES:              {1, 0, true},
+		RUN:                     {0, 1, false},
+		LISTFORMFIELDS:          {0, 0, true},
+		EXPORTFORMFIELDS:        {1, 0, true},
+		FILLFORMFIELDS:          {0, 1, false},
+		FILLTEMPLATE:            {0, 1, false},
+		PAGESIZES:               {0, 0, true},
+		ORPHANS:                 {0, 0, true},
+		RESIZE:                  {0, 1, false},
+		VALIDATESIGNATURES:      {0, 0, true},
+		FEATURES:                {0, 0, true},
+		PREVIEW:                 {0, 0, true},
+		EXTRACTSVG:              {1, 0, true},
+		ENCRYPT:                 {0, 0, false},
+		DECRYPT:                 {0, 0, false},
+		CHANGEUPW:               {0, 0, false},
+		CHANGEOPW:               {0, 0, false},
+		IMPORTIMAGES:            {0, 0, false},
+		ROTATE:                  {0, 0, false},
+		NUP:                     {0, 0, false},
+		BOOKLET:                 {0, 0, false},
+		INFO:                    {0, 0, true},
+		CHEATSHEETSFONTS:        {0, 0, false},
+		INSTALLFONTS:            {0, 0, false},
+		LISTFONTS:               {0, 0, true},
+		LISTANNOTATIONS:         {0, 0, true},
+		ADDANNOTATIONS:          {0, 0, false},
+		REMOVEANNOTATIONS:       {0, 0, false},
+		ADDBOOKMARKS:            {0, 0, false},
+		MANIFEST:                {0, 0, true},
+		VERIFY:                  {0, 0, true},
+		ENCRYPTIONINFO:          {0, 0, true},
 	}
 )
 
@@ -94,12 +142,12 @@ func newEncryptDict(needAES bool, keyLength int, permissions int16) Dict {
 
 	if keyLength >= 128 {
 		d.Insert("Length", Integer(keyLength))
-		i := 4
+		r, v := 4, 4
 		if keyLength == 256 {
-			i = 5
+			r, v = 6, 5
 		}
-		d.Insert("R", Integer(i))
-		d.Insert("V", Integer(i))
+		d.Insert("R", Integer(r))
+		d.Insert("V", Integer(v))
 	} else {
 		d.Insert("R", Integer(2))
 		d.Insert("V", Integer(1))
@@ -199,7 +247,7 @@ func encKey(userpw string, e *Enc) (key []byte) {
 // validateUserPassword validates the user password aka document open password.
 func validateUserPassword(ctx *Context) (ok bool, err error) {
 
-	if ctx.E.R == 5 {
+	if ctx.E.R >= 5 {
 		return validateUserPasswordAES256(ctx)
 	}
 
@@ -380,33 +428,106 @@ func keySalt(bb []byte) []byte {
 	return bb[40:]
 }
 
+// hashAES256 computes the hash for password pw, salt and the optional user key u.
+// R5 uses plain SHA-256, R6 uses the iterated hash of 7.6.4.3.4 Algorithm 2.B.
+func hashAES256(pw, salt, u []byte, r int) ([]byte, error) {
+
+	b := append(append(append([]byte{}, pw...), salt...), u...)
+	s := sha256.Sum256(b)
+	k := s[:]
+
+	if r < 6 {
+		return k, nil
+	}
+
+	var e []byte
+
+	for i := 0; i < 64 || int(e[len(e)-1]) > i-32; i++ {
+
+		// a) K1 = 64 repetitions of pw + K + u
+		k1 := make([]byte, 0, 64*(len(pw)+len(k)+len(u)))
+		for j := 0; j < 64; j++ {
+			k1 = append(k1, pw...)
+			k1 = append(k1, k...)
+			k1 = append(k1, u...)
+		}
+
+		// b) Encrypt K1 using AES-128 (CBC, no padding) with key = first 16 bytes of K and iv = second 16 bytes of K.
+		cb, err := aes.NewCipher(k[:16])
+		if err != nil {
+			return nil, err
+		}
+		e = make([]byte, len(k1))
+		cipher.NewCBCEncrypter(cb, k[16:32]).CryptBlocks(e, k1)
+
+		// c) Select the next hash function based on the first 16 bytes of E mod 3.
+		var sum int
+		for _, c := range e[:16] {
+			sum += int(c)
+		}
+
+		// d) Calculate the next K.
+		switch sum % 3 {
+		case 0:
+			h := sha256.Sum256(e)
+			k = h[:]
+		case 1:
+			h := sha512.Sum384(e)
+			k = h[:]
+		case 2:
+			h := sha512.Sum512(e)
+			k = h[:]
+		}
+	}
+
+	return k[:32], nil
+}
+
+// emptyUserPassword returns true if the document opens using an empty user password.
+func emptyUserPassword(ctx *Context) (bool, error) {
+	upw, key := ctx.UserPW, ctx.EncKey
+	defer func() {
+		ctx.UserPW, ctx.EncKey = upw, key
+	}()
+	ctx.UserPW = ""
+	return validateUserPassword(ctx)
+}
+
+// checkEncryptionStrength warns about encryption susceptible to brute force attacks.
+func checkEncryptionStrength(ctx *Context, d Dict) {
+	cfms, ll := cryptMethods(d)
+	for i, cfm := range cfms {
+		if cfm == "V2" {
+			ctx.Warn("weak encryption: RC4 %d bit (V=%d, R=%d), passwords may be recovered by brute force", ll[i], ctx.E.V, ctx.E.R)
+			return
+		}
+	}
+}
+
 func validateOwnerPasswordAES256(ctx *Context) (ok bool, err error) {
 
 	if len(ctx.OwnerPW) == 0 {
 		return false, nil
 	}
 
-	// TODO Process PW with SASLPrep profile (RFC 4013) of stringprep (RFC 3454).
-	opw := []byte(ctx.OwnerPW)
-	if len(opw) > 127 {
-		opw = opw[:127]
-	}
-	//fmt.Printf("opw <%s> isValidUTF8String: %t\n", opw, utf8.Valid(opw))
+	opw := aes256Password(ctx.OwnerPW)
 
 	// Algorithm 3.2a 3.
-	b := append(opw, validationSalt(ctx.E.O)...)
-	b = append(b, ctx.E.U...)
-	s := sha256.Sum256(b)
+	s, err := hashAES256(opw, validationSalt(ctx.E.O), ctx.E.U, ctx.E.R)
+	if err != nil {
+		return false, err
+	}
 
-	if !bytes.HasPrefix(ctx.E.O, s[:]) {
+	if !bytes.HasPrefix(ctx.E.O, s) {
 		return false, nil
 	}
 
-	b = append(opw, keySalt(ctx.E.O)...)
-	b = append(b, ctx.E.U...)
-	key := sha256.Sum256(b)
+	key, err := hashAES256(opw, keySalt(ctx.E.O), ctx.E.U, ctx.E.R)
+	if err != nil {
+		return false, err
+	}
 
-	cb, err := aes.NewCipher(key[:])
+	cb, err := aes.NewCipher(key)
 	if err != nil {
 		return false, err
 	}
@@ -422,23 +543,24 @@ func validateOwnerPasswordAES256(ctx *Context) (ok bool, err error) {
 
 func validateUserPasswordAES256(ctx *Context) (ok bool, err error) {
 
-	// TODO Process PW with SASLPrep profile (RFC 4013) of stringprep (RFC 3454).
-	upw := []byte(ctx.UserPW)
-	if len(upw) > 127 {
-		upw = upw[:127]
-	}
-	//fmt.Printf("upw <%s> isValidUTF8String: %t\n", upw, utf8.Valid(upw))
+	upw := aes256Password(ctx.UserPW)
 
 	// Algorithm 3.2a 4,
-	s := sha256.Sum256(append(upw, validationSalt(ctx.E.U)...))
+	s, err := hashAES256(upw, validationSalt(ctx.E.U), nil, ctx.E.R)
+	if err != nil {
+		return false, err
+	}
 
-	if !bytes.HasPrefix(ctx.E.U, s[:]) {
+	if !bytes.HasPrefix(ctx.E.U, s) {
 		return false, nil
 	}
 
-	key := sha256.Sum256(append(upw, keySalt(ctx.E.U)...))
+	key, err := hashAES256(upw, keySalt(ctx.E.U), nil, ctx.E.R)
+	if err != nil {
+		return false, err
+	}
 
-	cb, err := aes.NewCipher(key[:])
+	cb, err := aes.NewCipher(key)
 	if err != nil {
 		return false, err
 	}
@@ -457,7 +579,7 @@ func validateOwnerPassword(ctx *Context) (ok bool, err error) {
 
 	e := ctx.E
 
-	if e.R == 5 {
+	if e.R >= 5 {
 		return validateOwnerPasswordAES256(ctx)
 	}
 
@@ -553,6 +675,45 @@ func perms(p int) (list []string) {
 	return list
 }
 
+// The permission names accepted by ParsePermissions.
+var permissionNames = map[string]int16{
+	"print":    PermissionPrint | PermissionPrintHighQuality,
+	"modify":   PermissionModify,
+	"copy":     PermissionExtract | PermissionExtractAccessibility,
+	"annotate": PermissionAnnotate,
+	"forms":    PermissionFillForms,
+	"assemble": PermissionAssemble,
+}
+
+// ParsePermissions returns the user access permissions for s which is either
+// "none", "all", a numeric P value or a comma separated list of permissions
+// granted on top of "none": print, modify, copy, annotate, forms, assemble.
+func ParsePermissions(s string) (int16, error) {
+	s = strings.TrimSpace(s)
+
+	switch s {
+	case "none":
+		return PermissionsNone, nil
+	case "all":
+		return PermissionsAll, nil
+	}
+
+	if i, err := strconv.ParseInt(s, 10, 16); err == nil {
+		return int16(i), nil
+	}
+
+	p := PermissionsNone
+	for _, v := range strings.Split(s, ",") {
+		bits, ok := permissionNames[strings.ToLower(strings.TrimSpace(v))]
+		if !ok {
+			return 0, errors.Errorf("pdfcpu: invalid permission: %q, possible values: none, all, print, modify, copy, annotate, forms, assemble", v)
+		}
+		p |= bits
+	}
+
+	return p, nil
+}
+
 // Permissions returns a list of set permissions.
 func Permissions(ctx *Context) (list []string) {
 
@@ -567,7 +728,7 @@ func validatePermissions(ctx *Context) (bool, error) {
 
 	// Algorithm 3.2a 5.
 
-	if ctx.E.R != 5 {
+	if ctx.E.R < 5 {
 		return true, nil
 	}
 
@@ -590,7 +751,7 @@ func writePermissions(ctx *Context, d Dict) error {
 
 	// Algorithm 3.10
 
-	if ctx.E.R != 5 {
+	if ctx.E.R < 5 {
 		return nil
 	}
 
@@ -683,7 +844,7 @@ func hasNeededPermissions(mode CommandMode, enc *Enc) bool {
 
 	m = maskModify(mode, enc.R)
 	if m > 0 {
-		if enc.P&m == 0 {
+		if enc.P&m == 0 && !fillFormsPermitted(mode, enc) {
 			return false
 		}
 	}
@@ -691,6 +852,45 @@ func hasNeededPermissions(mode CommandMode, enc *Enc) bool {
 	return true
 }
 
+// fillFormsPermitted returns true if mode fills in form fields and bit 9 grants this regardless of bit 4 (R >= 3).
+func fillFormsPermitted(mode CommandMode, enc *Enc) bool {
+	return mode == FILLFORMFIELDS && enc.R >= 3 && enc.P&int(PermissionFillForms) > 0
+}
+
+// ErrPermissionDenied signals a command refused due to the document's user access permissions.
+var ErrPermissionDenied = errors.New("pdfcpu: insufficient access permissions")
+
+// PermissionPolicy is consulted before processing an encrypted document.
+// It decides whether cmd may proceed given the document's encryption dict
+// and whether the owner password has been supplied.
+type PermissionPolicy interface {
+	Permit(cmd CommandMode, enc *Enc, ownerPW bool) error
+}
+
+// DefaultPermissionPolicy refuses commands lacking the extract or modify permission they need,
+// eg. text extraction from a document without the copy permission, unless the owner password has been supplied.
+type DefaultPermissionPolicy struct{}
+
+// Permit implements PermissionPolicy.
+func (DefaultPermissionPolicy) Permit(cmd CommandMode, enc *Enc, ownerPW bool) error {
+	if ownerPW || hasNeededPermissions(cmd, enc) {
+		return nil
+	}
+	return ErrPermissionDenied
+}
+
+// checkPermissions consults the configured permission policy unless permissions are to be ignored.
+func checkPermissions(ctx *Context, ownerPW bool) error {
+	if ctx.IgnorePermissions {
+		return nil
+	}
+	policy := ctx.PermissionPolicy
+	if policy == nil {
+		policy = DefaultPermissionPolicy{}
+	}
+	return policy.Permit(ctx.Cmd, ctx.E, ownerPW)
+}
+
 func getV(d Dict) (*int, error) {
 
 	v := d.IntEntry("V")
@@ -771,6 +971,9 @@ func checkV(ctx *Context, d Dict) (*int, error) {
 			return nil, errors.Wrapf(err, "checkV: unsupported \"%s\" entry in \"CF\"", *eff)
 		}
 		ctx.AES4EmbeddedStreams = aes
+	} else if eff == nil {
+		// Embedded file streams default to the crypt filter of streams.
+		ctx.AES4EmbeddedStreams = ctx.AES4Streams
 	}
 
 	return v, nil
@@ -793,11 +996,8 @@ func length(d Dict) (int, error) {
 func getR(d Dict) (int, error) {
 
 	r := d.IntEntry("R")
-	if r == nil || *r < 2 || *r > 5 {
-		if r != nil && *r > 5 {
-			return 0, errors.New("pdfcpu: PDF 2.0 encryption not supported")
-		}
-		return 0, errors.New("pdfcpu: encryption: \"R\" must be 2,3,4,5")
+	if r == nil || *r < 2 || *r > 6 {
+		return 0, errors.New("pdfcpu: encryption: \"R\" must be 2,3,4,5,6")
 	}
 
 	return *r, nil
@@ -920,7 +1120,7 @@ func supportedEncryption(ctx *Context, d Dict) (*Enc, error) {
 	}
 
 	var oe, ue, perms []byte
-	if r == 5 {
+	if r >= 5 {
 		oe, ue, perms, err = validateAES256Parameters(d)
 		if err != nil {
 			return nil, err
@@ -940,6 +1140,8 @@ func supportedEncryption(ctx *Context, d Dict) (*Enc, error) {
 		encMeta = *emd
 	}
 
+	stmf, strf, eff := cryptFilterNames(d, *v)
+
 	return &Enc{
 			O:     o,
 			OE:    oe,
@@ -950,10 +1152,107 @@ func supportedEncryption(ctx *Context, d Dict) (*Enc, error) {
 			Perms: perms,
 			R:     r,
 			V:     *v,
-			Emd:   encMeta},
+			Emd:   encMeta,
+			StmF:  stmf,
+			StrF:  strf,
+			EFF:   eff},
 		nil
 }
 
+// cryptFilterNames returns the crypt filters used for streams, strings and embedded files, see 7.6.5.
+func cryptFilterNames(d Dict, v int) (stmf, strf, eff string) {
+
+	if v < 4 {
+		return "", "", ""
+	}
+
+	stmf, strf = "Identity", "Identity"
+
+	if n := d.NameEntry("StmF"); n != nil {
+		stmf = *n
+	}
+	if n := d.NameEntry("StrF"); n != nil {
+		strf = *n
+	}
+
+	eff = stmf
+	if n := d.NameEntry("EFF"); n != nil {
+		eff = *n
+	}
+
+	return stmf, strf, eff
+}
+
+// cryptFilterAES returns true if the crypt filter name of the encrypt dict uses AES.
+func cryptFilterAES(ctx *Context, name string) (bool, error) {
+
+	d, err := ctx.DereferenceDict(*ctx.Encrypt)
+	if err != nil {
+		return false, err
+	}
+
+	d1 := d.DictEntry("CF").DictEntry(name)
+	if d1 == nil {
+		return false, errors.Errorf("pdfcpu: crypt filter \"%s\" missing in \"CF\"", name)
+	}
+
+	return supportedCFEntry(d1)
+}
+
+// objectExempt returns true for objects never encrypted like the encrypt dict, see 7.6.2.
+func objectExempt(ctx *Context, objNr int) bool {
+	return ctx.Encrypt != nil && ctx.Encrypt.ObjectNumber.Value() == objNr
+}
+
+// stringsEncrypted returns true if strings of obj#objNr are subject to encryption.
+func stringsEncrypted(ctx *Context, objNr int) bool {
+	return ctx.EncKey != nil && ctx.E.StrF != "Identity" && !objectExempt(ctx, objNr)
+}
+
+// streamCrypt returns whether sd of obj#objNr is subject to encryption and whether AES is used.
+// Cross-reference streams, unencrypted metadata and streams using the "Identity" crypt filter are exempt.
+func streamCrypt(ctx *Context, sd *StreamDict, objNr int) (crypt bool, aes bool, err error) {
+
+	if ctx.EncKey == nil || objectExempt(ctx, objNr) {
+		return false, false, nil
+	}
+
+	if sd.IsXRefStm() {
+		return false, false, nil
+	}
+
+	// A crypt filter has to be the first filter of the pipeline, see 7.4.10.
+	if len(sd.FilterPipeline) > 0 && sd.FilterPipeline[0].Name == "Crypt" {
+		name := "Identity"
+		if d := sd.FilterPipeline[0].DecodeParms; d != nil {
+			if n := d.NameEntry("Name"); n != nil {
+				name = *n
+			}
+		}
+		if name == "Identity" {
+			return false, false, nil
+		}
+		aes, err = cryptFilterAES(ctx, name)
+		return err == nil, aes, err
+	}
+
+	if ctx.E.V < 4 {
+		return true, ctx.AES4Streams, nil
+	}
+
+	t := sd.Type()
+
+	if t != nil && *t == "Metadata" && !ctx.E.Emd {
+		return false, false, nil
+	}
+
+	if t != nil && *t == "EmbeddedFile" {
+		return ctx.E.EFF != "Identity", ctx.AES4EmbeddedStreams, nil
+	}
+
+	return ctx.E.StmF != "Identity", ctx.AES4Streams, nil
+}
+
 func decryptKey(objNumber, generation int, key []byte, aes bool) []byte {
 
 	m := md5.New()
@@ -983,14 +1282,14 @@ func decryptKey(objNumber, generation int, key []byte, aes bool) []byte {
 }
 
 // EncryptBytes encrypts s using RC4 or AES.
-func encryptBytes(b []byte, objNr, genNr int, encKey []byte, needAES bool, r int) ([]byte, error) {
+func encryptBytes(b []byte, objNr, genNr int, encKey []byte, needAES bool, r int, rnd io.Reader) ([]byte, error) {
 
 	if needAES {
 		k := encKey
-		if r != 5 {
+		if r < 5 {
 			k = decryptKey(objNr, genNr, encKey, needAES)
 		}
-		bb, err := encryptAESBytes(b, k)
+		bb, err := encryptAESBytes(b, k, rnd)
 		if err != nil {
 			return nil, err
 		}
@@ -1001,9 +1300,9 @@ func encryptBytes(b []byte, objNr, genNr int, encKey []byte, needAES bool, r int
 }
 
 // EncryptString encrypts s using RC4 or AES.
-func encryptString(s string, objNr, genNr int, key []byte, needAES bool, r int) (*string, error) {
+func encryptString(s string, objNr, genNr int, key []byte, needAES bool, r int, rnd io.Reader) (*string, error) {
 
-	b, err := encryptBytes([]byte(s), objNr, genNr, key, needAES, r)
+	b, err := encryptBytes([]byte(s), objNr, genNr, key, needAES, r, rnd)
 	if err != nil {
 		return nil, err
 	}
@@ -1021,7 +1320,7 @@ func decryptBytes(b []byte, objNr, genNr int, encKey []byte, needAES bool, r int
 
 	if needAES {
 		k := encKey
-		if r != 5 {
+		if r < 5 {
 			k = decryptKey(objNr, genNr, encKey, needAES)
 		}
 		bb, err := decryptAESBytes(b, k)
@@ -1057,9 +1356,9 @@ func applyRC4CipherBytes(b []byte, objNr, genNr int, key []byte, needAES bool) (
 	return b, nil
 }
 
-func encrypt(m map[string]Object, k string, v Object, objNr, genNr int, key []byte, needAES bool, r int) error {
+func encrypt(m map[string]Object, k string, v Object, objNr, genNr int, key []byte, needAES bool, r int, rnd io.Reader) error {
 
-	s, err := encryptDeepObject(v, objNr, genNr, key, needAES, r)
+	s, err := encryptDeepObject(v, objNr, genNr, key, needAES, r, rnd)
 	if err != nil {
 		return err
 	}
@@ -1071,10 +1370,17 @@ func encrypt(m map[string]Object, k string, v Object, objNr, genNr int, key []by
 	return nil
 }
 
-func encryptDict(d Dict, objNr, genNr int, key []byte, needAES bool, r int) error {
+func encryptDict(d Dict, objNr, genNr int, key []byte, needAES bool, r int, rnd io.Reader) error {
 
-	for k, v := range d {
-		err := encrypt(d, k, v, objNr, genNr, key, needAES, r)
+	// Sorted so that reproducible random sources yield reproducible output.
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		err := encrypt(d, k, d[k], objNr, genNr, key, needAES, r, rnd)
 		if err != nil {
 			return err
 		}
@@ -1084,7 +1390,7 @@ func encryptDict(d Dict, objNr, genNr int, key []byte, needAES bool, r int) erro
 }
 
 // EncryptDeepObject recurses over non trivial PDF objects and encrypts all strings encountered.
-func encryptDeepObject(objIn Object, objNr, genNr int, key []byte, needAES bool, r int) (*HexLiteral, error) {
+func encryptDeepObject(objIn Object, objNr, genNr int, key []byte, needAES bool, r int, rnd io.Reader) (*HexLiteral, error) {
 
 	_, ok := objIn.(IndirectRef)
 	if ok {
@@ -1094,20 +1400,20 @@ func encryptDeepObject(objIn Object, objNr, genNr int, key []byte, needAES bool,
 	switch obj := objIn.(type) {
 
 	case StreamDict:
-		err := encryptDict(obj.Dict, objNr, genNr, key, needAES, r)
+		err := encryptDict(obj.Dict, objNr, genNr, key, needAES, r, rnd)
 		if err != nil {
 			return nil, err
 		}
 
 	case Dict:
-		err := encryptDict(obj, objNr, genNr, key, needAES, r)
+		err := encryptDict(obj, objNr, genNr, key, needAES, r, rnd)
 		if err != nil {
 			return nil, err
 		}
 
 	case Array:
 		for i, v := range obj {
-			s, err := encryptDeepObject(v, objNr, genNr, key, needAES, r)
+			s, err := encryptDeepObject(v, objNr, genNr, key, needAES, r, rnd)
 			if err != nil {
 				return nil, err
 			}
@@ -1118,7 +1424,7 @@ func encryptDeepObject(objIn Object, objNr, genNr int, key []byte, needAES bool,
 
 	case StringLiteral:
 		s := obj.Value()
-		b, err := encryptBytes([]byte(s), objNr, genNr, key, needAES, r)
+		b, err := encryptBytes([]byte(s), objNr, genNr, key, needAES, r, rnd)
 		if err != nil {
 			return nil, err
 		}
@@ -1126,7 +1432,7 @@ func encryptDeepObject(objIn Object, objNr, genNr int, key []byte, needAES bool,
 		return &hl, nil
 
 	case HexLiteral:
-		bb, err := encryptHexLiteral(obj, objNr, genNr, key, needAES, r)
+		bb, err := encryptHexLiteral(obj, objNr, genNr, key, needAES, r, rnd)
 		if err != nil {
 			return nil, err
 		}
@@ -1195,15 +1501,15 @@ func decryptDeepObject(objIn Object, objNr, genNr int, key []byte, needAES bool,
 }
 
 // EncryptStream encrypts a stream buffer using RC4 or AES.
-func encryptStream(buf []byte, objNr, genNr int, encKey []byte, needAES bool, r int) ([]byte, error) {
+func encryptStream(buf []byte, objNr, genNr int, encKey []byte, needAES bool, r int, rnd io.Reader) ([]byte, error) {
 
 	k := encKey
-	if r != 5 {
+	if r < 5 {
 		k = decryptKey(objNr, genNr, encKey, needAES)
 	}
 
 	if needAES {
-		return encryptAESBytes(buf, k)
+		return encryptAESBytes(buf, k, rnd)
 	}
 
 	return applyRC4Bytes(buf, k)
@@ -1213,7 +1519,7 @@ func encryptStream(buf []byte, objNr, genNr int, encKey []byte, needAES bool, r
 func decryptStream(buf []byte, objNr, genNr int, encKey []byte, needAES bool, r int) ([]byte, error) {
 
 	k := encKey
-	if r != 5 {
+	if r < 5 {
 		k = decryptKey(objNr, genNr, encKey, needAES)
 	}
 
@@ -1243,7 +1549,7 @@ func applyRC4Bytes(buf, key []byte) ([]byte, error) {
 	return b.Bytes(), nil
 }
 
-func encryptAESBytes(b, key []byte) ([]byte, error) {
+func encryptAESBytes(b, key []byte, rnd io.Reader) ([]byte, error) {
 
 	// pad b to aes.Blocksize
 	l := len(b) % aes.BlockSize
@@ -1264,7 +1570,7 @@ func encryptAESBytes(b, key []byte) ([]byte, error) {
 	data := make([]byte, aes.BlockSize+len(b))
 	iv := data[:aes.BlockSize]
 
-	_, err := io.ReadFull(rand.Reader, iv)
+	_, err := io.ReadFull(rnd, iv)
 	if err != nil {
 		return nil, err
 	}
@@ -1325,7 +1631,7 @@ func fileID(ctx *Context) (HexLiteral, error) {
 	h := md5.New()
 
 	// Current timestamp.
-	h.Write([]byte(time.Now().String()))
+	h.Write([]byte(ctx.Now().String()))
 
 	// File location - ignore, we don't have this.
 
@@ -1338,8 +1644,15 @@ func fileID(ctx *Context) (HexLiteral, error) {
 		return "", err
 	}
 
-	for _, v := range d {
-		o, err := ctx.Dereference(v)
+	// Sorted for reproducible identifiers.
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		o, err := ctx.Dereference(d[k])
 		if err != nil {
 			return "", err
 		}
@@ -1351,14 +1664,14 @@ func fileID(ctx *Context) (HexLiteral, error) {
 	return HexLiteral(hex.EncodeToString(m)), nil
 }
 
-func encryptHexLiteral(hl HexLiteral, objNr, genNr int, key []byte, needAES bool, r int) ([]byte, error) {
+func encryptHexLiteral(hl HexLiteral, objNr, genNr int, key []byte, needAES bool, r int, rnd io.Reader) ([]byte, error) {
 
 	bb, err := hl.Bytes()
 	if err != nil {
 		return nil, err
 	}
 
-	return encryptBytes(bb, objNr, genNr, key, needAES, r)
+	return encryptBytes(bb, objNr, genNr, key, needAES, r, rnd)
 }
 
 func decryptHexLiteral(hl HexLiteral, objNr, genNr int, key []byte, needAES bool, r int) ([]byte, error) {
@@ -1415,7 +1728,7 @@ func calcFileEncKey(ctx *Context, d Dict) (err error) {
 
 	// Calc Random UE (32 bytes)
 	ue := make([]byte, 32)
-	_, err = io.ReadFull(rand.Reader, ue)
+	_, err = io.ReadFull(ctx.Rand(), ue)
 	if err != nil {
 		return err
 	}
@@ -1433,29 +1746,34 @@ func calcOAndUAES256(ctx *Context, d Dict) (err error) {
 
 	// 1) Calc U.
 	b := make([]byte, 16)
-	_, err = io.ReadFull(rand.Reader, b)
+	_, err = io.ReadFull(ctx.Rand(), b)
 	if err != nil {
 		return err
 	}
 
 	u := append(make([]byte, 32), b...)
-	upw := []byte(ctx.UserPW)
-	h := sha256.Sum256(append(upw, validationSalt(u)...))
-	ctx.E.U = append(h[:], b...)
+	upw := aes256Password(ctx.UserPW)
+	h, err := hashAES256(upw, validationSalt(u), nil, ctx.E.R)
+	if err != nil {
+		return err
+	}
+	ctx.E.U = append(h, b...)
 	d.Update("U", HexLiteral(hex.EncodeToString(ctx.E.U)))
 
 	// 2) Calc O (depends on U).
 	b = make([]byte, 16)
-	_, err = io.ReadFull(rand.Reader, b)
+	_, err = io.ReadFull(ctx.Rand(), b)
 	if err != nil {
 		return err
 	}
 
 	o := append(make([]byte, 32), b...)
-	opw := []byte(ctx.OwnerPW)
-	c := append(opw, validationSalt(o)...)
-	h = sha256.Sum256(append(c, ctx.E.U...))
-	ctx.E.O = append(h[:], b...)
+	opw := aes256Password(ctx.OwnerPW)
+	h, err = hashAES256(opw, validationSalt(o), ctx.E.U, ctx.E.R)
+	if err != nil {
+		return err
+	}
+	ctx.E.O = append(h, b...)
 	d.Update("O", HexLiteral(hex.EncodeToString(ctx.E.O)))
 
 	err = calcFileEncKey(ctx, d)
@@ -1464,8 +1782,10 @@ func calcOAndUAES256(ctx *Context, d Dict) (err error) {
 	}
 
 	// Encrypt file encryption key into UE.
-	h = sha256.Sum256(append(upw, keySalt(u)...))
-	cb, err := aes.NewCipher(h[:])
+	if h, err = hashAES256(upw, keySalt(u), nil, ctx.E.R); err != nil {
+		return err
+	}
+	cb, err := aes.NewCipher(h)
 	if err != nil {
 		return err
 	}
@@ -1476,9 +1796,10 @@ func calcOAndUAES256(ctx *Context, d Dict) (err error) {
 	d.Update("UE", HexLiteral(hex.EncodeToString(ctx.E.UE)))
 
 	// Encrypt file encryption key into OE.
-	c = append(opw, keySalt(o)...)
-	h = sha256.Sum256(append(c, ctx.E.U...))
-	cb, err = aes.NewCipher(h[:])
+	if h, err = hashAES256(opw, keySalt(o), ctx.E.U, ctx.E.R); err != nil {
+		return err
+	}
+	cb, err = aes.NewCipher(h)
 	if err != nil {
 		return err
 	}
@@ -1492,7 +1813,7 @@ func calcOAndUAES256(ctx *Context, d Dict) (err error) {
 
 func calcOAndU(ctx *Context, d Dict) (err error) {
 
-	if ctx.E.R == 5 {
+	if ctx.E.R >= 5 {
 		return calcOAndUAES256(ctx, d)
 	}
 
@@ -23,6 +23,19 @@ import (
 	"time"
 )
 
+// Clock is the source of the current time for dates written into documents.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock reading the system time.
+type SystemClock struct{}
+
+// Now implements Clock.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
 // DateString returns a string representation of t.
 func DateString(t time.Time) string {
 	_, tz := t.Zone()
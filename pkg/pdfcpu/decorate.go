@@ -0,0 +1,389 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var errInvalidDecorationConfig = errors.New("pdfcpu: invalid configuration string")
+
+type decorationParamMap map[string]func(string, *Decoration) error
+
+var decParamMap = decorationParamMap{
+	"backgroundcolor": parseBackgroundColorDec,
+	"bgcolor":         parseBackgroundColorDec,
+	"gradient":        parseGradientColorDec,
+	"axis":            parseGradientAxisDec,
+	"border":          parseBorderDec,
+	"radius":          parseBorderRadiusDec,
+	"margins":         parseMarginsDec,
+}
+
+// Handle applies parameter completion and if successful
+// parses the parameter values into dec.
+func (m decorationParamMap) Handle(paramPrefix, paramValueStr string, dec *Decoration) error {
+	var param string
+
+	// Completion support
+	for k := range m {
+		if !strings.HasPrefix(k, paramPrefix) {
+			continue
+		}
+		if len(param) > 0 {
+			return errors.Errorf("pdfcpu: ambiguous parameter prefix \"%s\"", paramPrefix)
+		}
+		param = k
+	}
+
+	if param == "" {
+		return errors.Errorf("pdfcpu: ambiguous parameter prefix \"%s\"", paramPrefix)
+	}
+
+	return m[param](paramValueStr, dec)
+}
+
+// Decoration represents the command details for the command "Decorate".
+// All geometry is relative to the visible page area taking any page rotation into account.
+type Decoration struct {
+	BgColor       *SimpleColor // Page background color.
+	GradientColor *SimpleColor // Optional end color turning the background into a linear gradient starting with BgColor.
+	Horizontal    bool         // true for a left to right gradient, false for a top to bottom gradient.
+	BorderWidth   float64      // Border rule width, 0 = no border.
+	BorderColor   SimpleColor  // Border rule color.
+	BorderRadius  float64      // Border corner radius.
+	MTop, MRight  float64      // Top and right distance of the border rule to the page edges.
+	MBot, MLeft   float64      // Bottom and left distance of the border rule to the page edges.
+	InpUnit       DisplayUnit  // input display unit.
+}
+
+// DefaultDecorationConfig returns the default Decoration configuration.
+func DefaultDecorationConfig() *Decoration {
+	return &Decoration{}
+}
+
+func (dec Decoration) String() string {
+	return fmt.Sprintf("Decoration conf: bgcolor=%v, gradient=%v, horizontal=%t, border=%.2f %s, radius=%.2f, margins=%.2f %.2f %.2f %.2f\n",
+		dec.BgColor, dec.GradientColor, dec.Horizontal, dec.BorderWidth, dec.BorderColor, dec.BorderRadius,
+		dec.MTop, dec.MRight, dec.MBot, dec.MLeft)
+}
+
+func parseBackgroundColorDec(s string, dec *Decoration) error {
+	c, err := parseColor(s)
+	if err != nil {
+		return err
+	}
+	dec.BgColor = &c
+	return nil
+}
+
+func parseGradientColorDec(s string, dec *Decoration) error {
+	c, err := parseColor(s)
+	if err != nil {
+		return err
+	}
+	dec.GradientColor = &c
+	return nil
+}
+
+func parseGradientAxisDec(s string, dec *Decoration) error {
+	switch strings.ToLower(s) {
+	case "h", "horizontal":
+		dec.Horizontal = true
+	case "v", "vertical":
+		dec.Horizontal = false
+	default:
+		return errors.New("pdfcpu: gradient axis, please provide one of: h(orizontal) v(ertical)")
+	}
+
+	return nil
+}
+
+func parseBorderDec(s string, dec *Decoration) error {
+	// w
+	// w r g b
+	// w #c
+
+	b := strings.Split(s, " ")
+	if len(b) == 0 || len(b) > 4 {
+		return errors.Errorf("pdfcpu: border: need 1,2 or 4 values, %s\n", s)
+	}
+
+	f, err := strconv.ParseFloat(b[0], 64)
+	if err != nil {
+		return err
+	}
+	if f <= 0 {
+		return errors.New("pdfcpu: border: need width > 0")
+	}
+	dec.BorderWidth = toUserSpace(f, dec.InpUnit)
+
+	if len(b) == 1 {
+		return nil
+	}
+
+	dec.BorderColor, err = parseColor(strings.Join(b[1:], " "))
+	return err
+}
+
+func parseBorderRadiusDec(s string, dec *Decoration) error {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	if f < 0 {
+		return errors.New("pdfcpu: border radius, please provide a positive value")
+	}
+	dec.BorderRadius = toUserSpace(f, dec.InpUnit)
+	return nil
+}
+
+// parseMarginValues parses 1,2,3 or 4 non negative lengths into top, right, bottom and left margins.
+func parseMarginValues(s string, u DisplayUnit) (float64, float64, float64, float64, error) {
+	// m
+	// v h
+	// t h b
+	// t r b l
+
+	m := strings.Fields(s)
+	if len(m) == 0 || len(m) > 4 {
+		return 0, 0, 0, 0, errors.Errorf("need 1,2,3 or 4 values, %s", s)
+	}
+
+	ff := make([]float64, len(m))
+	for i, v := range m {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, 0, 0, 0, err
+		}
+		if f < 0 {
+			return 0, 0, 0, 0, errors.New("please provide positive values")
+		}
+		ff[i] = toUserSpace(f, u)
+	}
+
+	switch len(ff) {
+	case 1:
+		return ff[0], ff[0], ff[0], ff[0], nil
+	case 2:
+		return ff[0], ff[1], ff[0], ff[1], nil
+	case 3:
+		return ff[0], ff[1], ff[2], ff[1], nil
+	}
+
+	return ff[0], ff[1], ff[2], ff[3], nil
+}
+
+func parseMarginsDec(s string, dec *Decoration) (err error) {
+	dec.MTop, dec.MRight, dec.MBot, dec.MLeft, err = parseMarginValues(s, dec.InpUnit)
+	if err != nil {
+		return errors.Errorf("pdfcpu: margins: %v", err)
+	}
+	return nil
+}
+
+// ParseDecorationDetails parses a Decoration command string into an internal structure.
+func ParseDecorationDetails(s string, u DisplayUnit) (*Decoration, error) {
+	if s == "" {
+		return nil, errInvalidDecorationConfig
+	}
+
+	dec := DefaultDecorationConfig()
+	dec.InpUnit = u
+
+	ss := strings.Split(s, ",")
+
+	for _, s := range ss {
+
+		ss1 := strings.Split(s, ":")
+		if len(ss1) != 2 {
+			return nil, errInvalidDecorationConfig
+		}
+
+		paramPrefix := strings.TrimSpace(ss1[0])
+		paramValueStr := strings.TrimSpace(ss1[1])
+
+		if err := decParamMap.Handle(paramPrefix, paramValueStr, dec); err != nil {
+			return nil, err
+		}
+	}
+
+	if dec.BgColor == nil && dec.BorderWidth == 0 {
+		return nil, errors.New("pdfcpu: decoration: need background color or border")
+	}
+
+	if dec.GradientColor != nil && dec.BgColor == nil {
+		return nil, errors.New("pdfcpu: decoration: gradient needs background color")
+	}
+
+	return dec, nil
+}
+
+func gradientShading(c0, c1 SimpleColor, coords Array) Dict {
+	return Dict(
+		map[string]Object{
+			"ShadingType": Integer(2),
+			"ColorSpace":  Name("DeviceRGB"),
+			"Coords":      coords,
+			"Extend":      Array{Boolean(true), Boolean(true)},
+			"Function": Dict(
+				map[string]Object{
+					"FunctionType": Integer(2),
+					"Domain":       NewNumberArray(0, 1),
+					"C0":           NewNumberArray(float64(c0.R), float64(c0.G), float64(c0.B)),
+					"C1":           NewNumberArray(float64(c1.R), float64(c1.G), float64(c1.B)),
+					"N":            Float(1),
+				},
+			),
+		},
+	)
+}
+
+// displaySpace returns the visible page area in display orientation
+// and the content bytes mapping display space into user space.
+func displaySpace(inhPAttrs *InheritedPageAttrs) (*Rectangle, []byte) {
+	vp := viewPort(inhPAttrs)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "1 0 0 1 %.2f %.2f cm ", vp.LL.X, vp.LL.Y)
+
+	w, h := vp.Width(), vp.Height()
+	rot := inhPAttrs.rotate
+
+	if rot != 0 {
+		b.Write(contentBytesForPageRotation(-rot, w, h))
+		if IntMemberOf(rot, []int{+90, -90, +270, -270}) {
+			w, h = h, w
+		}
+	}
+
+	return RectForDim(w, h), b.Bytes()
+}
+
+func (ctx *Context) backgroundContent(d Dict, inhPAttrs *InheritedPageAttrs, dec *Decoration) ([]byte, error) {
+	r, cm := displaySpace(inhPAttrs)
+
+	var b bytes.Buffer
+	fmt.Fprint(&b, "q ")
+	b.Write(cm)
+
+	if dec.GradientColor == nil {
+		SetFillColor(&b, *dec.BgColor)
+		fmt.Fprintf(&b, "%.2f %.2f %.2f %.2f re f Q ", r.LL.X, r.LL.Y, r.Width(), r.Height())
+		return b.Bytes(), nil
+	}
+
+	// Top to bottom or left to right.
+	coords := NewNumberArray(0, r.UR.Y, 0, 0)
+	if dec.Horizontal {
+		coords = NewNumberArray(0, 0, r.UR.X, 0)
+	}
+
+	ir, err := ctx.IndRefForNewObject(gradientShading(*dec.BgColor, *dec.GradientColor, coords))
+	if err != nil {
+		return nil, err
+	}
+
+	shDict, err := ctx.pageResourceDict(d, inhPAttrs, "Shading")
+	if err != nil {
+		return nil, err
+	}
+
+	id := uniqueResourceID(shDict, "Sh")
+	shDict.Insert(id, *ir)
+
+	fmt.Fprintf(&b, "%.2f %.2f %.2f %.2f re W n /%s sh Q ", r.LL.X, r.LL.Y, r.Width(), r.Height(), id)
+
+	return b.Bytes(), nil
+}
+
+func borderContent(pageNr int, inhPAttrs *InheritedPageAttrs, dec *Decoration) ([]byte, error) {
+	r, cm := displaySpace(inhPAttrs)
+
+	// Keep the border rule within the margins.
+	bw := dec.BorderWidth
+	r = Rect(r.LL.X+dec.MLeft+bw/2, r.LL.Y+dec.MBot+bw/2, r.UR.X-dec.MRight-bw/2, r.UR.Y-dec.MTop-bw/2)
+	if r.Width() <= 0 || r.Height() <= 0 {
+		return nil, errors.Errorf("pdfcpu: border and margins exceed page %d", pageNr)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprint(&b, "q ")
+	b.Write(cm)
+	fmt.Fprint(&b, "[]0 d ")
+	SetLineWidth(&b, bw)
+	SetStrokeColor(&b, dec.BorderColor)
+	DrawRoundedRect(&b, r, dec.BorderRadius)
+	fmt.Fprint(&b, "Q ")
+
+	return b.Bytes(), nil
+}
+
+func (ctx *Context) decoratePage(pageNr int, dec *Decoration) error {
+
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, true)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.Errorf("pdfcpu: unknown page number: %d\n", pageNr)
+	}
+
+	if dec.BorderWidth > 0 {
+		// The border is rendered on top of the page content.
+		bb, err := borderContent(pageNr, inhPAttrs, dec)
+		if err != nil {
+			return err
+		}
+		if err := ctx.appendPageContent(d, bb); err != nil {
+			return err
+		}
+	}
+
+	if dec.BgColor == nil {
+		return nil
+	}
+
+	// The background is rendered underneath the page content.
+	bb, err := ctx.backgroundContent(d, inhPAttrs, dec)
+	if err != nil {
+		return err
+	}
+
+	return ctx.prependPageContent(d, bb)
+}
+
+// Decorate applies a page background and/or a page border to selected pages.
+func (ctx *Context) Decorate(selectedPages IntSet, dec *Decoration) error {
+
+	if dec == nil {
+		return errors.New("pdfcpu: missing decoration")
+	}
+
+	for _, pageNr := range sortSelectedPages(selectedPages) {
+		if err := ctx.decoratePage(pageNr, dec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
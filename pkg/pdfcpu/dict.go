@@ -339,6 +339,11 @@ func (d Dict) IsObjStm() bool {
 	return d.Type() != nil && *d.Type() == "ObjStm"
 }
 
+// IsXRefStm returns true if given PDFDict is a cross-reference stream.
+func (d Dict) IsXRefStm() bool {
+	return d.Type() != nil && *d.Type() == "XRef"
+}
+
 // W returns a *Array for key "W".
 func (d Dict) W() Array {
 	return d.ArrayEntry("W")
@@ -478,6 +483,12 @@ func (d Dict) PDFString() string {
 			continue
 		}
 
+		ff, ok := v.(formattedFloat)
+		if ok {
+			logstr = append(logstr, fmt.Sprintf("/%s %s", k, ff.PDFString()))
+			continue
+		}
+
 		b, ok := v.(Boolean)
 		if ok {
 			logstr = append(logstr, fmt.Sprintf("/%s %s", k, b.PDFString()))
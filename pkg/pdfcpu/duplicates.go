@@ -0,0 +1,334 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math/bits"
+	"strings"
+	"unicode"
+
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pkg/errors"
+)
+
+// DefaultDuplicateThreshold is the minimum similarity of two pages considered near-duplicates.
+const DefaultDuplicateThreshold = 0.9
+
+// The number of consecutive words making up a shingle of page text.
+const shingleSize = 3
+
+// PageRef identifies a page of one of the documents analyzed.
+type PageRef struct {
+	File string `json:"file,omitempty"`
+	Page int    `json:"page"`
+}
+
+func (pr PageRef) String() string {
+	if pr.File == "" {
+		return fmt.Sprintf("page %d", pr.Page)
+	}
+	return fmt.Sprintf("%s page %d", pr.File, pr.Page)
+}
+
+// PageDuplicate represents a page (Page2) duplicating an earlier page (Page1).
+// Similarity is 1 for identical normalized text or image data.
+// Raster is true for pages without text compared by their largest image.
+type PageDuplicate struct {
+	Page1      PageRef `json:"page1"`
+	Page2      PageRef `json:"page2"`
+	Similarity float64 `json:"similarity"`
+	Raster     bool    `json:"raster,omitempty"`
+}
+
+func (pd PageDuplicate) String() string {
+	return fmt.Sprintf("%s duplicates %s (similarity %.2f)", pd.Page2, pd.Page1, pd.Similarity)
+}
+
+// pageFingerprint holds everything needed to compare a page with other pages.
+type pageFingerprint struct {
+	ref      PageRef
+	raster   bool
+	hash     [32]byte        // normalized text or raw image data.
+	shingles map[uint64]bool // text pages only.
+	aHash    uint64          // raster pages only.
+	hasAHash bool
+}
+
+func isWordSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// normalizedWords returns the lower case words of pt ignoring punctuation and whitespace.
+func normalizedWords(pt *PageText) []string {
+	var ww []string
+	for _, b := range pt.Blocks {
+		for _, l := range b.Lines {
+			ww = append(ww, strings.FieldsFunc(strings.ToLower(l.Text), isWordSeparator)...)
+		}
+	}
+	return ww
+}
+
+func shingles(ww []string) map[uint64]bool {
+	k := shingleSize
+	if len(ww) < k {
+		k = len(ww)
+	}
+	m := map[uint64]bool{}
+	for i := 0; i+k <= len(ww); i++ {
+		h := fnv.New64a()
+		for _, w := range ww[i : i+k] {
+			h.Write([]byte(w))
+			h.Write([]byte{0})
+		}
+		m[h.Sum64()] = true
+	}
+	return m
+}
+
+func jaccard(m1, m2 map[uint64]bool) float64 {
+	if len(m1) > len(m2) {
+		m1, m2 = m2, m1
+	}
+	n := 0
+	for k := range m1 {
+		if m2[k] {
+			n++
+		}
+	}
+	return float64(n) / float64(len(m1)+len(m2)-n)
+}
+
+// averageHash returns a 64 bit perceptual hash of img:
+// Each bit tells if one cell of an 8x8 grid is brighter than the whole image.
+func averageHash(img image.Image) uint64 {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return 0
+	}
+
+	// Sample large images sparsely.
+	step := w
+	if h < step {
+		step = h
+	}
+	step /= 64
+	if step < 1 {
+		step = 1
+	}
+
+	var sum [64]float64
+	var cnt [64]int
+	for y := b.Min.Y; y < b.Max.Y; y += step {
+		cy := (y - b.Min.Y) * 8 / h
+		for x := b.Min.X; x < b.Max.X; x += step {
+			i := cy*8 + (x-b.Min.X)*8/w
+			sum[i] += float64(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+			cnt[i]++
+		}
+	}
+
+	var total float64
+	for i := range sum {
+		if cnt[i] > 0 {
+			sum[i] /= float64(cnt[i])
+		}
+		total += sum[i]
+	}
+	mean := total / 64
+
+	var hash uint64
+	for i := range sum {
+		if sum[i] > mean {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash
+}
+
+// decodeImage returns the decoded image of sd.
+func (ctx *Context) decodeImage(sd *StreamDict, objNr int) (image.Image, error) {
+	if len(sd.FilterPipeline) == 0 {
+		return nil, errors.New("pdfcpu: decodeImage: missing filter")
+	}
+
+	if len(sd.FilterPipeline) == 1 && sd.FilterPipeline[0].Name == filter.DCT {
+		return jpeg.Decode(bytes.NewReader(sd.Raw))
+	}
+
+	r, ext, err := RenderImage(ctx.XRefTable, sd, false, "", objNr)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil || ext != "png" {
+		return nil, errors.Errorf("pdfcpu: decodeImage: unsupported image format: %s", ext)
+	}
+
+	return png.Decode(r)
+}
+
+// pageImage returns the image covering the largest area of page pageNr.
+func (ctx *Context) pageImage(pageNr int) (*StreamDict, int, error) {
+	var (
+		sd    *StreamDict
+		objNr int
+		area  float64
+	)
+
+	cb := &contentBoxer{ctx: ctx, fonts: map[int]*cbFont{}, forms: map[int]bool{}}
+	cb.image = func(gs *cbGState, isd *StreamDict, iObjNr int) {
+		r := intersectRect(gs.clip, transformRect(gs.ctm, Rect(0, 0, 1, 1)))
+		if r == nil {
+			return
+		}
+		if a := r.Width() * r.Height(); a > area {
+			sd, objNr, area = isd, iObjNr, a
+		}
+	}
+
+	if err := ctx.processPage(pageNr, cb); err != nil {
+		return nil, 0, err
+	}
+
+	return sd, objNr, nil
+}
+
+// pageFingerprint returns the fingerprint of page pageNr or nil for blank pages.
+func (ctx *Context) pageFingerprint(pageNr int) (*pageFingerprint, error) {
+	pt, err := ctx.ExtractPageText(pageNr)
+	if err != nil {
+		return nil, err
+	}
+
+	if ww := normalizedWords(pt); len(ww) > 0 {
+		return &pageFingerprint{
+			hash:     sha256.Sum256([]byte(strings.Join(ww, " "))),
+			shingles: shingles(ww),
+		}, nil
+	}
+
+	// Scanned pages are compared by their raster image.
+	sd, objNr, err := ctx.pageImage(pageNr)
+	if err != nil || sd == nil {
+		return nil, err
+	}
+
+	fp := &pageFingerprint{raster: true, hash: sha256.Sum256(sd.Raw)}
+
+	img, err := ctx.decodeImage(sd, objNr)
+	if err != nil {
+		// Fall back to comparing the raw image data.
+		ctx.Warn("page %d: image obj#%d: %v", pageNr, objNr, err)
+		return fp, nil
+	}
+
+	fp.aHash, fp.hasAHash = averageHash(img), true
+
+	return fp, nil
+}
+
+// similarity returns the similarity of fp1 and fp2 if it reaches threshold.
+func (fp1 *pageFingerprint) similarity(fp2 *pageFingerprint, threshold float64) (float64, bool) {
+	if fp1.raster != fp2.raster {
+		return 0, false
+	}
+
+	if fp1.hash == fp2.hash {
+		return 1, true
+	}
+
+	var s float64
+
+	if fp1.raster {
+		if !fp1.hasAHash || !fp2.hasAHash {
+			return 0, false
+		}
+		s = 1 - float64(bits.OnesCount64(fp1.aHash^fp2.aHash))/64
+	} else {
+		// The similarity can't exceed the ratio of the shingle set sizes.
+		n1, n2 := len(fp1.shingles), len(fp2.shingles)
+		if n1 > n2 {
+			n1, n2 = n2, n1
+		}
+		if float64(n1)/float64(n2) < threshold {
+			return 0, false
+		}
+		s = jaccard(fp1.shingles, fp2.shingles)
+	}
+
+	return s, s >= threshold
+}
+
+// DuplicatePages returns the pages of ctxs duplicating or nearly duplicating an earlier page.
+// Pages carrying text are compared by the similarity of their normalized text,
+// pages without text (eg. scans) by the perceptual hash of their largest image.
+// Blank pages are ignored. Each page is reported at most once along with its most similar predecessor.
+// names optionally identifies the documents in the result.
+func DuplicatePages(ctxs []*Context, names []string, threshold float64) ([]PageDuplicate, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, errors.Errorf("pdfcpu: invalid similarity threshold: %.2f", threshold)
+	}
+
+	var fps []*pageFingerprint
+
+	for i, ctx := range ctxs {
+		if err := ctx.EnsurePageCount(); err != nil {
+			return nil, err
+		}
+		var name string
+		if i < len(names) {
+			name = names[i]
+		}
+		for p := 1; p <= ctx.PageCount; p++ {
+			fp, err := ctx.pageFingerprint(p)
+			if err != nil {
+				return nil, err
+			}
+			if fp == nil {
+				continue
+			}
+			fp.ref = PageRef{File: name, Page: p}
+			fps = append(fps, fp)
+		}
+	}
+
+	var dups []PageDuplicate
+
+	for j, fp2 := range fps {
+		best := -1
+		var bestSim float64
+		for i, fp1 := range fps[:j] {
+			if s, ok := fp1.similarity(fp2, threshold); ok && s > bestSim {
+				best, bestSim = i, s
+			}
+		}
+		if best >= 0 {
+			dups = append(dups, PageDuplicate{Page1: fps[best].ref, Page2: fp2.ref, Similarity: bestSim, Raster: fp2.raster})
+		}
+	}
+
+	return dups, nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// scan returns a gray image with a dark left half, slightly brightened by noise.
+func scan(w, h int, noise uint8) image.Image {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			g := uint8(200)
+			if x < w/2 {
+				g = 40
+			}
+			if (x*7+y*13)%5 == 0 {
+				g += noise
+			}
+			img.SetGray(x, y, color.Gray{Y: g})
+		}
+	}
+	return img
+}
+
+func TestRasterSimilarity(t *testing.T) {
+	fp := func(img image.Image, raw string) *pageFingerprint {
+		return &pageFingerprint{raster: true, hash: [32]byte{raw[0]}, aHash: averageHash(img), hasAHash: true}
+	}
+
+	fp1 := fp(scan(300, 400, 0), "a")
+	fp2 := fp(scan(600, 800, 30), "b") // Rescanned at a higher resolution.
+
+	if s, ok := fp1.similarity(fp2, DefaultDuplicateThreshold); !ok || s != 1 {
+		t.Errorf("want rescan to match, got similarity %.2f\n", s)
+	}
+
+	// A page darkening from top to bottom.
+	other := image.NewGray(image.Rect(0, 0, 300, 400))
+	for y := 0; y < 400; y++ {
+		for x := 0; x < 300; x++ {
+			other.SetGray(x, y, color.Gray{Y: uint8(240 - y/2)})
+		}
+	}
+	if s, ok := fp1.similarity(fp(other, "c"), DefaultDuplicateThreshold); ok {
+		t.Errorf("want different scan not to match, got similarity %.2f\n", s)
+	}
+}
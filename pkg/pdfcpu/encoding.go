@@ -0,0 +1,187 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/internal/corefont/metrics"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// symbolEncoding maps the character codes of the built-in encoding of the Symbol font to Unicode.
+// Glyphs without Unicode counterpart are mapped into the Private Use Area as done by Adobe's symbol.txt.
+var symbolEncoding = [256]rune{
+	32: 0x0020, 33: 0x0021, 34: 0x2200, 35: 0x0023, 36: 0x2203, 37: 0x0025, 38: 0x0026, 39: 0x220B,
+	40: 0x0028, 41: 0x0029, 42: 0x2217, 43: 0x002B, 44: 0x002C, 45: 0x2212, 46: 0x002E, 47: 0x002F,
+	48: 0x0030, 49: 0x0031, 50: 0x0032, 51: 0x0033, 52: 0x0034, 53: 0x0035, 54: 0x0036, 55: 0x0037,
+	56: 0x0038, 57: 0x0039, 58: 0x003A, 59: 0x003B, 60: 0x003C, 61: 0x003D, 62: 0x003E, 63: 0x003F,
+	64: 0x2245, 65: 0x0391, 66: 0x0392, 67: 0x03A7, 68: 0x0394, 69: 0x0395, 70: 0x03A6, 71: 0x0393,
+	72: 0x0397, 73: 0x0399, 74: 0x03D1, 75: 0x039A, 76: 0x039B, 77: 0x039C, 78: 0x039D, 79: 0x039F,
+	80: 0x03A0, 81: 0x0398, 82: 0x03A1, 83: 0x03A3, 84: 0x03A4, 85: 0x03A5, 86: 0x03C2, 87: 0x03A9,
+	88: 0x039E, 89: 0x03A8, 90: 0x0396, 91: 0x005B, 92: 0x2234, 93: 0x005D, 94: 0x22A5, 95: 0x005F,
+	96: 0xF8E5, 97: 0x03B1, 98: 0x03B2, 99: 0x03C7, 100: 0x03B4, 101: 0x03B5, 102: 0x03C6, 103: 0x03B3,
+	104: 0x03B7, 105: 0x03B9, 106: 0x03D5, 107: 0x03BA, 108: 0x03BB, 109: 0x03BC, 110: 0x03BD, 111: 0x03BF,
+	112: 0x03C0, 113: 0x03B8, 114: 0x03C1, 115: 0x03C3, 116: 0x03C4, 117: 0x03C5, 118: 0x03D6, 119: 0x03C9,
+	120: 0x03BE, 121: 0x03C8, 122: 0x03B6, 123: 0x007B, 124: 0x007C, 125: 0x007D, 126: 0x223C, 160: 0x20AC,
+	161: 0x03D2, 162: 0x2032, 163: 0x2264, 164: 0x2044, 165: 0x221E, 166: 0x0192, 167: 0x2663, 168: 0x2666,
+	169: 0x2665, 170: 0x2660, 171: 0x2194, 172: 0x2190, 173: 0x2191, 174: 0x2192, 175: 0x2193, 176: 0x00B0,
+	177: 0x00B1, 178: 0x2033, 179: 0x2265, 180: 0x00D7, 181: 0x221D, 182: 0x2202, 183: 0x2022, 184: 0x00F7,
+	185: 0x2260, 186: 0x2261, 187: 0x2248, 188: 0x2026, 189: 0xF8E6, 190: 0xF8E7, 191: 0x21B5, 192: 0x2135,
+	193: 0x2111, 194: 0x211C, 195: 0x2118, 196: 0x2297, 197: 0x2295, 198: 0x2205, 199: 0x2229, 200: 0x222A,
+	201: 0x2283, 202: 0x2287, 203: 0x2284, 204: 0x2282, 205: 0x2286, 206: 0x2208, 207: 0x2209, 208: 0x2220,
+	209: 0x2207, 210: 0xF6DA, 211: 0xF6D9, 212: 0xF6DB, 213: 0x220F, 214: 0x221A, 215: 0x22C5, 216: 0x00AC,
+	217: 0x2227, 218: 0x2228, 219: 0x21D4, 220: 0x21D0, 221: 0x21D1, 222: 0x21D2, 223: 0x21D3, 224: 0x25CA,
+	225: 0x2329, 226: 0xF8E8, 227: 0xF8E9, 228: 0xF8EA, 229: 0x2211, 230: 0xF8EB, 231: 0xF8EC, 232: 0xF8ED,
+	233: 0xF8EE, 234: 0xF8EF, 235: 0xF8F0, 236: 0xF8F1, 237: 0xF8F2, 238: 0xF8F3, 239: 0xF8F4, 241: 0x232A,
+	242: 0x222B, 243: 0x2320, 244: 0xF8F5, 245: 0x2321, 246: 0xF8F6, 247: 0xF8F7, 248: 0xF8F8, 249: 0xF8F9,
+	250: 0xF8FA, 251: 0xF8FB, 252: 0xF8FC, 253: 0xF8FD, 254: 0xF8FE,
+}
+
+// zapfDingbatsEncoding maps the character codes of the built-in encoding of the ZapfDingbats font to Unicode.
+var zapfDingbatsEncoding = [256]rune{
+	32: 0x0020, 33: 0x2701, 34: 0x2702, 35: 0x2703, 36: 0x2704, 37: 0x260E, 38: 0x2706, 39: 0x2707,
+	40: 0x2708, 41: 0x2709, 42: 0x261B, 43: 0x261E, 44: 0x270C, 45: 0x270D, 46: 0x270E, 47: 0x270F,
+	48: 0x2710, 49: 0x2711, 50: 0x2712, 51: 0x2713, 52: 0x2714, 53: 0x2715, 54: 0x2716, 55: 0x2717,
+	56: 0x2718, 57: 0x2719, 58: 0x271A, 59: 0x271B, 60: 0x271C, 61: 0x271D, 62: 0x271E, 63: 0x271F,
+	64: 0x2720, 65: 0x2721, 66: 0x2722, 67: 0x2723, 68: 0x2724, 69: 0x2725, 70: 0x2726, 71: 0x2727,
+	72: 0x2605, 73: 0x2729, 74: 0x272A, 75: 0x272B, 76: 0x272C, 77: 0x272D, 78: 0x272E, 79: 0x272F,
+	80: 0x2730, 81: 0x2731, 82: 0x2732, 83: 0x2733, 84: 0x2734, 85: 0x2735, 86: 0x2736, 87: 0x2737,
+	88: 0x2738, 89: 0x2739, 90: 0x273A, 91: 0x273B, 92: 0x273C, 93: 0x273D, 94: 0x273E, 95: 0x273F,
+	96: 0x2740, 97: 0x2741, 98: 0x2742, 99: 0x2743, 100: 0x2744, 101: 0x2745, 102: 0x2746, 103: 0x2747,
+	104: 0x2748, 105: 0x2749, 106: 0x274A, 107: 0x274B, 108: 0x25CF, 109: 0x274D, 110: 0x25A0, 111: 0x274F,
+	112: 0x2750, 113: 0x2751, 114: 0x2752, 115: 0x25B2, 116: 0x25BC, 117: 0x25C6, 118: 0x2756, 119: 0x25D7,
+	120: 0x2758, 121: 0x2759, 122: 0x275A, 123: 0x275B, 124: 0x275C, 125: 0x275D, 126: 0x275E, 128: 0x2768,
+	129: 0x2769, 130: 0x276A, 131: 0x276B, 132: 0x276C, 133: 0x276D, 134: 0x276E, 135: 0x276F, 136: 0x2770,
+	137: 0x2771, 138: 0x2772, 139: 0x2773, 140: 0x2774, 141: 0x2775, 161: 0x2761, 162: 0x2762, 163: 0x2763,
+	164: 0x2764, 165: 0x2765, 166: 0x2766, 167: 0x2767, 168: 0x2663, 169: 0x2666, 170: 0x2665, 171: 0x2660,
+	172: 0x2460, 173: 0x2461, 174: 0x2462, 175: 0x2463, 176: 0x2464, 177: 0x2465, 178: 0x2466, 179: 0x2467,
+	180: 0x2468, 181: 0x2469, 182: 0x2776, 183: 0x2777, 184: 0x2778, 185: 0x2779, 186: 0x277A, 187: 0x277B,
+	188: 0x277C, 189: 0x277D, 190: 0x277E, 191: 0x277F, 192: 0x2780, 193: 0x2781, 194: 0x2782, 195: 0x2783,
+	196: 0x2784, 197: 0x2785, 198: 0x2786, 199: 0x2787, 200: 0x2788, 201: 0x2789, 202: 0x278A, 203: 0x278B,
+	204: 0x278C, 205: 0x278D, 206: 0x278E, 207: 0x278F, 208: 0x2790, 209: 0x2791, 210: 0x2792, 211: 0x2793,
+	212: 0x2794, 213: 0x2192, 214: 0x2194, 215: 0x2195, 216: 0x2798, 217: 0x2799, 218: 0x279A, 219: 0x279B,
+	220: 0x279C, 221: 0x279D, 222: 0x279E, 223: 0x279F, 224: 0x27A0, 225: 0x27A1, 226: 0x27A2, 227: 0x27A3,
+	228: 0x27A4, 229: 0x27A5, 230: 0x27A6, 231: 0x27A7, 232: 0x27A8, 233: 0x27A9, 234: 0x27AA, 235: 0x27AB,
+	236: 0x27AC, 237: 0x27AD, 238: 0x27AE, 239: 0x27AF, 241: 0x27B1, 242: 0x27B2, 243: 0x27B3, 244: 0x27B4,
+	245: 0x27B5, 246: 0x27B6, 247: 0x27B7, 248: 0x27B8, 249: 0x27B9, 250: 0x27BA, 251: 0x27BB, 252: 0x27BC,
+	253: 0x27BD, 254: 0x27BE,
+}
+
+// standardEncodingHigh maps the character codes >= 0xA1 of StandardEncoding to Unicode.
+var standardEncodingHigh = map[byte]rune{
+	0xA1: 0x00A1, 0xA2: 0x00A2, 0xA3: 0x00A3, 0xA4: 0x2044, 0xA5: 0x00A5, 0xA6: 0x0192, 0xA7: 0x00A7, 0xA8: 0x00A4,
+	0xA9: 0x0027, 0xAA: 0x201C, 0xAB: 0x00AB, 0xAC: 0x2039, 0xAD: 0x203A, 0xAE: 0xFB01, 0xAF: 0xFB02, 0xB1: 0x2013,
+	0xB2: 0x2020, 0xB3: 0x2021, 0xB4: 0x00B7, 0xB6: 0x00B6, 0xB7: 0x2022, 0xB8: 0x201A, 0xB9: 0x201E, 0xBA: 0x201D,
+	0xBB: 0x00BB, 0xBC: 0x2026, 0xBD: 0x2030, 0xBF: 0x00BF, 0xC1: 0x0060, 0xC2: 0x00B4, 0xC3: 0x02C6, 0xC4: 0x02DC,
+	0xC5: 0x00AF, 0xC6: 0x02D8, 0xC7: 0x02D9, 0xC8: 0x00A8, 0xCA: 0x02DA, 0xCB: 0x00B8, 0xCD: 0x02DD, 0xCE: 0x02DB,
+	0xCF: 0x02C7, 0xD0: 0x2014, 0xE1: 0x00C6, 0xE3: 0x00AA, 0xE8: 0x0141, 0xE9: 0x00D8, 0xEA: 0x0152, 0xEB: 0x00BA,
+	0xF1: 0x00E6, 0xF5: 0x0131, 0xF8: 0x0142, 0xF9: 0x00F8, 0xFA: 0x0153, 0xFB: 0x00DF,
+}
+
+func charmapEncoding(cm *charmap.Charmap) *[256]rune {
+	var e [256]rune
+	for i := 0x20; i < 256; i++ {
+		if r := cm.DecodeByte(byte(i)); r != 0xFFFD {
+			e[i] = r
+		}
+	}
+	return &e
+}
+
+var winAnsiEncoding = charmapEncoding(charmap.Windows1252)
+
+func standardEncoding() *[256]rune {
+	var e [256]rune
+	for i := 0x20; i < 0x7F; i++ {
+		e[i] = rune(i)
+	}
+	e[0x27] = 0x2019 // quoteright
+	e[0x60] = 0x2018 // quoteleft
+	for c, r := range standardEncodingHigh {
+		e[c] = r
+	}
+	return &e
+}
+
+// baseEncoding returns the Unicode mapping for the character codes of a simple font using the named encoding.
+func baseEncoding(name string) *[256]rune {
+	switch name {
+	case "WinAnsiEncoding":
+		e := *winAnsiEncoding
+		return &e
+	case "MacRomanEncoding":
+		return charmapEncoding(charmap.Macintosh)
+	case "Symbol":
+		e := symbolEncoding
+		return &e
+	case "ZapfDingbats":
+		e := zapfDingbatsEncoding
+		return &e
+	}
+	return standardEncoding()
+}
+
+// glyphRune returns the Unicode character for glyphName using the glyph names of the standard fonts
+// and the uniXXXX and uXXXX[XX] naming conventions.
+func glyphRune(glyphName string) (rune, bool) {
+
+	for _, v := range []struct {
+		m map[int]string
+		e *[256]rune
+	}{
+		{metrics.WinAnsiGlyphMap, winAnsiEncoding},
+		{metrics.SymbolGlyphMap, &symbolEncoding},
+		{metrics.ZapfDingbatsGlyphMap, &zapfDingbatsEncoding},
+	} {
+		for c, s := range v.m {
+			if s == glyphName && c >= 0 && c < 256 && v.e[c] != 0 {
+				return v.e[c], true
+			}
+		}
+	}
+
+	for c, s := range standardGlyphNames {
+		if s == glyphName {
+			return standardEncodingHigh[c], true
+		}
+	}
+
+	var s string
+	switch {
+	case strings.HasPrefix(glyphName, "uni") && len(glyphName) == 7:
+		s = glyphName[3:]
+	case strings.HasPrefix(glyphName, "u") && len(glyphName) >= 5 && len(glyphName) <= 7:
+		s = glyphName[1:]
+	default:
+		return 0, false
+	}
+
+	i, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	return rune(i), true
+}
+
+// standardGlyphNames holds the glyph names of StandardEncoding not covered by WinAnsiEncoding.
+var standardGlyphNames = map[byte]string{
+	0xA4: "fraction", 0xAE: "fi", 0xAF: "fl", 0xC6: "breve", 0xC7: "dotaccent", 0xCA: "ring",
+	0xCD: "hungarumlaut", 0xCE: "ogonek", 0xCF: "caron", 0xE8: "Lslash", 0xF5: "dotlessi", 0xF8: "lslash",
+}
@@ -0,0 +1,309 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CryptFilterInfo describes a crypt filter of the encryption dict, see 7.6.5
+type CryptFilterInfo struct {
+	Name      string `json:"name"`
+	Method    string `json:"method"`
+	Algorithm string `json:"algorithm"`
+	KeyLength int    `json:"keyLength,omitempty"` // in bits
+	AuthEvent string `json:"authEvent,omitempty"`
+}
+
+// PermissionInfo represents the user access permission bits, see Table 22.
+type PermissionInfo struct {
+	Bits                 uint32 `json:"bits"`
+	Print                bool   `json:"print"`                // Bit 3
+	Modify               bool   `json:"modify"`               // Bit 4
+	Extract              bool   `json:"extract"`              // Bit 5
+	Annotate             bool   `json:"annotate"`             // Bit 6
+	FillForms            bool   `json:"fillForms"`            // Bit 9
+	ExtractAccessibility bool   `json:"extractAccessibility"` // Bit 10
+	Assemble             bool   `json:"assemble"`             // Bit 11
+	PrintHighQuality     bool   `json:"printHighQuality"`     // Bit 12
+}
+
+// EncryptionInfo represents the security settings of a document.
+type EncryptionInfo struct {
+	Encrypted       bool              `json:"encrypted"`
+	Filter          string            `json:"filter,omitempty"` // Security handler
+	SubFilter       string            `json:"subFilter,omitempty"`
+	V               int               `json:"v,omitempty"`
+	R               int               `json:"r,omitempty"`
+	Algorithm       string            `json:"algorithm,omitempty"`
+	KeyLength       int               `json:"keyLength,omitempty"` // in bits
+	StmF            string            `json:"stmF,omitempty"`
+	StrF            string            `json:"strF,omitempty"`
+	EFF             string            `json:"eff,omitempty"`
+	CryptFilters    []CryptFilterInfo `json:"cryptFilters,omitempty"`
+	EncryptMetadata bool              `json:"encryptMetadata"`
+	EmptyUserPW     bool              `json:"emptyUserPassword"` // Effectively unprotected.
+	Permissions     *PermissionInfo   `json:"permissions,omitempty"`
+}
+
+func cryptAlgorithm(cfm string, keyLength int) string {
+	switch cfm {
+	case "None":
+		return "none"
+	case "V2":
+		return fmt.Sprintf("RC4 %d bit", keyLength)
+	case "AESV2":
+		return "AES 128 bit"
+	case "AESV3":
+		return "AES 256 bit"
+	}
+	return "unknown"
+}
+
+// cryptFilterKeyLength returns the key length of a crypt filter in bits.
+// RC4 crypt filters without a length use the key length of the encryption dict.
+func cryptFilterKeyLength(d Dict, cfm string, defaultLength int) int {
+	switch cfm {
+	case "None":
+		return 0
+	case "AESV2":
+		return 128
+	case "AESV3":
+		return 256
+	}
+	l := d.IntEntry("Length")
+	if l == nil {
+		return defaultLength
+	}
+	if *l <= 32 {
+		// Some writers supply the length in bytes.
+		return *l * 8
+	}
+	return *l
+}
+
+// keyLength returns the key length of the encryption dict d in bits.
+func keyLength(d Dict) int {
+	if v := d.IntEntry("V"); v != nil && *v == 1 {
+		// V1 implies RC4 40 bit.
+		return 40
+	}
+	l, err := length(d)
+	if err != nil {
+		return 0
+	}
+	return l
+}
+
+func cryptFilterMethod(d1 Dict) string {
+	if n := d1.NameEntry("CFM"); n != nil {
+		return *n
+	}
+	return "None"
+}
+
+// cryptMethods returns the crypt filter methods in use for streams and strings and their key lengths in bits.
+func cryptMethods(d Dict) (cfms []string, keyLengths []int) {
+	l := keyLength(d)
+
+	v := d.IntEntry("V")
+	if v == nil || (*v != 4 && *v != 5) {
+		return []string{"V2"}, []int{l}
+	}
+
+	cfDict := d.DictEntry("CF")
+	for _, key := range []string{"StmF", "StrF"} {
+		n := d.NameEntry(key)
+		if n == nil || *n == "Identity" || cfDict == nil {
+			// Not encrypted.
+			continue
+		}
+		d1 := cfDict.DictEntry(*n)
+		if d1 == nil {
+			continue
+		}
+		cfm := cryptFilterMethod(d1)
+		if cfm == "None" {
+			continue
+		}
+		cfms = append(cfms, cfm)
+		keyLengths = append(keyLengths, cryptFilterKeyLength(d1, cfm, l))
+	}
+
+	return cfms, keyLengths
+}
+
+// cryptMethod returns the crypt filter method in effect for d and its key length in bits.
+// For V4 and V5 this is the crypt filter used for streams or, if streams are not encrypted, for strings.
+func cryptMethod(d Dict) (string, int) {
+	cfms, ll := cryptMethods(d)
+	if len(cfms) == 0 {
+		return "None", 0
+	}
+	return cfms[0], ll[0]
+}
+
+func cryptFilterInfos(d Dict) []CryptFilterInfo {
+	cfDict := d.DictEntry("CF")
+	if cfDict == nil {
+		return nil
+	}
+
+	defaultLength := keyLength(d)
+
+	var cfi []CryptFilterInfo
+	for k := range cfDict {
+		d1 := cfDict.DictEntry(k)
+		if d1 == nil {
+			continue
+		}
+		cfm := cryptFilterMethod(d1)
+		l := cryptFilterKeyLength(d1, cfm, defaultLength)
+		ci := CryptFilterInfo{Name: k, Method: cfm, Algorithm: cryptAlgorithm(cfm, l), KeyLength: l}
+		if n := d1.NameEntry("AuthEvent"); n != nil {
+			ci.AuthEvent = *n
+		}
+		cfi = append(cfi, ci)
+	}
+
+	sort.Slice(cfi, func(i, j int) bool { return cfi[i].Name < cfi[j].Name })
+
+	return cfi
+}
+
+func permissionInfo(p int) *PermissionInfo {
+	return &PermissionInfo{
+		Bits:                 uint32(p) & 0x0F3C,
+		Print:                p&0x0004 > 0,
+		Modify:               p&0x0008 > 0,
+		Extract:              p&0x0010 > 0,
+		Annotate:             p&0x0020 > 0,
+		FillForms:            p&0x0100 > 0,
+		ExtractAccessibility: p&0x0200 > 0,
+		Assemble:             p&0x0400 > 0,
+		PrintHighQuality:     p&0x0800 > 0,
+	}
+}
+
+func nameEntry(d Dict, key string) string {
+	if n := d.NameEntry(key); n != nil {
+		return *n
+	}
+	return ""
+}
+
+// EncryptionDetails returns the security settings of ctx's encryption dict.
+func EncryptionDetails(ctx *Context) (*EncryptionInfo, error) {
+	if ctx.Encrypt == nil {
+		return &EncryptionInfo{}, nil
+	}
+
+	d, err := ctx.DereferenceDict(*ctx.Encrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	ei := &EncryptionInfo{
+		Encrypted:       true,
+		Filter:          nameEntry(d, "Filter"),
+		SubFilter:       nameEntry(d, "SubFilter"),
+		StmF:            nameEntry(d, "StmF"),
+		StrF:            nameEntry(d, "StrF"),
+		EFF:             nameEntry(d, "EFF"),
+		CryptFilters:    cryptFilterInfos(d),
+		EncryptMetadata: true,
+	}
+
+	if v := d.IntEntry("V"); v != nil {
+		ei.V = *v
+	}
+	if r := d.IntEntry("R"); r != nil {
+		ei.R = *r
+	}
+	if emd := d.BooleanEntry("EncryptMetadata"); emd != nil {
+		ei.EncryptMetadata = *emd
+	}
+	if p := d.IntEntry("P"); p != nil {
+		ei.Permissions = permissionInfo(*p)
+	}
+	if ctx.E != nil {
+		ei.EmptyUserPW = ctx.E.EmptyUPW
+	}
+
+	cfm, l := cryptMethod(d)
+	ei.Algorithm, ei.KeyLength = cryptAlgorithm(cfm, l), l
+
+	return ei, nil
+}
+
+// List returns a human readable representation of ei.
+func (ei EncryptionInfo) List() []string {
+	if !ei.Encrypted {
+		return []string{"Encrypted: false"}
+	}
+
+	ss := []string{
+		"Encrypted: true",
+		fmt.Sprintf("Security handler: %s", ei.Filter),
+	}
+	if ei.SubFilter != "" {
+		ss = append(ss, fmt.Sprintf("SubFilter: %s", ei.SubFilter))
+	}
+	ss = append(ss,
+		fmt.Sprintf("V: %d, R: %d", ei.V, ei.R),
+		fmt.Sprintf("Algorithm: %s", ei.Algorithm),
+		fmt.Sprintf("Key length: %d bits", ei.KeyLength),
+	)
+
+	if len(ei.CryptFilters) > 0 {
+		ss = append(ss, fmt.Sprintf("StmF: %s, StrF: %s", ei.StmF, ei.StrF))
+		if ei.EFF != "" {
+			ss = append(ss, fmt.Sprintf("EFF: %s", ei.EFF))
+		}
+		ss = append(ss, "Crypt filters:")
+		for _, cf := range ei.CryptFilters {
+			s := fmt.Sprintf("   %s: %s (%s)", cf.Name, cf.Method, cf.Algorithm)
+			if cf.AuthEvent != "" {
+				s += fmt.Sprintf(" authEvent: %s", cf.AuthEvent)
+			}
+			ss = append(ss, s)
+		}
+	}
+
+	ss = append(ss, fmt.Sprintf("Encrypt metadata: %t", ei.EncryptMetadata))
+
+	if ei.EmptyUserPW {
+		ss = append(ss, "Empty user password: true (effectively unprotected)")
+	}
+
+	if p := ei.Permissions; p != nil {
+		ss = append(ss,
+			fmt.Sprintf("Permission bits: %12b", p.Bits),
+			fmt.Sprintf("   print:                 %t", p.Print),
+			fmt.Sprintf("   modify:                %t", p.Modify),
+			fmt.Sprintf("   extract:               %t", p.Extract),
+			fmt.Sprintf("   annotate:              %t", p.Annotate),
+			fmt.Sprintf("   fill in form fields:   %t", p.FillForms),
+			fmt.Sprintf("   extract accessibility: %t", p.ExtractAccessibility),
+			fmt.Sprintf("   assemble:              %t", p.Assemble),
+			fmt.Sprintf("   print high quality:    %t", p.PrintHighQuality),
+		)
+	}
+
+	return ss
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"strings"
+	"testing"
+)
+
+func encryptDictV4(stmf, strf string, cf Dict) Dict {
+	d := Dict(map[string]Object{
+		"Filter": Name("Standard"),
+		"V":      Integer(4),
+		"R":      Integer(4),
+		"Length": Integer(128),
+		"CF":     cf,
+	})
+	if stmf != "" {
+		d["StmF"] = Name(stmf)
+	}
+	if strf != "" {
+		d["StrF"] = Name(strf)
+	}
+	return d
+}
+
+func TestEncryptionAlgorithm(t *testing.T) {
+	aesCF := Dict(map[string]Object{"StdCF": Dict(map[string]Object{"CFM": Name("AESV2"), "Length": Integer(16)})})
+	rc4CF := Dict(map[string]Object{"StdCF": Dict(map[string]Object{"CFM": Name("V2")})})
+
+	for _, tt := range []struct {
+		name      string
+		d         Dict
+		algorithm string
+		keyLength int
+		warning   string
+	}{
+		{
+			"V1 without Length",
+			Dict(map[string]Object{"Filter": Name("Standard"), "V": Integer(1), "R": Integer(2)}),
+			"RC4 40 bit", 40, "weak encryption: RC4 40 bit (V=1, R=2)",
+		},
+		{
+			"V2 with Length",
+			Dict(map[string]Object{"Filter": Name("Standard"), "V": Integer(2), "R": Integer(3), "Length": Integer(128)}),
+			"RC4 128 bit", 128, "weak encryption: RC4 128 bit (V=2, R=3)",
+		},
+		{
+			"V4 AESV2",
+			encryptDictV4("StdCF", "StdCF", aesCF),
+			"AES 128 bit", 128, "",
+		},
+		{
+			"V4 Identity streams, AESV2 strings",
+			encryptDictV4("Identity", "StdCF", aesCF),
+			"AES 128 bit", 128, "",
+		},
+		{
+			"V4 Identity",
+			encryptDictV4("", "", aesCF),
+			"none", 0, "",
+		},
+		{
+			"V4 RC4 crypt filter without Length",
+			encryptDictV4("StdCF", "StdCF", rc4CF),
+			"RC4 128 bit", 128, "weak encryption: RC4 128 bit (V=4, R=4)",
+		},
+	} {
+		ctx, err := CreateContextWithXRefTable(NewDefaultConfiguration(), PaperSize["A4"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ctx.Encrypt, err = ctx.IndRefForNewObject(tt.d); err != nil {
+			t.Fatal(err)
+		}
+		ctx.E = &Enc{V: *tt.d.IntEntry("V"), R: *tt.d.IntEntry("R")}
+
+		ei, err := EncryptionDetails(ctx)
+		if err != nil {
+			t.Fatalf("%s: %v\n", tt.name, err)
+		}
+		if ei.Algorithm != tt.algorithm || ei.KeyLength != tt.keyLength {
+			t.Errorf("%s: want %s/%d, got %s/%d\n", tt.name, tt.algorithm, tt.keyLength, ei.Algorithm, ei.KeyLength)
+		}
+
+		checkEncryptionStrength(ctx, tt.d)
+		if tt.warning == "" {
+			if len(ctx.Warnings) > 0 {
+				t.Errorf("%s: unexpected warnings: %v\n", tt.name, ctx.Warnings)
+			}
+			continue
+		}
+		if len(ctx.Warnings) != 1 || !strings.HasPrefix(ctx.Warnings[0], tt.warning) {
+			t.Errorf("%s: want warning %q, got %v\n", tt.name, tt.warning, ctx.Warnings)
+		}
+	}
+}
+
+func TestParsePermissions(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want int16
+	}{
+		{"none", PermissionsNone},
+		{"all", PermissionsAll},
+		{"-3904", -3904},
+		{"print", PermissionsNone | PermissionPrint | PermissionPrintHighQuality},
+		{"Annotate, forms", PermissionsNone | PermissionAnnotate | PermissionFillForms},
+	} {
+		p, err := ParsePermissions(tt.s)
+		if err != nil {
+			t.Fatalf("%s: %v\n", tt.s, err)
+		}
+		if p != tt.want {
+			t.Errorf("%s: want %#x, got %#x\n", tt.s, uint16(tt.want), uint16(p))
+		}
+	}
+
+	if _, err := ParsePermissions("print,fax"); err == nil {
+		t.Errorf("want error for unknown permission\n")
+	}
+}
@@ -0,0 +1,454 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Expression is a compiled user expression like "pagecount > 10 && page % 2 == 1".
+//
+// Supported are number, string ("..." or '...') and boolean literals, variables,
+// the arithmetic operators + - * / %, the comparison operators == != < <= > >=,
+// the logical operators && || ! and parentheses.
+// + concatenates if one of its operands is a string.
+type Expression struct {
+	src  string
+	root *exprNode
+}
+
+type exprNode struct {
+	op          string      // operator, empty for literals and variables.
+	val         interface{} // literal value: float64, string or bool.
+	name        string      // variable name.
+	left, right *exprNode   // operands, right is nil for unary operators.
+}
+
+type exprToken struct {
+	kind byte // 'n'umber, 's'tring, 'i'dentifier or 'o'perator.
+	s    string
+	pos  int
+}
+
+// Longer operators go first.
+var exprOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "%", "(", ")"}
+
+var exprPrecedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+func isExprDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isExprLetter(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func tokenizeExpression(s string) ([]exprToken, error) {
+	var tt []exprToken
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+
+		case c == ' ' || c == '\t':
+			i++
+
+		case isExprDigit(c) || c == '.':
+			j := i
+			for j < len(s) && (isExprDigit(s[j]) || s[j] == '.') {
+				j++
+			}
+			tt = append(tt, exprToken{kind: 'n', s: s[i:j], pos: i})
+			i = j
+
+		case c == '"' || c == '\'':
+			j := strings.IndexByte(s[i+1:], c)
+			if j < 0 {
+				return nil, errors.Errorf("pdfcpu: expression: unterminated string at %d", i)
+			}
+			tt = append(tt, exprToken{kind: 's', s: s[i+1 : i+1+j], pos: i})
+			i += j + 2
+
+		case isExprLetter(c):
+			j := i
+			for j < len(s) && (isExprLetter(s[j]) || isExprDigit(s[j])) {
+				j++
+			}
+			tt = append(tt, exprToken{kind: 'i', s: s[i:j], pos: i})
+			i = j
+
+		default:
+			var op string
+			for _, o := range exprOperators {
+				if strings.HasPrefix(s[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, errors.Errorf("pdfcpu: expression: unexpected %q at %d", c, i)
+			}
+			tt = append(tt, exprToken{kind: 'o', s: op, pos: i})
+			i += len(op)
+		}
+	}
+
+	return tt, nil
+}
+
+type exprParser struct {
+	tt []exprToken
+	i  int
+}
+
+func (p *exprParser) peek() *exprToken {
+	if p.i >= len(p.tt) {
+		return nil
+	}
+	return &p.tt[p.i]
+}
+
+func (p *exprParser) binary(minPrec int) (*exprNode, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		if t == nil || t.kind != 'o' {
+			return left, nil
+		}
+		prec, ok := exprPrecedence[t.s]
+		if !ok || prec < minPrec {
+			return left, nil
+		}
+		p.i++
+		right, err := p.binary(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &exprNode{op: t.s, left: left, right: right}
+	}
+}
+
+func (p *exprParser) unary() (*exprNode, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, errors.New("pdfcpu: expression: unexpected end")
+	}
+	p.i++
+
+	switch t.kind {
+
+	case 'n':
+		f, err := strconv.ParseFloat(t.s, 64)
+		if err != nil {
+			return nil, errors.Errorf("pdfcpu: expression: invalid number %s at %d", t.s, t.pos)
+		}
+		return &exprNode{val: f}, nil
+
+	case 's':
+		return &exprNode{val: t.s}, nil
+
+	case 'i':
+		switch t.s {
+		case "true":
+			return &exprNode{val: true}, nil
+		case "false":
+			return &exprNode{val: false}, nil
+		}
+		return &exprNode{name: t.s}, nil
+	}
+
+	switch t.s {
+
+	case "!", "-":
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &exprNode{op: t.s, left: n}, nil
+
+	case "(":
+		n, err := p.binary(1)
+		if err != nil {
+			return nil, err
+		}
+		if t := p.peek(); t == nil || t.kind != 'o' || t.s != ")" {
+			return nil, errors.New("pdfcpu: expression: missing )")
+		}
+		p.i++
+		return n, nil
+	}
+
+	return nil, errors.Errorf("pdfcpu: expression: unexpected %s at %d", t.s, t.pos)
+}
+
+// ParseExpression compiles s into an Expression.
+func ParseExpression(s string) (*Expression, error) {
+	tt, err := tokenizeExpression(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tt: tt}
+	n, err := p.binary(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t != nil {
+		return nil, errors.Errorf("pdfcpu: expression: unexpected %s at %d", t.s, t.pos)
+	}
+
+	return &Expression{src: s, root: n}, nil
+}
+
+func (e *Expression) String() string {
+	return e.src
+}
+
+// exprValue normalizes a variable value.
+func exprValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case float64, string, bool:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case float32:
+		return float64(v), nil
+	}
+	return nil, errors.Errorf("pdfcpu: expression: unsupported value type %T", v)
+}
+
+func exprString(v interface{}) string {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return v.(string)
+}
+
+func exprBool(v interface{}) (bool, error) {
+	b, ok := v.(bool)
+	if !ok {
+		return false, errors.Errorf("pdfcpu: expression: %v is not a boolean", v)
+	}
+	return b, nil
+}
+
+func exprNumbers(op string, l, r interface{}) (float64, float64, error) {
+	f1, ok1 := l.(float64)
+	f2, ok2 := r.(float64)
+	if !ok1 || !ok2 {
+		return 0, 0, errors.Errorf("pdfcpu: expression: %s needs numbers: %v, %v", op, l, r)
+	}
+	return f1, f2, nil
+}
+
+func exprCompare(op string, l, r interface{}) (bool, error) {
+	var c int
+
+	switch l := l.(type) {
+	case float64:
+		f, ok := r.(float64)
+		if !ok {
+			return false, errors.Errorf("pdfcpu: expression: cannot compare %v and %v", l, r)
+		}
+		if l < f {
+			c = -1
+		} else if l > f {
+			c = 1
+		}
+	case string:
+		s, ok := r.(string)
+		if !ok {
+			return false, errors.Errorf("pdfcpu: expression: cannot compare %v and %v", l, r)
+		}
+		c = strings.Compare(l, s)
+	default:
+		return false, errors.Errorf("pdfcpu: expression: cannot compare %v and %v", l, r)
+	}
+
+	switch op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	}
+	return c >= 0, nil
+}
+
+func exprBinary(op string, l, r interface{}) (interface{}, error) {
+	switch op {
+
+	case "==":
+		return l == r, nil
+
+	case "!=":
+		return l != r, nil
+
+	case "<", "<=", ">", ">=":
+		return exprCompare(op, l, r)
+
+	case "+":
+		_, ok1 := l.(string)
+		_, ok2 := r.(string)
+		if ok1 || ok2 {
+			return exprString(l) + exprString(r), nil
+		}
+	}
+
+	f1, f2, err := exprNumbers(op, l, r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "+":
+		return f1 + f2, nil
+	case "-":
+		return f1 - f2, nil
+	case "*":
+		return f1 * f2, nil
+	}
+
+	if f2 == 0 {
+		return nil, errors.New("pdfcpu: expression: division by zero")
+	}
+	if op == "/" {
+		return f1 / f2, nil
+	}
+	return math.Mod(f1, f2), nil
+}
+
+func (n *exprNode) eval(vars map[string]interface{}) (interface{}, error) {
+	if n.op == "" {
+		if n.name == "" {
+			return n.val, nil
+		}
+		v, ok := vars[n.name]
+		if !ok {
+			return nil, errors.Errorf("pdfcpu: expression: unknown variable: %s", n.name)
+		}
+		return exprValue(v)
+	}
+
+	l, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.right == nil {
+		if n.op == "!" {
+			b, err := exprBool(l)
+			return !b, err
+		}
+		f, ok := l.(float64)
+		if !ok {
+			return nil, errors.Errorf("pdfcpu: expression: cannot negate %v", l)
+		}
+		return -f, nil
+	}
+
+	if n.op == "&&" || n.op == "||" {
+		b, err := exprBool(l)
+		if err != nil {
+			return nil, err
+		}
+		if b == (n.op == "||") {
+			return b, nil
+		}
+		r, err := n.right.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		return exprBool(r)
+	}
+
+	r, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	return exprBinary(n.op, l, r)
+}
+
+// Eval evaluates e for vars and returns a float64, string or bool.
+// Variables may be of type int, int64, float32, float64, string or bool.
+func (e *Expression) Eval(vars map[string]interface{}) (interface{}, error) {
+	return e.root.eval(vars)
+}
+
+// EvalBool evaluates the condition e for vars.
+func (e *Expression) EvalBool(vars map[string]interface{}) (bool, error) {
+	v, err := e.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	return exprBool(v)
+}
+
+// ExpandTemplate replaces each expression in curly braces within tmpl by its value for vars,
+// eg. "{name}_{from}-{thru}.pdf" or "page_{page+1}.pdf".
+func ExpandTemplate(tmpl string, vars map[string]interface{}) (string, error) {
+	var sb strings.Builder
+
+	for {
+		i := strings.IndexByte(tmpl, '{')
+		if i < 0 {
+			sb.WriteString(tmpl)
+			return sb.String(), nil
+		}
+		j := strings.IndexByte(tmpl[i:], '}')
+		if j < 0 {
+			return "", errors.Errorf("pdfcpu: template: missing } in %s", tmpl)
+		}
+
+		e, err := ParseExpression(tmpl[i+1 : i+j])
+		if err != nil {
+			return "", err
+		}
+		v, err := e.Eval(vars)
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString(tmpl[:i])
+		sb.WriteString(exprString(v))
+		tmpl = tmpl[i+j+1:]
+	}
+}
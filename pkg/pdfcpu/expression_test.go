@@ -0,0 +1,83 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import "testing"
+
+func TestExpression(t *testing.T) {
+	vars := map[string]interface{}{"page": 3, "pagecount": 12, "name": "in", "draft": true}
+
+	for _, tt := range []struct {
+		s    string
+		want interface{}
+	}{
+		{"1 + 2 * 3", 7.0},
+		{"(1 + 2) * 3", 9.0},
+		{"-page + 1", -2.0},
+		{"pagecount / 4 - 1", 2.0},
+		{"page % 2", 1.0},
+		{"pagecount > 10", true},
+		{"page % 2 == 0 || page == pagecount", false},
+		{"!draft && page <= 3", false},
+		{"name + '_' + page", "in_3"},
+		{"name == \"in\" && name < 'out'", true},
+	} {
+		e, err := ParseExpression(tt.s)
+		if err != nil {
+			t.Fatalf("%s: %v\n", tt.s, err)
+		}
+		got, err := e.Eval(vars)
+		if err != nil {
+			t.Fatalf("%s: %v\n", tt.s, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: want %v, got %v\n", tt.s, tt.want, got)
+		}
+	}
+
+	for _, s := range []string{"", "1 +", "(1", "1 2", "page $ 2", "'open"} {
+		if _, err := ParseExpression(s); err == nil {
+			t.Errorf("%s: want parse error\n", s)
+		}
+	}
+
+	for _, s := range []string{"unknown > 1", "page / 0", "name * 2", "page && draft", "name < 1"} {
+		e, err := ParseExpression(s)
+		if err != nil {
+			t.Fatalf("%s: %v\n", s, err)
+		}
+		if _, err := e.Eval(vars); err == nil {
+			t.Errorf("%s: want eval error\n", s)
+		}
+	}
+}
+
+func TestExpandTemplate(t *testing.T) {
+	vars := map[string]interface{}{"name": "in", "from": 3, "thru": 4}
+
+	s, err := ExpandTemplate("{name}_{from}-{thru}_of_{thru-from+1}.pdf", vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "in_3-4_of_2.pdf"; s != want {
+		t.Errorf("want %s, got %s\n", want, s)
+	}
+
+	if _, err := ExpandTemplate("{name.pdf", vars); err == nil {
+		t.Error("want error for missing }")
+	}
+}
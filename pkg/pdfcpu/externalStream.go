@@ -0,0 +1,270 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// External stream data, see 7.3.8.2 Stream Extent:
+// If F is present the bytes between stream and endstream are ignored
+// and the stream data is read from the specified file using FFilter and FDecodeParms.
+//
+// Reading external files is opt-in via Configuration.ResolveExternalStreams.
+// Files are resolved relative to the directory of the input file and must not escape it.
+
+// IsExternal returns true if sd's data lives in an external file.
+func (sd StreamDict) IsExternal() bool {
+	_, found := sd.Find("F")
+	return found
+}
+
+func externalFileName(xRefTable *XRefTable, o Object) (string, error) {
+	o, err := xRefTable.Dereference(o)
+	if err != nil {
+		return "", err
+	}
+
+	switch o := o.(type) {
+
+	case StringLiteral, HexLiteral:
+		return xRefTable.DereferenceStringOrHexLiteral(o, V10, nil)
+
+	case Dict:
+		return fileSpectStreamFileName(xRefTable, o)
+
+	}
+
+	return "", errors.Errorf("pdfcpu: invalid file specification: %v", o)
+}
+
+// maxExternalFileSize limits the amount of data read from a single external file.
+const maxExternalFileSize = 1 << 26
+
+// externalFileSystem returns the file system external files are resolved against.
+// Unless configured this is the directory of the input file.
+func externalFileSystem(ctx *Context) (fs.FS, error) {
+	if ctx.FileSystem != nil {
+		return ctx.FileSystem, nil
+	}
+	if ctx.Read == nil || ctx.Read.FileName == "" {
+		return nil, errors.New("pdfcpu: external file: no file system configured and input is not a file")
+	}
+	return DirFS(filepath.Dir(ctx.Read.FileName)), nil
+}
+
+// dirFS is the file system of a local directory refusing to follow symbolic links,
+// which might point outside of the directory.
+type dirFS string
+
+// DirFS returns the file system of the local directory dir for resolving external files.
+// Unlike os.DirFS it does not follow symbolic links.
+func DirFS(dir string) fs.FS {
+	return dirFS(dir)
+}
+
+func (dir dirFS) path(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	p := string(dir)
+	for _, elem := range strings.Split(name, "/") {
+		p = filepath.Join(p, elem)
+		fi, err := os.Lstat(p)
+		if err != nil {
+			return "", err
+		}
+		if fi.Mode()&fs.ModeSymlink != 0 {
+			return "", &fs.PathError{Op: op, Path: name, Err: errors.New("symbolic link")}
+		}
+	}
+	return p, nil
+}
+
+func (dir dirFS) Open(name string) (fs.File, error) {
+	p, err := dir.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+func (dir dirFS) Stat(name string) (fs.FileInfo, error) {
+	p, err := dir.path("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Lstat(p)
+}
+
+// externalFilePath returns fileName as an io/fs path relative to the base directory.
+// Absolute paths and paths escaping the base directory are refused.
+func externalFilePath(fileName string) (string, error) {
+	if fileName == "" || path.IsAbs(fileName) || filepath.IsAbs(fileName) || filepath.VolumeName(fileName) != "" {
+		return "", errors.Errorf("pdfcpu: external file: invalid path %q", fileName)
+	}
+	name := path.Clean(fileName)
+	if name == "." || !fs.ValidPath(name) {
+		return "", errors.Errorf("pdfcpu: external file: invalid path %q", fileName)
+	}
+	return name, nil
+}
+
+// readExternalFile returns the content of the regular file fileName.
+func readExternalFile(ctx *Context, fileName string) ([]byte, error) {
+	fsys, err := externalFileSystem(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := externalFilePath(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stat first in order not to block on opening devices or named pipes.
+	fi, err := fs.Stat(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, errors.Errorf("pdfcpu: external file: %s is not a regular file", fileName)
+	}
+	if fi.Size() > maxExternalFileSize {
+		return nil, errors.Errorf("pdfcpu: external file: %s exceeds %d bytes", fileName, maxExternalFileSize)
+	}
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	bb, err := ioutil.ReadAll(io.LimitReader(f, maxExternalFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(bb) > maxExternalFileSize {
+		return nil, errors.Errorf("pdfcpu: external file: %s exceeds %d bytes", fileName, maxExternalFileSize)
+	}
+
+	return bb, nil
+}
+
+// externalStreamData returns the encoded data of an external stream and its filter pipeline.
+func externalStreamData(ctx *Context, sd *StreamDict) ([]byte, []PDFFilter, error) {
+	fileName, err := externalFileName(ctx.XRefTable, sd.Dict["F"])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw, err := readExternalFile(ctx, fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d := NewDict()
+	if o, found := sd.Find("FFilter"); found {
+		d.Insert("Filter", o)
+	}
+	if o, found := sd.Find("FDecodeParms"); found {
+		d.Insert("DecodeParms", o)
+	}
+
+	fp, err := pdfFilterPipeline(ctx, d)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return raw, fp, nil
+}
+
+// resolveExternalStream loads and decodes the data of an external stream into sd.Content.
+func resolveExternalStream(ctx *Context, sd *StreamDict, objNr int) error {
+	raw, fp, err := externalStreamData(ctx, sd)
+	if err != nil {
+		// Keep going, the data is unavailable but the document may still be processed.
+		ctx.Warn("obj#%d: unable to load external stream data: %v", objNr, err)
+		return nil
+	}
+
+	sd1 := StreamDict{Dict: sd.Dict, Raw: raw, FilterPipeline: fp}
+	if err := sd1.Decode(); err != nil {
+		ctx.Warn("obj#%d: unable to decode external stream data: %v", objNr, err)
+		return nil
+	}
+
+	sd.Content = sd1.Content
+
+	return nil
+}
+
+// InternalizeStreams embeds the data of all external streams into ctx
+// and returns the number of internalized streams.
+func InternalizeStreams(ctx *Context) (int, error) {
+	var count int
+
+	for objNr, entry := range ctx.Table {
+		if entry.Free || entry.Object == nil {
+			continue
+		}
+
+		sd, ok := entry.Object.(StreamDict)
+		if !ok || !sd.IsExternal() {
+			continue
+		}
+
+		raw, fp, err := externalStreamData(ctx, &sd)
+		if err != nil {
+			return 0, errors.Wrapf(err, "pdfcpu: obj#%d: internalize stream", objNr)
+		}
+
+		sd.Delete("Filter")
+		sd.Delete("DecodeParms")
+		if o, found := sd.Find("FFilter"); found {
+			sd.Insert("Filter", o)
+		}
+		if o, found := sd.Find("FDecodeParms"); found {
+			sd.Insert("DecodeParms", o)
+		}
+		sd.Delete("F")
+		sd.Delete("FFilter")
+		sd.Delete("FDecodeParms")
+
+		sd.Raw = raw
+		sd.FilterPipeline = fp
+		l := int64(len(raw))
+		sd.StreamLength = &l
+		sd.Update("Length", Integer(l))
+
+		entry.Object = sd
+		count++
+
+		log.Debug.Printf("InternalizeStreams: obj#%d, %d bytes\n", objNr, l)
+	}
+
+	return count, nil
+}
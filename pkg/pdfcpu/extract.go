@@ -400,7 +400,7 @@ func (ctx *Context) ExtractFont(objNr int) (*Font, error) {
 		return nil, nil
 	}
 
-	ir := fontDescriptorFontFileIndirectObjectRef(d)
+	ir := fontDescriptorFontFileIndirectObjectRef(ctx.XRefTable, d)
 	if ir == nil {
 		log.Debug.Printf("ExtractFont: ignoring obj#%d - no font file available for font: %s\n", objNr, fontObject.FontName)
 		return nil, nil
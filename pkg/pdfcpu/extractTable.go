@@ -0,0 +1,357 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// Tables are detected in two passes:
+// Ruled tables are grids of connected horizontal and vertical ruling lines painted as lines or thin rectangles.
+// The remaining text is scanned for consecutive rows of lines split at column gaps into aligned columns.
+
+const (
+	rulingTolerance  = 2.  // max deviation of ruling lines considered to be aligned, in user space.
+	minRulingLength  = 5.  // in user space.
+	minTableRows     = 3   // whitespace aligned tables.
+	minTableColumns  = 3   // whitespace aligned tables.
+	maxTableRowSpace = 3.5 // max baseline distance of rows of whitespace aligned tables relative to the font size.
+)
+
+// Table represents a table detected on a page.
+type Table struct {
+	BBox  [4]float64 `json:"bbox"`
+	Ruled bool       `json:"ruled"` // detected by ruling lines, else by whitespace alignment.
+	Rows  [][]string `json:"rows"`
+}
+
+// PageTables represents the tables detected on a page.
+type PageTables struct {
+	Page   int     `json:"page"`
+	Tables []Table `json:"tables"`
+}
+
+// ruling is a horizontal or vertical line at pos spanning lo to hi.
+type ruling struct {
+	horizontal bool
+	pos        float64
+	lo, hi     float64
+}
+
+func (r ruling) intersects(r1 ruling) bool {
+	return r.horizontal != r1.horizontal &&
+		r1.pos >= r.lo-rulingTolerance && r1.pos <= r.hi+rulingTolerance &&
+		r.pos >= r1.lo-rulingTolerance && r.pos <= r1.hi+rulingTolerance
+}
+
+type tableExtractor struct {
+	textExtractor
+	rulings []ruling
+}
+
+func (tx *tableExtractor) addRuling(gs *cbGState, p1, p2 Point) {
+	if intersectRect(unionRectPoint(Rect(p1.X, p1.Y, p1.X, p1.Y), p2), gs.clip) == nil {
+		return
+	}
+	dx, dy := math.Abs(p2.X-p1.X), math.Abs(p2.Y-p1.Y)
+	switch {
+	case dy <= rulingTolerance && dx >= minRulingLength:
+		tx.rulings = append(tx.rulings, ruling{true, (p1.Y + p2.Y) / 2, math.Min(p1.X, p2.X), math.Max(p1.X, p2.X)})
+	case dx <= rulingTolerance && dy >= minRulingLength:
+		tx.rulings = append(tx.rulings, ruling{false, (p1.X + p2.X) / 2, math.Min(p1.Y, p2.Y), math.Max(p1.Y, p2.Y)})
+	}
+}
+
+// mergeRulings joins overlapping or adjoining rulings sharing their orientation and position,
+// eg. the edges of thin rectangles or dashed lines.
+func mergeRulings(rr []ruling) []ruling {
+	sort.Slice(rr, func(i, j int) bool {
+		if rr[i].horizontal != rr[j].horizontal {
+			return rr[i].horizontal
+		}
+		if math.Abs(rr[i].pos-rr[j].pos) > rulingTolerance {
+			return rr[i].pos < rr[j].pos
+		}
+		return rr[i].lo < rr[j].lo
+	})
+
+	var res []ruling
+	for _, r := range rr {
+		if i := len(res) - 1; i >= 0 {
+			r0 := &res[i]
+			if r0.horizontal == r.horizontal && math.Abs(r0.pos-r.pos) <= rulingTolerance && r.lo <= r0.hi+rulingTolerance {
+				r0.hi = math.Max(r0.hi, r.hi)
+				continue
+			}
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+// gridLines returns the distinct positions of rr.
+func gridLines(rr []ruling) []float64 {
+	var ff []float64
+	for _, r := range rr {
+		ff = append(ff, r.pos)
+	}
+	sort.Float64s(ff)
+	var res []float64
+	for _, f := range ff {
+		if len(res) == 0 || f-res[len(res)-1] > rulingTolerance {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+// grids returns the connected components of rulings.
+func grids(rr []ruling) [][]ruling {
+	comp := make([]int, len(rr))
+	for i := range comp {
+		comp[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if comp[i] != i {
+			comp[i] = find(comp[i])
+		}
+		return comp[i]
+	}
+
+	for i := range rr {
+		for j := i + 1; j < len(rr); j++ {
+			if rr[i].intersects(rr[j]) {
+				comp[find(i)] = find(j)
+			}
+		}
+	}
+
+	m := map[int]int{}
+	var res [][]ruling
+	for i, r := range rr {
+		c := find(i)
+		k, ok := m[c]
+		if !ok {
+			k = len(res)
+			m[c] = k
+			res = append(res, nil)
+		}
+		res[k] = append(res[k], r)
+	}
+	return res
+}
+
+// text returns the text of gg as a single line.
+func text(gg []*textGlyph) string {
+	te := &textExtractor{glyphs: gg}
+	var ss []string
+	for _, b := range blocks(te.lines()) {
+		for _, l := range b.lines {
+			ss = append(ss, l.text())
+		}
+	}
+	return strings.Join(ss, " ")
+}
+
+func interval(ff []float64, f float64) int {
+	for i := 0; i+1 < len(ff); i++ {
+		if f >= ff[i] && f < ff[i+1] {
+			return i
+		}
+	}
+	return -1
+}
+
+// tabular returns true if at least two rows contain text in at least two cells.
+func tabular(cells [][]*textGlyph, cols int) bool {
+	m := map[int]int{}
+	for k, gg := range cells {
+		if len(gg) > 0 {
+			m[k/cols]++
+		}
+	}
+	n := 0
+	for _, c := range m {
+		if c >= 2 {
+			n++
+		}
+	}
+	return n >= 2
+}
+
+// ruledTables returns the tables defined by grids of rulings and the glyphs outside of these tables.
+func (tx *tableExtractor) ruledTables() ([]Table, []*textGlyph) {
+	var tt []Table
+
+	gg := tx.glyphs
+
+	for _, rr := range grids(mergeRulings(tx.rulings)) {
+		var hh, vv []ruling
+		for _, r := range rr {
+			if r.horizontal {
+				hh = append(hh, r)
+			} else {
+				vv = append(vv, r)
+			}
+		}
+
+		ys, xs := gridLines(hh), gridLines(vv)
+		if len(ys) < 3 || len(xs) < 3 {
+			continue
+		}
+
+		rows, cols := len(ys)-1, len(xs)-1
+		cells := make([][]*textGlyph, rows*cols)
+
+		var rest []*textGlyph
+		for _, g := range gg {
+			c := Point{(g.box.LL.X + g.box.UR.X) / 2, (g.box.LL.Y + g.box.UR.Y) / 2}
+			i, j := interval(ys, c.Y), interval(xs, c.X)
+			if i < 0 || j < 0 {
+				rest = append(rest, g)
+				continue
+			}
+			// Rows from top to bottom.
+			k := (rows-1-i)*cols + j
+			cells[k] = append(cells[k], g)
+		}
+
+		if !tabular(cells, cols) {
+			// eg. a frame or a box with a header bar.
+			continue
+		}
+		gg = rest
+
+		t := Table{BBox: bboxArray(Rect(xs[0], ys[0], xs[cols], ys[rows])), Ruled: true}
+		for i := 0; i < rows; i++ {
+			row := make([]string, cols)
+			for j := range row {
+				row[j] = text(cells[i*cols+j])
+			}
+			t.Rows = append(t.Rows, row)
+		}
+		tt = append(tt, t)
+	}
+
+	return tt, gg
+}
+
+// alignedTables returns the tables made up of consecutive rows of lines aligned in columns.
+func alignedTables(gg []*textGlyph) []Table {
+	te := &textExtractor{glyphs: gg}
+
+	// Horizontal lines grouped by baseline.
+	var rows [][]*textLine
+	for _, l := range te.lines() {
+		if l.dir != 0 || len(l.glyphs) == 0 {
+			continue
+		}
+		if n := len(rows); n > 0 && rows[n-1][0].row == l.row {
+			rows[n-1] = append(rows[n-1], l)
+			continue
+		}
+		rows = append(rows, []*textLine{l})
+	}
+
+	var (
+		tt   []Table
+		run  [][]*textLine
+		cols [][2]float64
+	)
+
+	flush := func() {
+		if len(run) >= minTableRows {
+			var box *Rectangle
+			t := Table{}
+			for _, r := range run {
+				row := make([]string, len(r))
+				for i, l := range r {
+					row[i] = l.text()
+					for _, g := range l.glyphs {
+						box = unionRect(box, g.box)
+					}
+				}
+				t.Rows = append(t.Rows, row)
+			}
+			t.BBox = bboxArray(box)
+			tt = append(tt, t)
+		}
+		run, cols = nil, nil
+	}
+
+	aligned := func(r []*textLine) bool {
+		if len(r) != len(cols) {
+			return false
+		}
+		prev := run[len(run)-1]
+		if prev[0].y-r[0].y > maxTableRowSpace*math.Max(prev[0].size, r[0].size) {
+			return false
+		}
+		for i, l := range r {
+			if l.x1 < cols[i][0] || l.x0 > cols[i][1] {
+				return false
+			}
+			if i > 0 && l.x0 <= cols[i-1][1] {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, r := range rows {
+		if len(r) < minTableColumns {
+			flush()
+			continue
+		}
+		if len(run) > 0 && !aligned(r) {
+			flush()
+		}
+		if len(run) == 0 {
+			cols = make([][2]float64, len(r))
+			for i, l := range r {
+				cols[i] = [2]float64{l.x0, l.x1}
+			}
+		}
+		for i, l := range r {
+			cols[i] = [2]float64{math.Min(cols[i][0], l.x0), math.Max(cols[i][1], l.x1)}
+		}
+		run = append(run, r)
+	}
+	flush()
+
+	return tt
+}
+
+// ExtractPageTables detects ruled and whitespace aligned tables on page pageNr and extracts their cell text.
+func (ctx *Context) ExtractPageTables(pageNr int) (*PageTables, error) {
+	tx := &tableExtractor{}
+	cb := &contentBoxer{ctx: ctx, fonts: map[int]*cbFont{}, forms: map[int]bool{}, glyph: tx.addGlyph, rule: tx.addRuling}
+
+	if err := ctx.processPage(pageNr, cb); err != nil {
+		return nil, err
+	}
+
+	tt, gg := tx.ruledTables()
+
+	pt := &PageTables{Page: pageNr, Tables: append([]Table{}, tt...)}
+	pt.Tables = append(pt.Tables, alignedTables(gg)...)
+
+	return pt, nil
+}
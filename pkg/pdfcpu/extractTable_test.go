@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTableDetection(t *testing.T) {
+	const (
+		text = "BT /F1 12 Tf 72 450 Td (Some text above the table) Tj ET "
+		grid = "100 300 m 400 300 l 100 340 m 400 340 l 100 380 m 400 380 l S " +
+			"100 300 m 100 380 l 200 300 m 200 380 l 300 300 m 300 380 l 400 300 m 400 380 l S "
+		thinRects = "100 300 300 .5 re 100 340 300 .5 re 100 380 300 .5 re f " +
+			"100 300 .5 80 re 200 300 .5 80 re f 300 300 .5 80 re 400 300 .5 80 re f "
+		cells = "BT /F1 12 Tf 110 355 Td (Name) Tj 100 0 Td (Qty) Tj 100 0 Td (Price) Tj ET " +
+			"BT /F1 12 Tf 110 315 Td (Apple pie) Tj 100 0 Td (2) Tj ET "
+		aligned = "BT /F1 12 Tf 72 200 Td (Name) Tj 128 0 Td (Qty) Tj 100 0 Td (Price) Tj ET " +
+			"BT /F1 12 Tf 72 186 Td (Apple) Tj 128 0 Td (2) Tj 100 0 Td (1.50) Tj ET " +
+			"BT /F1 12 Tf 72 172 Td (Pear) Tj 128 0 Td (10) Tj 100 0 Td (0.80) Tj ET " +
+			"BT /F1 12 Tf 72 120 Td (Some text below the table) Tj ET "
+	)
+
+	ruledRows := [][]string{{"Name", "Qty", "Price"}, {"Apple pie", "2", ""}}
+	alignedRows := [][]string{{"Name", "Qty", "Price"}, {"Apple", "2", "1.50"}, {"Pear", "10", "0.80"}}
+
+	for _, tt := range []struct {
+		s     string
+		want  [][][]string
+		ruled []bool
+	}{
+		{text, [][][]string{}, []bool{}},
+		{text + grid, [][][]string{}, []bool{}},
+		{text + grid + cells, [][][]string{ruledRows}, []bool{true}},
+		{text + thinRects + cells, [][][]string{ruledRows}, []bool{true}},
+		{text + aligned, [][][]string{alignedRows}, []bool{false}},
+		{grid + cells + aligned, [][][]string{ruledRows, alignedRows}, []bool{true, false}},
+	} {
+		tx := &tableExtractor{}
+		cb := &contentBoxer{fonts: map[int]*cbFont{}, forms: map[int]bool{}, glyph: tx.addGlyph, rule: tx.addRuling}
+		gs := cbGState{ctm: identMatrix, clip: Rect(0, 0, 500, 500), lw: 1, th: 1}
+
+		if err := cb.process([]byte(tt.s), nil, gs); err != nil {
+			t.Fatalf("%s: %v\n", tt.s, err)
+		}
+
+		ruled, rest := tx.ruledTables()
+		got, gotRuled := [][][]string{}, []bool{}
+		for _, t := range append(ruled, alignedTables(rest)...) {
+			got = append(got, t.Rows)
+			gotRuled = append(gotRuled, t.Ruled)
+		}
+
+		if !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(gotRuled, tt.ruled) {
+			t.Fatalf("%s: got %v %v, want %v %v\n", tt.s, got, gotRuled, tt.want, tt.ruled)
+		}
+	}
+}
@@ -0,0 +1,499 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Text extraction collects the glyphs shown on a page using the content stream interpreter of the content box
+// and groups them into words, lines and blocks:
+// Glyphs sharing writing direction and baseline form lines, which get split into words at white space and gaps.
+// Consecutive lines that are close and overlap horizontally form blocks.
+
+// Spacing thresholds relative to the font size.
+const (
+	lineTolerance = .5  // max baseline deviation of glyphs within a line.
+	wordSpacing   = .2  // min gap between words.
+	columnSpacing = 3   // min gap between lines of different columns sharing a baseline.
+	maxLeading    = 1.7 // max baseline distance of lines within a block.
+)
+
+// TextWord represents a word of extracted text.
+type TextWord struct {
+	Text string     `json:"text"`
+	BBox [4]float64 `json:"bbox"` // llx, lly, urx, ury in user space.
+}
+
+// TextLine represents a line of extracted text.
+type TextLine struct {
+	Text  string     `json:"text"`
+	BBox  [4]float64 `json:"bbox"`
+	Words []TextWord `json:"words"`
+}
+
+// TextBlock represents a block of extracted text like a paragraph or a column of a table.
+type TextBlock struct {
+	BBox  [4]float64 `json:"bbox"`
+	Role  string     `json:"role,omitempty"` // structure type of tagged content.
+	Lines []TextLine `json:"lines"`
+}
+
+// PageText represents the text extracted from a page.
+type PageText struct {
+	Page   int         `json:"page"`
+	Tagged bool        `json:"tagged"` // blocks are in logical order of the structure tree.
+	Blocks []TextBlock `json:"blocks"`
+}
+
+// String returns the plain text of pt with blocks separated by empty lines.
+func (pt PageText) String() string {
+	ss := make([]string, len(pt.Blocks))
+	for i, b := range pt.Blocks {
+		ll := make([]string, len(b.Lines))
+		for j, l := range b.Lines {
+			ll[j] = l.Text
+		}
+		ss[i] = strings.Join(ll, "\n")
+	}
+	return strings.Join(ss, "\n\n")
+}
+
+// TextGlyph represents a glyph shown on a page.
+type TextGlyph struct {
+	Text   string     `json:"text"`
+	BBox   [4]float64 `json:"bbox"`
+	Origin [2]float64 `json:"origin"` // start of the glyph on the baseline in user space.
+	Dir    int        `json:"dir"`    // writing direction in degrees.
+	Size   float64    `json:"size"`   // font size in user space.
+}
+
+// textGlyph is a glyph shown on a page.
+// The coordinates x0, x1 and y are taken along and across the writing direction dir.
+type textGlyph struct {
+	s      string
+	box    *Rectangle // user space.
+	org    Point      // user space.
+	dir    int        // writing direction in degrees.
+	x0, x1 float64
+	y      float64 // baseline.
+	size   float64 // font size in user space.
+	mcid   int     // marked content identifier.
+	lang   string  // language of the marked content.
+}
+
+type textLine struct {
+	glyphs  []*textGlyph
+	dir     int
+	x0, x1  float64
+	y, size float64
+	row     int // baseline cluster.
+}
+
+type textBlock struct {
+	lines  []*textLine
+	x0, x1 float64
+}
+
+type textExtractor struct {
+	glyphs []*textGlyph
+}
+
+func glyphText(f *cbFont, code []byte) string {
+	if f == nil || f.dec == nil {
+		// No font resource available.
+		if len(code) == 1 {
+			return string(rune(code[0]))
+		}
+		return string(rune(0xFFFD))
+	}
+	return f.dec.decodeCode(code)
+}
+
+// addGlyph records the glyph at the origin of gtm advancing by adv in glyph space.
+func (te *textExtractor) addGlyph(gs *cbGState, gtm matrix, adv Point, r *Rectangle, code []byte) {
+	if intersectRect(r, gs.clip) == nil {
+		// Clipped or outside the page.
+		return
+	}
+
+	s := glyphText(gs.font, code)
+	if s == "" {
+		return
+	}
+
+	p0 := gtm.transform(Point{0, 0})
+	p1 := gtm.transform(adv)
+
+	// The writing direction is horizontal unless the glyph advances vertically.
+	ux, uy := gtm[0][0], gtm[0][1]
+	across := gtm[1]
+	if adv.Y != 0 {
+		ux, uy = p1.X-p0.X, p1.Y-p0.Y
+		across = gtm[0]
+	}
+	l := math.Hypot(ux, uy)
+	if l == 0 {
+		return
+	}
+	ux, uy = ux/l, uy/l
+
+	dir := int(math.Round(math.Atan2(uy, ux) * 180 / math.Pi))
+	if dir == -180 {
+		dir = 180
+	}
+
+	te.glyphs = append(te.glyphs, &textGlyph{
+		s:    s,
+		box:  r,
+		org:  p0,
+		dir:  dir,
+		x0:   p0.X*ux + p0.Y*uy,
+		x1:   p1.X*ux + p1.Y*uy,
+		y:    p0.Y*ux - p0.X*uy,
+		size: gs.fs * math.Hypot(across[0], across[1]),
+		mcid: gs.mcid,
+		lang: gs.lang,
+	})
+}
+
+func isBlank(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+// lines clusters the glyphs by writing direction and baseline
+// and splits each cluster at column gaps.
+func (te *textExtractor) lines() []*textLine {
+	gg := te.glyphs
+
+	sort.SliceStable(gg, func(i, j int) bool {
+		if gg[i].dir != gg[j].dir {
+			return gg[i].dir < gg[j].dir
+		}
+		return gg[i].y > gg[j].y
+	})
+
+	var clusters [][]*textGlyph
+	for i := 0; i < len(gg); {
+		j := i + 1
+		for j < len(gg) && gg[j].dir == gg[i].dir && gg[i].y-gg[j].y <= lineTolerance*math.Max(gg[i].size, gg[j].size) {
+			j++
+		}
+		clusters = append(clusters, gg[i:j])
+		i = j
+	}
+
+	var ll []*textLine
+
+	for row, c := range clusters {
+		sort.SliceStable(c, func(i, j int) bool { return c[i].x0 < c[j].x0 })
+		var l *textLine
+		for _, g := range c {
+			if l != nil {
+				prev := l.glyphs[len(l.glyphs)-1]
+				if g.s == prev.s && math.Abs(g.x0-prev.x0) < .1*g.size {
+					// Overprinted glyph eg. for simulating bold text.
+					continue
+				}
+				if g.x0-l.x1 > columnSpacing*math.Max(g.size, l.size) {
+					l = nil
+				}
+			}
+			if l == nil {
+				l = &textLine{dir: g.dir, x0: g.x0, x1: g.x1, y: g.y, row: row}
+				ll = append(ll, l)
+			}
+			l.glyphs = append(l.glyphs, g)
+			l.x1 = math.Max(l.x1, g.x1)
+			l.size = math.Max(l.size, g.size)
+		}
+	}
+
+	for _, l := range ll {
+		// Trim leading and trailing white space.
+		for len(l.glyphs) > 0 && isBlank(l.glyphs[0].s) {
+			l.glyphs = l.glyphs[1:]
+		}
+		for len(l.glyphs) > 0 && isBlank(l.glyphs[len(l.glyphs)-1].s) {
+			l.glyphs = l.glyphs[:len(l.glyphs)-1]
+		}
+	}
+
+	return ll
+}
+
+// blocks groups lines into blocks in reading order.
+func blocks(ll []*textLine) []*textBlock {
+	sort.SliceStable(ll, func(i, j int) bool {
+		if ll[i].dir != ll[j].dir {
+			return ll[i].dir < ll[j].dir
+		}
+		if ll[i].y != ll[j].y {
+			return ll[i].y > ll[j].y
+		}
+		return ll[i].x0 < ll[j].x0
+	})
+
+	var bb []*textBlock
+
+	for _, l := range ll {
+		if len(l.glyphs) == 0 {
+			continue
+		}
+		var b *textBlock
+		for i := len(bb) - 1; i >= 0; i-- {
+			last := bb[i].lines[len(bb[i].lines)-1]
+			if last.dir != l.dir {
+				break
+			}
+			dy := last.y - l.y
+			if dy > 0 && dy <= maxLeading*math.Max(last.size, l.size) && l.x0 < bb[i].x1 && l.x1 > bb[i].x0 {
+				b = bb[i]
+				break
+			}
+		}
+		if b == nil {
+			b = &textBlock{x0: l.x0, x1: l.x1}
+			bb = append(bb, b)
+		}
+		b.lines = append(b.lines, l)
+		b.x0, b.x1 = math.Min(b.x0, l.x0), math.Max(b.x1, l.x1)
+	}
+
+	return bb
+}
+
+func roundCoord(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+func bboxArray(r *Rectangle) [4]float64 {
+	return [4]float64{roundCoord(r.LL.X), roundCoord(r.LL.Y), roundCoord(r.UR.X), roundCoord(r.UR.Y)}
+}
+
+// words splits l at white space and gaps wider than the word spacing threshold.
+// Words and their characters are returned in logical order.
+func (l *textLine) words() []TextWord {
+	var (
+		wgg  [][]*textGlyph // words in visual order.
+		gg   []*textGlyph
+		prev *textGlyph
+	)
+
+	flush := func() {
+		if len(gg) > 0 {
+			wgg = append(wgg, gg)
+		}
+		gg = nil
+	}
+
+	for _, g := range l.glyphs {
+		if isBlank(g.s) {
+			flush()
+			prev = g
+			continue
+		}
+		if prev != nil && g.x0-prev.x1 > wordSpacing*math.Max(g.size, prev.size) {
+			flush()
+		}
+		gg = append(gg, g)
+		prev = g
+	}
+	flush()
+
+	ww := make([]TextWord, len(wgg))
+	ss := make([][]string, len(wgg))
+	for i, gg := range wgg {
+		var box *Rectangle
+		ss[i] = make([]string, len(gg))
+		for j, g := range gg {
+			ss[i][j] = g.s
+			box = unionRect(box, g.box)
+		}
+		ww[i] = TextWord{Text: strings.Join(ss[i], ""), BBox: bboxArray(box)}
+	}
+
+	if base, ok := l.bidiBaseLevel(); ok {
+		ww = logicalWords(ww, ss, base)
+	}
+
+	return ww
+}
+
+func joinWords(ww []TextWord) string {
+	ss := make([]string, len(ww))
+	for i, w := range ww {
+		ss[i] = w.Text
+	}
+	return strings.Join(ss, " ")
+}
+
+func (l *textLine) text() string {
+	return joinWords(l.words())
+}
+
+func newTextBlock(ll []*textLine, role string) TextBlock {
+	tb := TextBlock{Role: role}
+	var box *Rectangle
+	for _, l := range ll {
+		var lbox *Rectangle
+		for _, g := range l.glyphs {
+			lbox = unionRect(lbox, g.box)
+		}
+		ww := l.words()
+		tb.Lines = append(tb.Lines, TextLine{Text: joinWords(ww), BBox: bboxArray(lbox), Words: ww})
+		box = unionRect(box, lbox)
+	}
+	tb.BBox = bboxArray(box)
+	return tb
+}
+
+// structuredBlocks returns a block for each run of marked content in sc shown on the page in logical order
+// and the glyphs not covered by sc.
+func (te *textExtractor) structuredBlocks(sc []*structContent) ([]TextBlock, []*textGlyph) {
+	m := map[int][]*textGlyph{}
+	for _, g := range te.glyphs {
+		if g.mcid >= 0 {
+			m[g.mcid] = append(m[g.mcid], g)
+		}
+	}
+
+	var tbb []TextBlock
+	used := map[int]bool{}
+
+	for _, c := range sc {
+		var gg []*textGlyph
+		for _, mcid := range c.mcids {
+			if !used[mcid] {
+				gg = append(gg, m[mcid]...)
+				used[mcid] = true
+			}
+		}
+		te1 := &textExtractor{glyphs: gg}
+		var ll []*textLine
+		for _, b := range blocks(te1.lines()) {
+			ll = append(ll, b.lines...)
+		}
+		if len(ll) > 0 {
+			tbb = append(tbb, newTextBlock(ll, c.role))
+		}
+	}
+
+	var rest []*textGlyph
+	for _, g := range te.glyphs {
+		if g.mcid < 0 || !used[g.mcid] {
+			rest = append(rest, g)
+		}
+	}
+
+	return tbb, rest
+}
+
+// pageText returns the text of the page in the logical order of sc, the runs of marked content of the structure tree.
+// Any remaining text as well as the text of untagged pages gets ordered geometrically.
+func (te *textExtractor) pageText(pageNr int, sc []*structContent) *PageText {
+	pt := &PageText{Page: pageNr, Blocks: []TextBlock{}}
+
+	if len(sc) > 0 {
+		tbb, rest := te.structuredBlocks(sc)
+		pt.Blocks = append(pt.Blocks, tbb...)
+		pt.Tagged = len(tbb) > 0
+		te = &textExtractor{glyphs: rest}
+	}
+
+	for _, b := range blocks(te.lines()) {
+		pt.Blocks = append(pt.Blocks, newTextBlock(b.lines, ""))
+	}
+
+	return pt
+}
+
+// processPage runs cb over the content of page pageNr.
+func (ctx *Context) processPage(pageNr int, cb *contentBoxer) error {
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, true)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.Errorf("pdfcpu: unknown page number: %d\n", pageNr)
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err != nil && err != errNoContent {
+		return err
+	}
+
+	vp := viewPort(inhPAttrs)
+	gs := cbGState{ctm: identMatrix, clip: Rect(vp.LL.X, vp.LL.Y, vp.UR.X, vp.UR.Y), lw: 1, th: 1, mcid: -1}
+
+	// The natural language of the document serves as a hint for the text direction.
+	if o, found := ctx.RootDict.Find("Lang"); found {
+		gs.lang, _ = ctx.DereferenceText(o)
+	}
+
+	return cb.process(bb, inhPAttrs.resources, gs)
+}
+
+// ExtractPageText extracts the text of page pageNr segmented into blocks, lines and words with their bounding boxes.
+// Tagged content is ordered by the structure tree, any other content by its position on the page.
+func (ctx *Context) ExtractPageText(pageNr int) (*PageText, error) {
+	te := &textExtractor{}
+	cb := &contentBoxer{ctx: ctx, fonts: map[int]*cbFont{}, forms: map[int]bool{}, glyph: te.addGlyph}
+
+	if err := ctx.processPage(pageNr, cb); err != nil {
+		return nil, err
+	}
+
+	ir, err := ctx.PageDictIndRef(pageNr)
+	if err != nil {
+		return nil, err
+	}
+
+	sc, err := ctx.pageStructContent(ir.ObjectNumber.Value())
+	if err != nil {
+		return nil, err
+	}
+
+	return te.pageText(pageNr, sc), nil
+}
+
+// ExtractPageGlyphs returns the glyphs shown on page pageNr in content stream order along with their positions.
+func (ctx *Context) ExtractPageGlyphs(pageNr int) ([]TextGlyph, error) {
+	te := &textExtractor{}
+	cb := &contentBoxer{ctx: ctx, fonts: map[int]*cbFont{}, forms: map[int]bool{}, glyph: te.addGlyph}
+
+	if err := ctx.processPage(pageNr, cb); err != nil {
+		return nil, err
+	}
+
+	gg := make([]TextGlyph, len(te.glyphs))
+	for i, g := range te.glyphs {
+		gg[i] = TextGlyph{
+			Text:   g.s,
+			BBox:   bboxArray(g.box),
+			Origin: [2]float64{roundCoord(g.org.X), roundCoord(g.org.Y)},
+			Dir:    g.dir,
+			Size:   roundCoord(g.size),
+		}
+	}
+
+	return gg, nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTextSegmentation(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want [][]string // lines per block
+	}{
+		{"", [][]string{}},
+		{"BT /F1 12 Tf 72 400 Td (Hello  World ) Tj ET", [][]string{{"Hello World"}}},
+		{"BT /F1 12 Tf 72 400 Td [(Hel) -100 (lo) -1000 (World)] TJ ET", [][]string{{"Hello World"}}},
+		{"BT /F1 12 Tf 72 400 Td (a) Tj 3 Ts (2) Tj 0 Ts (b) Tj ET", [][]string{{"a2b"}}},
+		{"BT /F1 12 Tf 3 Tr 72 400 Td (invisible) Tj 0 -1000 Td (clipped) Tj ET", [][]string{{"invisible"}}},
+		{
+			"BT /F1 12 Tf 72 400 Td (Hello World) Tj 0 -14 Td (second line) Tj ET " +
+				"BT /F1 12 Tf 72 300 Td (New) Tj 24 0 Td (block) Tj ET " +
+				"BT /F1 12 Tf 300 400 Td (Right) Tj ET " +
+				"BT /F1 12 Tf 0 1 -1 0 450 100 Tm (Up) Tj ET",
+			[][]string{{"Hello World", "second line"}, {"Right"}, {"New block"}, {"Up"}},
+		},
+	} {
+		te := &textExtractor{}
+		cb := &contentBoxer{fonts: map[int]*cbFont{}, forms: map[int]bool{}, glyph: te.addGlyph}
+		gs := cbGState{ctm: identMatrix, clip: Rect(0, 0, 500, 500), lw: 1, th: 1}
+
+		if err := cb.process([]byte(tt.s), nil, gs); err != nil {
+			t.Fatalf("%s: %v\n", tt.s, err)
+		}
+
+		got := [][]string{}
+		for _, b := range te.pageText(1, nil).Blocks {
+			ss := []string{}
+			for _, l := range b.Lines {
+				ss = append(ss, l.Text)
+			}
+			got = append(got, ss)
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%s: got %v, want %v\n", tt.s, got, tt.want)
+		}
+	}
+
+	// Word bounding boxes
+	te := &textExtractor{}
+	cb := &contentBoxer{fonts: map[int]*cbFont{}, forms: map[int]bool{}, glyph: te.addGlyph}
+	gs := cbGState{ctm: identMatrix, clip: Rect(0, 0, 500, 500), lw: 1, th: 1}
+	if err := cb.process([]byte("BT /F1 10 Tf 100 100 Td (ab cd) Tj ET"), nil, gs); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	want := []TextWord{{"ab", [4]float64{100, 97.5, 110, 107.5}}, {"cd", [4]float64{115, 97.5, 125, 107.5}}}
+	if got := te.pageText(1, nil).Blocks[0].Lines[0].Words; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v\n", got, want)
+	}
+}
+
+func TestTextDirection(t *testing.T) {
+	ucs2 := &TextDecoder{composite: true, cmap: decodeUCS2}
+	horizontal := &cbFont{composite: true, missing: 500, scale: .001, ascent: .75, descent: -.25, dec: ucs2}
+	vertical := &cbFont{composite: true, missing: 1000, scale: .001, ascent: .88, descent: -.12, vertical: true, vy: 880, w1: -1000, dec: ucs2}
+
+	for _, tt := range []struct {
+		f    *cbFont
+		lang string
+		s    string
+		want [][]string // lines per block
+	}{
+		// Hebrew shown in visual order: "םלוע םולש"
+		{horizontal, "", "BT 72 400 Td <05DD05DC05D505E2002005DD05D505DC05E9> Tj ET", [][]string{{"שלום עולם"}}},
+		// Numbers and Latin within right-to-left text: "abc 123 םולש"
+		{horizontal, "", "BT 72 400 Td <0061006200630020003100320033002005DD05D505DC05E9> Tj ET", [][]string{{"שלום 123 abc"}}},
+		// Hebrew within left-to-right text: "abcdef םולש"
+		{horizontal, "", "BT 72 400 Td <006100620063006400650066002005DD05D505DC05E9> Tj ET", [][]string{{"abcdef שלום"}}},
+		{horizontal, "he", "BT 72 400 Td <006100620063006400650066002005DD05D505DC05E9> Tj ET", [][]string{{"שלום abcdef"}}},
+		{horizontal, "", "/Span <</Lang (he-IL)>> BDC BT 72 400 Td <006100620063006400650066002005DD05D505DC05E9> Tj ET EMC", [][]string{{"שלום abcdef"}}},
+		// Vertical columns read from right to left.
+		{vertical, "", "BT 400 450 Td <65E5672C8A9E> Tj -14 0 Td <7E2666F8304D> Tj ET", [][]string{{"日本語", "縦書き"}}},
+		{vertical, "", "BT 400 450 Td [<65E5> 1000 <672C8A9E>] TJ ET", [][]string{{"日 本語"}}},
+	} {
+		te := &textExtractor{}
+		cb := &contentBoxer{fonts: map[int]*cbFont{}, forms: map[int]bool{}, glyph: te.addGlyph}
+		gs := cbGState{ctm: identMatrix, clip: Rect(0, 0, 500, 500), lw: 1, th: 1, mcid: -1, font: tt.f, fs: 12, lang: tt.lang}
+
+		if err := cb.process([]byte(tt.s), nil, gs); err != nil {
+			t.Fatalf("%s: %v\n", tt.s, err)
+		}
+
+		got := [][]string{}
+		for _, b := range te.pageText(1, nil).Blocks {
+			ss := []string{}
+			for _, l := range b.Lines {
+				ss = append(ss, l.Text)
+			}
+			got = append(got, ss)
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%s: got %v, want %v\n", tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FontTypeUsage counts the fonts of a specific type.
+type FontTypeUsage struct {
+	Type     string `json:"type"`
+	Count    int    `json:"count"`
+	Embedded int    `json:"embedded"`
+}
+
+// FeatureReport lists notable PDF features used by a document
+// in order to anticipate viewer compatibility.
+type FeatureReport struct {
+	Version         string          `json:"version"`
+	RequiredVersion string          `json:"requiredVersion"`
+	Encryption      string          `json:"encryption,omitempty"` // Algorithm in use, empty if unencrypted.
+	Linearized      bool            `json:"linearized"`
+	XRefStreams     bool            `json:"xrefStreams"`
+	ObjectStreams   bool            `json:"objectStreams"`
+	Transparency    bool            `json:"transparency"`
+	Layers          int             `json:"layers"` // Optional content groups.
+	JavaScript      bool            `json:"javaScript"`
+	EmbeddedFiles   int             `json:"embeddedFiles"`
+	Multimedia      []string        `json:"multimedia,omitempty"` // Multimedia annotation and action types.
+	Forms           bool            `json:"forms"`
+	XFA             bool            `json:"xfa"`
+	Signatures      int             `json:"signatures"`
+	Tagged          bool            `json:"tagged"`
+	Fonts           []FontTypeUsage `json:"fonts,omitempty"`
+}
+
+var multimediaTypes = map[string]bool{
+	// Annotation types
+	"Sound":     true,
+	"Movie":     true,
+	"Screen":    true,
+	"RichMedia": true,
+	"3D":        true,
+	// Action types
+	"Rendition": true,
+}
+
+// featureScan collects the features of a document while walking its objects.
+type featureScan struct {
+	xRefTable  *XRefTable
+	r          *FeatureReport
+	multimedia map[string]bool
+	fonts      map[string]*FontTypeUsage
+}
+
+func (fs *featureScan) fontEmbedded(d Dict) bool {
+	fd, err := fs.xRefTable.DereferenceDict(d["FontDescriptor"])
+	if err != nil || fd == nil {
+		return false
+	}
+	for _, k := range []string{"FontFile", "FontFile2", "FontFile3"} {
+		if _, found := fd.Find(k); found {
+			return true
+		}
+	}
+	return false
+}
+
+func (fs *featureScan) addFont(d Dict, st string) {
+	embedded := fs.fontEmbedded(d)
+
+	if st == "Type0" {
+		// The descendant font tells about embedding.
+		if a, err := fs.xRefTable.DereferenceArray(d["DescendantFonts"]); err == nil && len(a) > 0 {
+			if df, err := fs.xRefTable.DereferenceDict(a[0]); err == nil && df != nil {
+				embedded = fs.fontEmbedded(df)
+			}
+		}
+	}
+
+	if st == "Type3" {
+		// Glyphs are defined by content streams.
+		embedded = true
+	}
+
+	fu, ok := fs.fonts[st]
+	if !ok {
+		fu = &FontTypeUsage{Type: st}
+		fs.fonts[st] = fu
+	}
+	fu.Count++
+	if embedded {
+		fu.Embedded++
+	}
+}
+
+func (fs *featureScan) transparentGState(d Dict) bool {
+	if sm, found := d.Find("SMask"); found {
+		if n, ok := sm.(Name); !ok || n != "None" {
+			return true
+		}
+	}
+	for _, k := range []string{"CA", "ca"} {
+		switch f := d[k].(type) {
+		case Float:
+			if f.Value() < 1 {
+				return true
+			}
+		case Integer:
+			if f.Value() < 1 {
+				return true
+			}
+		}
+	}
+	if bm := d.NameEntry("BM"); bm != nil && *bm != "Normal" && *bm != "Compatible" {
+		return true
+	}
+	return false
+}
+
+func (fs *featureScan) addDict(d Dict) {
+	r := fs.r
+
+	var t, st string
+	if n := d.Type(); n != nil {
+		t = *n
+	}
+	if n := d.Subtype(); n != nil {
+		st = *n
+	}
+
+	switch t {
+	case "Font":
+		if st != "CIDFontType0" && st != "CIDFontType2" {
+			fs.addFont(d, st)
+		}
+	case "OCG":
+		r.Layers++
+	case "EmbeddedFile":
+		r.EmbeddedFiles++
+	case "ExtGState":
+		if fs.transparentGState(d) {
+			r.Transparency = true
+		}
+	case "Sig":
+		r.Signatures++
+	}
+
+	if t == "" || t == "Annot" {
+		if multimediaTypes[st] {
+			if _, found := d.Find("Rect"); found {
+				fs.multimedia[st] = true
+			}
+		}
+	}
+
+	if s := d.NameEntry("S"); s != nil {
+		switch *s {
+		case "JavaScript":
+			r.JavaScript = true
+		case "Transparency":
+			r.Transparency = true
+		case "Rendition", "Sound", "Movie":
+			fs.multimedia[*s] = true
+		}
+	}
+
+	if st == "Image" {
+		if _, found := d.Find("SMask"); found {
+			r.Transparency = true
+		}
+	}
+}
+
+// walk scans o and all its direct sub objects.
+func (fs *featureScan) walk(o Object) {
+	switch o := o.(type) {
+	case Dict:
+		fs.addDict(o)
+		for _, v := range o {
+			fs.walk(v)
+		}
+	case StreamDict:
+		fs.addDict(o.Dict)
+		for _, v := range o.Dict {
+			fs.walk(v)
+		}
+	case Array:
+		for _, v := range o {
+			fs.walk(v)
+		}
+	}
+}
+
+func (fs *featureScan) addCatalog(rootDict Dict) error {
+	r := fs.r
+
+	if o, found := rootDict.Find("Names"); found {
+		d, err := fs.xRefTable.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		if _, found := d.Find("JavaScript"); found {
+			r.JavaScript = true
+		}
+	}
+
+	if o, found := rootDict.Find("AcroForm"); found {
+		d, err := fs.xRefTable.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		if d != nil {
+			if a, err := fs.xRefTable.DereferenceArray(d["Fields"]); err == nil && len(a) > 0 {
+				r.Forms = true
+			}
+			if _, found := d.Find("XFA"); found {
+				r.XFA = true
+			}
+		}
+	}
+
+	if o, found := rootDict.Find("MarkInfo"); found {
+		d, err := fs.xRefTable.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		if d != nil {
+			if b := d.BooleanEntry("Marked"); b != nil && *b {
+				r.Tagged = true
+			}
+		}
+	}
+
+	return nil
+}
+
+// Features returns the notable PDF features used by ctx.
+func (ctx *Context) Features() (*FeatureReport, error) {
+	r := &FeatureReport{
+		Version: ctx.VersionString(),
+	}
+
+	if ctx.Read != nil {
+		r.Linearized = ctx.Read.Linearized
+		r.XRefStreams = ctx.Read.UsingXRefStreams
+		r.ObjectStreams = ctx.Read.UsingObjectStreams
+	}
+
+	if ctx.Encrypt != nil {
+		ei, err := EncryptionDetails(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r.Encryption = ei.Algorithm
+	}
+
+	v, _, err := ctx.RequiredVersion()
+	if err != nil {
+		return nil, err
+	}
+	r.RequiredVersion = v.String()
+
+	fs := &featureScan{
+		xRefTable:  ctx.XRefTable,
+		r:          r,
+		multimedia: map[string]bool{},
+		fonts:      map[string]*FontTypeUsage{},
+	}
+
+	for objNr, e := range ctx.Table {
+		if objNr == 0 || e == nil || e.Free || e.Object == nil {
+			continue
+		}
+		if ctx.Encrypt != nil && objNr == ctx.Encrypt.ObjectNumber.Value() {
+			continue
+		}
+		fs.walk(e.Object)
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	if err := fs.addCatalog(rootDict); err != nil {
+		return nil, err
+	}
+
+	for k := range fs.multimedia {
+		r.Multimedia = append(r.Multimedia, k)
+	}
+	sort.Strings(r.Multimedia)
+
+	for _, fu := range fs.fonts {
+		r.Fonts = append(r.Fonts, *fu)
+	}
+	sort.Slice(r.Fonts, func(i, j int) bool { return r.Fonts[i].Type < r.Fonts[j].Type })
+
+	return r, nil
+}
+
+// List returns a human readable representation of r.
+func (r FeatureReport) List() []string {
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+
+	encryption := "no"
+	if r.Encryption != "" {
+		encryption = r.Encryption
+	}
+
+	multimedia := "no"
+	if len(r.Multimedia) > 0 {
+		multimedia = strings.Join(r.Multimedia, ", ")
+	}
+
+	forms := yesNo(r.Forms)
+	if r.XFA {
+		forms += " (XFA)"
+	}
+
+	ss := []string{
+		fmt.Sprintf("%20s: %s", "PDF version", r.Version),
+		fmt.Sprintf("%20s: %s", "Required version", r.RequiredVersion),
+		fmt.Sprintf("%20s: %s", "Encryption", encryption),
+		fmt.Sprintf("%20s: %s", "Linearized", yesNo(r.Linearized)),
+		fmt.Sprintf("%20s: %s", "XRef streams", yesNo(r.XRefStreams)),
+		fmt.Sprintf("%20s: %s", "Object streams", yesNo(r.ObjectStreams)),
+		fmt.Sprintf("%20s: %s", "Transparency", yesNo(r.Transparency)),
+		fmt.Sprintf("%20s: %d", "Layers", r.Layers),
+		fmt.Sprintf("%20s: %s", "JavaScript", yesNo(r.JavaScript)),
+		fmt.Sprintf("%20s: %d", "Embedded files", r.EmbeddedFiles),
+		fmt.Sprintf("%20s: %s", "Multimedia", multimedia),
+		fmt.Sprintf("%20s: %s", "Forms", forms),
+		fmt.Sprintf("%20s: %d", "Signatures", r.Signatures),
+		fmt.Sprintf("%20s: %s", "Tagged", yesNo(r.Tagged)),
+	}
+
+	if len(r.Fonts) == 0 {
+		return append(ss, fmt.Sprintf("%20s: none", "Fonts"))
+	}
+
+	for i, fu := range r.Fonts {
+		label := ""
+		if i == 0 {
+			label = "Fonts"
+		}
+		ss = append(ss, fmt.Sprintf("%20s: %s %d (%d embedded)", label, fu.Type, fu.Count, fu.Embedded))
+	}
+
+	return ss
+}
@@ -19,9 +19,8 @@ package pdfcpu
 import (
 	"bytes"
 	"fmt"
-	"math/rand"
+	"io"
 	"sort"
-	"time"
 	"unicode/utf16"
 
 	"github.com/pdfcpu/pdfcpu/pkg/font"
@@ -292,17 +291,20 @@ end`
 	return flateEncodedStreamIndRef(xRefTable, b.Bytes())
 }
 
-func subFontPrefix() string {
+func subFontPrefix(rnd io.Reader) (string, error) {
 	s := "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	var r *rand.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
 	bb := make([]byte, 6)
-	for i := range bb {
-		bb[i] = s[r.Intn(len(s))]
+	if _, err := io.ReadFull(rnd, bb); err != nil {
+		return "", err
 	}
-	return string(bb)
+	for i, b := range bb {
+		bb[i] = s[int(b)%len(s)]
+	}
+	return string(bb), nil
 }
 
-func type0FontDict(xRefTable *XRefTable, fontName string) (*IndirectRef, error) {
+// newType0FontDict returns a font dict embedding the subset of fontName made up of all glyphs used so far.
+func newType0FontDict(xRefTable *XRefTable, fontName string) (Dict, error) {
 	// Combines a CIDFont and a CMap to produce a font whose glyphs may be accessed
 	// by means of variable-length character codes in a string to be shown.
 	ttf, ok := font.UserFontMetrics[fontName]
@@ -310,7 +312,11 @@ func type0FontDict(xRefTable *XRefTable, fontName string) (*IndirectRef, error)
 		return nil, errors.Errorf("pdfcpu: font %s not available", fontName)
 	}
 
-	baseFontName := subFontPrefix() + "-" + fontName
+	prefix, err := subFontPrefix(xRefTable.Rand())
+	if err != nil {
+		return nil, err
+	}
+	baseFontName := prefix + "-" + fontName
 
 	descendentFontIndRef, err := CIDFontDict(xRefTable, ttf, fontName, baseFontName)
 	if err != nil {
@@ -333,6 +339,14 @@ func type0FontDict(xRefTable *XRefTable, fontName string) (*IndirectRef, error)
 	d.Insert("DescendantFonts", Array{*descendentFontIndRef})
 	d.Insert("ToUnicode", *toUnicodeIndRef)
 
+	return d, nil
+}
+
+func type0FontDict(xRefTable *XRefTable, fontName string) (*IndirectRef, error) {
+	d, err := newType0FontDict(xRefTable, fontName)
+	if err != nil {
+		return nil, err
+	}
 	return xRefTable.IndRefForNewObject(d)
 }
 
@@ -0,0 +1,708 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pkg/errors"
+)
+
+// Field flags, see 12.7.3.1 Table 221, 12.7.4.2.1 Table 226, 12.7.4.3 Table 228 and 12.7.4.4 Table 230.
+const (
+	fieldReadOnly    = 1
+	fieldMultiline   = 1 << 12
+	fieldRadio       = 1 << 15
+	fieldPushbutton  = 1 << 16
+	fieldCombo       = 1 << 17
+	fieldEdit        = 1 << 18
+	fieldMultiSelect = 1 << 21
+)
+
+// Form field types.
+const (
+	FieldText      = "text"
+	FieldCheckBox  = "checkbox"
+	FieldRadio     = "radio"
+	FieldComboBox  = "combobox"
+	FieldListBox   = "listbox"
+	FieldButton    = "button"
+	FieldSignature = "signature"
+)
+
+// FormField represents a terminal field of an interactive form.
+type FormField struct {
+	Name     string   `json:"name"`              // Fully qualified field name.
+	Type     string   `json:"type"`              // text, checkbox, radio, combobox, listbox, button or signature.
+	Value    string   `json:"value"`             // Text, selected option or button state, "Off" for unchecked buttons.
+	Values   []string `json:"values,omitempty"`  // Selected options of multi select list boxes.
+	Options  []string `json:"options,omitempty"` // Available options of choice fields or on states of buttons.
+	ReadOnly bool     `json:"readOnly,omitempty"`
+}
+
+func (f FormField) String() string {
+	v := f.Value
+	if len(f.Values) > 0 {
+		v = strings.Join(f.Values, ", ")
+	}
+	s := fmt.Sprintf("%-9s %s = %q", f.Type, f.Name, v)
+	if len(f.Options) > 0 {
+		s += " [" + strings.Join(f.Options, ", ") + "]"
+	}
+	if f.ReadOnly {
+		s += " (read only)"
+	}
+	return s
+}
+
+// FormData is the JSON representation of form fields used for exporting and filling in forms.
+type FormData struct {
+	Fields []FormField `json:"fields"`
+}
+
+// formField is a terminal field along with its widget annotations.
+type formField struct {
+	name    string
+	d       Dict
+	widgets []Dict
+}
+
+// inheritedEntry returns the value of the inheritable field attribute key.
+func (ctx *Context) inheritedEntry(d Dict, key string) (Object, error) {
+	visited := IntSet{}
+	for d != nil {
+		if o, found := d.Find(key); found {
+			return ctx.Dereference(o)
+		}
+		ir := d.IndirectRefEntry("Parent")
+		if ir == nil || visited[ir.ObjectNumber.Value()] {
+			return nil, nil
+		}
+		visited[ir.ObjectNumber.Value()] = true
+		var err error
+		if d, err = ctx.DereferenceDict(*ir); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (ctx *Context) inheritedInt(d Dict, key string) (int, error) {
+	o, err := ctx.inheritedEntry(d, key)
+	if err != nil {
+		return 0, err
+	}
+	if i, ok := o.(Integer); ok {
+		return i.Value(), nil
+	}
+	return 0, nil
+}
+
+// collectFields appends the terminal fields rooted at fields to ff.
+func (ctx *Context) collectFields(fields Array, ff *[]*formField, visited IntSet) error {
+	for _, o := range fields {
+		ir, ok := o.(IndirectRef)
+		if !ok || visited[ir.ObjectNumber.Value()] {
+			continue
+		}
+		visited[ir.ObjectNumber.Value()] = true
+
+		d, err := ctx.DereferenceDict(ir)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			continue
+		}
+
+		kids, err := ctx.DereferenceArray(d["Kids"])
+		if err != nil {
+			return err
+		}
+
+		// Kids are either fields or widgets.
+		var subFields Array
+		var widgets []Dict
+		for _, o := range kids {
+			kid, err := ctx.DereferenceDict(o)
+			if err != nil {
+				return err
+			}
+			if kid == nil {
+				continue
+			}
+			if _, found := kid.Find("T"); found {
+				subFields = append(subFields, o)
+				continue
+			}
+			widgets = append(widgets, kid)
+		}
+
+		if len(subFields) > 0 {
+			if err := ctx.collectFields(subFields, ff, visited); err != nil {
+				return err
+			}
+		}
+
+		if len(subFields) > 0 && len(widgets) == 0 {
+			continue
+		}
+
+		if len(kids) == 0 {
+			// Terminal field merged with its widget.
+			widgets = []Dict{d}
+		}
+
+		name, err := ctx.fieldName(d)
+		if err != nil {
+			return err
+		}
+
+		*ff = append(*ff, &formField{name: name, d: d, widgets: widgets})
+	}
+
+	return nil
+}
+
+func (ctx *Context) formFields() ([]*formField, error) {
+	d, err := ctx.acroForm()
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	fields, err := ctx.DereferenceArray(d["Fields"])
+	if err != nil {
+		return nil, err
+	}
+
+	ff := []*formField{}
+	if err := ctx.collectFields(fields, &ff, IntSet{}); err != nil {
+		return nil, err
+	}
+
+	return ff, nil
+}
+
+func fieldType(ft string, flags int) string {
+	switch ft {
+	case "Tx":
+		return FieldText
+	case "Btn":
+		if flags&fieldPushbutton > 0 {
+			return FieldButton
+		}
+		if flags&fieldRadio > 0 {
+			return FieldRadio
+		}
+		return FieldCheckBox
+	case "Ch":
+		if flags&fieldCombo > 0 {
+			return FieldComboBox
+		}
+		return FieldListBox
+	case "Sig":
+		return FieldSignature
+	}
+	return ""
+}
+
+// onStates returns the appearance states other than Off of the widgets of f.
+func (ctx *Context) onStates(f *formField) ([]string, error) {
+	ss := []string{}
+	m := map[string]bool{}
+	for _, w := range f.widgets {
+		ap, err := ctx.DereferenceDict(w["AP"])
+		if err != nil || ap == nil {
+			continue
+		}
+		d, err := ctx.DereferenceDict(ap["N"])
+		if err != nil || d == nil {
+			continue
+		}
+		keys := make([]string, 0, len(d))
+		for k := range d {
+			if k != "Off" && !m[k] {
+				keys = append(keys, k)
+				m[k] = true
+			}
+		}
+		sort.Strings(keys)
+		ss = append(ss, keys...)
+	}
+	return ss, nil
+}
+
+// choiceOptions returns the export values of the options of the choice field d.
+func (ctx *Context) choiceOptions(d Dict) ([]string, error) {
+	o, err := ctx.inheritedEntry(d, "Opt")
+	if err != nil {
+		return nil, err
+	}
+	a, _ := o.(Array)
+
+	ss := []string{}
+	for _, o := range a {
+		o, err := ctx.Dereference(o)
+		if err != nil {
+			return nil, err
+		}
+		if a1, ok := o.(Array); ok && len(a1) > 0 {
+			o = a1[0]
+		}
+		s, err := ctx.DereferenceText(o)
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, s)
+	}
+	return ss, nil
+}
+
+func (ctx *Context) formFieldFor(f *formField) (*FormField, error) {
+	ft, err := ctx.inheritedEntry(f.d, "FT")
+	if err != nil {
+		return nil, err
+	}
+	n, _ := ft.(Name)
+
+	flags, err := ctx.inheritedInt(f.d, "Ff")
+	if err != nil {
+		return nil, err
+	}
+
+	ff := &FormField{
+		Name:     f.name,
+		Type:     fieldType(n.Value(), flags),
+		ReadOnly: flags&fieldReadOnly > 0,
+	}
+
+	v, err := ctx.inheritedEntry(f.d, "V")
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case Name:
+		ff.Value = v.Value()
+	case StringLiteral, HexLiteral:
+		if ff.Value, err = Text(v); err != nil {
+			return nil, err
+		}
+	case Array:
+		for _, o := range v {
+			s, err := ctx.DereferenceText(o)
+			if err != nil {
+				return nil, err
+			}
+			ff.Values = append(ff.Values, s)
+		}
+	}
+
+	switch ff.Type {
+	case FieldCheckBox, FieldRadio:
+		if ff.Value == "" {
+			ff.Value = "Off"
+		}
+		ff.Options, err = ctx.onStates(f)
+	case FieldComboBox, FieldListBox:
+		ff.Options, err = ctx.choiceOptions(f.d)
+	}
+
+	return ff, err
+}
+
+// FormFields returns the terminal fields of the interactive form of ctx.
+func (ctx *Context) FormFields() ([]FormField, error) {
+	ff, err := ctx.formFields()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []FormField{}
+	for _, f := range ff {
+		field, err := ctx.formFieldFor(f)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, *field)
+	}
+
+	return fields, nil
+}
+
+// SignatureField is a signed signature field of the interactive form.
+type SignatureField struct {
+	Name string // Fully qualified field name.
+	V    Dict   // Signature dict.
+}
+
+// SignatureFields returns all signature fields of ctx holding a signature.
+func (ctx *Context) SignatureFields() ([]SignatureField, error) {
+	ff, err := ctx.formFields()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []SignatureField{}
+	for _, f := range ff {
+		ft, err := ctx.inheritedEntry(f.d, "FT")
+		if err != nil {
+			return nil, err
+		}
+		if n, ok := ft.(Name); !ok || n.Value() != "Sig" {
+			continue
+		}
+		v, err := ctx.inheritedEntry(f.d, "V")
+		if err != nil {
+			return nil, err
+		}
+		d, ok := v.(Dict)
+		if !ok {
+			// Unsigned signature field.
+			continue
+		}
+		fields = append(fields, SignatureField{Name: f.name, V: d})
+	}
+
+	return fields, nil
+}
+
+// defaultAppearance returns the font resource name, font size and the remaining operators of da.
+func defaultAppearance(da string) (string, float64, string) {
+	fontRes, fontSize := "Helv", 0.
+	var ops []string
+	tt := strings.Fields(da)
+	for i := 0; i < len(tt); i++ {
+		if tt[i] == "Tf" && len(ops) >= 2 {
+			fontRes = strings.TrimPrefix(tt[i-2], "/")
+			fontSize, _ = strconv.ParseFloat(tt[i-1], 64)
+			ops = ops[:len(ops)-2]
+			continue
+		}
+		ops = append(ops, tt[i])
+	}
+	return fontRes, fontSize, strings.Join(ops, " ")
+}
+
+// coreFontName returns the name of the core font fontRes of resDict refers to.
+func (ctx *Context) coreFontName(resDict Dict, fontRes string) string {
+	fontName := "Helvetica"
+	d, err := ctx.DereferenceDict(resDict["Font"])
+	if err != nil || d == nil {
+		return fontName
+	}
+	fd, err := ctx.DereferenceDict(d[fontRes])
+	if err != nil || fd == nil {
+		return fontName
+	}
+	if bf := fd.NameEntry("BaseFont"); bf != nil && font.IsCoreFont(*bf) {
+		fontName = *bf
+	}
+	return fontName
+}
+
+// textAppearance creates the normal appearance of widget w of the text or combo box field f displaying s.
+func (ctx *Context) textAppearance(f *formField, w Dict, acroForm Dict, s string) error {
+	a, err := ctx.DereferenceArray(w["Rect"])
+	if err != nil {
+		return err
+	}
+	if len(a) != 4 {
+		return errors.Errorf("pdfcpu: field %s: invalid widget rect", f.name)
+	}
+	r, err := rect(ctx.XRefTable, a)
+	if err != nil {
+		return err
+	}
+	width, height := math.Abs(r.Width()), math.Abs(r.Height())
+
+	o, err := ctx.inheritedEntry(f.d, "DA")
+	if err != nil {
+		return err
+	}
+	if o == nil {
+		o = acroForm["DA"]
+	}
+	da, _ := ctx.DereferenceText(o)
+	fontRes, fontSize, ops := defaultAppearance(da)
+
+	resDict, err := ctx.DereferenceDict(acroForm["DR"])
+	if err != nil {
+		return err
+	}
+	if d, err := ctx.inheritedEntry(f.d, "DR"); err == nil && d != nil {
+		resDict, _ = d.(Dict)
+	}
+	fontName := ctx.coreFontName(resDict, fontRes)
+
+	flags, err := ctx.inheritedInt(f.d, "Ff")
+	if err != nil {
+		return err
+	}
+	o, err = ctx.inheritedEntry(f.d, "Q")
+	if err != nil {
+		return err
+	}
+	if o == nil {
+		o = acroForm["Q"]
+	}
+	q, _ := o.(Integer)
+
+	lines := []string{s}
+	if flags&fieldMultiline > 0 {
+		lines = strings.Split(s, "\n")
+	}
+
+	if fontSize <= 0 {
+		// Auto sized text.
+		fontSize = 12
+		if flags&fieldMultiline == 0 {
+			fontSize = math.Min(fontSize, (height-4)/1.2)
+			if tw := font.TextWidth(s, fontName, 1000) / 1000; tw > 0 {
+				fontSize = math.Min(fontSize, (width-4)/tw)
+			}
+		}
+		fontSize = math.Max(fontSize, 4)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "/Tx BMC q 1 1 %.2f %.2f re W n BT /%s %.2f Tf %s ", width-2, height-2, fontRes, fontSize, ops)
+
+	lead := fontSize * 1.2
+	y := (height-fontSize)/2 + fontSize*0.22
+	if flags&fieldMultiline > 0 {
+		y = height - 2 - fontSize
+	}
+
+	for i, l := range lines {
+		bb := decodeUTF8ToByte(l)
+		x := 2.
+		if q > 0 {
+			tw := font.TextWidth(bb, fontName, int(math.Round(fontSize)))
+			x = width - 2 - tw
+			if q == 1 {
+				x = (width - tw) / 2
+			}
+		}
+		esc, err := Escape(bb)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "1 0 0 1 %.2f %.2f Tm (%s) Tj ", x, y-float64(i)*lead, *esc)
+	}
+
+	b.WriteString("ET Q EMC")
+
+	sd, _ := ctx.NewStreamDictForBuf(b.Bytes())
+	sd.InsertName("Type", "XObject")
+	sd.InsertName("Subtype", "Form")
+	sd.Insert("BBox", NewNumberArray(0, 0, width, height))
+	if resDict != nil {
+		sd.Insert("Resources", resDict)
+	}
+	if err := sd.Encode(); err != nil {
+		return err
+	}
+
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		return err
+	}
+
+	w["AP"] = Dict(map[string]Object{"N": *ir})
+
+	return nil
+}
+
+func (ctx *Context) fillButton(f *formField, v string) error {
+	onStates, err := ctx.onStates(f)
+	if err != nil {
+		return err
+	}
+
+	state := v
+	if state == "" {
+		state = "Off"
+	}
+
+	if state != "Off" && !MemberOf(state, onStates) {
+		if len(onStates) != 1 || !MemberOf(strings.ToLower(state), []string{"true", "on", "yes", "1"}) {
+			return errors.Errorf("pdfcpu: field %s: invalid state %s, want one of Off, %s", f.name, v, strings.Join(onStates, ", "))
+		}
+		state = onStates[0]
+	}
+
+	f.d["V"] = Name(state)
+
+	for _, w := range f.widgets {
+		as := "Off"
+		if ap, _ := ctx.DereferenceDict(w["AP"]); ap != nil {
+			if d, _ := ctx.DereferenceDict(ap["N"]); d != nil {
+				if _, found := d.Find(state); found {
+					as = state
+				}
+			}
+		}
+		w["AS"] = Name(as)
+	}
+
+	return nil
+}
+
+func (ctx *Context) fillListBox(f *formField, field, value FormField) error {
+	vv := value.Values
+	if len(vv) == 0 && value.Value != "" {
+		vv = []string{value.Value}
+	}
+
+	flags, err := ctx.inheritedInt(f.d, "Ff")
+	if err != nil {
+		return err
+	}
+	if len(vv) > 1 && flags&fieldMultiSelect == 0 {
+		return errors.Errorf("pdfcpu: field %s: multiple selection not allowed", f.name)
+	}
+
+	a := Array{}
+	for _, s := range vv {
+		if len(field.Options) > 0 && !MemberOf(s, field.Options) {
+			return errors.Errorf("pdfcpu: field %s: invalid option: %s", f.name, s)
+		}
+		sl, err := textLiteral(s)
+		if err != nil {
+			return err
+		}
+		a = append(a, sl)
+	}
+
+	switch len(a) {
+	case 0:
+		delete(f.d, "V")
+	case 1:
+		f.d["V"] = a[0]
+	default:
+		f.d["V"] = a
+	}
+
+	// Leave the list box appearance to the viewer.
+	for _, w := range f.widgets {
+		delete(w, "AP")
+	}
+
+	return nil
+}
+
+// fillField sets the value of f to value.
+func (ctx *Context) fillField(f *formField, value FormField, acroForm Dict, needAppearances bool) error {
+	field, err := ctx.formFieldFor(f)
+	if err != nil {
+		return err
+	}
+
+	if field.ReadOnly {
+		return errors.Errorf("pdfcpu: field %s is read only", f.name)
+	}
+
+	switch field.Type {
+
+	case FieldCheckBox, FieldRadio:
+		return ctx.fillButton(f, value.Value)
+
+	case FieldListBox:
+		return ctx.fillListBox(f, *field, value)
+
+	case FieldText, FieldComboBox:
+		if field.Type == FieldComboBox && len(field.Options) > 0 && !MemberOf(value.Value, field.Options) {
+			flags, err := ctx.inheritedInt(f.d, "Ff")
+			if err != nil {
+				return err
+			}
+			if flags&fieldEdit == 0 {
+				return errors.Errorf("pdfcpu: field %s: invalid option: %s", f.name, value.Value)
+			}
+		}
+
+		sl, err := textLiteral(value.Value)
+		if err != nil {
+			return err
+		}
+		f.d["V"] = sl
+
+		for _, w := range f.widgets {
+			if needAppearances {
+				delete(w, "AP")
+				continue
+			}
+			if err := ctx.textAppearance(f, w, acroForm, value.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return errors.Errorf("pdfcpu: field %s: unable to fill %s fields", f.name, field.Type)
+}
+
+// FillFormFields sets the values of the form fields identified by the names of fields.
+// Unless needAppearances is set the appearance streams of text fields and combo boxes get regenerated,
+// otherwise they are removed and viewers are asked to regenerate them via NeedAppearances.
+// Any XFA form data gets removed since it would take precedence over the filled in fields.
+func (ctx *Context) FillFormFields(fields []FormField, needAppearances bool) error {
+	acroForm, err := ctx.acroForm()
+	if err != nil {
+		return err
+	}
+	if acroForm == nil {
+		return errors.New("pdfcpu: no form available")
+	}
+
+	ff, err := ctx.formFields()
+	if err != nil {
+		return err
+	}
+
+	m := map[string]*formField{}
+	for _, f := range ff {
+		if _, found := m[f.name]; !found {
+			m[f.name] = f
+		}
+	}
+
+	for _, field := range fields {
+		f, found := m[field.Name]
+		if !found {
+			return errors.Errorf("pdfcpu: unknown field: %s", field.Name)
+		}
+		if err := ctx.fillField(f, field, acroForm, needAppearances); err != nil {
+			return err
+		}
+	}
+
+	if needAppearances {
+		acroForm["NeedAppearances"] = Boolean(true)
+	}
+
+	delete(acroForm, "XFA")
+
+	return nil
+}
@@ -0,0 +1,358 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Transform is a transformation matrix [a b c d e f] mapping form space into page space, see 8.3.4.
+type Transform [6]float64
+
+// IdentityTransform places a form xobject at the origin of the page.
+var IdentityTransform = Transform{1, 0, 0, 1, 0, 0}
+
+// NewTransform returns a transform scaling by sx, sy, rotating counterclockwise by rot degrees
+// and finally translating by dx, dy.
+func NewTransform(sx, sy, rot, dx, dy float64) Transform {
+	sin := math.Sin(rot * float64(degToRad))
+	cos := math.Cos(rot * float64(degToRad))
+	m := calcTransformMatrix(sx, sy, sin, cos, dx, dy)
+	return Transform{m[0][0], m[0][1], m[1][0], m[1][1], m[2][0], m[2][1]}
+}
+
+// FormXObject is a page or a region of a page captured as a reusable Form XObject.
+// A FormXObject may be placed onto pages of any open document.
+type FormXObject struct {
+	ctx       *Context   // Source document.
+	PageNr    int        // Source page.
+	BBox      *Rectangle // Captured region in default user space of the source page.
+	content   []byte
+	resources Dict
+	forms     map[*Context]*IndirectRef // Form XObject per destination document.
+	migrated  map[*Context]map[int]int  // Migrated objects per destination document.
+}
+
+// Width returns the width of the captured region.
+func (fx FormXObject) Width() float64 {
+	return fx.BBox.Width()
+}
+
+// Height returns the height of the captured region.
+func (fx FormXObject) Height() float64 {
+	return fx.BBox.Height()
+}
+
+// ExtractFormXObject returns page pageNr of ctx as a reusable Form XObject.
+// If region is not nil only this region of the page is captured.
+// region is relative to the lower left corner of the visible page area taking any page rotation into account.
+func (ctx *Context) ExtractFormXObject(pageNr int, region *Rectangle) (*FormXObject, error) {
+
+	consolidateRes := true
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, consolidateRes)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.Errorf("pdfcpu: unknown page number: %d\n", pageNr)
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err != nil && err != errNoContent {
+		return nil, err
+	}
+
+	box := inhPAttrs.mediaBox
+	if inhPAttrs.cropBox != nil {
+		box = inhPAttrs.cropBox
+	}
+	box = Rect(box.LL.X, box.LL.Y, box.UR.X, box.UR.Y)
+
+	// Account for existing rotation.
+	if inhPAttrs.rotate != 0 {
+		if IntMemberOf(inhPAttrs.rotate, []int{+90, -90, +270, -270}) {
+			w := box.Width()
+			box.UR.X = box.LL.X + box.Height()
+			box.UR.Y = box.LL.Y + w
+		}
+		bb = append(contentBytesForPageRotation(inhPAttrs.rotate, box.Width(), box.Height()), bb...)
+	}
+
+	res := inhPAttrs.resources
+	if res == nil {
+		res = NewDict()
+	}
+
+	fx := &FormXObject{
+		ctx:       ctx,
+		PageNr:    pageNr,
+		BBox:      box,
+		content:   bb,
+		resources: res,
+		forms:     map[*Context]*IndirectRef{},
+		migrated:  map[*Context]map[int]int{},
+	}
+
+	if region == nil {
+		return fx, nil
+	}
+
+	return fx.Region(region)
+}
+
+// Region returns a Form XObject for region of fx.
+// region is relative to the lower left corner of fx.
+func (fx FormXObject) Region(region *Rectangle) (*FormXObject, error) {
+
+	if region.LL.X < 0 || region.LL.Y < 0 || region.UR.X > fx.Width() || region.UR.Y > fx.Height() ||
+		region.Width() <= 0 || region.Height() <= 0 {
+		return nil, errors.Errorf("pdfcpu: invalid region %v for page %d", region, fx.PageNr)
+	}
+
+	ll := fx.BBox.LL
+
+	return &FormXObject{
+		ctx:       fx.ctx,
+		PageNr:    fx.PageNr,
+		BBox:      Rect(ll.X+region.LL.X, ll.Y+region.LL.Y, ll.X+region.UR.X, ll.Y+region.UR.Y),
+		content:   fx.content,
+		resources: fx.resources,
+		forms:     map[*Context]*IndirectRef{},
+		migrated:  fx.migrated,
+	}, nil
+}
+
+// formIndRef returns the Form XObject for fx in ctx creating it on first use.
+func (ctx *Context) formIndRef(fx *FormXObject) (*IndirectRef, error) {
+
+	if ir, ok := fx.forms[ctx]; ok {
+		return ir, nil
+	}
+
+	res := fx.resources.Clone().(Dict)
+
+	if ctx != fx.ctx {
+		// Migrate resources into the destination document.
+		migrated, ok := fx.migrated[ctx]
+		if !ok {
+			migrated = map[int]int{}
+			fx.migrated[ctx] = migrated
+		}
+		if _, err := migrateObject(res, fx.ctx, ctx, migrated); err != nil {
+			return nil, err
+		}
+	}
+
+	resIndRef, err := ctx.IndRefForNewObject(res)
+	if err != nil {
+		return nil, err
+	}
+
+	ir, err := createNUpFormForPDF(ctx.XRefTable, resIndRef, fx.content, fx.BBox)
+	if err != nil {
+		return nil, err
+	}
+
+	fx.forms[ctx] = ir
+
+	return ir, nil
+}
+
+// pageResourceDict returns the resource dict of type resType of page dict d, creating it if necessary.
+func (ctx *Context) pageResourceDict(d Dict, inhPAttrs *InheritedPageAttrs, resType string) (Dict, error) {
+
+	o, found := d.Find("Resources")
+	if !found {
+		// Resources are inherited, continue with a consolidated copy.
+		res := inhPAttrs.resources
+		if res == nil {
+			res = NewDict()
+		}
+		d.Insert("Resources", res)
+		o = res
+	}
+
+	resDict, err := ctx.DereferenceDict(o)
+	if err != nil {
+		return nil, err
+	}
+	if resDict == nil {
+		resDict = NewDict()
+		d.Update("Resources", resDict)
+	}
+
+	o, found = resDict.Find(resType)
+	if !found {
+		d1 := NewDict()
+		resDict.Insert(resType, d1)
+		return d1, nil
+	}
+
+	d1, err := ctx.DereferenceDict(o)
+	if err != nil {
+		return nil, err
+	}
+	if d1 == nil {
+		d1 = NewDict()
+		resDict.Update(resType, d1)
+	}
+
+	return d1, nil
+}
+
+// uniqueResourceID returns the first unused resource name prefix%d of d.
+func uniqueResourceID(d Dict, prefix string) string {
+	for i := 0; ; i++ {
+		id := prefix + strconv.Itoa(i)
+		if _, found := d.Find(id); !found {
+			return id
+		}
+	}
+}
+
+func (ctx *Context) contentStreamIndRef(bb []byte) (*IndirectRef, error) {
+	sd, _ := ctx.NewStreamDictForBuf(bb)
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+	return ctx.IndRefForNewObject(*sd)
+}
+
+// appendPageContent appends bb to the content of page dict d
+// and isolates the existing content from any changes of the graphics state.
+func (ctx *Context) appendPageContent(d Dict, bb []byte) error {
+
+	if _, found := d.Find("Contents"); !found {
+		ir, err := ctx.contentStreamIndRef(bb)
+		if err != nil {
+			return err
+		}
+		d.Insert("Contents", *ir)
+		return nil
+	}
+
+	return ctx.wrapPageContent(d, []byte("q "), append([]byte(" Q "), bb...))
+}
+
+// wrapPageContent surrounds the existing content of page dict d by prefix and suffix.
+func (ctx *Context) wrapPageContent(d Dict, prefix, suffix []byte) error {
+
+	var a Array
+
+	o, err := ctx.Dereference(d["Contents"])
+	if err != nil {
+		return err
+	}
+
+	switch o1 := o.(type) {
+	case StreamDict:
+		a = Array{d["Contents"]}
+	case Array:
+		a = o1
+	default:
+		return errors.Errorf("pdfcpu: page content must be stream dict or array")
+	}
+
+	ir1, err := ctx.contentStreamIndRef(prefix)
+	if err != nil {
+		return err
+	}
+
+	ir2, err := ctx.contentStreamIndRef(suffix)
+	if err != nil {
+		return err
+	}
+
+	aNew := Array{*ir1}
+	aNew = append(aNew, a...)
+	aNew = append(aNew, *ir2)
+	d.Update("Contents", aNew)
+
+	return nil
+}
+
+// prependPageContent inserts bb in front of the content of page dict d.
+// bb is expected to leave the graphics state unchanged.
+func (ctx *Context) prependPageContent(d Dict, bb []byte) error {
+
+	ir, err := ctx.contentStreamIndRef(bb)
+	if err != nil {
+		return err
+	}
+
+	o, found := d.Find("Contents")
+	if !found {
+		d.Insert("Contents", *ir)
+		return nil
+	}
+
+	o, err = ctx.Dereference(o)
+	if err != nil {
+		return err
+	}
+
+	switch o1 := o.(type) {
+	case StreamDict:
+		d.Update("Contents", Array{*ir, d["Contents"]})
+	case Array:
+		d.Update("Contents", append(Array{*ir}, o1...))
+	default:
+		return errors.Errorf("pdfcpu: page content must be stream dict or array")
+	}
+
+	return nil
+}
+
+// PlaceFormXObject draws fx onto page pageNr of ctx using the transform t
+// which maps the lower left corner of fx onto the page origin for IdentityTransform.
+func (ctx *Context) PlaceFormXObject(fx *FormXObject, pageNr int, t Transform) error {
+
+	if fx == nil {
+		return errors.New("pdfcpu: missing form xobject")
+	}
+
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, true)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.Errorf("pdfcpu: unknown page number: %d\n", pageNr)
+	}
+
+	formIndRef, err := ctx.formIndRef(fx)
+	if err != nil {
+		return err
+	}
+
+	xoDict, err := ctx.pageResourceDict(d, inhPAttrs, "XObject")
+	if err != nil {
+		return err
+	}
+
+	id := uniqueResourceID(xoDict, "Fx")
+	xoDict.Insert(id, *formIndRef)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "q %.2f %.2f %.2f %.2f %.2f %.2f cm /%s Do Q ", t[0], t[1], t[2], t[3], t[4], t[5], id)
+
+	return ctx.appendPageContent(d, b.Bytes())
+}
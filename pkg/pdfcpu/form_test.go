@@ -0,0 +1,38 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import "testing"
+
+func TestDefaultAppearance(t *testing.T) {
+	for _, tt := range []struct {
+		da, fontRes string
+		fontSize    float64
+		ops         string
+	}{
+		{"/Helv 12 Tf 0 g", "Helv", 12, "0 g"},
+		{"0 0 1 rg /TiRo 0 Tf", "TiRo", 0, "0 0 1 rg"},
+		{"/Helv 0 Tf Tf", "Helv", 0, "Tf"},
+		{"Tf Tf", "Helv", 0, "Tf Tf"},
+		{"", "Helv", 0, ""},
+	} {
+		fontRes, fontSize, ops := defaultAppearance(tt.da)
+		if fontRes != tt.fontRes || fontSize != tt.fontSize || ops != tt.ops {
+			t.Errorf("defaultAppearance(%q): got %s %.1f %q\n", tt.da, fontRes, fontSize, ops)
+		}
+	}
+}
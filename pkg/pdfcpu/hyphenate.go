@@ -0,0 +1,209 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+)
+
+// Hyphenator breaks words into syllables using Liang's algorithm as known from TeX.
+//
+// Hyphenation patterns for most languages are available as part of the TeX distributions (hyph-*.tex)
+// and may be loaded using ReadHyphenator.
+type Hyphenator struct {
+	patterns   map[string][]int // Inter letter values by pattern letters.
+	exceptions map[string][]int // Break positions by word.
+	maxLen     int              // Letter count of the longest pattern.
+	LeftMin    int              // Minimum number of letters in front of a hyphen.
+	RightMin   int              // Minimum number of letters after a hyphen.
+}
+
+// NewHyphenator returns a Hyphenator for patterns like "hy3ph" and exceptions like "ta-ble".
+func NewHyphenator(patterns, exceptions []string) *Hyphenator {
+	h := &Hyphenator{
+		patterns:   map[string][]int{},
+		exceptions: map[string][]int{},
+		LeftMin:    2,
+		RightMin:   3,
+	}
+	for _, p := range patterns {
+		h.addPattern(p)
+	}
+	for _, e := range exceptions {
+		h.addException(e)
+	}
+	return h
+}
+
+func (h *Hyphenator) addPattern(p string) {
+	var letters []rune
+	values := []int{0}
+	for _, r := range p {
+		if unicode.IsDigit(r) {
+			values[len(values)-1] = int(r - '0')
+			continue
+		}
+		letters = append(letters, unicode.ToLower(r))
+		values = append(values, 0)
+	}
+	if len(letters) == 0 {
+		return
+	}
+	h.patterns[string(letters)] = values
+	if len(letters) > h.maxLen {
+		h.maxLen = len(letters)
+	}
+}
+
+func (h *Hyphenator) addException(e string) {
+	var letters []rune
+	var breaks []int
+	for _, r := range e {
+		if r == '-' {
+			breaks = append(breaks, len(letters))
+			continue
+		}
+		letters = append(letters, unicode.ToLower(r))
+	}
+	if len(letters) > 0 {
+		h.exceptions[string(letters)] = breaks
+	}
+}
+
+// ReadHyphenator returns a Hyphenator for the patterns and exceptions read from r.
+// r is either a TeX hyphenation file using \patterns{...} and \hyphenation{...}
+// or a whitespace separated list of patterns.
+// Lines starting with % are comments.
+func ReadHyphenator(r io.Reader) (*Hyphenator, error) {
+	var patterns, exceptions []string
+	var section *[]string
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, "%"); i >= 0 {
+			line = line[:i]
+		}
+		for _, tok := range strings.Fields(line) {
+			switch {
+			case strings.HasPrefix(tok, `\patterns{`):
+				section, tok = &patterns, tok[len(`\patterns{`):]
+			case strings.HasPrefix(tok, `\hyphenation{`):
+				section, tok = &exceptions, tok[len(`\hyphenation{`):]
+			case strings.HasPrefix(tok, `\`):
+				continue
+			}
+			closing := strings.HasSuffix(tok, "}")
+			tok = strings.TrimSuffix(tok, "}")
+			if tok != "" {
+				if section == nil {
+					patterns = append(patterns, tok)
+				} else {
+					*section = append(*section, tok)
+				}
+			}
+			if closing {
+				section = nil
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewHyphenator(patterns, exceptions), nil
+}
+
+// breaks returns the positions word may be hyphenated at.
+func (h *Hyphenator) breaks(word []rune) []int {
+	lw := strings.ToLower(string(word))
+	if bb, ok := h.exceptions[lw]; ok {
+		return bb
+	}
+
+	w := []rune("." + lw + ".")
+	points := make([]int, len(w)+1)
+	for i := 0; i < len(w); i++ {
+		for j := i + 1; j <= len(w) && j-i <= h.maxLen; j++ {
+			values, ok := h.patterns[string(w[i:j])]
+			if !ok {
+				continue
+			}
+			for k, v := range values {
+				if v > points[i+k] {
+					points[i+k] = v
+				}
+			}
+		}
+	}
+
+	var bb []int
+	for i := h.LeftMin; i <= len(word)-h.RightMin; i++ {
+		if points[i+1]%2 == 1 {
+			bb = append(bb, i)
+		}
+	}
+	return bb
+}
+
+// Hyphenate returns the syllables of word.
+// Leading and trailing punctuation sticks to the first and last syllable.
+// Explicit hyphens end a syllable.
+func (h *Hyphenator) Hyphenate(word string) []string {
+	var ss []string
+	for _, part := range strings.SplitAfter(word, "-") {
+		rr := []rune(part)
+		i, j := 0, len(rr)
+		for i < j && !unicode.IsLetter(rr[i]) {
+			i++
+		}
+		for j > i && !unicode.IsLetter(rr[j-1]) {
+			j--
+		}
+		if j-i < h.LeftMin+h.RightMin {
+			ss = append(ss, part)
+			continue
+		}
+		last := 0
+		for _, b := range h.breaks(rr[i:j]) {
+			ss = append(ss, string(rr[last:i+b]))
+			last = i + b
+		}
+		ss = append(ss, string(rr[last:]))
+	}
+	return ss
+}
+
+// hyphenateToFit splits word into the longest hyphenated head rendered within width and the remaining tail.
+func (h *Hyphenator) hyphenateToFit(word string, width float64, fontName string, fontSize int) (string, string) {
+	ss := h.Hyphenate(word)
+	for k := len(ss) - 1; k > 0; k-- {
+		head := strings.Join(ss[:k], "")
+		if !strings.HasSuffix(head, "-") {
+			head += "-"
+		}
+		if font.TextWidth(head, fontName, fontSize) <= width {
+			return head, strings.Join(ss[k:], "")
+		}
+	}
+	return "", word
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+)
+
+// Patterns taken from Liang's thesis "Word Hy-phen-a-tion by Com-put-er".
+const testPatterns = `
+% Sample patterns.
+\patterns{
+hy3ph he2n hena4 hen5at 1na n2at 1tio 2io o2n
+}
+\hyphenation{
+ta-ble
+}
+`
+
+func testHyphenator(t *testing.T) *Hyphenator {
+	t.Helper()
+	h, err := ReadHyphenator(strings.NewReader(testPatterns))
+	if err != nil {
+		t.Fatalf("ReadHyphenator: %v\n", err)
+	}
+	return h
+}
+
+func TestHyphenate(t *testing.T) {
+	h := testHyphenator(t)
+
+	for _, tt := range []struct {
+		word string
+		want []string
+	}{
+		{"hyphenation", []string{"hy", "phen", "ation"}},
+		{"Hyphenation,", []string{"Hy", "phen", "ation,"}},
+		{"(hyphenation)", []string{"(hy", "phen", "ation)"}},
+		{"table", []string{"ta", "ble"}},
+		{"well-table", []string{"well-", "ta", "ble"}},
+		{"on", []string{"on"}},
+	} {
+		if got := h.Hyphenate(tt.word); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: want %v, got %v\n", tt.word, tt.want, got)
+		}
+	}
+}
+
+func TestJustifiedTextHyphenation(t *testing.T) {
+	fontName, fontSize := "Helvetica", 12
+	w := font.TextWidth("Word hyphen-", fontName, fontSize) + 1
+
+	layout := func(hy *Hyphenator) []string {
+		fs := fontSize
+		prepJustifiedString := newPrepJustifiedString(fontName, fontSize, hy)
+		lines := []string{}
+		prepJustifiedString(&lines, "Word hyphenation by computer", w, fontName, &fs, false, false, false)
+		prepJustifiedString(&lines, "", w, fontName, &fs, true, false, false)
+		return lines
+	}
+
+	lines := layout(testHyphenator(t))
+	if len(lines) == 0 || !strings.Contains(lines[0], "hyphen-)") {
+		t.Fatalf("want hyphenated first line, got %v\n", lines)
+	}
+	if !strings.Contains(strings.Join(lines[1:], " "), "(ation") {
+		t.Fatalf("want continuation of hyphenated word, got %v\n", lines)
+	}
+
+	for _, l := range layout(nil) {
+		if strings.Contains(l, "-)") {
+			t.Fatalf("unexpected hyphenation: %v\n", l)
+		}
+	}
+}
@@ -122,11 +122,14 @@ func parsePageFormat(v string) (*Dim, string, error) {
 		port = true
 	}
 
-	d, ok := PaperSize[v]
+	ps, ok := PaperSize[v]
 	if !ok {
 		return nil, v, errors.Errorf("pdfcpu: page format %s is unsupported.\n", v)
 	}
 
+	// Do not modify the predefined paper size.
+	d := &Dim{ps.Width, ps.Height}
+
 	if d.Portrait() && land || d.Landscape() && port {
 		d.Width, d.Height = d.Height, d.Width
 	}
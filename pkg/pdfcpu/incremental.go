@@ -0,0 +1,193 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// originalObjectStream returns a freshly decoded copy of the object stream objNr.
+// Decoded object streams cached while reading share their objects with the xref table.
+func originalObjectStream(ctx *Context, objNr int) (*ObjectStreamDict, error) {
+	entry, ok := ctx.Find(objNr)
+	if !ok {
+		return nil, errors.Errorf("pdfcpu: missing object stream %d", objNr)
+	}
+
+	osd, ok := entry.Object.(ObjectStreamDict)
+	if !ok {
+		return nil, errors.Errorf("pdfcpu: missing object stream %d", objNr)
+	}
+
+	return parsedObjectStream(ctx, osd, objNr)
+}
+
+// originalObject returns the object objNr as found in the file ctx has been read from.
+func originalObject(ctx *Context, objNr int, entry *XRefTableEntry, objStreams map[int]*ObjectStreamDict) (Object, error) {
+	if entry.ObjectStream != nil {
+		osd, ok := objStreams[*entry.ObjectStream]
+		if !ok {
+			var err error
+			if osd, err = originalObjectStream(ctx, *entry.ObjectStream); err != nil {
+				return nil, err
+			}
+			objStreams[*entry.ObjectStream] = osd
+		}
+		return osd.IndexedObject(*entry.ObjectStreamInd)
+	}
+
+	o, err := ParseObject(ctx, *entry.Offset, objNr, *entry.Generation)
+	if err != nil {
+		return nil, err
+	}
+
+	if sd, ok := o.(StreamDict); ok {
+		if _, err := loadEncodedStreamContent(ctx, &sd); err != nil {
+			return nil, err
+		}
+		return sd, nil
+	}
+
+	return o, nil
+}
+
+// sameObjects compares two objects as serialized without following indirect references.
+func sameObjects(o1, o2 Object) bool {
+	sd1, ok := o1.(StreamDict)
+	if !ok {
+		return o1.PDFString() == o2.PDFString()
+	}
+	sd2, ok := o2.(StreamDict)
+	if !ok {
+		return false
+	}
+	return sd1.Dict.PDFString() == sd2.Dict.PDFString() && bytes.Equal(sd1.Raw, sd2.Raw)
+}
+
+// modifiedObjects returns the numbers of all objects created or modified since ctx has been read.
+func modifiedObjects(ctx *Context) ([]int, error) {
+	var objNrs []int
+	objStreams := map[int]*ObjectStreamDict{}
+
+	for objNr, entry := range ctx.Table {
+		if objNr == 0 || entry.Free || entry.Object == nil {
+			continue
+		}
+
+		switch entry.Object.(type) {
+		case ObjectStreamDict, XRefStreamDict:
+			continue
+		}
+
+		if entry.Offset == nil && entry.ObjectStream == nil {
+			// New object.
+			objNrs = append(objNrs, objNr)
+			continue
+		}
+
+		o, err := originalObject(ctx, objNr, entry, objStreams)
+		if err != nil {
+			return nil, errors.Wrapf(err, "pdfcpu: incremental update: obj#%d", objNr)
+		}
+
+		if o == nil || !sameObjects(o, entry.Object) {
+			objNrs = append(objNrs, objNr)
+		}
+	}
+
+	sort.Ints(objNrs)
+
+	return objNrs, nil
+}
+
+func checkIncrementalUpdate(ctx *Context) error {
+	if ctx.Read == nil || ctx.Read.rs == nil {
+		return errors.New("pdfcpu: incremental update needs a context read from a file")
+	}
+
+	if ctx.Read.Repaired || ctx.Write.OffsetPrevXRef == nil {
+		return errors.New("pdfcpu: incremental update not supported for repaired files")
+	}
+
+	if ctx.Encrypt != nil || ctx.Cmd == ENCRYPT {
+		return errors.New("pdfcpu: incremental update not supported for encrypted files")
+	}
+
+	return nil
+}
+
+// WriteIncrementalUpdate writes the original file ctx has been read from followed by an incremental update.
+// The update is made up of all objects created or modified since reading and a new xref section pointing to the original one.
+// The original bytes are kept untouched which preserves existing digital signatures.
+func WriteIncrementalUpdate(ctx *Context) error {
+	if err := checkIncrementalUpdate(ctx); err != nil {
+		return err
+	}
+
+	if ctx.AuditLog {
+		if err := ctx.appendAuditEntry(); err != nil {
+			return err
+		}
+	}
+
+	if err := ensureInfoDictAndFileID(ctx); err != nil {
+		return err
+	}
+
+	objNrs, err := modifiedObjects(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx.Log.Write().Printf("WriteIncrementalUpdate: %d modified objects\n", len(objNrs))
+
+	rs := ctx.Read.rs
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	n, err := io.Copy(ctx.Write, rs)
+	if err != nil {
+		return err
+	}
+	ctx.Write.Offset = n
+
+	if len(objNrs) > 0 {
+
+		// An update section starts on a new line.
+		if err := ctx.Write.WriteEol(); err != nil {
+			return err
+		}
+		ctx.Write.Offset += int64(len(ctx.Write.Eol))
+
+		// Stick to the xref format of the original file.
+		ctx.WriteXRefStream = ctx.Read.UsingXRefStreams
+
+		ctx.Write.Increment = true
+		ctx.Write.ObjNrs = objNrs
+
+		if err := WriteIncrement(ctx); err != nil {
+			return err
+		}
+	}
+
+	return setFileSizeOfWrittenFile(ctx.Write)
+}
@@ -20,8 +20,6 @@ import (
 	"fmt"
 	"strings"
 	"time"
-
-	"github.com/pdfcpu/pdfcpu/pkg/log"
 )
 
 func csvSafeString(s string) string {
@@ -36,10 +34,10 @@ func (ctx *Context) handleInfoDict(d Dict) (err error) {
 		switch key {
 
 		case "Title":
-			log.Write.Println("found Title")
+			ctx.Log.Write().Println("found Title")
 
 		case "Author":
-			log.Write.Println("found Author")
+			ctx.Log.Write().Println("found Author")
 			// Record for stats.
 			ctx.Author, err = ctx.DereferenceText(value)
 			if err != nil {
@@ -48,13 +46,13 @@ func (ctx *Context) handleInfoDict(d Dict) (err error) {
 			ctx.Author = csvSafeString(ctx.Author)
 
 		case "Subject":
-			log.Write.Println("found Subject")
+			ctx.Log.Write().Println("found Subject")
 
 		case "Keywords":
-			log.Write.Println("found Keywords")
+			ctx.Log.Write().Println("found Keywords")
 
 		case "Creator":
-			log.Write.Println("found Creator")
+			ctx.Log.Write().Println("found Creator")
 			// Record for stats.
 			ctx.Creator, err = ctx.DereferenceText(value)
 			if err != nil {
@@ -64,17 +62,17 @@ func (ctx *Context) handleInfoDict(d Dict) (err error) {
 
 		case "Producer", "CreationDate", "ModDate":
 			// pdfcpu will modify these as direct dict entries.
-			log.Write.Printf("found %s", key)
+			ctx.Log.Write().Printf("found %s", key)
 			if indRef, ok := value.(IndirectRef); ok {
 				// Get rid of these extra objects.
 				ctx.Optimize.DuplicateInfoObjects[int(indRef.ObjectNumber)] = true
 			}
 
 		case "Trapped":
-			log.Write.Println("found Trapped")
+			ctx.Log.Write().Println("found Trapped")
 
 		default:
-			log.Write.Printf("handleInfoDict: found out of spec entry %s %v\n", key, value)
+			ctx.Log.Write().Printf("handleInfoDict: found out of spec entry %s %v\n", key, value)
 
 		}
 	}
@@ -93,11 +91,13 @@ func (ctx *Context) ensureInfoDict() error {
 	// Keywords             -
 	// Creator              -
 	// Producer		        modified by pdfcpu
-	// CreationDate	        modified by pdfcpu
+	// CreationDate	        modified by pdfcpu unless present
 	// ModDate		        modified by pdfcpu
 	// Trapped              -
 
-	now := DateString(time.Now())
+	// An existing XMP metadata stream gets updated accordingly.
+
+	now := DateString(ctx.Now())
 
 	v := "pdfcpu " + VersionStr
 
@@ -115,6 +115,8 @@ func (ctx *Context) ensureInfoDict() error {
 
 		ctx.Info = ir
 
+		ctx.syncXMPMetadata()
+
 		return nil
 	}
 
@@ -127,26 +129,38 @@ func (ctx *Context) ensureInfoDict() error {
 		return err
 	}
 
-	d.Update("CreationDate", StringLiteral(now))
+	creationDate := now
+	if o, found := d.Find("CreationDate"); found {
+		// Keep a valid creation date as direct object.
+		if s, err := ctx.DereferenceText(o); err == nil {
+			if _, ok := DateTime(s, true); ok {
+				creationDate = s
+			}
+		}
+	}
+
+	d.Update("CreationDate", StringLiteral(creationDate))
 	d.Update("ModDate", StringLiteral(now))
 	d.Update("Producer", StringLiteral(v))
 
+	ctx.syncXMPMetadata()
+
 	return nil
 }
 
 // Write the document info object for this PDF file.
 func (ctx *Context) writeDocumentInfoDict() error {
 
-	log.Write.Printf("*** writeDocumentInfoDict begin: offset=%d ***\n", ctx.Write.Offset)
+	ctx.Log.Write().Printf("*** writeDocumentInfoDict begin: offset=%d ***\n", ctx.Write.Offset)
 
 	// Note: The document info object is optional but pdfcpu ensures one.
 
 	if ctx.Info == nil {
-		log.Write.Printf("writeDocumentInfoObject end: No info object present, offset=%d\n", ctx.Write.Offset)
+		ctx.Log.Write().Printf("writeDocumentInfoObject end: No info object present, offset=%d\n", ctx.Write.Offset)
 		return nil
 	}
 
-	log.Write.Printf("writeDocumentInfoObject: %s\n", *ctx.Info)
+	ctx.Log.Write().Printf("writeDocumentInfoObject: %s\n", *ctx.Info)
 
 	o := *ctx.Info
 
@@ -160,7 +174,7 @@ func (ctx *Context) writeDocumentInfoDict() error {
 		return err
 	}
 
-	log.Write.Printf("*** writeDocumentInfoDict end: offset=%d ***\n", ctx.Write.Offset)
+	ctx.Log.Write().Printf("*** writeDocumentInfoDict end: offset=%d ***\n", ctx.Write.Offset)
 
 	return nil
 }
@@ -299,6 +313,20 @@ func (ctx *Context) pageInfo(selectedPages IntSet) ([]string, error) {
 	return ss, nil
 }
 
+func firstNonEmpty(s1, s2 string) string {
+	if s1 != "" {
+		return s1
+	}
+	return s2
+}
+
+func metadataDateString(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return DateString(t)
+}
+
 // InfoDigest returns info about ctx.
 func (ctx *Context) InfoDigest(selectedPages IntSet) ([]string, error) {
 	var separator = "............................................"
@@ -308,6 +336,11 @@ func (ctx *Context) InfoDigest(selectedPages IntSet) ([]string, error) {
 		v = ctx.RootVersion
 	}
 	ss = append(ss, fmt.Sprintf("%20s: %s", "PDF version", v))
+	rv, _, err := ctx.RequiredVersion()
+	if err != nil {
+		return nil, err
+	}
+	ss = append(ss, fmt.Sprintf("%20s: %s", "Required version", rv))
 	ss = append(ss, fmt.Sprintf("%20s: %d", "Page count", ctx.PageCount))
 
 	pi, err := ctx.pageInfo(selectedPages)
@@ -316,18 +349,27 @@ func (ctx *Context) InfoDigest(selectedPages IntSet) ([]string, error) {
 	}
 	ss = append(ss, pi...)
 
+	// Fall back to XMP metadata for values missing in the info dict.
+	md, err := ctx.DocumentMetadata()
+	if err != nil {
+		return nil, err
+	}
+
 	ss = append(ss, fmt.Sprintf(separator))
-	ss = append(ss, fmt.Sprintf("%20s: %s", "Title", ctx.Title))
-	ss = append(ss, fmt.Sprintf("%20s: %s", "Author", ctx.Author))
-	ss = append(ss, fmt.Sprintf("%20s: %s", "Subject", ctx.Subject))
-	ss = append(ss, fmt.Sprintf("%20s: %s", "PDF Producer", ctx.Producer))
-	ss = append(ss, fmt.Sprintf("%20s: %s", "Content creator", ctx.Creator))
-	ss = append(ss, fmt.Sprintf("%20s: %s", "Creation date", ctx.CreationDate))
-	ss = append(ss, fmt.Sprintf("%20s: %s", "Modification date", ctx.ModDate))
+	ss = append(ss, fmt.Sprintf("%20s: %s", "Title", firstNonEmpty(ctx.Title, md.Title)))
+	ss = append(ss, fmt.Sprintf("%20s: %s", "Author", firstNonEmpty(ctx.Author, md.Author)))
+	ss = append(ss, fmt.Sprintf("%20s: %s", "Subject", firstNonEmpty(ctx.Subject, md.Subject)))
+	ss = append(ss, fmt.Sprintf("%20s: %s", "PDF Producer", firstNonEmpty(ctx.Producer, md.Producer)))
+	ss = append(ss, fmt.Sprintf("%20s: %s", "Content creator", firstNonEmpty(ctx.Creator, md.Creator)))
+	ss = append(ss, fmt.Sprintf("%20s: %s", "Creation date", firstNonEmpty(ctx.CreationDate, metadataDateString(md.CreationDate))))
+	ss = append(ss, fmt.Sprintf("%20s: %s", "Modification date", firstNonEmpty(ctx.ModDate, metadataDateString(md.ModDate))))
 
 	if err := ctx.addKeywordsToInfoDigest(&ss); err != nil {
 		return nil, err
 	}
+	if len(ctx.Keywords) == 0 && md.Keywords != "" {
+		ss = append(ss, fmt.Sprintf("%20s: %s", "Keywords", md.Keywords))
+	}
 
 	if err := ctx.addPropertiesToInfoDigest(&ss); err != nil {
 		return nil, err
@@ -385,11 +427,24 @@ func (ctx *Context) InfoDigest(selectedPages IntSet) ([]string, error) {
 	}
 	ss = append(ss, fmt.Sprintf("%20s: %s", "Encrypted", s))
 
+	if ctx.E != nil {
+		s = "Yes"
+		if ctx.E.EmptyUPW {
+			s = "No (empty user password)"
+		}
+		ss = append(ss, fmt.Sprintf("%20s: %s", "Open protected", s))
+	}
+
 	ctx.addPermissionsToInfoDigest(&ss)
 
 	if err := ctx.addAttachmentsToInfoDigest(&ss); err != nil {
 		return nil, err
 	}
 
+	if ctx.ListWarnings {
+		ss = append(ss, fmt.Sprintf(separator))
+		ctx.addWarningsToInfoDigest(&ss)
+	}
+
 	return ss, nil
 }
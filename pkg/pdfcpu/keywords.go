@@ -40,7 +40,7 @@ func KeywordsAdd(xRefTable *XRefTable, keywords []string) error {
 
 	for _, s := range keywords {
 		if !MemberOf(s, list) {
-			xRefTable.Keywords += ", " + UTF8ToCP1252(s)
+			xRefTable.Keywords += ", " + s
 		}
 	}
 
@@ -49,7 +49,12 @@ func KeywordsAdd(xRefTable *XRefTable, keywords []string) error {
 		return err
 	}
 
-	d["Keywords"] = StringLiteral(xRefTable.Keywords)
+	sl, err := textLiteral(xRefTable.Keywords)
+	if err != nil {
+		return err
+	}
+
+	d["Keywords"] = sl
 
 	return nil
 }
@@ -69,11 +74,6 @@ func KeywordsRemove(xRefTable *XRefTable, keywords []string) (bool, error) {
 		return true, nil
 	}
 
-	kw := make([]string, len(keywords))
-	for i, s := range keywords {
-		kw[i] = UTF8ToCP1252(s)
-	}
-
 	// Distil document keywords.
 	ss := strings.FieldsFunc(xRefTable.Keywords, func(c rune) bool { return c == ',' || c == ';' || c == '\r' })
 
@@ -83,7 +83,7 @@ func KeywordsRemove(xRefTable *XRefTable, keywords []string) (bool, error) {
 
 	for _, s := range ss {
 		s = strings.TrimSpace(s)
-		if MemberOf(s, kw) {
+		if MemberOf(s, keywords) {
 			removed = true
 			continue
 		}
@@ -96,7 +96,11 @@ func KeywordsRemove(xRefTable *XRefTable, keywords []string) (bool, error) {
 	}
 
 	if removed {
-		d["Keywords"] = StringLiteral(xRefTable.Keywords)
+		sl, err := textLiteral(xRefTable.Keywords)
+		if err != nil {
+			return false, err
+		}
+		d["Keywords"] = sl
 	}
 
 	return removed, nil
@@ -0,0 +1,349 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	errInvalidLabelsGrid   = errors.New("pdfcpu labels: grid dimensions: m > 0, n > 0")
+	errInvalidLabelsConfig = errors.New("pdfcpu: invalid configuration string")
+)
+
+type labelsParamMap map[string]func(string, *Labels) error
+
+var lblParamMap = labelsParamMap{
+	"dimensions": parseDimensionsLabels,
+	"formsize":   parsePageFormatLabels,
+	"papersize":  parsePageFormatLabels,
+	"margin":     parseSheetMarginLabels,
+	"gutter":     parseGutterLabels,
+	"border":     parseBorderLabels,
+}
+
+// Handle applies parameter completion and if successful
+// parses the parameter values into labels.
+func (m labelsParamMap) Handle(paramPrefix, paramValueStr string, lbl *Labels) error {
+	var param string
+
+	// Completion support
+	for k := range m {
+		if !strings.HasPrefix(k, paramPrefix) {
+			continue
+		}
+		if len(param) > 0 {
+			return errors.Errorf("pdfcpu: ambiguous parameter prefix \"%s\"", paramPrefix)
+		}
+		param = k
+	}
+
+	if param == "" {
+		return errors.Errorf("pdfcpu: ambiguous parameter prefix \"%s\"", paramPrefix)
+	}
+
+	return m[param](paramValueStr, lbl)
+}
+
+// Labels represents the command details for the command "Labels".
+// A single source page gets repeated onto a grid of labels on each output sheet.
+type Labels struct {
+	PageDim  *Dim        // Sheet dimensions in display unit.
+	PageSize string      // Paper size eg. A4L, A4P, A4(=default=A4P), see paperSize.go
+	UserDim  bool        // true if one of dimensions or paperSize provided overriding the default.
+	Grid     *Dim        // Label grid dimensions eg (3,8)
+	Margin   float64     // Outer sheet margin.
+	HGutter  float64     // Horizontal spacing between adjacent labels.
+	VGutter  float64     // Vertical spacing between adjacent labels.
+	Border   bool        // Draw label boundaries.
+	InpUnit  DisplayUnit // input display unit.
+}
+
+// DefaultLabelsConfig returns the default Labels configuration.
+func DefaultLabelsConfig() *Labels {
+	return &Labels{
+		PageSize: "A4",
+	}
+}
+
+func (lbl Labels) String() string {
+	return fmt.Sprintf("Labels conf: %s %s, grid=%s, margin=%.2f, gutter=%.2f %.2f, border=%t\n",
+		lbl.PageSize, *lbl.PageDim, *lbl.Grid, lbl.Margin, lbl.HGutter, lbl.VGutter, lbl.Border)
+}
+
+// N returns the number of labels per sheet.
+func (lbl Labels) N() int {
+	return int(lbl.Grid.Height * lbl.Grid.Width)
+}
+
+func parsePageFormatLabels(s string, lbl *Labels) (err error) {
+	if lbl.UserDim {
+		return errors.New("pdfcpu: only one of formsize(papersize) or dimensions allowed")
+	}
+	lbl.PageDim, lbl.PageSize, err = parsePageFormat(s)
+	lbl.UserDim = true
+	return err
+}
+
+func parseDimensionsLabels(s string, lbl *Labels) (err error) {
+	if lbl.UserDim {
+		return errors.New("pdfcpu: only one of formsize(papersize) or dimensions allowed")
+	}
+	lbl.PageDim, lbl.PageSize, err = parsePageDim(s, lbl.InpUnit)
+	lbl.UserDim = true
+	return err
+}
+
+func parseNonNegativeLength(s string, u DisplayUnit) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if f < 0 {
+		return 0, errors.New("pdfcpu: please provide a positive value")
+	}
+	return toUserSpace(f, u), nil
+}
+
+func parseSheetMarginLabels(s string, lbl *Labels) error {
+	f, err := parseNonNegativeLength(s, lbl.InpUnit)
+	if err != nil {
+		return errors.Errorf("pdfcpu: labels margin: %v", err)
+	}
+	lbl.Margin = f
+	return nil
+}
+
+func parseGutterLabels(s string, lbl *Labels) error {
+
+	// gutter: h [v]
+	// A single value applies to both directions.
+
+	ss := strings.Fields(s)
+	if len(ss) < 1 || len(ss) > 2 {
+		return errors.Errorf("pdfcpu: labels gutter: need 1 or 2 values, %s\n", s)
+	}
+
+	h, err := parseNonNegativeLength(ss[0], lbl.InpUnit)
+	if err != nil {
+		return errors.Errorf("pdfcpu: labels gutter: %v", err)
+	}
+
+	v := h
+	if len(ss) == 2 {
+		if v, err = parseNonNegativeLength(ss[1], lbl.InpUnit); err != nil {
+			return errors.Errorf("pdfcpu: labels gutter: %v", err)
+		}
+	}
+
+	lbl.HGutter, lbl.VGutter = h, v
+
+	return nil
+}
+
+func parseBorderLabels(s string, lbl *Labels) error {
+	switch strings.ToLower(s) {
+	case "on", "true", "t":
+		lbl.Border = true
+	case "off", "false", "f":
+		lbl.Border = false
+	default:
+		return errors.New("pdfcpu: labels border, please provide one of: on/off true/false t/f")
+	}
+
+	return nil
+}
+
+// ParseLabelsDetails parses a Labels command string into an internal structure.
+func ParseLabelsDetails(s string, lbl *Labels) error {
+	if s == "" {
+		return errInvalidLabelsConfig
+	}
+
+	ss := strings.Split(s, ",")
+
+	for _, s := range ss {
+
+		ss1 := strings.Split(s, ":")
+		if len(ss1) != 2 {
+			return errInvalidLabelsConfig
+		}
+
+		paramPrefix := strings.TrimSpace(ss1[0])
+		paramValueStr := strings.TrimSpace(ss1[1])
+
+		if err := lblParamMap.Handle(paramPrefix, paramValueStr, lbl); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ParseLabelsGridDefinition parses the label grid dimensions into an internal structure.
+func ParseLabelsGridDefinition(cols, rows int, lbl *Labels) error {
+	if cols <= 0 || rows <= 0 {
+		return errInvalidLabelsGrid
+	}
+	lbl.Grid = &Dim{float64(cols), float64(rows)}
+	return nil
+}
+
+// LabelsConfig returns a Labels configuration for a sheet of cols x rows labels.
+func LabelsConfig(cols, rows int, desc string) (*Labels, error) {
+	lbl := DefaultLabelsConfig()
+	if desc != "" {
+		if err := ParseLabelsDetails(desc, lbl); err != nil {
+			return nil, err
+		}
+	}
+	return lbl, ParseLabelsGridDefinition(cols, rows, lbl)
+}
+
+// rectsForLabels returns the label cells of a sheet row by row starting at the top left corner.
+func rectsForLabels(lbl *Labels) ([]*Rectangle, error) {
+	cols := int(lbl.Grid.Width)
+	rows := int(lbl.Grid.Height)
+
+	m := lbl.Margin
+	gw := (lbl.PageDim.Width - 2*m - float64(cols-1)*lbl.HGutter) / float64(cols)
+	gh := (lbl.PageDim.Height - 2*m - float64(rows-1)*lbl.VGutter) / float64(rows)
+
+	if gw <= 0 || gh <= 0 {
+		return nil, errors.New("pdfcpu labels: margin and gutters exceed sheet dimensions")
+	}
+
+	rr := []*Rectangle{}
+
+	for i := rows - 1; i >= 0; i-- {
+		for j := 0; j < cols; j++ {
+			llx := m + float64(j)*(gw+lbl.HGutter)
+			lly := m + float64(i)*(gh+lbl.VGutter)
+			rr = append(rr, Rect(llx, lly, llx+gw, lly+gh))
+		}
+	}
+
+	return rr, nil
+}
+
+func (ctx *Context) labelSheet(fx *FormXObject, lbl *Labels, rr []*Rectangle, pagesDict Dict, pagesIndRef *IndirectRef) error {
+
+	formIndRef, err := ctx.formIndRef(fx)
+	if err != nil {
+		return err
+	}
+
+	// rSrc is the label content in form space.
+	rSrc := RectForDim(fx.Width(), fx.Height())
+
+	// nup supplies the tiling of a form into a grid cell.
+	nup := &NUp{Border: lbl.Border}
+
+	var buf bytes.Buffer
+	for _, r := range rr {
+		nUpTilePDFBytes(&buf, rSrc, r, "Fx0", nup, false, false)
+	}
+
+	resourceDict := Dict(
+		map[string]Object{
+			"XObject": Dict(map[string]Object{"Fx0": *formIndRef}),
+		},
+	)
+
+	contentsIndRef, err := ctx.contentStreamIndRef(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	pageDict := Dict(
+		map[string]Object{
+			"Type":      Name("Page"),
+			"Parent":    *pagesIndRef,
+			"MediaBox":  RectForDim(lbl.PageDim.Width, lbl.PageDim.Height).Array(),
+			"Resources": resourceDict,
+			"Contents":  *contentsIndRef,
+		},
+	)
+
+	indRef, err := ctx.IndRefForNewObject(pageDict)
+	if err != nil {
+		return err
+	}
+
+	return AppendPageTree(indRef, 1, pagesDict)
+}
+
+// Labels replaces the pages of ctx by label sheets.
+// For each selected page a sheet is generated containing this page repeated onto each cell of the label grid.
+// Source pages not fitting into a label cell are scaled down preserving their aspect ratio.
+func (ctx *Context) Labels(selectedPages IntSet, lbl *Labels) error {
+
+	if lbl == nil || lbl.Grid == nil {
+		return errors.New("pdfcpu: missing label grid")
+	}
+
+	if lbl.PageDim == nil {
+		lbl.PageDim = PaperSize[lbl.PageSize]
+	}
+
+	rr, err := rectsForLabels(lbl)
+	if err != nil {
+		return err
+	}
+
+	pagesDict := Dict(
+		map[string]Object{
+			"Type":  Name("Pages"),
+			"Count": Integer(0),
+		},
+	)
+
+	pagesIndRef, err := ctx.IndRefForNewObject(pagesDict)
+	if err != nil {
+		return err
+	}
+
+	pageNrs := sortSelectedPages(selectedPages)
+
+	for _, pageNr := range pageNrs {
+
+		fx, err := ctx.ExtractFormXObject(pageNr, nil)
+		if err != nil {
+			return err
+		}
+
+		if err = ctx.labelSheet(fx, lbl, rr, pagesDict, pagesIndRef); err != nil {
+			return err
+		}
+	}
+
+	// Replace original pagesDict.
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+
+	rootDict.Update("Pages", *pagesIndRef)
+	ctx.PageCount = len(pageNrs)
+
+	return nil
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Manifest captures the state of a PDF file for later integrity verification.
+type Manifest struct {
+	FileHash    string            `json:"fileHash"`              // SHA-256 of the file.
+	PageCount   int               `json:"pageCount"`             // Number of pages.
+	Signed      bool              `json:"signed"`                // true if the document signals existing signatures.
+	Attachments map[string]string `json:"attachments,omitempty"` // SHA-256 for each attachment by id.
+}
+
+// FileHash returns the SHA-256 of rs and resets rs to its start.
+func FileHash(rs io.ReadSeeker) (string, error) {
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, rs); err != nil {
+		return "", err
+	}
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Signed returns true if the AcroForm of this document signals at least one signature field.
+func (ctx *Context) Signed() (bool, error) {
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return false, err
+	}
+
+	d, err := ctx.DereferenceDict(rootDict["AcroForm"])
+	if err != nil || d == nil {
+		return false, err
+	}
+
+	o, found := d.Find("SigFlags")
+	if !found {
+		return false, nil
+	}
+
+	i, err := ctx.DereferenceInteger(o)
+	if err != nil || i == nil {
+		return false, err
+	}
+
+	// Bit position 1: SignaturesExist
+	return i.Value()&1 > 0, nil
+}
+
+func (ctx *Context) attachmentHashes() (map[string]string, error) {
+	if err := ctx.LocateNameTree("EmbeddedFiles", false); err != nil {
+		return nil, err
+	}
+	if ctx.Names["EmbeddedFiles"] == nil {
+		return nil, nil
+	}
+
+	aa, err := ctx.ExtractAttachments(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]string{}
+	for _, a := range aa {
+		bb, err := ioutil.ReadAll(a)
+		if err != nil {
+			return nil, err
+		}
+		id := a.ID
+		if IsStringUTF16BE(id) {
+			if id, err = DecodeUTF16String(id); err != nil {
+				return nil, err
+			}
+		}
+		h := sha256.Sum256(bb)
+		m[id] = hex.EncodeToString(h[:])
+	}
+
+	return m, nil
+}
+
+// Manifest returns the manifest for this document using fileHash as the hash of the underlying file.
+func (ctx *Context) Manifest(fileHash string) (*Manifest, error) {
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	signed, err := ctx.Signed()
+	if err != nil {
+		return nil, err
+	}
+
+	aa, err := ctx.attachmentHashes()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manifest{
+		FileHash:    fileHash,
+		PageCount:   ctx.PageCount,
+		Signed:      signed,
+		Attachments: aa,
+	}, nil
+}
+
+// Verify checks m against want and returns an error listing all mismatches.
+func (m Manifest) Verify(want Manifest) error {
+	var ss []string
+
+	if m.FileHash != want.FileHash {
+		ss = append(ss, "file hash mismatch")
+	}
+
+	if m.PageCount != want.PageCount {
+		ss = append(ss, fmt.Sprintf("page count: got %d, want %d", m.PageCount, want.PageCount))
+	}
+
+	if m.Signed != want.Signed {
+		ss = append(ss, "signature status mismatch")
+	}
+
+	var ids []string
+	for id := range want.Attachments {
+		ids = append(ids, id)
+	}
+	for id := range m.Attachments {
+		if _, ok := want.Attachments[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		got, ok1 := m.Attachments[id]
+		h, ok2 := want.Attachments[id]
+		switch {
+		case !ok1:
+			ss = append(ss, "missing attachment: "+id)
+		case !ok2:
+			ss = append(ss, "unexpected attachment: "+id)
+		case got != h:
+			ss = append(ss, "attachment hash mismatch: "+id)
+		}
+	}
+
+	if len(ss) > 0 {
+		return errors.Errorf("pdfcpu: manifest verification failed:\n%s", strings.Join(ss, "\n"))
+	}
+
+	return nil
+}
@@ -17,9 +17,14 @@ limitations under the License.
 package pdfcpu
 
 import (
+	"strings"
+
 	"github.com/pdfcpu/pdfcpu/pkg/log"
 )
 
+// mergeStampDesc describes the stamp identifying the file merged pages originate from.
+const mergeStampDesc = "font:Helvetica, points:8, pos:bl, off:10 6, scale:1 abs, rot:0, fillc:0.5 0.5 0.5"
+
 func patchIndRef(ir *IndirectRef, lookup map[int]int) {
 	i := ir.ObjectNumber.Value()
 	ir.ObjectNumber = Integer(lookup[i])
@@ -289,13 +294,63 @@ func mergeDuplicateObjNumberIntSets(ctxSource, ctxDest *Context) {
 	log.Debug.Println("mergeDuplicateObjNumberIntSets end")
 }
 
+func remapSourcePageRefs(ctxSource, ctxDest *Context) error {
+
+	objNrs, err := ctxSource.pageObjNrs()
+	if err != nil {
+		return err
+	}
+
+	pages := make([]IndirectRef, len(objNrs))
+	for i, objNr := range objNrs {
+		pages[i] = *NewIndirectRef(objNr, 0)
+	}
+
+	keepStructure, err := mergeStructTrees(ctxSource, ctxDest, pages)
+	if err != nil {
+		return err
+	}
+
+	// Page object numbers are consistently patched already.
+	return ctxDest.RemapPageRefs(pages, nil, keepStructure)
+}
+
+// stampFileName stamps the pages from thru to of ctx with title.
+func (ctx *Context) stampFileName(title string, from, thru int) error {
+	// Escape % since it introduces page number placeholders.
+	wm, err := ParseTextWatermarkDetails(strings.ReplaceAll(title, "%", "%%"), mergeStampDesc, true, POINTS)
+	if err != nil {
+		return err
+	}
+	pages := IntSet{}
+	for i := from; i <= thru; i++ {
+		pages[i] = true
+	}
+	return ctx.AddWatermarks(pages, wm)
+}
+
+// stampMergedFiles stamps the pages of ctxSource merged into ctxDest with the file name of ctxSource.
+// On the first merge the original pages of ctxDest get stamped with its file name as well.
+func stampMergedFiles(ctxSource, ctxDest *Context) error {
+	pageNr := ctxDest.PageCount - ctxSource.PageCount + 1
+	if !ctxDest.mergeStamped {
+		if err := ctxDest.stampFileName(ctxDest.outlineTitle(), 1, pageNr-1); err != nil {
+			return err
+		}
+		ctxDest.mergeStamped = true
+	}
+	return ctxDest.stampFileName(ctxSource.outlineTitle(), pageNr, ctxDest.PageCount)
+}
+
 // MergeXRefTables merges Context ctxSource into ctxDest by appending its page tree.
 func MergeXRefTables(ctxSource, ctxDest *Context) (err error) {
 
 	// Sweep over ctxSource cross ref table and ensure valid object numbers in ctxDest's space.
 	patchSourceObjectNumbers(ctxSource, ctxDest)
 
-	mergeAcroForms(ctxSource, ctxDest)
+	if err = mergeAcroForms(ctxSource, ctxDest); err != nil {
+		return err
+	}
 
 	// Append ctxSource pageTree to ctxDest pageTree.
 	log.Debug.Println("appendSourcePageTreeToDestPageTree")
@@ -308,6 +363,27 @@ func MergeXRefTables(ctxSource, ctxDest *Context) (err error) {
 	log.Debug.Println("appendSourceObjectsToDest")
 	appendSourceObjectsToDest(ctxSource, ctxDest)
 
+	// Merge ctxSource name trees like named destinations and embedded files into ctxDest.
+	if err = mergeNames(ctxSource, ctxDest); err != nil {
+		return err
+	}
+
+	// Graft ctxSource structure tree onto ctxDest structure tree.
+	if err = remapSourcePageRefs(ctxSource, ctxDest); err != nil {
+		return err
+	}
+
+	// Merge ctxSource outline into ctxDest outline.
+	if err = mergeOutlines(ctxSource, ctxDest); err != nil {
+		return err
+	}
+
+	if ctxDest.MergeStampFileNames {
+		if err = stampMergedFiles(ctxSource, ctxDest); err != nil {
+			return err
+		}
+	}
+
 	// Mark source's root object as free.
 	err = ctxDest.turnEntryToFree(int(ctxSource.Root.ObjectNumber))
 	if err != nil {
@@ -16,6 +16,10 @@ limitations under the License.
 
 package pdfcpu
 
+import (
+	"strconv"
+)
+
 // NOTE
 // A naive first stab at merging AcroForms.
 // Your mileage may vary.
@@ -36,7 +40,7 @@ func handleNeedAppearances(ctxSource *Context, dSrc, dDest Dict) error {
 }
 
 func handleSigFields(ctxSource, ctxDest *Context, dSrc, dDest Dict) error {
-	o, found := dSrc.Find("SigFields")
+	o, found := dSrc.Find("SigFlags")
 	if !found {
 		return nil
 	}
@@ -47,10 +51,10 @@ func handleSigFields(ctxSource, ctxDest *Context, dSrc, dDest Dict) error {
 	if iSrc == nil {
 		return nil
 	}
-	// Merge SigFields into dDest.
+	// Merge SigFlags into dDest.
 	o, found = dDest.Find("SigFlags")
 	if !found {
-		dDest["SigFields"] = Integer(*iSrc)
+		dDest["SigFlags"] = Integer(*iSrc)
 		return nil
 	}
 	iDest, err := ctxDest.DereferenceInteger(o)
@@ -58,7 +62,7 @@ func handleSigFields(ctxSource, ctxDest *Context, dSrc, dDest Dict) error {
 		return err
 	}
 	if iDest == nil {
-		dDest["SigFields"] = Integer(*iSrc)
+		dDest["SigFlags"] = Integer(*iSrc)
 		return nil
 	}
 	// SignaturesExist
@@ -69,6 +73,7 @@ func handleSigFields(ctxSource, ctxDest *Context, dSrc, dDest Dict) error {
 	if *iSrc&2 > 0 {
 		*iDest |= 2
 	}
+	dDest["SigFlags"] = Integer(*iDest)
 	return nil
 }
 
@@ -114,14 +119,14 @@ func handleDR(ctxSource, ctxDest *Context, dSrc, dDest Dict) error {
 	o, found = dDest.Find("DR")
 	if !found {
 		dDest["DR"] = dSrc
-	} else {
-		dDest, err := ctxDest.DereferenceDict(o)
-		if err != nil {
-			return err
-		}
-		if len(dDest) == 0 {
-			dDest["DR"] = dSrc
-		}
+		return nil
+	}
+	dr, err := ctxDest.DereferenceDict(o)
+	if err != nil {
+		return err
+	}
+	if len(dr) == 0 {
+		dDest["DR"] = dSrc
 	}
 	return nil
 }
@@ -225,6 +230,83 @@ func handleFormAttributes(ctxSource, ctxDest *Context, dSrc, dDest Dict, arrFiel
 	return nil
 }
 
+func fieldNames(ctx *Context, fields Array) (map[string]bool, error) {
+	m := map[string]bool{}
+	for _, o := range fields {
+		d, err := ctx.DereferenceDict(o)
+		if err != nil {
+			return nil, err
+		}
+		if d == nil {
+			continue
+		}
+		o, found := d.Find("T")
+		if !found {
+			continue
+		}
+		s, err := ctx.DereferenceText(o)
+		if err != nil {
+			return nil, err
+		}
+		m[s] = true
+	}
+	return m, nil
+}
+
+// renameCollidingFields renames all top level fields of arrFieldsSrc whose partial names are already taken by arrFieldsDest.
+// Since fully qualified field names are rooted in top level fields this resolves all name collisions.
+// A colliding name "x" becomes "x_1" or the first unused "x_n" in the order of arrFieldsSrc.
+func renameCollidingFields(ctxSource, ctxDest *Context, arrFieldsSrc, arrFieldsDest Array) error {
+
+	names, err := fieldNames(ctxDest, arrFieldsDest)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range arrFieldsSrc {
+
+		d, err := ctxSource.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			continue
+		}
+
+		o, found := d.Find("T")
+		if !found {
+			continue
+		}
+
+		s, err := ctxSource.DereferenceText(o)
+		if err != nil {
+			return err
+		}
+
+		if !names[s] {
+			names[s] = true
+			continue
+		}
+
+		s1 := s
+		for i := 1; names[s1]; i++ {
+			s1 = s + "_" + strconv.Itoa(i)
+		}
+		names[s1] = true
+
+		sl, err := textLiteral(s1)
+		if err != nil {
+			return err
+		}
+		d.Update("T", sl)
+	}
+
+	return nil
+}
+
+// mergeAcroForms merges the field tree of ctxSource into the field tree of ctxDest.
+// Colliding field names get renamed, see renameCollidingFields.
+// Widgets keep referring to their pages since the page trees get merged as a whole.
 func mergeAcroForms(ctxSource, ctxDest *Context) error {
 
 	rootDictDest, err := ctxDest.Catalog()
@@ -252,7 +334,7 @@ func mergeAcroForms(ctxSource, ctxDest *Context) error {
 	if !found {
 		return nil
 	}
-	arrFieldsSrc, err := ctxDest.DereferenceArray(o)
+	arrFieldsSrc, err := ctxSource.DereferenceArray(o)
 	if err != nil {
 		return err
 	}
@@ -297,6 +379,10 @@ func mergeAcroForms(ctxSource, ctxDest *Context) error {
 
 	// Fields: add all indrefs
 
+	if err := renameCollidingFields(ctxSource, ctxDest, arrFieldsSrc, arrFieldsDest); err != nil {
+		return err
+	}
+
 	// Merge all fields from ctxSrc into ctxDest
 	arrFieldsDest = append(arrFieldsDest, arrFieldsSrc...)
 	dDest["Fields"] = arrFieldsDest
@@ -0,0 +1,263 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pkg/errors"
+)
+
+func nameTreeKey(o Object) (string, bool) {
+	switch k := o.(type) {
+	case StringLiteral:
+		return k.Value(), true
+	case HexLiteral:
+		return k.Value(), true
+	}
+	return "", false
+}
+
+// processNameTreeDict applies handler to all key value pairs of the name tree rooted at o.
+func (ctx *Context) processNameTreeDict(o Object, handler func(k string, v Object) error, visited IntSet) error {
+	if ir, ok := o.(IndirectRef); ok {
+		objNr := ir.ObjectNumber.Value()
+		if visited[objNr] {
+			return nil
+		}
+		visited[objNr] = true
+	}
+
+	d, err := ctx.DereferenceDict(o)
+	if err != nil || d == nil {
+		return err
+	}
+
+	if o, found := d.Find("Kids"); found {
+		kids, err := ctx.DereferenceArray(o)
+		if err != nil {
+			return err
+		}
+		for _, kid := range kids {
+			if err := ctx.processNameTreeDict(kid, handler, visited); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	a, err := ctx.DereferenceArray(d["Names"])
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i+1 < len(a); i += 2 {
+		o, err := ctx.Dereference(a[i])
+		if err != nil {
+			return err
+		}
+		k, ok := nameTreeKey(o)
+		if !ok {
+			return errors.Errorf("pdfcpu: corrupt name tree key: %v", o)
+		}
+		if err := handler(k, a[i+1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sameEmbeddedFile returns true if the file specifications o1 and o2 embed identical data.
+func (ctx *Context) sameEmbeddedFile(o1, o2 Object) (bool, error) {
+	var bb [2][]byte
+	for i, o := range []Object{o1, o2} {
+		d, err := ctx.DereferenceDict(o)
+		if err != nil || d == nil {
+			return false, err
+		}
+		sd, err := fileSpecStreamDict(ctx.XRefTable, d)
+		if err != nil || sd == nil {
+			return false, err
+		}
+		bb[i] = sd.Raw
+	}
+	return bytes.Equal(bb[0], bb[1]), nil
+}
+
+// renameFileSpec replaces the file name id of the file specification o by id1.
+func (ctx *Context) renameFileSpec(o Object, id, id1 string) error {
+	d, err := ctx.DereferenceDict(o)
+	if err != nil || d == nil {
+		return err
+	}
+	for _, k := range []string{"UF", "F"} {
+		o, found := d.Find(k)
+		if !found {
+			continue
+		}
+		s, err := ctx.DereferenceText(o)
+		if err != nil || s != id {
+			continue
+		}
+		sl, err := textLiteral(id1)
+		if err != nil {
+			return err
+		}
+		d[k] = sl
+	}
+	return nil
+}
+
+// sameJavaScript returns true if the JavaScript actions o1 and o2 execute the same script.
+func (ctx *Context) sameJavaScript(o1, o2 Object) (bool, error) {
+	var ss [2]string
+	for i, o := range []Object{o1, o2} {
+		d, err := ctx.DereferenceDict(o)
+		if err != nil || d == nil {
+			return false, err
+		}
+		o, err := ctx.Dereference(d["JS"])
+		if err != nil {
+			return false, err
+		}
+		switch js := o.(type) {
+		case StreamDict:
+			ss[i] = string(js.Raw)
+		case StringLiteral, HexLiteral:
+			if ss[i], err = Text(js); err != nil {
+				return false, err
+			}
+		}
+	}
+	return ss[0] == ss[1], nil
+}
+
+// addRenamedOnCollision adds v for key k to the name tree n.
+// On key collision v gets skipped if same reports a duplicate,
+// otherwise it is added using a unique key like "name (2)" or "name (2).ext" if keepExt is set
+// and rename gets called for the new name.
+func (ctx *Context) addRenamedOnCollision(n *Node, k string, v Object, keepExt bool, same func(v1, v2 Object) (bool, error), rename func(id, id1 string) error) error {
+	v1, found := n.Value(k)
+	if !found {
+		return n.Add(ctx.XRefTable, k, v)
+	}
+
+	id, err := StringLiteralToString(StringLiteral(k))
+	if err != nil {
+		id = k
+	}
+	stem, ext := id, ""
+	if keepExt {
+		ext = filepath.Ext(id)
+		stem = strings.TrimSuffix(id, ext)
+	}
+
+	for i := 2; ; i++ {
+		dupl, err := same(v1, v)
+		if err != nil {
+			return err
+		}
+		if dupl {
+			log.Info.Printf("mergeNames: skipping duplicate %s\n", id)
+			return nil
+		}
+
+		id1 := fmt.Sprintf("%s (%d)%s", stem, i, ext)
+		sl, err := textLiteral(id1)
+		if err != nil {
+			return err
+		}
+		if v1, found = n.Value(string(sl)); found {
+			continue
+		}
+
+		log.Info.Printf("mergeNames: renaming %s to %s\n", id, id1)
+		if rename != nil {
+			if err := rename(id, id1); err != nil {
+				return err
+			}
+		}
+		return n.Add(ctx.XRefTable, string(sl), v)
+	}
+}
+
+// mergeNames adds the entries of all name trees of ctxSource to the corresponding name trees of ctxDest.
+// On key collision the entry of ctxDest is kept, colliding embedded files get renamed unless they are duplicates.
+// Document level JavaScript of ctxSource gets dropped unless ctxDest.MergeJavaScript is set,
+// in which case colliding scripts get renamed unless they are duplicates.
+func mergeNames(ctxSource, ctxDest *Context) error {
+	rootDict, err := ctxSource.Catalog()
+	if err != nil {
+		return err
+	}
+
+	namesSrc, err := ctxSource.DereferenceDict(rootDict["Names"])
+	if err != nil || namesSrc == nil {
+		return err
+	}
+
+	names := make([]string, 0, len(namesSrc))
+	for name := range namesSrc {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+
+		if name == "JavaScript" && !ctxDest.MergeJavaScript {
+			log.Info.Println("mergeNames: dropping document level JavaScript")
+			continue
+		}
+
+		if err := ctxDest.LocateNameTree(name, true); err != nil {
+			return err
+		}
+		n := ctxDest.Names[name]
+
+		add := func(k string, v Object) error {
+			switch name {
+			case "EmbeddedFiles":
+				rename := func(id, id1 string) error { return ctxDest.renameFileSpec(v, id, id1) }
+				return ctxDest.addRenamedOnCollision(n, k, v, true, ctxDest.sameEmbeddedFile, rename)
+			case "JavaScript":
+				return ctxDest.addRenamedOnCollision(n, k, v, false, ctxDest.sameJavaScript, nil)
+			}
+			if _, found := n.Value(k); found {
+				log.Info.Printf("mergeNames: %s: skipping colliding key %s\n", name, k)
+				return nil
+			}
+			return n.Add(ctxDest.XRefTable, k, v)
+		}
+
+		if err := ctxSource.processNameTreeDict(namesSrc[name], add, IntSet{}); err != nil {
+			return err
+		}
+
+		// Sync the catalog since validating a merged context rebuilds its name tree cache.
+		if err := ctxDest.bindNameTreeNode(name, n, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,447 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// mergedFile describes a merged file by the title of its outline node and its first page.
+type mergedFile struct {
+	title  string
+	pageNr int
+}
+
+// outlineTitle returns the title of the outline node representing a merged file.
+func (ctx *Context) outlineTitle() string {
+	if ctx.Read != nil && ctx.Read.FileName != "" {
+		return filepath.Base(ctx.Read.FileName)
+	}
+	if ctx.Title != "" {
+		return ctx.Title
+	}
+	return "Untitled"
+}
+
+// outlinesDict returns the outline dictionary of ctx, creating it if necessary.
+func (ctx *Context) outlinesDict() (Dict, *IndirectRef, error) {
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ir := rootDict.IndirectRefEntry("Outlines"); ir != nil {
+		d, err := ctx.DereferenceDict(*ir)
+		if err != nil {
+			return nil, nil, err
+		}
+		if d != nil {
+			return d, ir, nil
+		}
+	}
+
+	d := Dict(map[string]Object{"Type": Name("Outlines")})
+	ir, err := ctx.IndRefForNewObject(d)
+	if err != nil {
+		return nil, nil, err
+	}
+	rootDict.Update("Outlines", *ir)
+
+	return d, ir, nil
+}
+
+// outlineItems returns the first and last top level outline item of ctx
+// and the number of visible outline items.
+func (ctx *Context) outlineItems() (*IndirectRef, *IndirectRef, int, error) {
+
+	ir, err := ctx.Outlines()
+	if err != nil || ir == nil {
+		return nil, nil, 0, err
+	}
+
+	d, err := ctx.DereferenceDict(*ir)
+	if err != nil || d == nil {
+		return nil, nil, 0, err
+	}
+
+	first := d.IndirectRefEntry("First")
+	last := d.IndirectRefEntry("Last")
+	if first == nil || last == nil {
+		return nil, nil, 0, nil
+	}
+
+	count := 0
+	if i := d.IntEntry("Count"); i != nil {
+		count = *i
+	}
+	if count < 0 {
+		count = -count
+	}
+
+	if count == 0 {
+		// Count the top level items.
+		visited := IntSet{}
+		for ir := first; ir != nil; {
+			objNr := ir.ObjectNumber.Value()
+			if visited[objNr] {
+				return nil, nil, 0, errCorruptedBookmarks
+			}
+			visited[objNr] = true
+			count++
+			d, err := ctx.DereferenceDict(*ir)
+			if err != nil || d == nil {
+				return nil, nil, 0, err
+			}
+			ir = d.IndirectRefEntry("Next")
+		}
+	}
+
+	return first, last, count, nil
+}
+
+// namedDestination returns the explicit destination array for a named destination of ctx.
+func (ctx *Context) namedDestination(o Object) (Array, error) {
+
+	var key string
+
+	switch o := o.(type) {
+	case Name:
+		key = o.Value()
+	case StringLiteral:
+		s, err := StringLiteralToString(o)
+		if err != nil {
+			return nil, err
+		}
+		key = s
+	case HexLiteral:
+		s, err := HexLiteralToString(o)
+		if err != nil {
+			return nil, err
+		}
+		key = s
+	default:
+		return nil, nil
+	}
+
+	var dest Object
+
+	if t := ctx.Names["Dests"]; t != nil {
+		dest, _ = t.Value(key)
+	}
+
+	if dest == nil {
+		// PDF 1.1 style named destinations.
+		rootDict, err := ctx.Catalog()
+		if err != nil {
+			return nil, err
+		}
+		d, err := ctx.DereferenceDict(rootDict["Dests"])
+		if err != nil || d == nil {
+			return nil, err
+		}
+		dest = d[key]
+	}
+
+	dest, err := ctx.Dereference(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	if d, ok := dest.(Dict); ok {
+		if dest, err = ctx.Dereference(d["D"]); err != nil {
+			return nil, err
+		}
+	}
+
+	a, _ := dest.(Array)
+
+	return a, nil
+}
+
+// resolveOutlineDestinations replaces named destinations of all outline items starting at ir by explicit destinations.
+// Named destinations do not survive a merge whereas explicit destinations refer to the merged page objects.
+func (ctx *Context) resolveOutlineDestinations(ir *IndirectRef, visited IntSet) error {
+
+	for ; ir != nil; ir = ctx.nextOutlineItem(ir) {
+
+		objNr := ir.ObjectNumber.Value()
+		if visited[objNr] {
+			return errCorruptedBookmarks
+		}
+		visited[objNr] = true
+
+		d, err := ctx.DereferenceDict(*ir)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			return nil
+		}
+
+		if o, found := d.Find("Dest"); found {
+			o, err = ctx.Dereference(o)
+			if err != nil {
+				return err
+			}
+			a, err := ctx.namedDestination(o)
+			if err != nil {
+				return err
+			}
+			if a != nil {
+				d.Update("Dest", a)
+			}
+		}
+
+		act, err := ctx.DereferenceDict(d["A"])
+		if err != nil {
+			return err
+		}
+		if act != nil && act.NameEntry("S") != nil && *act.NameEntry("S") == "GoTo" {
+			o, err := ctx.Dereference(act["D"])
+			if err != nil {
+				return err
+			}
+			a, err := ctx.namedDestination(o)
+			if err != nil {
+				return err
+			}
+			if a != nil {
+				act.Update("D", a)
+			}
+		}
+
+		if err := ctx.resolveOutlineDestinations(d.IndirectRefEntry("First"), visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ctx *Context) nextOutlineItem(ir *IndirectRef) *IndirectRef {
+	d, err := ctx.DereferenceDict(*ir)
+	if err != nil || d == nil {
+		return nil
+	}
+	return d.IndirectRefEntry("Next")
+}
+
+// setOutlineParent sets the parent of all siblings starting at first.
+func (ctx *Context) setOutlineParent(first *IndirectRef, parent IndirectRef) error {
+	visited := IntSet{}
+	for ir := first; ir != nil; ir = ctx.nextOutlineItem(ir) {
+		objNr := ir.ObjectNumber.Value()
+		if visited[objNr] {
+			return errCorruptedBookmarks
+		}
+		visited[objNr] = true
+		d, err := ctx.DereferenceDict(*ir)
+		if err != nil {
+			return err
+		}
+		d.Update("Parent", parent)
+	}
+	return nil
+}
+
+// appendOutlineItems appends the siblings first..last having count visible items to the top level of the outline of ctx.
+func (ctx *Context) appendOutlineItems(first, last *IndirectRef, count int) error {
+
+	d, ir, err := ctx.outlinesDict()
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.setOutlineParent(first, *ir); err != nil {
+		return err
+	}
+
+	c := 0
+	if i := d.IntEntry("Count"); i != nil {
+		c = *i
+	}
+	if c < 0 {
+		c = -c
+	}
+
+	lastDest := d.IndirectRefEntry("Last")
+	if lastDest == nil {
+		d.Update("First", *first)
+	} else {
+		dLast, err := ctx.DereferenceDict(*lastDest)
+		if err != nil {
+			return err
+		}
+		dFirst, err := ctx.DereferenceDict(*first)
+		if err != nil {
+			return err
+		}
+		dLast.Update("Next", *first)
+		dFirst.Update("Prev", *lastDest)
+	}
+
+	d.Update("Last", *last)
+	d.Update("Count", Integer(c+count))
+
+	return nil
+}
+
+// outlineNode returns a new outline item for a merged file pointing to page pageNr of ctx
+// and holding the siblings first..last having count visible items as children.
+func (ctx *Context) outlineNode(title string, pageNr int, first, last *IndirectRef, count int) (*IndirectRef, error) {
+
+	_, pageIndRef, _, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+	if pageIndRef == nil {
+		return nil, errors.Errorf("pdfcpu: unknown page number: %d\n", pageNr)
+	}
+
+	sl, err := textLiteral(title)
+	if err != nil {
+		return nil, err
+	}
+
+	d := Dict(map[string]Object{
+		"Title": sl,
+		"Dest":  Array{*pageIndRef, Name("Fit")},
+	})
+
+	ir, err := ctx.IndRefForNewObject(d)
+	if err != nil {
+		return nil, err
+	}
+
+	if first != nil {
+		if err := ctx.setOutlineParent(first, *ir); err != nil {
+			return nil, err
+		}
+		d["First"] = *first
+		d["Last"] = *last
+		d["Count"] = Integer(count)
+	}
+
+	return ir, nil
+}
+
+// nestOutline moves the outline of ctx underneath a single top level node titled title.
+func (ctx *Context) nestOutline(title string) error {
+
+	first, last, count, err := ctx.outlineItems()
+	if err != nil {
+		return err
+	}
+
+	ir, err := ctx.outlineNode(title, 1, first, last, count)
+	if err != nil {
+		return err
+	}
+
+	d, _, err := ctx.outlinesDict()
+	if err != nil {
+		return err
+	}
+
+	delete(d, "First")
+	delete(d, "Last")
+	delete(d, "Count")
+
+	return ctx.appendOutlineItems(ir, ir, 1+count)
+}
+
+// nestOutlines nests the outline of ctx and adds outline nodes for the files merged so far.
+func (ctx *Context) nestOutlines() error {
+
+	if err := ctx.nestOutline(ctx.outlineTitle()); err != nil {
+		return err
+	}
+
+	for _, f := range ctx.mergedFiles {
+		ir, err := ctx.outlineNode(f.title, f.pageNr, nil, nil, 0)
+		if err != nil {
+			return err
+		}
+		if err := ctx.appendOutlineItems(ir, ir, 1); err != nil {
+			return err
+		}
+	}
+
+	ctx.mergedFiles = nil
+	ctx.outlinesNested = true
+
+	return nil
+}
+
+// mergeOutlines merges the outline of ctxSource into the outline of ctxDest according to ctxDest.MergeOutlines.
+// The pages and objects of ctxSource are expected to be part of ctxDest already.
+// No outline gets created unless any merged file has one or ctxDest.MergeOutlines is MergeOutlinesFiles.
+func mergeOutlines(ctxSource, ctxDest *Context) error {
+
+	if ctxDest.MergeOutlines == MergeOutlinesNone {
+		return nil
+	}
+
+	if err := ctxSource.LocateNameTree("Dests", false); err != nil {
+		return err
+	}
+
+	first, last, count, err := ctxSource.outlineItems()
+	if err != nil {
+		return err
+	}
+
+	if first != nil {
+		if err := ctxSource.resolveOutlineDestinations(first, IntSet{}); err != nil {
+			return err
+		}
+	}
+
+	if ctxDest.MergeOutlines == MergeOutlinesFlat {
+		if first == nil {
+			return nil
+		}
+		return ctxDest.appendOutlineItems(first, last, count)
+	}
+
+	pageNr := ctxDest.PageCount - ctxSource.PageCount + 1
+
+	if !ctxDest.outlinesNested {
+		destFirst, _, _, err := ctxDest.outlineItems()
+		if err != nil {
+			return err
+		}
+		if first == nil && destFirst == nil && ctxDest.MergeOutlines != MergeOutlinesFiles {
+			// Postpone nesting until a merged file brings an outline.
+			ctxDest.mergedFiles = append(ctxDest.mergedFiles, mergedFile{title: ctxSource.outlineTitle(), pageNr: pageNr})
+			return nil
+		}
+		if err := ctxDest.nestOutlines(); err != nil {
+			return err
+		}
+	}
+
+	ir, err := ctxDest.outlineNode(ctxSource.outlineTitle(), pageNr, first, last, count)
+	if err != nil {
+		return err
+	}
+
+	return ctxDest.appendOutlineItems(ir, ir, 1+count)
+}
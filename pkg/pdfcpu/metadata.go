@@ -0,0 +1,283 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+)
+
+// DocumentMetadata represents the document level metadata
+// kept in the document information dict and in the XMP metadata stream of the catalog.
+type DocumentMetadata struct {
+	Title        string
+	Author       string
+	Subject      string
+	Keywords     string
+	Creator      string // The application that created the original document.
+	Producer     string // The application that converted it to PDF.
+	CreationDate time.Time
+	ModDate      time.Time
+}
+
+// mergeMissing fills all fields of m not set yet with the corresponding values of m1.
+func (m *DocumentMetadata) mergeMissing(m1 DocumentMetadata) {
+	for _, p := range xmpProperties {
+		if p.text != nil {
+			if f := p.text(m); *f == "" {
+				*f = *p.text(&m1)
+			}
+			continue
+		}
+		if f := p.date(m); f.IsZero() {
+			*f = *p.date(&m1)
+		}
+	}
+}
+
+// infoDictMetadata returns the metadata recorded in the document information dict.
+func (ctx *Context) infoDictMetadata() (DocumentMetadata, error) {
+	var m DocumentMetadata
+
+	if ctx.Info == nil {
+		return m, nil
+	}
+
+	d, err := ctx.DereferenceDict(*ctx.Info)
+	if err != nil || d == nil {
+		return m, err
+	}
+
+	for k, f := range map[string]*string{
+		"Title":    &m.Title,
+		"Author":   &m.Author,
+		"Subject":  &m.Subject,
+		"Keywords": &m.Keywords,
+		"Creator":  &m.Creator,
+		"Producer": &m.Producer,
+	} {
+		o, found := d.Find(k)
+		if !found {
+			continue
+		}
+		if *f, err = ctx.DereferenceText(o); err != nil {
+			return m, err
+		}
+	}
+
+	for k, f := range map[string]*time.Time{
+		"CreationDate": &m.CreationDate,
+		"ModDate":      &m.ModDate,
+	} {
+		o, found := d.Find(k)
+		if !found {
+			continue
+		}
+		s, err := ctx.DereferenceText(o)
+		if err != nil {
+			return m, err
+		}
+		if t, ok := DateTime(s, true); ok {
+			*f = t
+		}
+	}
+
+	return m, nil
+}
+
+// xmpMetadata returns the decoded XMP metadata stream of the catalog.
+func (ctx *Context) xmpMetadata() (*StreamDict, error) {
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	o, found := rootDict.Find("Metadata")
+	if !found || o == nil {
+		return nil, nil
+	}
+
+	sd, _, err := ctx.DereferenceStreamDict(o)
+	if err != nil || sd == nil {
+		return nil, err
+	}
+
+	if err := sd.Decode(); err != nil {
+		return nil, err
+	}
+
+	return sd, nil
+}
+
+// DocumentMetadata returns the document metadata of ctx.
+// Values of the document information dict take precedence,
+// missing values are taken from the XMP metadata stream.
+func (ctx *Context) DocumentMetadata() (*DocumentMetadata, error) {
+	m, err := ctx.infoDictMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	sd, err := ctx.xmpMetadata()
+	if err == filter.ErrUnsupportedFilter {
+		return &m, nil
+	}
+	if err != nil || sd == nil {
+		return &m, err
+	}
+
+	m1, err := parseXMP(sd.Content)
+	if err != nil {
+		// Corrupt XMP is no reason to fail.
+		log.Info.Printf("DocumentMetadata: ignoring XMP metadata: %v\n", err)
+		return &m, nil
+	}
+
+	m.mergeMissing(m1)
+
+	return &m, nil
+}
+
+// updateXMPMetadata writes all values set in m into the XMP metadata stream of the catalog.
+// If create is true a missing or corrupt metadata stream gets replaced by a new one.
+func (ctx *Context) updateXMPMetadata(m DocumentMetadata, create bool) error {
+	vv := m.xmpValues()
+	if len(vv) == 0 {
+		return nil
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+
+	o, found := rootDict.Find("Metadata")
+	if !found || o == nil {
+		if !create {
+			return nil
+		}
+		b, err := patchXMP([]byte(xmpPacket), vv)
+		if err != nil {
+			return err
+		}
+		sd := StreamDict{Dict: NewDict(), Content: b}
+		sd.InsertName("Type", "Metadata")
+		sd.InsertName("Subtype", "XML")
+		if err := sd.Encode(); err != nil {
+			return err
+		}
+		ir, err := ctx.IndRefForNewObject(sd)
+		if err != nil {
+			return err
+		}
+		rootDict["Metadata"] = *ir
+		return nil
+	}
+
+	sd, err := ctx.xmpMetadata()
+	if err != nil || sd == nil {
+		return err
+	}
+
+	b, err := patchXMP(sd.Content, vv)
+	if err != nil {
+		if !create {
+			return err
+		}
+		if b, err = patchXMP([]byte(xmpPacket), vv); err != nil {
+			return err
+		}
+	}
+
+	sd.Content = b
+	if err := sd.Encode(); err != nil {
+		return err
+	}
+
+	ir, ok := o.(IndirectRef)
+	if !ok {
+		rootDict["Metadata"] = *sd
+		return nil
+	}
+
+	entry, found := ctx.FindTableEntryForIndRef(&ir)
+	if !found {
+		return nil
+	}
+	entry.Object = *sd
+
+	return nil
+}
+
+// syncXMPMetadata updates an existing XMP metadata stream with the values of the document information dict.
+// XMP is optional and often produced by third party tools, so any problem is logged and ignored.
+func (ctx *Context) syncXMPMetadata() {
+	m, err := ctx.infoDictMetadata()
+	if err == nil {
+		err = ctx.updateXMPMetadata(m, false)
+	}
+	if err != nil {
+		log.Info.Printf("syncXMPMetadata: skipping XMP metadata: %v\n", err)
+	}
+}
+
+// SetDocumentMetadata updates the document information dict and the XMP metadata stream with all values set in m.
+// Producer and ModDate are ignored since pdfcpu maintains them on write.
+func (ctx *Context) SetDocumentMetadata(m DocumentMetadata) error {
+	m.Producer, m.ModDate = "", time.Time{}
+
+	var d Dict
+
+	if ctx.Info == nil {
+		d = NewDict()
+		ir, err := ctx.IndRefForNewObject(d)
+		if err != nil {
+			return err
+		}
+		ctx.Info = ir
+	} else {
+		var err error
+		if d, err = ctx.DereferenceDict(*ctx.Info); err != nil || d == nil {
+			return err
+		}
+	}
+
+	for k, v := range map[string]string{
+		"Title":    m.Title,
+		"Author":   m.Author,
+		"Subject":  m.Subject,
+		"Keywords": m.Keywords,
+		"Creator":  m.Creator,
+	} {
+		if v == "" {
+			continue
+		}
+		sl, err := textLiteral(v)
+		if err != nil {
+			return err
+		}
+		d.Update(k, sl)
+	}
+
+	if !m.CreationDate.IsZero() {
+		d.Update("CreationDate", StringLiteral(DateString(m.CreationDate)))
+	}
+
+	return ctx.updateXMPMetadata(m, true)
+}
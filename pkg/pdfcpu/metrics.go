@@ -0,0 +1,173 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Metrics represents instrumentation data collected while processing a PDF file.
+type Metrics struct {
+	Operation     string
+	DurRead       float64 // in seconds
+	DurValidate   float64 // in seconds
+	DurOptimize   float64 // in seconds
+	DurWrite      float64 // in seconds
+	DurTotal      float64 // in seconds
+	BytesRead     int64
+	BytesWritten  int64
+	ObjectsParsed int
+	CacheHits     int // Object stream cache hits.
+	CacheMisses   int // Object stream cache misses.
+}
+
+// OperationTiming records the durations of the processing stages of a context.
+type OperationTiming struct {
+	Start       time.Time // Start of reading.
+	DurRead     float64   // in seconds
+	DurValidate float64   // in seconds
+	DurOptimize float64   // in seconds
+	DurWrite    float64   // in seconds
+	published   bool      // true, once metrics got published for this context.
+}
+
+// MetricsSubscriber gets notified about the metrics of every completed operation.
+type MetricsSubscriber interface {
+	OperationCompleted(m Metrics)
+}
+
+var (
+	metricsMutex       sync.Mutex
+	metricsSubscribers []MetricsSubscriber
+)
+
+// SubscribeMetrics registers s for receiving operation metrics.
+func SubscribeMetrics(s MetricsSubscriber) {
+	metricsMutex.Lock()
+	defer metricsMutex.Unlock()
+	metricsSubscribers = append(metricsSubscribers, s)
+}
+
+// UnsubscribeMetrics removes s from the list of metrics subscribers.
+func UnsubscribeMetrics(s MetricsSubscriber) {
+	metricsMutex.Lock()
+	defer metricsMutex.Unlock()
+	for i, s1 := range metricsSubscribers {
+		if s1 == s {
+			metricsSubscribers = append(metricsSubscribers[:i], metricsSubscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// MetricsEnabled returns true if there is at least one metrics subscriber.
+func MetricsEnabled() bool {
+	metricsMutex.Lock()
+	defer metricsMutex.Unlock()
+	return len(metricsSubscribers) > 0
+}
+
+// PublishMetrics notifies all subscribers about m.
+func PublishMetrics(m Metrics) {
+	metricsMutex.Lock()
+	ss := make([]MetricsSubscriber, len(metricsSubscribers))
+	copy(ss, metricsSubscribers)
+	metricsMutex.Unlock()
+
+	for _, s := range ss {
+		s.OperationCompleted(m)
+	}
+}
+
+// PublishOperationMetrics collects the metrics of the operation op processed by ctx and notifies all subscribers.
+// Metrics get published at most once per context.
+func PublishOperationMetrics(ctx *Context, op string, durRead, durVal, durOpt, durWrite, durTotal float64) {
+	if ctx != nil {
+		if ctx.Timing.published {
+			return
+		}
+		ctx.Timing.published = true
+	}
+
+	if !MetricsEnabled() {
+		return
+	}
+
+	m := Metrics{
+		Operation:   op,
+		DurRead:     durRead,
+		DurValidate: durVal,
+		DurOptimize: durOpt,
+		DurWrite:    durWrite,
+		DurTotal:    durTotal,
+	}
+
+	if ctx != nil && ctx.Read != nil {
+		m.BytesRead = ctx.Read.FileSize
+		m.ObjectsParsed = ctx.Read.ObjectsParsed
+		m.CacheHits = ctx.Read.ObjStmCacheHits
+		m.CacheMisses = ctx.Read.ObjStmCacheMisses
+	}
+
+	if ctx != nil && ctx.Write != nil {
+		m.BytesWritten = ctx.Write.FileSize
+	}
+
+	PublishMetrics(m)
+}
+
+// PublishContextMetrics publishes the metrics of the operation op processed by ctx
+// using the stage durations recorded in ctx.Timing.
+func PublishContextMetrics(ctx *Context, op string) {
+	if ctx == nil || ctx.Timing.published {
+		return
+	}
+	t := ctx.Timing
+	durTotal := t.DurRead + t.DurValidate + t.DurOptimize + t.DurWrite
+	if !t.Start.IsZero() {
+		durTotal = time.Since(t.Start).Seconds()
+	}
+	PublishOperationMetrics(ctx, op, t.DurRead, t.DurValidate, t.DurOptimize, t.DurWrite, durTotal)
+}
+
+func (m Metrics) String() string {
+	return fmt.Sprintf("%s:\n"+
+		"read                 : %6.3fs\n"+
+		"validate             : %6.3fs\n"+
+		"optimize             : %6.3fs\n"+
+		"write                : %6.3fs\n"+
+		"total processing time: %6.3fs\n"+
+		"bytes read           : %d\n"+
+		"bytes written        : %d\n"+
+		"objects parsed       : %d\n"+
+		"objstm cache hits    : %d\n"+
+		"objstm cache misses  : %d\n",
+		m.Operation,
+		m.DurRead,
+		m.DurValidate,
+		m.DurOptimize,
+		m.DurWrite,
+		m.DurTotal,
+		m.BytesRead,
+		m.BytesWritten,
+		m.ObjectsParsed,
+		m.CacheHits,
+		m.CacheMisses,
+	)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FormatFloat returns f using at most precision decimal places, without trailing zeros if trim is set.
+// A precision of 0 returns the shortest representation reading back as f.
+// A decimal place is always kept so f reads back as a real.
+func FormatFloat(f float64, precision int, trim bool) string {
+	var s string
+	if precision <= 0 {
+		s = strconv.FormatFloat(f, 'f', -1, 64)
+	} else {
+		s = strconv.FormatFloat(f, 'f', precision, 64)
+		if trim {
+			s = strings.TrimRight(s, "0")
+		}
+	}
+	if !strings.Contains(s, ".") {
+		s += "."
+	}
+	if strings.HasSuffix(s, ".") {
+		s += "0"
+	}
+	if strings.HasPrefix(s, "-") && strings.Trim(s, "-0.") == "" {
+		// Rounded to zero.
+		s = s[1:]
+	}
+	return s
+}
+
+// formattedFloat is a real number preformatted for writing.
+type formattedFloat string
+
+func (f formattedFloat) String() string {
+	return string(f)
+}
+
+// Clone returns a clone of f.
+func (f formattedFloat) Clone() Object {
+	return f
+}
+
+// PDFString returns a string representation as written to a PDF file.
+func (f formattedFloat) PDFString() string {
+	return string(f)
+}
+
+// objectInPrecision returns a copy of o with all reals formatted using precision.
+// Reals nested in dicts and arrays are taken into account, indirect objects are not.
+func objectInPrecision(o Object, precision int, trim bool) Object {
+	switch o := o.(type) {
+
+	case Float:
+		return formattedFloat(FormatFloat(o.Value(), precision, trim))
+
+	case Dict:
+		d := NewDict()
+		for k, v := range o {
+			d[k] = objectInPrecision(v, precision, trim)
+		}
+		return d
+
+	case StreamDict:
+		o.Dict = objectInPrecision(o.Dict, precision, trim).(Dict)
+		return o
+
+	case Array:
+		a := make(Array, len(o))
+		for i, v := range o {
+			a[i] = objectInPrecision(v, precision, trim)
+		}
+		return a
+	}
+
+	return o
+}
+
+// rewritesObjects returns true if objects need to be transformed by writeForm before being written.
+func (ctx *Context) rewritesObjects() bool {
+	return ctx.StringForm != StringFormPreserve || ctx.FloatPrecision > 0
+}
+
+// writeForm returns o in the string form and number format configured for writing.
+func (ctx *Context) writeForm(o Object) Object {
+	if ctx.StringForm != StringFormPreserve {
+		o = objectInForm(o, ctx.StringForm)
+	}
+	if ctx.FloatPrecision > 0 {
+		o = objectInPrecision(o, ctx.FloatPrecision, ctx.TrimFloatZeros)
+	}
+	return o
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestFormatFloat(t *testing.T) {
+	x, y := 0.1, 0.2
+	for _, tt := range []struct {
+		f         float64
+		precision int
+		trim      bool
+		want      string
+	}{
+		{1, 0, false, "1.0"},
+		{x + y, 0, false, "0.30000000000000004"},
+		{1e-7, 0, false, "0.0000001"},
+		{-0.0, 0, false, "0.0"},
+		{12.5, 2, false, "12.50"},
+		{12.5, 2, true, "12.5"},
+		{12, 2, true, "12.0"},
+		{-0.001, 2, false, "0.00"},
+		{-0.001, 2, true, "0.0"},
+		{-1.005, 5, true, "-1.005"},
+		{x + y, 12, true, "0.3"},
+	} {
+		if got := FormatFloat(tt.f, tt.precision, tt.trim); got != tt.want {
+			t.Errorf("%v %d %t: want %s, got %s\n", tt.f, tt.precision, tt.trim, tt.want, got)
+		}
+	}
+
+	// The default reads back as the same value.
+	for _, f := range []float64{0.1, 1.0 / 3, 595.2755905511812, -1e-12, 1e21} {
+		f1, err := strconv.ParseFloat(FormatFloat(f, 0, false), 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f1 != f {
+			t.Errorf("want %v, got %v\n", f, f1)
+		}
+	}
+}
+
+func TestObjectInPrecision(t *testing.T) {
+	o := Dict{
+		"MediaBox": Array{Integer(0), Integer(0), Float(595.2755905511812), Float(841.8897637795276)},
+		"Matrix":   Array{Float(0.001), Float(0), Float(0), Float(0.001), Float(0), Float(0)},
+	}
+
+	o1 := objectInPrecision(o, 2, true).(Dict)
+	if s := o1.PDFString(); s != "<</Matrix[0.0 0.0 0.0 0.0 0.0 0.0]/MediaBox[0 0 595.28 841.89]>>" {
+		t.Errorf("got %s\n", s)
+	}
+
+	// o is untouched.
+	if _, ok := o["MediaBox"].(Array)[2].(Float); !ok {
+		t.Errorf("source modified\n")
+	}
+}
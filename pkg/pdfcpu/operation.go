@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"sort"
+	"sync"
+)
+
+// Operation is a custom transformation of a PDF document
+// which may be registered and invoked by name via api.RunOperation or "pdfcpu run".
+type Operation interface {
+	// Name identifies the operation.
+	Name() string
+
+	// Validate checks conf before the document is read.
+	Validate(conf *Configuration) error
+
+	// Execute applies the operation to ctx.
+	Execute(ctx *Context) error
+}
+
+var (
+	operationsMu sync.RWMutex
+	operations   = map[string]Operation{}
+)
+
+// RegisterOperation registers op replacing any operation registered under the same name.
+func RegisterOperation(op Operation) {
+	operationsMu.Lock()
+	defer operationsMu.Unlock()
+	operations[op.Name()] = op
+}
+
+// UnregisterOperation removes the operation registered under name.
+func UnregisterOperation(name string) {
+	operationsMu.Lock()
+	defer operationsMu.Unlock()
+	delete(operations, name)
+}
+
+// LookupOperation returns the operation registered under name.
+func LookupOperation(name string) (Operation, bool) {
+	operationsMu.RLock()
+	defer operationsMu.RUnlock()
+	op, ok := operations[name]
+	return op, ok
+}
+
+// OperationNames returns the sorted names of all registered operations.
+func OperationNames() []string {
+	operationsMu.RLock()
+	defer operationsMu.RUnlock()
+	ss := make([]string, 0, len(operations))
+	for name := range operations {
+		ss = append(ss, name)
+	}
+	sort.Strings(ss)
+	return ss
+}
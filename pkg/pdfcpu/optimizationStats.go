@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import "fmt"
+
+// Stream data categories of optimization stats.
+const (
+	binaryFont = iota
+	binaryImage
+	binaryOther
+)
+
+// binaryCategory returns the stats category of the stream data of sd
+// or -1 for object streams and xref streams which get generated while writing.
+func binaryCategory(sd StreamDict) int {
+	if t := sd.Type(); t != nil && (*t == "XRef" || *t == "ObjStm") {
+		return -1
+	}
+
+	if st := sd.Subtype(); st != nil {
+		switch *st {
+		case "Image":
+			return binaryImage
+		case "Type1C", "CIDFontType0C", "OpenType":
+			return binaryFont
+		}
+	}
+
+	for _, k := range []string{"Length1", "Length2", "Length3"} {
+		if _, found := sd.Find(k); found {
+			// Embedded TrueType or Type1 font file.
+			return binaryFont
+		}
+	}
+
+	return binaryOther
+}
+
+func streamLength(sd StreamDict) int64 {
+	if sd.StreamLength != nil {
+		return *sd.StreamLength
+	}
+	return int64(len(sd.Raw))
+}
+
+// binarySizes returns the size of all stream data of xRefTable by category.
+func binarySizes(xRefTable *XRefTable) [3]int64 {
+	var sizes [3]int64
+	for _, e := range xRefTable.Table {
+		if e == nil || e.Free {
+			continue
+		}
+		sd, ok := e.Object.(StreamDict)
+		if !ok {
+			continue
+		}
+		if c := binaryCategory(sd); c >= 0 {
+			sizes[c] += streamLength(sd)
+		}
+	}
+	return sizes
+}
+
+// OptimizationStats represents the stream data saved by optimizing a document.
+// Savings result from removing duplicate and unused resources, dropping unreferenced objects and recompressing streams.
+type OptimizationStats struct {
+	Fonts  int64 `json:"fonts"`  // Bytes saved on embedded font files.
+	Images int64 `json:"images"` // Bytes saved on images.
+	Other  int64 `json:"other"`  // Bytes saved on content streams, form XObjects and any other stream.
+}
+
+// Total returns the overall number of bytes saved.
+func (s OptimizationStats) Total() int64 {
+	return s.Fonts + s.Images + s.Other
+}
+
+func (s OptimizationStats) String() string {
+	return fmt.Sprintf("saved fonts: %s, images: %s, other: %s, total: %s",
+		ByteSize(s.Fonts), ByteSize(s.Images), ByteSize(s.Other), ByteSize(s.Total()))
+}
+
+// OptimizationStats returns the stream data saved per category by optimizing and writing ctx.
+func (ctx *Context) OptimizationStats() OptimizationStats {
+	if ctx.Optimize == nil || ctx.Write == nil {
+		return OptimizationStats{}
+	}
+	before := ctx.Optimize.binarySizes
+	after := ctx.Write.binarySizes
+	return OptimizationStats{
+		Fonts:  before[binaryFont] - after[binaryFont],
+		Images: before[binaryImage] - after[binaryImage],
+		Other:  before[binaryOther] - after[binaryOther],
+	}
+}
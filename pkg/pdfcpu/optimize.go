@@ -21,17 +21,18 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
 	"github.com/pdfcpu/pdfcpu/pkg/log"
 	"github.com/pkg/errors"
 )
 
 // Mark all content streams for a page dictionary (for stats).
 func identifyPageContent(xRefTable *XRefTable, pageDict Dict, pageObjNumber int) error {
-	log.Optimize.Println("identifyPageContent begin")
+	xRefTable.Log.Optimize().Println("identifyPageContent begin")
 
 	o, found := pageDict.Find("Contents")
 	if !found {
-		log.Optimize.Println("identifyPageContent end: no \"Contents\"")
+		xRefTable.Log.Optimize().Println("identifyPageContent end: no \"Contents\"")
 		return nil
 	}
 
@@ -48,7 +49,7 @@ func identifyPageContent(xRefTable *XRefTable, pageDict Dict, pageObjNumber int)
 		if ok {
 			contentStreamDict.IsPageContent = true
 			entry.Object = contentStreamDict
-			log.Optimize.Printf("identifyPageContent end: ok obj#%d\n", ir.ObjectNumber.Value())
+			xRefTable.Log.Optimize().Printf("identifyPageContent end: ok obj#%d\n", ir.ObjectNumber.Value())
 			return nil
 		}
 
@@ -80,10 +81,10 @@ func identifyPageContent(xRefTable *XRefTable, pageDict Dict, pageObjNumber int)
 
 		contentStreamDict.IsPageContent = true
 		entry.Object = contentStreamDict
-		log.Optimize.Printf("identifyPageContent: ok obj#%d\n", ir.GenerationNumber.Value())
+		xRefTable.Log.Optimize().Printf("identifyPageContent: ok obj#%d\n", ir.GenerationNumber.Value())
 	}
 
-	log.Optimize.Println("identifyPageContent end")
+	xRefTable.Log.Optimize().Println("identifyPageContent end")
 
 	return nil
 }
@@ -92,7 +93,7 @@ func identifyPageContent(xRefTable *XRefTable, pageDict Dict, pageObjNumber int)
 func resourcesDictForPageDict(xRefTable *XRefTable, pageDict Dict, pageObjNumber int) (Dict, error) {
 	o, found := pageDict.Find("Resources")
 	if !found {
-		log.Optimize.Printf("resourcesDictForPageDict end: No resources dict for page object %d, may be inherited\n", pageObjNumber)
+		xRefTable.Log.Optimize().Printf("resourcesDictForPageDict end: No resources dict for page object %d, may be inherited\n", pageObjNumber)
 		return nil, nil
 	}
 
@@ -118,7 +119,7 @@ func handleDuplicateFontObject(ctx *Context, fontDict Dict, fName, rName string,
 		// Get the font object from the lookup table.
 		fontObject := ctx.Optimize.FontObjects[fontObjNr]
 
-		log.Optimize.Printf("handleDuplicateFontObject: comparing with fontDict Obj %d\n", fontObjNr)
+		ctx.Log.Optimize().Printf("handleDuplicateFontObject: comparing with fontDict Obj %d\n", fontObjNr)
 
 		// Check if the input fontDict matches the fontDict of this fontObject.
 		ok, err := equalFontDicts(fontObject.FontDict, fontDict, ctx.XRefTable)
@@ -132,7 +133,7 @@ func handleDuplicateFontObject(ctx *Context, fontDict Dict, fName, rName string,
 		}
 
 		// We have detected a redundant font dict!
-		log.Optimize.Printf("handleDuplicateFontObject: redundant fontObj#:%d basefont %s already registered with obj#:%d !\n", objNr, fName, fontObjNr)
+		ctx.Log.Optimize().Printf("handleDuplicateFontObject: redundant fontObj#:%d basefont %s already registered with obj#:%d !\n", objNr, fName, fontObjNr)
 
 		// Register new page font with pageNumber.
 		// The font for font object number is used instead of objNr.
@@ -223,7 +224,7 @@ func fontName(ctx *Context, fontDict Dict, objNumber int) (prefix, fontName stri
 
 // Get rid of redundant fonts for given fontResources dictionary.
 func optimizeFontResourcesDict(ctx *Context, rDict Dict, pageNumber, pageObjNumber int) error {
-	log.Optimize.Printf("optimizeFontResourcesDict begin: page=%d pageObjNumber=%d %s\nPageFonts=%v\n", pageNumber, pageObjNumber, rDict, ctx.Optimize.PageFonts)
+	ctx.Log.Optimize().Printf("optimizeFontResourcesDict begin: page=%d pageObjNumber=%d %s\nPageFonts=%v\n", pageNumber, pageObjNumber, rDict, ctx.Optimize.PageFonts)
 
 	pageFonts := pageFonts(ctx, pageNumber)
 
@@ -235,9 +236,9 @@ func optimizeFontResourcesDict(ctx *Context, rDict Dict, pageNumber, pageObjNumb
 			continue
 		}
 
-		log.Optimize.Printf("optimizeFontResourcesDict: processing font: %s, %s\n", rName, indRef)
+		ctx.Log.Optimize().Printf("optimizeFontResourcesDict: processing font: %s, %s\n", rName, indRef)
 		objNr := int(indRef.ObjectNumber)
-		log.Optimize.Printf("optimizeFontResourcesDict: objectNumber = %d\n", objNr)
+		ctx.Log.Optimize().Printf("optimizeFontResourcesDict: objectNumber = %d\n", objNr)
 
 		if _, found := ctx.Optimize.FontObjects[objNr]; found {
 			// This font has already been registered.
@@ -256,7 +257,7 @@ func optimizeFontResourcesDict(ctx *Context, rDict Dict, pageNumber, pageObjNumb
 			continue
 		}
 
-		log.Optimize.Printf("optimizeFontResourcesDict: fontDict: %s\n", fontDict)
+		ctx.Log.Optimize().Printf("optimizeFontResourcesDict: fontDict: %s\n", fontDict)
 
 		if fontDict.Type() == nil {
 			return errors.Errorf("pdfcpu: optimizeFontResourcesDict: missing dict type %s\n", v)
@@ -271,7 +272,7 @@ func optimizeFontResourcesDict(ctx *Context, rDict Dict, pageNumber, pageObjNumb
 		if err != nil {
 			return err
 		}
-		log.Optimize.Printf("optimizeFontResourcesDict: baseFont: prefix=%s name=%s\n", prefix, fName)
+		ctx.Log.Optimize().Printf("optimizeFontResourcesDict: baseFont: prefix=%s name=%s\n", prefix, fName)
 
 		// Check if fontDict is a duplicate and if so return the object number of the original.
 		originalObjNr, err := handleDuplicateFontObject(ctx, fontDict, fName, rName, objNr, pageNumber)
@@ -293,11 +294,11 @@ func optimizeFontResourcesDict(ctx *Context, rDict Dict, pageNumber, pageObjNumb
 		}
 
 		// Register new font dict.
-		log.Optimize.Printf("optimizeFontResourcesDict: adding new font %s obj#%d\n", fName, objNr)
+		ctx.Log.Optimize().Printf("optimizeFontResourcesDict: adding new font %s obj#%d\n", fName, objNr)
 
 		fontObjNrs, found := ctx.Optimize.Fonts[fName]
 		if found {
-			log.Optimize.Printf("optimizeFontResourcesDict: appending %d to %s\n", objNr, fName)
+			ctx.Log.Optimize().Printf("optimizeFontResourcesDict: appending %d to %s\n", objNr, fName)
 			ctx.Optimize.Fonts[fName] = append(fontObjNrs, objNr)
 		} else {
 			ctx.Optimize.Fonts[fName] = []int{objNr}
@@ -315,7 +316,7 @@ func optimizeFontResourcesDict(ctx *Context, rDict Dict, pageNumber, pageObjNumb
 
 	}
 
-	log.Optimize.Println("optimizeFontResourcesDict end:")
+	ctx.Log.Optimize().Println("optimizeFontResourcesDict end:")
 
 	return nil
 }
@@ -328,7 +329,7 @@ func handleDuplicateImageObject(ctx *Context, imageDict *StreamDict, resourceNam
 	// Process image dict, check if this is a duplicate.
 	for imageObjNr, imageObject := range ctx.Optimize.ImageObjects {
 
-		log.Optimize.Printf("handleDuplicateImageObject: comparing with imagedict Obj %d\n", imageObjNr)
+		ctx.Log.Optimize().Printf("handleDuplicateImageObject: comparing with imagedict Obj %d\n", imageObjNr)
 
 		// Check if the input imageDict matches the imageDict of this imageObject.
 		ok, err := equalStreamDicts(imageObject.ImageDict, imageDict, ctx.XRefTable)
@@ -342,7 +343,7 @@ func handleDuplicateImageObject(ctx *Context, imageDict *StreamDict, resourceNam
 		}
 
 		// We have detected a redundant image dict.
-		log.Optimize.Printf("handleDuplicateImageObject: redundant imageObj#:%d already registered with obj#:%d !\n", objNr, imageObjNr)
+		ctx.Log.Optimize().Printf("handleDuplicateImageObject: redundant imageObj#:%d already registered with obj#:%d !\n", objNr, imageObjNr)
 
 		// Register new page image for pageNumber.
 		// The image for image object number is used instead of objNr.
@@ -363,7 +364,7 @@ func handleDuplicateImageObject(ctx *Context, imageDict *StreamDict, resourceNam
 
 // Get rid of redundant XObjects e.g. embedded images.
 func optimizeXObjectResourcesDict(ctx *Context, rDict Dict, pageNumber, pageObjNumber int) error {
-	log.Optimize.Printf("optimizeXObjectResourcesDict page#%dbegin: %s\n", pageObjNumber, rDict)
+	ctx.Log.Optimize().Printf("optimizeXObjectResourcesDict page#%dbegin: %s\n", pageObjNumber, rDict)
 	pageImages := pageImages(ctx, pageNumber)
 
 	// Iterate over XObject resource dict.
@@ -374,9 +375,9 @@ func optimizeXObjectResourcesDict(ctx *Context, rDict Dict, pageNumber, pageObjN
 			continue
 		}
 
-		log.Optimize.Printf("optimizeXObjectResourcesDict: processing xobject: %s, %s\n", rName, indRef)
+		ctx.Log.Optimize().Printf("optimizeXObjectResourcesDict: processing xobject: %s, %s\n", rName, indRef)
 		objNr := int(indRef.ObjectNumber)
-		log.Optimize.Printf("optimizeXObjectResourcesDict: objectNumber = %d\n", objNr)
+		ctx.Log.Optimize().Printf("optimizeXObjectResourcesDict: objectNumber = %d\n", objNr)
 
 		// We are dealing with a new XObject..
 		// Dereference the XObject stream dict.
@@ -389,7 +390,7 @@ func optimizeXObjectResourcesDict(ctx *Context, rDict Dict, pageNumber, pageObjN
 			continue
 		}
 
-		log.Optimize.Printf("optimizeXObjectResourcesDict: dereferenced obj:%d\n%s", objNr, osd)
+		ctx.Log.Optimize().Printf("optimizeXObjectResourcesDict: dereferenced obj:%d\n%s", objNr, osd)
 
 		if osd.Dict.Subtype() == nil {
 			return errors.Errorf("pdfcpu: optimizeXObjectResourcesDict: missing stream dict Subtype %s\n", v)
@@ -400,7 +401,7 @@ func optimizeXObjectResourcesDict(ctx *Context, rDict Dict, pageNumber, pageObjN
 			// Already registered image object that appears in different resources dicts.
 			if _, found := ctx.Optimize.ImageObjects[objNr]; found {
 				// This image has already been registered.
-				//log.Optimize.Printf("optimizeXObjectResourcesDict: Imageobject %d already registered\n", objNr)
+				//ctx.Log.Optimize().Printf("optimizeXObjectResourcesDict: Imageobject %d already registered\n", objNr)
 				pageImages[objNr] = true
 				continue
 			}
@@ -425,7 +426,7 @@ func optimizeXObjectResourcesDict(ctx *Context, rDict Dict, pageNumber, pageObjN
 			}
 
 			// Register new image dict.
-			log.Optimize.Printf("optimizeXObjectResourcesDict: adding new image obj#%d\n", objNr)
+			ctx.Log.Optimize().Printf("optimizeXObjectResourcesDict: adding new image obj#%d\n", objNr)
 
 			ctx.Optimize.ImageObjects[objNr] =
 				&ImageObject{
@@ -438,26 +439,26 @@ func optimizeXObjectResourcesDict(ctx *Context, rDict Dict, pageNumber, pageObjN
 		}
 
 		if *osd.Subtype() != "Form" {
-			log.Optimize.Printf("optimizeXObjectResourcesDict: unexpected stream dict Subtype %s\n", *osd.Dict.Subtype())
+			ctx.Log.Optimize().Printf("optimizeXObjectResourcesDict: unexpected stream dict Subtype %s\n", *osd.Dict.Subtype())
 			continue
 		}
 
 		// Process form dict
-		log.Optimize.Printf("optimizeXObjectResourcesDict: parsing form dict obj:%d\n", objNr)
+		ctx.Log.Optimize().Printf("optimizeXObjectResourcesDict: parsing form dict obj:%d\n", objNr)
 		parseResourcesDict(ctx, osd.Dict, pageNumber, objNr)
 	}
 
-	log.Optimize.Println("optimizeXObjectResourcesDict end")
+	ctx.Log.Optimize().Println("optimizeXObjectResourcesDict end")
 
 	return nil
 }
 
 // Optimize given resource dictionary by removing redundant fonts and images.
 func optimizeResources(ctx *Context, resourcesDict Dict, pageNumber, pageObjNumber int) error {
-	log.Optimize.Printf("optimizeResources begin: pageNumber=%d pageObjNumber=%d\n", pageNumber, pageObjNumber)
+	ctx.Log.Optimize().Printf("optimizeResources begin: pageNumber=%d pageObjNumber=%d\n", pageNumber, pageObjNumber)
 
 	if resourcesDict == nil {
-		log.Optimize.Printf("optimizeResources end: No resources dict available")
+		ctx.Log.Optimize().Printf("optimizeResources end: No resources dict available")
 		return nil
 	}
 
@@ -501,7 +502,7 @@ func optimizeResources(ctx *Context, resourcesDict Dict, pageNumber, pageObjNumb
 
 	}
 
-	log.Optimize.Println("optimizeResources end")
+	ctx.Log.Optimize().Println("optimizeResources end")
 
 	return nil
 }
@@ -514,7 +515,7 @@ func parseResourcesDict(ctx *Context, pageDict Dict, pageNumber, pageObjNumber i
 	ctx.Optimize.Cache[pageObjNumber] = true
 
 	// The logical pageNumber is pageNumber+1.
-	log.Optimize.Printf("parseResourcesDict begin page: %d, object:%d\n", pageNumber+1, pageObjNumber)
+	ctx.Log.Optimize().Printf("parseResourcesDict begin page: %d, object:%d\n", pageNumber+1, pageObjNumber)
 
 	// Get resources dict for this page.
 	d, err := resourcesDictForPageDict(ctx.XRefTable, pageDict, pageObjNumber)
@@ -532,7 +533,7 @@ func parseResourcesDict(ctx *Context, pageDict Dict, pageNumber, pageObjNumber i
 
 	}
 
-	log.Optimize.Printf("parseResourcesDict end page: %d, object:%d\n", pageNumber+1, pageObjNumber)
+	ctx.Log.Optimize().Printf("parseResourcesDict end page: %d, object:%d\n", pageNumber+1, pageObjNumber)
 
 	return nil
 }
@@ -540,7 +541,7 @@ func parseResourcesDict(ctx *Context, pageDict Dict, pageNumber, pageObjNumber i
 // Iterate over all pages and optimize resources.
 func parsePagesDict(ctx *Context, pagesDict Dict, pageNumber int) (int, error) {
 	// TODO Integrate resource consolidation based on content stream requirements.
-	log.Optimize.Printf("parsePagesDict begin (next page=%d): %s\n", pageNumber+1, pagesDict)
+	ctx.Log.Optimize().Printf("parsePagesDict begin (next page=%d): %s\n", pageNumber+1, pagesDict)
 
 	// Get number of pages of this PDF file.
 	count, found := pagesDict.Find("Count")
@@ -548,16 +549,20 @@ func parsePagesDict(ctx *Context, pagesDict Dict, pageNumber int) (int, error) {
 		return 0, errors.New("pdfcpu: parsePagesDict: missing Count")
 	}
 
-	log.Optimize.Printf("parsePagesDict: This page node has %d pages\n", int(count.(Integer)))
+	ctx.Log.Optimize().Printf("parsePagesDict: This page node has %d pages\n", int(count.(Integer)))
 
 	ctx.Optimize.Cache = map[int]bool{}
 
 	// Iterate over page tree.
 	for _, v := range pagesDict.ArrayEntry("Kids") {
 
+		if err := ctx.Canceled(); err != nil {
+			return 0, err
+		}
+
 		// Dereference next page node dict.
 		ir, _ := v.(IndirectRef)
-		log.Optimize.Printf("parsePagesDict PageNode: %s\n", ir)
+		ctx.Log.Optimize().Printf("parsePagesDict PageNode: %s\n", ir)
 		o, err := ctx.Dereference(ir)
 		if err != nil {
 			return 0, errors.Wrap(err, "parsePagesDict: can't locate Pagedict or Pagesdict")
@@ -605,7 +610,7 @@ func parsePagesDict(ctx *Context, pagesDict Dict, pageNumber int) (int, error) {
 		pageNumber++
 	}
 
-	log.Optimize.Printf("parsePagesDict end: %s\n", pagesDict)
+	ctx.Log.Optimize().Printf("parsePagesDict end: %s\n", pagesDict)
 
 	return pageNumber, nil
 }
@@ -634,12 +639,12 @@ func traverse(xRefTable *XRefTable, value Object, duplObjs IntSet) error {
 
 // Traverse the object graph for a Object and mark all objects as potential duplicates.
 func traverseObjectGraphAndMarkDuplicates(xRefTable *XRefTable, obj Object, duplObjs IntSet) error {
-	log.Optimize.Printf("traverseObjectGraphAndMarkDuplicates begin type=%T\n", obj)
+	xRefTable.Log.Optimize().Printf("traverseObjectGraphAndMarkDuplicates begin type=%T\n", obj)
 
 	switch x := obj.(type) {
 
 	case Dict:
-		log.Optimize.Println("traverseObjectGraphAndMarkDuplicates: dict.")
+		xRefTable.Log.Optimize().Println("traverseObjectGraphAndMarkDuplicates: dict.")
 		for _, value := range x {
 			if err := traverse(xRefTable, value, duplObjs); err != nil {
 				return err
@@ -647,7 +652,7 @@ func traverseObjectGraphAndMarkDuplicates(xRefTable *XRefTable, obj Object, dupl
 		}
 
 	case StreamDict:
-		log.Optimize.Println("traverseObjectGraphAndMarkDuplicates: streamDict.")
+		xRefTable.Log.Optimize().Println("traverseObjectGraphAndMarkDuplicates: streamDict.")
 		for _, value := range x.Dict {
 			if err := traverse(xRefTable, value, duplObjs); err != nil {
 				return err
@@ -655,7 +660,7 @@ func traverseObjectGraphAndMarkDuplicates(xRefTable *XRefTable, obj Object, dupl
 		}
 
 	case Array:
-		log.Optimize.Println("traverseObjectGraphAndMarkDuplicates: arr.")
+		xRefTable.Log.Optimize().Println("traverseObjectGraphAndMarkDuplicates: arr.")
 		for _, value := range x {
 			if err := traverse(xRefTable, value, duplObjs); err != nil {
 				return err
@@ -663,14 +668,14 @@ func traverseObjectGraphAndMarkDuplicates(xRefTable *XRefTable, obj Object, dupl
 		}
 	}
 
-	log.Optimize.Println("traverseObjectGraphAndMarkDuplicates end")
+	xRefTable.Log.Optimize().Println("traverseObjectGraphAndMarkDuplicates end")
 
 	return nil
 }
 
 // Identify and mark all potential duplicate objects.
 func calcRedundantObjects(ctx *Context) error {
-	log.Optimize.Println("calcRedundantObjects begin")
+	ctx.Log.Optimize().Println("calcRedundantObjects begin")
 
 	for i, fontDict := range ctx.Optimize.DuplicateFonts {
 		ctx.Optimize.DuplicateFontObjs[i] = true
@@ -688,7 +693,7 @@ func calcRedundantObjects(ctx *Context) error {
 		}
 	}
 
-	log.Optimize.Println("calcRedundantObjects end")
+	ctx.Log.Optimize().Println("calcRedundantObjects end")
 
 	return nil
 }
@@ -696,7 +701,7 @@ func calcRedundantObjects(ctx *Context) error {
 // Iterate over all pages and optimize resources.
 // Get rid of duplicate embedded fonts and images.
 func optimizeFontAndImages(ctx *Context) error {
-	log.Optimize.Println("optimizeFontAndImages begin")
+	ctx.Log.Optimize().Println("optimizeFontAndImages begin")
 
 	// Get a reference to the PDF indirect reference of the page tree root dict.
 	indRefPages, err := ctx.Pages()
@@ -741,14 +746,14 @@ func optimizeFontAndImages(ctx *Context) error {
 		return err
 	}
 
-	log.Optimize.Println("optimizeFontAndImages end")
+	ctx.Log.Optimize().Println("optimizeFontAndImages end")
 
 	return nil
 }
 
 // Return stream length for font file object.
 func streamLengthFontFile(xRefTable *XRefTable, indirectRef *IndirectRef) (*int64, error) {
-	log.Optimize.Println("streamLengthFontFile begin")
+	xRefTable.Log.Optimize().Println("streamLengthFontFile begin")
 
 	objectNumber := indirectRef.ObjectNumber
 
@@ -761,14 +766,14 @@ func streamLengthFontFile(xRefTable *XRefTable, indirectRef *IndirectRef) (*int6
 		return nil, errors.Errorf("pdfcpu: streamLengthFontFile: fontFile Streamlength is nil for object %d\n", objectNumber)
 	}
 
-	log.Optimize.Println("streamLengthFontFile end")
+	xRefTable.Log.Optimize().Println("streamLengthFontFile end")
 
 	return (*sd).StreamLength, nil
 }
 
 // Calculate amount of memory used by embedded fonts for stats.
 func calcEmbeddedFontsMemoryUsage(ctx *Context) error {
-	log.Optimize.Printf("calcEmbeddedFontsMemoryUsage begin: %d fontObjects\n", len(ctx.Optimize.FontObjects))
+	ctx.Log.Optimize().Printf("calcEmbeddedFontsMemoryUsage begin: %d fontObjects\n", len(ctx.Optimize.FontObjects))
 
 	fontFileIndRefs := map[IndirectRef]bool{}
 
@@ -804,14 +809,14 @@ func calcEmbeddedFontsMemoryUsage(ctx *Context) error {
 		ctx.Read.BinaryFontSize += *streamLength
 	}
 
-	log.Optimize.Println("calcEmbeddedFontsMemoryUsage end")
+	ctx.Log.Optimize().Println("calcEmbeddedFontsMemoryUsage end")
 
 	return nil
 }
 
 // fontDescriptorFontFileIndirectObjectRef returns the indirect object for the font file for given font descriptor.
-func fontDescriptorFontFileIndirectObjectRef(fontDescriptorDict Dict) *IndirectRef {
-	log.Optimize.Println("fontDescriptorFontFileIndirectObjectRef begin")
+func fontDescriptorFontFileIndirectObjectRef(xRefTable *XRefTable, fontDescriptorDict Dict) *IndirectRef {
+	xRefTable.Log.Optimize().Println("fontDescriptorFontFileIndirectObjectRef begin")
 
 	ir := fontDescriptorDict.IndirectRefEntry("FontFile")
 
@@ -827,7 +832,7 @@ func fontDescriptorFontFileIndirectObjectRef(fontDescriptorDict Dict) *IndirectR
 		//logInfoReader.Printf("FontDescriptorFontFileLength: FontDescriptor dict without fontFile: \n%s\n", fontDescriptorDict)
 	}
 
-	log.Optimize.Println("FontDescriptorFontFileIndirectObjectRef end")
+	xRefTable.Log.Optimize().Println("FontDescriptorFontFileIndirectObjectRef end")
 
 	return ir
 }
@@ -858,7 +863,7 @@ func trivialFontDescriptor(xRefTable *XRefTable, fontDict Dict, objNr int) (Dict
 
 // FontDescriptor gets the font descriptor for this font.
 func fontDescriptor(xRefTable *XRefTable, fontDict Dict, objNr int) (Dict, error) {
-	log.Optimize.Println("fontDescriptor begin")
+	xRefTable.Log.Optimize().Println("fontDescriptor begin")
 
 	d, err := trivialFontDescriptor(xRefTable, fontDict, objNr)
 	if err != nil {
@@ -901,7 +906,7 @@ func fontDescriptor(xRefTable *XRefTable, fontDict Dict, objNr int) (Dict, error
 
 	o, ok = d.Find("FontDescriptor")
 	if !ok {
-		log.Optimize.Printf("fontDescriptor: descendant font not embedded %s\n", d)
+		xRefTable.Log.Optimize().Printf("fontDescriptor: descendant font not embedded %s\n", d)
 		return nil, nil
 	}
 
@@ -910,14 +915,14 @@ func fontDescriptor(xRefTable *XRefTable, fontDict Dict, objNr int) (Dict, error
 		return nil, errors.Errorf("pdfcpu: fontDescriptor: No FontDescriptor dict for font object %d\n", objNr)
 	}
 
-	log.Optimize.Println("fontDescriptor end")
+	xRefTable.Log.Optimize().Println("fontDescriptor end")
 
 	return d, nil
 }
 
 // Record font file objects referenced by this fonts font descriptor for stats and size calculation.
 func processFontFilesForFontDict(xRefTable *XRefTable, fontDict Dict, objectNumber int, indRefsMap map[IndirectRef]bool) error {
-	log.Optimize.Println("processFontFilesForFontDict begin")
+	xRefTable.Log.Optimize().Println("processFontFilesForFontDict begin")
 
 	// Note:
 	// "ToUnicode" is also an entry containing binary content that could be inspected for duplicate content.
@@ -928,19 +933,19 @@ func processFontFilesForFontDict(xRefTable *XRefTable, fontDict Dict, objectNumb
 	}
 
 	if d != nil {
-		if ir := fontDescriptorFontFileIndirectObjectRef(d); ir != nil {
+		if ir := fontDescriptorFontFileIndirectObjectRef(xRefTable, d); ir != nil {
 			indRefsMap[*ir] = true
 		}
 	}
 
-	log.Optimize.Println("processFontFilesForFontDict end")
+	xRefTable.Log.Optimize().Println("processFontFilesForFontDict end")
 
 	return nil
 }
 
 // Calculate amount of memory used by duplicate embedded fonts for stats.
 func calcRedundantEmbeddedFontsMemoryUsage(ctx *Context) error {
-	log.Optimize.Println("calcRedundantEmbeddedFontsMemoryUsage begin")
+	ctx.Log.Optimize().Println("calcRedundantEmbeddedFontsMemoryUsage begin")
 
 	fontFileIndRefs := map[IndirectRef]bool{}
 
@@ -965,14 +970,14 @@ func calcRedundantEmbeddedFontsMemoryUsage(ctx *Context) error {
 		ctx.Read.BinaryFontDuplSize += *streamLength
 	}
 
-	log.Optimize.Println("calcRedundantEmbeddedFontsMemoryUsage end")
+	ctx.Log.Optimize().Println("calcRedundantEmbeddedFontsMemoryUsage end")
 
 	return nil
 }
 
 // Calculate amount of memory used by embedded fonts and duplicate embedded fonts for stats.
 func calcFontBinarySizes(ctx *Context) error {
-	log.Optimize.Println("calcFontBinarySizes begin")
+	ctx.Log.Optimize().Println("calcFontBinarySizes begin")
 
 	if err := calcEmbeddedFontsMemoryUsage(ctx); err != nil {
 		return err
@@ -982,14 +987,14 @@ func calcFontBinarySizes(ctx *Context) error {
 		return err
 	}
 
-	log.Optimize.Println("calcFontBinarySizes end")
+	ctx.Log.Optimize().Println("calcFontBinarySizes end")
 
 	return nil
 }
 
 // Calculate amount of memory used by images and duplicate images for stats.
 func calcImageBinarySizes(ctx *Context) {
-	log.Optimize.Println("calcImageBinarySizes begin")
+	ctx.Log.Optimize().Println("calcImageBinarySizes begin")
 
 	// Calc memory usage for images.
 	for _, imageObject := range ctx.Optimize.ImageObjects {
@@ -1001,12 +1006,12 @@ func calcImageBinarySizes(ctx *Context) {
 		ctx.Read.BinaryImageDuplSize += *imageDict.StreamLength
 	}
 
-	log.Optimize.Println("calcImageBinarySizes end")
+	ctx.Log.Optimize().Println("calcImageBinarySizes end")
 }
 
 // Calculate memory usage of binary data for stats.
 func calcBinarySizes(ctx *Context) error {
-	log.Optimize.Println("calcBinarySizes begin")
+	ctx.Log.Optimize().Println("calcBinarySizes begin")
 
 	// Calculate font memory usage for stats.
 	if err := calcFontBinarySizes(ctx); err != nil {
@@ -1018,7 +1023,7 @@ func calcBinarySizes(ctx *Context) error {
 
 	// Note: Content streams also represent binary content.
 
-	log.Optimize.Println("calcBinarySizes end")
+	ctx.Log.Optimize().Println("calcBinarySizes end")
 
 	return nil
 }
@@ -1142,10 +1147,103 @@ func fixReferencesToFreeObjects(ctx *Context) error {
 	return fixDirectObject(ctx, ctx.RootDict)
 }
 
+// lzwEncoded returns true if sd's filter pipeline uses LZW along with general purpose filters only.
+func lzwEncoded(sd StreamDict) bool {
+	lzw := false
+	for _, f := range sd.FilterPipeline {
+		switch f.Name {
+		case filter.LZW:
+			lzw = true
+		case filter.ASCII85, filter.ASCIIHex, filter.RunLength, filter.Flate:
+		default:
+			// Leave image codecs and crypt filters alone.
+			return false
+		}
+	}
+	return lzw
+}
+
+// uncompressed returns true if sd is a candidate for Flate encoding.
+// XMP metadata is left uncompressed in order to stay readable by non PDF tools.
+func uncompressed(sd StreamDict) bool {
+	if len(sd.FilterPipeline) > 0 || len(sd.Raw) == 0 {
+		return false
+	}
+	if _, found := sd.Find("Filter"); found {
+		return false
+	}
+	if _, found := sd.Find("F"); found {
+		// External stream data.
+		return false
+	}
+	return sd.Type() == nil || *sd.Type() != "Metadata"
+}
+
+// flateEncode replaces the filter pipeline of sd by Flate using content as decoded stream data.
+func flateEncode(sd *StreamDict, content []byte) error {
+	sd.Content = content
+	sd.Update("Filter", Name(filter.Flate))
+	sd.Delete("DecodeParms")
+	sd.FilterPipeline = []PDFFilter{{Name: filter.Flate, DecodeParms: nil}}
+	return sd.Encode()
+}
+
+// recompressStreams rewrites LZW encoded streams as Flate encoded streams.
+// If CompressStreams is set, uncompressed streams get Flate encoded as long as this saves space.
+func recompressStreams(ctx *Context) error {
+	for objNr, e := range ctx.Table {
+		if err := ctx.Canceled(); err != nil {
+			return err
+		}
+
+		if e.Free || e.Object == nil {
+			continue
+		}
+
+		sd, ok := e.Object.(StreamDict)
+		if !ok || sd.Type() != nil && (*sd.Type() == "XRef" || *sd.Type() == "ObjStm") {
+			continue
+		}
+
+		if ctx.CompressStreams && uncompressed(sd) {
+			l := len(sd.Raw)
+			sd1 := sd.Clone().(StreamDict)
+			if err := flateEncode(&sd1, sd.Raw); err != nil {
+				return err
+			}
+			if len(sd1.Raw) < l {
+				ctx.Log.Optimize().Printf("recompressStreams: obj#%d: %d -> %d bytes\n", objNr, l, len(sd1.Raw))
+				e.Object = sd1
+			}
+			continue
+		}
+
+		if !lzwEncoded(sd) {
+			continue
+		}
+
+		if err := sd.Decode(); err != nil {
+			ctx.Log.Optimize().Printf("recompressStreams: obj#%d: %v\n", objNr, err)
+			continue
+		}
+
+		if err := flateEncode(&sd, sd.Content); err != nil {
+			return err
+		}
+
+		e.Object = sd
+	}
+
+	return nil
+}
+
 // OptimizeXRefTable optimizes an xRefTable by locating and getting rid of redundant embedded fonts and images.
 func OptimizeXRefTable(ctx *Context) error {
 	log.Info.Println("optimizing fonts & images")
-	log.Optimize.Println("optimizeXRefTable begin")
+	ctx.Log.Optimize().Println("optimizeXRefTable begin")
+
+	// Record the stream data in use for optimization stats.
+	ctx.Optimize.binarySizes = binarySizes(ctx.XRefTable)
 
 	// Sometimes free objects are used although they are part of the free object list.
 	// Replace references to free xref table entries with a reference to a NULL object.
@@ -1153,6 +1251,26 @@ func OptimizeXRefTable(ctx *Context) error {
 		return err
 	}
 
+	// Incremental updates are limited to the objects touched by the operation in progress.
+	if !ctx.Incremental {
+
+		// LZW is outperformed by Flate.
+		if err := recompressStreams(ctx); err != nil {
+			return err
+		}
+
+		// Get rid of page resources not referenced by any content stream.
+		if ctx.OptimizeResourceDicts {
+			if err := ctx.RemoveUnusedResources(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := ctx.Canceled(); err != nil {
+		return err
+	}
+
 	// Get rid of duplicate embedded fonts and images.
 	if err := optimizeFontAndImages(ctx); err != nil {
 		return err
@@ -1170,7 +1288,7 @@ func OptimizeXRefTable(ctx *Context) error {
 
 	ctx.Optimized = true
 
-	log.Optimize.Println("optimizeXRefTable end")
+	ctx.Log.Optimize().Println("optimizeXRefTable end")
 
 	return nil
 }
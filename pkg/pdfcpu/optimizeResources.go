@@ -0,0 +1,480 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// The resource sub dicts taking part in resource pruning.
+var prunableResourceTypes = []string{"ExtGState", "Font", "XObject"}
+
+// resSubDictUsage accumulates the resource names referenced by all pages sharing a resource sub dict.
+type resSubDictUsage struct {
+	d     Dict      // the resource sub dict eg. the font resource dict.
+	names StringSet // the names referenced by any page content using d.
+	keep  bool      // true if the referenced names could not be determined reliably.
+}
+
+// resourceUsage maps resource sub dicts to their usage.
+// A resource sub dict is identified by its object number if it is an indirect object,
+// otherwise by the resource dict containing it.
+type resourceUsage map[string]*resSubDictUsage
+
+func (ru resourceUsage) usage(key string, d Dict) *resSubDictUsage {
+	u, ok := ru[key]
+	if !ok {
+		u = &resSubDictUsage{d: d, names: StringSet{}}
+		ru[key] = u
+	}
+	return u
+}
+
+// formOrType3WithoutResources returns true if o is a form XObject or a Type3 font relying on the resources of the page.
+func formOrType3WithoutResources(xRefTable *XRefTable, o Object) (bool, error) {
+	o, err := xRefTable.Dereference(o)
+	if err != nil || o == nil {
+		return false, err
+	}
+
+	var d Dict
+
+	switch x := o.(type) {
+	case StreamDict:
+		d = x.Dict
+	case Dict:
+		d = x
+	default:
+		return false, nil
+	}
+
+	if st := d.Subtype(); st == nil || (*st != "Form" && *st != "Type3") {
+		return false, nil
+	}
+
+	_, found := d.Find("Resources")
+	return !found, nil
+}
+
+func (xRefTable *XRefTable) pageResourceNamesReliable(resDict Dict, prn PageResourceNames) (bool, error) {
+	for _, resType := range []string{"Font", "XObject"} {
+		d, err := xRefTable.DereferenceDict(resDict[resType])
+		if err != nil {
+			return false, err
+		}
+		for name := range prn.Resources(resType) {
+			noRes, err := formOrType3WithoutResources(xRefTable, d[name])
+			if err != nil {
+				return false, err
+			}
+			if noRes {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+func (xRefTable *XRefTable) collectResourceUsageForPage(pageDict Dict, resDict Dict, resKey string, ru resourceUsage) error {
+	var prn PageResourceNames
+	keep := false
+
+	bb, err := xRefTable.PageContent(pageDict)
+	if err != nil && err != errNoContent {
+		xRefTable.Log.Optimize().Printf("collectResourceUsageForPage: %v\n", err)
+		keep = true
+	}
+
+	if !keep {
+		if prn, err = parseContent(string(bb)); err != nil {
+			xRefTable.Log.Optimize().Printf("collectResourceUsageForPage: %v\n", err)
+			keep = true
+		}
+	}
+
+	if !keep {
+		ok, err := xRefTable.pageResourceNamesReliable(resDict, prn)
+		if err != nil {
+			return err
+		}
+		keep = !ok
+	}
+
+	for _, resType := range prunableResourceTypes {
+		o, found := resDict.Find(resType)
+		if !found {
+			continue
+		}
+		d, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			continue
+		}
+		key := resKey + "/" + resType
+		if ir, ok := o.(IndirectRef); ok {
+			key = fmt.Sprintf("%d", ir.ObjectNumber.Value())
+		}
+		u := ru.usage(key, d)
+		if keep {
+			u.keep = true
+			continue
+		}
+		for name := range prn.Resources(resType) {
+			u.names[name] = true
+		}
+	}
+
+	return nil
+}
+
+func (xRefTable *XRefTable) collectResourceUsage(root IndirectRef, resDict Dict, resKey string, ru resourceUsage) error {
+	d, err := xRefTable.DereferenceDict(root)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.Errorf("pdfcpu: collectResourceUsage: missing page node obj#%d", root.ObjectNumber.Value())
+	}
+
+	if o, found := d.Find("Resources"); found {
+		if resDict, err = xRefTable.DereferenceDict(o); err != nil {
+			return err
+		}
+		resKey = fmt.Sprintf("%d", root.ObjectNumber.Value())
+		if ir, ok := o.(IndirectRef); ok {
+			resKey = fmt.Sprintf("%d", ir.ObjectNumber.Value())
+		}
+	}
+
+	if t := d.Type(); t != nil && *t == "Page" {
+		if resDict == nil {
+			return nil
+		}
+		return xRefTable.collectResourceUsageForPage(d, resDict, resKey, ru)
+	}
+
+	for _, o := range d.ArrayEntry("Kids") {
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			return errors.New("pdfcpu: collectResourceUsage: corrupt page node")
+		}
+		if err := xRefTable.collectResourceUsage(ir, resDict, resKey, ru); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// markExternalResources records the usage keys of the prunable sub dicts of the resource dict o.
+func (xRefTable *XRefTable) markExternalResources(o Object, keys StringSet) error {
+	resKey := ""
+	if ir, ok := o.(IndirectRef); ok {
+		resKey = fmt.Sprintf("%d", ir.ObjectNumber.Value())
+	}
+
+	resDict, err := xRefTable.DereferenceDict(o)
+	if err != nil || resDict == nil {
+		return err
+	}
+
+	for _, resType := range prunableResourceTypes {
+		if resKey != "" {
+			keys[resKey+"/"+resType] = true
+		}
+		if ir, ok := resDict[resType].(IndirectRef); ok {
+			keys[fmt.Sprintf("%d", ir.ObjectNumber.Value())] = true
+		}
+	}
+
+	return nil
+}
+
+// collectExternalResources walks d including all its direct sub objects and
+// records resource dicts not belonging to a page tree node.
+func (xRefTable *XRefTable) collectExternalResources(o Object, pageNode bool, keys StringSet) error {
+	switch x := o.(type) {
+
+	case StreamDict:
+		return xRefTable.collectExternalResources(x.Dict, false, keys)
+
+	case Dict:
+		for k, v := range x {
+			if !pageNode && (k == "Resources" || k == "DR") {
+				if err := xRefTable.markExternalResources(v, keys); err != nil {
+					return err
+				}
+			}
+			if err := xRefTable.collectExternalResources(v, false, keys); err != nil {
+				return err
+			}
+		}
+
+	case Array:
+		for _, v := range x {
+			if err := xRefTable.collectExternalResources(v, false, keys); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+// externalResourceKeys returns the usage keys of all resource sub dicts also reachable from outside page resources
+// eg. via the AcroForm default resources, appearance streams, form XObjects or Type3 fonts.
+func (xRefTable *XRefTable) externalResourceKeys() (StringSet, error) {
+	keys := StringSet{}
+
+	for _, entry := range xRefTable.Table {
+		if entry == nil || entry.Free || entry.Object == nil {
+			continue
+		}
+		pageNode := false
+		if d, ok := entry.Object.(Dict); ok {
+			if t := d.Type(); t != nil && (*t == "Page" || *t == "Pages") {
+				pageNode = true
+			}
+		}
+		if err := xRefTable.collectExternalResources(entry.Object, pageNode, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+// RemoveUnusedResources analyzes all page content streams and removes
+// font, XObject and extended graphics state resources no page is referring to.
+// Resource dicts shared by several pages only lose entries unused by all of them.
+// Resource dicts also used outside of page resources are left untouched.
+func (xRefTable *XRefTable) RemoveUnusedResources() error {
+	xRefTable.Log.Optimize().Println("RemoveUnusedResources begin")
+
+	root, err := xRefTable.Pages()
+	if err != nil || root == nil {
+		return err
+	}
+
+	ru := resourceUsage{}
+	if err := xRefTable.collectResourceUsage(*root, nil, "", ru); err != nil {
+		return err
+	}
+
+	external, err := xRefTable.externalResourceKeys()
+	if err != nil {
+		return err
+	}
+
+	for k, u := range ru {
+		if u.keep || external[k] {
+			continue
+		}
+		for name := range u.d {
+			if !u.names[name] {
+				xRefTable.Log.Optimize().Printf("RemoveUnusedResources: removing %s: %s\n", k, name)
+				u.d.Delete(name)
+			}
+		}
+	}
+
+	xRefTable.Log.Optimize().Println("RemoveUnusedResources end")
+
+	return nil
+}
+
+// The inheritable page attributes taking part in page tree consolidation.
+var consolidatablePageAttrs = []string{"Resources", "MediaBox", "CropBox", "Rotate"}
+
+// pageNodesByResources groups all page tree nodes by their resource dicts.
+func (xRefTable *XRefTable) pageNodesByResources(root IndirectRef, m map[string][]Dict) error {
+	d, err := xRefTable.DereferenceDict(root)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.Errorf("pdfcpu: pageNodesByResources: missing page node obj#%d", root.ObjectNumber.Value())
+	}
+
+	if o, found := d.Find("Resources"); found {
+		resDict, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		if resDict != nil {
+			s := resDict.PDFString()
+			m[s] = append(m[s], d)
+		}
+	}
+
+	for _, o := range d.ArrayEntry("Kids") {
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			return errors.New("pdfcpu: pageNodesByResources: corrupt page node")
+		}
+		if err := xRefTable.pageNodesByResources(ir, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// shareResourceDicts makes all page tree nodes with identical resources refer to the same indirect resource dict.
+func (xRefTable *XRefTable) shareResourceDicts(root IndirectRef) error {
+	m := map[string][]Dict{}
+	if err := xRefTable.pageNodesByResources(root, m); err != nil {
+		return err
+	}
+
+	// Process in a stable order for reproducible object numbers.
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		dd := m[k]
+		if len(dd) < 2 {
+			continue
+		}
+
+		// Prefer an already existing indirect resource dict.
+		var indRef *IndirectRef
+		for _, d := range dd {
+			if ir, ok := d["Resources"].(IndirectRef); ok {
+				indRef = &ir
+				break
+			}
+		}
+
+		if indRef == nil {
+			resDict, err := xRefTable.DereferenceDict(dd[0]["Resources"])
+			if err != nil {
+				return err
+			}
+			if indRef, err = xRefTable.IndRefForNewObject(resDict); err != nil {
+				return err
+			}
+		}
+
+		for _, d := range dd {
+			d.Update("Resources", *indRef)
+		}
+	}
+
+	return nil
+}
+
+func (xRefTable *XRefTable) hoistPageAttrs(root IndirectRef) error {
+	d, err := xRefTable.DereferenceDict(root)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.Errorf("pdfcpu: hoistPageAttrs: missing page node obj#%d", root.ObjectNumber.Value())
+	}
+
+	if t := d.Type(); t != nil && *t == "Page" {
+		return nil
+	}
+
+	kids := []Dict{}
+	for _, o := range d.ArrayEntry("Kids") {
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			return errors.New("pdfcpu: hoistPageAttrs: corrupt page node")
+		}
+		if err := xRefTable.hoistPageAttrs(ir); err != nil {
+			return err
+		}
+		kid, err := xRefTable.DereferenceDict(ir)
+		if err != nil {
+			return err
+		}
+		kids = append(kids, kid)
+	}
+
+	if len(kids) == 0 {
+		return nil
+	}
+
+	for _, attr := range consolidatablePageAttrs {
+		// An attribute may only be hoisted if all kids define the very same value.
+		var s string
+		hoist := true
+		for i, kid := range kids {
+			o, found := kid.Find(attr)
+			if !found {
+				hoist = false
+				break
+			}
+			if o == nil {
+				hoist = false
+				break
+			}
+			if i == 0 {
+				s = o.PDFString()
+				continue
+			}
+			if o.PDFString() != s {
+				hoist = false
+				break
+			}
+		}
+		if !hoist {
+			continue
+		}
+		xRefTable.Log.Optimize().Printf("hoistPageAttrs: hoisting %s to obj#%d\n", attr, root.ObjectNumber.Value())
+		d.Update(attr, kids[0][attr])
+		for _, kid := range kids {
+			kid.Delete(attr)
+		}
+	}
+
+	return nil
+}
+
+// ConsolidatePageTree shares identical page resource dicts and hoists
+// inheritable page attributes common to all kids of a page tree node up to this node.
+func (xRefTable *XRefTable) ConsolidatePageTree() error {
+	xRefTable.Log.Optimize().Println("ConsolidatePageTree begin")
+
+	root, err := xRefTable.Pages()
+	if err != nil || root == nil {
+		return err
+	}
+
+	if err := xRefTable.shareResourceDicts(*root); err != nil {
+		return err
+	}
+
+	if err := xRefTable.hoistPageAttrs(*root); err != nil {
+		return err
+	}
+
+	xRefTable.Log.Optimize().Println("ConsolidatePageTree end")
+
+	return nil
+}
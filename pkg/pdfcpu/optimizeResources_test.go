@@ -0,0 +1,231 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"testing"
+)
+
+func addTestPage(t *testing.T, xRefTable *XRefTable, parent IndirectRef, resources Object, content string) IndirectRef {
+	t.Helper()
+	sd, err := xRefTable.NewStreamDictForBuf([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sd.Encode(); err != nil {
+		t.Fatal(err)
+	}
+	contentIndRef, err := xRefTable.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := Dict(map[string]Object{
+		"Type":      Name("Page"),
+		"Parent":    parent,
+		"Resources": resources,
+		"Contents":  *contentIndRef,
+	})
+	ir, err := xRefTable.IndRefForNewObject(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *ir
+}
+
+func fontDictForTest(baseFont string) Dict {
+	return Dict(map[string]Object{
+		"Type":     Name("Font"),
+		"Subtype":  Name("Type1"),
+		"BaseFont": Name(baseFont),
+	})
+}
+
+func TestRemoveUnusedResources(t *testing.T) {
+	xRefTable, err := createXRefTableWithRootDict()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootDict, err := xRefTable.Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pagesDict := Dict(map[string]Object{
+		"Type":     Name("Pages"),
+		"Count":    Integer(2),
+		"MediaBox": RectForFormat("A4").Array(),
+	})
+	pagesIndRef, err := xRefTable.IndRefForNewObject(pagesDict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootDict.Insert("Pages", *pagesIndRef)
+
+	// A font resource dict shared by both pages.
+	fontDict := Dict(map[string]Object{
+		"F1": fontDictForTest("Helvetica"),
+		"F2": fontDictForTest("Courier"),
+		"F3": fontDictForTest("Times-Roman"),
+	})
+	fontIndRef, err := xRefTable.IndRefForNewObject(fontDict)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gsDict := Dict(map[string]Object{
+		"GS0": Dict(map[string]Object{"Type": Name("ExtGState"), "CA": Float(.5)}),
+		"GS1": Dict(map[string]Object{"Type": Name("ExtGState"), "CA": Float(.7)}),
+	})
+
+	res1 := Dict(map[string]Object{"Font": *fontIndRef, "ExtGState": gsDict})
+	res2 := Dict(map[string]Object{"Font": *fontIndRef})
+
+	p1 := addTestPage(t, xRefTable, *pagesIndRef, res1, "BT /F1 12 Tf (Hello) Tj ET /GS1 gs")
+	p2 := addTestPage(t, xRefTable, *pagesIndRef, res2, "BT /F2 12 Tf (World) Tj ET")
+	pagesDict.Insert("Kids", Array{p1, p2})
+
+	if err := xRefTable.RemoveUnusedResources(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, k := range []string{"F1", "F2"} {
+		if _, found := fontDict.Find(k); !found {
+			t.Errorf("missing shared font resource %s", k)
+		}
+	}
+	if _, found := fontDict.Find("F3"); found {
+		t.Errorf("unused font resource F3 not removed")
+	}
+	if _, found := gsDict.Find("GS0"); found {
+		t.Errorf("unused extGState resource GS0 not removed")
+	}
+	if _, found := gsDict.Find("GS1"); !found {
+		t.Errorf("missing extGState resource GS1")
+	}
+}
+
+func TestRemoveUnusedResourcesSharedWithAcroForm(t *testing.T) {
+	xRefTable, err := createXRefTableWithRootDict()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootDict, err := xRefTable.Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pagesDict := Dict(map[string]Object{
+		"Type":     Name("Pages"),
+		"Count":    Integer(1),
+		"MediaBox": RectForFormat("A4").Array(),
+	})
+	pagesIndRef, err := xRefTable.IndRefForNewObject(pagesDict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootDict.Insert("Pages", *pagesIndRef)
+
+	// A font resource dict shared by the page and the AcroForm default resources.
+	fontDict := Dict(map[string]Object{
+		"F1":   fontDictForTest("Helvetica"),
+		"Helv": fontDictForTest("Helvetica"),
+	})
+	fontIndRef, err := xRefTable.IndRefForNewObject(fontDict)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := Dict(map[string]Object{"Font": *fontIndRef})
+	p1 := addTestPage(t, xRefTable, *pagesIndRef, res, "BT /F1 12 Tf (Hello) Tj ET")
+	pagesDict.Insert("Kids", Array{p1})
+
+	rootDict.Insert("AcroForm", Dict(map[string]Object{
+		"Fields": Array{},
+		"DR":     Dict(map[string]Object{"Font": *fontIndRef}),
+		"DA":     StringLiteral("/Helv 0 Tf 0 g"),
+	}))
+
+	if err := xRefTable.RemoveUnusedResources(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, k := range []string{"F1", "Helv"} {
+		if _, found := fontDict.Find(k); !found {
+			t.Errorf("missing font resource %s shared with AcroForm", k)
+		}
+	}
+}
+
+func TestConsolidatePageTree(t *testing.T) {
+	xRefTable, err := createXRefTableWithRootDict()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootDict, err := xRefTable.Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pagesDict := Dict(map[string]Object{
+		"Type":  Name("Pages"),
+		"Count": Integer(2),
+	})
+	pagesIndRef, err := xRefTable.IndRefForNewObject(pagesDict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootDict.Insert("Pages", *pagesIndRef)
+
+	res := func() Dict {
+		return Dict(map[string]Object{"Font": Dict(map[string]Object{"F1": fontDictForTest("Helvetica")})})
+	}
+
+	p1 := addTestPage(t, xRefTable, *pagesIndRef, res(), "BT /F1 12 Tf (Hello) Tj ET")
+	p2 := addTestPage(t, xRefTable, *pagesIndRef, res(), "BT /F1 12 Tf (World) Tj ET")
+	pagesDict.Insert("Kids", Array{p1, p2})
+
+	for i, ir := range []IndirectRef{p1, p2} {
+		d, _ := xRefTable.DereferenceDict(ir)
+		d.Insert("MediaBox", RectForFormat("A4").Array())
+		d.Insert("Rotate", Integer(90*i))
+	}
+
+	if err := xRefTable.ConsolidatePageTree(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, attr := range []string{"Resources", "MediaBox"} {
+		if _, found := pagesDict.Find(attr); !found {
+			t.Errorf("%s not hoisted to page tree root", attr)
+		}
+	}
+	if _, found := pagesDict.Find("Rotate"); found {
+		t.Errorf("Rotate hoisted to page tree root")
+	}
+
+	for _, ir := range []IndirectRef{p1, p2} {
+		d, _ := xRefTable.DereferenceDict(ir)
+		for _, attr := range []string{"Resources", "MediaBox"} {
+			if _, found := d.Find(attr); found {
+				t.Errorf("%s still present in page obj#%d", attr, ir.ObjectNumber.Value())
+			}
+		}
+		if _, found := d.Find("Rotate"); !found {
+			t.Errorf("Rotate missing in page obj#%d", ir.ObjectNumber.Value())
+		}
+	}
+}
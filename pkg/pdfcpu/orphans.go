@@ -0,0 +1,194 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+)
+
+// OrphanedObject is an object present in the file but unreachable from the trailer.
+// Orphaned objects are typically leftovers of incremental updates and get dropped on write.
+type OrphanedObject struct {
+	ObjNr     int    `json:"objNr"`
+	GenNr     int    `json:"genNr"`
+	Type      string `json:"type"`                // eg. Font/Type1, stream/XObject/Image or array
+	Size      int64  `json:"size"`                // Serialized size including stream data.
+	ObjStream int    `json:"objStream,omitempty"` // Object number of the containing object stream.
+}
+
+func (o OrphanedObject) String() string {
+	s := fmt.Sprintf("obj#%d gen:%d %s %s", o.ObjNr, o.GenNr, o.Type, ByteSize(o.Size))
+	if o.ObjStream > 0 {
+		s += fmt.Sprintf(" (objStm#%d)", o.ObjStream)
+	}
+	return s
+}
+
+// objectTypeString returns a short description of the type of o.
+func objectTypeString(o Object) string {
+	var (
+		d Dict
+		s string
+	)
+
+	switch o := o.(type) {
+	case Dict:
+		d, s = o, "dict"
+	case StreamDict:
+		d, s = o.Dict, "stream"
+	case Array:
+		return "array"
+	case StringLiteral, HexLiteral:
+		return "string"
+	case Name:
+		return "name"
+	case Integer:
+		return "integer"
+	case Float:
+		return "float"
+	case Boolean:
+		return "boolean"
+	default:
+		return fmt.Sprintf("%T", o)
+	}
+
+	t, st := d.Type(), d.Subtype()
+	if t == nil && st == nil {
+		return s
+	}
+	if _, ok := o.(StreamDict); !ok {
+		s = ""
+	}
+	for _, n := range []*string{t, st} {
+		if n == nil {
+			continue
+		}
+		if s != "" {
+			s += "/"
+		}
+		s += *n
+	}
+	return s
+}
+
+// collectAllReachableObjNrs adds the object numbers of all objects reachable from o to objNrs.
+func (xRefTable *XRefTable) collectAllReachableObjNrs(o Object, objNrs IntSet) error {
+
+	if ir, ok := o.(IndirectRef); ok {
+		objNr := ir.ObjectNumber.Value()
+		if objNrs[objNr] {
+			return nil
+		}
+		objNrs[objNr] = true
+		var err error
+		if o, err = xRefTable.Dereference(ir); err != nil {
+			return err
+		}
+	}
+
+	switch o := o.(type) {
+
+	case Dict:
+		for _, v := range o {
+			if err := xRefTable.collectAllReachableObjNrs(v, objNrs); err != nil {
+				return err
+			}
+		}
+
+	case StreamDict:
+		return xRefTable.collectAllReachableObjNrs(o.Dict, objNrs)
+
+	case Array:
+		for _, v := range o {
+			if err := xRefTable.collectAllReachableObjNrs(v, objNrs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isStructuralObject returns true for objects which are part of the file structure rather than the document.
+func (ctx *Context) isStructuralObject(objNr int, e *XRefTableEntry) bool {
+	if ctx.LinearizationObjs[objNr] {
+		return true
+	}
+	if ctx.Read != nil && (ctx.Read.ObjectStreams[objNr] || ctx.Read.XRefStreams[objNr]) {
+		return true
+	}
+
+	sd, ok := e.Object.(StreamDict)
+	if !ok {
+		return false
+	}
+
+	if t := sd.Type(); t != nil && (*t == "XRef" || *t == "ObjStm") {
+		return true
+	}
+
+	// Linearization hint streams have no type.
+	if e.Offset != nil {
+		for _, off := range []*int64{ctx.OffsetPrimaryHintTable, ctx.OffsetOverflowHintTable} {
+			if off != nil && *off == *e.Offset {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// OrphanedObjects returns all objects of ctx unreachable from the trailer ordered by object number.
+// Object streams, xref streams and linearization objects are not considered orphans.
+func (ctx *Context) OrphanedObjects() ([]OrphanedObject, error) {
+
+	objNrs := IntSet{}
+
+	for _, ir := range []*IndirectRef{ctx.Root, ctx.Info, ctx.Encrypt} {
+		if ir == nil {
+			continue
+		}
+		if err := ctx.collectAllReachableObjNrs(*ir, objNrs); err != nil {
+			return nil, err
+		}
+	}
+
+	oo := []OrphanedObject{}
+
+	for objNr, e := range ctx.Table {
+		if objNr == 0 || e == nil || e.Free || e.Object == nil || objNrs[objNr] {
+			continue
+		}
+		if ctx.isStructuralObject(objNr, e) {
+			continue
+		}
+		o := OrphanedObject{ObjNr: objNr, Type: objectTypeString(e.Object), Size: objectSize(e.Object)}
+		if e.Generation != nil {
+			o.GenNr = *e.Generation
+		}
+		if e.Compressed && e.ObjectStream != nil {
+			o.ObjStream = *e.ObjectStream
+		}
+		oo = append(oo, o)
+	}
+
+	sort.Slice(oo, func(i, j int) bool { return oo[i].ObjNr < oo[j].ObjNr })
+
+	return oo, nil
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"github.com/pkg/errors"
+)
+
+// OutlineItem is an entry of the document outline recorded while generating pages.
+type OutlineItem struct {
+	Title  string
+	Level  int     // Nesting level starting with 1 for top level items.
+	PageNr int     // Target page, defaults to the page added last.
+	Fit    string  // Destination zoom: Fit (default), FitB, FitH, FitBH, FitV, FitBV or XYZ
+	Left   float64 // Left edge of the view for XYZ, FitV and FitBV.
+	Top    float64 // Top edge of the view for XYZ, FitH and FitBH.
+	Zoom   float64 // Zoom factor for XYZ, 0 retains the current zoom.
+	Open   bool    // Show the children of this item initially.
+	Bold   bool
+	Italic bool
+	Color  *SimpleColor
+}
+
+type outlineNode struct {
+	item OutlineItem
+	page IndirectRef
+	kids []*outlineNode
+}
+
+func (item OutlineItem) validate() error {
+	if item.Level < 1 {
+		return errors.Errorf("pdfcpu: outline item %q: invalid level: %d", item.Title, item.Level)
+	}
+	if item.Fit != "" && !MemberOf(item.Fit, []string{"Fit", "FitB", "FitH", "FitBH", "FitV", "FitBV", "XYZ"}) {
+		return errors.Errorf("pdfcpu: outline item %q: invalid fit: %s", item.Title, item.Fit)
+	}
+	if item.Zoom < 0 {
+		return errors.Errorf("pdfcpu: outline item %q: invalid zoom: %.2f", item.Title, item.Zoom)
+	}
+	return nil
+}
+
+func (item OutlineItem) destination(page IndirectRef) Array {
+	fit := item.Fit
+	if fit == "" {
+		fit = "Fit"
+	}
+
+	switch fit {
+	case "XYZ":
+		var zoom Object
+		if item.Zoom > 0 {
+			zoom = Float(item.Zoom)
+		}
+		return Array{page, Name(fit), Float(item.Left), Float(item.Top), zoom}
+	case "FitH", "FitBH":
+		return Array{page, Name(fit), Float(item.Top)}
+	case "FitV", "FitBV":
+		return Array{page, Name(fit), Float(item.Left)}
+	}
+
+	return Array{page, Name(fit)}
+}
+
+func (item OutlineItem) style() int {
+	var i int
+	if item.Bold {
+		i += 2
+	}
+	if item.Italic {
+		i += 1
+	}
+	return i
+}
+
+// descendants returns the number of descendants of n visible if n is open.
+func (n *outlineNode) descendants() int {
+	c := 0
+	for _, kid := range n.kids {
+		c++
+		if kid.item.Open {
+			c += kid.descendants()
+		}
+	}
+	return c
+}
+
+// AddOutlineItem records an outline item pointing to a page added so far.
+// Outline items are nested by level in the order they are added.
+// The outline gets written on Close.
+func (sw *PageStreamWriter) AddOutlineItem(item OutlineItem) error {
+	if sw.closed {
+		return errors.New("pdfcpu: PageStreamWriter: already closed")
+	}
+
+	if err := item.validate(); err != nil {
+		return err
+	}
+
+	pageNr := item.PageNr
+	if pageNr == 0 {
+		pageNr = len(sw.kids)
+	}
+	if pageNr < 1 || pageNr > len(sw.kids) {
+		return errors.Errorf("pdfcpu: outline item %q: page %d not added yet", item.Title, pageNr)
+	}
+
+	// The parent of a new item is the last item added one level up.
+	parent := &sw.outline
+	for level := 1; level < item.Level; level++ {
+		if len(parent.kids) == 0 {
+			return errors.Errorf("pdfcpu: outline item %q: missing parent for level %d", item.Title, item.Level)
+		}
+		parent = parent.kids[len(parent.kids)-1]
+	}
+
+	n := &outlineNode{item: item, page: sw.kids[pageNr-1].(IndirectRef)}
+	parent.kids = append(parent.kids, n)
+
+	return nil
+}
+
+func createOutlineItems(xRefTable *XRefTable, nodes []*outlineNode, parent IndirectRef) (*IndirectRef, *IndirectRef, error) {
+	var (
+		first, irPrev *IndirectRef
+		dPrev         Dict
+	)
+
+	for _, n := range nodes {
+
+		sl, err := textLiteral(n.item.Title)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		d := Dict(map[string]Object{
+			"Title":  sl,
+			"Parent": parent,
+			"Dest":   n.item.destination(n.page),
+		})
+
+		if c := n.item.Color; c != nil {
+			d["C"] = Array{Float(c.R), Float(c.G), Float(c.B)}
+		}
+
+		if style := n.item.style(); style > 0 {
+			d["F"] = Integer(style)
+		}
+
+		ir, err := xRefTable.IndRefForNewObject(d)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(n.kids) > 0 {
+			f, l, err := createOutlineItems(xRefTable, n.kids, *ir)
+			if err != nil {
+				return nil, nil, err
+			}
+			d["First"] = *f
+			d["Last"] = *l
+			c := n.descendants()
+			if !n.item.Open {
+				c = -c
+			}
+			d["Count"] = Integer(c)
+		}
+
+		if first == nil {
+			first = ir
+		}
+
+		if irPrev != nil {
+			d["Prev"] = *irPrev
+			dPrev["Next"] = *ir
+		}
+
+		dPrev = d
+		irPrev = ir
+	}
+
+	return first, irPrev, nil
+}
+
+// writeOutline creates the outline tree of all recorded outline items.
+func (sw *PageStreamWriter) writeOutline() error {
+	if len(sw.outline.kids) == 0 {
+		return nil
+	}
+
+	xRefTable := sw.ctx.XRefTable
+
+	rootDict, err := xRefTable.Catalog()
+	if err != nil {
+		return err
+	}
+
+	d := Dict(map[string]Object{"Type": Name("Outlines")})
+	ir, err := xRefTable.IndRefForNewObject(d)
+	if err != nil {
+		return err
+	}
+
+	first, last, err := createOutlineItems(xRefTable, sw.outline.kids, *ir)
+	if err != nil {
+		return err
+	}
+
+	d["First"] = *first
+	d["Last"] = *last
+	d["Count"] = Integer(sw.outline.descendants())
+
+	rootDict["Outlines"] = *ir
+
+	return nil
+}
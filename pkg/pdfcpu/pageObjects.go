@@ -0,0 +1,236 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// PageObjects represents the objects reachable from a page.
+//
+// Reachable objects are all indirect objects referenced from the page dict including inherited resources.
+// Back pointers like Parent and P are not followed and traversal stops at other pages and page tree nodes.
+type PageObjects struct {
+	PageNr    int
+	Exclusive []int // Object numbers of objects reachable from this page only including the page dict.
+	Shared    []int // Object numbers of objects also reachable from other pages or the rest of the document.
+}
+
+// isPageNode returns true for page dicts and page tree nodes.
+func isPageNode(o Object) bool {
+	d, ok := o.(Dict)
+	if !ok {
+		return false
+	}
+	t := d.Type()
+	return t != nil && (*t == "Page" || *t == "Pages")
+}
+
+// collectReachableObjNrs adds the object numbers of all objects reachable from o to objNrs.
+func (xRefTable *XRefTable) collectReachableObjNrs(o Object, objNrs IntSet) error {
+
+	if ir, ok := o.(IndirectRef); ok {
+		objNr := ir.ObjectNumber.Value()
+		if objNrs[objNr] {
+			return nil
+		}
+		var err error
+		if o, err = xRefTable.Dereference(ir); err != nil {
+			return err
+		}
+		if o == nil || isPageNode(o) {
+			return nil
+		}
+		objNrs[objNr] = true
+	}
+
+	var d Dict
+
+	switch o := o.(type) {
+
+	case Dict:
+		d = o
+
+	case StreamDict:
+		d = o.Dict
+
+	case Array:
+		for _, v := range o {
+			if err := xRefTable.collectReachableObjNrs(v, objNrs); err != nil {
+				return err
+			}
+		}
+	}
+
+	for k, v := range d {
+		if k == "Parent" || k == "P" {
+			continue
+		}
+		if err := xRefTable.collectReachableObjNrs(v, objNrs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// inheritedResources returns the resources a page dict inherits from its page tree ancestors.
+func (xRefTable *XRefTable) inheritedResources(d Dict) (Object, error) {
+
+	visited := IntSet{}
+
+	for {
+		if o, found := d.Find("Resources"); found {
+			return o, nil
+		}
+
+		ir := d.IndirectRefEntry("Parent")
+		if ir == nil {
+			return nil, nil
+		}
+
+		objNr := ir.ObjectNumber.Value()
+		if visited[objNr] {
+			return nil, errors.New("pdfcpu: corrupt page tree")
+		}
+		visited[objNr] = true
+
+		var err error
+		if d, err = xRefTable.DereferenceDict(*ir); err != nil || d == nil {
+			return nil, err
+		}
+	}
+}
+
+// pageReachableObjNrs returns the object numbers of all objects reachable from the page dict pageObjNr.
+func (xRefTable *XRefTable) pageReachableObjNrs(pageObjNr int) (IntSet, error) {
+
+	d, err := xRefTable.DereferenceDict(*NewIndirectRef(pageObjNr, 0))
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.Errorf("pdfcpu: missing page dict obj#%d", pageObjNr)
+	}
+
+	objNrs := IntSet{pageObjNr: true}
+
+	if err := xRefTable.collectReachableObjNrs(d, objNrs); err != nil {
+		return nil, err
+	}
+
+	if _, found := d.Find("Resources"); !found {
+		o, err := xRefTable.inheritedResources(d)
+		if err != nil {
+			return nil, err
+		}
+		if err := xRefTable.collectReachableObjNrs(o, objNrs); err != nil {
+			return nil, err
+		}
+	}
+
+	return objNrs, nil
+}
+
+// documentReachableObjNrs returns the object numbers of all objects reachable from the catalog
+// and the document information dict bypassing the page tree.
+func (xRefTable *XRefTable) documentReachableObjNrs() (IntSet, error) {
+
+	rootDict, err := xRefTable.Catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	objNrs := IntSet{}
+
+	for k, v := range rootDict {
+		if k == "Pages" {
+			continue
+		}
+		if err := xRefTable.collectReachableObjNrs(v, objNrs); err != nil {
+			return nil, err
+		}
+	}
+
+	if xRefTable.Info != nil {
+		if err := xRefTable.collectReachableObjNrs(*xRefTable.Info, objNrs); err != nil {
+			return nil, err
+		}
+	}
+
+	return objNrs, nil
+}
+
+func sortedObjNrs(objNrs IntSet) []int {
+	ii := make([]int, 0, len(objNrs))
+	for objNr := range objNrs {
+		ii = append(ii, objNr)
+	}
+	sort.Ints(ii)
+	return ii
+}
+
+// PageObjects returns the objects reachable from page pageNr split up into objects exclusive to this page and shared objects.
+// Determining shared objects takes a traversal of the whole document.
+func (xRefTable *XRefTable) PageObjects(pageNr int) (*PageObjects, error) {
+
+	pageObjNrs, err := xRefTable.pageObjNrs()
+	if err != nil {
+		return nil, err
+	}
+
+	if pageNr < 1 || pageNr > len(pageObjNrs) {
+		return nil, errors.Errorf("pdfcpu: invalid page number: %d", pageNr)
+	}
+
+	objNrs, err := xRefTable.pageReachableObjNrs(pageObjNrs[pageNr-1])
+	if err != nil {
+		return nil, err
+	}
+
+	// Objects reachable from anywhere else.
+	others, err := xRefTable.documentReachableObjNrs()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, objNr := range pageObjNrs {
+		if i == pageNr-1 {
+			continue
+		}
+		m, err := xRefTable.pageReachableObjNrs(objNr)
+		if err != nil {
+			return nil, err
+		}
+		for k := range m {
+			others[k] = true
+		}
+	}
+
+	exclusive, shared := IntSet{}, IntSet{}
+	for objNr := range objNrs {
+		if others[objNr] {
+			shared[objNr] = true
+			continue
+		}
+		exclusive[objNr] = true
+	}
+
+	return &PageObjects{PageNr: pageNr, Exclusive: sortedObjNrs(exclusive), Shared: sortedObjNrs(shared)}, nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import "fmt"
+
+// PageSize represents the bytes attributed to a page.
+type PageSize struct {
+	PageNr    int   `json:"page"`
+	Exclusive int64 `json:"exclusive"` // Bytes of objects used by this page only including the page dict.
+	Shared    int64 `json:"shared"`    // Bytes of objects this page shares with other pages or the rest of the document.
+}
+
+// PageSizeReport attributes the bytes of all objects of a document to its pages.
+//
+// The size of an object is the length of its serialized form plus the length of its stream data as stored in the file.
+// Object streams and xref streams are not taken into account.
+type PageSizeReport struct {
+	Pages    []PageSize `json:"pages"`
+	Shared   int64      `json:"shared"`   // Bytes of objects used by more than one page or by a page and the rest of the document.
+	Document int64      `json:"document"` // Bytes of objects not used by any page eg. outlines, metadata or unreferenced objects.
+	Total    int64      `json:"total"`    // Bytes of all objects.
+}
+
+// objectSize returns the number of bytes taken up by o.
+func objectSize(o Object) int64 {
+	switch o := o.(type) {
+	case nil:
+		return 0
+	case StreamDict:
+		if binaryCategory(o) < 0 {
+			return 0
+		}
+		return int64(len(o.Dict.PDFString())) + streamLength(o)
+	}
+	return int64(len(o.PDFString()))
+}
+
+func (xRefTable *XRefTable) objNrSize(objNr int) int64 {
+	e, found := xRefTable.Table[objNr]
+	if !found || e == nil || e.Free {
+		return 0
+	}
+	return objectSize(e.Object)
+}
+
+// PageSizes returns the bytes attributed to each page based on the objects reachable from a page.
+// Objects reachable from one page only count as exclusive, all others as shared.
+func (xRefTable *XRefTable) PageSizes() (*PageSizeReport, error) {
+
+	pageObjNrs, err := xRefTable.pageObjNrs()
+	if err != nil {
+		return nil, err
+	}
+
+	// Count the users of each object.
+	users := map[int]int{}
+
+	pageSets := make([]IntSet, len(pageObjNrs))
+	for i, objNr := range pageObjNrs {
+		if pageSets[i], err = xRefTable.pageReachableObjNrs(objNr); err != nil {
+			return nil, err
+		}
+		for k := range pageSets[i] {
+			users[k]++
+		}
+	}
+
+	others, err := xRefTable.documentReachableObjNrs()
+	if err != nil {
+		return nil, err
+	}
+	for k := range others {
+		users[k]++
+	}
+
+	r := &PageSizeReport{Pages: make([]PageSize, len(pageSets))}
+
+	var exclusive int64
+	counted := IntSet{}
+
+	for i, objNrs := range pageSets {
+		ps := PageSize{PageNr: i + 1}
+		for objNr := range objNrs {
+			n := xRefTable.objNrSize(objNr)
+			if users[objNr] == 1 {
+				ps.Exclusive += n
+				continue
+			}
+			ps.Shared += n
+			if !counted[objNr] {
+				counted[objNr] = true
+				r.Shared += n
+			}
+		}
+		exclusive += ps.Exclusive
+		r.Pages[i] = ps
+	}
+
+	for objNr := range xRefTable.Table {
+		r.Total += xRefTable.objNrSize(objNr)
+	}
+
+	r.Document = r.Total - exclusive - r.Shared
+
+	return r, nil
+}
+
+// Largest returns the page with the most exclusive bytes.
+func (r PageSizeReport) Largest() *PageSize {
+	var ps *PageSize
+	for i := range r.Pages {
+		if ps == nil || r.Pages[i].Exclusive > ps.Exclusive {
+			ps = &r.Pages[i]
+		}
+	}
+	return ps
+}
+
+// List returns r in human readable form.
+func (r PageSizeReport) List() []string {
+	ss := []string{fmt.Sprintf("%6s %12s %12s", "page", "exclusive", "shared")}
+	for _, ps := range r.Pages {
+		ss = append(ss, fmt.Sprintf("%6d %12s %12s", ps.PageNr, ByteSize(ps.Exclusive), ByteSize(ps.Shared)))
+	}
+	ss = append(ss, fmt.Sprintf("shared: %s, document: %s, total: %s", ByteSize(r.Shared), ByteSize(r.Document), ByteSize(r.Total)))
+	if ps := r.Largest(); ps != nil {
+		ss = append(ss, fmt.Sprintf("largest page: %d (%s)", ps.PageNr, ByteSize(ps.Exclusive)))
+	}
+	return ss
+}
@@ -21,7 +21,20 @@ import (
 	"github.com/pkg/errors"
 )
 
+// appendContentPart appends the content stream part to bb.
+// Parts get separated by an EOL since the division between content streams occurs
+// at token boundaries (7.8.2) and must also terminate a trailing comment.
+func appendContentPart(bb, part []byte) []byte {
+	if len(bb) > 0 && len(part) > 0 {
+		if c := bb[len(bb)-1]; c != 0x0A && c != 0x0D {
+			bb = append(bb, 0x0A)
+		}
+	}
+	return append(bb, part...)
+}
+
 // PageContent returns the content in PDF syntax for page dict d.
+// The parts of content split across several streams get concatenated.
 func (xRefTable *XRefTable) PageContent(d Dict) ([]byte, error) {
 
 	o, _ := d.Find("Contents")
@@ -67,7 +80,7 @@ func (xRefTable *XRefTable) PageContent(d Dict) ([]byte, error) {
 			if err != nil {
 				return nil, err
 			}
-			bb = append(bb, o.Content...)
+			bb = appendContentPart(bb, o.Content)
 		}
 
 	default:
@@ -95,6 +108,9 @@ func migratePageDict(d Dict, ctx, ctxDest *Context, migrated map[int]int) error
 }
 
 // AddPages adds pages and corresponding resources from otherXRefTable to xRefTable.
+// Page references of annotations and destinations get remapped onto the added pages,
+// references to pages not being added get removed.
+// Form fields having widgets on the added pages are added along with the form's calculation order.
 func AddPages(ctx, ctxDest *Context, pages []int, usePgCache bool) error {
 
 	pagesIndRef, err := ctxDest.Pages()
@@ -108,9 +124,40 @@ func AddPages(ctx, ctxDest *Context, pages []int, usePgCache bool) error {
 		return err
 	}
 
+	pageObjNrs, err := ctx.pageObjNrs()
+	if err != nil {
+		return err
+	}
+
 	pageCache := map[int]*IndirectRef{}
 	migrated := map[int]int{}
 
+	// Redirect references to pages not being added to a placeholder in order to prevent their migration.
+	orphan, err := ctxDest.InsertObject(nil)
+	if err != nil {
+		return err
+	}
+	for _, objNr := range pageObjNrs {
+		migrated[objNr] = orphan
+	}
+
+	// Reserve object numbers for all pages being added.
+	for _, i := range pages {
+		if i < 1 || i > len(pageObjNrs) {
+			return errors.Errorf("pdfcpu: unknown page number: %d\n", i)
+		}
+		objNr := pageObjNrs[i-1]
+		if migrated[objNr] != orphan {
+			continue
+		}
+		if migrated[objNr], err = ctxDest.InsertObject(nil); err != nil {
+			return err
+		}
+	}
+
+	reserved := IntSet{}
+	pageIndRefs := []IndirectRef{}
+
 	for _, i := range pages {
 
 		if usePgCache {
@@ -132,10 +179,19 @@ func AddPages(ctx, ctxDest *Context, pages []int, usePgCache bool) error {
 		if d == nil {
 			return errors.Errorf("pdfcpu: unknown page number: %d\n", i)
 		}
-		//log.Write.Printf("AddPages:\n%s\n", inhPAttrs.resources)
+		//ctxDest.Log.Write().Printf("AddPages:\n%s\n", inhPAttrs.resources)
 
 		//fmt.Printf("migrresDict bef: \n%s", d)
 
+		objNr := migrated[pageObjNrs[i-1]]
+		if reserved[objNr] {
+			// Another copy of an already added page.
+			if objNr, err = ctxDest.InsertObject(nil); err != nil {
+				return err
+			}
+		}
+		reserved[objNr] = true
+
 		d = d.Clone().(Dict)
 
 		d["Resources"] = inhPAttrs.resources
@@ -152,19 +208,24 @@ func AddPages(ctx, ctxDest *Context, pages []int, usePgCache bool) error {
 			d["Rotate"] = Integer(inhPAttrs.rotate)
 		}
 
-		indRef, err := ctxDest.IndRefForNewObject(d)
-		if err != nil {
-			return err
-		}
+		entry, _ := ctxDest.FindTableEntryLight(objNr)
+		entry.Object = d
+		indRef := NewIndirectRef(objNr, 0)
 
 		if err := AppendPageTree(indRef, 1, pagesDict); err != nil {
 			return err
 		}
 
+		pageIndRefs = append(pageIndRefs, *indRef)
+
 		if usePgCache {
 			pageCache[i] = indRef
 		}
 	}
 
-	return nil
+	if err := ctxDest.RemapPageRefs(pageIndRefs, PageRefMap{orphan: 0}, false); err != nil {
+		return err
+	}
+
+	return migrateAcroForm(ctx, ctxDest, pageIndRefs, migrated)
 }
@@ -86,7 +86,9 @@ func positionToNextEOL(s string) string {
 
 // trimLeftSpace trims leading whitespace and trailing comment.
 func trimLeftSpace(s string, relaxed bool) (outstr string, eol bool) {
-	log.Parse.Printf("TrimLeftSpace: begin %s\n", s)
+	if log.IsParseLoggerEnabled() {
+		log.Parse.Printf("TrimLeftSpace: begin %s\n", s)
+	}
 
 	whitespace := func(c rune) bool { return unicode.IsSpace(c) || c == 0x00 }
 
@@ -108,17 +110,23 @@ func trimLeftSpace(s string, relaxed bool) (outstr string, eol bool) {
 			}
 		}
 		outstr = strings.TrimLeftFunc(outstr, whitespace)
-		log.Parse.Printf("1 outstr: <%s>\n", outstr)
+		if log.IsParseLoggerEnabled() {
+			log.Parse.Printf("1 outstr: <%s>\n", outstr)
+		}
 		if len(outstr) <= 1 || outstr[0] != '%' {
 			break
 		}
 		// trim PDF comment (= '%' up to eol)
 		outstr = positionToNextEOL(outstr)
-		log.Parse.Printf("2 outstr: <%s>\n", outstr)
+		if log.IsParseLoggerEnabled() {
+			log.Parse.Printf("2 outstr: <%s>\n", outstr)
+		}
 
 	}
 
-	log.Parse.Printf("TrimLeftSpace: end %s\n", outstr)
+	if log.IsParseLoggerEnabled() {
+		log.Parse.Printf("TrimLeftSpace: end %s\n", outstr)
+	}
 
 	return outstr, eol
 }
@@ -221,7 +229,9 @@ func delimiter(b byte) bool {
 
 // parseObjectAttributes parses object number and generation of the next object for given string buffer.
 func parseObjectAttributes(line *string) (objectNumber *int, generationNumber *int, err error) {
-	log.Parse.Printf("ParseObjectAttributes: buf=<%s>\n", *line)
+	if log.IsParseLoggerEnabled() {
+		log.Parse.Printf("ParseObjectAttributes: buf=<%s>\n", *line)
+	}
 
 	if line == nil || len(*line) == 0 {
 		return nil, nil, errors.New("pdfcpu: ParseObjectAttributes: buf not available")
@@ -281,14 +291,16 @@ func parseObjectAttributes(line *string) (objectNumber *int, generationNumber *i
 	return objectNumber, generationNumber, nil
 }
 
-func parseArray(line *string) (*Array, error) {
+func parseArray(line *string, dupKeys *[]string) (*Array, error) {
 	if line == nil || len(*line) == 0 {
 		return nil, errNoArray
 	}
 
 	l := *line
 
-	log.Parse.Printf("ParseArray: %s\n", l)
+	if log.IsParseLoggerEnabled() {
+		log.Parse.Printf("ParseArray: %s\n", l)
+	}
 
 	if !strings.HasPrefix(l, "[") {
 		return nil, errArrayCorrupt
@@ -313,11 +325,13 @@ func parseArray(line *string) (*Array, error) {
 
 	for !strings.HasPrefix(l, "]") {
 
-		obj, err := parseObject(&l)
+		obj, err := parseObjectWithDupKeys(&l, dupKeys)
 		if err != nil {
 			return nil, err
 		}
-		log.Parse.Printf("ParseArray: new array obj=%v\n", obj)
+		if log.IsParseLoggerEnabled() {
+			log.Parse.Printf("ParseArray: new array obj=%v\n", obj)
+		}
 		a = append(a, obj)
 
 		// we are positioned on the char behind the last parsed array entry.
@@ -337,7 +351,9 @@ func parseArray(line *string) (*Array, error) {
 
 	*line = l
 
-	log.Parse.Printf("ParseArray: returning array (len=%d): %v\n", len(a), a)
+	if log.IsParseLoggerEnabled() {
+		log.Parse.Printf("ParseArray: returning array (len=%d): %v\n", len(a), a)
+	}
 
 	return &a, nil
 }
@@ -369,7 +385,9 @@ func parseStringLiteral(line *string) (Object, error) {
 
 	l := *line
 
-	log.Parse.Printf("parseStringLiteral: begin <%s>\n", l)
+	if log.IsParseLoggerEnabled() {
+		log.Parse.Printf("parseStringLiteral: begin <%s>\n", l)
+	}
 
 	if len(l) < 2 || !strings.HasPrefix(l, "(") {
 		return nil, errStringLiteralCorrupt
@@ -393,7 +411,9 @@ func parseStringLiteral(line *string) (Object, error) {
 	*line = forwardParseBuf(l[i:], 1)
 
 	stringLiteral := StringLiteral(balParStr)
-	log.Parse.Printf("parseStringLiteral: end <%s>\n", stringLiteral)
+	if log.IsParseLoggerEnabled() {
+		log.Parse.Printf("parseStringLiteral: end <%s>\n", stringLiteral)
+	}
 
 	return stringLiteral, nil
 }
@@ -405,7 +425,9 @@ func parseHexLiteral(line *string) (Object, error) {
 
 	l := *line
 
-	log.Parse.Printf("parseHexLiteral: %s\n", l)
+	if log.IsParseLoggerEnabled() {
+		log.Parse.Printf("parseHexLiteral: %s\n", l)
+	}
 
 	if len(l) < 2 || !strings.HasPrefix(l, "<") {
 		return nil, errHexLiteralCorrupt
@@ -456,7 +478,16 @@ func validateNameHexSequence(s string) error {
 	return nil
 }
 
-func parseName(line *string) (*Name, error) {
+func parseName(line *string) (Name, error) {
+	o, err := parseNameObject(line)
+	if err != nil {
+		return "", err
+	}
+	return o.(Name), nil
+}
+
+// parseNameObject parses a name and returns its canonical Object.
+func parseNameObject(line *string) (Object, error) {
 	// see 7.3.5
 	if line == nil || len(*line) == 0 {
 		return nil, errBufNotAvailable
@@ -464,7 +495,9 @@ func parseName(line *string) (*Name, error) {
 
 	l := *line
 
-	log.Parse.Printf("parseNameObject: %s\n", l)
+	if log.IsParseLoggerEnabled() {
+		log.Parse.Printf("parseNameObject: %s\n", l)
+	}
 
 	if len(l) < 2 || !strings.HasPrefix(l, "/") {
 		return nil, errNameObjectCorrupt
@@ -489,11 +522,57 @@ func parseName(line *string) (*Name, error) {
 		return nil, err
 	}
 
-	nameObj := Name(l)
-	return &nameObj, nil
+	if o, ok := internedNames[l]; ok {
+		// Avoid referring to the parse buffer.
+		return o, nil
+	}
+
+	return Name(l), nil
+}
+
+// internedNames holds canonical values for the most frequently used names.
+// Parsing maps names to these values in order to cut allocations.
+var internedNames = func() map[string]Object {
+	m := map[string]Object{}
+	for _, s := range []string{
+		"Type", "Subtype", "Page", "Pages", "Catalog", "Parent", "Kids", "Count", "Resources", "MediaBox",
+		"CropBox", "BleedBox", "TrimBox", "ArtBox", "Rotate", "Contents", "Font", "XObject", "ExtGState",
+		"ColorSpace", "Pattern", "Shading", "Properties", "ProcSet", "PDF", "Text", "ImageB", "ImageC",
+		"ImageI", "Length", "Filter", "DecodeParms", "FlateDecode", "DCTDecode", "LZWDecode", "ASCII85Decode",
+		"ASCIIHexDecode", "CCITTFaxDecode", "JBIG2Decode", "JPXDecode", "RunLengthDecode", "Predictor",
+		"Columns", "Colors", "BitsPerComponent", "Width", "Height", "Image", "Form", "BBox", "Matrix",
+		"Group", "Transparency", "CS", "SMask", "Mask", "Decode", "Interpolate", "DeviceRGB", "DeviceGray",
+		"DeviceCMYK", "ICCBased", "Indexed", "Separation", "DeviceN", "Alternate", "BaseFont", "Encoding",
+		"FirstChar", "LastChar", "Widths", "FontDescriptor", "FontName", "FontFamily", "Flags", "FontBBox",
+		"ItalicAngle", "Ascent", "Descent", "Leading", "CapHeight", "XHeight", "StemV", "StemH", "AvgWidth",
+		"MaxWidth", "MissingWidth", "FontFile", "FontFile2", "FontFile3", "CharSet", "CIDSet", "Type0",
+		"Type1", "Type3", "MMType1", "TrueType", "CIDFontType0", "CIDFontType2", "DescendantFonts", "ToUnicode",
+		"WinAnsiEncoding", "MacRomanEncoding", "StandardEncoding", "Identity-H", "Identity-V", "Identity",
+		"CIDSystemInfo", "Registry", "Ordering", "Supplement", "CIDToGIDMap", "DW", "W", "Annots", "Annot",
+		"Link", "Widget", "Rect", "Border", "BS", "C", "A", "S", "D", "URI", "GoTo", "Dest", "Dests",
+		"P", "F", "H", "T", "V", "DA", "DR", "AP", "MK", "FT", "Ff", "Fields", "AcroForm", "Tx", "Btn",
+		"Ch", "Sig", "Outlines", "First", "Last", "Next", "Prev", "Title", "Names", "Info", "Root", "ID",
+		"Encrypt", "Size", "Index", "ObjStm", "XRef", "N", "Extends", "Metadata", "XML", "StructParents",
+		"StructParent", "StructTreeRoot", "MarkInfo", "Marked", "Tabs", "Lang", "ViewerPreferences",
+		"PageLayout", "PageMode", "OpenAction", "PageLabels", "Nums", "Limits", "EmbeddedFiles", "Filespec",
+		"EF", "UF", "Params", "CheckSum", "ModDate", "CreationDate", "CA", "ca", "BM", "LW", "LC", "LJ",
+		"ML", "SA", "Normal",
+	} {
+		m[s] = Name(s)
+	}
+	return m
+}()
+
+func recordDuplicateKey(d Dict, key string, dupKeys *[]string) {
+	if dupKeys == nil {
+		return
+	}
+	if _, found := d.Find(key); found {
+		*dupKeys = append(*dupKeys, key)
+	}
 }
 
-func processDictKeys(line *string, relaxed bool) (Dict, error) {
+func processDictKeys(line *string, relaxed bool, dupKeys *[]string) (Dict, error) {
 	l := *line
 	var eol bool
 	d := NewDict()
@@ -502,7 +581,9 @@ func processDictKeys(line *string, relaxed bool) (Dict, error) {
 		if err != nil {
 			return nil, err
 		}
-		log.Parse.Printf("ParseDict: key = %s\n", key)
+		if log.IsParseLoggerEnabled() {
+			log.Parse.Printf("ParseDict: key = %s\n", key)
+		}
 
 		// position to first non whitespace after key
 		l, eol = trimLeftSpace(l, relaxed)
@@ -518,23 +599,30 @@ func processDictKeys(line *string, relaxed bool) (Dict, error) {
 		// For dicts with kv pairs terminated by eol we accept a missing value as an empty string.
 		if eol {
 			obj := StringLiteral("")
-			log.Parse.Printf("ParseDict: dict[%s]=%v\n", key, obj)
-			if ok := d.Insert(string(*key), obj); !ok {
+			recordDuplicateKey(d, string(key), dupKeys)
+			if log.IsParseLoggerEnabled() {
+				log.Parse.Printf("ParseDict: dict[%s]=%v\n", key, obj)
+			}
+			if ok := d.Insert(string(key), obj); !ok {
 				return nil, errDictionaryDuplicateKey
 			}
 			continue
 		}
 
-		obj, err := parseObject(&l)
+		obj, err := parseObjectWithDupKeys(&l, dupKeys)
 		if err != nil {
 			return nil, err
 		}
 
+		recordDuplicateKey(d, string(key), dupKeys)
+
 		// Specifying the null object as the value of a dictionary entry (7.3.7, "Dictionary Objects")
 		// shall be equivalent to omitting the entry entirely.
 		if obj != nil {
-			log.Parse.Printf("ParseDict: dict[%s]=%v\n", key, obj)
-			if ok := d.Insert(string(*key), obj); !ok {
+			if log.IsParseLoggerEnabled() {
+				log.Parse.Printf("ParseDict: dict[%s]=%v\n", key, obj)
+			}
+			if ok := d.Insert(string(key), obj); !ok {
 				return nil, errDictionaryDuplicateKey
 			}
 		}
@@ -555,14 +643,16 @@ func processDictKeys(line *string, relaxed bool) (Dict, error) {
 	return d, nil
 }
 
-func parseDict(line *string, relaxed bool) (Dict, error) {
+func parseDict(line *string, relaxed bool, dupKeys *[]string) (Dict, error) {
 	if line == nil || len(*line) == 0 {
 		return nil, errNoDictionary
 	}
 
 	l := *line
 
-	log.Parse.Printf("ParseDict: %s\n", l)
+	if log.IsParseLoggerEnabled() {
+		log.Parse.Printf("ParseDict: %s\n", l)
+	}
 
 	if len(l) < 4 || !strings.HasPrefix(l, "<<") {
 		return nil, errDictionaryCorrupt
@@ -579,7 +669,7 @@ func parseDict(line *string, relaxed bool) (Dict, error) {
 		return nil, errDictionaryNotTerminated
 	}
 
-	d, err := processDictKeys(&l, relaxed)
+	d, err := processDictKeys(&l, relaxed, dupKeys)
 	if err != nil {
 		return nil, err
 	}
@@ -589,7 +679,9 @@ func parseDict(line *string, relaxed bool) (Dict, error) {
 
 	*line = l
 
-	log.Parse.Printf("ParseDict: returning dict at: %v\n", d)
+	if log.IsParseLoggerEnabled() {
+		log.Parse.Printf("ParseDict: returning dict at: %v\n", d)
+	}
 
 	return d, nil
 }
@@ -656,7 +748,9 @@ func parseNumericOrIndRef(line *string) (Object, error) {
 		}
 
 		// We have a Float!
-		log.Parse.Printf("parseNumericOrIndRef: value is numeric float: %f\n", f)
+		if log.IsParseLoggerEnabled() {
+			log.Parse.Printf("parseNumericOrIndRef: value is numeric float: %f\n", f)
+		}
 		*line = l1
 		return Float(f), nil
 	}
@@ -665,7 +759,9 @@ func parseNumericOrIndRef(line *string) (Object, error) {
 
 	// if not followed by whitespace return sole integer value.
 	if i1 <= 0 || delimiter(l[i1]) {
-		log.Parse.Printf("parseNumericOrIndRef: value is numeric int: %d\n", i)
+		if log.IsParseLoggerEnabled() {
+			log.Parse.Printf("parseNumericOrIndRef: value is numeric int: %d\n", i)
+		}
 		*line = l1
 		return Integer(i), nil
 	}
@@ -688,7 +784,9 @@ func parseNumericOrIndRef(line *string) (Object, error) {
 	// if only 2 token, can't be indirect reference.
 	// if not followed by whitespace return sole integer value.
 	if i2 <= 0 || delimiter(l[i2]) {
-		log.Parse.Printf("parseNumericOrIndRef: 2 objects => value is numeric int: %d\n", i)
+		if log.IsParseLoggerEnabled() {
+			log.Parse.Printf("parseNumericOrIndRef: 2 objects => value is numeric int: %d\n", i)
+		}
 		*line = l1
 		return Integer(i), nil
 	}
@@ -702,7 +800,9 @@ func parseNumericOrIndRef(line *string) (Object, error) {
 	if err != nil {
 		// 2nd int(generation number) not available.
 		// Can't be an indirect reference.
-		log.Parse.Printf("parseNumericOrIndRef: 3 objects, 2nd no int, value is no indirect ref but numeric int: %d\n", i)
+		if log.IsParseLoggerEnabled() {
+			log.Parse.Printf("parseNumericOrIndRef: 3 objects, 2nd no int, value is no indirect ref but numeric int: %d\n", i)
+		}
 		*line = l1
 		return Integer(i), nil
 	}
@@ -727,13 +827,15 @@ func parseNumericOrIndRef(line *string) (Object, error) {
 
 	// 'R' not available.
 	// Can't be an indirect reference.
-	log.Parse.Printf("parseNumericOrIndRef: value is no indirect ref(no 'R') but numeric int: %d\n", i)
+	if log.IsParseLoggerEnabled() {
+		log.Parse.Printf("parseNumericOrIndRef: value is no indirect ref(no 'R') but numeric int: %d\n", i)
+	}
 	*line = l1
 
 	return Integer(i), nil
 }
 
-func parseHexLiteralOrDict(l *string) (val Object, err error) {
+func parseHexLiteralOrDict(l *string, dupKeys *[]string) (val Object, err error) {
 	if len(*l) < 2 {
 		return nil, errBufNotAvailable
 	}
@@ -744,9 +846,17 @@ func parseHexLiteralOrDict(l *string) (val Object, err error) {
 		var (
 			d   Dict
 			err error
+			n   int
 		)
-		if d, err = parseDict(l, false); err != nil {
-			if d, err = parseDict(l, true); err != nil {
+		if dupKeys != nil {
+			n = len(*dupKeys)
+		}
+		if d, err = parseDict(l, false, dupKeys); err != nil {
+			if dupKeys != nil {
+				// Drop keys recorded by the failed attempt.
+				*dupKeys = (*dupKeys)[:n]
+			}
+			if d, err = parseDict(l, true, dupKeys); err != nil {
 				return nil, err
 			}
 		}
@@ -786,13 +896,20 @@ func parseBooleanOrNull(l string) (val Object, s string, ok bool) {
 
 // parseObject parses next Object from string buffer and returns the updated (left clipped) buffer.
 func parseObject(line *string) (Object, error) {
+	return parseObjectWithDupKeys(line, nil)
+}
+
+// parseObjectWithDupKeys parses next Object from string buffer and records any duplicate dict keys in dupKeys.
+func parseObjectWithDupKeys(line *string, dupKeys *[]string) (Object, error) {
 	if noBuf(line) {
 		return nil, errBufNotAvailable
 	}
 
 	l := *line
 
-	log.Parse.Printf("ParseObject: buf= <%s>\n", l)
+	if log.IsParseLoggerEnabled() {
+		log.Parse.Printf("ParseObject: buf= <%s>\n", l)
+	}
 
 	// position to first non whitespace char
 	l, _ = trimLeftSpace(l, false)
@@ -808,28 +925,31 @@ func parseObject(line *string) (Object, error) {
 
 	case '[': // array
 		log.Parse.Println("ParseObject: value = Array")
-		a, err := parseArray(&l)
+		a, err := parseArray(&l, dupKeys)
 		if err != nil {
 			return nil, err
 		}
 		value = *a
 
 	case '/': // name
-		log.Parse.Println("ParseObject: value = Name Object")
-		nameObj, err := parseName(&l)
+		if log.IsParseLoggerEnabled() {
+			log.Parse.Println("ParseObject: value = Name Object")
+		}
+		value, err = parseNameObject(&l)
 		if err != nil {
 			return nil, err
 		}
-		value = *nameObj
 
 	case '<': // hex literal or dict
-		value, err = parseHexLiteralOrDict(&l)
+		value, err = parseHexLiteralOrDict(&l, dupKeys)
 		if err != nil {
 			return nil, err
 		}
 
 	case '(': // string literal
-		log.Parse.Printf("ParseObject: value = String Literal: <%s>\n", l)
+		if log.IsParseLoggerEnabled() {
+			log.Parse.Printf("ParseObject: value = String Literal: <%s>\n", l)
+		}
 		if value, err = parseStringLiteral(&l); err != nil {
 			return nil, err
 		}
@@ -852,7 +972,9 @@ func parseObject(line *string) (Object, error) {
 
 	}
 
-	log.Parse.Printf("ParseObject returning %v\n", value)
+	if log.IsParseLoggerEnabled() {
+		log.Parse.Printf("ParseObject returning %v\n", value)
+	}
 
 	*line = l
 
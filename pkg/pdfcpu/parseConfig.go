@@ -27,17 +27,34 @@ import (
 )
 
 type configuration struct {
-	Reader15          bool   `yaml:"reader15"`
-	DecodeAllStreams  bool   `yaml:"decodeAllStreams"`
-	ValidationMode    string `yaml:"validationMode"`
-	Eol               string `yaml:"eol"`
-	WriteObjectStream bool   `yaml:"writeObjectStream"`
-	WriteXRefStream   bool   `yaml:"writeXRefStream"`
-	EncryptUsingAES   bool   `yaml:"encryptUsingAES"`
-	EncryptKeyLength  int    `yaml:"encryptKeyLength"`
-	Permissions       int    `yaml:"permissions"`
-	Unit              string `yaml:"unit"`
-	Units             string `yaml:"units"` // Be flexible if version < v0.3.8
+	Reader15              bool   `yaml:"reader15"`
+	DecodeAllStreams      bool   `yaml:"decodeAllStreams"`
+	ValidationMode        string `yaml:"validationMode"`
+	FilterPolicy          string `yaml:"filterPolicy"`
+	SignaturePolicy       string `yaml:"signaturePolicy"`
+	OptimizeResourceDicts bool   `yaml:"optimizeResourceDicts"`
+	ConsolidateResources  bool   `yaml:"consolidateResources"`
+	CompressStreams       bool   `yaml:"compressStreams"`
+	ObjectStreamCacheSize int    `yaml:"objectStreamCacheSize"`
+	ReadWorkers           int    `yaml:"readWorkers"`
+	Eol                   string `yaml:"eol"`
+	WriteObjectStream     bool   `yaml:"writeObjectStream"`
+	WriteXRefStream       bool   `yaml:"writeXRefStream"`
+	Canonical             bool   `yaml:"canonical"`
+	StringForm            string `yaml:"stringForm"`
+	FloatPrecision        int    `yaml:"floatPrecision"`
+	TrimFloatZeros        bool   `yaml:"trimFloatZeros"`
+	MaxContentStreamSize  int    `yaml:"maxContentStreamSize"`
+	TargetVersion         string `yaml:"targetVersion"`
+	EncryptUsingAES       bool   `yaml:"encryptUsingAES"`
+	EncryptKeyLength      int    `yaml:"encryptKeyLength"`
+	Permissions           string `yaml:"permissions"`
+	IgnorePermissions     bool   `yaml:"ignorePermissions"`
+	AuditLog              bool   `yaml:"auditLog"`
+	FileNameTemplate      string `yaml:"fileNameTemplate"`
+	BackupInPlace         bool   `yaml:"backupInPlace"`
+	Unit                  string `yaml:"unit"`
+	Units                 string `yaml:"units"` // Be flexible if version < v0.3.8
 }
 
 func loadedConfig(c configuration, configPath string) *Configuration {
@@ -46,11 +63,25 @@ func loadedConfig(c configuration, configPath string) *Configuration {
 
 	conf.Reader15 = c.Reader15
 	conf.DecodeAllStreams = c.DecodeAllStreams
+	conf.OptimizeResourceDicts = c.OptimizeResourceDicts
+	conf.ConsolidateResources = c.ConsolidateResources
+	conf.CompressStreams = c.CompressStreams
+	conf.ObjectStreamCacheSize = c.ObjectStreamCacheSize
+	conf.ReadWorkers = c.ReadWorkers
 	conf.WriteObjectStream = c.WriteObjectStream
 	conf.WriteXRefStream = c.WriteXRefStream
+	conf.Canonical = c.Canonical
+	conf.FloatPrecision = c.FloatPrecision
+	conf.TrimFloatZeros = c.TrimFloatZeros
+	conf.MaxContentStreamSize = c.MaxContentStreamSize
+	conf.TargetVersion = c.TargetVersion
 	conf.EncryptUsingAES = c.EncryptUsingAES
 	conf.EncryptKeyLength = c.EncryptKeyLength
-	conf.Permissions = int16(c.Permissions)
+	conf.Permissions, _ = ParsePermissions(c.Permissions)
+	conf.IgnorePermissions = c.IgnorePermissions
+	conf.AuditLog = c.AuditLog
+	conf.FileNameTemplate = c.FileNameTemplate
+	conf.BackupInPlace = c.BackupInPlace
 
 	switch c.ValidationMode {
 	case "ValidationStrict":
@@ -59,6 +90,37 @@ func loadedConfig(c configuration, configPath string) *Configuration {
 		conf.ValidationMode = ValidationRelaxed
 	case "ValidationNone":
 		conf.ValidationMode = ValidationNone
+	case "ValidationPedantic":
+		conf.ValidationMode = ValidationPedantic
+	}
+
+	switch c.FilterPolicy {
+	case "FilterPolicyError":
+		conf.FilterPolicy = FilterPolicyError
+	case "FilterPolicyDecode":
+		conf.FilterPolicy = FilterPolicyDecode
+	default:
+		conf.FilterPolicy = FilterPolicyPreserve
+	}
+
+	switch c.StringForm {
+	case "StringFormLiteral":
+		conf.StringForm = StringFormLiteral
+	case "StringFormHex":
+		conf.StringForm = StringFormHex
+	case "StringFormAuto":
+		conf.StringForm = StringFormAuto
+	default:
+		conf.StringForm = StringFormPreserve
+	}
+
+	switch c.SignaturePolicy {
+	case "SignaturePolicyRefuse":
+		conf.SignaturePolicy = SignaturePolicyRefuse
+	case "SignaturePolicyIncremental":
+		conf.SignaturePolicy = SignaturePolicyIncremental
+	default:
+		conf.SignaturePolicy = SignaturePolicyWarn
 	}
 
 	switch c.Eol {
@@ -94,9 +156,27 @@ func parseConfigFile(r io.Reader, configPath string) error {
 		return err
 	}
 
-	if !MemberOf(c.ValidationMode, []string{"ValidationStrict", "ValidationRelaxed", "ValidationNone"}) {
+	if !MemberOf(c.ValidationMode, []string{"ValidationStrict", "ValidationRelaxed", "ValidationNone", "ValidationPedantic"}) {
 		return errors.Errorf("invalid validationMode: %s", c.ValidationMode)
 	}
+	// Config files predating filterPolicy default to FilterPolicyPreserve.
+	if c.FilterPolicy != "" && !MemberOf(c.FilterPolicy, []string{"FilterPolicyPreserve", "FilterPolicyError", "FilterPolicyDecode"}) {
+		return errors.Errorf("invalid filterPolicy: %s", c.FilterPolicy)
+	}
+	// Config files predating stringForm default to StringFormPreserve.
+	if c.StringForm != "" && !MemberOf(c.StringForm, []string{"StringFormPreserve", "StringFormLiteral", "StringFormHex", "StringFormAuto"}) {
+		return errors.Errorf("invalid stringForm: %s", c.StringForm)
+	}
+	if c.FloatPrecision < 0 {
+		return errors.Errorf("invalid floatPrecision: %d", c.FloatPrecision)
+	}
+	if c.MaxContentStreamSize < 0 {
+		return errors.Errorf("invalid maxContentStreamSize: %d", c.MaxContentStreamSize)
+	}
+	// Config files predating signaturePolicy default to SignaturePolicyWarn.
+	if c.SignaturePolicy != "" && !MemberOf(c.SignaturePolicy, []string{"SignaturePolicyWarn", "SignaturePolicyRefuse", "SignaturePolicyIncremental"}) {
+		return errors.Errorf("invalid signaturePolicy: %s", c.SignaturePolicy)
+	}
 	if !MemberOf(c.Eol, []string{"EolLF", "EolCR", "EolCRLF"}) {
 		return errors.Errorf("invalid eol: %s", c.Eol)
 	}
@@ -110,9 +190,28 @@ func parseConfigFile(r io.Reader, configPath string) error {
 		return errors.Errorf("invalid unit: %s", c.Unit)
 	}
 
+	if c.ObjectStreamCacheSize < 0 {
+		return errors.Errorf("invalid objectStreamCacheSize: %d", c.ObjectStreamCacheSize)
+	}
+
+	if c.ReadWorkers < 0 {
+		return errors.Errorf("invalid readWorkers: %d", c.ReadWorkers)
+	}
+
+	if !validTargetVersion(c.TargetVersion) {
+		return errors.Errorf("invalid targetVersion: %s", c.TargetVersion)
+	}
+
 	if !IntMemberOf(c.EncryptKeyLength, []int{40, 128, 256}) {
 		return errors.Errorf("encryptKeyLength possible values: 40, 128, 256, got: %s", c.Unit)
 	}
+
+	if c.Permissions == "" {
+		c.Permissions = "none"
+	}
+	if _, err := ParsePermissions(c.Permissions); err != nil {
+		return errors.Errorf("invalid permissions: %s", c.Permissions)
+	}
 	loadedDefaultConfig = loadedConfig(c, configPath)
 	return nil
 }
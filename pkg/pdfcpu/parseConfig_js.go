@@ -54,12 +54,71 @@ func handleConfValidationMode(v string, c *Configuration) error {
 		c.ValidationMode = ValidationRelaxed
 	case "validationone":
 		c.ValidationMode = ValidationNone
+	case "validationpedantic":
+		c.ValidationMode = ValidationPedantic
 	default:
 		return errors.Errorf("invalid validationMode: %s", v)
 	}
 	return nil
 }
 
+func handleConfFilterPolicy(v string, c *Configuration) error {
+	v1 := strings.ToLower(v)
+	switch v1 {
+	case "filterpolicypreserve":
+		c.FilterPolicy = FilterPolicyPreserve
+	case "filterpolicyerror":
+		c.FilterPolicy = FilterPolicyError
+	case "filterpolicydecode":
+		c.FilterPolicy = FilterPolicyDecode
+	default:
+		return errors.Errorf("invalid filterPolicy: %s", v)
+	}
+	return nil
+}
+
+func handleConfSignaturePolicy(v string, c *Configuration) error {
+	v1 := strings.ToLower(v)
+	switch v1 {
+	case "signaturepolicywarn":
+		c.SignaturePolicy = SignaturePolicyWarn
+	case "signaturepolicyrefuse":
+		c.SignaturePolicy = SignaturePolicyRefuse
+	case "signaturepolicyincremental":
+		c.SignaturePolicy = SignaturePolicyIncremental
+	default:
+		return errors.Errorf("invalid signaturePolicy: %s", v)
+	}
+	return nil
+}
+
+func handleConfOptimizeResourceDicts(k, v string, c *Configuration) error {
+	v = strings.ToLower(v)
+	if v != "true" && v != "false" {
+		return errors.Errorf("config key %s is boolean", k)
+	}
+	c.OptimizeResourceDicts = v == "true"
+	return nil
+}
+
+func handleConfConsolidateResources(k, v string, c *Configuration) error {
+	v = strings.ToLower(v)
+	if v != "true" && v != "false" {
+		return errors.Errorf("config key %s is boolean", k)
+	}
+	c.ConsolidateResources = v == "true"
+	return nil
+}
+
+func handleConfCompressStreams(k, v string, c *Configuration) error {
+	v = strings.ToLower(v)
+	if v != "true" && v != "false" {
+		return errors.Errorf("config key %s is boolean", k)
+	}
+	c.CompressStreams = v == "true"
+	return nil
+}
+
 func handleConfEol(v string, c *Configuration) error {
 	v1 := strings.ToLower(v)
 	switch v1 {
@@ -93,6 +152,40 @@ func handleConfWriteXRefStream(k, v string, c *Configuration) error {
 	return nil
 }
 
+func handleConfCanonical(k, v string, c *Configuration) error {
+	v = strings.ToLower(v)
+	if v != "true" && v != "false" {
+		return errors.Errorf("config key %s is boolean", k)
+	}
+	c.Canonical = v == "true"
+	return nil
+}
+
+func handleConfStringForm(v string, c *Configuration) error {
+	v1 := strings.ToLower(v)
+	switch v1 {
+	case "stringformpreserve":
+		c.StringForm = StringFormPreserve
+	case "stringformliteral":
+		c.StringForm = StringFormLiteral
+	case "stringformhex":
+		c.StringForm = StringFormHex
+	case "stringformauto":
+		c.StringForm = StringFormAuto
+	default:
+		return errors.Errorf("invalid stringForm: %s", v)
+	}
+	return nil
+}
+
+func handleConfTargetVersion(v string, c *Configuration) error {
+	if !validTargetVersion(v) {
+		return errors.Errorf("targetVersion possible values: auto, 1.0 .. 1.7, got: %s", v)
+	}
+	c.TargetVersion = v
+	return nil
+}
+
 func handleConfEncryptUsingAES(k, v string, c *Configuration) error {
 	v = strings.ToLower(v)
 	if v != "true" && v != "false" {
@@ -102,6 +195,51 @@ func handleConfEncryptUsingAES(k, v string, c *Configuration) error {
 	return nil
 }
 
+func handleConfFloatPrecision(v string, c *Configuration) error {
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 0 {
+		return errors.Errorf("floatPrecision is a non negative integer, got: %s", v)
+	}
+	c.FloatPrecision = i
+	return nil
+}
+
+func handleConfTrimFloatZeros(k, v string, c *Configuration) error {
+	v = strings.ToLower(v)
+	if v != "true" && v != "false" {
+		return errors.Errorf("config key %s is boolean", k)
+	}
+	c.TrimFloatZeros = v == "true"
+	return nil
+}
+
+func handleConfMaxContentStreamSize(v string, c *Configuration) error {
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 0 {
+		return errors.Errorf("maxContentStreamSize is a non negative integer, got: %s", v)
+	}
+	c.MaxContentStreamSize = i
+	return nil
+}
+
+func handleConfObjectStreamCacheSize(v string, c *Configuration) error {
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 0 {
+		return errors.Errorf("objectStreamCacheSize is a non negative integer, got: %s", v)
+	}
+	c.ObjectStreamCacheSize = i
+	return nil
+}
+
+func handleConfReadWorkers(v string, c *Configuration) error {
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 0 {
+		return errors.Errorf("readWorkers is a non negative integer, got: %s", v)
+	}
+	c.ReadWorkers = i
+	return nil
+}
+
 func handleConfEncryptKeyLength(v string, c *Configuration) error {
 	i, err := strconv.Atoi(v)
 	if err != nil {
@@ -115,11 +253,43 @@ func handleConfEncryptKeyLength(v string, c *Configuration) error {
 }
 
 func handleConfPermissions(v string, c *Configuration) error {
-	i, err := strconv.Atoi(v)
+	p, err := ParsePermissions(v)
 	if err != nil {
-		return errors.Errorf("permissions is numeric, got: %s", v)
+		return errors.Errorf("invalid permissions: %s", v)
 	}
-	c.Permissions = int16(i)
+	c.Permissions = p
+	return nil
+}
+
+func handleConfIgnorePermissions(k, v string, c *Configuration) error {
+	v = strings.ToLower(v)
+	if v != "true" && v != "false" {
+		return errors.Errorf("config key %s is boolean", k)
+	}
+	c.IgnorePermissions = v == "true"
+	return nil
+}
+
+func handleConfAuditLog(k, v string, c *Configuration) error {
+	v = strings.ToLower(v)
+	if v != "true" && v != "false" {
+		return errors.Errorf("config key %s is boolean", k)
+	}
+	c.AuditLog = v == "true"
+	return nil
+}
+
+func handleConfFileNameTemplate(v string, c *Configuration) error {
+	c.FileNameTemplate = strings.Trim(v, "\"")
+	return nil
+}
+
+func handleConfBackupInPlace(k, v string, c *Configuration) error {
+	v = strings.ToLower(v)
+	if v != "true" && v != "false" {
+		return errors.Errorf("config key %s is boolean", k)
+	}
+	c.BackupInPlace = v == "true"
 	return nil
 }
 
@@ -152,6 +322,27 @@ func parseKeyValue(k, v string, c *Configuration) error {
 	case "validationMode":
 		err = handleConfValidationMode(v, c)
 
+	case "filterPolicy":
+		err = handleConfFilterPolicy(v, c)
+
+	case "signaturePolicy":
+		err = handleConfSignaturePolicy(v, c)
+
+	case "optimizeResourceDicts":
+		err = handleConfOptimizeResourceDicts(k, v, c)
+
+	case "consolidateResources":
+		err = handleConfConsolidateResources(k, v, c)
+
+	case "compressStreams":
+		err = handleConfCompressStreams(k, v, c)
+
+	case "objectStreamCacheSize":
+		err = handleConfObjectStreamCacheSize(v, c)
+
+	case "readWorkers":
+		err = handleConfReadWorkers(v, c)
+
 	case "eol":
 		err = handleConfEol(v, c)
 
@@ -161,6 +352,24 @@ func parseKeyValue(k, v string, c *Configuration) error {
 	case "writeXRefStream":
 		err = handleConfWriteXRefStream(k, v, c)
 
+	case "canonical":
+		err = handleConfCanonical(k, v, c)
+
+	case "stringForm":
+		err = handleConfStringForm(v, c)
+
+	case "floatPrecision":
+		err = handleConfFloatPrecision(v, c)
+
+	case "trimFloatZeros":
+		err = handleConfTrimFloatZeros(k, v, c)
+
+	case "maxContentStreamSize":
+		err = handleConfMaxContentStreamSize(v, c)
+
+	case "targetVersion":
+		err = handleConfTargetVersion(v, c)
+
 	case "encryptUsingAES":
 		err = handleConfEncryptUsingAES(k, v, c)
 
@@ -170,6 +379,18 @@ func parseKeyValue(k, v string, c *Configuration) error {
 	case "permissions":
 		err = handleConfPermissions(v, c)
 
+	case "ignorePermissions":
+		err = handleConfIgnorePermissions(k, v, c)
+
+	case "auditLog":
+		err = handleConfAuditLog(k, v, c)
+
+	case "fileNameTemplate":
+		err = handleConfFileNameTemplate(v, c)
+
+	case "backupInPlace":
+		err = handleConfBackupInPlace(k, v, c)
+
 	case "unit", "units":
 		err = handleConfUnit(v, c)
 	}
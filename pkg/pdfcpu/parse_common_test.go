@@ -110,3 +110,37 @@ func TestParseObject(t *testing.T) {
 	doTestParseObjectOK("<</n 1 0 R>>", t)
 	doTestParseObjectOK("(!\\(S:\\356[\\272H\\355>>R{sb\\007)", t)
 }
+
+func TestParseInternedNames(t *testing.T) {
+	s := "<</Type/Page/Custom#20Name/Font>>"
+	o, err := parseObject(&s)
+	if err != nil {
+		t.Fatalf("parseObject failed: <%v>\n", err)
+	}
+
+	d, ok := o.(Dict)
+	if !ok {
+		t.Fatalf("parseObject: expected Dict, got %T\n", o)
+	}
+
+	if n := d.NameEntry("Type"); n == nil || *n != "Page" {
+		t.Errorf("parseObject: Type: expected Page, got %v\n", d["Type"])
+	}
+
+	if n := d.NameEntry("Custom#20Name"); n == nil || *n != "Font" {
+		t.Errorf("parseObject: Custom#20Name: expected Font, got %v\n", d["Custom#20Name"])
+	}
+}
+
+func TestParseInternedNamesAllocs(t *testing.T) {
+	buf := "/FlateDecode"
+	allocs := testing.AllocsPerRun(100, func() {
+		s := buf
+		if _, err := parseObject(&s); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("parseObject: interned name: want 0 allocs, got %.1f\n", allocs)
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package pdfcpu
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -157,3 +159,52 @@ func TestParseDict(t *testing.T) {
 	doTestParseDictIndirectRefs(t)
 	doTestParseDictWithComments(t)
 }
+
+func TestParseDictDuplicateKeys(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want []string
+	}{
+		{"<</Key1/Value1/Key2/Value2>>", nil},
+		{"<</Key1/Value1/Key1/Value2>>", []string{"Key1"}},
+		{"<</A<</B 1/B 2>>/A 3>>", []string{"B", "A"}},
+		{"[<</K 1/K 2>>]", []string{"K"}},
+		// kv pairs terminated by eol.
+		{"<</T \x0a/B 1/T \x0a>>", []string{"T"}},
+	} {
+		var dupKeys []string
+		s := tt.s
+		if _, err := parseObjectWithDupKeys(&s, &dupKeys); err != nil {
+			t.Fatalf("%s: %v\n", tt.s, err)
+		}
+		if !reflect.DeepEqual(dupKeys, tt.want) {
+			t.Errorf("%s: want duplicate keys %v, got %v\n", tt.s, tt.want, dupKeys)
+		}
+	}
+}
+
+func TestObjectStreamDuplicateKeys(t *testing.T) {
+	ctx := &Context{XRefTable: &XRefTable{}}
+
+	prolog := "10 0 11 15 "
+	content := prolog + "<</A 1/A 2>>   <</B 1/B 2>>"
+	osd := ObjectStreamDict{ObjCount: 2, FirstObjOffset: len(prolog)}
+	osd.Content = []byte(content)
+
+	if err := parseObjectStream(ctx, &osd); err != nil {
+		t.Fatal(err)
+	}
+	if len(osd.ObjArray) != 2 {
+		t.Fatalf("want 2 objects, got %d\n", len(osd.ObjArray))
+	}
+
+	want := []string{"obj#10: duplicate dict key A", "obj#11: duplicate dict key B"}
+	if len(ctx.Warnings) != len(want) {
+		t.Fatalf("want %d warnings, got %v\n", len(want), ctx.Warnings)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(ctx.Warnings[i], w) {
+			t.Errorf("want warning %q, got %q\n", w, ctx.Warnings[i])
+		}
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Objects are independent of each other as far as parsing is concerned.
+// Large files get read faster by parsing objects and decoding object streams concurrently
+// and committing the results to the xref table in object number order afterwards.
+// This way the outcome including errors and warnings does not depend on the number of goroutines in use.
+// Objects get prefetched in windows of limited size and decoded object streams go into the object stream cache,
+// so memory usage stays within the bounds of sequential reading.
+
+// prefetchWindowSize is the max number of objects loaded ahead of dereferencing.
+const prefetchWindowSize = 256
+
+type prefetchResult struct {
+	o        Object // Object including stream data.
+	err      error
+	warnings []string // Warnings recorded while loading.
+}
+
+type prefetchJob struct {
+	objNr int
+	entry *XRefTableEntry
+	osd   *ObjectStreamDict // Set for object streams.
+	r     *prefetchResult
+}
+
+// readWorkers returns the number of goroutines to be used for parsing objects.
+// Concurrent parsing needs random access to the input.
+// While tracing the parser objects get parsed sequentially in order to keep trace events in file order.
+func readWorkers(ctx *Context) int {
+	if ctx.Read.ra == nil || ctx.tracing() {
+		return 1
+	}
+	if ctx.ReadWorkers > 0 {
+		return ctx.ReadWorkers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// parsedObjectStream returns a copy of osd with all its objects parsed.
+func parsedObjectStream(ctx *Context, osd ObjectStreamDict, objNr int) (*ObjectStreamDict, error) {
+	osd.ObjArray = nil
+	if len(osd.Raw) == 0 {
+		// Nothing to decode for empty streams.
+		osd.Content = osd.Raw
+	} else if err := osd.Decode(); err != nil {
+		return nil, errors.Wrapf(err, "decodedObjectStream: problem decoding object stream %d", objNr)
+	}
+
+	// Parse all objects of this object stream and save them to ObjectStreamDict.ObjArray.
+	if err := parseObjectStream(ctx, &osd); err != nil {
+		return nil, errors.Wrapf(err, "decodedObjectStream: problem decoding object stream %d\n", objNr)
+	}
+
+	if osd.ObjArray == nil {
+		return nil, errors.New("pdfcpu: decodedObjectStream: objArray should be set")
+	}
+
+	return &osd, nil
+}
+
+// objectStreamJob returns a job decoding the object stream objNr unless it is decoded already.
+func objectStreamJob(ctx *Context, objNr int) (prefetchJob, bool) {
+	entry, ok := ctx.Find(objNr)
+	if !ok {
+		return prefetchJob{}, false
+	}
+	osd, ok := entry.Object.(ObjectStreamDict)
+	if !ok || osd.ObjArray != nil {
+		return prefetchJob{}, false
+	}
+	return prefetchJob{objNr: objNr, entry: entry, osd: &osd, r: &prefetchResult{}}, true
+}
+
+// nextPrefetchWindow returns the leading object numbers of objNrs to be dereferenced next
+// along with the jobs loading their objects and decoding the object streams they need.
+// A window needs no more object streams than the object stream cache holds.
+func nextPrefetchWindow(ctx *Context, objNrs []int) ([]int, []prefetchJob) {
+	var jj []prefetchJob
+	objStreams := map[int]bool{}
+	limit := ctx.ObjectStreamCacheSize
+
+	i := 0
+	for ; i < len(objNrs) && len(jj) < prefetchWindowSize; i++ {
+		entry := ctx.Table[objNrs[i]]
+		if entry.Free {
+			continue
+		}
+
+		if entry.Compressed {
+			osNr := *entry.ObjectStream
+			if objStreams[osNr] {
+				continue
+			}
+			if limit > 0 && len(objStreams) == limit {
+				break
+			}
+			objStreams[osNr] = true
+			if j, ok := objectStreamJob(ctx, osNr); ok {
+				jj = append(jj, j)
+			} else {
+				// Keep the decoded object stream cached while dereferencing this window.
+				touchObjectStreamCache(ctx, osNr)
+			}
+			continue
+		}
+
+		if entry.Object != nil || entry.Offset == nil || *entry.Offset == 0 {
+			continue
+		}
+		jj = append(jj, prefetchJob{objNr: objNrs[i], entry: entry, r: &prefetchResult{}})
+	}
+
+	return objNrs[:i], jj
+}
+
+// prefetchObjects runs jj using n goroutines.
+// Decoded object streams go into the object stream cache, all other results are returned by object number.
+func prefetchObjects(ctx *Context, jj []prefetchJob, n int) map[int]*prefetchResult {
+	ctx.Log.Read().Printf("prefetchObjects: %d objects using %d goroutines\n", len(jj), n)
+
+	jobs := make(chan prefetchJob)
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if err := ctx.Canceled(); err != nil {
+					j.r.err = err
+					continue
+				}
+				// Hold back warnings until the result gets committed.
+				jctx := *ctx
+				jctx.warnings = &j.r.warnings
+				if j.osd == nil {
+					j.r.o, j.r.err = loadObject(&jctx, j.objNr, j.entry)
+					continue
+				}
+				osd, err := parsedObjectStream(&jctx, *j.osd, j.objNr)
+				if err == nil {
+					j.r.o = *osd
+				}
+				j.r.err = err
+			}
+		}()
+	}
+
+	for _, j := range jj {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+
+	m := map[int]*prefetchResult{}
+	for _, j := range jj {
+		m[j.objNr] = j.r
+		if j.osd == nil || j.r.err != nil {
+			// Failed object streams get decoded again on demand reporting the error.
+			continue
+		}
+		j.entry.Object = j.r.o
+		j.r.o = nil
+		ctx.Read.ObjStmCacheMisses++
+		ctx.Read.objStreamCache = append(ctx.Read.objStreamCache, j.objNr)
+	}
+	trimObjectStreamCache(ctx)
+
+	return m
+}
+
+// commit records the warnings of r.
+func (r *prefetchResult) commit(ctx *Context) {
+	for _, s := range r.warnings {
+		ctx.Warn("%s", s)
+	}
+	r.warnings = nil
+}
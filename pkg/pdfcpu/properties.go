@@ -48,9 +48,12 @@ func PropertiesAdd(xRefTable *XRefTable, properties map[string]string) error {
 	}
 	for k, v := range properties {
 		k1 := UTF8ToCP1252(k)
-		v1 := UTF8ToCP1252(v)
-		d[k1] = StringLiteral(v1)
-		xRefTable.Properties[k1] = v1
+		sl, err := textLiteral(v)
+		if err != nil {
+			return err
+		}
+		d[k1] = sl
+		xRefTable.Properties[k1] = v
 	}
 	return nil
 }
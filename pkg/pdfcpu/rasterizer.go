@@ -0,0 +1,51 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"image"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNoRasterizer is returned when rendering a page without a registered Rasterizer.
+var ErrNoRasterizer = errors.New("pdfcpu: no rasterizer registered")
+
+// Rasterizer renders page pageNr of ctx at a resolution of dpi.
+// pdfcpu does not render pages itself, a rasterizer backed by a rendering engine needs to be registered.
+// ctx may be a partially loaded context holding the objects of pageNr only, see ReadPages.
+type Rasterizer func(ctx *Context, pageNr, dpi int) (image.Image, error)
+
+var (
+	rasterizerMu sync.RWMutex
+	rasterizer   Rasterizer
+)
+
+// RegisterRasterizer registers r for rendering pages, nil removes the registered rasterizer.
+func RegisterRasterizer(r Rasterizer) {
+	rasterizerMu.Lock()
+	defer rasterizerMu.Unlock()
+	rasterizer = r
+}
+
+// RegisteredRasterizer returns the registered rasterizer.
+func RegisteredRasterizer() (Rasterizer, bool) {
+	rasterizerMu.RLock()
+	defer rasterizerMu.RUnlock()
+	return rasterizer, rasterizer != nil
+}
@@ -19,6 +19,7 @@ package pdfcpu
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"io"
 	"os"
 	"sort"
@@ -56,14 +57,33 @@ func ReadFile(inFile string, conf *Configuration) (*Context, error) {
 // Read takes a readSeeker and generates a Context,
 // an in-memory representation containing a cross reference table.
 func Read(rs io.ReadSeeker, conf *Configuration) (*Context, error) {
+	return ReadWithContext(context.Background(), rs, conf)
+}
+
+// ReadWithContext is like Read but stops with c's error once c is done.
+// The resulting Context keeps c for any subsequent validation, optimization and writing.
+func ReadWithContext(c context.Context, rs io.ReadSeeker, conf *Configuration) (*Context, error) {
+	return read(c, rs, conf, nil)
+}
+
+// ReadPages is like ReadWithContext but only loads the trailer, the catalog, the page tree
+// and the objects used by the pages returned by selectPages for the page count of the document.
+// All other objects are skipped and resolve to null.
+func ReadPages(c context.Context, rs io.ReadSeeker, conf *Configuration, selectPages func(pageCount int) (IntSet, error)) (*Context, error) {
+	return read(c, rs, conf, selectPages)
+}
 
-	log.Read.Println("Read: begin")
+func read(c context.Context, rs io.ReadSeeker, conf *Configuration, selectPages func(pageCount int) (IntSet, error)) (*Context, error) {
 
 	ctx, err := NewContext(rs, conf)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx.Log.Read().Println("Read: begin")
+
+	ctx.SetCancelContext(c)
+
 	if ctx.Reader15 {
 		log.Info.Println("PDF Version 1.5 conforming reader")
 	} else {
@@ -77,7 +97,7 @@ func Read(rs io.ReadSeeker, conf *Configuration) (*Context, error) {
 
 	// Make all objects explicitly available (load into memory) in corresponding xRefTable entries.
 	// Also decode any involved object streams.
-	if err = dereferenceXRefTable(ctx, conf); err != nil {
+	if err = dereferenceXRefTable(ctx, selectPages); err != nil {
 		return nil, err
 	}
 
@@ -86,7 +106,7 @@ func Read(rs io.ReadSeeker, conf *Configuration) (*Context, error) {
 		*ctx.XRefTable.Size = len(ctx.XRefTable.Table)
 	}
 
-	log.Read.Println("Read: end")
+	ctx.Log.Read().Println("Read: end")
 
 	return ctx, nil
 }
@@ -138,13 +158,13 @@ func scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
 	return 0, nil, nil
 }
 
-func newPositionedReader(rs io.ReadSeeker, offset *int64) (*bufio.Reader, error) {
+func newPositionedReader(ctx *Context, rs io.ReadSeeker, offset *int64) (*bufio.Reader, error) {
 
 	if _, err := rs.Seek(*offset, io.SeekStart); err != nil {
 		return nil, err
 	}
 
-	log.Read.Printf("newPositionedReader: positioned to offset: %d\n", *offset)
+	ctx.Log.Read().Printf("newPositionedReader: positioned to offset: %d\n", *offset)
 
 	return bufio.NewReader(rs), nil
 }
@@ -169,7 +189,7 @@ func offsetLastXRefSection(ctx *Context, skip int64) (*int64, error) {
 			return nil, errors.New("pdfcpu: can't find last xref section")
 		}
 
-		log.Read.Printf("scanning for offsetLastXRefSection starting at %d\n", off)
+		ctx.Log.Read().Printf("scanning for offsetLastXRefSection starting at %d\n", off)
 
 		curBuf := make([]byte, bufSize)
 
@@ -202,7 +222,8 @@ func offsetLastXRefSection(ctx *Context, skip int64) (*int64, error) {
 		}
 	}
 
-	log.Read.Printf("Offset last xrefsection: %d\n", offset)
+	ctx.Log.Read().Printf("Offset last xrefsection: %d\n", offset)
+	ctx.trace(TraceStartXRef, offset, 0, "")
 
 	return &offset, nil
 }
@@ -210,7 +231,7 @@ func offsetLastXRefSection(ctx *Context, skip int64) (*int64, error) {
 // Read next subsection entry and generate corresponding xref table entry.
 func parseXRefTableEntry(s *bufio.Scanner, xRefTable *XRefTable, objectNumber, repairOff int) error {
 
-	log.Read.Println("parseXRefTableEntry: begin")
+	xRefTable.Log.Read().Println("parseXRefTableEntry: begin")
 
 	line, err := scanLine(s)
 	if err != nil {
@@ -218,7 +239,7 @@ func parseXRefTableEntry(s *bufio.Scanner, xRefTable *XRefTable, objectNumber, r
 	}
 
 	if xRefTable.Exists(objectNumber) {
-		log.Read.Printf("parseXRefTableEntry: end - Skip entry %d - already assigned\n", objectNumber)
+		xRefTable.Log.Read().Printf("parseXRefTableEntry: end - Skip entry %d - already assigned\n", objectNumber)
 		return nil
 	}
 
@@ -250,7 +271,7 @@ func parseXRefTableEntry(s *bufio.Scanner, xRefTable *XRefTable, objectNumber, r
 
 		// in use object
 
-		log.Read.Printf("parseXRefTableEntry: Object #%d is in use at offset=%d, generation=%d\n", objectNumber, offset, generation)
+		xRefTable.Log.Read().Printf("parseXRefTableEntry: Object #%d is in use at offset=%d, generation=%d\n", objectNumber, offset, generation)
 
 		if offset == 0 {
 			log.Info.Printf("parseXRefTableEntry: Skip entry for in use object #%d with offset 0\n", objectNumber)
@@ -267,7 +288,7 @@ func parseXRefTableEntry(s *bufio.Scanner, xRefTable *XRefTable, objectNumber, r
 
 		// free object
 
-		log.Read.Printf("parseXRefTableEntry: Object #%d is unused, next free is object#%d, generation=%d\n", objectNumber, offset, generation)
+		xRefTable.Log.Read().Printf("parseXRefTableEntry: Object #%d is unused, next free is object#%d, generation=%d\n", objectNumber, offset, generation)
 
 		xRefTableEntry =
 			XRefTableEntry{
@@ -277,11 +298,11 @@ func parseXRefTableEntry(s *bufio.Scanner, xRefTable *XRefTable, objectNumber, r
 
 	}
 
-	log.Read.Printf("parseXRefTableEntry: Insert new xreftable entry for Object %d\n", objectNumber)
+	xRefTable.Log.Read().Printf("parseXRefTableEntry: Insert new xreftable entry for Object %d\n", objectNumber)
 
 	xRefTable.Table[objectNumber] = &xRefTableEntry
 
-	log.Read.Println("parseXRefTableEntry: end")
+	xRefTable.Log.Read().Println("parseXRefTableEntry: end")
 
 	return nil
 }
@@ -289,7 +310,7 @@ func parseXRefTableEntry(s *bufio.Scanner, xRefTable *XRefTable, objectNumber, r
 // Process xRef table subsection and create corrresponding xRef table entries.
 func parseXRefTableSubSection(s *bufio.Scanner, xRefTable *XRefTable, fields []string, repairOff int) error {
 
-	log.Read.Println("parseXRefTableSubSection: begin")
+	xRefTable.Log.Read().Println("parseXRefTableSubSection: begin")
 
 	startObjNumber, err := strconv.Atoi(fields[0])
 	if err != nil {
@@ -301,7 +322,7 @@ func parseXRefTableSubSection(s *bufio.Scanner, xRefTable *XRefTable, fields []s
 		return err
 	}
 
-	log.Read.Printf("detected xref subsection, startObj=%d length=%d\n", startObjNumber, objCount)
+	xRefTable.Log.Read().Printf("detected xref subsection, startObj=%d length=%d\n", startObjNumber, objCount)
 
 	// Process all entries of this subsection into xRefTable entries.
 	for i := 0; i < objCount; i++ {
@@ -310,17 +331,17 @@ func parseXRefTableSubSection(s *bufio.Scanner, xRefTable *XRefTable, fields []s
 		}
 	}
 
-	log.Read.Println("parseXRefTableSubSection: end")
+	xRefTable.Log.Read().Println("parseXRefTableSubSection: end")
 
 	return nil
 }
 
-// Parse compressed object.
-func compressedObject(s string) (Object, error) {
+// Parse compressed object and record any duplicate dict keys in dupKeys.
+func compressedObject(ctx *Context, s string, dupKeys *[]string) (Object, error) {
 
-	log.Read.Println("compressedObject: begin")
+	ctx.Log.Read().Println("compressedObject: begin")
 
-	o, err := parseObject(&s)
+	o, err := parseObjectWithDupKeys(&s, dupKeys)
 	if err != nil {
 		return nil, err
 	}
@@ -328,24 +349,40 @@ func compressedObject(s string) (Object, error) {
 	d, ok := o.(Dict)
 	if !ok {
 		// return trivial Object: Integer, Array, etc.
-		log.Read.Println("compressedObject: end, any other than dict")
+		ctx.Log.Read().Println("compressedObject: end, any other than dict")
 		return o, nil
 	}
 
 	streamLength, streamLengthRef := d.Length()
 	if streamLength == nil && streamLengthRef == nil {
 		// return Dict
-		log.Read.Println("compressedObject: end, dict")
+		ctx.Log.Read().Println("compressedObject: end, dict")
 		return d, nil
 	}
 
 	return nil, errors.New("pdfcpu: compressedObject: stream objects are not to be stored in an object stream")
 }
 
+func compressedStreamObject(ctx *Context, s, objNr string) (Object, error) {
+	var dupKeys []string
+	o, err := compressedObject(ctx, s, &dupKeys)
+	if err != nil {
+		return nil, err
+	}
+	if len(dupKeys) > 0 {
+		i, err := strconv.Atoi(objNr)
+		if err != nil {
+			return nil, err
+		}
+		warnDuplicateKeys(ctx, i, dupKeys)
+	}
+	return o, nil
+}
+
 // Parse all objects of an object stream and save them into objectStreamDict.ObjArray.
-func parseObjectStream(osd *ObjectStreamDict) error {
+func parseObjectStream(ctx *Context, osd *ObjectStreamDict) error {
 
-	log.Read.Printf("parseObjectStream begin: decoding %d objects.\n", osd.ObjCount)
+	ctx.Log.Read().Printf("parseObjectStream begin: decoding %d objects.\n", osd.ObjCount)
 
 	decodedContent := osd.Content
 	prolog := decodedContent[:osd.FirstObjOffset]
@@ -376,25 +413,25 @@ func parseObjectStream(osd *ObjectStreamDict) error {
 
 		if i > 0 {
 			dstr := string(decodedContent[offsetOld:offset])
-			log.Read.Printf("parseObjectStream: objString = %s\n", dstr)
-			o, err := compressedObject(dstr)
+			ctx.Log.Read().Printf("parseObjectStream: objString = %s\n", dstr)
+			o, err := compressedStreamObject(ctx, dstr, objs[i-2])
 			if err != nil {
 				return err
 			}
 
-			log.Read.Printf("parseObjectStream: [%d] = obj %s:\n%s\n", i/2-1, objs[i-2], o)
+			ctx.Log.Read().Printf("parseObjectStream: [%d] = obj %s:\n%s\n", i/2-1, objs[i-2], o)
 			objArray = append(objArray, o)
 		}
 
 		if i == len(objs)-2 {
 			dstr := string(decodedContent[offset:])
-			log.Read.Printf("parseObjectStream: objString = %s\n", dstr)
-			o, err := compressedObject(dstr)
+			ctx.Log.Read().Printf("parseObjectStream: objString = %s\n", dstr)
+			o, err := compressedStreamObject(ctx, dstr, objs[i])
 			if err != nil {
 				return err
 			}
 
-			log.Read.Printf("parseObjectStream: [%d] = obj %s:\n%s\n", i/2, objs[i], o)
+			ctx.Log.Read().Printf("parseObjectStream: [%d] = obj %s:\n%s\n", i/2, objs[i], o)
 			objArray = append(objArray, o)
 		}
 
@@ -403,7 +440,7 @@ func parseObjectStream(osd *ObjectStreamDict) error {
 
 	osd.ObjArray = objArray
 
-	log.Read.Println("parseObjectStream end")
+	ctx.Log.Read().Println("parseObjectStream end")
 
 	return nil
 }
@@ -411,7 +448,7 @@ func parseObjectStream(osd *ObjectStreamDict) error {
 // For each object embedded in this xRefStream create the corresponding xRef table entry.
 func extractXRefTableEntriesFromXRefStream(buf []byte, xsd *XRefStreamDict, ctx *Context) error {
 
-	log.Read.Printf("extractXRefTableEntriesFromXRefStream begin")
+	ctx.Log.Read().Printf("extractXRefTableEntriesFromXRefStream begin")
 
 	// Note:
 	// A value of zero for an element in the W array indicates that the corresponding field shall not be present in the stream,
@@ -423,16 +460,16 @@ func extractXRefTableEntriesFromXRefStream(buf []byte, xsd *XRefStreamDict, ctx
 	i3 := xsd.W[2]
 
 	xrefEntryLen := i1 + i2 + i3
-	log.Read.Printf("extractXRefTableEntriesFromXRefStream: begin xrefEntryLen = %d\n", xrefEntryLen)
+	ctx.Log.Read().Printf("extractXRefTableEntriesFromXRefStream: begin xrefEntryLen = %d\n", xrefEntryLen)
 
 	if len(buf)%xrefEntryLen > 0 {
 		return errors.New("pdfcpu: extractXRefTableEntriesFromXRefStream: corrupt xrefstream")
 	}
 
 	objCount := len(xsd.Objects)
-	log.Read.Printf("extractXRefTableEntriesFromXRefStream: objCount:%d %v\n", objCount, xsd.Objects)
+	ctx.Log.Read().Printf("extractXRefTableEntriesFromXRefStream: objCount:%d %v\n", objCount, xsd.Objects)
 
-	log.Read.Printf("extractXRefTableEntriesFromXRefStream: len(buf):%d objCount*xrefEntryLen:%d\n", len(buf), objCount*xrefEntryLen)
+	ctx.Log.Read().Printf("extractXRefTableEntriesFromXRefStream: len(buf):%d objCount*xrefEntryLen:%d\n", len(buf), objCount*xrefEntryLen)
 	if len(buf) < objCount*xrefEntryLen {
 		// Sometimes there is an additional xref entry not accounted for by "Index".
 		// We ignore such entries and do not treat this as an error.
@@ -466,7 +503,7 @@ func extractXRefTableEntriesFromXRefStream(buf []byte, xsd *XRefStreamDict, ctx
 
 		case 0x00:
 			// free object
-			log.Read.Printf("extractXRefTableEntriesFromXRefStream: Object #%d is unused, next free is object#%d, generation=%d\n", objectNumber, c2, c3)
+			ctx.Log.Read().Printf("extractXRefTableEntriesFromXRefStream: Object #%d is unused, next free is object#%d, generation=%d\n", objectNumber, c2, c3)
 			g := int(c3)
 
 			xRefTableEntry =
@@ -478,7 +515,7 @@ func extractXRefTableEntriesFromXRefStream(buf []byte, xsd *XRefStreamDict, ctx
 
 		case 0x01:
 			// in use object
-			log.Read.Printf("extractXRefTableEntriesFromXRefStream: Object #%d is in use at offset=%d, generation=%d\n", objectNumber, c2, c3)
+			ctx.Log.Read().Printf("extractXRefTableEntriesFromXRefStream: Object #%d is in use at offset=%d, generation=%d\n", objectNumber, c2, c3)
 			g := int(c3)
 
 			xRefTableEntry =
@@ -491,7 +528,7 @@ func extractXRefTableEntriesFromXRefStream(buf []byte, xsd *XRefStreamDict, ctx
 		case 0x02:
 			// compressed object
 			// generation always 0.
-			log.Read.Printf("extractXRefTableEntriesFromXRefStream: Object #%d is compressed at obj %5d[%d]\n", objectNumber, c2, c3)
+			ctx.Log.Read().Printf("extractXRefTableEntriesFromXRefStream: Object #%d is compressed at obj %5d[%d]\n", objectNumber, c2, c3)
 			objNumberRef := int(c2)
 			objIndex := int(c3)
 
@@ -507,7 +544,7 @@ func extractXRefTableEntriesFromXRefStream(buf []byte, xsd *XRefStreamDict, ctx
 		}
 
 		if ctx.XRefTable.Exists(objectNumber) {
-			log.Read.Printf("extractXRefTableEntriesFromXRefStream: Skip entry %d - already assigned\n", objectNumber)
+			ctx.Log.Read().Printf("extractXRefTableEntriesFromXRefStream: Skip entry %d - already assigned\n", objectNumber)
 		} else {
 			ctx.Table[objectNumber] = &xRefTableEntry
 		}
@@ -515,7 +552,7 @@ func extractXRefTableEntriesFromXRefStream(buf []byte, xsd *XRefStreamDict, ctx
 		j++
 	}
 
-	log.Read.Println("extractXRefTableEntriesFromXRefStream: end")
+	ctx.Log.Read().Println("extractXRefTableEntriesFromXRefStream: end")
 
 	return nil
 }
@@ -540,7 +577,7 @@ func xRefStreamDict(ctx *Context, o Object, objNr int, streamOffset int64) (*XRe
 	}
 
 	// We have a stream object.
-	log.Read.Printf("xRefStreamDict: streamobject #%d\n", objNr)
+	ctx.Log.Read().Printf("xRefStreamDict: streamobject #%d\n", objNr)
 	sd := NewStreamDict(d, streamOffset, streamLength, streamLengthObjNr, filterPipeline)
 
 	if _, err = loadEncodedStreamContent(ctx, &sd); err != nil {
@@ -548,7 +585,7 @@ func xRefStreamDict(ctx *Context, o Object, objNr int, streamOffset int64) (*XRe
 	}
 
 	// Decode xrefstream content
-	if err = saveDecodedStreamContent(nil, &sd, 0, 0, true); err != nil {
+	if err = saveDecodedStreamContent(ctx, &sd, objNr, 0, true); err != nil {
 		return nil, errors.Wrapf(err, "xRefStreamDict: cannot decode stream for obj#:%d\n", objNr)
 	}
 
@@ -558,14 +595,14 @@ func xRefStreamDict(ctx *Context, o Object, objNr int, streamOffset int64) (*XRe
 // Parse xRef stream and setup xrefTable entries for all embedded objects and the xref stream dict.
 func parseXRefStream(rd io.Reader, offset *int64, ctx *Context) (prevOffset *int64, err error) {
 
-	log.Read.Printf("parseXRefStream: begin at offset %d\n", *offset)
+	ctx.Log.Read().Printf("parseXRefStream: begin at offset %d\n", *offset)
 
-	buf, endInd, streamInd, streamOffset, err := buffer(rd)
+	buf, endInd, streamInd, streamOffset, err := buffer(ctx, rd)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Read.Printf("parseXRefStream: endInd=%[1]d(%[1]x) streamInd=%[2]d(%[2]x)\n", endInd, streamInd)
+	ctx.Log.Read().Printf("parseXRefStream: endInd=%[1]d(%[1]x) streamInd=%[2]d(%[2]x)\n", endInd, streamInd)
 
 	line := string(buf)
 
@@ -584,14 +621,15 @@ func parseXRefStream(rd io.Reader, offset *int64, ctx *Context) (prevOffset *int
 	}
 
 	// parse this object
-	log.Read.Printf("parseXRefStream: xrefstm obj#:%d gen:%d\n", *objectNumber, *generationNumber)
-	log.Read.Printf("parseXRefStream: dereferencing object %d\n", *objectNumber)
+	ctx.Log.Read().Printf("parseXRefStream: xrefstm obj#:%d gen:%d\n", *objectNumber, *generationNumber)
+	ctx.trace(TraceXRefStream, *offset, *objectNumber, "")
+	ctx.Log.Read().Printf("parseXRefStream: dereferencing object %d\n", *objectNumber)
 	o, err := parseObject(&l)
 	if err != nil {
 		return nil, errors.Wrapf(err, "parseXRefStream: no object")
 	}
 
-	log.Read.Printf("parseXRefStream: we have an object: %s\n", o)
+	ctx.Log.Read().Printf("parseXRefStream: we have an object: %s\n", o)
 
 	streamOffset += *offset
 	sd, err := xRefStreamDict(ctx, o, *objectNumber, streamOffset)
@@ -618,13 +656,13 @@ func parseXRefStream(rd io.Reader, offset *int64, ctx *Context) (prevOffset *int
 			Generation: generationNumber,
 			Object:     *sd}
 
-	log.Read.Printf("parseXRefStream: Insert new xRefTable entry for Object %d\n", *objectNumber)
+	ctx.Log.Read().Printf("parseXRefStream: Insert new xRefTable entry for Object %d\n", *objectNumber)
 
 	ctx.Table[*objectNumber] = &entry
 	ctx.Read.XRefStreams[*objectNumber] = true
 	prevOffset = sd.PreviousOffset
 
-	log.Read.Println("parseXRefStream: end")
+	ctx.Log.Read().Println("parseXRefStream: end")
 
 	return prevOffset, nil
 }
@@ -632,9 +670,9 @@ func parseXRefStream(rd io.Reader, offset *int64, ctx *Context) (prevOffset *int
 // Parse an xRefStream for a hybrid PDF file.
 func parseHybridXRefStream(offset *int64, ctx *Context) error {
 
-	log.Read.Println("parseHybridXRefStream: begin")
+	ctx.Log.Read().Println("parseHybridXRefStream: begin")
 
-	rd, err := newPositionedReader(ctx.Read.rs, offset)
+	rd, err := newPositionedReader(ctx, ctx.Read.rs, offset)
 	if err != nil {
 		return err
 	}
@@ -644,7 +682,7 @@ func parseHybridXRefStream(offset *int64, ctx *Context) error {
 		return err
 	}
 
-	log.Read.Println("parseHybridXRefStream: end")
+	ctx.Log.Read().Println("parseHybridXRefStream: end")
 
 	return nil
 }
@@ -652,13 +690,13 @@ func parseHybridXRefStream(offset *int64, ctx *Context) error {
 // Parse trailer dict and return any offset of a previous xref section.
 func parseTrailerInfo(d Dict, xRefTable *XRefTable) error {
 
-	log.Read.Println("parseTrailerInfo begin")
+	xRefTable.Log.Read().Println("parseTrailerInfo begin")
 
 	if _, found := d.Find("Encrypt"); found {
 		encryptObjRef := d.IndirectRefEntry("Encrypt")
 		if encryptObjRef != nil {
 			xRefTable.Encrypt = encryptObjRef
-			log.Read.Printf("parseTrailerInfo: Encrypt object: %s\n", *xRefTable.Encrypt)
+			xRefTable.Log.Read().Printf("parseTrailerInfo: Encrypt object: %s\n", *xRefTable.Encrypt)
 		}
 	}
 
@@ -678,14 +716,14 @@ func parseTrailerInfo(d Dict, xRefTable *XRefTable) error {
 			return errors.New("pdfcpu: parseTrailerInfo: missing entry \"Root\"")
 		}
 		xRefTable.Root = rootObjRef
-		log.Read.Printf("parseTrailerInfo: Root object: %s\n", *xRefTable.Root)
+		xRefTable.Log.Read().Printf("parseTrailerInfo: Root object: %s\n", *xRefTable.Root)
 	}
 
 	if xRefTable.Info == nil {
 		infoObjRef := d.IndirectRefEntry("Info")
 		if infoObjRef != nil {
 			xRefTable.Info = infoObjRef
-			log.Read.Printf("parseTrailerInfo: Info object: %s\n", *xRefTable.Info)
+			xRefTable.Log.Read().Printf("parseTrailerInfo: Info object: %s\n", *xRefTable.Info)
 		}
 	}
 
@@ -693,20 +731,20 @@ func parseTrailerInfo(d Dict, xRefTable *XRefTable) error {
 		idArray := d.ArrayEntry("ID")
 		if idArray != nil {
 			xRefTable.ID = idArray
-			log.Read.Printf("parseTrailerInfo: ID object: %s\n", xRefTable.ID)
+			xRefTable.Log.Read().Printf("parseTrailerInfo: ID object: %s\n", xRefTable.ID)
 		} else if xRefTable.Encrypt != nil {
 			return errors.New("pdfcpu: parseTrailerInfo: missing entry \"ID\"")
 		}
 	}
 
-	log.Read.Println("parseTrailerInfo end")
+	xRefTable.Log.Read().Println("parseTrailerInfo end")
 
 	return nil
 }
 
 func parseTrailerDict(trailerDict Dict, ctx *Context) (*int64, error) {
 
-	log.Read.Println("parseTrailerDict begin")
+	ctx.Log.Read().Println("parseTrailerDict begin")
 
 	xRefTable := ctx.XRefTable
 
@@ -716,7 +754,7 @@ func parseTrailerDict(trailerDict Dict, ctx *Context) (*int64, error) {
 	}
 
 	if arr := trailerDict.ArrayEntry("AdditionalStreams"); arr != nil {
-		log.Read.Printf("parseTrailerInfo: found AdditionalStreams: %s\n", arr)
+		ctx.Log.Read().Printf("parseTrailerInfo: found AdditionalStreams: %s\n", arr)
 		a := Array{}
 		for _, value := range arr {
 			if indRef, ok := value.(IndirectRef); ok {
@@ -728,10 +766,10 @@ func parseTrailerDict(trailerDict Dict, ctx *Context) (*int64, error) {
 
 	offset := trailerDict.Prev()
 	if offset != nil {
-		log.Read.Printf("parseTrailerDict: previous xref table section offset:%d\n", *offset)
+		ctx.Log.Read().Printf("parseTrailerDict: previous xref table section offset:%d\n", *offset)
 		if *offset == 0 {
 			// Ignoring illegal offset.
-			log.Read.Println("parseTrailerDict: ignoring previous xref table section")
+			ctx.Log.Read().Println("parseTrailerDict: ignoring previous xref table section")
 			offset = nil
 		}
 	}
@@ -742,7 +780,7 @@ func parseTrailerDict(trailerDict Dict, ctx *Context) (*int64, error) {
 		if !ctx.Reader15 && xRefTable.Version() >= V14 && !ctx.Read.Hybrid {
 			return nil, errors.Errorf("parseTrailerDict: PDF1.4 conformant reader: found incompatible version: %s", xRefTable.VersionString())
 		}
-		log.Read.Println("parseTrailerDict end")
+		ctx.Log.Read().Println("parseTrailerDict end")
 		// continue to parse previous xref section, if there is any.
 		return offset, nil
 	}
@@ -764,7 +802,7 @@ func parseTrailerDict(trailerDict Dict, ctx *Context) (*int64, error) {
 		}
 	}
 
-	log.Read.Println("parseTrailerDict end")
+	ctx.Log.Read().Println("parseTrailerDict end")
 
 	return offset, nil
 }
@@ -802,7 +840,7 @@ func isDict(s string) (bool, error) {
 	return ok, nil
 }
 
-func scanTrailerDictStart(s *bufio.Scanner, line *string) error {
+func scanTrailerDictStart(ctx *Context, s *bufio.Scanner, line *string) error {
 	l := *line
 	var err error
 	for {
@@ -812,20 +850,20 @@ func scanTrailerDictStart(s *bufio.Scanner, line *string) error {
 			return nil
 		}
 		l, err = scanLine(s)
-		log.Read.Printf("line: <%s>\n", l)
+		ctx.Log.Read().Printf("line: <%s>\n", l)
 		if err != nil {
 			return err
 		}
 	}
 }
 
-func scanTrailerDictRemainder(s *bufio.Scanner, line string, buf bytes.Buffer) (string, error) {
+func scanTrailerDictRemainder(ctx *Context, s *bufio.Scanner, line string, buf bytes.Buffer) (string, error) {
 	var err error
 	var i, j, k int
 
 	buf.WriteString(line)
 	buf.WriteString("\x0a")
-	log.Read.Printf("scanTrailer dictBuf after start tag: <%s>\n", line)
+	ctx.Log.Read().Printf("scanTrailer dictBuf after start tag: <%s>\n", line)
 
 	line = line[2:]
 
@@ -838,7 +876,7 @@ func scanTrailerDictRemainder(s *bufio.Scanner, line string, buf bytes.Buffer) (
 			}
 			buf.WriteString(line)
 			buf.WriteString("\x0a")
-			log.Read.Printf("scanTrailer dictBuf next line: <%s>\n", line)
+			ctx.Log.Read().Printf("scanTrailer dictBuf next line: <%s>\n", line)
 		}
 
 		i = strings.Index(line, "<<")
@@ -866,7 +904,7 @@ func scanTrailerDictRemainder(s *bufio.Scanner, line string, buf bytes.Buffer) (
 			}
 			buf.WriteString(line)
 			buf.WriteString("\x0a")
-			log.Read.Printf("scanTrailer dictBuf next line: <%s>\n", line)
+			ctx.Log.Read().Printf("scanTrailer dictBuf next line: <%s>\n", line)
 		} else {
 			// Yes <<
 			j = strings.Index(line, ">>")
@@ -898,17 +936,17 @@ func scanTrailerDictRemainder(s *bufio.Scanner, line string, buf bytes.Buffer) (
 	}
 }
 
-func scanTrailer(s *bufio.Scanner, line string) (string, error) {
+func scanTrailer(ctx *Context, s *bufio.Scanner, line string) (string, error) {
 	var buf bytes.Buffer
-	log.Read.Printf("line: <%s>\n", line)
+	ctx.Log.Read().Printf("line: <%s>\n", line)
 
 	// Scan for dict start tag "<<".
-	if err := scanTrailerDictStart(s, &line); err != nil {
+	if err := scanTrailerDictStart(ctx, s, &line); err != nil {
 		return "", err
 	}
 
 	// Scan for dict end tag ">>" but account for inner dicts.
-	return scanTrailerDictRemainder(s, line, buf)
+	return scanTrailerDictRemainder(ctx, s, line, buf)
 }
 
 func processTrailer(ctx *Context, s *bufio.Scanner, line string) (*int64, error) {
@@ -916,17 +954,17 @@ func processTrailer(ctx *Context, s *bufio.Scanner, line string) (*int64, error)
 
 	if line != "trailer" {
 		trailerString = line[7:]
-		log.Read.Printf("processTrailer: trailer leftover: <%s>\n", trailerString)
+		ctx.Log.Read().Printf("processTrailer: trailer leftover: <%s>\n", trailerString)
 	} else {
-		log.Read.Printf("line (len %d) <%s>\n", len(line), line)
+		ctx.Log.Read().Printf("line (len %d) <%s>\n", len(line), line)
 	}
 
-	trailerString, err := scanTrailer(s, trailerString)
+	trailerString, err := scanTrailer(ctx, s, trailerString)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Read.Printf("processTrailer: trailerString: (len:%d) <%s>\n", len(trailerString), trailerString)
+	ctx.Log.Read().Printf("processTrailer: trailerString: (len:%d) <%s>\n", len(trailerString), trailerString)
 
 	o, err := parseObject(&trailerString)
 	if err != nil {
@@ -938,21 +976,22 @@ func processTrailer(ctx *Context, s *bufio.Scanner, line string) (*int64, error)
 		return nil, errors.New("pdfcpu: processTrailer: corrupt trailer dict")
 	}
 
-	log.Read.Printf("processTrailer: trailerDict:\n%s\n", trailerDict)
+	ctx.Log.Read().Printf("processTrailer: trailerDict:\n%s\n", trailerDict)
+	ctx.trace(TraceTrailer, -1, 0, "%d entries", trailerDict.Len())
 
 	return parseTrailerDict(trailerDict, ctx)
 }
 
 // Parse xRef section into corresponding number of xRef table entries.
 func parseXRefSection(s *bufio.Scanner, ctx *Context, ssCount *int, repairOff int) (*int64, error) {
-	log.Read.Println("parseXRefSection begin")
+	ctx.Log.Read().Println("parseXRefSection begin")
 
 	line, err := scanLine(s)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Read.Printf("parseXRefSection: <%s>\n", line)
+	ctx.Log.Read().Printf("parseXRefSection: <%s>\n", line)
 
 	fields := strings.Fields(line)
 
@@ -979,13 +1018,13 @@ func parseXRefSection(s *bufio.Scanner, ctx *Context, ssCount *int, repairOff in
 		fields = strings.Fields(line)
 	}
 
-	log.Read.Println("parseXRefSection: All subsections read!")
+	ctx.Log.Read().Println("parseXRefSection: All subsections read!")
 
 	if !strings.HasPrefix(line, "trailer") {
 		return nil, errors.Errorf("xrefsection: missing trailer dict, line = <%s>", line)
 	}
 
-	log.Read.Println("parseXRefSection: parsing trailer dict..")
+	ctx.Log.Read().Println("parseXRefSection: parsing trailer dict..")
 
 	return processTrailer(ctx, s, line)
 }
@@ -997,8 +1036,8 @@ func parseXRefSection(s *bufio.Scanner, ctx *Context, ssCount *int, repairOff in
 // Save PDF Version from header to xRefTable.
 // The header version comes as the first line of the file.
 // eolCount is the number of characters used for eol (1 or 2).
-func headerVersion(rs io.ReadSeeker) (v *Version, eolCount int, err error) {
-	log.Read.Println("headerVersion begin")
+func headerVersion(ctx *Context, rs io.ReadSeeker) (v *Version, eolCount int, err error) {
+	ctx.Log.Read().Println("headerVersion begin")
 
 	var errCorruptHeader = errors.New("pdfcpu: headerVersion: corrupt pdf stream - no header version available")
 
@@ -1050,108 +1089,11 @@ func headerVersion(rs io.ReadSeeker) (v *Version, eolCount int, err error) {
 		}
 	}
 
-	log.Read.Printf("headerVersion: end, found header version: %s\n", pdfVersion)
+	ctx.Log.Read().Printf("headerVersion: end, found header version: %s\n", pdfVersion)
 
 	return &pdfVersion, eolCount, nil
 }
 
-// bypassXrefSection is a hack for digesting corrupt xref sections.
-// It populates the xRefTable by reading in all indirect objects line by line
-// and works on the assumption of a single xref section - meaning no incremental updates have been made.
-func bypassXrefSection(ctx *Context) error {
-	var z int64
-	g := FreeHeadGeneration
-	ctx.Table[0] = &XRefTableEntry{
-		Free:       true,
-		Offset:     &z,
-		Generation: &g}
-
-	rs := ctx.Read.rs
-	eolCount := ctx.Read.EolCount
-	var off, offset int64
-
-	rd, err := newPositionedReader(rs, &offset)
-	if err != nil {
-		return err
-	}
-
-	s := bufio.NewScanner(rd)
-	s.Split(scanLines)
-
-	bb := []byte{}
-	var (
-		withinObj     bool
-		withinXref    bool
-		withinTrailer bool
-	)
-
-	for {
-		line, err := scanLineRaw(s)
-		//println(line)
-		if err != nil {
-			break
-		}
-		if withinXref {
-			offset += int64(len(line) + eolCount)
-			if withinTrailer {
-				bb = append(bb, '\n')
-				bb = append(bb, line...)
-				i := strings.Index(line, "startxref")
-				if i >= 0 {
-					// Parse trailer.
-					_, err = processTrailer(ctx, s, string(bb))
-					return err
-				}
-				continue
-			}
-			// Ignore all until "trailer".
-			i := strings.Index(line, "trailer")
-			if i >= 0 {
-				bb = append(bb, line...)
-				withinTrailer = true
-			}
-			continue
-		}
-		i := strings.Index(line, "xref")
-		if i >= 0 {
-			offset += int64(len(line) + eolCount)
-			withinXref = true
-			continue
-		}
-		if !withinObj {
-			i := strings.Index(line, "obj")
-			if i >= 0 {
-				withinObj = true
-				off = offset
-				bb = append(bb, line[:i+3]...)
-			}
-			offset += int64(len(line) + eolCount)
-			continue
-		}
-
-		// within obj
-		offset += int64(len(line) + eolCount)
-		bb = append(bb, ' ')
-		bb = append(bb, line...)
-		i = strings.Index(line, "endobj")
-		if i >= 0 {
-			l := string(bb)
-			objNr, generation, err := parseObjectAttributes(&l)
-			if err != nil {
-				return err
-			}
-			of := off
-			ctx.Table[*objNr] = &XRefTableEntry{
-				Free:       false,
-				Offset:     &of,
-				Generation: generation}
-			bb = nil
-			withinObj = false
-		}
-	}
-	return nil
-}
-
 func postProcess(ctx *Context, xrefSectionCount int) {
 	// Ensure free object #0 if exactly one xref subsection
 	// and in one of the following weird situations:
@@ -1173,7 +1115,7 @@ func postProcess(ctx *Context, xrefSectionCount int) {
 }
 
 func tryXRefSection(ctx *Context, rs io.ReadSeeker, offset *int64, xrefSectionCount *int) (*int64, error) {
-	rd, err := newPositionedReader(rs, offset)
+	rd, err := newPositionedReader(ctx, rs, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -1185,11 +1127,12 @@ func tryXRefSection(ctx *Context, rs io.ReadSeeker, offset *int64, xrefSectionCo
 	if err != nil {
 		return nil, err
 	}
-	log.Read.Printf("xref line 1: <%s>\n", line)
+	ctx.Log.Read().Printf("xref line 1: <%s>\n", line)
 	repairOff := len(line)
 
 	if strings.TrimSpace(line) == "xref" {
-		log.Read.Println("buildXRefTableStartingAt: found xref section")
+		ctx.Log.Read().Println("buildXRefTableStartingAt: found xref section")
+		ctx.trace(TraceXRefSection, *offset, 0, "")
 		return parseXRefSection(s, ctx, xrefSectionCount, 0)
 	}
 
@@ -1198,13 +1141,14 @@ func tryXRefSection(ctx *Context, rs io.ReadSeeker, offset *int64, xrefSectionCo
 	if err != nil {
 		return nil, err
 	}
-	log.Read.Printf("xref line 2: <%s>\n", line)
+	ctx.Log.Read().Printf("xref line 2: <%s>\n", line)
 
 	i := strings.Index(line, "xref")
 	if i >= 0 {
-		log.Read.Println("buildXRefTableStartingAt: found xref section")
+		ctx.Log.Read().Println("buildXRefTableStartingAt: found xref section")
 		repairOff += i
-		log.Read.Printf("Repair offset: %d\n", repairOff)
+		ctx.Log.Read().Printf("Repair offset: %d\n", repairOff)
+		ctx.trace(TraceXRefRepair, *offset, 0, "xref found in second line, repair offset %d", repairOff)
 		return parseXRefSection(s, ctx, xrefSectionCount, repairOff)
 	}
 
@@ -1214,11 +1158,11 @@ func tryXRefSection(ctx *Context, rs io.ReadSeeker, offset *int64, xrefSectionCo
 // Build XRefTable by reading XRef streams or XRef sections.
 func buildXRefTableStartingAt(ctx *Context, offset *int64) error {
 
-	log.Read.Println("buildXRefTableStartingAt: begin")
+	ctx.Log.Read().Println("buildXRefTableStartingAt: begin")
 
 	rs := ctx.Read.rs
 
-	hv, eolCount, err := headerVersion(rs)
+	hv, eolCount, err := headerVersion(ctx, rs)
 	if err != nil {
 		return err
 	}
@@ -1230,6 +1174,10 @@ func buildXRefTableStartingAt(ctx *Context, offset *int64) error {
 
 	for offset != nil {
 
+		if err := ctx.Canceled(); err != nil {
+			return err
+		}
+
 		if offs[*offset] {
 			offset, err = offsetLastXRefSection(ctx, ctx.Read.FileSize-*offset)
 			if err != nil {
@@ -1252,27 +1200,43 @@ func buildXRefTableStartingAt(ctx *Context, offset *int64) error {
 			continue
 		}
 
-		log.Read.Println("buildXRefTableStartingAt: found xref stream")
+		ctx.Log.Read().Println("buildXRefTableStartingAt: found xref stream")
 		ctx.Read.UsingXRefStreams = true
-		rd, err := newPositionedReader(rs, offset)
+		rd, err := newPositionedReader(ctx, rs, offset)
 		if err != nil {
 			return err
 		}
+		off = offset
 		if offset, err = parseXRefStream(rd, offset, ctx); err != nil {
-			log.Read.Printf("bypassXRefSection after %v\n", err)
-			// Try fix for corrupt single xref section.
-			return bypassXrefSection(ctx)
+			ctx.Log.Read().Printf("buildXRefTableStartingAt: xref stream at %d: %v\n", *off, err)
+			return err
 		}
 
 	}
 
 	postProcess(ctx, xrefSectionCount)
 
-	log.Read.Println("buildXRefTableStartingAt: end")
+	ctx.Log.Read().Println("buildXRefTableStartingAt: end")
 
 	return nil
 }
 
+// parseXRefTable builds the xref table from the xref sections and streams of this PDF file.
+func parseXRefTable(ctx *Context) error {
+	offset, err := offsetLastXRefSection(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	ctx.Write.OffsetPrevXRef = offset
+
+	err = buildXRefTableStartingAt(ctx, offset)
+	if err == io.EOF {
+		return errors.Wrap(err, "readXRefTable: unexpected eof")
+	}
+	return err
+}
+
 // Populate the cross reference table for this PDF file.
 // Goto offset of first xref table entry.
 // Can be "xref" or indirect object reference eg. "34 0 obj"
@@ -1280,32 +1244,38 @@ func buildXRefTableStartingAt(ctx *Context, offset *int64) error {
 // and build up the xref table along the way.
 func readXRefTable(ctx *Context) (err error) {
 
-	log.Read.Println("readXRefTable: begin")
+	ctx.Log.Read().Println("readXRefTable: begin")
 
-	offset, err := offsetLastXRefSection(ctx, 0)
-	if err != nil {
-		return
+	if !ctx.Repair {
+		err = parseXRefTable(ctx)
+		if err == nil && ctx.Root == nil {
+			err = errors.New("pdfcpu: readXRefTable: missing root object")
+		}
 	}
 
-	ctx.Write.OffsetPrevXRef = offset
-
-	err = buildXRefTableStartingAt(ctx, offset)
-	if err == io.EOF {
-		return errors.Wrap(err, "readXRefTable: unexpected eof")
+	if cerr := ctx.Canceled(); cerr != nil {
+		return cerr
 	}
-	if err != nil {
-		return
+
+	if ctx.Repair || err != nil {
+		if err != nil {
+			ctx.Log.Read().Printf("readXRefTable: %v\n", err)
+			ctx.Warn("corrupt xref table (%v), rebuilt by scanning the file", err)
+		}
+		if err = rebuildXRefTable(ctx); err != nil {
+			return err
+		}
 	}
 
 	//Log list of free objects (not the "free list").
-	//log.Read.Printf("freelist: %v\n", ctx.freeObjects())
+	//ctx.Log.Read().Printf("freelist: %v\n", ctx.freeObjects())
 
 	// Ensure valid freelist of objects.
 	// Note: Acrobat 6.0 and later do not use the free list to recycle object numbers.
 	// Not really necessary but call and fail silently so we at least get a chance to repair corrupt free lists.
 	ctx.EnsureValidFreeList()
 
-	log.Read.Println("readXRefTable: end")
+	ctx.Log.Read().Println("readXRefTable: end")
 
 	return
 }
@@ -1380,13 +1350,13 @@ func lastStreamMarker(streamInd *int, endInd int, line string) {
 }
 
 // Provide a PDF file buffer of sufficient size for parsing an object w/o stream.
-func buffer(rd io.Reader) (buf []byte, endInd int, streamInd int, streamOffset int64, err error) {
+func buffer(ctx *Context, rd io.Reader) (buf []byte, endInd int, streamInd int, streamOffset int64, err error) {
 
 	// process: # gen obj ... obj dict ... {stream ... data ... endstream} ... endobj
 	//                                    streamInd                            endInd
 	//                                  -1 if absent                        -1 if absent
 
-	//log.Read.Println("buffer: begin")
+	//ctx.Log.Read().Println("buffer: begin")
 
 	endInd, streamInd = -1, -1
 
@@ -1412,7 +1382,7 @@ func buffer(rd io.Reader) (buf []byte, endInd int, streamInd int, streamOffset i
 			lastStreamMarker(&streamInd, endInd, line)
 		}
 
-		log.Read.Printf("buffer: endInd=%d streamInd=%d\n", endInd, streamInd)
+		ctx.Log.Read().Printf("buffer: endInd=%d streamInd=%d\n", endInd, streamInd)
 
 		if streamInd > 0 {
 
@@ -1437,7 +1407,7 @@ func buffer(rd io.Reader) (buf []byte, endInd int, streamInd int, streamOffset i
 		}
 	}
 
-	//log.Read.Printf("buffer: end, returned bufsize=%d streamOffset=%d\n", len(buf), streamOffset)
+	//ctx.Log.Read().Printf("buffer: end, returned bufsize=%d streamOffset=%d\n", len(buf), streamOffset)
 
 	return buf, endInd, streamInd, streamOffset, nil
 }
@@ -1465,6 +1435,18 @@ func keywordStreamRightAfterEndOfDict(buf string, streamInd int) bool {
 	return ok
 }
 
+// The filters defined in 7.4
+var knownFilters = NewStringSet([]string{
+	filter.ASCII85, filter.ASCIIHex, filter.RunLength, filter.LZW, filter.Flate,
+	filter.CCITTFax, filter.JBIG2, filter.DCT, filter.JPX, "Crypt",
+})
+
+func checkFilterName(ctx *Context, filterName string) {
+	if !knownFilters[filterName] {
+		ctx.Warn("unknown filter: %s", filterName)
+	}
+}
+
 func buildFilterPipeline(ctx *Context, filterArray, decodeParmsArr Array) ([]PDFFilter, error) {
 
 	var filterPipeline []PDFFilter
@@ -1475,6 +1457,7 @@ func buildFilterPipeline(ctx *Context, filterArray, decodeParmsArr Array) ([]PDF
 		if !ok {
 			return nil, errors.New("pdfcpu: buildFilterPipeline: filterArray elements corrupt")
 		}
+		checkFilterName(ctx, filterName.Value())
 		if decodeParmsArr == nil || decodeParmsArr[i] == nil {
 			filterPipeline = append(filterPipeline, PDFFilter{Name: filterName.Value(), DecodeParms: nil})
 			continue
@@ -1502,7 +1485,7 @@ func buildFilterPipeline(ctx *Context, filterArray, decodeParmsArr Array) ([]PDF
 // Return the filter pipeline associated with this stream dict.
 func pdfFilterPipeline(ctx *Context, dict Dict) ([]PDFFilter, error) {
 
-	log.Read.Println("pdfFilterPipeline: begin")
+	ctx.Log.Read().Println("pdfFilterPipeline: begin")
 
 	var err error
 
@@ -1530,11 +1513,16 @@ func pdfFilterPipeline(ctx *Context, dict Dict) ([]PDFFilter, error) {
 		// single filter.
 
 		filterName := name.String()
+		checkFilterName(ctx, filterName)
 
 		o, found := dict.Find("DecodeParms")
-		if !found {
+		if a, ok := o.(Array); ok && len(a) == 1 {
+			// Tolerate a single decode parameter dict wrapped in an array.
+			o = a[0]
+		}
+		if !found || o == nil {
 			// w/o decode parameters.
-			log.Read.Println("pdfFilterPipeline: end w/o decode parms")
+			ctx.Log.Read().Println("pdfFilterPipeline: end w/o decode parms")
 			return append(filterPipeline, PDFFilter{Name: filterName, DecodeParms: nil}), nil
 		}
 
@@ -1551,7 +1539,7 @@ func pdfFilterPipeline(ctx *Context, dict Dict) ([]PDFFilter, error) {
 		}
 
 		// with decode parameters.
-		log.Read.Println("pdfFilterPipeline: end with decode parms")
+		ctx.Log.Read().Println("pdfFilterPipeline: end with decode parms")
 		return append(filterPipeline, PDFFilter{Name: filterName, DecodeParms: d}), nil
 	}
 
@@ -1566,7 +1554,16 @@ func pdfFilterPipeline(ctx *Context, dict Dict) ([]PDFFilter, error) {
 	// Optional array of decode parameter dicts.
 	var decodeParmsArr Array
 	decodeParms, found := dict.Find("DecodeParms")
-	if found {
+	if found && decodeParms != nil {
+		if indRef, ok := decodeParms.(IndirectRef); ok {
+			if decodeParms, err = dereferencedObject(ctx, indRef.ObjectNumber.Value()); err != nil {
+				return nil, err
+			}
+		}
+		if d, ok := decodeParms.(Dict); ok && len(filterArray) == 1 {
+			// Tolerate a single decode parameter dict for a single filter array.
+			decodeParms = Array{d}
+		}
 		decodeParmsArr, ok = decodeParms.(Array)
 		if !ok || len(decodeParmsArr) != len(filterArray) {
 			return nil, errors.New("pdfcpu: pdfFilterPipeline: expected decodeParms array corrupt")
@@ -1577,7 +1574,7 @@ func pdfFilterPipeline(ctx *Context, dict Dict) ([]PDFFilter, error) {
 
 	filterPipeline, err = buildFilterPipeline(ctx, filterArray, decodeParmsArr)
 
-	log.Read.Println("pdfFilterPipeline: end")
+	ctx.Log.Read().Println("pdfFilterPipeline: end")
 
 	return filterPipeline, err
 }
@@ -1600,14 +1597,15 @@ func streamDictForObject(ctx *Context, d Dict, objNr, streamInd int, streamOffse
 	// We have a stream object.
 	sd = NewStreamDict(d, streamOffset, streamLength, streamLengthRef, filterPipeline)
 
-	log.Read.Printf("streamDictForObject: end, Streamobject #%d\n", objNr)
+	ctx.Log.Read().Printf("streamDictForObject: end, Streamobject #%d\n", objNr)
 
 	return sd, nil
 }
 
 func dict(ctx *Context, d1 Dict, objNr, genNr, endInd, streamInd int) (d2 Dict, err error) {
 
-	if ctx.EncKey != nil {
+	// Strings of cross-reference streams are not encrypted.
+	if stringsEncrypted(ctx, objNr) && !d1.IsXRefStm() {
 		_, err := decryptDeepObject(d1, objNr, genNr, ctx.EncKey, ctx.AES4Strings, ctx.E.R)
 		if err != nil {
 			return nil, err
@@ -1615,7 +1613,7 @@ func dict(ctx *Context, d1 Dict, objNr, genNr, endInd, streamInd int) (d2 Dict,
 	}
 
 	if endInd >= 0 && (streamInd < 0 || streamInd > endInd) {
-		log.Read.Printf("dict: end, #%d\n", objNr)
+		ctx.Log.Read().Printf("dict: end, #%d\n", objNr)
 		d2 = d1
 	}
 
@@ -1625,52 +1623,56 @@ func dict(ctx *Context, d1 Dict, objNr, genNr, endInd, streamInd int) (d2 Dict,
 func object(ctx *Context, offset int64, objNr, genNr int) (o Object, endInd, streamInd int, streamOffset int64, err error) {
 
 	var rd io.Reader
-	rd, err = newPositionedReader(ctx.Read.rs, &offset)
+	rd, err = positionedReader(ctx, offset)
 	if err != nil {
 		return nil, 0, 0, 0, err
 	}
 
-	//log.Read.Printf("object: seeked to offset:%d\n", offset)
+	//ctx.Log.Read().Printf("object: seeked to offset:%d\n", offset)
 
 	// process: # gen obj ... obj dict ... {stream ... data ... endstream} endobj
 	//                                    streamInd                        endInd
 	//                                  -1 if absent                    -1 if absent
 	var buf []byte
-	buf, endInd, streamInd, streamOffset, err = buffer(rd)
+	buf, endInd, streamInd, streamOffset, err = buffer(ctx, rd)
 	if err != nil {
 		return nil, 0, 0, 0, err
 	}
 
-	//log.Read.Printf("streamInd:%d(#%x) streamOffset:%d(#%x) endInd:%d(#%x)\n", streamInd, streamInd, streamOffset, streamOffset, endInd, endInd)
-	//log.Read.Printf("buflen=%d\n%s", len(buf), hex.Dump(buf))
+	//ctx.Log.Read().Printf("streamInd:%d(#%x) streamOffset:%d(#%x) endInd:%d(#%x)\n", streamInd, streamInd, streamOffset, streamOffset, endInd, endInd)
+	//ctx.Log.Read().Printf("buflen=%d\n%s", len(buf), hex.Dump(buf))
 
 	line := string(buf)
 
+	if ctx.XRefTable.ValidationMode == ValidationPedantic && streamInd > 0 && (endInd < 0 || streamInd < endInd) {
+		checkStreamKeywordEOL(ctx, objNr, line, streamInd)
+	}
+
 	var l string
 
 	if endInd < 0 { // && streamInd >= 0, streamdict
 		// buf: # gen obj ... obj dict ... stream ... data
 		// implies we detected no endobj and a stream starting at streamInd.
 		// big stream, we parse object until "stream"
-		log.Read.Println("object: big stream, we parse object until stream")
+		ctx.Log.Read().Println("object: big stream, we parse object until stream")
 		l = line[:streamInd]
 	} else if streamInd < 0 { // dict
 		// buf: # gen obj ... obj dict ... endobj
 		// implies we detected endobj and no stream.
 		// small object w/o stream, parse until "endobj"
-		log.Read.Println("object: small object w/o stream, parse until endobj")
+		ctx.Log.Read().Println("object: small object w/o stream, parse until endobj")
 		l = line[:endInd]
 	} else if streamInd < endInd { // streamdict
 		// buf: # gen obj ... obj dict ... stream ... data ... endstream endobj
 		// implies we detected endobj and stream.
 		// small stream within buffer, parse until "stream"
-		log.Read.Println("object: small stream within buffer, parse until stream")
+		ctx.Log.Read().Println("object: small stream within buffer, parse until stream")
 		l = line[:streamInd]
 	} else { // dict
 		// buf: # gen obj ... obj dict ... endobj # gen obj ... obj dict ... stream
 		// small obj w/o stream, parse until "endobj"
 		// stream in buf belongs to subsequent object.
-		log.Read.Println("object: small obj w/o stream, parse until endobj")
+		ctx.Log.Read().Println("object: small obj w/o stream, parse until endobj")
 		l = line[:endInd]
 	}
 
@@ -1684,7 +1686,8 @@ func object(ctx *Context, offset int64, objNr, genNr int) (o Object, endInd, str
 	if objNr != *objectNr || genNr != *generationNr {
 		// This is suspicious, but ok if two object numbers point to same offset and only one of them is used
 		// (compare entry.RefCount) like for cases where the PDF Writer is MS Word 2013.
-		log.Read.Printf("object %d: non matching objNr(%d) or generationNumber(%d) tags found.\n", objNr, *objectNr, *generationNr)
+		ctx.Log.Read().Printf("object %d: non matching objNr(%d) or generationNumber(%d) tags found.\n", objNr, *objectNr, *generationNr)
+		ctx.trace(TraceObjNrMismatch, offset, objNr, "found %d %d obj", *objectNr, *generationNr)
 	}
 
 	l = strings.TrimSpace(l)
@@ -1695,21 +1698,53 @@ func object(ctx *Context, offset int64, objNr, genNr int) (o Object, endInd, str
 		return nil, endInd, streamInd, streamOffset, err
 	}
 
-	o, err = parseObject(&l)
+	var dupKeys []string
+	o, err = parseObjectWithDupKeys(&l, &dupKeys)
+	if err == nil {
+		warnDuplicateKeys(ctx, objNr, dupKeys)
+	}
 
 	return o, endInd, streamInd, streamOffset, err
 }
 
+// checkStreamKeywordEOL records a missing end-of-line marker after the keyword stream, see 7.3.8.1.
+func checkStreamKeywordEOL(ctx *Context, objNr int, line string, streamInd int) {
+	s := line[streamInd+len("stream"):]
+	if !strings.HasPrefix(s, "\n") && !strings.HasPrefix(s, "\r\n") {
+		ctx.Warn("obj#%d: keyword stream not followed by CRLF or LF", objNr)
+	}
+}
+
+func warnDuplicateKeys(ctx *Context, objNr int, dupKeys []string) {
+	m := StringSet{}
+	for _, k := range dupKeys {
+		if !m[k] {
+			m[k] = true
+			ctx.Warn("obj#%d: duplicate dict key %s, using first value", objNr, k)
+		}
+	}
+}
+
 // ParseObject parses an object from file at given offset.
 func ParseObject(ctx *Context, offset int64, objNr, genNr int) (Object, error) {
 
-	log.Read.Printf("ParseObject: begin, obj#%d, offset:%d\n", objNr, offset)
+	ctx.Log.Read().Printf("ParseObject: begin, obj#%d, offset:%d\n", objNr, offset)
+
+	if ctx.Read != nil {
+		ctx.Read.lock()
+		ctx.Read.ObjectsParsed++
+		ctx.Read.unlock()
+	}
 
 	obj, endInd, streamInd, streamOffset, err := object(ctx, offset, objNr, genNr)
 	if err != nil {
 		return nil, err
 	}
 
+	if ctx.tracing() {
+		ctx.trace(TraceObject, offset, objNr, "%T", obj)
+	}
+
 	switch o := obj.(type) {
 
 	case Dict:
@@ -1722,7 +1757,7 @@ func ParseObject(ctx *Context, offset int64, objNr, genNr int) (Object, error) {
 		return streamDictForObject(ctx, o, objNr, streamInd, streamOffset, offset)
 
 	case Array:
-		if ctx.EncKey != nil {
+		if stringsEncrypted(ctx, objNr) {
 			if _, err = decryptDeepObject(o, objNr, genNr, ctx.EncKey, ctx.AES4Strings, ctx.E.R); err != nil {
 				return nil, err
 			}
@@ -1730,7 +1765,7 @@ func ParseObject(ctx *Context, offset int64, objNr, genNr int) (Object, error) {
 		return o, nil
 
 	case StringLiteral:
-		if ctx.EncKey != nil {
+		if stringsEncrypted(ctx, objNr) {
 			bb, err := decryptString(o.Value(), objNr, genNr, ctx.EncKey, ctx.AES4Strings, ctx.E.R)
 			if err != nil {
 				return nil, err
@@ -1740,7 +1775,7 @@ func ParseObject(ctx *Context, offset int64, objNr, genNr int) (Object, error) {
 		return o, nil
 
 	case HexLiteral:
-		if ctx.EncKey != nil {
+		if stringsEncrypted(ctx, objNr) {
 			bb, err := decryptHexLiteral(o, objNr, genNr, ctx.EncKey, ctx.AES4Strings, ctx.E.R)
 			if err != nil {
 				return nil, err
@@ -1761,26 +1796,36 @@ func dereferencedObject(ctx *Context, objectNumber int) (Object, error) {
 		return nil, errors.New("pdfcpu: dereferencedObject: unregistered object")
 	}
 
+	// Objects referenced by stream dicts may get loaded by concurrent readers.
+	ctx.Read.lock()
 	if entry.Compressed {
-		err := decompressXRefTableEntry(ctx.XRefTable, objectNumber, entry)
-		if err != nil {
+		if err := decompressXRefTableEntry(ctx, objectNumber, entry); err != nil {
+			ctx.Read.unlock()
 			return nil, err
 		}
 	}
+	o := entry.Object
+	ctx.Read.unlock()
 
-	if entry.Object == nil {
+	if o != nil {
+		return o, nil
+	}
 
-		log.Read.Printf("dereferencedObject: dereferencing object %d\n", objectNumber)
+	ctx.Log.Read().Printf("dereferencedObject: dereferencing object %d\n", objectNumber)
 
-		o, err := ParseObject(ctx, *entry.Offset, objectNumber, *entry.Generation)
-		if err != nil {
-			return nil, errors.Wrapf(err, "dereferencedObject: problem dereferencing object %d", objectNumber)
-		}
+	o, err := ParseObject(ctx, *entry.Offset, objectNumber, *entry.Generation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "dereferencedObject: problem dereferencing object %d", objectNumber)
+	}
 
-		if o == nil {
-			return nil, errors.New("pdfcpu: dereferencedObject: object is nil")
-		}
+	if o == nil {
+		return nil, errors.New("pdfcpu: dereferencedObject: object is nil")
+	}
 
+	ctx.Read.lock()
+	defer ctx.Read.unlock()
+
+	if entry.Object == nil {
 		entry.Object = o
 	}
 
@@ -1820,7 +1865,7 @@ func dereferencedDict(ctx *Context, objectNumber int) (Dict, error) {
 // dereference a Integer object representing an int64 value.
 func int64Object(ctx *Context, objectNumber int) (*int64, error) {
 
-	log.Read.Printf("int64Object begin: %d\n", objectNumber)
+	ctx.Log.Read().Printf("int64Object begin: %d\n", objectNumber)
 
 	i, err := dereferencedInteger(ctx, objectNumber)
 	if err != nil {
@@ -1829,7 +1874,7 @@ func int64Object(ctx *Context, objectNumber int) (*int64, error) {
 
 	i64 := int64(i.Value())
 
-	log.Read.Printf("int64Object end: %d\n", objectNumber)
+	ctx.Log.Read().Printf("int64Object end: %d\n", objectNumber)
 
 	return &i64, nil
 
@@ -1859,9 +1904,9 @@ func readStreamContentBlindly(rd io.Reader) (buf []byte, err error) {
 }
 
 // Reads and returns a file buffer with length = stream length using provided reader positioned at offset.
-func readStreamContent(rd io.Reader, streamLength int) ([]byte, error) {
+func readStreamContent(ctx *Context, rd io.Reader, streamLength int) ([]byte, error) {
 
-	log.Read.Printf("readStreamContent: begin streamLength:%d\n", streamLength)
+	ctx.Log.Read().Printf("readStreamContent: begin streamLength:%d\n", streamLength)
 
 	// If streamLength == 0 read until "endstream" then fix "Length"
 	if streamLength == 0 {
@@ -1885,12 +1930,12 @@ func readStreamContent(rd io.Reader, streamLength int) ([]byte, error) {
 			return buf[:eob], nil
 		}
 
-		log.Read.Printf("readStreamContent: count=%d, buflen=%d(%X)\n", count, len(buf), len(buf))
+		ctx.Log.Read().Printf("readStreamContent: count=%d, buflen=%d(%X)\n", count, len(buf), len(buf))
 		totalCount += count
 
 	}
 
-	log.Read.Printf("readStreamContent: end\n")
+	ctx.Log.Read().Printf("readStreamContent: end\n")
 
 	return buf, nil
 }
@@ -1898,13 +1943,13 @@ func readStreamContent(rd io.Reader, streamLength int) ([]byte, error) {
 // LoadEncodedStreamContent loads the encoded stream content from file into StreamDict.
 func loadEncodedStreamContent(ctx *Context, sd *StreamDict) ([]byte, error) {
 
-	log.Read.Printf("LoadEncodedStreamContent: begin\n%v\n", sd)
+	ctx.Log.Read().Printf("LoadEncodedStreamContent: begin\n%v\n", sd)
 
 	var err error
 
 	// Return saved decoded content.
 	if sd.Raw != nil {
-		log.Read.Println("LoadEncodedStreamContent: end, already in memory.")
+		ctx.Log.Read().Println("LoadEncodedStreamContent: end, already in memory.")
 		return sd.Raw, nil
 	}
 
@@ -1920,20 +1965,19 @@ func loadEncodedStreamContent(ctx *Context, sd *StreamDict) ([]byte, error) {
 		if err != nil {
 			return nil, err
 		}
-		log.Read.Printf("LoadEncodedStreamContent: new indirect streamLength:%d\n", *sd.StreamLength)
+		ctx.Log.Read().Printf("LoadEncodedStreamContent: new indirect streamLength:%d\n", *sd.StreamLength)
 	}
 
-	newOffset := sd.StreamOffset
-	rd, err := newPositionedReader(ctx.Read.rs, &newOffset)
+	rd, err := positionedReader(ctx, sd.StreamOffset)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Read.Printf("LoadEncodedStreamContent: seeked to offset:%d\n", newOffset)
+	ctx.Log.Read().Printf("LoadEncodedStreamContent: seeked to offset:%d\n", sd.StreamOffset)
 
 	// Buffer stream contents.
 	// Read content from disk.
-	rawContent, err := readStreamContent(rd, int(*sd.StreamLength))
+	rawContent, err := readStreamContent(ctx, rd, int(*sd.StreamLength))
 	if err != nil {
 		return nil, err
 	}
@@ -1941,16 +1985,18 @@ func loadEncodedStreamContent(ctx *Context, sd *StreamDict) ([]byte, error) {
 	// Sometimes the stream dict length is corrupt and needs to be fixed.
 	l := int64(len(rawContent))
 	if *sd.StreamLength == 0 || l < *sd.StreamLength {
+		ctx.trace(TraceStreamLength, sd.StreamOffset, 0, "Length %d -> %d", *sd.StreamLength, l)
+		ctx.Warn("stream at offset %d: corrected Length %d to %d", sd.StreamOffset, *sd.StreamLength, l)
 		sd.StreamLength = &l
 		sd.Dict["Length"] = Integer(l)
 	}
 
-	//log.Read.Printf("rawContent buflen=%d(#%x)\n%s", len(rawContent), len(rawContent), hex.Dump(rawContent))
+	//ctx.Log.Read().Printf("rawContent buflen=%d(#%x)\n%s", len(rawContent), len(rawContent), hex.Dump(rawContent))
 
 	// Save encoded content.
 	sd.Raw = rawContent
 
-	log.Read.Printf("LoadEncodedStreamContent: end: len(streamDictRaw)=%d\n", len(sd.Raw))
+	ctx.Log.Read().Printf("LoadEncodedStreamContent: end: len(streamDictRaw)=%d\n", len(sd.Raw))
 
 	// Return encoded content.
 	return rawContent, nil
@@ -1959,15 +2005,7 @@ func loadEncodedStreamContent(ctx *Context, sd *StreamDict) ([]byte, error) {
 // Decodes the raw encoded stream content and saves it to streamDict.Content.
 func saveDecodedStreamContent(ctx *Context, sd *StreamDict, objNr, genNr int, decode bool) (err error) {
 
-	log.Read.Printf("saveDecodedStreamContent: begin decode=%t\n", decode)
-
-	// If the "Identity" crypt filter is used we do not need to decrypt.
-	if ctx != nil && ctx.EncKey != nil {
-		if len(sd.FilterPipeline) == 1 && sd.FilterPipeline[0].Name == "Crypt" {
-			sd.Content = sd.Raw
-			return nil
-		}
-	}
+	ctx.Log.Read().Printf("saveDecodedStreamContent: begin decode=%t\n", decode)
 
 	// Special case: If the length of the encoded data is 0, we do not need to decode anything.
 	if len(sd.Raw) == 0 {
@@ -1975,11 +2013,13 @@ func saveDecodedStreamContent(ctx *Context, sd *StreamDict, objNr, genNr int, de
 		return nil
 	}
 
-	// ctx gets created after XRefStream parsing.
-	// XRefStreams are not encrypted.
-	if ctx != nil && ctx.EncKey != nil {
-		sd.Raw, err = decryptStream(sd.Raw, objNr, genNr, ctx.EncKey, ctx.AES4Streams, ctx.E.R)
-		if err != nil {
+	// XRefStreams and streams using the "Identity" crypt filter are not encrypted.
+	crypt, aes, err := streamCrypt(ctx, sd, objNr)
+	if err != nil {
+		return err
+	}
+	if crypt {
+		if sd.Raw, err = decryptStream(sd.Raw, objNr, genNr, ctx.EncKey, aes, ctx.E.R); err != nil {
 			return err
 		}
 		l := int64(len(sd.Raw))
@@ -2003,26 +2043,100 @@ func saveDecodedStreamContent(ctx *Context, sd *StreamDict, objNr, genNr int, de
 		return err
 	}
 
-	log.Read.Println("saveDecodedStreamContent: end")
+	ctx.Log.Read().Println("saveDecodedStreamContent: end")
 
 	return nil
 }
 
-// Resolve compressed xRefTableEntry
-func decompressXRefTableEntry(xRefTable *XRefTable, objectNumber int, entry *XRefTableEntry) error {
+// Release the decoded content of the least recently used object stream
+// whenever the number of cached object streams exceeds the configured limit.
+func trimObjectStreamCache(ctx *Context) {
+
+	limit := ctx.ObjectStreamCacheSize
+
+	for limit > 0 && len(ctx.Read.objStreamCache) > limit {
+
+		objNr := ctx.Read.objStreamCache[0]
+		ctx.Read.objStreamCache = ctx.Read.objStreamCache[1:]
 
-	log.Read.Printf("decompressXRefTableEntry: compressed object %d at %d[%d]\n", objectNumber, *entry.ObjectStream, *entry.ObjectStreamInd)
+		entry, ok := ctx.Find(objNr)
+		if !ok {
+			continue
+		}
+
+		if osd, ok := entry.Object.(ObjectStreamDict); ok {
+			ctx.Log.Read().Printf("trimObjectStreamCache: releasing object stream %d\n", objNr)
+			osd.Content = nil
+			osd.ObjArray = nil
+			entry.Object = osd
+		}
+	}
+}
+
+// Mark the cached object stream objNr as most recently used.
+func touchObjectStreamCache(ctx *Context, objNr int) {
+	c := ctx.Read.objStreamCache
+	for i, nr := range c {
+		if nr == objNr {
+			copy(c[i:], c[i+1:])
+			c[len(c)-1] = objNr
+			return
+		}
+	}
+}
+
+// Return the object stream dict for objNr with all its objects parsed.
+// Object streams get decoded on first access and stay cached until trimmed.
+func decodedObjectStream(ctx *Context, objNr int) (*ObjectStreamDict, error) {
 
 	// Resolve xRefTable entry of referenced object stream.
-	objectStreamXRefTableEntry, ok := xRefTable.Find(*entry.ObjectStream)
+	entry, ok := ctx.Find(objNr)
 	if !ok {
-		return errors.Errorf("decompressXRefTableEntry: problem dereferencing object stream %d, no xref table entry", *entry.ObjectStream)
+		return nil, errors.Errorf("decodedObjectStream: problem dereferencing object stream %d, no xref table entry", objNr)
 	}
 
 	// Object of this entry has to be a ObjectStreamDict.
-	sd, ok := objectStreamXRefTableEntry.Object.(ObjectStreamDict)
+	osd, ok := entry.Object.(ObjectStreamDict)
 	if !ok {
-		return errors.Errorf("decompressXRefTableEntry: problem dereferencing object stream %d, no object stream", *entry.ObjectStream)
+		return nil, errors.Errorf("decodedObjectStream: problem dereferencing object stream %d, no object stream", objNr)
+	}
+
+	if osd.ObjArray != nil {
+		ctx.Read.ObjStmCacheHits++
+		touchObjectStreamCache(ctx, objNr)
+		return &osd, nil
+	}
+
+	ctx.Read.ObjStmCacheMisses++
+
+	ctx.Log.Read().Printf("decodedObjectStream: decoding object stream %d:\n", objNr)
+
+	osd1, err := parsedObjectStream(ctx, osd, objNr)
+	if err != nil {
+		return nil, err
+	}
+	osd = *osd1
+
+	ctx.Log.Read().Printf("decodedObjectStream: decoded object stream %d:\n", objNr)
+	ctx.trace(TraceObjectStream, osd.StreamOffset, objNr, "%d objects", osd.ObjCount)
+
+	// Save object stream dict to xRefTableEntry.
+	entry.Object = osd
+
+	ctx.Read.objStreamCache = append(ctx.Read.objStreamCache, objNr)
+	trimObjectStreamCache(ctx)
+
+	return &osd, nil
+}
+
+// Resolve compressed xRefTableEntry
+func decompressXRefTableEntry(ctx *Context, objectNumber int, entry *XRefTableEntry) error {
+
+	ctx.Log.Read().Printf("decompressXRefTableEntry: compressed object %d at %d[%d]\n", objectNumber, *entry.ObjectStream, *entry.ObjectStreamInd)
+
+	sd, err := decodedObjectStream(ctx, *entry.ObjectStream)
+	if err != nil {
+		return errors.Wrap(err, "decompressXRefTableEntry")
 	}
 
 	// Get indexed object from ObjectStreamDict.
@@ -2037,42 +2151,42 @@ func decompressXRefTableEntry(xRefTable *XRefTable, objectNumber int, entry *XRe
 	entry.Generation = &g
 	entry.Compressed = false
 
-	log.Read.Printf("decompressXRefTableEntry: end, Obj %d[%d]:\n<%s>\n", *entry.ObjectStream, *entry.ObjectStreamInd, o)
+	ctx.Log.Read().Printf("decompressXRefTableEntry: end, Obj %d[%d]:\n<%s>\n", *entry.ObjectStream, *entry.ObjectStreamInd, o)
 
 	return nil
 }
 
 // Log interesting stream content.
-func logStream(o Object) {
+func logStream(ctx *Context, o Object) {
 
 	switch o := o.(type) {
 
 	case StreamDict:
 
 		if o.Content == nil {
-			log.Read.Println("logStream: no stream content")
+			ctx.Log.Read().Println("logStream: no stream content")
 		}
 
 		// if o.IsPageContent {
-		// 	//log.Read.Printf("content <%s>\n", StreamDict.Content)
+		// 	//ctx.Log.Read().Printf("content <%s>\n", StreamDict.Content)
 		// }
 
 	case ObjectStreamDict:
 
 		if o.Content == nil {
-			log.Read.Println("logStream: no object stream content")
+			ctx.Log.Read().Println("logStream: no object stream content")
 		} else {
-			log.Read.Printf("logStream: objectStream content = %s\n", o.Content)
+			ctx.Log.Read().Printf("logStream: objectStream content = %s\n", o.Content)
 		}
 
 		if o.ObjArray == nil {
-			log.Read.Println("logStream: no object stream obj arr")
+			ctx.Log.Read().Println("logStream: no object stream obj arr")
 		} else {
-			log.Read.Printf("logStream: objectStream objArr = %s\n", o.ObjArray)
+			ctx.Log.Read().Printf("logStream: objectStream objArr = %s\n", o.ObjArray)
 		}
 
 	default:
-		log.Read.Println("logStream: no ObjectStreamDict")
+		ctx.Log.Read().Println("logStream: no ObjectStreamDict")
 
 	}
 
@@ -2085,7 +2199,7 @@ func decodeObjectStreams(ctx *Context) error {
 	// Entry "Extends" intentionally left out.
 	// No object stream collection validation necessary.
 
-	log.Read.Println("decodeObjectStreams: begin")
+	ctx.Log.Read().Println("decodeObjectStreams: begin")
 
 	// Get sorted slice of object numbers.
 	var keys []int
@@ -2096,13 +2210,17 @@ func decodeObjectStreams(ctx *Context) error {
 
 	for _, objectNumber := range keys {
 
+		if err := ctx.Canceled(); err != nil {
+			return err
+		}
+
 		// Get XRefTableEntry.
 		entry := ctx.XRefTable.Table[objectNumber]
 		if entry == nil {
 			return errors.Errorf("decodeObjectStream: missing entry for obj#%d\n", objectNumber)
 		}
 
-		log.Read.Printf("decodeObjectStreams: parsing object stream for obj#%d\n", objectNumber)
+		ctx.Log.Read().Printf("decodeObjectStreams: parsing object stream for obj#%d\n", objectNumber)
 
 		// Parse object stream from file.
 		o, err := ParseObject(ctx, *entry.Offset, objectNumber, *entry.Generation)
@@ -2121,19 +2239,19 @@ func decodeObjectStreams(ctx *Context) error {
 			return errors.Wrapf(err, "decodeObjectStreams: problem dereferencing object stream %d", objectNumber)
 		}
 
-		// Save decoded stream content to xRefTable.
-		if err = saveDecodedStreamContent(ctx, &sd, objectNumber, *entry.Generation, true); err != nil {
-			log.Read.Printf("obj %d: %s", objectNumber, err)
+		// Decrypt stream content if necessary.
+		// Decoding is deferred until the first object of this object stream is needed.
+		if err = saveDecodedStreamContent(ctx, &sd, objectNumber, *entry.Generation, false); err != nil {
+			ctx.Log.Read().Printf("obj %d: %s", objectNumber, err)
 			return err
 		}
 
-		// Ensure decoded objectArray for object stream dicts.
 		if !sd.IsObjStm() {
 			return errors.New("pdfcpu: decodeObjectStreams: corrupt object stream")
 		}
 
 		// We have an object stream.
-		log.Read.Printf("decodeObjectStreams: object stream #%d\n", objectNumber)
+		ctx.Log.Read().Printf("decodeObjectStreams: object stream #%d\n", objectNumber)
 
 		ctx.Read.UsingObjectStreams = true
 
@@ -2143,24 +2261,11 @@ func decodeObjectStreams(ctx *Context) error {
 			return errors.Wrapf(err, "decodeObjectStreams: problem dereferencing object stream %d", objectNumber)
 		}
 
-		log.Read.Printf("decodeObjectStreams: decoding object stream %d:\n", objectNumber)
-
-		// Parse all objects of this object stream and save them to ObjectStreamDict.ObjArray.
-		if err = parseObjectStream(osd); err != nil {
-			return errors.Wrapf(err, "decodeObjectStreams: problem decoding object stream %d\n", objectNumber)
-		}
-
-		if osd.ObjArray == nil {
-			return errors.Wrap(err, "decodeObjectStreams: objArray should be set!")
-		}
-
-		log.Read.Printf("decodeObjectStreams: decoded object stream %d:\n", objectNumber)
-
 		// Save object stream dict to xRefTableEntry.
 		entry.Object = *osd
 	}
 
-	log.Read.Println("decodeObjectStreams: end")
+	ctx.Log.Read().Println("decodeObjectStreams: end")
 
 	return nil
 }
@@ -2177,7 +2282,7 @@ func handleLinearizationParmDict(ctx *Context, obj Object, objNr int) error {
 
 		ctx.Read.Linearized = true
 		ctx.LinearizationObjs[objNr] = true
-		log.Read.Printf("handleLinearizationParmDict: identified linearizationObj #%d\n", objNr)
+		ctx.Log.Read().Printf("handleLinearizationParmDict: identified linearizationObj #%d\n", objNr)
 
 		a := d.ArrayEntry("H")
 
@@ -2221,12 +2326,65 @@ func loadStreamDict(ctx *Context, sd *StreamDict, objNr, genNr int) error {
 		return errors.Wrapf(err, "dereferenceObject: problem dereferencing stream %d", objNr)
 	}
 
-	ctx.Read.BinaryTotalSize += *sd.StreamLength
-
 	// Decode stream content.
-	err = saveDecodedStreamContent(ctx, sd, objNr, genNr, ctx.DecodeAllStreams)
+	if err = saveDecodedStreamContent(ctx, sd, objNr, genNr, ctx.DecodeAllStreams); err != nil {
+		return err
+	}
 
-	return err
+	if err = applyFilterPolicy(ctx, sd, objNr); err != nil {
+		return err
+	}
+
+	if sd.IsExternal() && ctx.ResolveExternalStreams {
+		return resolveExternalStream(ctx, sd, objNr)
+	}
+
+	return nil
+}
+
+// unsupportedFilter returns the first filter of sd's pipeline pdfcpu is unable to decode.
+// Invalid filter names are not subject to the filter policy.
+func unsupportedFilter(sd *StreamDict) (string, bool) {
+	for _, f := range sd.FilterPipeline {
+		// Crypt filters are taken care of by decryption.
+		if f.Name == filter.Crypt || filter.Supported(f.Name) {
+			continue
+		}
+		if filter.Standard(f.Name) || filter.NonStandard(f.Name) {
+			return f.Name, true
+		}
+		// Decoding fails for invalid filter names.
+		break
+	}
+	return "", false
+}
+
+// applyFilterPolicy handles streams using filters pdfcpu is unable to decode as configured.
+func applyFilterPolicy(ctx *Context, sd *StreamDict, objNr int) error {
+	if sd.Content != nil {
+		return nil
+	}
+
+	filterName, ok := unsupportedFilter(sd)
+	if !ok {
+		return nil
+	}
+
+	switch ctx.FilterPolicy {
+
+	case FilterPolicyError:
+		return errors.Errorf("pdfcpu: obj#%d: unsupported filter: %s", objNr, filterName)
+
+	case FilterPolicyDecode:
+		err := sd.decode(true)
+		if err != filter.ErrUnsupportedFilter {
+			return err
+		}
+		ctx.Warn("obj#%d: no decoder registered for filter %s, preserving raw stream data", objNr, filterName)
+	}
+
+	// Carry the raw stream data through, sd.Content stays nil.
+	return nil
 }
 
 func updateBinaryTotalSize(ctx *Context, o Object) {
@@ -2246,34 +2404,66 @@ func updateBinaryTotalSize(ctx *Context, o Object) {
 
 }
 
-func dereferenceObject(ctx *Context, objNr int) error {
+// loadObject parses object objNr from file including its stream data.
+func loadObject(ctx *Context, objNr int, entry *XRefTableEntry) (Object, error) {
+
+	// Parse object from file: anything goes dict, array, integer, float, streamdicts...
+	o, err := ParseObject(ctx, *entry.Offset, objNr, *entry.Generation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "dereferenceObject: problem dereferencing object %d", objNr)
+	}
+
+	// Handle stream dicts.
+
+	if _, ok := o.(ObjectStreamDict); ok {
+		return nil, errors.Errorf("dereferenceObject: object stream should already be dereferenced at obj:%d", objNr)
+	}
+
+	if _, ok := o.(XRefStreamDict); ok {
+		return nil, errors.Errorf("dereferenceObject: xref stream should already be dereferenced at obj:%d", objNr)
+	}
+
+	if sd, ok := o.(StreamDict); ok {
+		if err = loadStreamDict(ctx, &sd, objNr, *entry.Generation); err != nil {
+			return nil, err
+		}
+		o = sd
+	}
+
+	return o, nil
+}
+
+func dereferenceObject(ctx *Context, objNr int, prefetched map[int]*prefetchResult) error {
 
 	xRefTable := ctx.XRefTable
 	xRefTableSize := len(xRefTable.Table)
 
-	log.Read.Printf("dereferenceObject: begin, dereferencing object %d\n", objNr)
+	ctx.Log.Read().Printf("dereferenceObject: begin, dereferencing object %d\n", objNr)
 
 	entry := xRefTable.Table[objNr]
 
 	if entry.Free {
-		log.Read.Printf("free object %d\n", objNr)
+		ctx.Log.Read().Printf("free object %d\n", objNr)
 		return nil
 	}
 
 	if entry.Compressed {
-		err := decompressXRefTableEntry(xRefTable, objNr, entry)
+		if r, ok := prefetched[*entry.ObjectStream]; ok && r.err == nil {
+			r.commit(ctx)
+		}
+		err := decompressXRefTableEntry(ctx, objNr, entry)
 		if err != nil {
 			return err
 		}
-		//log.Read.Printf("dereferenceObject: decompressed entry, Compressed=%v\n%s\n", entry.Compressed, entry.Object)
+		//ctx.Log.Read().Printf("dereferenceObject: decompressed entry, Compressed=%v\n%s\n", entry.Compressed, entry.Object)
 		return nil
 	}
 
 	// entry is in use.
-	log.Read.Printf("in use object %d\n", objNr)
+	ctx.Log.Read().Printf("in use object %d\n", objNr)
 
 	if entry.Offset == nil || *entry.Offset == 0 {
-		log.Read.Printf("dereferenceObject: already decompressed or used object w/o offset -> ignored")
+		ctx.Log.Read().Printf("dereferenceObject: already decompressed or used object w/o offset -> ignored")
 		return nil
 	}
 
@@ -2281,20 +2471,25 @@ func dereferenceObject(ctx *Context, objNr int) error {
 
 	// Already dereferenced object.
 	if o != nil {
-		logStream(entry.Object)
+		logStream(ctx, entry.Object)
 		updateBinaryTotalSize(ctx, o)
-		log.Read.Printf("handleCachedStreamDict: using cached object %d of %d\n<%s>\n", objNr, xRefTableSize, entry.Object)
+		ctx.Log.Read().Printf("handleCachedStreamDict: using cached object %d of %d\n<%s>\n", objNr, xRefTableSize, entry.Object)
 		return nil
 	}
 
 	// Dereference (load from disk into memory).
 
-	log.Read.Printf("dereferenceObject: dereferencing object %d\n", objNr)
+	ctx.Log.Read().Printf("dereferenceObject: dereferencing object %d\n", objNr)
 
-	// Parse object from file: anything goes dict, array, integer, float, streamdicts...
-	o, err := ParseObject(ctx, *entry.Offset, objNr, *entry.Generation)
+	var err error
+	if r, ok := prefetched[objNr]; ok {
+		r.commit(ctx)
+		o, err = r.o, r.err
+	} else {
+		o, err = loadObject(ctx, objNr, entry)
+	}
 	if err != nil {
-		return errors.Wrapf(err, "dereferenceObject: problem dereferencing object %d", objNr)
+		return err
 	}
 
 	entry.Object = o
@@ -2305,29 +2500,13 @@ func dereferenceObject(ctx *Context, objNr int) error {
 		return err
 	}
 
-	// Handle stream dicts.
-
-	if _, ok := o.(ObjectStreamDict); ok {
-		return errors.Errorf("dereferenceObject: object stream should already be dereferenced at obj:%d", objNr)
-	}
-
-	if _, ok := o.(XRefStreamDict); ok {
-		return errors.Errorf("dereferenceObject: xref stream should already be dereferenced at obj:%d", objNr)
-	}
-
 	if sd, ok := o.(StreamDict); ok {
-
-		err = loadStreamDict(ctx, &sd, objNr, *entry.Generation)
-		if err != nil {
-			return err
-		}
-
-		entry.Object = sd
+		ctx.Read.BinaryTotalSize += *sd.StreamLength
 	}
 
-	log.Read.Printf("dereferenceObject: end obj %d of %d\n<%s>\n", objNr, xRefTableSize, entry.Object)
+	ctx.Log.Read().Printf("dereferenceObject: end obj %d of %d\n<%s>\n", objNr, xRefTableSize, entry.Object)
 
-	logStream(entry.Object)
+	logStream(ctx, entry.Object)
 
 	return nil
 }
@@ -2381,7 +2560,7 @@ func processRefCounts(xRefTable *XRefTable, o Object) {
 // Dereferences all objects including compressed objects from object streams.
 func dereferenceObjects(ctx *Context) error {
 
-	log.Read.Println("dereferenceObjects: begin")
+	ctx.Log.Read().Println("dereferenceObjects: begin")
 
 	xRefTable := ctx.XRefTable
 
@@ -2393,10 +2572,26 @@ func dereferenceObjects(ctx *Context) error {
 	}
 	sort.Ints(keys)
 
-	for _, objNr := range keys {
-		err := dereferenceObject(ctx, objNr)
-		if err != nil {
-			return err
+	n := readWorkers(ctx)
+
+	for objNrs := keys; len(objNrs) > 0; {
+		window := objNrs
+		var prefetched map[int]*prefetchResult
+		if n > 1 {
+			var jj []prefetchJob
+			window, jj = nextPrefetchWindow(ctx, objNrs)
+			prefetched = prefetchObjects(ctx, jj, n)
+		}
+		objNrs = objNrs[len(window):]
+
+		for _, objNr := range window {
+			if err := ctx.Canceled(); err != nil {
+				return err
+			}
+			err := dereferenceObject(ctx, objNr, prefetched)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -2408,7 +2603,7 @@ func dereferenceObjects(ctx *Context) error {
 		processRefCounts(xRefTable, entry.Object)
 	}
 
-	log.Read.Println("dereferenceObjects: end")
+	ctx.Log.Read().Println("dereferenceObjects: end")
 
 	return nil
 }
@@ -2417,7 +2612,7 @@ func dereferenceObjects(ctx *Context) error {
 // and record this as rootVersion (as opposed to headerVersion).
 func identifyRootVersion(xRefTable *XRefTable) error {
 
-	log.Read.Println("identifyRootVersion: begin")
+	xRefTable.Log.Read().Println("identifyRootVersion: begin")
 
 	// Try to get Version from Root.
 	rootVersionStr, err := xRefTable.ParseRootVersion()
@@ -2443,16 +2638,17 @@ func identifyRootVersion(xRefTable *XRefTable) error {
 			xRefTable.HeaderVersion, *rootVersionStr)
 	}
 
-	log.Read.Println("identifyRootVersion: end")
+	xRefTable.Log.Read().Println("identifyRootVersion: end")
 
 	return nil
 }
 
 // Parse all Objects including stream content from file and save to the corresponding xRefTableEntries.
 // This includes processing of object streams and linearization dicts.
-func dereferenceXRefTable(ctx *Context, conf *Configuration) error {
+// If selectPages is not nil only the page tree and the objects of the selected pages get parsed.
+func dereferenceXRefTable(ctx *Context, selectPages func(pageCount int) (IntSet, error)) error {
 
-	log.Read.Println("dereferenceXRefTable: begin")
+	ctx.Log.Read().Println("dereferenceXRefTable: begin")
 
 	xRefTable := ctx.XRefTable
 
@@ -2472,8 +2668,18 @@ func dereferenceXRefTable(ctx *Context, conf *Configuration) error {
 		return err
 	}
 
-	// For each xRefTableEntry assign a Object either by parsing from file or pointing to a decompressed object.
-	err = dereferenceObjects(ctx)
+	if ctx.Read.Repaired {
+		if err = recoverCompressedObjects(ctx); err != nil {
+			return err
+		}
+	}
+
+	if selectPages != nil {
+		err = dereferencePages(ctx, selectPages)
+	} else {
+		// For each xRefTableEntry assign a Object either by parsing from file or pointing to a decompressed object.
+		err = dereferenceObjects(ctx)
+	}
 	if err != nil {
 		return err
 	}
@@ -2484,7 +2690,7 @@ func dereferenceXRefTable(ctx *Context, conf *Configuration) error {
 		return err
 	}
 
-	log.Read.Println("dereferenceXRefTable: end")
+	ctx.Log.Read().Println("dereferenceXRefTable: end")
 
 	return nil
 }
@@ -2513,7 +2719,7 @@ func needsOwnerAndUserPassword(cmd CommandMode) bool {
 	return cmd == CHANGEOPW || cmd == CHANGEUPW || cmd == SETPERMISSIONS
 }
 
-func handlePermissions(ctx *Context) error {
+func handlePermissions(ctx *Context, ownerPW bool) error {
 
 	// AES256 Validate permissions
 	ok, err := validatePermissions(ctx)
@@ -2526,11 +2732,7 @@ func handlePermissions(ctx *Context) error {
 	}
 
 	// Double check minimum permissions for pdfcpu processing.
-	if !hasNeededPermissions(ctx.Cmd, ctx.E) {
-		return errors.New("pdfcpu: insufficient access permissions")
-	}
-
-	return nil
+	return checkPermissions(ctx, ownerPW)
 }
 
 func setupEncryptionKey(ctx *Context, d Dict) (err error) {
@@ -2544,48 +2746,62 @@ func setupEncryptionKey(ctx *Context, d Dict) (err error) {
 		return err
 	}
 
-	var ok bool
+	checkEncryptionStrength(ctx, d)
+
+	// Many documents only restrict permissions and open without a user password.
+	if ctx.E.EmptyUPW, err = emptyUserPassword(ctx); err != nil {
+		return err
+	}
 
 	//fmt.Printf("opw: <%s> upw: <%s> \n", ctx.OwnerPW, ctx.UserPW)
 
 	// Validate the owner password aka. permissions/master password.
-	ok, err = validateOwnerPassword(ctx)
+	ownerPW, err := validateOwnerPassword(ctx)
 	if err != nil {
 		return err
 	}
 
 	// If the owner password does not match we generally move on if the user password is correct
 	// unless we need to insist on a correct owner password due to the specific command in progress.
-	if !ok && needsOwnerAndUserPassword(ctx.Cmd) {
+	if !ownerPW && needsOwnerAndUserPassword(ctx.Cmd) {
 		return errors.New("pdfcpu: please provide the owner password with -opw")
 	}
 
 	// Generally the owner password, which is also regarded as the master password or set permissions password
 	// is sufficient for moving on. A password change is an exception since it requires both current passwords.
-	if ok && !needsOwnerAndUserPassword(ctx.Cmd) {
+	if ownerPW && !needsOwnerAndUserPassword(ctx.Cmd) {
 		// AES256 Validate permissions
-		ok, err = validatePermissions(ctx)
+		ok, err := validatePermissions(ctx)
 		if err != nil {
 			return err
 		}
 		if !ok {
 			return errors.New("pdfcpu: corrupted permissions after opw ok")
 		}
-		return nil
+		return checkPermissions(ctx, true)
 	}
 
 	// Validate the user password aka. document open password.
-	ok, err = validateUserPassword(ctx)
+	ok, err := validateUserPassword(ctx)
 	if err != nil {
 		return err
 	}
+	if !ok && ctx.E.EmptyUPW && !needsOwnerAndUserPassword(ctx.Cmd) {
+		// Fall back to the empty user password unless changing passwords or permissions.
+		ctx.Warn("incorrect user password, the document opens with the empty user password and is not protected by a user password")
+		ctx.UserPW = ""
+		ok, err = validateUserPassword(ctx)
+		if err != nil {
+			return err
+		}
+	}
 	if !ok {
 		return errors.New("pdfcpu: please provide the correct password")
 	}
 
 	//fmt.Printf("upw ok: %t\n", ok)
 
-	return handlePermissions(ctx)
+	return handlePermissions(ctx, ownerPW)
 }
 
 func checkForEncryption(ctx *Context) error {
@@ -2598,7 +2814,7 @@ func checkForEncryption(ctx *Context) error {
 	}
 
 	// This file is encrypted.
-	log.Read.Printf("Encryption: %v\n", ir)
+	ctx.Log.Read().Printf("Encryption: %v\n", ir)
 
 	if ctx.Cmd == ENCRYPT {
 		// We want to encrypt this file.
@@ -2610,7 +2826,7 @@ func checkForEncryption(ctx *Context) error {
 	if err != nil {
 		return err
 	}
-	log.Read.Printf("%s\n", d)
+	ctx.Log.Read().Printf("%s\n", d)
 
 	// We need to decrypt this file in order to read it.
 	return setupEncryptionKey(ctx, d)
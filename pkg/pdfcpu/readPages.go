@@ -0,0 +1,226 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"github.com/pkg/errors"
+)
+
+// pageTreeNode is a page tree node encountered while loading the page tree.
+type pageTreeNode struct {
+	objNr  int
+	d      Dict
+	parent *pageTreeNode
+}
+
+// partialLoader loads the objects of selected pages only.
+type partialLoader struct {
+	ctx       *Context
+	loaded    IntSet // Object numbers dereferenced so far.
+	pageNodes IntSet // Object numbers of page tree nodes. Loading stops here.
+	leaves    []*pageTreeNode
+}
+
+// load dereferences the object objNr unless already loaded and returns it.
+func (pl *partialLoader) load(objNr int) (Object, error) {
+
+	entry, found := pl.ctx.Find(objNr)
+	if !found || entry.Free {
+		return nil, nil
+	}
+
+	if !pl.loaded[objNr] {
+		pl.loaded[objNr] = true
+		if err := dereferenceObject(pl.ctx, objNr, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return entry.Object, nil
+}
+
+// loadDeep loads all objects reachable from o without entering the page tree.
+func (pl *partialLoader) loadDeep(o Object) error {
+
+	switch o := o.(type) {
+
+	case IndirectRef:
+		objNr := o.ObjectNumber.Value()
+		if pl.loaded[objNr] || pl.pageNodes[objNr] {
+			return nil
+		}
+		o1, err := pl.load(objNr)
+		if err != nil {
+			return err
+		}
+		return pl.loadDeep(o1)
+
+	case Dict:
+		for _, v := range o {
+			if err := pl.loadDeep(v); err != nil {
+				return err
+			}
+		}
+
+	case StreamDict:
+		return pl.loadDeep(o.Dict)
+
+	case Array:
+		for _, v := range o {
+			if err := pl.loadDeep(v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// loadPageTree loads the page tree nodes below ir and records the leaves in page order.
+func (pl *partialLoader) loadPageTree(ir IndirectRef, parent *pageTreeNode) error {
+
+	if err := pl.ctx.Canceled(); err != nil {
+		return err
+	}
+
+	objNr := ir.ObjectNumber.Value()
+	if pl.pageNodes[objNr] {
+		return errors.Errorf("pdfcpu: page tree: cycle detected at obj#%d", objNr)
+	}
+	pl.pageNodes[objNr] = true
+
+	o, err := pl.load(objNr)
+	if err != nil {
+		return err
+	}
+
+	d, ok := o.(Dict)
+	if !ok {
+		return errors.Errorf("pdfcpu: page tree: corrupt node obj#%d", objNr)
+	}
+
+	node := &pageTreeNode{objNr: objNr, d: d, parent: parent}
+
+	kids := d.ArrayEntry("Kids")
+	if kids == nil {
+		// Page leaf
+		pl.leaves = append(pl.leaves, node)
+		return nil
+	}
+
+	for _, o := range kids {
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			return errors.Errorf("pdfcpu: page tree: corrupt kid of obj#%d", objNr)
+		}
+		if err := pl.loadPageTree(ir, node); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadPage loads the objects used by a page leaf including inherited attributes of its ancestors.
+func (pl *partialLoader) loadPage(node *pageTreeNode) error {
+
+	for n := node; n != nil; n = n.parent {
+		for k, v := range n.d {
+			if k == "Parent" || k == "Kids" {
+				continue
+			}
+			if err := pl.loadDeep(v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// dereferencePages loads the catalog, the document info dict, the page tree
+// and all objects needed by the pages returned by selectPages.
+func dereferencePages(ctx *Context, selectPages func(pageCount int) (IntSet, error)) error {
+
+	ctx.Log.Read().Println("dereferencePages: begin")
+
+	xRefTable := ctx.XRefTable
+
+	if xRefTable.Root == nil {
+		return errors.New("pdfcpu: dereferencePages: missing root")
+	}
+
+	pl := &partialLoader{ctx: ctx, loaded: IntSet{}, pageNodes: IntSet{}}
+
+	o, err := pl.load(xRefTable.Root.ObjectNumber.Value())
+	if err != nil {
+		return err
+	}
+
+	rootDict, ok := o.(Dict)
+	if !ok {
+		return errors.New("pdfcpu: dereferencePages: corrupt root")
+	}
+
+	if xRefTable.Info != nil {
+		if err := pl.loadDeep(*xRefTable.Info); err != nil {
+			return err
+		}
+	}
+
+	ir := rootDict.IndirectRefEntry("Pages")
+	if ir == nil {
+		return errors.New("pdfcpu: dereferencePages: missing page tree")
+	}
+
+	if err := pl.loadPageTree(*ir, nil); err != nil {
+		return err
+	}
+
+	pageCount := len(pl.leaves)
+
+	pages, err := selectPages(pageCount)
+	if err != nil {
+		return err
+	}
+
+	for pageNr, node := range pl.leaves {
+		if !pages[pageNr+1] {
+			continue
+		}
+		if err := pl.loadPage(node); err != nil {
+			return err
+		}
+	}
+
+	// Compressed objects get their generation assigned on decompression.
+	for _, e := range xRefTable.Table {
+		if e.Compressed && e.Generation == nil {
+			g := 0
+			e.Generation = &g
+		}
+	}
+
+	// Reference counts remain unset since most references point to objects not loaded.
+
+	xRefTable.PageCount = pageCount
+	ctx.Read.Pages = pages
+
+	ctx.Log.Read().Printf("dereferencePages: end, %d of %d objects loaded\n", len(pl.loaded), len(xRefTable.Table))
+
+	return nil
+}
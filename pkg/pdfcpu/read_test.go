@@ -0,0 +1,121 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestObjectStreamCacheLRU(t *testing.T) {
+	ctx := &Context{
+		Configuration: &Configuration{ObjectStreamCacheSize: 2},
+		XRefTable:     &XRefTable{Table: map[int]*XRefTableEntry{}},
+		Read:          &ReadContext{},
+	}
+
+	prolog := "1 0 "
+	for objNr := 10; objNr <= 12; objNr++ {
+		osd := ObjectStreamDict{ObjCount: 1, FirstObjOffset: len(prolog)}
+		osd.Raw = []byte(prolog + "(abc)")
+		ctx.Table[objNr] = &XRefTableEntry{Object: osd}
+	}
+
+	cached := func(objNr int) bool {
+		return ctx.Table[objNr].Object.(ObjectStreamDict).ObjArray != nil
+	}
+
+	for _, objNr := range []int{10, 11, 10, 12} {
+		if _, err := decodedObjectStream(ctx, objNr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 11 is the least recently used object stream.
+	if !cached(10) || cached(11) || !cached(12) {
+		t.Errorf("want object streams 10 and 12 cached, got %v\n", ctx.Read.objStreamCache)
+	}
+
+	if ctx.Read.ObjStmCacheHits != 1 || ctx.Read.ObjStmCacheMisses != 3 {
+		t.Errorf("want 1 hit and 3 misses, got %d and %d\n", ctx.Read.ObjStmCacheHits, ctx.Read.ObjStmCacheMisses)
+	}
+}
+
+func TestPrefetchWithinObjectStreamCache(t *testing.T) {
+	inFile := filepath.Join("..", "testdata", "go-lecture.pdf")
+
+	for _, n := range []int{1, 2} {
+		f, err := os.Open(inFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		conf := NewDefaultConfiguration()
+		conf.ObjectStreamCacheSize = n
+
+		ctx, err := NewContext(f, conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = readXRefTable(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err = decodeObjectStreams(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		decoded := func() int {
+			c := 0
+			for _, e := range ctx.Table {
+				if osd, ok := e.Object.(ObjectStreamDict); ok && osd.ObjArray != nil {
+					c++
+				}
+			}
+			return c
+		}
+
+		var keys []int
+		for k := range ctx.Table {
+			keys = append(keys, k)
+		}
+		sort.Ints(keys)
+
+		for objNrs := keys; len(objNrs) > 0; {
+			window, jj := nextPrefetchWindow(ctx, objNrs)
+			prefetched := prefetchObjects(ctx, jj, 4)
+			if c := decoded(); c > n {
+				t.Fatalf("cache size %d: %d object streams decoded after prefetching\n", n, c)
+			}
+			for _, objNr := range window {
+				if err := dereferenceObject(ctx, objNr, prefetched); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if c := decoded(); c > n {
+				t.Fatalf("cache size %d: %d object streams decoded after dereferencing\n", n, c)
+			}
+			objNrs = objNrs[len(window):]
+		}
+
+		if len(ctx.Read.ObjectStreams) <= n || ctx.Read.ObjStmCacheMisses < len(ctx.Read.ObjectStreams) {
+			t.Errorf("cache size %d: want at least %d misses, got %d\n", n, len(ctx.Read.ObjectStreams), ctx.Read.ObjStmCacheMisses)
+		}
+	}
+}
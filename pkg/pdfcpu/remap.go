@@ -0,0 +1,238 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"github.com/pkg/errors"
+)
+
+// PageRefMap maps object numbers of page dicts onto the object numbers of the page dicts replacing them.
+// A mapping onto 0 marks a page which is not part of the result.
+// Object numbers without mapping remain unchanged.
+type PageRefMap map[int]int
+
+// remap returns the remapped page reference o and true if o refers to a page which is not part of the result.
+func (m PageRefMap) remap(o Object) (Object, bool) {
+	ir, ok := o.(IndirectRef)
+	if !ok {
+		return o, false
+	}
+	objNr, found := m[ir.ObjectNumber.Value()]
+	if !found {
+		return o, false
+	}
+	if objNr == 0 {
+		return nil, true
+	}
+	ir.ObjectNumber = Integer(objNr)
+	return ir, false
+}
+
+func (xRefTable *XRefTable) collectPageObjNrs(root IndirectRef, objNrs *[]int, visited IntSet) error {
+
+	objNr := root.ObjectNumber.Value()
+	if visited[objNr] {
+		return errors.New("pdfcpu: corrupt page tree")
+	}
+	visited[objNr] = true
+
+	d, err := xRefTable.DereferenceDict(root)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range d.ArrayEntry("Kids") {
+
+		if o == nil {
+			continue
+		}
+
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			return errors.New("pdfcpu: corrupt page node dict")
+		}
+
+		d, err := xRefTable.DereferenceDict(ir)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			continue
+		}
+
+		if t := d.Type(); t != nil && *t == "Pages" {
+			if err := xRefTable.collectPageObjNrs(ir, objNrs, visited); err != nil {
+				return err
+			}
+			continue
+		}
+
+		*objNrs = append(*objNrs, ir.ObjectNumber.Value())
+	}
+
+	return nil
+}
+
+// pageObjNrs returns the object numbers of all page dicts in page order.
+func (xRefTable *XRefTable) pageObjNrs() ([]int, error) {
+
+	root, err := xRefTable.Pages()
+	if err != nil {
+		return nil, err
+	}
+
+	objNrs := []int{}
+	if err := xRefTable.collectPageObjNrs(*root, &objNrs, IntSet{}); err != nil {
+		return nil, err
+	}
+
+	return objNrs, nil
+}
+
+// remapDestination remaps the page of an explicit destination and returns true if its page is not part of the result.
+func (ctx *Context) remapDestination(o Object, m PageRefMap) (bool, error) {
+
+	o, err := ctx.Dereference(o)
+	if err != nil {
+		return false, err
+	}
+
+	a, ok := o.(Array)
+	if !ok || len(a) == 0 {
+		// Named destinations are left alone.
+		return false, nil
+	}
+
+	o, dropped := m.remap(a[0])
+	if dropped {
+		return true, nil
+	}
+	a[0] = o
+
+	return false, nil
+}
+
+func (ctx *Context) remapAnnotation(d Dict, pageIndRef IndirectRef, m PageRefMap, keepStructure bool) error {
+
+	if _, found := d.Find("P"); found {
+		d.Update("P", pageIndRef)
+	}
+
+	if !keepStructure {
+		d.Delete("StructParent")
+	}
+
+	if o, found := d.Find("Dest"); found {
+		dropped, err := ctx.remapDestination(o, m)
+		if err != nil {
+			return err
+		}
+		if dropped {
+			d.Delete("Dest")
+		}
+	}
+
+	act, err := ctx.DereferenceDict(d["A"])
+	if err != nil || act == nil {
+		return err
+	}
+
+	if s := act.NameEntry("S"); s == nil || *s != "GoTo" {
+		return nil
+	}
+
+	dropped, err := ctx.remapDestination(act["D"], m)
+	if err != nil {
+		return err
+	}
+	if dropped {
+		d.Delete("A")
+	}
+
+	return nil
+}
+
+func (ctx *Context) remapPage(pageIndRef IndirectRef, m PageRefMap, keepStructure bool, visited IntSet) error {
+
+	d, err := ctx.DereferenceDict(pageIndRef)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.Errorf("pdfcpu: missing page dict obj#%d\n", pageIndRef.ObjectNumber.Value())
+	}
+
+	if !keepStructure {
+		d.Delete("StructParents")
+		if tabs := d.NameEntry("Tabs"); tabs != nil && *tabs == "S" {
+			// Structure order is not available anymore.
+			d.Delete("Tabs")
+		}
+	}
+
+	a, err := ctx.DereferenceArray(d["Annots"])
+	if err != nil {
+		return err
+	}
+
+	for _, o := range a {
+
+		if ir, ok := o.(IndirectRef); ok {
+			objNr := ir.ObjectNumber.Value()
+			if visited[objNr] {
+				continue
+			}
+			visited[objNr] = true
+		}
+
+		annot, err := ctx.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		if annot == nil {
+			continue
+		}
+
+		if err := ctx.remapAnnotation(annot, pageIndRef, m, keepStructure); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemapPageRefs rewrites the page references of the page dicts pages of ctx after page object numbers have changed
+// eg. as a result of merging, splitting or collecting pages.
+// References to pages mapped onto 0 get removed. The following references get processed:
+//
+//	annotation /P entries pointing to the annotated page
+//	explicit destinations of link annotations and of their GoTo actions
+//
+// Unless keepStructure is true the structure tree is considered gone and
+// /StructParents, /StructParent and structure based tab order get removed.
+func (ctx *Context) RemapPageRefs(pages []IndirectRef, m PageRefMap, keepStructure bool) error {
+
+	visited := IntSet{}
+
+	for _, ir := range pages {
+		if err := ctx.remapPage(ir, m, keepStructure, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,315 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// objMarker matches the start of an indirect object: "objNr genNr obj".
+var objMarker = regexp.MustCompile(`(\d+)[\x00\t\n\f\r ]+(\d+)[\x00\t\n\f\r ]+obj\b`)
+
+var (
+	kwEndObj    = []byte("endobj")
+	kwStream    = []byte("stream")
+	kwEndStream = []byte("endstream")
+	kwTrailer   = []byte("trailer")
+	kwStartXRef = []byte("startxref")
+)
+
+// The max size of a trailer dict we are willing to parse.
+const maxTrailerSize = 4096
+
+type trailerCandidate struct {
+	offset int64
+	d      Dict
+}
+
+func isObjMarkerStart(bb []byte, i int) bool {
+	if i == 0 {
+		return true
+	}
+	c := bb[i-1]
+	return cbWhitespace(c) || cbDelimiter(c)
+}
+
+// scanObjects adds an xref table entry for each indirect object found in bb.
+// Objects defined more than once resolve to their last definition in accordance with incremental updates.
+func scanObjects(ctx *Context, bb []byte) {
+	for i := 0; i < len(bb); {
+		loc := objMarker.FindSubmatchIndex(bb[i:])
+		if loc == nil {
+			break
+		}
+		m := bb[i:]
+		start, next := i+loc[0], i+loc[1]
+		i = next
+
+		if !isObjMarkerStart(bb, start) {
+			continue
+		}
+
+		objNr, err1 := strconv.Atoi(string(m[loc[2]:loc[3]]))
+		genNr, err2 := strconv.Atoi(string(m[loc[4]:loc[5]]))
+		if err1 != nil || err2 != nil || objNr == 0 {
+			continue
+		}
+
+		off := int64(start)
+		ctx.Table[objNr] = &XRefTableEntry{Offset: &off, Generation: &genNr}
+		ctx.trace(TraceXRefRebuild, off, objNr, "recovered")
+
+		// Skip stream data which might contain anything.
+		e := bytes.Index(bb[next:], kwEndObj)
+		if e < 0 {
+			e = len(bb) - next
+		}
+		if s := bytes.Index(bb[next:next+e], kwStream); s >= 0 {
+			if j := bytes.Index(bb[next+s:], kwEndStream); j >= 0 {
+				i = next + s + j + len(kwEndStream)
+			}
+		}
+	}
+}
+
+// scanTrailers returns all parsable trailer dicts found in bb.
+func scanTrailers(bb []byte) []trailerCandidate {
+	var tt []trailerCandidate
+
+	for i := 0; ; {
+		j := bytes.Index(bb[i:], kwTrailer)
+		if j < 0 {
+			break
+		}
+		i += j + len(kwTrailer)
+
+		buf := bb[i:]
+		if k := bytes.Index(buf, kwStartXRef); k >= 0 {
+			buf = buf[:k]
+		}
+		if len(buf) > maxTrailerSize {
+			buf = buf[:maxTrailerSize]
+		}
+
+		l := strings.TrimSpace(string(buf))
+		o, err := parseObject(&l)
+		if err != nil {
+			continue
+		}
+		if d, ok := o.(Dict); ok {
+			tt = append(tt, trailerCandidate{offset: int64(i), d: d})
+		}
+	}
+
+	return tt
+}
+
+// classifyObjects inspects all recovered objects for xref streams, object streams and catalogs.
+// Unparsable objects are dropped.
+func classifyObjects(ctx *Context) ([]trailerCandidate, *IndirectRef) {
+	var (
+		tt         []trailerCandidate
+		catalog    *IndirectRef
+		catalogOff int64 = -1
+	)
+
+	for objNr, entry := range ctx.Table {
+		if entry.Free {
+			continue
+		}
+
+		o, err := ParseObject(ctx, *entry.Offset, objNr, *entry.Generation)
+		if err != nil {
+			ctx.Log.Read().Printf("rebuildXRefTable: dropping obj#%d: %v\n", objNr, err)
+			delete(ctx.Table, objNr)
+			continue
+		}
+
+		var d Dict
+		switch o := o.(type) {
+		case Dict:
+			d = o
+		case StreamDict:
+			d = o.Dict
+		default:
+			continue
+		}
+
+		switch t := d.Type(); {
+
+		case t == nil:
+
+		case *t == "XRef":
+			// The xref stream dict doubles as trailer dict, the stream itself is obsolete.
+			tt = append(tt, trailerCandidate{offset: *entry.Offset, d: d})
+			g := FreeHeadGeneration
+			ctx.Table[objNr] = &XRefTableEntry{Free: true, Offset: &zero, Generation: &g}
+
+		case *t == "ObjStm":
+			ctx.Read.ObjectStreams[objNr] = true
+
+		case *t == "Catalog":
+			if *entry.Offset > catalogOff {
+				catalog, catalogOff = NewIndirectRef(objNr, *entry.Generation), *entry.Offset
+			}
+		}
+	}
+
+	return tt, catalog
+}
+
+// applyTrailers reconstructs the trailer info starting with the last trailer found.
+func applyTrailers(ctx *Context, tt []trailerCandidate) {
+	sort.Slice(tt, func(i, j int) bool { return tt[i].offset > tt[j].offset })
+
+	for _, t := range tt {
+		if ctx.Root == nil {
+			ctx.Root = t.d.IndirectRefEntry("Root")
+		}
+		if ctx.Info == nil {
+			ctx.Info = t.d.IndirectRefEntry("Info")
+		}
+		if ctx.ID == nil {
+			ctx.ID = t.d.ArrayEntry("ID")
+		}
+		if ctx.Encrypt == nil {
+			ctx.Encrypt = t.d.IndirectRefEntry("Encrypt")
+		}
+	}
+}
+
+// rebuildXRefTable reconstructs the xref table and the trailer by scanning the whole file for indirect objects.
+// Objects compressed into object streams are recovered once the object streams have been decoded.
+func rebuildXRefTable(ctx *Context) error {
+	ctx.Log.Read().Println("rebuildXRefTable: begin")
+
+	rs := ctx.Read.rs
+
+	hv, eolCount, err := headerVersion(ctx, rs)
+	if err != nil {
+		return err
+	}
+	ctx.HeaderVersion = hv
+	ctx.Read.EolCount = eolCount
+
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	bb, err := ioutil.ReadAll(rs)
+	if err != nil {
+		return err
+	}
+
+	g := FreeHeadGeneration
+	ctx.Table = map[int]*XRefTableEntry{0: {Free: true, Offset: &zero, Generation: &g}}
+	ctx.Root, ctx.Info, ctx.ID, ctx.Encrypt = nil, nil, nil, nil
+	ctx.Read.ObjectStreams = IntSet{}
+	ctx.Read.XRefStreams = IntSet{}
+	ctx.Read.UsingXRefStreams, ctx.Read.Hybrid = false, false
+	ctx.Read.Repaired = true
+
+	// Incremental updates would otherwise point to a corrupt xref section.
+	ctx.Write.OffsetPrevXRef = nil
+
+	scanObjects(ctx, bb)
+
+	tt, catalog := classifyObjects(ctx)
+
+	if err := ctx.Canceled(); err != nil {
+		return err
+	}
+
+	applyTrailers(ctx, append(scanTrailers(bb), tt...))
+
+	// The root object might be compressed into an object stream not decoded yet.
+	if ctx.Root == nil || !ctx.Exists(ctx.Root.ObjectNumber.Value()) && len(ctx.Read.ObjectStreams) == 0 {
+		if catalog == nil {
+			return errors.New("pdfcpu: rebuildXRefTable: no catalog found")
+		}
+		ctx.Root = catalog
+	}
+
+	maxObjNr := 0
+	for objNr := range ctx.Table {
+		if objNr > maxObjNr {
+			maxObjNr = objNr
+		}
+	}
+	size := maxObjNr + 1
+	ctx.Size = &size
+
+	ctx.Log.Read().Printf("rebuildXRefTable: end, recovered %d objects\n", len(ctx.Table)-1)
+
+	return nil
+}
+
+// recoverCompressedObjects adds xref table entries for objects living in object streams of a repaired file.
+// An object stream located after an uncompressed definition of one of its objects takes precedence.
+func recoverCompressedObjects(ctx *Context) error {
+	var objStms []int
+	for objNr := range ctx.Read.ObjectStreams {
+		objStms = append(objStms, objNr)
+	}
+	sort.Slice(objStms, func(i, j int) bool {
+		return *ctx.Table[objStms[i]].Offset < *ctx.Table[objStms[j]].Offset
+	})
+
+	for _, objStmNr := range objStms {
+		entry := ctx.Table[objStmNr]
+		osd, ok := entry.Object.(ObjectStreamDict)
+		if !ok {
+			continue
+		}
+
+		if osd.Content == nil {
+			if err := osd.Decode(); err != nil {
+				return errors.Wrapf(err, "recoverCompressedObjects: obj#%d", objStmNr)
+			}
+		}
+		if osd.FirstObjOffset > len(osd.Content) {
+			return errors.Errorf("pdfcpu: recoverCompressedObjects: corrupt object stream obj#%d", objStmNr)
+		}
+
+		prolog := bytes.ReplaceAll(osd.Content[:osd.FirstObjOffset], []byte{0x00}, []byte{0x20})
+		ff := strings.Fields(string(prolog))
+
+		for i := 0; i+1 < len(ff); i += 2 {
+			objNr, err := strconv.Atoi(ff[i])
+			if err != nil {
+				return errors.Errorf("pdfcpu: recoverCompressedObjects: corrupt object stream obj#%d", objStmNr)
+			}
+			if e, found := ctx.Table[objNr]; found && !e.Free && !e.Compressed && *e.Offset > *entry.Offset {
+				continue
+			}
+			objStm, ind := objStmNr, i/2
+			ctx.Table[objNr] = &XRefTableEntry{Compressed: true, ObjectStream: &objStm, ObjectStreamInd: &ind}
+			if objNr >= *ctx.Size {
+				*ctx.Size = objNr + 1
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,470 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// VersionFeature is a feature used by a document along with the PDF version introducing it.
+type VersionFeature struct {
+	Feature string  `json:"feature"`
+	Version Version `json:"version"`
+}
+
+func (f VersionFeature) String() string {
+	return fmt.Sprintf("%s (PDF %s)", f.Feature, f.Version)
+}
+
+// Catalog entries introduced after PDF 1.0, see 7.7.2 Table 28.
+var catalogVersions = map[string]Version{
+	"Dests":             V11,
+	"Threads":           V11,
+	"OpenAction":        V11,
+	"URI":               V11,
+	"Names":             V12,
+	"ViewerPreferences": V12,
+	"AcroForm":          V12,
+	"PageLabels":        V13,
+	"StructTreeRoot":    V13,
+	"SpiderInfo":        V13,
+	"AA":                V14,
+	"Metadata":          V14,
+	"MarkInfo":          V14,
+	"Lang":              V14,
+	"OutputIntents":     V14,
+	"PieceInfo":         V14,
+	"OCProperties":      V15,
+	"Perms":             V15,
+	"Legal":             V15,
+	"Requirements":      V17,
+	"Collection":        V17,
+	"NeedsRendering":    V17,
+}
+
+// Page entries introduced after PDF 1.0, see 7.7.3.3 Table 30.
+var pageVersions = map[string]Version{
+	"Trans":                V11,
+	"AA":                   V12,
+	"BleedBox":             V13,
+	"TrimBox":              V13,
+	"ArtBox":               V13,
+	"PieceInfo":            V13,
+	"StructParents":        V13,
+	"ID":                   V13,
+	"PZ":                   V13,
+	"SeparationInfo":       V13,
+	"BoxColorInfo":         V14,
+	"Group":                V14,
+	"Metadata":             V14,
+	"Tabs":                 V15,
+	"TemplateInstantiated": V15,
+	"PresSteps":            V15,
+	"UserUnit":             V16,
+	"VP":                   V16,
+}
+
+// Annotation types introduced after PDF 1.0, see 12.5.6.1 Table 169.
+var annotationVersions = map[string]Version{
+	"Sound":          V12,
+	"Movie":          V12,
+	"Widget":         V12,
+	"FreeText":       V13,
+	"Line":           V13,
+	"Square":         V13,
+	"Circle":         V13,
+	"Highlight":      V13,
+	"Underline":      V13,
+	"StrikeOut":      V13,
+	"Stamp":          V13,
+	"Ink":            V13,
+	"Popup":          V13,
+	"FileAttachment": V13,
+	"TrapNet":        V13,
+	"Squiggly":       V14,
+	"PrinterMark":    V14,
+	"Polygon":        V15,
+	"PolyLine":       V15,
+	"Caret":          V15,
+	"Screen":         V15,
+	"Watermark":      V16,
+	"3D":             V16,
+	"Redact":         V17,
+}
+
+// Action types introduced after PDF 1.1, see 12.6.4.1 Table 198.
+var actionVersions = map[string]Version{
+	"Named":       V12,
+	"SubmitForm":  V12,
+	"ResetForm":   V12,
+	"ImportData":  V12,
+	"Hide":        V12,
+	"JavaScript":  V13,
+	"SetOCGState": V15,
+	"Rendition":   V15,
+	"Trans":       V15,
+	"GoToE":       V16,
+	"GoTo3DView":  V16,
+}
+
+// Object types introduced after PDF 1.2.
+var typeVersions = map[string]Version{
+	"EmbeddedFile": V13,
+	"Sig":          V13,
+	"StructElem":   V13,
+	"Metadata":     V14,
+	"OutputIntent": V14,
+	"Mask":         V14,
+	"OCG":          V15,
+	"OCMD":         V15,
+	"Collection":   V17,
+}
+
+// Stream filters introduced after PDF 1.2, see 7.4.1 Table 6.
+var filterVersions = map[string]Version{
+	"JBIG2Decode": V14,
+	"JPXDecode":   V15,
+	"Crypt":       V15,
+}
+
+// Graphics state parameters introduced after PDF 1.2, see 8.4.5 Table 58.
+var extGStateVersions = map[string]Version{
+	"OPM":   V13,
+	"SA":    V13,
+	"Font":  V13,
+	"BM":    V14,
+	"SMask": V14,
+	"CA":    V14,
+	"ca":    V14,
+	"AIS":   V14,
+	"TK":    V14,
+}
+
+// Color space families introduced after PDF 1.2, see 8.6.
+var colorSpaceVersions = map[string]Version{
+	"ICCBased": V13,
+	"DeviceN":  V13,
+}
+
+// versionFeatures collects the features of a document requiring a PDF version > 1.0.
+type versionFeatures map[string]Version
+
+func (ff versionFeatures) add(feature string, v Version) {
+	if v > V10 && v > ff[feature] {
+		ff[feature] = v
+	}
+}
+
+func (ff versionFeatures) addEntries(d Dict, m map[string]Version, prefix string) {
+	for k := range d {
+		if v, ok := m[k]; ok {
+			ff.add(prefix+k, v)
+		}
+	}
+}
+
+func (ff versionFeatures) addFilters(sd StreamDict) {
+	for _, f := range sd.FilterPipeline {
+		if v, ok := filterVersions[f.Name]; ok {
+			ff.add(f.Name+" filter", v)
+		}
+	}
+}
+
+func (ff versionFeatures) addExtGStates(xRefTable *XRefTable, o Object) error {
+	d, err := xRefTable.DereferenceDict(o)
+	if err != nil || d == nil {
+		return err
+	}
+	for _, o := range d {
+		gs, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		ff.addEntries(gs, extGStateVersions, "graphics state ")
+	}
+	return nil
+}
+
+func (ff versionFeatures) addDict(xRefTable *XRefTable, d Dict) error {
+
+	t, st := d.Type(), d.Subtype()
+
+	if t != nil {
+		switch *t {
+		case "Catalog":
+			ff.addEntries(d, catalogVersions, "catalog ")
+		case "Page":
+			ff.addEntries(d, pageVersions, "page ")
+		case "Action":
+		default:
+			if v, ok := typeVersions[*t]; ok {
+				ff.add(*t, v)
+			}
+		}
+	}
+
+	if st != nil {
+		if v, ok := annotationVersions[*st]; ok && (t == nil || *t == "Annot") {
+			if _, found := d.Find("Rect"); found {
+				ff.add(*st+" annotation", v)
+			}
+		}
+		switch *st {
+		case "Image":
+			if _, found := d.Find("SMask"); found {
+				ff.add("soft mask images", V14)
+			}
+			if _, found := d.Find("SMaskInData"); found {
+				ff.add("JPX soft masks", V15)
+			}
+			if _, found := d.Find("OC"); found {
+				ff.add("optional content", V15)
+			}
+		case "Form":
+			if _, found := d.Find("Group"); found {
+				ff.add("transparency groups", V14)
+			}
+			if _, found := d.Find("Ref"); found {
+				ff.add("reference XObjects", V14)
+			}
+			if _, found := d.Find("OC"); found {
+				ff.add("optional content", V15)
+			}
+		case "OpenType":
+			ff.add("OpenType fonts", V16)
+		}
+	}
+
+	if s := d.NameEntry("S"); s != nil && (t == nil || *t == "Action") {
+		if v, ok := actionVersions[*s]; ok {
+			ff.add(*s+" action", v)
+		}
+		if *s == "Transparency" {
+			ff.add("transparency groups", V14)
+		}
+	}
+
+	if _, found := d.Find("FontFile3"); found {
+		ff.add("compact font files", V12)
+	}
+
+	if i := d.IntEntry("ShadingType"); i != nil {
+		ff.add("smooth shading", V13)
+	}
+
+	if i := d.IntEntry("FunctionType"); i != nil && *i > 0 {
+		ff.add(fmt.Sprintf("type %d functions", *i), V13)
+	}
+
+	if o, found := d.Find("ExtGState"); found {
+		if err := ff.addExtGStates(xRefTable, o); err != nil {
+			return err
+		}
+	}
+
+	for _, o := range d {
+		if err := ff.addDirectObject(xRefTable, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addDirectObject collects the features of o and all its direct sub objects.
+// Indirect objects are taken care of separately.
+func (ff versionFeatures) addDirectObject(xRefTable *XRefTable, o Object) error {
+	switch o := o.(type) {
+
+	case Dict:
+		return ff.addDict(xRefTable, o)
+
+	case StreamDict:
+		ff.addFilters(o)
+		return ff.addDict(xRefTable, o.Dict)
+
+	case Array:
+		if len(o) > 0 {
+			if n, ok := o[0].(Name); ok {
+				if v, ok := colorSpaceVersions[n.Value()]; ok {
+					ff.add(n.Value()+" color spaces", v)
+				}
+			}
+		}
+		for _, o := range o {
+			if err := ff.addDirectObject(xRefTable, o); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// addEncryption collects the features used for encrypting the document on write.
+func (ff versionFeatures) addEncryption(ctx *Context) {
+	if ctx.Encrypt == nil || ctx.EncKey == nil || ctx.E == nil {
+		return
+	}
+
+	switch {
+	case ctx.E.R >= 5:
+		ff.add("AES-256 encryption", V17)
+	case ctx.E.R == 4 && (ctx.AES4Streams || ctx.AES4Strings || ctx.AES4EmbeddedStreams):
+		ff.add("AES-128 encryption", V16)
+	case ctx.E.R == 4:
+		ff.add("crypt filters", V15)
+	case ctx.E.R == 3:
+		ff.add("128-bit RC4 encryption", V14)
+	default:
+		ff.add("encryption", V11)
+	}
+}
+
+func (ctx *Context) versionFeatures() (versionFeatures, error) {
+	ff := versionFeatures{}
+
+	for objNr, e := range ctx.Table {
+		if objNr == 0 || e == nil || e.Free || e.Object == nil {
+			continue
+		}
+		switch e.Object.(type) {
+		case ObjectStreamDict, XRefStreamDict:
+			// Written according to the configuration.
+			continue
+		}
+		if ctx.Encrypt != nil && objNr == ctx.Encrypt.ObjectNumber.Value() {
+			continue
+		}
+		if err := ff.addDirectObject(ctx.XRefTable, e.Object); err != nil {
+			return nil, err
+		}
+	}
+
+	return ff, nil
+}
+
+func (ff versionFeatures) sorted() (Version, []VersionFeature) {
+	features := make([]VersionFeature, 0, len(ff))
+	v := V10
+	for f, fv := range ff {
+		features = append(features, VersionFeature{Feature: f, Version: fv})
+		if fv > v {
+			v = fv
+		}
+	}
+
+	sort.Slice(features, func(i, j int) bool {
+		if features[i].Version != features[j].Version {
+			return features[i].Version > features[j].Version
+		}
+		return features[i].Feature < features[j].Feature
+	})
+
+	return v, features
+}
+
+// RequiredVersion returns the minimum PDF version able to express the content of ctx
+// along with all features requiring a version > 1.0 ordered by descending version.
+func (ctx *Context) RequiredVersion() (Version, []VersionFeature, error) {
+	ff, err := ctx.versionFeatures()
+	if err != nil {
+		return V10, nil, err
+	}
+	v, features := ff.sorted()
+	return v, features, nil
+}
+
+// requiredVersionForWriting returns the minimum PDF version able to express ctx
+// including object streams and encryption as configured for writing.
+func (ctx *Context) requiredVersionForWriting() (Version, []VersionFeature, error) {
+	ff, err := ctx.versionFeatures()
+	if err != nil {
+		return V10, nil, err
+	}
+
+	if ctx.WriteObjectStream || ctx.WriteXRefStream {
+		ff.add("object and xref streams", V15)
+	}
+
+	ff.addEncryption(ctx)
+
+	v, features := ff.sorted()
+	return v, features, nil
+}
+
+func validTargetVersion(s string) bool {
+	if s == "" || s == "auto" {
+		return true
+	}
+	_, err := PDFVersion(s)
+	return err == nil
+}
+
+// versionForWriting returns the version to be written into the header according to ctx.TargetVersion.
+// Object and xref streams are turned off for targets below PDF 1.5.
+// An error is returned if the document uses features unsupported by the target version.
+func (ctx *Context) versionForWriting() (Version, error) {
+
+	target := ctx.TargetVersion
+	if target == "" {
+		target = V17.String()
+	}
+
+	if target == "auto" {
+		v, _, err := ctx.requiredVersionForWriting()
+		return v, err
+	}
+
+	v, err := PDFVersion(target)
+	if err != nil {
+		return V10, errors.Errorf("pdfcpu: unsupported target version: %s", target)
+	}
+
+	if v == V17 {
+		// Nothing is beyond the highest supported version.
+		return v, nil
+	}
+
+	if v < V15 {
+		ctx.WriteObjectStream = false
+		ctx.WriteXRefStream = false
+	}
+
+	_, ff, err := ctx.requiredVersionForWriting()
+	if err != nil {
+		return V10, err
+	}
+
+	var ss []string
+	for _, f := range ff {
+		if f.Version > v {
+			ss = append(ss, f.String())
+		}
+	}
+
+	if len(ss) > 0 {
+		return V10, errors.Errorf("pdfcpu: PDF %s can't express: %s", v, strings.Join(ss, ", "))
+	}
+
+	return v, nil
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var errInvalidResizeConfig = errors.New("pdfcpu: invalid configuration string")
+
+type resizeParamMap map[string]func(string, *Resize) error
+
+var rszParamMap = resizeParamMap{
+	"dimensions": parseDimensionsResize,
+	"formsize":   parsePageFormatResize,
+	"papersize":  parsePageFormatResize,
+	"enforce":    parseEnforceOrientResize,
+}
+
+// Handle applies parameter completion and if successful
+// parses the parameter values into res.
+func (m resizeParamMap) Handle(paramPrefix, paramValueStr string, res *Resize) error {
+	var param string
+
+	// Completion support
+	for k := range m {
+		if !strings.HasPrefix(k, paramPrefix) {
+			continue
+		}
+		if len(param) > 0 {
+			return errors.Errorf("pdfcpu: ambiguous parameter prefix \"%s\"", paramPrefix)
+		}
+		param = k
+	}
+
+	if param == "" {
+		return errors.Errorf("pdfcpu: ambiguous parameter prefix \"%s\"", paramPrefix)
+	}
+
+	return m[param](paramValueStr, res)
+}
+
+// Resize represents the command details for the command "Resize".
+// The content of each selected page gets scaled to fit onto a page of the target size.
+type Resize struct {
+	PageDim       *Dim        // Target page dimensions in display unit.
+	PageSize      string      // Paper size eg. A4L, A4P, A4(=default=A4P), see paperSize.go
+	UserDim       bool        // true if one of dimensions or paperSize provided overriding the default.
+	EnforceOrient bool        // true: use the target dimensions as is, false: flip them to match the orientation of each page.
+	InpUnit       DisplayUnit // input display unit.
+}
+
+// DefaultResizeConfig returns the default Resize configuration.
+func DefaultResizeConfig() *Resize {
+	return &Resize{
+		PageSize: "A4",
+	}
+}
+
+func (res Resize) String() string {
+	return fmt.Sprintf("Resize conf: %s %s, enforce=%t\n", res.PageSize, *res.PageDim, res.EnforceOrient)
+}
+
+func parsePageFormatResize(s string, res *Resize) (err error) {
+	if res.UserDim {
+		return errors.New("pdfcpu: only one of formsize(papersize) or dimensions allowed")
+	}
+	res.PageDim, res.PageSize, err = parsePageFormat(s)
+	res.UserDim = true
+	return err
+}
+
+func parseDimensionsResize(s string, res *Resize) (err error) {
+	if res.UserDim {
+		return errors.New("pdfcpu: only one of formsize(papersize) or dimensions allowed")
+	}
+	res.PageDim, res.PageSize, err = parsePageDim(s, res.InpUnit)
+	res.UserDim = true
+	return err
+}
+
+func parseEnforceOrientResize(s string, res *Resize) error {
+	switch strings.ToLower(s) {
+	case "on", "true", "t":
+		res.EnforceOrient = true
+	case "off", "false", "f":
+		res.EnforceOrient = false
+	default:
+		return errors.New("pdfcpu: resize enforce, please provide one of: on/off true/false t/f")
+	}
+
+	return nil
+}
+
+// ParseResizeDetails parses a Resize command string into an internal structure.
+func ParseResizeDetails(s string, res *Resize) error {
+	if s == "" {
+		return errInvalidResizeConfig
+	}
+
+	ss := strings.Split(s, ",")
+
+	for _, s := range ss {
+
+		ss1 := strings.Split(s, ":")
+		if len(ss1) != 2 {
+			return errInvalidResizeConfig
+		}
+
+		paramPrefix := strings.TrimSpace(ss1[0])
+		paramValueStr := strings.TrimSpace(ss1[1])
+
+		if err := rszParamMap.Handle(paramPrefix, paramValueStr, res); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ResizeConfig returns a Resize configuration for desc.
+func ResizeConfig(desc string) (*Resize, error) {
+	res := DefaultResizeConfig()
+	if desc != "" {
+		if err := ParseResizeDetails(desc, res); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// resizePage replaces the content of page pageNr by its best fit into a page of the target size.
+func (ctx *Context) resizePage(pageNr int, res *Resize) error {
+
+	fx, err := ctx.ExtractFormXObject(pageNr, nil)
+	if err != nil {
+		return err
+	}
+
+	d, _, _, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return err
+	}
+
+	w, h := res.PageDim.Width, res.PageDim.Height
+	if !res.EnforceOrient && (w < h) != (fx.Width() < fx.Height()) && fx.Width() != fx.Height() {
+		w, h = h, w
+	}
+
+	formIndRef, err := ctx.formIndRef(fx)
+	if err != nil {
+		return err
+	}
+
+	// Scale and center the page content preserving its aspect ratio.
+	s := math.Min(w/fx.Width(), h/fx.Height())
+	t := NewTransform(s, s, 0, (w-s*fx.Width())/2, (h-s*fx.Height())/2)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "q %.2f %.2f %.2f %.2f %.2f %.2f cm /Fx0 Do Q ", t[0], t[1], t[2], t[3], t[4], t[5])
+
+	contentsIndRef, err := ctx.contentStreamIndRef(b.Bytes())
+	if err != nil {
+		return err
+	}
+
+	// The page boxes, rotation and annotations refer to the original page geometry.
+	for _, k := range []string{"CropBox", "BleedBox", "TrimBox", "ArtBox", "Annots"} {
+		d.Delete(k)
+	}
+
+	d.Update("MediaBox", RectForDim(w, h).Array())
+	d.Update("Rotate", Integer(0))
+	d.Update("Resources", Dict(map[string]Object{"XObject": Dict(map[string]Object{"Fx0": *formIndRef})}))
+	d.Update("Contents", *contentsIndRef)
+
+	return nil
+}
+
+// Resize scales the content of all selected pages of ctx onto pages of the size described by res.
+// Each page is wrapped into a Form XObject and placed centered on the resized page preserving its aspect ratio.
+// Page annotations of resized pages get dropped.
+func (ctx *Context) Resize(selectedPages IntSet, res *Resize) error {
+
+	if res == nil {
+		return errors.New("pdfcpu: missing resize configuration")
+	}
+
+	if res.PageDim == nil {
+		d, _, err := parsePageFormat(res.PageSize)
+		if err != nil {
+			return err
+		}
+		res.PageDim = d
+	}
+
+	for _, pageNr := range sortSelectedPages(selectedPages) {
+		if err := ctx.resizePage(pageNr, res); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pkg/errors"
+)
+
+// ImageMap maps image resource ids to the ids of images registered with a ResourceManager.
+type ImageMap map[string]string
+
+// EnsureKey registers the image id with corresponding image resource id.
+func (im ImageMap) EnsureKey(id string) string {
+	for k, v := range im {
+		if v == id {
+			return k
+		}
+	}
+	key := "Im" + strconv.Itoa(len(im))
+	im[key] = id
+	return key
+}
+
+type imageResource struct {
+	indRef IndirectRef
+	w, h   int
+}
+
+// ResourceManager hands out font and image resources for generated pages.
+//
+// Each font and image gets embedded once no matter how many pages are referring to it.
+// User fonts are subsetted to the glyphs used by all pages, hence their font dicts are created by Finalize
+// which needs to be called once all pages have been rendered.
+type ResourceManager struct {
+	xRefTable *XRefTable
+	fonts     map[string]IndirectRef   // Font dicts by font name.
+	images    map[string]imageResource // Image XObjects by image id.
+	pending   map[int]string           // Font names of user fonts waiting for Finalize by object number.
+}
+
+// NewResourceManager returns a ResourceManager creating resources in xRefTable.
+func NewResourceManager(xRefTable *XRefTable) *ResourceManager {
+	return &ResourceManager{
+		xRefTable: xRefTable,
+		fonts:     map[string]IndirectRef{},
+		images:    map[string]imageResource{},
+		pending:   map[int]string{},
+	}
+}
+
+// Font returns an indirect reference to the font dict shared by all pages using fontName.
+func (rm *ResourceManager) Font(fontName string) (*IndirectRef, error) {
+	if ir, ok := rm.fonts[fontName]; ok {
+		return &ir, nil
+	}
+
+	var (
+		ir  *IndirectRef
+		err error
+	)
+
+	if font.IsCoreFont(fontName) {
+		ir, err = coreFontDict(rm.xRefTable, fontName)
+	} else {
+		if _, ok := font.UserFontMetrics[fontName]; !ok {
+			return nil, errors.Errorf("pdfcpu: font %s not available", fontName)
+		}
+		// Reserve an object for the font dict to be created by Finalize.
+		if ir, err = rm.xRefTable.IndRefForNewObject(nil); err == nil {
+			rm.pending[ir.ObjectNumber.Value()] = fontName
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rm.xRefTable.Log.Write().Printf("ResourceManager: font %s is obj#%d\n", fontName, ir.ObjectNumber)
+
+	rm.fonts[fontName] = *ir
+	return ir, nil
+}
+
+// Image returns an indirect reference to the image XObject shared by all pages using the image id
+// along with the image dimensions.
+// The first call for id reads the image from r, subsequent calls ignore r.
+func (rm *ResourceManager) Image(id string, r io.Reader) (*IndirectRef, int, int, error) {
+	if img, ok := rm.images[id]; ok {
+		return &img.indRef, img.w, img.h, nil
+	}
+
+	if r == nil {
+		return nil, 0, 0, errors.Errorf("pdfcpu: image %s not available", id)
+	}
+
+	ir, w, h, err := createImageResource(rm.xRefTable, r, false, false)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	rm.xRefTable.Log.Write().Printf("ResourceManager: image %s is obj#%d\n", id, ir.ObjectNumber)
+
+	rm.images[id] = imageResource{indRef: *ir, w: w, h: h}
+	return ir, w, h, nil
+}
+
+// PageResources returns the resource dict for p.
+// Images referenced by p need to be registered using Image beforehand.
+func (rm *ResourceManager) PageResources(p Page) (Dict, error) {
+	d := Dict{}
+
+	if len(p.Fm) > 0 {
+		fontRes := Dict{}
+		for k, fontName := range p.Fm {
+			ir, err := rm.Font(fontName)
+			if err != nil {
+				return nil, err
+			}
+			fontRes.Insert(k, *ir)
+		}
+		d.Insert("Font", fontRes)
+	}
+
+	if len(p.Im) > 0 {
+		imgRes := Dict{}
+		for k, id := range p.Im {
+			ir, _, _, err := rm.Image(id, nil)
+			if err != nil {
+				return nil, err
+			}
+			imgRes.Insert(k, *ir)
+		}
+		d.Insert("XObject", imgRes)
+	}
+
+	return d, nil
+}
+
+func (rm *ResourceManager) isPending(objNr int) bool {
+	_, ok := rm.pending[objNr]
+	return ok
+}
+
+// Finalize creates the font dicts of all user fonts in use.
+func (rm *ResourceManager) Finalize() error {
+	for objNr, fontName := range rm.pending {
+		d, err := newType0FontDict(rm.xRefTable, fontName)
+		if err != nil {
+			return err
+		}
+		entry, ok := rm.xRefTable.FindTableEntryLight(objNr)
+		if !ok {
+			return errors.Errorf("pdfcpu: ResourceManager: missing obj#%d", objNr)
+		}
+		entry.Object = d
+		delete(rm.pending, objNr)
+	}
+	return nil
+}
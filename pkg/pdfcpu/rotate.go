@@ -16,7 +16,12 @@ limitations under the License.
 
 package pdfcpu
 
-import "github.com/pdfcpu/pdfcpu/pkg/log"
+import (
+	"fmt"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pkg/errors"
+)
 
 func rotatePage(xRefTable *XRefTable, i, j int) error {
 
@@ -47,3 +52,215 @@ func RotatePages(ctx *Context, selectedPages IntSet, rotation int) error {
 
 	return nil
 }
+
+// contentRotation returns the transformation rotating box clockwise by rot degrees
+// with the rotated box ending up with its lower left corner at the origin.
+func contentRotation(rot int, box *Rectangle) matrix {
+
+	m := identMatrix
+
+	switch rot {
+	case 90:
+		m[0][0], m[0][1], m[1][0], m[1][1] = 0, -1, 1, 0
+		m[2][0], m[2][1] = -box.LL.Y, box.UR.X
+	case 180:
+		m[0][0], m[1][1] = -1, -1
+		m[2][0], m[2][1] = box.UR.X, box.UR.Y
+	case 270:
+		m[0][0], m[0][1], m[1][0], m[1][1] = 0, 1, -1, 0
+		m[2][0], m[2][1] = box.UR.Y, -box.LL.X
+	}
+
+	return m
+}
+
+func (ctx *Context) rotateAppearance(o Object, m matrix, visited IntSet) error {
+
+	if ir, ok := o.(IndirectRef); ok {
+		objNr := ir.ObjectNumber.Value()
+		if visited[objNr] {
+			return nil
+		}
+		visited[objNr] = true
+	}
+
+	o, err := ctx.Dereference(o)
+	if err != nil || o == nil {
+		return err
+	}
+
+	switch o1 := o.(type) {
+
+	case StreamDict:
+		// The appearance gets mapped onto the transformed annotation rectangle, apply the rotation only.
+		mx := identMatrix
+		if a, err := ctx.DereferenceArray(o1.Dict["Matrix"]); err == nil && len(a) == 6 {
+			for i, o := range a {
+				f, err := ctx.DereferenceNumber(o)
+				if err != nil {
+					return err
+				}
+				mx[i/2][i%2] = f
+			}
+		}
+		r := m
+		r[2][0], r[2][1] = 0, 0
+		mx = mx.multiply(r)
+		o1.Dict.Update("Matrix", NewNumberArray(mx[0][0], mx[0][1], mx[1][0], mx[1][1], mx[2][0], mx[2][1]))
+
+	case Dict:
+		// Appearance subdictionary keyed by appearance state.
+		for _, v := range o1 {
+			if err := ctx.rotateAppearance(v, m, visited); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ctx *Context) rotateAnnotations(d Dict, m matrix, visited IntSet) error {
+
+	a, err := ctx.DereferenceArray(d["Annots"])
+	if err != nil || a == nil {
+		return err
+	}
+
+	for _, o := range a {
+
+		if ir, ok := o.(IndirectRef); ok {
+			objNr := ir.ObjectNumber.Value()
+			if visited[objNr] {
+				continue
+			}
+			visited[objNr] = true
+		}
+
+		annot, err := ctx.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		if annot == nil {
+			continue
+		}
+
+		if arr, err := ctx.DereferenceArray(annot["Rect"]); err == nil && len(arr) == 4 {
+			r, err := RectForArray(arr)
+			if err != nil {
+				return err
+			}
+			annot.Update("Rect", transformRect(m, r).Array())
+		}
+
+		if arr, err := ctx.DereferenceArray(annot["QuadPoints"]); err == nil && len(arr)%8 == 0 {
+			qp := make([]float64, len(arr))
+			for i := 0; i < len(arr); i += 2 {
+				x, err := ctx.DereferenceNumber(arr[i])
+				if err != nil {
+					return err
+				}
+				y, err := ctx.DereferenceNumber(arr[i+1])
+				if err != nil {
+					return err
+				}
+				p := m.transform(Point{x, y})
+				qp[i], qp[i+1] = p.X, p.Y
+			}
+			annot.Update("QuadPoints", NewNumberArray(qp...))
+		}
+
+		ap, err := ctx.DereferenceDict(annot["AP"])
+		if err != nil {
+			return err
+		}
+		for _, k := range []string{"N", "R", "D"} {
+			if o, found := ap.Find(k); found {
+				if err := ctx.rotateAppearance(o, m, visited); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ctx *Context) rotatePageContent(pageNr, rot int, visited IntSet) error {
+
+	log.Debug.Printf("rotate content of page:%d\n", pageNr)
+
+	consolidateRes := false
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, consolidateRes)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.Errorf("pdfcpu: unknown page number: %d\n", pageNr)
+	}
+
+	mediaBox := inhPAttrs.mediaBox
+	m := contentRotation(rot, mediaBox)
+
+	// Rewrite the CTM of the page content.
+	if _, found := d.Find("Contents"); found {
+		prefix := fmt.Sprintf("q %.2f %.2f %.2f %.2f %.2f %.2f cm ", m[0][0], m[0][1], m[1][0], m[1][1], m[2][0], m[2][1])
+		if err := ctx.wrapPageContent(d, []byte(prefix), []byte(" Q")); err != nil {
+			return err
+		}
+	}
+
+	// Transform all page boxes.
+	d.Update("MediaBox", transformRect(m, mediaBox).Array())
+
+	if inhPAttrs.cropBox != nil {
+		d.Update("CropBox", transformRect(m, inhPAttrs.cropBox).Array())
+	}
+
+	for _, k := range []string{"BleedBox", "TrimBox", "ArtBox"} {
+		a, err := ctx.DereferenceArray(d[k])
+		if err != nil {
+			return err
+		}
+		if len(a) != 4 {
+			continue
+		}
+		r, err := RectForArray(a)
+		if err != nil {
+			return err
+		}
+		d.Update(k, transformRect(m, r).Array())
+	}
+
+	return ctx.rotateAnnotations(d, m, visited)
+}
+
+// RotatePageContents rotates the content of all selected pages clockwise by a multiple of 90 degrees.
+// Unlike RotatePages which only sets the page attribute /Rotate, the page content, page boxes and annotations are
+// transformed, so the rotation also gets honored by processors ignoring /Rotate.
+// Any existing /Rotate is left untouched.
+func RotatePageContents(ctx *Context, selectedPages IntSet, rotation int) error {
+
+	rot := rotation % 360
+	if rot < 0 {
+		rot += 360
+	}
+	if rot%90 != 0 {
+		return errors.Errorf("pdfcpu: rotation must be a multiple of 90: %d\n", rotation)
+	}
+	if rot == 0 {
+		return nil
+	}
+
+	visited := IntSet{}
+
+	for k, v := range selectedPages {
+		if v {
+			if err := ctx.rotatePageContent(k, rot, visited); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pkg/errors"
+	"golang.org/x/text/unicode/bidi"
+	"golang.org/x/text/unicode/norm"
+)
+
+// The security handler for AES-256 processes passwords using the SASLprep profile (RFC 4013)
+// of stringprep (RFC 3454), see 7.6.4.3.3 Algorithm 2.A.
+
+type runeRange struct{ lo, hi rune }
+
+func inRanges(r rune, rr []runeRange) bool {
+	for _, rng := range rr {
+		if r >= rng.lo && r <= rng.hi {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// RFC 3454 B.1: Commonly mapped to nothing
+	mappedToNothing = []runeRange{
+		{0x00AD, 0x00AD}, {0x034F, 0x034F}, {0x1806, 0x1806}, {0x180B, 0x180D},
+		{0x200B, 0x200D}, {0x2060, 0x2060}, {0xFE00, 0xFE0F}, {0xFEFF, 0xFEFF},
+	}
+
+	// RFC 3454 C.1.2: Non-ASCII space characters
+	nonASCIISpace = []runeRange{
+		{0x00A0, 0x00A0}, {0x1680, 0x1680}, {0x2000, 0x200B}, {0x202F, 0x202F},
+		{0x205F, 0x205F}, {0x3000, 0x3000},
+	}
+
+	// RFC 4013 2.3: Prohibited output
+	prohibited = []runeRange{
+		// C.2.1 ASCII control characters
+		{0x0000, 0x001F}, {0x007F, 0x007F},
+		// C.2.2 Non-ASCII control characters
+		{0x0080, 0x009F}, {0x06DD, 0x06DD}, {0x070F, 0x070F}, {0x180E, 0x180E},
+		{0x200C, 0x200D}, {0x2028, 0x2029}, {0x2060, 0x2063}, {0x206A, 0x206F},
+		{0xFEFF, 0xFEFF}, {0xFFF9, 0xFFFC}, {0x1D173, 0x1D17A},
+		// C.3 Private use
+		{0xE000, 0xF8FF}, {0xF0000, 0xFFFFD}, {0x100000, 0x10FFFD},
+		// C.4 Non-character code points
+		{0xFDD0, 0xFDEF},
+		// C.5 Surrogate codes
+		{0xD800, 0xDFFF},
+		// C.6 Inappropriate for plain text
+		{0xFFF9, 0xFFFD},
+		// C.7 Inappropriate for canonical representation
+		{0x2FF0, 0x2FFB},
+		// C.8 Change display properties or are deprecated
+		{0x0340, 0x0341}, {0x200E, 0x200F}, {0x202A, 0x202E},
+		// C.9 Tagging characters
+		{0xE0001, 0xE0001}, {0xE0020, 0xE007F},
+	}
+)
+
+func prohibitedRune(r rune) bool {
+	// C.4: U+FFFE, U+FFFF of every plane.
+	if r&0xFFFE == 0xFFFE {
+		return true
+	}
+	return inRanges(r, nonASCIISpace) || inRanges(r, prohibited)
+}
+
+func randALCat(r rune) bool {
+	p, _ := bidi.LookupRune(r)
+	c := p.Class()
+	return c == bidi.R || c == bidi.AL
+}
+
+func lCat(r rune) bool {
+	p, _ := bidi.LookupRune(r)
+	return p.Class() == bidi.L
+}
+
+// checkBidi applies the bidirectional rules of RFC 3454 6.
+func checkBidi(rr []rune) error {
+	var hasRandAL, hasL bool
+	for _, r := range rr {
+		if randALCat(r) {
+			hasRandAL = true
+		}
+		if lCat(r) {
+			hasL = true
+		}
+	}
+	if !hasRandAL {
+		return nil
+	}
+	if hasL {
+		return errors.New("pdfcpu: saslprep: mixed bidirectional text")
+	}
+	if !randALCat(rr[0]) || !randALCat(rr[len(rr)-1]) {
+		return errors.New("pdfcpu: saslprep: bidirectional text must start and end with a right-to-left character")
+	}
+	return nil
+}
+
+// saslPrep prepares s according to the SASLprep profile of stringprep.
+// Unassigned code points are allowed as for queries.
+func saslPrep(s string) (string, error) {
+
+	// 1. Map
+	var sb strings.Builder
+	for _, r := range s {
+		if inRanges(r, mappedToNothing) {
+			continue
+		}
+		if inRanges(r, nonASCIISpace) {
+			r = ' '
+		}
+		sb.WriteRune(r)
+	}
+
+	// 2. Normalize
+	s = norm.NFKC.String(sb.String())
+
+	// 3. Prohibit
+	rr := []rune(s)
+	for _, r := range rr {
+		if prohibitedRune(r) {
+			return "", errors.Errorf("pdfcpu: saslprep: prohibited character U+%04X", r)
+		}
+	}
+
+	// 4. Check bidi
+	if err := checkBidi(rr); err != nil {
+		return "", err
+	}
+
+	return s, nil
+}
+
+// aes256Password returns pw prepared for the AES-256 security handler:
+// SASLprep processed, UTF-8 encoded and truncated to 127 bytes.
+func aes256Password(pw string) []byte {
+	s, err := saslPrep(pw)
+	if err != nil {
+		// Fall back to the raw password for compatibility with writers skipping SASLprep.
+		log.Info.Printf("%v, using password as is\n", err)
+		s = pw
+	}
+
+	bb := []byte(s)
+	if len(bb) > 127 {
+		bb = bb[:127]
+	}
+
+	return bb
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+)
+
+const testShapingFont = "TestShaping"
+
+// installShapingFont registers a user font mapping each rune in rr to a glyph with gid = rune & 0xFFFF.
+func installShapingFont(t *testing.T, rr ...rune) {
+	t.Helper()
+	ttf := font.TTFLight{
+		GlyphWidths: make([]int, 0x10000),
+		Chars:       map[uint32]uint16{},
+		UsedGIDs:    map[uint16]bool{},
+	}
+	for _, r := range rr {
+		ttf.Chars[uint32(r)] = uint16(r)
+		ttf.GlyphWidths[uint16(r)] = 500
+	}
+	font.UserFontMetrics[testShapingFont] = ttf
+	t.Cleanup(func() { delete(font.UserFontMetrics, testShapingFont) })
+}
+
+func shapedRunes(s string) []rune {
+	rr := []rune{}
+	for _, g := range font.Shape(testShapingFont, s) {
+		rr = append(rr, rune(g.GID))
+	}
+	return rr
+}
+
+func TestShapeArabic(t *testing.T) {
+	installShapingFont(t,
+		0x0628, 0xFE8F, 0xFE90, 0xFE91, 0xFE92, // beh
+		0x0644, 0xFEDD, 0xFEDE, 0xFEDF, 0xFEE0, // lam
+		0x0627, 0xFE8D, 0xFE8E, // alef
+		0x062F, 0xFEA9, 0xFEAA, // dal
+		0xFEFB, 0xFEFC, // lam alef
+		0x064E, // fatha
+	)
+
+	for _, tt := range []struct {
+		in   string
+		want []rune
+	}{
+		{"ب", []rune{0xFE8F}},
+		{"ببب", []rune{0xFE91, 0xFE92, 0xFE90}},
+		{"بَب", []rune{0xFE91, 0x064E, 0xFE90}},
+		{"دب", []rune{0xFEA9, 0xFE8F}},
+		{"بد", []rune{0xFE91, 0xFEAA}},
+		{"لا", []rune{0xFEFB}},
+		{"بلا", []rune{0xFE91, 0xFEFC}},
+		{"aب", []rune{0xFE8F}},
+	} {
+		if got := shapedRunes(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: want %X, got %X\n", tt.in, tt.want, got)
+		}
+	}
+
+	// Fall back to nominal glyphs if the font lacks presentation forms.
+	installShapingFont(t, 0x0628)
+	if got, want := shapedRunes("بب"), []rune{0x0628, 0x0628}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %X, got %X\n", want, got)
+	}
+}
+
+func TestShapeDevanagari(t *testing.T) {
+	installShapingFont(t, 0x0915, 0x0937, 0x093F, 0x094D, 0x0928)
+
+	// कि: pre-base vowel sign i precedes its consonant.
+	if got, want := shapedRunes("कि"), []rune{0x093F, 0x0915}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %X, got %X\n", want, got)
+	}
+
+	// क्षि: the vowel sign precedes the whole consonant cluster.
+	if got, want := shapedRunes("नक्षि"), []rune{0x0928, 0x093F, 0x0915, 0x094D, 0x0937}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %X, got %X\n", want, got)
+	}
+}
+
+type testShaper struct{}
+
+func (testShaper) Shape(fontName, text string) ([]font.Glyph, error) {
+	gg := []font.Glyph{}
+	for i, r := range text {
+		g := font.Glyph{GID: uint16(r), Cluster: i, XAdvance: 500}
+		if r == 'b' {
+			// Mark attached to the preceding glyph.
+			g = font.Glyph{GID: uint16(r), Cluster: i - 1, XOffset: -250, YOffset: 100}
+		}
+		gg = append(gg, g)
+	}
+	return gg, nil
+}
+
+func TestTextShow(t *testing.T) {
+	installShapingFont(t, 'a', 'b', 'c')
+
+	ts := textShow{}
+	ts.addText("ac", testShapingFont, false)
+	if got, want := ts.String(10), "(\x00a\x00c) Tj"; got != want {
+		t.Errorf("want %q, got %q\n", want, got)
+	}
+
+	font.SetShaper(testShaper{})
+	defer font.SetShaper(nil)
+
+	// Right to left text keeps marks after their base.
+	ts = textShow{}
+	ts.addText("abc", testShapingFont, true)
+	want := "[ (\x00c\x00a) ] TJ 1.00 Ts [ 250 (\x00b) 250 ] TJ 0 Ts"
+	if got := ts.String(10); got != want {
+		t.Errorf("want %q, got %q\n", want, got)
+	}
+
+	if w := font.TextWidth("abc", testShapingFont, 10); w != 10 {
+		t.Errorf("want width 10, got %.2f\n", w)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import "github.com/pkg/errors"
+
+// ErrSigned signals a command refused in order to preserve the digital signatures of a document.
+var ErrSigned = errors.New("pdfcpu: modification would invalidate digital signatures")
+
+// ReadOnlyCmd returns true if cmd never writes a modified document nor anything besides its output file or directory.
+// Commands lacking an entry in perm are treated as modifying.
+func ReadOnlyCmd(cmd CommandMode) bool {
+	p, ok := perm[cmd]
+	return ok && p.readOnly
+}
+
+// signaturePreservingCmds make changes permitted after signing if written as incremental update, see 12.8.2.2.
+var signaturePreservingCmds = map[CommandMode]bool{
+	FILLFORMFIELDS:    true,
+	ADDANNOTATIONS:    true,
+	REMOVEANNOTATIONS: true,
+}
+
+// CheckSignatures applies the configured signature policy to the command in progress
+// if it modifies a document holding digital signatures.
+// Incremental updates leave the signed bytes untouched and always pass.
+func CheckSignatures(ctx *Context) error {
+	if ReadOnlyCmd(ctx.Cmd) || ctx.Incremental {
+		return nil
+	}
+
+	ff, err := ctx.SignatureFields()
+	if err != nil {
+		if ctx.SignaturePolicy == SignaturePolicyWarn {
+			// Don't let a corrupt form get in the way of unrelated commands.
+			ctx.Log.Info().Printf("CheckSignatures: %v\n", err)
+			return nil
+		}
+		return err
+	}
+	if len(ff) == 0 {
+		return nil
+	}
+
+	switch ctx.SignaturePolicy {
+
+	case SignaturePolicyRefuse:
+		return ErrSigned
+
+	case SignaturePolicyIncremental:
+		if !signaturePreservingCmds[ctx.Cmd] {
+			return ErrSigned
+		}
+		ctx.Log.Info().Printf("CheckSignatures: %d signature(s) found, switching to incremental update\n", len(ff))
+		ctx.Incremental = true
+		return nil
+	}
+
+	ctx.Warn("modification invalidates %d digital signature(s)", len(ff))
+	return nil
+}
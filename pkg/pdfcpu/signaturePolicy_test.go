@@ -0,0 +1,49 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import "testing"
+
+func TestPermCoversAllCommands(t *testing.T) {
+	for cmd := VALIDATE; cmd <= EXTRACTSVG; cmd++ {
+		if _, ok := perm[cmd]; !ok {
+			t.Errorf("no perm entry for command mode %d", cmd)
+		}
+	}
+}
+
+func TestReadOnlyCmd(t *testing.T) {
+	for _, tt := range []struct {
+		cmd  CommandMode
+		want bool
+	}{
+		{VALIDATE, true},
+		{INFO, true},
+		{LISTANNOTATIONS, true},
+		{EXTRACTSVG, true},
+		{OPTIMIZE, false},
+		{INTERNALIZE, false},
+		{ROTATECONTENT, false},
+		{FILLFORMFIELDS, false},
+		{INSTALLFONTS, false},
+		{CHEATSHEETSFONTS, false},
+	} {
+		if got := ReadOnlyCmd(tt.cmd); got != tt.want {
+			t.Errorf("ReadOnlyCmd(%d) = %t, want %t", tt.cmd, got, tt.want)
+		}
+	}
+}
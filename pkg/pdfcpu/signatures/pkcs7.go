@@ -0,0 +1,310 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signatures
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"time"
+
+	// Register the supported digest algorithms.
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+
+	"github.com/pkg/errors"
+)
+
+// Object identifiers, see RFC 5652, RFC 5754, RFC 4055 and RFC 5758.
+var (
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSigningTime   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+	oidTimeStamp     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 14}
+	oidRSASSAPSS     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}
+
+	digestAlgorithms = map[string]crypto.Hash{
+		"1.3.14.3.2.26":          crypto.SHA1,
+		"2.16.840.1.101.3.4.2.1": crypto.SHA256,
+		"2.16.840.1.101.3.4.2.2": crypto.SHA384,
+		"2.16.840.1.101.3.4.2.3": crypto.SHA512,
+		"2.16.840.1.101.3.4.2.4": crypto.SHA224,
+	}
+)
+
+// contentInfo represents a CMS ContentInfo, see RFC 5652 3.
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+// signedData represents a CMS SignedData, see RFC 5652 5.1.
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo contentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+// signerInfo represents a CMS SignerInfo, see RFC 5652 5.3.
+type signerInfo struct {
+	Version            int
+	SID                asn1.RawValue // IssuerAndSerialNumber or [0] SubjectKeyIdentifier
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type issuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+// pkcs7 is a parsed CMS signature along with its signer.
+type pkcs7 struct {
+	sd      signedData
+	si      signerInfo
+	certs   []*x509.Certificate
+	signer  *x509.Certificate
+	content []byte // Encapsulated content if present.
+}
+
+// parsePKCS7 parses the DER encoded CMS SignedData b.
+// Any trailing bytes eg. the zero padding of /Contents are ignored.
+func parsePKCS7(b []byte) (*pkcs7, error) {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(b, &ci); err != nil {
+		return nil, errors.Wrap(err, "pdfcpu: signature: invalid content info")
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, errors.Errorf("pdfcpu: signature: unsupported content type %s", ci.ContentType)
+	}
+
+	p := &pkcs7{}
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &p.sd); err != nil {
+		return nil, errors.Wrap(err, "pdfcpu: signature: invalid signed data")
+	}
+
+	if len(p.sd.SignerInfos) != 1 {
+		return nil, errors.Errorf("pdfcpu: signature: want 1 signer, got %d", len(p.sd.SignerInfos))
+	}
+	p.si = p.sd.SignerInfos[0]
+
+	if len(p.sd.EncapContentInfo.Content.Bytes) > 0 {
+		if _, err := asn1.Unmarshal(p.sd.EncapContentInfo.Content.Bytes, &p.content); err != nil {
+			return nil, errors.Wrap(err, "pdfcpu: signature: invalid encapsulated content")
+		}
+	}
+
+	if len(p.sd.Certificates.Bytes) > 0 {
+		certs, err := x509.ParseCertificates(p.sd.Certificates.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "pdfcpu: signature: invalid certificates")
+		}
+		p.certs = certs
+	}
+
+	signer, err := p.signerCertificate()
+	if err != nil {
+		return nil, err
+	}
+	p.signer = signer
+
+	return p, nil
+}
+
+// signerCertificate returns the embedded certificate identified by the signer info.
+func (p *pkcs7) signerCertificate() (*x509.Certificate, error) {
+	sid := p.si.SID
+
+	if sid.Class == asn1.ClassContextSpecific && sid.Tag == 0 {
+		for _, c := range p.certs {
+			if bytes.Equal(c.SubjectKeyId, sid.Bytes) {
+				return c, nil
+			}
+		}
+		return nil, errors.New("pdfcpu: signature: missing signer certificate")
+	}
+
+	var ias issuerAndSerialNumber
+	if _, err := asn1.Unmarshal(sid.FullBytes, &ias); err != nil {
+		return nil, errors.Wrap(err, "pdfcpu: signature: invalid signer identifier")
+	}
+
+	for _, c := range p.certs {
+		if c.SerialNumber.Cmp(ias.SerialNumber) == 0 && bytes.Equal(c.RawIssuer, ias.Issuer.FullBytes) {
+			return c, nil
+		}
+	}
+
+	return nil, errors.New("pdfcpu: signature: missing signer certificate")
+}
+
+// digestAlgorithm returns the digest algorithm used by the signer.
+func (p *pkcs7) digestAlgorithm() (crypto.Hash, error) {
+	h, ok := digestAlgorithms[p.si.DigestAlgorithm.Algorithm.String()]
+	if !ok || !h.Available() {
+		return 0, errors.Errorf("pdfcpu: signature: unsupported digest algorithm %s", p.si.DigestAlgorithm.Algorithm)
+	}
+	return h, nil
+}
+
+// signedAttributes returns the DER encoded signed attributes as covered by the signature
+// along with the parsed attributes.
+func (p *pkcs7) signedAttributes() ([]byte, []attribute, error) {
+	if len(p.si.SignedAttrs.FullBytes) == 0 {
+		return nil, nil, nil
+	}
+
+	// The signature covers the explicit SET OF encoding, see RFC 5652 5.4.
+	b := append([]byte{}, p.si.SignedAttrs.FullBytes...)
+	b[0] = 0x31
+
+	var attrs []attribute
+	if _, err := asn1.UnmarshalWithParams(b, &attrs, "set"); err != nil {
+		return nil, nil, errors.Wrap(err, "pdfcpu: signature: invalid signed attributes")
+	}
+
+	return b, attrs, nil
+}
+
+func attributeValue(attrs []attribute, oid asn1.ObjectIdentifier) *asn1.RawValue {
+	for _, a := range attrs {
+		if a.Type.Equal(oid) && len(a.Values) > 0 {
+			return &a.Values[0]
+		}
+	}
+	return nil
+}
+
+// signingTime returns the signing time claimed by the signer if present.
+func (p *pkcs7) signingTime() time.Time {
+	_, attrs, err := p.signedAttributes()
+	if err != nil {
+		return time.Time{}
+	}
+	v := attributeValue(attrs, oidSigningTime)
+	if v == nil {
+		return time.Time{}
+	}
+	var t time.Time
+	if _, err := asn1.Unmarshal(v.FullBytes, &t); err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// hasTimeStampToken returns true if the signer info carries a signature time stamp token.
+func (p *pkcs7) hasTimeStampToken() bool {
+	if len(p.si.UnsignedAttrs.FullBytes) == 0 {
+		return false
+	}
+	b := append([]byte{}, p.si.UnsignedAttrs.FullBytes...)
+	b[0] = 0x31
+	var attrs []attribute
+	if _, err := asn1.UnmarshalWithParams(b, &attrs, "set"); err != nil {
+		return false
+	}
+	return attributeValue(attrs, oidTimeStamp) != nil
+}
+
+// verify checks that digest is the digest of the signed content
+// and that the signature matches the signer certificate.
+func (p *pkcs7) verify(h crypto.Hash, digest []byte) (digestOK bool, err error) {
+	b, attrs, err := p.signedAttributes()
+	if err != nil {
+		return false, err
+	}
+
+	if b == nil {
+		// The signature covers the content digest.
+		err = verifySignature(p.signer, p.si.SignatureAlgorithm, h, digest, p.si.Signature)
+		return err == nil, err
+	}
+
+	v := attributeValue(attrs, oidMessageDigest)
+	if v == nil || attributeValue(attrs, oidContentType) == nil {
+		return false, errors.New("pdfcpu: signature: missing mandatory signed attributes")
+	}
+
+	var md []byte
+	if _, err := asn1.Unmarshal(v.FullBytes, &md); err != nil {
+		return false, errors.Wrap(err, "pdfcpu: signature: invalid message digest")
+	}
+	digestOK = bytes.Equal(md, digest)
+
+	hw := h.New()
+	hw.Write(b)
+
+	return digestOK, verifySignature(p.signer, p.si.SignatureAlgorithm, h, hw.Sum(nil), p.si.Signature)
+}
+
+// verifySignature checks sig of digest against the public key of cert.
+func verifySignature(cert *x509.Certificate, alg pkix.AlgorithmIdentifier, h crypto.Hash, digest, sig []byte) error {
+	switch pub := cert.PublicKey.(type) {
+
+	case *rsa.PublicKey:
+		if alg.Algorithm.Equal(oidRSASSAPSS) {
+			return rsa.VerifyPSS(pub, h, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+		}
+		return rsa.VerifyPKCS1v15(pub, h, digest, sig)
+
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest, sig) {
+			return errors.New("pdfcpu: signature: ECDSA verification failure")
+		}
+		return nil
+	}
+
+	return errors.Errorf("pdfcpu: signature: unsupported public key algorithm %s", cert.PublicKeyAlgorithm)
+}
+
+// verifyChain checks that the signer certificate chains up to one of roots at time t.
+// The embedded certificates serve as intermediates.
+func (p *pkcs7) verifyChain(roots *x509.CertPool, t time.Time) error {
+	intermediates := x509.NewCertPool()
+	for _, c := range p.certs {
+		if c != p.signer {
+			intermediates.AddCert(c)
+		}
+	}
+
+	_, err := p.signer.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   t,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+
+	return err
+}
@@ -0,0 +1,463 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package signatures implements the validation of digital signatures, see 12.8.
+package signatures
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// Result represents the outcome of validating a single signature.
+type Result struct {
+	Field                string    `json:"field"`                    // Fully qualified name of the signature field.
+	SubFilter            string    `json:"subFilter"`                // Signature encoding eg. adbe.pkcs7.detached
+	Signer               string    `json:"signer,omitempty"`         // Subject of the signer certificate.
+	Name                 string    `json:"name,omitempty"`           // Name of the signer as recorded in the signature dict.
+	Reason               string    `json:"reason,omitempty"`         // Reason for signing.
+	Location             string    `json:"location,omitempty"`       // Location of signing.
+	SigningTime          time.Time `json:"signingTime,omitempty"`    // Time of signing as claimed by the signer.
+	TimeStampToken       bool      `json:"timeStampToken,omitempty"` // The signature carries a time stamp token, which is not verified.
+	DigestValid          bool      `json:"digestValid"`              // The signed digest matches the bytes covered by the signature.
+	SignatureValid       bool      `json:"signatureValid"`           // The signature matches the signer certificate.
+	Trusted              bool      `json:"trusted"`                  // The signer certificate chains up to a trusted root at validation time.
+	SignedBytes          int64     `json:"signedBytes"`              // Size of the signed revision.
+	ModifiedAfterSigning bool      `json:"modifiedAfterSigning"`     // The document has been updated incrementally after signing.
+	AddedObjects         []int     `json:"addedObjects,omitempty"`   // Objects added after signing.
+	ChangedObjects       []int     `json:"changedObjects,omitempty"` // Objects changed after signing.
+	Problems             []string  `json:"problems,omitempty"`       // Reasons for failed checks.
+}
+
+// Valid returns true if the signature is intact and trusted.
+func (r Result) Valid() bool {
+	return r.DigestValid && r.SignatureValid && r.Trusted
+}
+
+func (r *Result) problem(err error) {
+	r.Problems = append(r.Problems, err.Error())
+}
+
+// List returns r in human readable form.
+func (r Result) List() []string {
+	status := "valid"
+	switch {
+	case !r.DigestValid || !r.SignatureValid:
+		status = "invalid"
+	case !r.Trusted:
+		status = "valid but untrusted"
+	}
+
+	ss := []string{fmt.Sprintf("%s: %s (%s)", r.Field, status, r.SubFilter)}
+
+	for _, kv := range [][2]string{
+		{"signer", r.Signer},
+		{"name", r.Name},
+		{"reason", r.Reason},
+		{"location", r.Location},
+	} {
+		if kv[1] != "" {
+			ss = append(ss, fmt.Sprintf("   %-9s %s", kv[0]+":", kv[1]))
+		}
+	}
+
+	if !r.SigningTime.IsZero() {
+		s := r.SigningTime.Format(time.RFC3339)
+		if r.TimeStampToken {
+			s += " (time stamp token present, unverified)"
+		}
+		ss = append(ss, fmt.Sprintf("   %-9s %s", "signed:", s))
+	}
+
+	if r.ModifiedAfterSigning {
+		ss = append(ss, fmt.Sprintf("   modified after signing: %d objects added, %d objects changed", len(r.AddedObjects), len(r.ChangedObjects)))
+	} else {
+		ss = append(ss, "   covers the whole document")
+	}
+
+	for _, s := range r.Problems {
+		ss = append(ss, "   problem: "+s)
+	}
+
+	return ss
+}
+
+// LoadTrustStore returns a pool of the PEM or DER encoded certificates in the file or directory path.
+func LoadTrustStore(path string) (*x509.CertPool, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if fi.IsDir() {
+		fis, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		files = nil
+		for _, fi := range fis {
+			if !fi.IsDir() {
+				files = append(files, filepath.Join(path, fi.Name()))
+			}
+		}
+	}
+
+	pool := x509.NewCertPool()
+	n := 0
+
+	for _, fn := range files {
+		bb, err := ioutil.ReadFile(fn)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Contains(bb, []byte("-----BEGIN")) {
+			c, err := x509.ParseCertificate(bb)
+			if err != nil {
+				continue
+			}
+			pool.AddCert(c)
+			n++
+			continue
+		}
+		for {
+			var b *pem.Block
+			if b, bb = pem.Decode(bb); b == nil {
+				break
+			}
+			if b.Type != "CERTIFICATE" {
+				continue
+			}
+			c, err := x509.ParseCertificate(b.Bytes)
+			if err != nil {
+				return nil, errors.Wrapf(err, "pdfcpu: trust store %s", fn)
+			}
+			pool.AddCert(c)
+			n++
+		}
+	}
+
+	if n == 0 {
+		return nil, errors.Errorf("pdfcpu: no certificates found in trust store %s", path)
+	}
+
+	return pool, nil
+}
+
+// byteRange returns the /ByteRange of the signature dict d after checking it is well formed.
+// The gap between both ranges has to hold the /Contents hex string.
+func byteRange(ctx *pdf.Context, d pdf.Dict, fileSize int64) ([4]int64, error) {
+	var br [4]int64
+
+	a, err := ctx.DereferenceArray(d["ByteRange"])
+	if err != nil {
+		return br, err
+	}
+	if len(a) != 4 {
+		return br, errors.New("pdfcpu: signature: invalid /ByteRange")
+	}
+
+	for i, o := range a {
+		n, err := ctx.DereferenceInteger(o)
+		if err != nil || n == nil || *n < 0 {
+			return br, errors.New("pdfcpu: signature: invalid /ByteRange")
+		}
+		br[i] = int64(*n)
+	}
+
+	if br[0] != 0 || br[1] > br[2] || br[2] > fileSize || br[3] > fileSize-br[2] {
+		return br, errors.Errorf("pdfcpu: signature: /ByteRange %v out of bounds", br)
+	}
+
+	return br, nil
+}
+
+// signatureContents returns the decoded hex string found in the gap of the byte range in file.
+func signatureContents(file []byte, br [4]int64) ([]byte, error) {
+	s := strings.TrimSpace(string(file[br[1]:br[2]]))
+	if len(s) < 2 || s[0] != '<' || s[len(s)-1] != '>' {
+		return nil, errors.New("pdfcpu: signature: /ByteRange does not exclude exactly /Contents")
+	}
+	b, err := hex.DecodeString(strings.Join(strings.Fields(s[1:len(s)-1]), ""))
+	if err != nil {
+		return nil, errors.Wrap(err, "pdfcpu: signature: invalid /Contents")
+	}
+	return b, nil
+}
+
+func digestOf(h crypto.Hash, bb ...[]byte) []byte {
+	hw := h.New()
+	for _, b := range bb {
+		hw.Write(b)
+	}
+	return hw.Sum(nil)
+}
+
+// verifyChain checks the signer certificate chain at validation time.
+// The signing time is claimed by the signer and therefore cannot be relied upon.
+func (r *Result) verifyChain(ctx *pdf.Context, p *pkcs7, roots *x509.CertPool) {
+	if err := p.verifyChain(roots, ctx.Now()); err != nil {
+		r.problem(err)
+		return
+	}
+	r.Trusted = true
+}
+
+func (r *Result) verifyPKCS7(ctx *pdf.Context, contents, data1, data2 []byte, roots *x509.CertPool) error {
+	p, err := parsePKCS7(contents)
+	if err != nil {
+		return err
+	}
+
+	r.Signer = p.signer.Subject.String()
+	if t := p.signingTime(); !t.IsZero() {
+		r.SigningTime = t
+	}
+	r.TimeStampToken = p.hasTimeStampToken()
+
+	h, err := p.digestAlgorithm()
+	if err != nil {
+		return err
+	}
+
+	digest := digestOf(h, data1, data2)
+
+	if r.SubFilter == "adbe.pkcs7.sha1" {
+		// The signed content is the SHA-1 digest of the byte ranges.
+		if !bytes.Equal(p.content, digestOf(crypto.SHA1, data1, data2)) {
+			r.problem(errors.New("pdfcpu: signature: document digest mismatch"))
+			return nil
+		}
+		digest = digestOf(h, p.content)
+	}
+
+	digestOK, err := p.verify(h, digest)
+	r.DigestValid = digestOK
+	if !digestOK {
+		r.problem(errors.New("pdfcpu: signature: document digest mismatch"))
+	}
+	if err != nil {
+		r.problem(err)
+	} else {
+		r.SignatureValid = true
+	}
+
+	r.verifyChain(ctx, p, roots)
+
+	return nil
+}
+
+func (r *Result) verifyX509RSASHA1(ctx *pdf.Context, d pdf.Dict, contents, data1, data2 []byte, roots *x509.CertPool) error {
+	var sig []byte
+	if _, err := asn1.Unmarshal(contents, &sig); err != nil {
+		return errors.Wrap(err, "pdfcpu: signature: invalid /Contents")
+	}
+
+	// /Cert is either a single certificate or the chain starting with the signer certificate.
+	o, err := ctx.Dereference(d["Cert"])
+	if err != nil {
+		return err
+	}
+	a, ok := o.(pdf.Array)
+	if !ok {
+		a = pdf.Array{o}
+	}
+
+	var certs []*x509.Certificate
+	for _, o := range a {
+		o, err := ctx.Dereference(o)
+		if err != nil {
+			return err
+		}
+		var b []byte
+		switch o := o.(type) {
+		case pdf.StringLiteral:
+			b, err = pdf.Unescape(o.Value())
+		case pdf.HexLiteral:
+			b, err = o.Bytes()
+		default:
+			err = errors.New("pdfcpu: signature: invalid /Cert")
+		}
+		if err != nil {
+			return err
+		}
+		c, err := x509.ParseCertificate(b)
+		if err != nil {
+			return errors.Wrap(err, "pdfcpu: signature: invalid /Cert")
+		}
+		certs = append(certs, c)
+	}
+	if len(certs) == 0 {
+		return errors.New("pdfcpu: signature: missing /Cert")
+	}
+
+	p := &pkcs7{certs: certs, signer: certs[0]}
+	r.Signer = p.signer.Subject.String()
+
+	err = verifySignature(p.signer, p.si.SignatureAlgorithm, crypto.SHA1, digestOf(crypto.SHA1, data1, data2), sig)
+	if err != nil {
+		r.problem(err)
+	} else {
+		r.DigestValid, r.SignatureValid = true, true
+	}
+
+	r.verifyChain(ctx, p, roots)
+
+	return nil
+}
+
+// validateSignature validates the signature of field f against the file bytes.
+func validateSignature(ctx *pdf.Context, f pdf.SignatureField, file []byte, roots *x509.CertPool) Result {
+	d := f.V
+	r := Result{Field: f.Name}
+
+	if n := d.NameEntry("SubFilter"); n != nil {
+		r.SubFilter = *n
+	}
+
+	for k, v := range map[string]*string{"Name": &r.Name, "Reason": &r.Reason, "Location": &r.Location} {
+		if o, found := d.Find(k); found {
+			*v, _ = ctx.DereferenceText(o)
+		}
+	}
+
+	if o, found := d.Find("M"); found {
+		if s, err := ctx.DereferenceText(o); err == nil {
+			if t, ok := pdf.DateTime(s, true); ok {
+				r.SigningTime = t
+			}
+		}
+	}
+
+	br, err := byteRange(ctx, d, int64(len(file)))
+	if err != nil {
+		r.problem(err)
+		return r
+	}
+
+	r.SignedBytes = br[2] + br[3]
+
+	contents, err := signatureContents(file, br)
+	if err != nil {
+		r.problem(err)
+		return r
+	}
+
+	data1, data2 := file[br[0]:br[0]+br[1]], file[br[2]:br[2]+br[3]]
+
+	switch r.SubFilter {
+	case "adbe.pkcs7.detached", "adbe.pkcs7.sha1", "ETSI.CAdES.detached":
+		err = r.verifyPKCS7(ctx, contents, data1, data2, roots)
+	case "adbe.x509.rsa_sha1":
+		err = r.verifyX509RSASHA1(ctx, d, contents, data1, data2, roots)
+	default:
+		err = errors.Errorf("pdfcpu: signature: unsupported /SubFilter %q", r.SubFilter)
+	}
+
+	if err != nil {
+		r.problem(err)
+	}
+
+	return r
+}
+
+// revision represents the objects added and changed after a signed revision.
+type revision struct {
+	added, changed []int
+}
+
+// modifications records the objects added or changed by incremental updates following the signed revision.
+func modifications(r *Result, file []byte, ctxAll *pdf.Context, conf *pdf.Configuration, revisions map[int64]revision) error {
+	rev, ok := revisions[r.SignedBytes]
+	if !ok {
+		ctx, err := pdf.Read(bytes.NewReader(file[:r.SignedBytes]), conf)
+		if err != nil {
+			return errors.Wrap(err, "pdfcpu: signature: unable to read signed revision")
+		}
+		rev.added, rev.changed = pdf.ModifiedObjects(ctx, ctxAll)
+		revisions[r.SignedBytes] = rev
+	}
+
+	r.AddedObjects, r.ChangedObjects = rev.added, rev.changed
+
+	return nil
+}
+
+// Validate validates all signatures of ctx which has been read from rs.
+// The signer certificates are checked against roots or against the system trust store if roots is nil.
+func Validate(ctx *pdf.Context, rs io.ReadSeeker, roots *x509.CertPool) ([]Result, error) {
+	ff, err := ctx.SignatureFields()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ff) == 0 {
+		return []Result{}, nil
+	}
+
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	file, err := ioutil.ReadAll(rs)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := pdf.NewDefaultConfiguration()
+	if ctx.Configuration != nil {
+		c := *ctx.Configuration
+		conf = &c
+	}
+
+	var ctxAll *pdf.Context
+	revisions := map[int64]revision{}
+
+	rr := make([]Result, len(ff))
+
+	for i, f := range ff {
+		r := validateSignature(ctx, f, file, roots)
+		r.ModifiedAfterSigning = r.SignedBytes > 0 && r.SignedBytes < int64(len(file))
+		if r.ModifiedAfterSigning {
+			if ctxAll == nil {
+				// Compare both revisions read the same way.
+				if ctxAll, err = pdf.Read(bytes.NewReader(file), conf); err != nil {
+					return nil, err
+				}
+			}
+			if err := modifications(&r, file, ctxAll, conf, revisions); err != nil {
+				r.problem(err)
+			}
+		}
+		rr[i] = r
+	}
+
+	return rr, nil
+}
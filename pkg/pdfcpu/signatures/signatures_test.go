@@ -0,0 +1,50 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signatures
+
+import (
+	"math"
+	"testing"
+
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestByteRange(t *testing.T) {
+	ctx, err := pdf.CreateContextWithXRefTable(nil, pdf.PaperSize["A4"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		br [4]int
+		ok bool
+	}{
+		{[4]int{0, 10, 20, 80}, true},
+		{[4]int{0, 10, 20, 81}, false},
+		{[4]int{0, 20, 10, 10}, false},
+		{[4]int{0, 10, 101, 0}, false},
+		{[4]int{0, 10, 20, math.MaxInt64}, false},
+		{[4]int{0, 10, math.MaxInt64, math.MaxInt64}, false},
+	} {
+		d := pdf.Dict{"ByteRange": pdf.Array{
+			pdf.Integer(tt.br[0]), pdf.Integer(tt.br[1]), pdf.Integer(tt.br[2]), pdf.Integer(tt.br[3]),
+		}}
+		if _, err := byteRange(ctx, d, 100); (err == nil) != tt.ok {
+			t.Errorf("byteRange %v: want ok=%t, got %v\n", tt.br, tt.ok, err)
+		}
+	}
+}
@@ -109,6 +109,7 @@ var wmParamMap = watermarkParamMap{
 	"diagonal":        parseDiagonal,
 	"fillcolor":       parseFillColor,
 	"fontname":        parseFontName,
+	"if":              parseCondition,
 	"margins":         parseMargins,
 	"mode":            parseRenderMode,
 	"offset":          parsePositionOffsetWM,
@@ -196,6 +197,7 @@ type Watermark struct {
 	ScaleEff          float64       // effective scale factor
 	ScaleAbs          bool          // true for absolute scaling.
 	Update            bool          // true for updating instead of adding a page watermark.
+	Condition         *Expression   // apply to pages satisfying this condition only, eg. "pagecount > 10".
 
 	// resources
 	ocg, extGState, font, image *IndirectRef
@@ -445,6 +447,15 @@ func parseFontName(s string, wm *Watermark) error {
 	return nil
 }
 
+func parseCondition(s string, wm *Watermark) error {
+	e, err := ParseExpression(s)
+	if err != nil {
+		return err
+	}
+	wm.Condition = e
+	return nil
+}
+
 func parseURL(s string, wm *Watermark) error {
 	if !wm.OnTop {
 		return errors.Errorf("pdfcpu: \"url\" supported for stamps only.\n")
@@ -1151,7 +1162,7 @@ func (ctx *Context) createPDFResForWM(wm *Watermark) error {
 	}
 
 	if err := otherCtx.EnsurePageCount(); err != nil {
-		return nil
+		return err
 	}
 
 	migrated := map[int]int{}
@@ -1798,6 +1809,13 @@ func (ctx *Context) addPageWatermark(i int, wm *Watermark) error {
 		return errors.Errorf("pdfcpu: invalid page number: %d", i)
 	}
 
+	if wm.Condition != nil {
+		ok, err := wm.Condition.EvalBool(map[string]interface{}{"page": i, "pagecount": ctx.PageCount})
+		if err != nil || !ok {
+			return err
+		}
+	}
+
 	log.Debug.Printf("addPageWatermark page:%d\n", i)
 	if wm.Update {
 		log.Debug.Println("Updating")
@@ -0,0 +1,197 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// PageStreamWriter writes a generated document page by page.
+//
+// Each page gets serialized and flushed as soon as it is added.
+// Only the cross reference table, the catalog and the page tree root are kept in memory
+// which allows for generating documents with a huge number of pages using bounded memory.
+// Fonts and images are shared by all pages, see Resources.
+// User fonts are written on Close subsetted to the glyphs used by all pages.
+// Outline items recorded along the way make up the document outline, see AddOutlineItem.
+type PageStreamWriter struct {
+	ctx     *Context
+	pages   IndirectRef // The page tree root.
+	kids    Array       // The pages written so far.
+	rm      *ResourceManager
+	outline outlineNode // Root of the recorded outline items.
+	closed  bool
+}
+
+// NewPageStreamWriter returns a PageStreamWriter writing to w.
+// Pages without media box default to A4.
+func NewPageStreamWriter(w io.Writer, conf *Configuration) (*PageStreamWriter, error) {
+	ctx, err := CreateContextWithXRefTable(conf, PaperSize["A4"])
+	if err != nil {
+		return nil, err
+	}
+
+	pagesIndRef, err := ctx.Pages()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Write.Writer = bufio.NewWriter(w)
+
+	if err := writeHeader(ctx.Write, V17); err != nil {
+		return nil, err
+	}
+
+	return &PageStreamWriter{ctx: ctx, pages: *pagesIndRef, rm: NewResourceManager(ctx.XRefTable)}, nil
+}
+
+// PageCount returns the number of pages written so far.
+func (sw *PageStreamWriter) PageCount() int {
+	return len(sw.kids)
+}
+
+// Resources returns the resource manager for the pages of sw.
+// Images need to be registered before adding the first page using them.
+func (sw *PageStreamWriter) Resources() *ResourceManager {
+	return sw.rm
+}
+
+// flushObjects writes all objects starting with object number from and releases their memory.
+// Font dicts of user fonts are held back until Close.
+func (sw *PageStreamWriter) flushObjects(from int) error {
+	ctx := sw.ctx
+
+	for objNr := from; objNr < *ctx.Size; objNr++ {
+		if ctx.Write.HasWriteOffset(objNr) || sw.rm.isPending(objNr) {
+			continue
+		}
+		if err := writeFlatObject(ctx, objNr); err != nil {
+			return err
+		}
+		if e, ok := ctx.FindTableEntryLight(objNr); ok {
+			e.Object = nil
+		}
+	}
+
+	return ctx.Write.Flush()
+}
+
+// AddPage serializes p and flushes it to the underlying writer.
+func (sw *PageStreamWriter) AddPage(p Page) error {
+	if sw.closed {
+		return errors.New("pdfcpu: PageStreamWriter: already closed")
+	}
+
+	xRefTable := sw.ctx.XRefTable
+	from := *xRefTable.Size
+
+	pageDict := Dict(
+		map[string]Object{
+			"Type":   Name("Page"),
+			"Parent": sw.pages,
+		},
+	)
+
+	if p.MediaBox != nil {
+		pageDict.Insert("MediaBox", p.MediaBox.Array())
+	}
+
+	resDict, err := sw.rm.PageResources(p)
+	if err != nil {
+		return err
+	}
+
+	if len(resDict) > 0 {
+		pageDict.Insert("Resources", resDict)
+	}
+
+	var b []byte
+	if p.Buf != nil {
+		b = p.Buf.Bytes()
+	}
+
+	ir, err := createDemoContentStreamDict(xRefTable, pageDict, b)
+	if err != nil {
+		return err
+	}
+	pageDict.Insert("Contents", *ir)
+
+	ir, err = xRefTable.IndRefForNewObject(pageDict)
+	if err != nil {
+		return err
+	}
+
+	sw.kids = append(sw.kids, *ir)
+
+	sw.ctx.Log.Write().Printf("PageStreamWriter: page %d is obj#%d\n", len(sw.kids), ir.ObjectNumber)
+
+	return sw.flushObjects(from)
+}
+
+// Close completes the document by writing the page tree root, the catalog and the cross reference table.
+// It does not close the underlying writer.
+func (sw *PageStreamWriter) Close() error {
+	if sw.closed {
+		return nil
+	}
+	sw.closed = true
+
+	ctx := sw.ctx
+
+	if len(sw.kids) == 0 {
+		return errors.New("pdfcpu: PageStreamWriter: need at least one page")
+	}
+
+	pagesDict, err := ctx.DereferenceDict(sw.pages)
+	if err != nil {
+		return err
+	}
+
+	pagesDict.Update("Kids", sw.kids)
+	pagesDict.Update("Count", Integer(len(sw.kids)))
+	ctx.PageCount = len(sw.kids)
+
+	if err := sw.writeOutline(); err != nil {
+		return err
+	}
+
+	if err := sw.rm.Finalize(); err != nil {
+		return err
+	}
+
+	if err := ensureInfoDictAndFileID(ctx); err != nil {
+		return err
+	}
+
+	// Catalog, page tree root, info dict, outline and user fonts.
+	if err := sw.flushObjects(1); err != nil {
+		return err
+	}
+
+	if err := writeXRefTable(ctx); err != nil {
+		return err
+	}
+
+	if err := writeTrailer(ctx.Write); err != nil {
+		return err
+	}
+
+	return ctx.Write.Flush()
+}
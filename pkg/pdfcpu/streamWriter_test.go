@@ -0,0 +1,274 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestPageStreamWriter(t *testing.T) {
+	msg := "TestPageStreamWriter"
+
+	var buf bytes.Buffer
+	sw, err := NewPageStreamWriter(&buf, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	n := 1000
+	for i := 1; i <= n; i++ {
+		mediaBox := RectForFormat("A5")
+		if i%2 == 0 {
+			mediaBox = RectForFormat("A4")
+		}
+		p := NewPage(mediaBox)
+		td := TextDescriptor{
+			Text:     fmt.Sprintf("Page %d", i),
+			FontName: "Helvetica",
+			FontKey:  p.Fm.EnsureKey("Helvetica"),
+			FontSize: 12,
+			Scale:    1.,
+			ScaleAbs: true,
+			X:        36,
+			Y:        mediaBox.Height() - 36,
+		}
+		WriteMultiLine(p.Buf, mediaBox, nil, td)
+
+		size := buf.Len()
+		if err := sw.AddPage(p); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if buf.Len() == size {
+			t.Fatalf("%s: page %d not flushed\n", msg, i)
+		}
+	}
+
+	// Written pages are not kept in memory.
+	for objNr, e := range sw.ctx.Table {
+		if sw.ctx.Write.HasWriteOffset(objNr) && e.Object != nil {
+			t.Fatalf("%s: obj#%d still in memory\n", msg, objNr)
+		}
+	}
+
+	// All pages share one font dict.
+	if len(sw.rm.fonts) != 1 {
+		t.Fatalf("%s: want 1 font, got %d\n", msg, len(sw.rm.fonts))
+	}
+
+	if err := sw.Close(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := sw.AddPage(NewPage(RectForFormat("A4"))); err == nil {
+		t.Fatalf("%s: want error adding page after close\n", msg)
+	}
+
+	ctx, err := Read(bytes.NewReader(buf.Bytes()), NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ctx.PageCount != n {
+		t.Fatalf("%s: want %d pages, got %d\n", msg, n, ctx.PageCount)
+	}
+
+	for _, i := range []int{1, 2, n} {
+		pt, err := ctx.ExtractPageText(i)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if want := fmt.Sprintf("Page %d", i); !strings.Contains(pt.String(), want) {
+			t.Errorf("%s: page %d: want %q, got %q\n", msg, i, want, pt.String())
+		}
+	}
+}
+
+func TestPageStreamWriterSharedImage(t *testing.T) {
+	msg := "TestPageStreamWriterSharedImage"
+
+	var buf bytes.Buffer
+	sw, err := NewPageStreamWriter(&buf, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	img := image.NewGray(image.Rect(0, 0, 8, 8))
+	var pngBuf bytes.Buffer
+	if err := png.Encode(&pngBuf, img); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if _, w, h, err := sw.Resources().Image("logo", &pngBuf); err != nil || w != 8 || h != 8 {
+		t.Fatalf("%s: want 8x8 image, got %dx%d: %v\n", msg, w, h, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		p := NewPage(RectForFormat("A4"))
+		fmt.Fprintf(p.Buf, "q 50 0 0 50 36 36 cm /%s Do Q ", p.Im.EnsureKey("logo"))
+		if err := sw.AddPage(p); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+
+	p := NewPage(RectForFormat("A4"))
+	p.Im.EnsureKey("unknown")
+	if err := sw.AddPage(p); err == nil {
+		t.Fatalf("%s: want error for unregistered image\n", msg)
+	}
+
+	if err := sw.Close(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := Read(bytes.NewReader(buf.Bytes()), NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	images := 0
+	for _, e := range ctx.Table {
+		if sd, ok := e.Object.(StreamDict); ok && sd.Subtype() != nil && *sd.Subtype() == "Image" {
+			images++
+		}
+	}
+	if images != 1 {
+		t.Fatalf("%s: want 1 image, got %d\n", msg, images)
+	}
+}
+
+func TestPageStreamWriterUnknownFont(t *testing.T) {
+	var buf bytes.Buffer
+	sw, err := NewPageStreamWriter(&buf, nil)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	p := NewPage(RectForFormat("A4"))
+	p.Fm.EnsureKey("NoSuchFont")
+
+	if err := sw.AddPage(p); err == nil {
+		t.Fatal("want error for unknown font\n")
+	}
+}
+
+func TestPageStreamWriterOutline(t *testing.T) {
+	msg := "TestPageStreamWriterOutline"
+
+	var buf bytes.Buffer
+	sw, err := NewPageStreamWriter(&buf, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := sw.AddOutlineItem(OutlineItem{Title: "Too early", Level: 1}); err == nil {
+		t.Fatalf("%s: want error for missing page\n", msg)
+	}
+
+	items := []OutlineItem{
+		{Title: "Chapter 1", Level: 1, Open: true},
+		{Title: "Section 1.1", Level: 2, Fit: "XYZ", Top: 800, Zoom: 1.5},
+		{Title: "Section 1.2", Level: 2, Fit: "FitH", Top: 400},
+		{Title: "Chapter 2", Level: 1, Bold: true},
+		{Title: "Section 2.1", Level: 2},
+	}
+
+	for i, item := range items {
+		if err := sw.AddPage(NewPage(RectForFormat("A4"))); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if err := sw.AddOutlineItem(item); err != nil {
+			t.Fatalf("%s: item %d: %v\n", msg, i, err)
+		}
+	}
+
+	for _, item := range []OutlineItem{
+		{Title: "Gap", Level: 4},
+		{Title: "Zero", Level: 0},
+		{Title: "Page", Level: 1, PageNr: 99},
+		{Title: "Fit", Level: 1, Fit: "FitX"},
+	} {
+		if err := sw.AddOutlineItem(item); err == nil {
+			t.Fatalf("%s: want error for %s\n", msg, item.Title)
+		}
+	}
+
+	if err := sw.Close(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := Read(bytes.NewReader(buf.Bytes()), NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	bms, err := ctx.BookmarksForOutline()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(bms) != 2 || len(bms[0].Children) != 2 || len(bms[1].Children) != 1 {
+		t.Fatalf("%s: unexpected outline: %v\n", msg, bms)
+	}
+	if bms[1].Title != "Chapter 2" || bms[1].PageFrom != 4 || bms[0].Children[1].PageFrom != 3 {
+		t.Fatalf("%s: unexpected outline: %v\n", msg, bms)
+	}
+
+	ir, err := ctx.Outlines()
+	if err != nil || ir == nil {
+		t.Fatalf("%s: missing outlines: %v\n", msg, err)
+	}
+	d, err := ctx.DereferenceDict(*ir)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	// Chapter 1, its two sections and Chapter 2 which is closed.
+	if c := d.IntEntry("Count"); c == nil || *c != 4 {
+		t.Fatalf("%s: want count 4, got %v\n", msg, d["Count"])
+	}
+
+	ch1, err := ctx.DereferenceDict(*d.IndirectRefEntry("First"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	s11, err := ctx.DereferenceDict(*ch1.IndirectRefEntry("First"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if dest := s11.ArrayEntry("Dest"); len(dest) != 5 || dest[1] != Name("XYZ") || dest[4] != Float(1.5) {
+		t.Fatalf("%s: unexpected destination: %v\n", msg, s11["Dest"])
+	}
+
+	ch2, err := ctx.DereferenceDict(*d.IndirectRefEntry("Last"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if c := ch2.IntEntry("Count"); c == nil || *c != -1 {
+		t.Fatalf("%s: want count -1, got %v\n", msg, ch2["Count"])
+	}
+	if f := ch2.IntEntry("F"); f == nil || *f != 2 {
+		t.Fatalf("%s: want bold, got %v\n", msg, ch2["F"])
+	}
+}
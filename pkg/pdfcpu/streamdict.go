@@ -64,16 +64,18 @@ func NewStreamDict(d Dict, streamOffset int64, streamLength *int64, streamLength
 func (sd StreamDict) Clone() Object {
 	sd1 := sd
 	sd1.Dict = sd.Dict.Clone().(Dict)
-	pl := make([]PDFFilter, len(sd.FilterPipeline))
-	for k, v := range sd.FilterPipeline {
-		f := PDFFilter{}
-		f.Name = v.Name
-		if f.DecodeParms != nil {
-			f.DecodeParms = v.DecodeParms.Clone().(Dict)
+	if sd.FilterPipeline != nil {
+		pl := make([]PDFFilter, len(sd.FilterPipeline))
+		for k, v := range sd.FilterPipeline {
+			f := PDFFilter{}
+			f.Name = v.Name
+			if v.DecodeParms != nil {
+				f.DecodeParms = v.DecodeParms.Clone().(Dict)
+			}
+			pl[k] = f
 		}
-		pl[k] = f
+		sd1.FilterPipeline = pl
 	}
-	sd1.FilterPipeline = pl
 	return sd1
 }
 
@@ -197,8 +199,30 @@ func (sd *StreamDict) Encode() error {
 	return nil
 }
 
+func decodeFilter(filterName string, parms map[string]int, r io.Reader, external bool) (io.Reader, error) {
+	// Invalid or misspelled filter names get rejected by filter.NewFilter.
+	if !filter.Supported(filterName) && (filter.Standard(filterName) || filter.NonStandard(filterName)) {
+		if d, ok := filter.ExternalDecoder(filterName); ok && external {
+			return d(r, parms)
+		}
+		return nil, filter.ErrUnsupportedFilter
+	}
+
+	fi, err := filter.NewFilter(filterName, parms)
+	if err != nil {
+		return nil, err
+	}
+
+	return fi.Decode(r)
+}
+
 // Decode applies sd's filter pipeline to sd.Raw in order to produce sd.Content.
 func (sd *StreamDict) Decode() error {
+	return sd.decode(false)
+}
+
+// decode falls back to registered external decoders for unsupported filters if external is true.
+func (sd *StreamDict) decode(external bool) error {
 	if sd.Content != nil {
 		// This stream has already been decoded.
 		return nil
@@ -215,10 +239,16 @@ func (sd *StreamDict) Decode() error {
 
 	var b, c io.Reader
 	b = bytes.NewReader(sd.Raw)
+	c = b
 
 	// Apply each filter in the pipeline to result of preceding filter.
 	for _, f := range sd.FilterPipeline {
 
+		if f.Name == "Crypt" {
+			// Crypt filters are taken care of by decryption.
+			continue
+		}
+
 		if f.DecodeParms != nil {
 			log.Trace.Printf("decodeStream: decoding filter:%s\ndecodeParms:%s\n", f.Name, f.DecodeParms)
 		} else {
@@ -241,12 +271,8 @@ func (sd *StreamDict) Decode() error {
 			}
 		}
 
-		fi, err := filter.NewFilter(f.Name, parms)
-		if err != nil {
-			return err
-		}
-
-		c, err = fi.Decode(b)
+		var err error
+		c, err = decodeFilter(f.Name, parms, b, external)
 		if err != nil {
 			return err
 		}
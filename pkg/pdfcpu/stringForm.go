@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Strings may be written as literal strings (7.3.4.2) or as hexadecimal strings (7.3.4.3).
+// Both forms represent the same bytes, so converting between them does not change the document.
+
+// stringBytes returns the bytes represented by a string object.
+func stringBytes(o Object) ([]byte, bool) {
+	switch o := o.(type) {
+	case StringLiteral:
+		bb, err := Unescape(o.Value())
+		if err != nil {
+			return nil, false
+		}
+		return bb, true
+	case HexLiteral:
+		bb, err := o.Bytes()
+		if err != nil {
+			return nil, false
+		}
+		return bb, true
+	}
+	return nil, false
+}
+
+// printableASCII returns true if bb is made up of printable ASCII characters and common whitespace.
+func printableASCII(bb []byte) bool {
+	for _, c := range bb {
+		if (c < 0x20 || c > 0x7E) && c != '\n' && c != '\r' && c != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// literalString returns bb as string literal.
+// Delimiters and the backslash get escaped, any other non printable byte gets written as octal escape sequence.
+func literalString(bb []byte) StringLiteral {
+	var sb strings.Builder
+	for _, c := range bb {
+		switch c {
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\\', '(', ')':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		default:
+			if c < 0x20 || c > 0x7E {
+				fmt.Fprintf(&sb, "\\%03o", c)
+				continue
+			}
+			sb.WriteByte(c)
+		}
+	}
+	return StringLiteral(sb.String())
+}
+
+// stringInForm returns the string object o written in form.
+// Strings failing to decode are left as is.
+func stringInForm(o Object, form int) Object {
+	bb, ok := stringBytes(o)
+	if !ok {
+		return o
+	}
+
+	if form == StringFormAuto {
+		form = StringFormHex
+		if printableASCII(bb) {
+			form = StringFormLiteral
+		}
+	}
+
+	switch form {
+	case StringFormLiteral:
+		if _, ok := o.(StringLiteral); ok {
+			return o
+		}
+		return literalString(bb)
+	case StringFormHex:
+		if _, ok := o.(HexLiteral); ok {
+			return o
+		}
+		return NewHexLiteral(bb)
+	}
+
+	return o
+}
+
+// objectInForm returns a copy of o with all strings written in form.
+// Strings nested in dicts and arrays are taken into account, indirect objects are not.
+func objectInForm(o Object, form int) Object {
+	switch o := o.(type) {
+
+	case StringLiteral, HexLiteral:
+		return stringInForm(o, form)
+
+	case Dict:
+		d := NewDict()
+		for k, v := range o {
+			d[k] = objectInForm(v, form)
+		}
+		return d
+
+	case StreamDict:
+		o.Dict = objectInForm(o.Dict, form).(Dict)
+		return o
+
+	case Array:
+		a := make(Array, len(o))
+		for i, v := range o {
+			a[i] = objectInForm(v, form)
+		}
+		return a
+	}
+
+	return o
+}
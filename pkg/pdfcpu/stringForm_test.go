@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStringInForm(t *testing.T) {
+	for _, tt := range []struct {
+		in   Object
+		form int
+		want Object
+	}{
+		{StringLiteral("pdfcpu"), StringFormHex, HexLiteral("706466637075")},
+		{HexLiteral("706466637075"), StringFormLiteral, StringLiteral("pdfcpu")},
+		{HexLiteral("28615c62290a"), StringFormLiteral, StringLiteral(`\(a\\b\)\n`)},
+		{HexLiteral("00ff"), StringFormLiteral, StringLiteral(`\000\377`)},
+		{HexLiteral("706466637075"), StringFormAuto, StringLiteral("pdfcpu")},
+		{StringLiteral(`\376\377\000A`), StringFormAuto, HexLiteral("feff0041")},
+		{StringLiteral(`(a\))`), StringFormPreserve, StringLiteral(`(a\))`)},
+	} {
+		got := stringInForm(tt.in, tt.form)
+		if got != tt.want {
+			t.Errorf("%s: want %s, got %s\n", tt.in, tt.want, got)
+		}
+		bb1, _ := stringBytes(tt.in)
+		bb2, _ := stringBytes(got)
+		if !bytes.Equal(bb1, bb2) {
+			t.Errorf("%s: bytes changed: %x -> %x\n", tt.in, bb1, bb2)
+		}
+	}
+}
+
+func TestObjectInForm(t *testing.T) {
+	d := Dict(map[string]Object{
+		"Title": StringLiteral("Report"),
+		"Kids":  Array{HexLiteral("4142"), Integer(1)},
+	})
+
+	d1 := objectInForm(d, StringFormHex).(Dict)
+	if d1["Title"] != HexLiteral("5265706f7274") || d1.ArrayEntry("Kids")[0] != HexLiteral("4142") {
+		t.Errorf("unexpected result: %s\n", d1)
+	}
+	if d["Title"] != StringLiteral("Report") {
+		t.Errorf("source modified: %s\n", d)
+	}
+}
@@ -0,0 +1,681 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+var errCorruptedStructTree = errors.New("pdfcpu: corrupted structure tree")
+
+// structTreeRoot returns the structure tree root of ctx, creating it if necessary and create is true.
+func (ctx *Context) structTreeRoot(create bool) (Dict, *IndirectRef, error) {
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ir := rootDict.IndirectRefEntry("StructTreeRoot"); ir != nil {
+		d, err := ctx.DereferenceDict(*ir)
+		if err != nil {
+			return nil, nil, err
+		}
+		if d != nil {
+			return d, ir, nil
+		}
+	}
+
+	if !create {
+		return nil, nil, nil
+	}
+
+	d := Dict(map[string]Object{"Type": Name("StructTreeRoot")})
+	ir, err := ctx.IndRefForNewObject(d)
+	if err != nil {
+		return nil, nil, err
+	}
+	rootDict.Update("StructTreeRoot", *ir)
+
+	return d, ir, nil
+}
+
+func (ctx *Context) collectNumberTree(o Object, m map[int]Object, visited IntSet) error {
+
+	if ir, ok := o.(IndirectRef); ok {
+		objNr := ir.ObjectNumber.Value()
+		if visited[objNr] {
+			return errCorruptedStructTree
+		}
+		visited[objNr] = true
+	}
+
+	d, err := ctx.DereferenceDict(o)
+	if err != nil || d == nil {
+		return err
+	}
+
+	for _, o := range d.ArrayEntry("Kids") {
+		if err := ctx.collectNumberTree(o, m, visited); err != nil {
+			return err
+		}
+	}
+
+	a, err := ctx.DereferenceArray(d["Nums"])
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i+1 < len(a); i += 2 {
+		k, err := ctx.DereferenceInteger(a[i])
+		if err != nil {
+			return err
+		}
+		if k == nil {
+			return errCorruptedStructTree
+		}
+		m[k.Value()] = a[i+1]
+	}
+
+	return nil
+}
+
+// parentTree returns the entries of the parent tree of the structure tree root d.
+func (ctx *Context) parentTree(d Dict) (map[int]Object, error) {
+	m := map[int]Object{}
+	if err := ctx.collectNumberTree(d["ParentTree"], m, IntSet{}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// setParentTree replaces the parent tree of the structure tree root d by a single node holding m.
+func (ctx *Context) setParentTree(d Dict, m map[int]Object) error {
+
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	nums := Array{}
+	for _, k := range keys {
+		nums = append(nums, Integer(k), m[k])
+	}
+
+	next := 0
+	if len(keys) > 0 {
+		next = keys[len(keys)-1] + 1
+	}
+	if i := d.IntEntry("ParentTreeNextKey"); i != nil && *i > next {
+		next = *i
+	}
+	d.Update("ParentTreeNextKey", Integer(next))
+
+	// Any former intermediate nodes become unreferenced and do not get written.
+	ptDict := Dict(map[string]Object{"Nums": nums})
+
+	if ir, ok := d["ParentTree"].(IndirectRef); ok {
+		entry, found := ctx.FindTableEntryForIndRef(&ir)
+		if found && entry != nil && !entry.Free {
+			entry.Object = ptDict
+			return nil
+		}
+	}
+
+	ir, err := ctx.IndRefForNewObject(ptDict)
+	if err != nil {
+		return err
+	}
+	d.Update("ParentTree", *ir)
+
+	return nil
+}
+
+// structParentKeys returns the parent tree keys used by a page and its annotations.
+func (ctx *Context) structParentKeys(pageDict Dict) ([]int, error) {
+
+	keys := []int{}
+
+	if i := pageDict.IntEntry("StructParents"); i != nil {
+		keys = append(keys, *i)
+	}
+
+	a, err := ctx.DereferenceArray(pageDict["Annots"])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range a {
+		d, err := ctx.DereferenceDict(o)
+		if err != nil {
+			return nil, err
+		}
+		if d == nil {
+			continue
+		}
+		if i := d.IntEntry("StructParent"); i != nil {
+			keys = append(keys, *i)
+		}
+	}
+
+	return keys, nil
+}
+
+// onPage returns true if ir refers to a page contained in objNrs.
+func onPage(ir *IndirectRef, objNrs IntSet) bool {
+	return ir != nil && objNrs[ir.ObjectNumber.Value()]
+}
+
+// pruneStructKid returns true if the structure element kid o is not exclusively related to removed pages.
+// pg is the page inherited from the parent structure element.
+func (ctx *Context) pruneStructKid(o Object, pg *IndirectRef, removed, visited IntSet) (bool, error) {
+
+	if _, ok := o.(Integer); ok {
+		// Marked content identifier on pg.
+		return !onPage(pg, removed), nil
+	}
+
+	if ir, ok := o.(IndirectRef); ok {
+		objNr := ir.ObjectNumber.Value()
+		if visited[objNr] {
+			return true, nil
+		}
+		visited[objNr] = true
+	}
+
+	d, err := ctx.DereferenceDict(o)
+	if err != nil || d == nil {
+		return false, err
+	}
+
+	if ir := d.IndirectRefEntry("Pg"); ir != nil {
+		pg = ir
+	}
+
+	if t := d.Type(); t != nil && (*t == "MCR" || *t == "OBJR") {
+		// Marked content reference or object reference.
+		return !onPage(pg, removed), nil
+	}
+
+	// Structure element.
+	k, found := d.Find("K")
+	if !found {
+		if onPage(pg, removed) {
+			d.Delete("Pg")
+			return false, nil
+		}
+		return true, nil
+	}
+
+	k, err = ctx.pruneStructKids(k, pg, removed, visited)
+	if err != nil {
+		return false, err
+	}
+
+	if k == nil {
+		// Nothing left to be tagged.
+		d.Delete("K")
+		d.Delete("Pg")
+		return false, nil
+	}
+
+	d.Update("K", k)
+	if onPage(d.IndirectRefEntry("Pg"), removed) {
+		d.Delete("Pg")
+	}
+
+	return true, nil
+}
+
+// pruneStructKids returns the kids o of a structure element without the kids exclusively related to removed pages.
+func (ctx *Context) pruneStructKids(o Object, pg *IndirectRef, removed, visited IntSet) (Object, error) {
+
+	a, ok := o.(Array)
+	if !ok {
+		keep, err := ctx.pruneStructKid(o, pg, removed, visited)
+		if err != nil || !keep {
+			return nil, err
+		}
+		return o, nil
+	}
+
+	kids := Array{}
+	for _, o := range a {
+		keep, err := ctx.pruneStructKid(o, pg, removed, visited)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			kids = append(kids, o)
+		}
+	}
+
+	if len(kids) == 0 {
+		return nil, nil
+	}
+
+	return kids, nil
+}
+
+// PruneStructTree removes all structure related to the pages objNrs which are not going to be part of the result.
+// Structure elements without remaining content get dropped and
+// the parent tree entries for the removed pages and their annotations get deleted.
+// Marked content identifiers within the content streams of the remaining pages stay valid.
+func (ctx *Context) PruneStructTree(objNrs IntSet) error {
+
+	d, _, err := ctx.structTreeRoot(false)
+	if err != nil || d == nil || len(objNrs) == 0 {
+		return err
+	}
+
+	m, err := ctx.parentTree(d)
+	if err != nil {
+		return err
+	}
+
+	for objNr := range objNrs {
+		pageDict, err := ctx.DereferenceDict(*NewIndirectRef(objNr, 0))
+		if err != nil {
+			return err
+		}
+		if pageDict == nil {
+			continue
+		}
+		keys, err := ctx.structParentKeys(pageDict)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			delete(m, k)
+		}
+	}
+
+	if err := ctx.setParentTree(d, m); err != nil {
+		return err
+	}
+
+	k, found := d.Find("K")
+	if !found {
+		return nil
+	}
+
+	k, err = ctx.pruneStructKids(k, nil, objNrs, IntSet{})
+	if err != nil {
+		return err
+	}
+
+	if k == nil {
+		d.Delete("K")
+		return nil
+	}
+
+	d.Update("K", k)
+
+	return nil
+}
+
+// offsetStructParents shifts the parent tree keys used by the pages pages and their annotations by offset.
+func (ctx *Context) offsetStructParents(pages []IndirectRef, offset int) error {
+
+	for _, ir := range pages {
+
+		d, err := ctx.DereferenceDict(ir)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			continue
+		}
+
+		if i := d.IntEntry("StructParents"); i != nil {
+			d.Update("StructParents", Integer(*i+offset))
+		}
+
+		a, err := ctx.DereferenceArray(d["Annots"])
+		if err != nil {
+			return err
+		}
+
+		for _, o := range a {
+			annot, err := ctx.DereferenceDict(o)
+			if err != nil {
+				return err
+			}
+			if annot == nil {
+				continue
+			}
+			if i := annot.IntEntry("StructParent"); i != nil {
+				annot.Update("StructParent", Integer(*i+offset))
+			}
+		}
+	}
+
+	return nil
+}
+
+// structKids returns the kids o of a structure element as array.
+func (ctx *Context) structKids(o Object) (Array, error) {
+	if o == nil {
+		return Array{}, nil
+	}
+	if ir, ok := o.(IndirectRef); ok {
+		// May either be a structure element or an array of kids.
+		o1, err := ctx.Dereference(ir)
+		if err != nil {
+			return nil, err
+		}
+		if a, ok := o1.(Array); ok {
+			return a, nil
+		}
+		return Array{ir}, nil
+	}
+	if a, ok := o.(Array); ok {
+		return a, nil
+	}
+	return Array{o}, nil
+}
+
+// mergeDictEntries adds all entries of the dict src missing in the dict entry key of dest.
+func (ctx *Context) mergeDictEntries(src, dest Dict, key string) error {
+
+	d1, err := ctx.DereferenceDict(src[key])
+	if err != nil || d1 == nil {
+		return err
+	}
+
+	d2, err := ctx.DereferenceDict(dest[key])
+	if err != nil {
+		return err
+	}
+	if d2 == nil {
+		dest[key] = d1
+		return nil
+	}
+
+	for k, v := range d1 {
+		if _, found := d2.Find(k); !found {
+			d2[k] = v
+		}
+	}
+
+	return nil
+}
+
+// mergeStructTrees grafts the structure tree of ctxSource onto the structure tree of ctxDest.
+// The parent tree keys of ctxSource get shifted behind the keys of ctxDest.
+// The pages and objects of ctxSource are expected to be part of ctxDest already.
+func mergeStructTrees(ctxSource, ctxDest *Context, pages []IndirectRef) (bool, error) {
+
+	// ctxSource objects live in ctxDest by now.
+	srcRootDict, err := ctxDest.DereferenceDict(*ctxSource.Root)
+	if err != nil || srcRootDict == nil {
+		return false, err
+	}
+
+	srcDict, err := ctxDest.DereferenceDict(srcRootDict["StructTreeRoot"])
+	if err != nil || srcDict == nil {
+		return false, err
+	}
+
+	destDict, destIndRef, err := ctxDest.structTreeRoot(true)
+	if err != nil {
+		return false, err
+	}
+
+	destPT, err := ctxDest.parentTree(destDict)
+	if err != nil {
+		return false, err
+	}
+
+	srcPT, err := ctxDest.parentTree(srcDict)
+	if err != nil {
+		return false, err
+	}
+
+	offset := 0
+	for k := range destPT {
+		if k >= offset {
+			offset = k + 1
+		}
+	}
+	if i := destDict.IntEntry("ParentTreeNextKey"); i != nil && *i > offset {
+		offset = *i
+	}
+
+	for k, v := range srcPT {
+		destPT[k+offset] = v
+	}
+
+	if err := ctxDest.offsetStructParents(pages, offset); err != nil {
+		return false, err
+	}
+
+	next := offset
+	if i := srcDict.IntEntry("ParentTreeNextKey"); i != nil {
+		next += *i
+	}
+	if next > offset {
+		destDict.Update("ParentTreeNextKey", Integer(next))
+	}
+
+	if err := ctxDest.setParentTree(destDict, destPT); err != nil {
+		return false, err
+	}
+
+	destKids, err := ctxDest.structKids(destDict["K"])
+	if err != nil {
+		return false, err
+	}
+
+	srcKids, err := ctxDest.structKids(srcDict["K"])
+	if err != nil {
+		return false, err
+	}
+
+	for _, o := range srcKids {
+		d, err := ctxDest.DereferenceDict(o)
+		if err != nil {
+			return false, err
+		}
+		if d != nil {
+			d.Update("P", *destIndRef)
+		}
+	}
+
+	if len(destKids)+len(srcKids) > 0 {
+		destDict.Update("K", append(destKids, srcKids...))
+	}
+
+	for _, key := range []string{"RoleMap", "ClassMap"} {
+		if err := ctxDest.mergeDictEntries(srcDict, destDict, key); err != nil {
+			return false, err
+		}
+	}
+
+	rootDict, err := ctxDest.Catalog()
+	if err != nil {
+		return false, err
+	}
+
+	markInfo, err := ctxDest.DereferenceDict(rootDict["MarkInfo"])
+	if err != nil {
+		return false, err
+	}
+	if markInfo == nil {
+		rootDict.Update("MarkInfo", Dict(map[string]Object{"Marked": Boolean(true)}))
+	} else {
+		markInfo.Update("Marked", Boolean(true))
+	}
+
+	return true, nil
+}
+
+// inlineStructTypes are the inline level structure types whose content continues the text of the enclosing element.
+var inlineStructTypes = map[string]bool{
+	"Span": true, "Quote": true, "Note": true, "Reference": true, "BibEntry": true, "Code": true,
+	"Link": true, "Annot": true, "Ruby": true, "RB": true, "RT": true, "RP": true,
+	"Warichu": true, "WT": true, "WP": true, "Lbl": true, "Em": true, "Strong": true, "Sub": true,
+}
+
+// structContent is a run of marked content of a structure element on a page.
+type structContent struct {
+	role  string
+	mcids []int
+}
+
+type structOrder struct {
+	ctx      *Context
+	page     int // page object number.
+	roleMap  Dict
+	visited  IntSet
+	contents []*structContent
+}
+
+// role returns the standard structure type the structure type s is mapped to.
+func (so *structOrder) role(s string) string {
+	for i := 0; i < 8 && so.roleMap != nil; i++ {
+		n := so.roleMap.NameEntry(s)
+		if n == nil || *n == s {
+			break
+		}
+		s = *n
+	}
+	return s
+}
+
+// visit collects the marked content of the structure element o on the page in logical order.
+// sc is the current content run of the enclosing element which gets continued by inline level elements.
+// visit returns the current content run to be continued by the enclosing element.
+func (so *structOrder) visit(o Object, pg *IndirectRef, sc *structContent) (*structContent, error) {
+	if ir, ok := o.(IndirectRef); ok {
+		objNr := ir.ObjectNumber.Value()
+		if so.visited[objNr] {
+			return sc, nil
+		}
+		so.visited[objNr] = true
+	}
+
+	d, err := so.ctx.DereferenceDict(o)
+	if err != nil || d == nil {
+		return sc, err
+	}
+
+	if ir := d.IndirectRefEntry("Pg"); ir != nil {
+		pg = ir
+	}
+
+	role := ""
+	if n := d.NameEntry("S"); n != nil {
+		role = so.role(*n)
+	}
+
+	inline := inlineStructTypes[role]
+	if !inline {
+		sc = nil
+	}
+
+	add := func(pg *IndirectRef, mcid int) {
+		if pg == nil || pg.ObjectNumber.Value() != so.page {
+			return
+		}
+		if sc == nil {
+			sc = &structContent{role: role}
+			so.contents = append(so.contents, sc)
+		}
+		sc.mcids = append(sc.mcids, mcid)
+	}
+
+	kids, err := so.ctx.structKids(d["K"])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range kids {
+		o, err := so.ctx.Dereference(k)
+		if err != nil {
+			return nil, err
+		}
+
+		if i, ok := o.(Integer); ok {
+			add(pg, i.Value())
+			continue
+		}
+
+		kd, ok := o.(Dict)
+		if !ok {
+			continue
+		}
+
+		if t := kd.Type(); t != nil && (*t == "MCR" || *t == "OBJR") {
+			if _, found := kd.Find("Stm"); *t == "OBJR" || found {
+				// Object references and marked content of form XObjects are not part of the page content.
+				continue
+			}
+			if mcid := kd.IntEntry("MCID"); mcid != nil {
+				kpg := pg
+				if ir := kd.IndirectRefEntry("Pg"); ir != nil {
+					kpg = ir
+				}
+				add(kpg, *mcid)
+			}
+			continue
+		}
+
+		// Child structure element.
+		if sc, err = so.visit(k, pg, sc); err != nil {
+			return nil, err
+		}
+	}
+
+	if !inline {
+		// Content following a block level element starts a new run.
+		return nil, nil
+	}
+
+	return sc, nil
+}
+
+// pageStructContent returns the runs of marked content of the page pageObjNr in the logical order of the structure tree.
+func (ctx *Context) pageStructContent(pageObjNr int) ([]*structContent, error) {
+	d, _, err := ctx.structTreeRoot(false)
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	roleMap, err := ctx.DereferenceDict(d["RoleMap"])
+	if err != nil {
+		return nil, err
+	}
+
+	so := &structOrder{ctx: ctx, page: pageObjNr, roleMap: roleMap, visited: IntSet{}}
+
+	kids, err := ctx.structKids(d["K"])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range kids {
+		if _, err := so.visit(k, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return so.contents, nil
+}
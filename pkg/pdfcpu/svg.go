@@ -0,0 +1,1140 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// The SVG export translates the content stream of a page into vector graphics
+// by interpreting path construction and painting, clipping, colors and text.
+// Text is written as text elements using the decoded Unicode text of the glyphs so it remains editable.
+// Fonts are referenced by name, font programs do not get embedded.
+// Images are embedded as PNG or JPEG data URIs, unsupported image types, shadings and inline images are skipped.
+
+const (
+	svgGray = iota
+	svgRGB
+	svgCMYK
+	svgTint
+	svgIndexed
+	svgPattern
+)
+
+// svgColorSpace approximates a PDF color space for conversion into RGB.
+type svgColorSpace struct {
+	kind   int
+	base   *svgColorSpace // Indexed
+	n      int            // Indexed: # of base components.
+	lookup []byte         // Indexed
+}
+
+var (
+	svgDeviceGray = &svgColorSpace{kind: svgGray}
+	svgDeviceRGB  = &svgColorSpace{kind: svgRGB}
+	svgDeviceCMYK = &svgColorSpace{kind: svgCMYK}
+)
+
+func svgClamp(f float64) float64 {
+	return math.Max(0, math.Min(1, f))
+}
+
+// rgb converts the color components c into RGB.
+func (cs *svgColorSpace) rgb(c []float64) ([3]float64, bool) {
+	switch cs.kind {
+	case svgGray:
+		if len(c) >= 1 {
+			return [3]float64{c[0], c[0], c[0]}, true
+		}
+	case svgRGB:
+		if len(c) >= 3 {
+			return [3]float64{c[0], c[1], c[2]}, true
+		}
+	case svgCMYK:
+		if len(c) >= 4 {
+			k := 1 - svgClamp(c[3])
+			return [3]float64{(1 - svgClamp(c[0])) * k, (1 - svgClamp(c[1])) * k, (1 - svgClamp(c[2])) * k}, true
+		}
+	case svgTint:
+		// Separation and DeviceN: tints are rendered as shades of gray.
+		if len(c) >= 1 {
+			g := 1 - svgClamp(c[0])
+			return [3]float64{g, g, g}, true
+		}
+	case svgIndexed:
+		if len(c) >= 1 {
+			i := int(c[0]) * cs.n
+			if i < 0 || i+cs.n > len(cs.lookup) {
+				return [3]float64{}, false
+			}
+			bc := make([]float64, cs.n)
+			for j := range bc {
+				bc[j] = float64(cs.lookup[i+j]) / 255
+			}
+			return cs.base.rgb(bc)
+		}
+	}
+	return [3]float64{}, false
+}
+
+// initialColor returns the initial color for cs.
+func (cs *svgColorSpace) initialColor() [3]float64 {
+	if cs.kind == svgTint {
+		return [3]float64{}
+	}
+	if c, ok := cs.rgb([]float64{0, 0, 0, 1}); ok {
+		return c
+	}
+	return [3]float64{}
+}
+
+type svgGState struct {
+	cbGState
+	fillCS, strokeCS       *svgColorSpace
+	fill, stroke           [3]float64
+	fillAlpha, strokeAlpha float64
+	lineCap, lineJoin      int
+	miterLimit             float64
+	dash                   []float64
+	dashPhase              float64
+	groups                 int // # of open group elements.
+}
+
+type svgRenderer struct {
+	cb      *contentBoxer // font metrics and text decoding.
+	w       *bytes.Buffer
+	defs    *bytes.Buffer
+	clipID  int
+	images  map[int]string // image element id per image object number.
+	imageID int
+	forms   map[int]bool // forms in process.
+	depth   int
+}
+
+func svgNum(f float64) string {
+	f = math.Round(f*1000) / 1000
+	if f == 0 {
+		// Avoid -0
+		return "0"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func svgMatrix(m matrix) string {
+	return fmt.Sprintf("matrix(%s %s %s %s %s %s)",
+		svgNum(m[0][0]), svgNum(m[0][1]), svgNum(m[1][0]), svgNum(m[1][1]), svgNum(m[2][0]), svgNum(m[2][1]))
+}
+
+func svgColor(c [3]float64) string {
+	return fmt.Sprintf("#%02x%02x%02x",
+		int(math.Round(svgClamp(c[0])*255)), int(math.Round(svgClamp(c[1])*255)), int(math.Round(svgClamp(c[2])*255)))
+}
+
+func svgEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func (r *svgRenderer) colorSpaceForName(name string) *svgColorSpace {
+	switch name {
+	case "DeviceGray", "G", "CalGray":
+		return svgDeviceGray
+	case "DeviceRGB", "RGB", "CalRGB", "Lab":
+		return svgDeviceRGB
+	case "DeviceCMYK", "CMYK":
+		return svgDeviceCMYK
+	case "Pattern":
+		return &svgColorSpace{kind: svgPattern}
+	}
+	return nil
+}
+
+func (r *svgRenderer) colorSpace(o Object, depth int) *svgColorSpace {
+	ctx := r.cb.ctx
+
+	o, err := ctx.Dereference(o)
+	if err != nil || o == nil || depth > 2 {
+		return svgDeviceGray
+	}
+
+	switch o := o.(type) {
+
+	case Name:
+		if cs := r.colorSpaceForName(o.Value()); cs != nil {
+			return cs
+		}
+
+	case Array:
+		if len(o) == 0 {
+			break
+		}
+		n, ok := o[0].(Name)
+		if !ok {
+			break
+		}
+		switch n {
+		case "ICCBased":
+			if len(o) < 2 {
+				break
+			}
+			sd, _, err := ctx.DereferenceStreamDict(o[1])
+			if err != nil || sd == nil {
+				break
+			}
+			if n := sd.IntEntry("N"); n != nil {
+				switch *n {
+				case 3:
+					return svgDeviceRGB
+				case 4:
+					return svgDeviceCMYK
+				}
+			}
+		case "Separation", "DeviceN":
+			return &svgColorSpace{kind: svgTint}
+		case "Indexed", "I":
+			if len(o) < 4 {
+				break
+			}
+			base := r.colorSpace(o[1], depth+1)
+			cs := &svgColorSpace{kind: svgIndexed, base: base, n: 1}
+			switch base.kind {
+			case svgRGB:
+				cs.n = 3
+			case svgCMYK:
+				cs.n = 4
+			}
+			lo, err := ctx.Dereference(o[3])
+			if err != nil {
+				break
+			}
+			switch lo := lo.(type) {
+			case StringLiteral:
+				bb, err := Unescape(lo.Value())
+				if err == nil {
+					cs.lookup = bb
+				}
+			case HexLiteral:
+				cs.lookup, _ = lo.Bytes()
+			case StreamDict:
+				if err := lo.Decode(); err == nil {
+					cs.lookup = lo.Content
+				}
+			}
+			return cs
+		default:
+			if cs := r.colorSpaceForName(n.Value()); cs != nil {
+				return cs
+			}
+		}
+	}
+
+	return svgDeviceGray
+}
+
+// colorSpaceResource returns the color space for the operand of cs or CS.
+func (r *svgRenderer) colorSpaceResource(res Dict, name string) *svgColorSpace {
+	if cs := r.colorSpaceForName(name); cs != nil {
+		return cs
+	}
+	o, err := r.cb.resource(res, "ColorSpace", name)
+	if err != nil || o == nil {
+		return svgDeviceGray
+	}
+	return r.colorSpace(o, 0)
+}
+
+func (r *svgRenderer) extGState(gs *svgGState, res Dict, name string) {
+	o, err := r.cb.resource(res, "ExtGState", name)
+	if err != nil || o == nil {
+		return
+	}
+	d, err := r.cb.ctx.DereferenceDict(o)
+	if err != nil || d == nil {
+		return
+	}
+	ctx := r.cb.ctx
+	if o, found := d.Find("ca"); found {
+		if f, err := ctx.DereferenceNumber(o); err == nil {
+			gs.fillAlpha = f
+		}
+	}
+	if o, found := d.Find("CA"); found {
+		if f, err := ctx.DereferenceNumber(o); err == nil {
+			gs.strokeAlpha = f
+		}
+	}
+	if o, found := d.Find("LW"); found {
+		if f, err := ctx.DereferenceNumber(o); err == nil {
+			gs.lw = f
+		}
+	}
+}
+
+func strokeScale(m matrix) float64 {
+	return math.Sqrt(math.Abs(m[0][0]*m[1][1] - m[0][1]*m[1][0]))
+}
+
+// paintAttrs returns the presentation attributes for filling and stroking.
+func (r *svgRenderer) paintAttrs(gs *svgGState, fill, stroke, evenOdd bool) string {
+	var ss []string
+
+	if fill {
+		ss = append(ss, fmt.Sprintf("fill=\"%s\"", svgColor(gs.fill)))
+		if gs.fillAlpha < 1 {
+			ss = append(ss, fmt.Sprintf("fill-opacity=\"%s\"", svgNum(gs.fillAlpha)))
+		}
+		if evenOdd {
+			ss = append(ss, "fill-rule=\"evenodd\"")
+		}
+	} else {
+		ss = append(ss, "fill=\"none\"")
+	}
+
+	if stroke {
+		sc := strokeScale(gs.ctm)
+		w := gs.lw * sc
+		if w == 0 {
+			// Thinnest line that can be rendered.
+			w = .25
+		}
+		ss = append(ss, fmt.Sprintf("stroke=\"%s\" stroke-width=\"%s\"", svgColor(gs.stroke), svgNum(w)))
+		if gs.strokeAlpha < 1 {
+			ss = append(ss, fmt.Sprintf("stroke-opacity=\"%s\"", svgNum(gs.strokeAlpha)))
+		}
+		switch gs.lineCap {
+		case 1:
+			ss = append(ss, "stroke-linecap=\"round\"")
+		case 2:
+			ss = append(ss, "stroke-linecap=\"square\"")
+		}
+		switch gs.lineJoin {
+		case 1:
+			ss = append(ss, "stroke-linejoin=\"round\"")
+		case 2:
+			ss = append(ss, "stroke-linejoin=\"bevel\"")
+		default:
+			if gs.miterLimit != 10 && gs.miterLimit >= 1 {
+				ss = append(ss, fmt.Sprintf("stroke-miterlimit=\"%s\"", svgNum(gs.miterLimit)))
+			}
+		}
+		if len(gs.dash) > 0 {
+			dd := make([]string, len(gs.dash))
+			for i, d := range gs.dash {
+				dd[i] = svgNum(d * sc)
+			}
+			ss = append(ss, fmt.Sprintf("stroke-dasharray=\"%s\"", strings.Join(dd, " ")))
+			if gs.dashPhase != 0 {
+				ss = append(ss, fmt.Sprintf("stroke-dashoffset=\"%s\"", svgNum(gs.dashPhase*sc)))
+			}
+		}
+	}
+
+	return strings.Join(ss, " ")
+}
+
+// clip starts a group clipped to path d.
+func (r *svgRenderer) clip(gs *svgGState, d string, evenOdd bool) {
+	r.clipID++
+	rule := ""
+	if evenOdd {
+		rule = " clip-rule=\"evenodd\""
+	}
+	fmt.Fprintf(r.w, "<clipPath id=\"c%d\"><path d=\"%s\"%s/></clipPath>\n", r.clipID, d, rule)
+	fmt.Fprintf(r.w, "<g clip-path=\"url(#c%d)\">\n", r.clipID)
+	gs.groups++
+}
+
+// closeGroups closes the groups opened since gs0.
+func (r *svgRenderer) closeGroups(gs *svgGState, n int) {
+	for ; gs.groups > n; gs.groups-- {
+		r.w.WriteString("</g>\n")
+	}
+}
+
+func svgFontAttrs(d Dict) string {
+	family, generic := "", "sans-serif"
+	var bold, italic bool
+
+	if d != nil {
+		if bf := d.NameEntry("BaseFont"); bf != nil {
+			family = *bf
+			if i := strings.IndexByte(family, '+'); i == 6 {
+				// Remove subset prefix.
+				family = family[7:]
+			}
+			s := strings.ToLower(family)
+			bold = strings.Contains(s, "bold") || strings.Contains(s, "black") || strings.Contains(s, "heavy")
+			italic = strings.Contains(s, "italic") || strings.Contains(s, "oblique")
+			switch {
+			case strings.Contains(s, "times") || strings.Contains(s, "serif") && !strings.Contains(s, "sans") || strings.Contains(s, "roman"):
+				generic = "serif"
+			case strings.Contains(s, "courier") || strings.Contains(s, "mono"):
+				generic = "monospace"
+			}
+			if i := strings.IndexAny(family, ",-"); i > 0 {
+				family = family[:i]
+			}
+		}
+	}
+
+	s := fmt.Sprintf("font-family=\"%s\"", generic)
+	if family != "" {
+		s = fmt.Sprintf("font-family=\"%s, %s\"", svgEscape(family), generic)
+	}
+	if bold {
+		s += " font-weight=\"bold\""
+	}
+	if italic {
+		s += " font-style=\"italic\""
+	}
+	return s
+}
+
+// svgTextRun collects the glyphs shown by a text showing operator into a single text element.
+type svgTextRun struct {
+	gs     *svgGState
+	tm     matrix // text matrix at the start of the run.
+	tx, ty float64
+	xs, ys []string
+	text   strings.Builder
+}
+
+func newSVGTextRun(gs *svgGState, tm matrix) *svgTextRun {
+	return &svgTextRun{gs: gs, tm: tm}
+}
+
+func (tr *svgTextRun) font() *cbFont {
+	if tr.gs.font == nil {
+		return defaultCBFont
+	}
+	return tr.gs.font
+}
+
+func (tr *svgTextRun) visible() bool {
+	gs := tr.gs
+	return gs.tr != 3 && gs.tr != 7 && gs.fs*gs.th != 0 && gs.fs != 0
+}
+
+// adjust moves the current position by a TJ adjustment of d thousandths of text space units.
+func (tr *svgTextRun) adjust(d float64) {
+	gs := tr.gs
+	if tr.font().vertical {
+		tr.ty -= d / 1000 * gs.fs
+		return
+	}
+	tr.tx -= d / 1000 * gs.fs * gs.th
+}
+
+// show lays out the glyphs of s.
+func (tr *svgTextRun) show(s string) {
+	gs, f := tr.gs, tr.font()
+
+	n := 1
+	if f.composite {
+		n = 2
+	}
+
+	sx, sy := gs.fs*gs.th, gs.fs
+	visible := tr.visible()
+
+	for i := 0; i+n <= len(s); i += n {
+		code := []byte(s[i : i+n])
+		c := int(code[0])
+		if n == 2 {
+			c = c<<8 + int(code[1])
+		}
+
+		gw := f.width(c)
+
+		if visible {
+			rr := []rune(glyphText(f, code))
+			for j := range rr {
+				if f.vertical {
+					tr.xs = append(tr.xs, svgNum((tr.tx-gw*gs.fs/2)/sx))
+					tr.ys = append(tr.ys, svgNum(-tr.ty/sy+f.vy*f.scale))
+					continue
+				}
+				tr.xs = append(tr.xs, svgNum((tr.tx+float64(j)*gw*sx/float64(len(rr)))/sx))
+				tr.ys = append(tr.ys, svgNum(-tr.ty/sy))
+			}
+			tr.text.WriteString(string(rr))
+		}
+
+		if f.vertical {
+			tr.ty += f.w1*f.scale*gs.fs + gs.tc
+			continue
+		}
+
+		w := gw*gs.fs + gs.tc
+		if !f.composite && c == 32 {
+			w += gs.tw
+		}
+		tr.tx += w * gs.th
+	}
+}
+
+// end returns the text matrix at the end of the run.
+func (tr *svgTextRun) end() matrix {
+	return translation(tr.tx, tr.ty).multiply(tr.tm)
+}
+
+// writeText writes the text run tr as text element.
+func (r *svgRenderer) writeText(tr *svgTextRun, fontAttrs string) {
+	gs := tr.gs
+
+	if !tr.visible() || isBlank(tr.text.String()) {
+		return
+	}
+
+	// Text space scaled by font size with the y axis pointing downwards.
+	ts := gs.ts
+	if tr.font().vertical {
+		ts = 0
+	}
+	m := matrix{{gs.fs * gs.th, 0, 0}, {0, -gs.fs, 0}, {0, ts, 1}}.multiply(tr.tm.multiply(gs.ctm))
+
+	fill, stroke := true, false
+	switch gs.tr % 4 {
+	case 1:
+		fill, stroke = false, true
+	case 2:
+		stroke = true
+	}
+
+	gs1 := *gs
+	if stroke {
+		// The line width applies to user space but the text element is scaled by the font size.
+		k := strokeScale(gs.ctm) / strokeScale(m)
+		gs1.ctm = matrix{{k, 0, 0}, {0, k, 0}, {0, 0, 1}}
+	}
+
+	ys := "0"
+	for _, y := range tr.ys {
+		if y != "0" {
+			ys = strings.Join(tr.ys, " ")
+			break
+		}
+	}
+
+	fmt.Fprintf(r.w, "<text xml:space=\"preserve\" transform=\"%s\" font-size=\"1\" %s %s x=\"%s\" y=\"%s\">%s</text>\n",
+		svgMatrix(m), fontAttrs, r.paintAttrs(&gs1, fill, stroke, false), strings.Join(tr.xs, " "), ys, svgEscape(tr.text.String()))
+}
+
+// showText writes the glyphs of s as text element and advances the text matrix.
+func (r *svgRenderer) showText(gs *svgGState, tm *matrix, s string, fontAttrs string) {
+	tr := newSVGTextRun(gs, *tm)
+	tr.show(s)
+	r.writeText(tr, fontAttrs)
+	*tm = tr.end()
+}
+
+// image writes a reference to the image XObject sd placed into the unit square of the current user space.
+func (r *svgRenderer) image(gs *svgGState, sd *StreamDict, name string, objNr int) error {
+	id, ok := r.images[objNr]
+	if !ok || objNr < 0 {
+		img, err := r.cb.ctx.ExtractImage(sd, false, name, objNr, false)
+		if err != nil {
+			return err
+		}
+		if img == nil {
+			return nil
+		}
+		var mime string
+		switch img.FileType {
+		case "png":
+			mime = "image/png"
+		case "jpg":
+			mime = "image/jpeg"
+		default:
+			log.Info.Printf("svg: skip image obj#%d, unsupported file type: %s\n", objNr, img.FileType)
+			return nil
+		}
+		bb, err := ioutil.ReadAll(img)
+		if err != nil {
+			return err
+		}
+		r.imageID++
+		id = fmt.Sprintf("i%d", r.imageID)
+		fmt.Fprintf(r.defs, "<image id=\"%s\" width=\"1\" height=\"1\" preserveAspectRatio=\"none\" xlink:href=\"data:%s;base64,%s\"/>\n",
+			id, mime, base64.StdEncoding.EncodeToString(bb))
+		if objNr >= 0 {
+			r.images[objNr] = id
+		}
+	}
+
+	// Image space has its origin in the upper left corner.
+	m := matrix{{1, 0, 0}, {0, -1, 0}, {0, 1, 1}}.multiply(gs.ctm)
+	fmt.Fprintf(r.w, "<use xlink:href=\"#%s\" transform=\"%s\"", id, svgMatrix(m))
+	if gs.fillAlpha < 1 {
+		fmt.Fprintf(r.w, " opacity=\"%s\"", svgNum(gs.fillAlpha))
+	}
+	r.w.WriteString("/>\n")
+
+	return nil
+}
+
+func (r *svgRenderer) xObject(gs svgGState, res Dict, name string) error {
+	ctx := r.cb.ctx
+
+	o, err := r.cb.resource(res, "XObject", name)
+	if err != nil || o == nil {
+		return err
+	}
+
+	objNr := -1
+	if ir, ok := o.(IndirectRef); ok {
+		objNr = ir.ObjectNumber.Value()
+		if r.forms[objNr] {
+			// Recursive form.
+			return nil
+		}
+	}
+
+	sd, _, err := ctx.DereferenceStreamDict(o)
+	if err != nil || sd == nil {
+		return err
+	}
+
+	subType := sd.Subtype()
+	if subType == nil {
+		return nil
+	}
+
+	if *subType == "Image" {
+		return r.image(&gs, sd, name, objNr)
+	}
+
+	if *subType != "Form" || r.depth >= maxFormNesting {
+		return nil
+	}
+
+	if err := sd.Decode(); err != nil {
+		return err
+	}
+
+	if a, err := ctx.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(a) == 6 {
+		m := identMatrix
+		for i, o := range a {
+			f, err := ctx.DereferenceNumber(o)
+			if err != nil {
+				return err
+			}
+			m[i/2][i%2] = f
+		}
+		gs.ctm = m.multiply(gs.ctm)
+	}
+
+	groups := gs.groups
+
+	if a, err := ctx.DereferenceArray(sd.Dict["BBox"]); err == nil && len(a) == 4 {
+		bb, err := RectForArray(a)
+		if err != nil {
+			return err
+		}
+		r.clip(&gs, svgRectPath(gs.ctm, bb.LL.X, bb.LL.Y, bb.Width(), bb.Height()), false)
+	}
+
+	formRes := res
+	if d, err := ctx.DereferenceDict(sd.Dict["Resources"]); err == nil && d != nil {
+		formRes = d
+	}
+
+	if objNr >= 0 {
+		r.forms[objNr] = true
+		defer delete(r.forms, objNr)
+	}
+
+	r.depth++
+	defer func() { r.depth-- }()
+
+	if err := r.process(sd.Content, formRes, gs); err != nil {
+		return err
+	}
+
+	r.closeGroups(&gs, groups)
+
+	return nil
+}
+
+func svgPoint(m matrix, x, y float64) string {
+	p := m.transform(Point{x, y})
+	return svgNum(p.X) + " " + svgNum(p.Y)
+}
+
+func svgRectPath(m matrix, x, y, w, h float64) string {
+	return fmt.Sprintf("M%sL%sL%sL%sZ", svgPoint(m, x, y), svgPoint(m, x+w, y), svgPoint(m, x+w, y+h), svgPoint(m, x, y+h))
+}
+
+func operandNumbers(opds []cbOperand) []float64 {
+	var ff []float64
+	for _, o := range opds {
+		if o.kind == cbNumber {
+			ff = append(ff, o.num)
+		}
+	}
+	return ff
+}
+
+func (r *svgRenderer) process(bb []byte, res Dict, gs svgGState) error {
+	var (
+		stack      []svgGState
+		opds       []cbOperand
+		path       strings.Builder
+		cur, start Point
+		clip       bool
+		evenOdd    bool
+		tm, tlm    = identMatrix, identMatrix
+		fontAttrs  = svgFontAttrs(nil)
+		fontStack  []string
+		groups     = gs.groups
+	)
+
+	pt := func(x, y float64) string {
+		return svgPoint(gs.ctm, x, y)
+	}
+
+	paint := func(fill, stroke, eo bool) {
+		if path.Len() > 0 {
+			d := path.String()
+			if fill || stroke {
+				fmt.Fprintf(r.w, "<path d=\"%s\" %s/>\n", d, r.paintAttrs(&gs, fill, stroke, eo))
+			}
+			if clip {
+				r.clip(&gs, d, evenOdd)
+			}
+		}
+		path.Reset()
+		clip = false
+	}
+
+	nextLine := func(tx, ty float64) {
+		tlm = translation(tx, ty).multiply(tlm)
+		tm = tlm
+	}
+
+	setColor := func(c *[3]float64, cs *svgColorSpace, opds []cbOperand) {
+		if col, ok := cs.rgb(operandNumbers(opds)); ok {
+			*c = col
+		}
+	}
+
+	l := &contentLexer{bb: bb}
+
+	for {
+		t, err := l.next()
+		if err != nil {
+			return err
+		}
+		if t == nil {
+			break
+		}
+		if t.op == "" {
+			opds = append(opds, t.opd)
+			continue
+		}
+
+		n := len(opds)
+
+		switch t.op {
+
+		case "q":
+			stack = append(stack, gs)
+			fontStack = append(fontStack, fontAttrs)
+
+		case "Q":
+			if len(stack) > 0 {
+				g := gs.groups
+				gs = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				fontAttrs = fontStack[len(fontStack)-1]
+				fontStack = fontStack[:len(fontStack)-1]
+				n0 := gs.groups
+				gs.groups = g
+				r.closeGroups(&gs, n0)
+			}
+
+		case "cm":
+			if numbers(opds, 6) {
+				gs.ctm = operandMatrix(opds[n-6:]).multiply(gs.ctm)
+			}
+
+		case "w":
+			if numbers(opds, 1) {
+				gs.lw = opds[n-1].num
+			}
+
+		case "J":
+			if numbers(opds, 1) {
+				gs.lineCap = int(opds[n-1].num)
+			}
+
+		case "j":
+			if numbers(opds, 1) {
+				gs.lineJoin = int(opds[n-1].num)
+			}
+
+		case "M":
+			if numbers(opds, 1) {
+				gs.miterLimit = opds[n-1].num
+			}
+
+		case "d":
+			if n >= 2 && opds[n-2].kind == cbArray && opds[n-1].kind == cbNumber {
+				gs.dash = operandNumbers(opds[n-2].arr)
+				gs.dashPhase = opds[n-1].num
+			}
+
+		case "gs":
+			if n > 0 && opds[n-1].kind == cbName {
+				r.extGState(&gs, res, opds[n-1].s)
+			}
+
+		case "g":
+			gs.fillCS = svgDeviceGray
+			setColor(&gs.fill, gs.fillCS, opds)
+
+		case "G":
+			gs.strokeCS = svgDeviceGray
+			setColor(&gs.stroke, gs.strokeCS, opds)
+
+		case "rg":
+			gs.fillCS = svgDeviceRGB
+			setColor(&gs.fill, gs.fillCS, opds)
+
+		case "RG":
+			gs.strokeCS = svgDeviceRGB
+			setColor(&gs.stroke, gs.strokeCS, opds)
+
+		case "k":
+			gs.fillCS = svgDeviceCMYK
+			setColor(&gs.fill, gs.fillCS, opds)
+
+		case "K":
+			gs.strokeCS = svgDeviceCMYK
+			setColor(&gs.stroke, gs.strokeCS, opds)
+
+		case "cs":
+			if n > 0 && opds[n-1].kind == cbName {
+				gs.fillCS = r.colorSpaceResource(res, opds[n-1].s)
+				gs.fill = gs.fillCS.initialColor()
+			}
+
+		case "CS":
+			if n > 0 && opds[n-1].kind == cbName {
+				gs.strokeCS = r.colorSpaceResource(res, opds[n-1].s)
+				gs.stroke = gs.strokeCS.initialColor()
+			}
+
+		case "sc", "scn":
+			setColor(&gs.fill, gs.fillCS, opds)
+
+		case "SC", "SCN":
+			setColor(&gs.stroke, gs.strokeCS, opds)
+
+		case "m":
+			if numbers(opds, 2) {
+				cur = Point{opds[n-2].num, opds[n-1].num}
+				start = cur
+				path.WriteString("M" + pt(cur.X, cur.Y))
+			}
+
+		case "l":
+			if numbers(opds, 2) {
+				cur = Point{opds[n-2].num, opds[n-1].num}
+				path.WriteString("L" + pt(cur.X, cur.Y))
+			}
+
+		case "c":
+			if numbers(opds, 6) {
+				o := opds[n-6:]
+				path.WriteString("C" + pt(o[0].num, o[1].num) + " " + pt(o[2].num, o[3].num) + " " + pt(o[4].num, o[5].num))
+				cur = Point{o[4].num, o[5].num}
+			}
+
+		case "v":
+			if numbers(opds, 4) {
+				o := opds[n-4:]
+				path.WriteString("C" + pt(cur.X, cur.Y) + " " + pt(o[0].num, o[1].num) + " " + pt(o[2].num, o[3].num))
+				cur = Point{o[2].num, o[3].num}
+			}
+
+		case "y":
+			if numbers(opds, 4) {
+				o := opds[n-4:]
+				path.WriteString("C" + pt(o[0].num, o[1].num) + " " + pt(o[2].num, o[3].num) + " " + pt(o[2].num, o[3].num))
+				cur = Point{o[2].num, o[3].num}
+			}
+
+		case "re":
+			if numbers(opds, 4) {
+				x, y, w, h := opds[n-4].num, opds[n-3].num, opds[n-2].num, opds[n-1].num
+				path.WriteString(svgRectPath(gs.ctm, x, y, w, h))
+				cur, start = Point{x, y}, Point{x, y}
+			}
+
+		case "h":
+			path.WriteString("Z")
+			cur = start
+
+		case "S":
+			paint(false, true, false)
+
+		case "s":
+			path.WriteString("Z")
+			paint(false, true, false)
+
+		case "f", "F":
+			paint(true, false, false)
+
+		case "f*":
+			paint(true, false, true)
+
+		case "B":
+			paint(true, true, false)
+
+		case "B*":
+			paint(true, true, true)
+
+		case "b":
+			path.WriteString("Z")
+			paint(true, true, false)
+
+		case "b*":
+			path.WriteString("Z")
+			paint(true, true, true)
+
+		case "n":
+			paint(false, false, false)
+
+		case "W":
+			clip, evenOdd = true, false
+
+		case "W*":
+			clip, evenOdd = true, true
+
+		case "Do":
+			if n > 0 && opds[n-1].kind == cbName {
+				if err := r.xObject(gs, res, opds[n-1].s); err != nil {
+					return err
+				}
+			}
+
+		case "BT":
+			tm, tlm = identMatrix, identMatrix
+
+		case "Tf":
+			if n >= 2 && opds[n-2].kind == cbName && opds[n-1].kind == cbNumber {
+				if gs.font, err = r.cb.font(res, opds[n-2].s); err != nil {
+					return err
+				}
+				gs.fs = opds[n-1].num
+				fontAttrs = svgFontAttrs(nil)
+				if o, err := r.cb.resource(res, "Font", opds[n-2].s); err == nil && o != nil {
+					if d, err := r.cb.ctx.DereferenceDict(o); err == nil {
+						fontAttrs = svgFontAttrs(d)
+					}
+				}
+			}
+
+		case "Tc":
+			if numbers(opds, 1) {
+				gs.tc = opds[n-1].num
+			}
+
+		case "Tw":
+			if numbers(opds, 1) {
+				gs.tw = opds[n-1].num
+			}
+
+		case "Tz":
+			if numbers(opds, 1) {
+				gs.th = opds[n-1].num / 100
+			}
+
+		case "TL":
+			if numbers(opds, 1) {
+				gs.tl = opds[n-1].num
+			}
+
+		case "Ts":
+			if numbers(opds, 1) {
+				gs.ts = opds[n-1].num
+			}
+
+		case "Tr":
+			if numbers(opds, 1) {
+				gs.tr = int(opds[n-1].num)
+			}
+
+		case "Td":
+			if numbers(opds, 2) {
+				nextLine(opds[n-2].num, opds[n-1].num)
+			}
+
+		case "TD":
+			if numbers(opds, 2) {
+				gs.tl = -opds[n-1].num
+				nextLine(opds[n-2].num, opds[n-1].num)
+			}
+
+		case "Tm":
+			if numbers(opds, 6) {
+				tlm = operandMatrix(opds[n-6:])
+				tm = tlm
+			}
+
+		case "T*":
+			nextLine(0, -gs.tl)
+
+		case "Tj":
+			if n > 0 && opds[n-1].kind == cbString {
+				r.showText(&gs, &tm, opds[n-1].s, fontAttrs)
+			}
+
+		case "'":
+			nextLine(0, -gs.tl)
+			if n > 0 && opds[n-1].kind == cbString {
+				r.showText(&gs, &tm, opds[n-1].s, fontAttrs)
+			}
+
+		case "\"":
+			nextLine(0, -gs.tl)
+			if n >= 3 && numbers(opds[:n-1], 2) && opds[n-1].kind == cbString {
+				gs.tw, gs.tc = opds[n-3].num, opds[n-2].num
+				r.showText(&gs, &tm, opds[n-1].s, fontAttrs)
+			}
+
+		case "TJ":
+			if n > 0 && opds[n-1].kind == cbArray {
+				tr := newSVGTextRun(&gs, tm)
+				for _, o := range opds[n-1].arr {
+					switch o.kind {
+					case cbNumber:
+						tr.adjust(o.num)
+					case cbString:
+						tr.show(o.s)
+					}
+				}
+				r.writeText(tr, fontAttrs)
+				tm = tr.end()
+			}
+		}
+
+		opds = opds[:0]
+	}
+
+	// Close groups left open by unbalanced q/Q.
+	r.closeGroups(&gs, groups)
+
+	return nil
+}
+
+// svgPageMatrix maps default user space into the SVG coordinate system for a visible region vp rotated by rot degrees.
+// It returns the transformation along with the dimensions of the SVG image.
+func svgPageMatrix(vp *Rectangle, rot int) (matrix, float64, float64) {
+	w, h := vp.Width(), vp.Height()
+
+	m := matrix{{1, 0, 0}, {0, -1, 0}, {-vp.LL.X, vp.UR.Y, 1}}
+
+	switch (rot%360 + 360) % 360 {
+	case 90:
+		m = m.multiply(matrix{{0, 1, 0}, {-1, 0, 0}, {h, 0, 1}})
+		w, h = h, w
+	case 180:
+		m = m.multiply(matrix{{-1, 0, 0}, {0, -1, 0}, {w, h, 1}})
+	case 270:
+		m = m.multiply(matrix{{0, -1, 0}, {1, 0, 0}, {0, w, 1}})
+		w, h = h, w
+	}
+
+	return m, w, h
+}
+
+// WritePageSVG writes the content of page pageNr as standalone SVG image to w.
+// One unit of the SVG image corresponds to one point in default user space.
+func (ctx *Context) WritePageSVG(pageNr int, w io.Writer) error {
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, true)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.Errorf("pdfcpu: unknown page number: %d\n", pageNr)
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err != nil && err != errNoContent {
+		return err
+	}
+
+	r := &svgRenderer{
+		// A glyph consumer makes the content boxer load text decoders for fonts.
+		cb:     &contentBoxer{ctx: ctx, fonts: map[int]*cbFont{}, forms: map[int]bool{}, glyph: func(*cbGState, matrix, Point, *Rectangle, []byte) {}},
+		w:      &bytes.Buffer{},
+		defs:   &bytes.Buffer{},
+		images: map[int]string{},
+		forms:  map[int]bool{},
+	}
+
+	vp := viewPort(inhPAttrs)
+
+	gs := svgGState{
+		cbGState:    cbGState{ctm: identMatrix, lw: 1, th: 1, mcid: -1},
+		fillCS:      svgDeviceGray,
+		strokeCS:    svgDeviceGray,
+		fillAlpha:   1,
+		strokeAlpha: 1,
+		miterLimit:  10,
+	}
+
+	r.clip(&gs, svgRectPath(identMatrix, vp.LL.X, vp.LL.Y, vp.Width(), vp.Height()), false)
+
+	if err := r.process(bb, inhPAttrs.resources, gs); err != nil {
+		return err
+	}
+
+	r.closeGroups(&gs, 0)
+
+	m, width, height := svgPageMatrix(vp, inhPAttrs.rotate)
+
+	var b bytes.Buffer
+	b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" xmlns:xlink=\"http://www.w3.org/1999/xlink\" version=\"1.1\" width=\"%spt\" height=\"%spt\" viewBox=\"0 0 %s %s\">\n",
+		svgNum(width), svgNum(height), svgNum(width), svgNum(height))
+	if r.defs.Len() > 0 {
+		b.WriteString("<defs>\n")
+		b.Write(r.defs.Bytes())
+		b.WriteString("</defs>\n")
+	}
+	fmt.Fprintf(&b, "<g transform=\"%s\">\n", svgMatrix(m))
+	b.Write(r.w.Bytes())
+	b.WriteString("</g>\n</svg>\n")
+
+	_, err = w.Write(b.Bytes())
+	return err
+}
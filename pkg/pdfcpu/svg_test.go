@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import "testing"
+
+func TestSVGPageMatrix(t *testing.T) {
+	vp := Rect(10, 20, 110, 220) // 100 x 200
+
+	for _, tt := range []struct {
+		rot  int
+		w, h float64
+		ll   Point // lower left corner of vp in SVG coordinates.
+	}{
+		{0, 100, 200, Point{0, 200}},
+		{90, 200, 100, Point{0, 0}},
+		{180, 100, 200, Point{100, 0}},
+		{270, 200, 100, Point{200, 100}},
+		{-90, 200, 100, Point{200, 100}},
+	} {
+		m, w, h := svgPageMatrix(vp, tt.rot)
+		if w != tt.w || h != tt.h {
+			t.Errorf("rot %d: want %.0fx%.0f, got %.0fx%.0f", tt.rot, tt.w, tt.h, w, h)
+		}
+		if p := m.transform(Point{vp.LL.X, vp.LL.Y}); p != tt.ll {
+			t.Errorf("rot %d: want lower left corner at %v, got %v", tt.rot, tt.ll, p)
+		}
+	}
+}
+
+func TestSVGColorSpace(t *testing.T) {
+	if c := svgColor(svgDeviceCMYK.initialColor()); c != "#000000" {
+		t.Errorf("want black, got %s", c)
+	}
+	if c, _ := svgDeviceCMYK.rgb([]float64{1, 0, 0, 0}); svgColor(c) != "#00ffff" {
+		t.Errorf("want cyan, got %s", svgColor(c))
+	}
+	cs := &svgColorSpace{kind: svgIndexed, base: svgDeviceRGB, n: 3, lookup: []byte{0, 0, 0, 255, 0, 0}}
+	if c, _ := cs.rgb([]float64{1}); svgColor(c) != "#ff0000" {
+		t.Errorf("want red, got %s", svgColor(c))
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The tab orders for the annotations of a page.
+var tabOrders = map[string]string{
+	"R": "row",
+	"C": "column",
+	"S": "structure",
+	"A": "annotations", // PDF 2.0
+	"W": "widgets",     // PDF 2.0
+}
+
+// ParseTabOrder returns the /Tabs value for s which is either a tab order value or its description.
+// An empty string stands for no tab order.
+func ParseTabOrder(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	for k, v := range tabOrders {
+		if strings.EqualFold(s, k) || strings.EqualFold(s, v) {
+			return k, nil
+		}
+	}
+	return "", errors.Errorf("pdfcpu: invalid tab order: %s, please use one of: row, column, structure, annotations, widgets", s)
+}
+
+// TabOrder returns the tab order of page pageNr or an empty string.
+func (ctx *Context) TabOrder(pageNr int) (string, error) {
+	d, _, _, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return "", err
+	}
+	if d == nil {
+		return "", errors.Errorf("pdfcpu: unknown page number: %d\n", pageNr)
+	}
+	if n := d.NameEntry("Tabs"); n != nil {
+		return *n, nil
+	}
+	return "", nil
+}
+
+// ListTabOrders returns a list of the tab orders of selected pages.
+func (ctx *Context) ListTabOrders(selectedPages IntSet) ([]string, error) {
+
+	pageNrs := []int{}
+	for k, v := range selectedPages {
+		if v {
+			pageNrs = append(pageNrs, k)
+		}
+	}
+	sort.Ints(pageNrs)
+
+	ss := []string{}
+	for _, i := range pageNrs {
+		s, err := ctx.TabOrder(i)
+		if err != nil {
+			return nil, err
+		}
+		desc := "none"
+		if s != "" {
+			desc = tabOrders[s]
+			if desc == "" {
+				desc = "unknown"
+			}
+			desc = fmt.Sprintf("%s (%s)", s, desc)
+		}
+		ss = append(ss, fmt.Sprintf("page %d: %s", i, desc))
+	}
+
+	return ss, nil
+}
+
+// SetTabOrder sets the tab order of selected pages to tabs.
+// An empty tabs removes the tab order.
+func (ctx *Context) SetTabOrder(selectedPages IntSet, tabs string) error {
+
+	if tabs != "" {
+		if _, found := tabOrders[tabs]; !found {
+			return errors.Errorf("pdfcpu: invalid tab order: %s", tabs)
+		}
+	}
+
+	for k, v := range selectedPages {
+		if !v {
+			continue
+		}
+		d, _, _, err := ctx.PageDict(k, false)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			return errors.Errorf("pdfcpu: unknown page number: %d\n", k)
+		}
+		if tabs == "" {
+			d.Delete("Tabs")
+			continue
+		}
+		d.Update("Tabs", Name(tabs))
+	}
+
+	if tabs != "" {
+		// Tabs got introduced with PDF 1.5.
+		ctx.EnsureVersionForWriting()
+	}
+
+	return nil
+}
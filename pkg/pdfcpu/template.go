@@ -0,0 +1,294 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pkg/errors"
+)
+
+// TemplateRegion is a named box on a page of an existing document getting filled with text or an image.
+//
+// Rect is given as llx lly urx ury relative to the lower left corner of the visible page area in display orientation.
+type TemplateRegion struct {
+	Name     string     `json:"name"`
+	Page     int        `json:"page"`
+	Rect     [4]float64 `json:"rect"`
+	Image    bool       `json:"image,omitempty"`    // Fill in an image instead of text.
+	Pos      string     `json:"pos,omitempty"`      // Position anchor within Rect: tl,tc,tr,l,c,r,bl,bc,br,full (default: tl)
+	Justify  bool       `json:"justify,omitempty"`  // Justify text using the width of Rect.
+	FontName string     `json:"font,omitempty"`     // Core or user font (default: Helvetica)
+	FontSize int        `json:"fontSize,omitempty"` // Font size in points (default: 12)
+	Color    string     `json:"color,omitempty"`    // Text color, #RRGGBB or r g b (default: black)
+	RTL      bool       `json:"rtl,omitempty"`      // Right to left user font.
+
+	rect   *Rectangle
+	anchor anchor
+	col    SimpleColor
+}
+
+// Template is a collection of regions defined for an existing document.
+type Template struct {
+	Regions []*TemplateRegion `json:"regions"`
+}
+
+// TemplateRecord holds the text for each text region and an image id for each image region by region name.
+type TemplateRecord map[string]string
+
+func (tr *TemplateRegion) validate() error {
+	if tr.Name == "" {
+		return errors.New("pdfcpu: template region: missing name")
+	}
+	if tr.Page < 1 {
+		return errors.Errorf("pdfcpu: template region %s: invalid page: %d", tr.Name, tr.Page)
+	}
+
+	tr.rect = Rect(tr.Rect[0], tr.Rect[1], tr.Rect[2], tr.Rect[3])
+	if tr.rect.Width() <= 0 || tr.rect.Height() <= 0 {
+		return errors.Errorf("pdfcpu: template region %s: invalid rect: %v", tr.Name, tr.Rect)
+	}
+
+	tr.anchor = TopLeft
+	if tr.Pos != "" {
+		a, err := parsePositionAnchor(tr.Pos)
+		if err != nil {
+			return errors.Wrapf(err, "pdfcpu: template region %s", tr.Name)
+		}
+		tr.anchor = a
+	}
+
+	if tr.Image {
+		return nil
+	}
+
+	if tr.FontName == "" {
+		tr.FontName = "Helvetica"
+	}
+	if !font.SupportedFont(tr.FontName) {
+		return errors.Errorf("pdfcpu: template region %s: unsupported font: %s", tr.Name, tr.FontName)
+	}
+
+	if tr.FontSize == 0 {
+		tr.FontSize = 12
+	}
+	if tr.FontSize < 0 {
+		return errors.Errorf("pdfcpu: template region %s: invalid font size: %d", tr.Name, tr.FontSize)
+	}
+
+	tr.col = Black
+	if tr.Color != "" {
+		c, err := parseColor(tr.Color)
+		if err != nil {
+			return errors.Wrapf(err, "pdfcpu: template region %s", tr.Name)
+		}
+		tr.col = c
+	}
+
+	return nil
+}
+
+// Validate checks t for unique region names and valid region attributes and applies defaults.
+func (t *Template) Validate() error {
+	if len(t.Regions) == 0 {
+		return errors.New("pdfcpu: template without regions")
+	}
+
+	names := map[string]bool{}
+	for _, tr := range t.Regions {
+		if tr == nil {
+			return errors.New("pdfcpu: template: missing region")
+		}
+		if err := tr.validate(); err != nil {
+			return err
+		}
+		if names[tr.Name] {
+			return errors.Errorf("pdfcpu: template: duplicate region: %s", tr.Name)
+		}
+		names[tr.Name] = true
+	}
+
+	return nil
+}
+
+func (tr *TemplateRegion) renderText(w io.Writer, s, fontKey string) {
+	td := TextDescriptor{
+		Text:     s,
+		FontName: tr.FontName,
+		FontKey:  fontKey,
+		FontSize: tr.FontSize,
+		RTL:      tr.RTL,
+		RMode:    RMFill,
+		FillCol:  tr.col,
+		Scale:    1,
+		ScaleAbs: true,
+	}
+
+	// Anchoring operates relative to the region.
+	r := RectForDim(tr.rect.Width(), tr.rect.Height())
+
+	a := tr.anchor
+	if a == Full {
+		a = TopLeft
+	}
+
+	fmt.Fprintf(w, "q 1 0 0 1 %.2f %.2f cm %.2f %.2f %.2f %.2f re W n ", tr.rect.LL.X, tr.rect.LL.Y, r.LL.X, r.LL.Y, r.Width(), r.Height())
+
+	if tr.Justify {
+		WriteColumnAnchored(w, r, nil, td, a, r.Width())
+	} else {
+		WriteMultiLineAnchored(w, r, nil, td, a)
+	}
+
+	fmt.Fprint(w, "Q ")
+}
+
+func (tr *TemplateRegion) renderImage(w io.Writer, imgKey string, imgW, imgH int) {
+	r := tr.rect
+	w1, h1 := r.Width(), r.Height()
+
+	if tr.anchor != Full {
+		// Fit the image into the region preserving its aspect ratio.
+		s := math.Min(w1/float64(imgW), h1/float64(imgH))
+		w1, h1 = float64(imgW)*s, float64(imgH)*s
+	}
+
+	ll := boxLowerLeftCorner(r, w1, h1, tr.anchor)
+	fmt.Fprintf(w, "q %.2f 0 0 %.2f %.2f %.2f cm /%s Do Q ", w1, h1, ll.X, ll.Y, imgKey)
+}
+
+func (ctx *Context) fillTemplatePage(pageNr int, regions []*TemplateRegion, record TemplateRecord, images map[string]io.Reader, rm *ResourceManager) error {
+
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, true)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.Errorf("pdfcpu: unknown page number: %d\n", pageNr)
+	}
+
+	var b bytes.Buffer
+
+	// Resource ids by font name and image id.
+	keys := map[string]string{}
+
+	resourceKey := func(resType, prefix, name string, ir *IndirectRef) (string, error) {
+		k := resType + ":" + name
+		if id, ok := keys[k]; ok {
+			return id, nil
+		}
+		resDict, err := ctx.pageResourceDict(d, inhPAttrs, resType)
+		if err != nil {
+			return "", err
+		}
+		id := uniqueResourceID(resDict, prefix)
+		resDict.Insert(id, *ir)
+		keys[k] = id
+		return id, nil
+	}
+
+	for _, tr := range regions {
+		s, ok := record[tr.Name]
+		if !ok {
+			// Regions not covered by record stay empty.
+			continue
+		}
+
+		if tr.Image {
+			ir, w, h, err := rm.Image(s, images[s])
+			if err != nil {
+				return errors.Wrapf(err, "pdfcpu: template region %s", tr.Name)
+			}
+			id, err := resourceKey("XObject", "Im", s, ir)
+			if err != nil {
+				return err
+			}
+			tr.renderImage(&b, id, w, h)
+			continue
+		}
+
+		ir, err := rm.Font(tr.FontName)
+		if err != nil {
+			return err
+		}
+		id, err := resourceKey("Font", "F", tr.FontName, ir)
+		if err != nil {
+			return err
+		}
+		tr.renderText(&b, s, id)
+	}
+
+	if b.Len() == 0 {
+		return nil
+	}
+
+	_, cm := displaySpace(inhPAttrs)
+
+	var bb bytes.Buffer
+	fmt.Fprint(&bb, "q ")
+	bb.Write(cm)
+	bb.Write(b.Bytes())
+	fmt.Fprint(&bb, "Q ")
+
+	return ctx.appendPageContent(d, bb.Bytes())
+}
+
+// FillTemplate renders the values of record into the regions of t.
+// The values of image regions are ids of images provided by images.
+func (ctx *Context) FillTemplate(t *Template, record TemplateRecord, images map[string]io.Reader) error {
+
+	if t == nil {
+		return errors.New("pdfcpu: missing template")
+	}
+
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	m := map[int][]*TemplateRegion{}
+	for _, tr := range t.Regions {
+		if tr.Page > ctx.PageCount {
+			return errors.Errorf("pdfcpu: template region %s: page %d out of range", tr.Name, tr.Page)
+		}
+		m[tr.Page] = append(m[tr.Page], tr)
+	}
+
+	pageNrs := make([]int, 0, len(m))
+	for pageNr := range m {
+		pageNrs = append(pageNrs, pageNr)
+	}
+	sort.Ints(pageNrs)
+
+	rm := NewResourceManager(ctx.XRefTable)
+
+	for _, pageNr := range pageNrs {
+		if err := ctx.fillTemplatePage(pageNr, m[pageNr], record, images, rm); err != nil {
+			return err
+		}
+	}
+
+	return rm.Finalize()
+}
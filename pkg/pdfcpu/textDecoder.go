@@ -0,0 +1,215 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"strings"
+)
+
+// TextDecoder decodes strings shown using a specific font into Unicode text.
+type TextDecoder struct {
+	toUnicode *unicodeCMap
+	cmap      cmapDecoder // Type0 fonts using a predefined CMap
+	encoding  *[256]rune  // simple fonts
+	composite bool
+}
+
+// symbolicBaseFont returns the name of the symbolic standard font fontDict refers to.
+func symbolicBaseFont(fontDict Dict) string {
+	n := fontDict.NameEntry("BaseFont")
+	if n == nil {
+		return ""
+	}
+	s := *n
+	if i := strings.Index(s, "+"); i == 6 {
+		// Skip subset prefix.
+		s = s[i+1:]
+	}
+	switch {
+	case strings.HasPrefix(s, "Symbol"):
+		return "Symbol"
+	case strings.HasPrefix(s, "ZapfDingbats"), strings.HasPrefix(s, "Dingbats"):
+		return "ZapfDingbats"
+	}
+	return ""
+}
+
+func (xRefTable *XRefTable) simpleFontEncoding(fontDict Dict) (*[256]rune, error) {
+
+	// Symbolic standard fonts use their built-in encoding unless told otherwise.
+	name := symbolicBaseFont(fontDict)
+
+	o, err := xRefTable.Dereference(fontDict["Encoding"])
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs Array
+
+	switch o := o.(type) {
+	case Name:
+		name = o.Value()
+	case Dict:
+		if n := o.NameEntry("BaseEncoding"); n != nil {
+			name = *n
+		}
+		if diffs, err = xRefTable.DereferenceArray(o["Differences"]); err != nil {
+			return nil, err
+		}
+	}
+
+	e := baseEncoding(name)
+
+	code := 0
+	for _, o := range diffs {
+		o, err := xRefTable.Dereference(o)
+		if err != nil {
+			return nil, err
+		}
+		switch o := o.(type) {
+		case Integer:
+			code = o.Value()
+		case Name:
+			if code >= 0 && code < 256 {
+				if r, ok := glyphRune(o.Value()); ok {
+					e[code] = r
+				}
+			}
+			code++
+		}
+	}
+
+	return e, nil
+}
+
+func (xRefTable *XRefTable) compositeFontCMap(fontDict Dict) (cmapDecoder, error) {
+
+	o, err := xRefTable.Dereference(fontDict["Encoding"])
+	if err != nil {
+		return nil, err
+	}
+
+	var name string
+
+	switch o := o.(type) {
+	case Name:
+		name = o.Value()
+	case StreamDict:
+		// Embedded CMaps are supported as far as they are named after a predefined CMap.
+		if n := o.NameEntry("CMapName"); n != nil {
+			name = *n
+		}
+	}
+
+	return predefinedCMaps[name], nil
+}
+
+// NewTextDecoder returns a TextDecoder for fontDict.
+// A ToUnicode CMap takes precedence. Type0 fonts fall back to predefined Unicode based or CJK CMaps,
+// simple fonts fall back to their encoding including the built-in encodings of Symbol and ZapfDingbats.
+func NewTextDecoder(xRefTable *XRefTable, fontDict Dict) (*TextDecoder, error) {
+
+	td := &TextDecoder{}
+
+	if st := fontDict.Subtype(); st != nil && *st == "Type0" {
+		td.composite = true
+		dec, err := xRefTable.compositeFontCMap(fontDict)
+		if err != nil {
+			return nil, err
+		}
+		td.cmap = dec
+	} else {
+		e, err := xRefTable.simpleFontEncoding(fontDict)
+		if err != nil {
+			return nil, err
+		}
+		td.encoding = e
+	}
+
+	o, found := fontDict.Find("ToUnicode")
+	if !found {
+		return td, nil
+	}
+
+	sd, _, err := xRefTable.DereferenceStreamDict(o)
+	if err != nil || sd == nil {
+		return td, err
+	}
+
+	if err := sd.Decode(); err != nil {
+		return nil, err
+	}
+
+	if td.toUnicode, err = parseToUnicodeCMap(sd.Content); err != nil {
+		return nil, err
+	}
+
+	return td, nil
+}
+
+func (td *TextDecoder) decodeCode(code []byte) string {
+	if td.toUnicode != nil {
+		if s, ok := td.toUnicode.m[string(code)]; ok {
+			return s
+		}
+	}
+	if td.encoding != nil && len(code) == 1 {
+		if r := td.encoding[code[0]]; r != 0 {
+			return string(r)
+		}
+	}
+	if td.cmap != nil {
+		if s, err := td.cmap(code); err == nil {
+			return s
+		}
+	}
+	return string(rune(0xFFFD))
+}
+
+// Decode returns the Unicode text for the string b shown using the font of td.
+// Character codes without Unicode mapping are represented by U+FFFD.
+func (td *TextDecoder) Decode(b []byte) (string, error) {
+
+	if td.toUnicode == nil && td.composite {
+		if td.cmap == nil {
+			// eg. Identity-H without ToUnicode.
+			return strings.Repeat(string(rune(0xFFFD)), (len(b)+1)/2), nil
+		}
+		return td.cmap(b)
+	}
+
+	defLen := 1
+	if td.composite {
+		defLen = 2
+	}
+
+	var sb strings.Builder
+
+	for len(b) > 0 {
+		n := defLen
+		if td.toUnicode != nil {
+			n = td.toUnicode.codeLength(b, defLen)
+		}
+		if n > len(b) {
+			n = len(b)
+		}
+		sb.WriteString(td.decodeCode(b[:n]))
+		b = b[n:]
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"testing"
+)
+
+const testToUnicodeCMap = `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+/CMapName /Adobe-Identity-UCS def
+/CMapType 2 def
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+2 beginbfchar
+<0003> <0020>
+<0024> <00660069>
+endbfchar
+2 beginbfrange
+<0010> <0012> <0041>
+<0020> <0021> [<03B1> <D835DC00>]
+endbfrange
+endcmap
+CMapName currentdict /CMap defineresource pop
+end
+end`
+
+func doTestTextDecoder(t *testing.T, xRefTable *XRefTable, fontDict Dict, b []byte, want string) {
+	t.Helper()
+	td, err := NewTextDecoder(xRefTable, fontDict)
+	if err != nil {
+		t.Fatalf("NewTextDecoder: %v\n", err)
+	}
+	got, err := td.Decode(b)
+	if err != nil {
+		t.Fatalf("Decode: %v\n", err)
+	}
+	if got != want {
+		t.Errorf("Decode %s: want %q, got %q\n", fontDict, want, got)
+	}
+}
+
+func TestTextDecoder(t *testing.T) {
+
+	xRefTable, err := createXRefTableWithRootDict()
+	if err != nil {
+		t.Fatalf("createXRefTableWithRootDict: %v\n", err)
+	}
+
+	font := func(subtype, baseFont string, enc Object) Dict {
+		d := Dict(map[string]Object{"Type": Name("Font"), "Subtype": Name(subtype), "BaseFont": Name(baseFont)})
+		if enc != nil {
+			d["Encoding"] = enc
+		}
+		return d
+	}
+
+	// Simple fonts
+	doTestTextDecoder(t, xRefTable, font("Type1", "Helvetica", Name("WinAnsiEncoding")), []byte("Euro \x80"), "Euro €")
+	doTestTextDecoder(t, xRefTable, font("Type1", "Times-Roman", nil), []byte("it`s"), "it‘s")
+	doTestTextDecoder(t, xRefTable, font("Type1", "Symbol", nil), []byte("abg \xa5"), "αβγ ∞")
+	doTestTextDecoder(t, xRefTable, font("Type1", "ABCDEF+Symbol", nil), []byte("p"), "π")
+	doTestTextDecoder(t, xRefTable, font("Type1", "ZapfDingbats", nil), []byte("34H\xac"), "✓✔★①")
+
+	diffs := Dict(map[string]Object{
+		"BaseEncoding": Name("WinAnsiEncoding"),
+		"Differences":  Array{Integer(65), Name("alpha"), Name("uni263A"), Integer(100), Name("fi")},
+	})
+	doTestTextDecoder(t, xRefTable, font("Type1", "Custom", diffs), []byte("ABCd"), "α☺Cﬁ")
+
+	// Predefined CMaps
+	doTestTextDecoder(t, xRefTable, font("Type0", "Ryumin", Name("UniJIS-UCS2-H")), []byte{0x65, 0xE5, 0x67, 0x2C}, "日本")
+	doTestTextDecoder(t, xRefTable, font("Type0", "Ryumin", Name("90ms-RKSJ-V")), []byte{0x93, 0xFA, 0x96, 0x7B, 0x41}, "日本A")
+	doTestTextDecoder(t, xRefTable, font("Type0", "STSong", Name("GBK-EUC-H")), []byte{0xD6, 0xD0, 0xCE, 0xC4}, "中文")
+	doTestTextDecoder(t, xRefTable, font("Type0", "MSung", Name("ETen-B5-H")), []byte{0xA4, 0xA4, 0xA4, 0xE5}, "中文")
+	doTestTextDecoder(t, xRefTable, font("Type0", "HYGoThic", Name("KSC-EUC-H")), []byte{0xC7, 0xD1, 0xB1, 0xDB}, "한글")
+	doTestTextDecoder(t, xRefTable, font("Type0", "Ryumin", Name("Identity-H")), []byte{0x00, 0x10, 0x00, 0x11}, "��")
+
+	// ToUnicode CMap
+	sd, err := xRefTable.NewStreamDictForBuf([]byte(testToUnicodeCMap))
+	if err != nil {
+		t.Fatalf("NewStreamDictForBuf: %v\n", err)
+	}
+	ir, err := xRefTable.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatalf("IndRefForNewObject: %v\n", err)
+	}
+	d := font("Type0", "Arial", Name("Identity-H"))
+	d["ToUnicode"] = *ir
+	doTestTextDecoder(t, xRefTable, d, []byte{0x00, 0x10, 0x00, 0x12, 0x00, 0x03, 0x00, 0x24, 0x00, 0x20, 0x00, 0x21, 0x00, 0x99}, "AC fiα𝐀�")
+
+	if !IsPredefinedCMap("UniGB-UCS2-V") || IsPredefinedCMap("Unknown-H") {
+		t.Errorf("IsPredefinedCMap: unexpected result\n")
+	}
+}
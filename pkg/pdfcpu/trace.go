@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import "fmt"
+
+// TraceEventType represents a parser decision.
+type TraceEventType int
+
+// The parser decisions being traced.
+const (
+	TraceStartXRef     TraceEventType = iota // Offset of the last xref section located via startxref.
+	TraceXRefSection                         // Xref section found.
+	TraceXRefStream                          // Xref stream found.
+	TraceTrailer                             // Trailer dict parsed.
+	TraceXRefRepair                          // Xref section found at a shifted offset.
+	TraceXRefRebuild                         // Xref table reconstructed by scanning the whole file.
+	TraceObject                              // Indirect object parsed.
+	TraceObjNrMismatch                       // Object header does not match the xref table entry.
+	TraceObjectStream                        // Object stream decoded.
+	TraceStreamLength                        // Stream length corrected.
+)
+
+func (t TraceEventType) String() string {
+	switch t {
+	case TraceStartXRef:
+		return "startxref"
+	case TraceXRefSection:
+		return "xref"
+	case TraceXRefStream:
+		return "xrefstream"
+	case TraceTrailer:
+		return "trailer"
+	case TraceXRefRepair:
+		return "xrefrepair"
+	case TraceXRefRebuild:
+		return "xrefrebuild"
+	case TraceObject:
+		return "obj"
+	case TraceObjNrMismatch:
+		return "objnrmismatch"
+	case TraceObjectStream:
+		return "objstm"
+	case TraceStreamLength:
+		return "streamlength"
+	}
+	return "?"
+}
+
+// TraceEvent represents a single parser decision.
+type TraceEvent struct {
+	Type   TraceEventType
+	Offset int64  // File offset, -1 if not applicable.
+	ObjNr  int    // Object number, 0 if not applicable.
+	Msg    string // Details.
+}
+
+func (e TraceEvent) String() string {
+	s := fmt.Sprintf("%-13s offset=%d", e.Type, e.Offset)
+	if e.ObjNr > 0 {
+		s += fmt.Sprintf(" obj#%d", e.ObjNr)
+	}
+	if e.Msg != "" {
+		s += " " + e.Msg
+	}
+	return s
+}
+
+func (ctx *Context) tracing() bool {
+	return ctx.Configuration != nil && ctx.TraceParser && ctx.Read != nil
+}
+
+// trace records a parser decision if enabled.
+func (ctx *Context) trace(t TraceEventType, offset int64, objNr int, format string, args ...interface{}) {
+	if !ctx.tracing() {
+		return
+	}
+	ctx.Read.Trace = append(ctx.Read.Trace, TraceEvent{Type: t, Offset: offset, ObjNr: objNr, Msg: fmt.Sprintf(format, args...)})
+}
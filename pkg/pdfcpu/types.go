@@ -112,9 +112,9 @@ func (f Float) String() string {
 }
 
 // PDFString returns a string representation as found in and written to a PDF file.
+// This is the shortest representation reading back as f, see Configuration.FloatPrecision for other options.
 func (f Float) PDFString() string {
-	// The max precision encountered so far has been 12 (fontType3 fontmatrix components).
-	return strconv.FormatFloat(f.Value(), 'f', 12, 64)
+	return FormatFloat(f.Value(), 0, false)
 }
 
 // Value returns a float64 value for this PDF object.
@@ -17,6 +17,7 @@ limitations under the License.
 package pdfcpu
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"unicode/utf16"
@@ -65,7 +66,7 @@ func decodeUTF16String(b []byte) (string, error) {
 
 		val := (uint16(b[i]) << 8) + uint16(b[i+1])
 
-		if val <= 0xD7FF || val > 0xE000 && val <= 0xFFFF {
+		if val <= 0xD7FF || val >= 0xE000 {
 			// Basic Multilingual Plane
 			u16 = append(u16, val)
 			i += 2
@@ -118,25 +119,70 @@ func encodeUTF16String(s string) string {
 	return string(bb)
 }
 
-// StringLiteralToString returns the best possible string rep for a string literal.
-func StringLiteralToString(sl StringLiteral) (string, error) {
-	bb, err := Unescape(sl.Value())
+// textLiteral returns s as string literal using UTF-16BE encoding for non ASCII text.
+// PDFDocEncoding is avoided for non ASCII text since the result might be mistaken for UTF-8 on read.
+func textLiteral(s string) (StringLiteral, error) {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			s = encodeUTF16String(s)
+			break
+		}
+	}
+	s1, err := Escape(s)
 	if err != nil {
 		return "", err
 	}
+	return StringLiteral(*s1), nil
+}
+
+// pdfDocEncoding holds the code points of PDFDocEncoding differing from ISO Latin-1.
+var pdfDocEncoding = map[byte]rune{
+	0x18: 0x02D8, 0x19: 0x02C7, 0x1A: 0x02C6, 0x1B: 0x02D9, 0x1C: 0x02DD, 0x1D: 0x02DB, 0x1E: 0x02DA, 0x1F: 0x02DC,
+	0x80: 0x2022, 0x81: 0x2020, 0x82: 0x2021, 0x83: 0x2026, 0x84: 0x2014, 0x85: 0x2013, 0x86: 0x0192, 0x87: 0x2044,
+	0x88: 0x2039, 0x89: 0x203A, 0x8A: 0x2212, 0x8B: 0x2030, 0x8C: 0x201E, 0x8D: 0x201C, 0x8E: 0x201D, 0x8F: 0x2018,
+	0x90: 0x2019, 0x91: 0x201A, 0x92: 0x2122, 0x93: 0xFB01, 0x94: 0xFB02, 0x95: 0x0141, 0x96: 0x0152, 0x97: 0x0160,
+	0x98: 0x0178, 0x99: 0x017D, 0x9A: 0x0131, 0x9B: 0x0142, 0x9C: 0x0153, 0x9D: 0x0161, 0x9E: 0x017E, 0xA0: 0x20AC,
+}
+
+// PDFDocEncodingToUTF8 converts PDFDocEncoding to UTF-8.
+func PDFDocEncodingToUTF8(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		r, ok := pdfDocEncoding[s[i]]
+		if !ok {
+			r = rune(s[i])
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// decodeText decodes the bytes of a text string.
+// Text strings are encoded using UTF-16BE or UTF-8 (PDF 2.0), both starting with a byte order mark, or PDFDocEncoding.
+// Text without byte order mark being valid UTF-8 is taken as is since UTF-8 is commonly used by producers.
+func decodeText(b []byte) (string, error) {
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		return decodeUTF16String(b)
+	}
 
-	s1 := string(bb)
+	if bytes.HasPrefix(b, []byte{0xEF, 0xBB, 0xBF}) {
+		return string(b[3:]), nil
+	}
 
-	// Check for Big Endian UTF-16.
-	if IsStringUTF16BE(s1) {
-		return DecodeUTF16String(s1)
+	s := string(b)
+	if !utf8.ValidString(s) {
+		s = PDFDocEncodingToUTF8(s)
 	}
+	return s, nil
+}
 
-	// if no acceptable UTF16 encoding found, ensure utf8 encoding.
-	if !utf8.ValidString(s1) {
-		s1 = CP1252ToUTF8(s1)
+// StringLiteralToString returns the best possible string rep for a string literal.
+func StringLiteralToString(sl StringLiteral) (string, error) {
+	bb, err := Unescape(sl.Value())
+	if err != nil {
+		return "", err
 	}
-	return s1, nil
+	return decodeText(bb)
 }
 
 // HexLiteralToString returns a possibly UTF16 encoded string for a hex string.
@@ -146,12 +192,5 @@ func HexLiteralToString(hl HexLiteral) (string, error) {
 	if err != nil {
 		return "", err
 	}
-
-	// Check for Big Endian UTF-16.
-	if IsUTF16BE(b) {
-		return decodeUTF16String(b)
-	}
-
-	// if no acceptable UTF16 encoding found, just return decoded hexstring.
-	return string(b), nil
+	return decodeText(b)
 }
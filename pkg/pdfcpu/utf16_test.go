@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"testing"
+)
+
+func TestTextLiteralRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"Chapter 1 (Overview)",
+		"Grüße",
+		"第一章 概要",
+		"الفصل الأول: مقدمة",
+		"日本語の見出し \\ (括弧)",
+		"Private use \ue000\uffee",
+		"Emoji 😀",
+	} {
+		sl, err := textLiteral(s)
+		if err != nil {
+			t.Fatalf("%s: %v\n", s, err)
+		}
+		got, err := StringLiteralToString(sl)
+		if err != nil {
+			t.Fatalf("%s: %v\n", s, err)
+		}
+		if got != s {
+			t.Errorf("want %q, got %q\n", s, got)
+		}
+	}
+}
+
+func TestDecodeText(t *testing.T) {
+	for _, tt := range []struct {
+		in   []byte
+		want string
+	}{
+		{[]byte("plain"), "plain"},
+		{[]byte{0xFE, 0xFF, 0x4E, 0x2D, 0x65, 0x87}, "中文"},
+		{[]byte{0xEF, 0xBB, 0xBF, 0xD8, 0xB9, 0xD8, 0xB1, 0xD8, 0xA8, 0xD9, 0x8A}, "عربي"},
+		{[]byte("中文"), "中文"},
+		{[]byte{0x80, ' ', 0x92, ' ', 0xA0, ' ', 0xE4}, "• ™ € ä"},
+	} {
+		got, err := decodeText(tt.in)
+		if err != nil {
+			t.Fatalf("%x: %v\n", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("%x: want %q, got %q\n", tt.in, tt.want, got)
+		}
+	}
+}
@@ -89,7 +89,7 @@ func validateAppearanceDict(xRefTable *pdf.XRefTable, o pdf.Object) error {
 	// Normal Appearance
 	o, ok := d.Find("N")
 	if !ok {
-		if xRefTable.ValidationMode == pdf.ValidationStrict {
+		if xRefTable.StrictValidation() {
 			return errors.New("pdfcpu: validateAppearanceDict: missing required entry \"N\"")
 		}
 	} else {
@@ -19,7 +19,6 @@ package validate
 import (
 	"fmt"
 
-	"github.com/pdfcpu/pdfcpu/pkg/log"
 	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pkg/errors"
 )
@@ -1556,7 +1555,7 @@ func validatePageAnnotations(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 
 		if ir, ok = v.(pdf.IndirectRef); ok {
 			hasIndRef = true
-			log.Validate.Printf("processing annotDict %d\n", ir.ObjectNumber)
+			xRefTable.Log.Validate().Printf("processing annotDict %d\n", ir.ObjectNumber)
 			annotsDict, err = xRefTable.DereferenceDict(ir)
 			if err != nil || annotsDict == nil {
 				return errors.New("pdfcpu: validatePageAnnotations: corrupted annotation dict")
@@ -1603,7 +1602,7 @@ func validatePagesAnnotations(xRefTable *pdf.XRefTable, d pdf.Dict, curPage int)
 		return curPage, errors.New("pdfcpu: validatePagesAnnotations: missing \"Count\"")
 	}
 
-	log.Validate.Printf("validatePagesAnnotations: This page node has %d pages\n", *pageCount)
+	xRefTable.Log.Validate().Printf("validatePagesAnnotations: This page node has %d pages\n", *pageCount)
 
 	// Iterate over page tree.
 	kidsArray := d.ArrayEntry("Kids")
@@ -1611,7 +1610,7 @@ func validatePagesAnnotations(xRefTable *pdf.XRefTable, d pdf.Dict, curPage int)
 	for _, v := range kidsArray {
 
 		if v == nil {
-			log.Validate.Println("validatePagesAnnotations: kid is nil")
+			xRefTable.Log.Validate().Println("validatePagesAnnotations: kid is nil")
 			continue
 		}
 
@@ -1641,6 +1640,7 @@ func validatePagesAnnotations(xRefTable *pdf.XRefTable, d pdf.Dict, curPage int)
 			curPage++
 			xRefTable.CurPage = curPage
 			err = validatePageAnnotations(xRefTable, d)
+			err = collect(xRefTable, fmt.Sprintf("Root/Pages/%d/Annots", curPage), pdf.RuleAnnot, err)
 			if err != nil {
 				return curPage, err
 			}
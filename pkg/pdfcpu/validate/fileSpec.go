@@ -140,7 +140,16 @@ func validateEmbeddedFileStreamDict(xRefTable *pdf.XRefTable, sd *pdf.StreamDict
 	// Params, optional, dict
 	// parameter dict containing additional file-specific information.
 	if o, found := sd.Dict.Find("Params"); found && o != nil {
-		err = validateEmbeddedFileStreamParameterDict(xRefTable, o)
+		if err = validateEmbeddedFileStreamParameterDict(xRefTable, o); err != nil {
+			return err
+		}
+		// Verify Size and CheckSum against the embedded file.
+		if err = xRefTable.CheckEmbeddedFileParams(sd); err != nil {
+			if xRefTable.ValidationMode == pdf.ValidationRelaxed {
+				xRefTable.Log.Validate().Printf("validateEmbeddedFileStreamDict: ignoring: %v\n", err)
+				return nil
+			}
+		}
 	}
 
 	return err
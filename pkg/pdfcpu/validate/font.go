@@ -17,7 +17,6 @@ limitations under the License.
 package validate
 
 import (
-	"github.com/pdfcpu/pdfcpu/pkg/log"
 	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pkg/errors"
 )
@@ -111,7 +110,7 @@ func validateFontDescriptorType(xRefTable *pdf.XRefTable, d pdf.Dict) (err error
 	if dictType == nil {
 
 		if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-			log.Validate.Println("validateFontDescriptor: missing entry \"Type\"")
+			xRefTable.Log.Validate().Println("validateFontDescriptor: missing entry \"Type\"")
 		} else {
 			return errors.New("pdfcpu: validateFontDescriptor: missing entry \"Type\"")
 		}
@@ -690,7 +689,7 @@ func validateType1FontDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 
 	if !required && fc != nil {
 		// For the standard 14 fonts, the entries FirstChar, LastChar, Widths and FontDescriptor shall either all be present or all be absent.
-		if xRefTable.ValidationMode == pdf.ValidationStrict {
+		if xRefTable.StrictValidation() {
 			required = true
 		} else {
 			// relaxed: do nothing
@@ -19,7 +19,6 @@ package validate
 import (
 	"unicode/utf8"
 
-	"github.com/pdfcpu/pdfcpu/pkg/log"
 	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pkg/errors"
 )
@@ -36,7 +35,7 @@ func handleDefault(xRefTable *pdf.XRefTable, o pdf.Object) (string, error) {
 		return s, nil
 	}
 
-	if xRefTable.ValidationMode == pdf.ValidationStrict {
+	if xRefTable.StrictValidation() {
 		return "", err
 	}
 
@@ -177,7 +176,7 @@ func validateDocumentInfoObject(xRefTable *pdf.XRefTable) error {
 		return nil
 	}
 
-	log.Validate.Println("*** validateDocumentInfoObject begin ***")
+	xRefTable.Log.Validate().Println("*** validateDocumentInfoObject begin ***")
 
 	hasModDate, err := validateDocumentInfoDict(xRefTable, *xRefTable.Info)
 	if err != nil {
@@ -193,7 +192,7 @@ func validateDocumentInfoObject(xRefTable *pdf.XRefTable) error {
 		return errors.Errorf("validateDocumentInfoObject: missing required entry \"ModDate\"")
 	}
 
-	log.Validate.Println("*** validateDocumentInfoObject end ***")
+	xRefTable.Log.Validate().Println("*** validateDocumentInfoObject end ***")
 
 	return nil
 }
@@ -20,7 +20,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/pdfcpu/pdfcpu/pkg/log"
 	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pkg/errors"
 )
@@ -67,7 +66,7 @@ func validateEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName str
 
 func validateArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(pdf.Array) bool) (pdf.Array, error) {
 
-	log.Validate.Printf("validateArrayEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateArrayEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -82,7 +81,7 @@ func validateArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryNam
 		if required {
 			return nil, errors.Errorf("validateArrayEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateArrayEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateArrayEntry end: optional entry %s is nil\n", entryName)
 		return nil, nil
 	}
 
@@ -102,14 +101,14 @@ func validateArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryNam
 		return nil, errors.Errorf("validateArrayEntry: dict=%s entry=%s invalid dict entry", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateArrayEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateArrayEntry end: entry=%s\n", entryName)
 
 	return a, nil
 }
 
 func validateBooleanEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(bool) bool) (*pdf.Boolean, error) {
 
-	log.Validate.Printf("validateBooleanEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateBooleanEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -124,7 +123,7 @@ func validateBooleanEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryN
 		if required {
 			return nil, errors.Errorf("validateBooleanEntry: dict=%s required entry=%s missing", dictName, entryName)
 		}
-		log.Validate.Printf("validateBooleanEntry end: entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateBooleanEntry end: entry %s is nil\n", entryName)
 		return nil, nil
 	}
 
@@ -144,14 +143,14 @@ func validateBooleanEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryN
 		return nil, errors.Errorf("validateBooleanEntry: dict=%s entry=%s invalid name dict entry", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateBooleanEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateBooleanEntry end: entry=%s\n", entryName)
 
 	return &b, nil
 }
 
 func validateBooleanArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(pdf.Array) bool) (pdf.Array, error) {
 
-	log.Validate.Printf("validateBooleanArrayEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateBooleanArrayEntry begin: entry=%s\n", entryName)
 
 	a, err := validateArrayEntry(xRefTable, d, dictName, entryName, required, sinceVersion, validate)
 	if err != nil || a == nil {
@@ -175,11 +174,23 @@ func validateBooleanArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, e
 
 	}
 
-	log.Validate.Printf("validateBooleanArrayEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateBooleanArrayEntry end: entry=%s\n", entryName)
 
 	return a, nil
 }
 
+// dateTime parses s and records a warning for dates only acceptable in relaxed mode.
+func dateTime(xRefTable *pdf.XRefTable, s string) (time.Time, bool) {
+	relaxed := xRefTable.ValidationMode == pdf.ValidationRelaxed
+	t, ok := pdf.DateTime(s, relaxed)
+	if ok && relaxed {
+		if _, ok := pdf.DateTime(s, false); !ok {
+			xRefTable.Warn("invalid date: <%s>", s)
+		}
+	}
+	return t, ok
+}
+
 func validateDateObject(xRefTable *pdf.XRefTable, o pdf.Object, sinceVersion pdf.Version) (string, error) {
 	s, err := xRefTable.DereferenceStringOrHexLiteral(o, sinceVersion, nil)
 	//sl, err := xRefTable.DereferenceStringLiteral(o, sinceVersion, nil)
@@ -191,7 +202,7 @@ func validateDateObject(xRefTable *pdf.XRefTable, o pdf.Object, sinceVersion pdf
 		return s, nil
 	}
 
-	if _, ok := pdf.DateTime(s, xRefTable.ValidationMode == pdf.ValidationRelaxed); !ok {
+	if _, ok := dateTime(xRefTable, s); !ok {
 		return "", errors.Errorf("pdfcpu: validateDateObject: <%s> invalid date", s)
 	}
 
@@ -200,7 +211,7 @@ func validateDateObject(xRefTable *pdf.XRefTable, o pdf.Object, sinceVersion pdf
 
 func validateDateEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version) (*time.Time, error) {
 
-	log.Validate.Printf("validateDateEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateDateEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -215,23 +226,23 @@ func validateDateEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName
 		if required {
 			return nil, errors.Errorf("validateDateEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateDateEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateDateEntry end: optional entry %s is nil\n", entryName)
 		return nil, nil
 	}
 
-	time, ok := pdf.DateTime(s, xRefTable.ValidationMode == pdf.ValidationRelaxed)
+	time, ok := dateTime(xRefTable, s)
 	if !ok {
 		return nil, errors.Errorf("pdfcpu: validateDateEntry: <%s> invalid date", s)
 	}
 
-	log.Validate.Printf("validateDateEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateDateEntry end: entry=%s\n", entryName)
 
 	return &time, nil
 }
 
 func validateDictEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(pdf.Dict) bool) (pdf.Dict, error) {
 
-	log.Validate.Printf("validateDictEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateDictEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -246,7 +257,7 @@ func validateDictEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName
 		if required {
 			return nil, errors.Errorf("validateDictEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateDictEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateDictEntry end: optional entry %s is nil\n", entryName)
 		return nil, nil
 	}
 
@@ -266,14 +277,14 @@ func validateDictEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName
 		return nil, errors.Errorf("validateDictEntry: dict=%s entry=%s invalid dict entry", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateDictEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateDictEntry end: entry=%s\n", entryName)
 
 	return d, nil
 }
 
 func validateFloat(xRefTable *pdf.XRefTable, o pdf.Object, validate func(float64) bool) (*pdf.Float, error) {
 
-	log.Validate.Println("validateFloat begin")
+	xRefTable.Log.Validate().Println("validateFloat begin")
 
 	o, err := xRefTable.Dereference(o)
 	if err != nil {
@@ -293,14 +304,14 @@ func validateFloat(xRefTable *pdf.XRefTable, o pdf.Object, validate func(float64
 		return nil, errors.Errorf("pdfcpu: validateFloat: invalid float: %s\n", f)
 	}
 
-	log.Validate.Println("validateFloat end")
+	xRefTable.Log.Validate().Println("validateFloat end")
 
 	return &f, nil
 }
 
 func validateFloatEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(float64) bool) (*pdf.Float, error) {
 
-	log.Validate.Printf("validateFloatEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateFloatEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -315,7 +326,7 @@ func validateFloatEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryNam
 		if required {
 			return nil, errors.Errorf("pdfcpu: validateFloatEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateFloatEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateFloatEntry end: optional entry %s is nil\n", entryName)
 		return nil, nil
 	}
 
@@ -335,14 +346,14 @@ func validateFloatEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryNam
 		return nil, errors.Errorf("pdfcpu: validateFloatEntry: dict=%s entry=%s invalid dict entry", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateFloatEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateFloatEntry end: entry=%s\n", entryName)
 
 	return &f, nil
 }
 
 func validateFunctionEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version) error {
 
-	log.Validate.Printf("validateFunctionEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateFunctionEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -359,14 +370,14 @@ func validateFunctionEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entry
 		return err
 	}
 
-	log.Validate.Printf("validateFunctionEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateFunctionEntry end: entry=%s\n", entryName)
 
 	return nil
 }
 
 func validateFunctionArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(pdf.Array) bool) (pdf.Array, error) {
 
-	log.Validate.Printf("validateFunctionArrayEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateFunctionArrayEntry begin: entry=%s\n", entryName)
 
 	a, err := validateArrayEntry(xRefTable, d, dictName, entryName, required, sinceVersion, validate)
 	if err != nil || a == nil {
@@ -380,14 +391,14 @@ func validateFunctionArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName,
 		}
 	}
 
-	log.Validate.Printf("validateFunctionArrayEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateFunctionArrayEntry end: entry=%s\n", entryName)
 
 	return a, nil
 }
 
 func validateFunctionOrArrayOfFunctionsEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version) error {
 
-	log.Validate.Printf("validateFunctionOrArrayOfFunctionsEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateFunctionOrArrayOfFunctionsEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -402,7 +413,7 @@ func validateFunctionOrArrayOfFunctionsEntry(xRefTable *pdf.XRefTable, d pdf.Dic
 		if required {
 			return errors.Errorf("pdfcpu: validateFunctionOrArrayOfFunctionsEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateFunctionOrArrayOfFunctionsEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateFunctionOrArrayOfFunctionsEntry end: optional entry %s is nil\n", entryName)
 		return nil
 	}
 
@@ -436,14 +447,14 @@ func validateFunctionOrArrayOfFunctionsEntry(xRefTable *pdf.XRefTable, d pdf.Dic
 		return err
 	}
 
-	log.Validate.Printf("validateFunctionOrArrayOfFunctionsEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateFunctionOrArrayOfFunctionsEntry end: entry=%s\n", entryName)
 
 	return nil
 }
 
 func validateIndRefEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version) (*pdf.IndirectRef, error) {
 
-	log.Validate.Printf("validateIndRefEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateIndRefEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -461,14 +472,14 @@ func validateIndRefEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryNa
 		return nil, err
 	}
 
-	log.Validate.Printf("validateIndRefEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateIndRefEntry end: entry=%s\n", entryName)
 
 	return &ir, nil
 }
 
 func validateIndRefArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(pdf.Array) bool) (pdf.Array, error) {
 
-	log.Validate.Printf("validateIndRefArrayEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateIndRefArrayEntry begin: entry=%s\n", entryName)
 
 	a, err := validateArrayEntry(xRefTable, d, dictName, entryName, required, sinceVersion, validate)
 	if err != nil || a == nil {
@@ -482,14 +493,14 @@ func validateIndRefArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, en
 		}
 	}
 
-	log.Validate.Printf("validateIndRefArrayEntry end: entry=%s \n", entryName)
+	xRefTable.Log.Validate().Printf("validateIndRefArrayEntry end: entry=%s \n", entryName)
 
 	return a, nil
 }
 
 func validateInteger(xRefTable *pdf.XRefTable, o pdf.Object, validate func(int) bool) (*pdf.Integer, error) {
 
-	log.Validate.Println("validateInteger begin")
+	xRefTable.Log.Validate().Println("validateInteger begin")
 
 	o, err := xRefTable.Dereference(o)
 	if err != nil {
@@ -510,14 +521,14 @@ func validateInteger(xRefTable *pdf.XRefTable, o pdf.Object, validate func(int)
 		return nil, errors.Errorf("pdfcpu: validateInteger: invalid integer: %s\n", i)
 	}
 
-	log.Validate.Println("validateInteger end")
+	xRefTable.Log.Validate().Println("validateInteger end")
 
 	return &i, nil
 }
 
 func validateIntegerEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(int) bool) (*pdf.Integer, error) {
 
-	log.Validate.Printf("validateIntegerEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateIntegerEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -532,7 +543,7 @@ func validateIntegerEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryN
 		if required {
 			return nil, errors.Errorf("pdfcpu: validateIntegerEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateIntegerEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateIntegerEntry end: optional entry %s is nil\n", entryName)
 		return nil, nil
 	}
 
@@ -552,14 +563,14 @@ func validateIntegerEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryN
 		return nil, errors.Errorf("pdfcpu: validateIntegerEntry: dict=%s entry=%s invalid dict entry", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateIntegerEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateIntegerEntry end: entry=%s\n", entryName)
 
 	return &i, nil
 }
 
 func validateIntegerArray(xRefTable *pdf.XRefTable, o pdf.Object) (pdf.Array, error) {
 
-	log.Validate.Println("validateIntegerArray begin")
+	xRefTable.Log.Validate().Println("validateIntegerArray begin")
 
 	a, err := xRefTable.DereferenceArray(o)
 	if err != nil || a == nil {
@@ -588,14 +599,14 @@ func validateIntegerArray(xRefTable *pdf.XRefTable, o pdf.Object) (pdf.Array, er
 
 	}
 
-	log.Validate.Println("validateIntegerArray end")
+	xRefTable.Log.Validate().Println("validateIntegerArray end")
 
 	return a, nil
 }
 
 func validateIntegerArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(pdf.Array) bool) (pdf.Array, error) {
 
-	log.Validate.Printf("validateIntegerArrayEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateIntegerArrayEntry begin: entry=%s\n", entryName)
 
 	a, err := validateArrayEntry(xRefTable, d, dictName, entryName, required, sinceVersion, validate)
 	if err != nil || a == nil {
@@ -620,14 +631,14 @@ func validateIntegerArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, e
 
 	}
 
-	log.Validate.Printf("validateIntegerArrayEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateIntegerArrayEntry end: entry=%s\n", entryName)
 
 	return a, nil
 }
 
 func validateName(xRefTable *pdf.XRefTable, o pdf.Object, validate func(string) bool) (*pdf.Name, error) {
 
-	log.Validate.Println("validateName begin")
+	xRefTable.Log.Validate().Println("validateName begin")
 
 	o, err := xRefTable.Dereference(o)
 	if err != nil {
@@ -647,14 +658,14 @@ func validateName(xRefTable *pdf.XRefTable, o pdf.Object, validate func(string)
 		return nil, errors.Errorf("pdfcpu: validateName: invalid name: %s\n", name)
 	}
 
-	log.Validate.Println("validateName end")
+	xRefTable.Log.Validate().Println("validateName end")
 
 	return &name, nil
 }
 
 func validateNameEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(string) bool) (*pdf.Name, error) {
 
-	log.Validate.Printf("validateNameEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateNameEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -669,7 +680,7 @@ func validateNameEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName
 		if required {
 			return nil, errors.Errorf("pdfcpu: validateNameEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateNameEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateNameEntry end: optional entry %s is nil\n", entryName)
 		return nil, nil
 	}
 
@@ -690,14 +701,14 @@ func validateNameEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName
 		return nil, errors.Errorf("pdfcpu: validateNameEntry: dict=%s entry=%s invalid dict entry: %s", dictName, entryName, name.Value())
 	}
 
-	log.Validate.Printf("validateNameEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateNameEntry end: entry=%s\n", entryName)
 
 	return &name, nil
 }
 
 func validateNameArray(xRefTable *pdf.XRefTable, o pdf.Object) (pdf.Array, error) {
 
-	log.Validate.Println("validateNameArray begin")
+	xRefTable.Log.Validate().Println("validateNameArray begin")
 
 	a, err := xRefTable.DereferenceArray(o)
 	if err != nil || a == nil {
@@ -722,14 +733,14 @@ func validateNameArray(xRefTable *pdf.XRefTable, o pdf.Object) (pdf.Array, error
 
 	}
 
-	log.Validate.Println("validateNameArray end")
+	xRefTable.Log.Validate().Println("validateNameArray end")
 
 	return a, nil
 }
 
 func validateNameArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(a pdf.Array) bool) (pdf.Array, error) {
 
-	log.Validate.Printf("validateNameArrayEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateNameArrayEntry begin: entry=%s\n", entryName)
 
 	a, err := validateArrayEntry(xRefTable, d, dictName, entryName, required, sinceVersion, validate)
 	if err != nil || a == nil {
@@ -754,14 +765,14 @@ func validateNameArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entr
 
 	}
 
-	log.Validate.Printf("validateNameArrayEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateNameArrayEntry end: entry=%s\n", entryName)
 
 	return a, nil
 }
 
 func validateNumber(xRefTable *pdf.XRefTable, o pdf.Object) (pdf.Object, error) {
 
-	log.Validate.Println("validateNumber begin")
+	xRefTable.Log.Validate().Println("validateNumber begin")
 
 	o, err := xRefTable.Dereference(o)
 	if err != nil {
@@ -784,14 +795,14 @@ func validateNumber(xRefTable *pdf.XRefTable, o pdf.Object) (pdf.Object, error)
 
 	}
 
-	log.Validate.Println("validateNumber end ")
+	xRefTable.Log.Validate().Println("validateNumber end ")
 
 	return o, nil
 }
 
 func validateNumberEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(f float64) bool) (pdf.Object, error) {
 
-	log.Validate.Printf("validateNumberEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateNumberEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -825,14 +836,14 @@ func validateNumberEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryNa
 		return nil, errors.Errorf("pdfcpu: validateFloatEntry: dict=%s entry=%s invalid dict entry", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateNumberEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateNumberEntry end: entry=%s\n", entryName)
 
 	return o, nil
 }
 
 func validateNumberArray(xRefTable *pdf.XRefTable, o pdf.Object) (pdf.Array, error) {
 
-	log.Validate.Println("validateNumberArray begin")
+	xRefTable.Log.Validate().Println("validateNumberArray begin")
 
 	a, err := xRefTable.DereferenceArray(o)
 	if err != nil || a == nil {
@@ -864,14 +875,14 @@ func validateNumberArray(xRefTable *pdf.XRefTable, o pdf.Object) (pdf.Array, err
 
 	}
 
-	log.Validate.Println("validateNumberArray end")
+	xRefTable.Log.Validate().Println("validateNumberArray end")
 
 	return a, err
 }
 
 func validateNumberArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(pdf.Array) bool) (pdf.Array, error) {
 
-	log.Validate.Printf("validateNumberArrayEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateNumberArrayEntry begin: entry=%s\n", entryName)
 
 	a, err := validateArrayEntry(xRefTable, d, dictName, entryName, required, sinceVersion, validate)
 	if err != nil || a == nil {
@@ -903,14 +914,14 @@ func validateNumberArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, en
 
 	}
 
-	log.Validate.Printf("validateNumberArrayEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateNumberArrayEntry end: entry=%s\n", entryName)
 
 	return a, nil
 }
 
 func validateRectangleEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(pdf.Array) bool) (pdf.Array, error) {
 
-	log.Validate.Printf("validateRectangleEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateRectangleEntry begin: entry=%s\n", entryName)
 
 	a, err := validateNumberArrayEntry(xRefTable, d, dictName, entryName, required, sinceVersion, func(a pdf.Array) bool { return len(a) == 4 })
 	if err != nil || a == nil {
@@ -921,14 +932,14 @@ func validateRectangleEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entr
 		return nil, errors.Errorf("pdfcpu: validateRectangleEntry: dict=%s entry=%s invalid rectangle entry", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateRectangleEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateRectangleEntry end: entry=%s\n", entryName)
 
 	return a, nil
 }
 
 func validateStreamDict(xRefTable *pdf.XRefTable, o pdf.Object) (*pdf.StreamDict, error) {
 
-	log.Validate.Println("validateStreamDict begin")
+	xRefTable.Log.Validate().Println("validateStreamDict begin")
 
 	o, err := xRefTable.Dereference(o)
 	if err != nil {
@@ -943,14 +954,14 @@ func validateStreamDict(xRefTable *pdf.XRefTable, o pdf.Object) (*pdf.StreamDict
 		return nil, errors.New("pdfcpu: validateStreamDict: invalid type")
 	}
 
-	log.Validate.Println("validateStreamDict endobj")
+	xRefTable.Log.Validate().Println("validateStreamDict endobj")
 
 	return &sd, nil
 }
 
 func validateStreamDictEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(pdf.StreamDict) bool) (*pdf.StreamDict, error) {
 
-	log.Validate.Printf("validateStreamDictEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateStreamDictEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -973,7 +984,7 @@ func validateStreamDictEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, ent
 		if required {
 			return nil, errors.Errorf("pdfcpu: validateStreamDictEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateStreamDictEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateStreamDictEntry end: optional entry %s is nil\n", entryName)
 		return nil, nil
 	}
 
@@ -993,14 +1004,14 @@ func validateStreamDictEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, ent
 		return nil, errors.Errorf("pdfcpu: validateStreamDictEntry: dict=%s entry=%s invalid dict entry", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateStreamDictEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateStreamDictEntry end: entry=%s\n", entryName)
 
 	return sd, nil
 }
 
 func validateString(xRefTable *pdf.XRefTable, o pdf.Object, validate func(string) bool) (string, error) {
 
-	//log.Validate.Println("validateString begin")
+	//xRefTable.Log.Validate().Println("validateString begin")
 
 	o, err := xRefTable.Dereference(o)
 	if err != nil {
@@ -1033,14 +1044,14 @@ func validateString(xRefTable *pdf.XRefTable, o pdf.Object, validate func(string
 		return "", errors.Errorf("pdfcpu: validateString: %s invalid", s)
 	}
 
-	//log.Validate.Println("validateString end")
+	//xRefTable.Log.Validate().Println("validateString end")
 
 	return s, nil
 }
 
 func validateStringEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(string) bool) (*string, error) {
 
-	log.Validate.Printf("validateStringEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateStringEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -1055,7 +1066,7 @@ func validateStringEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryNa
 		if required {
 			return nil, errors.Errorf("pdfcpu: validateStringEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateStringEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateStringEntry end: optional entry %s is nil\n", entryName)
 		return nil, nil
 	}
 
@@ -1088,14 +1099,14 @@ func validateStringEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryNa
 		return nil, errors.Errorf("pdfcpu: validateStringEntry: dict=%s entry=%s invalid dict entry", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateStringEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateStringEntry end: entry=%s\n", entryName)
 
 	return &s, nil
 }
 
 func validateStringArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(pdf.Array) bool) (pdf.Array, error) {
 
-	log.Validate.Printf("validateStringArrayEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateStringArrayEntry begin: entry=%s\n", entryName)
 
 	a, err := validateArrayEntry(xRefTable, d, dictName, entryName, required, sinceVersion, validate)
 	if err != nil || a == nil {
@@ -1127,14 +1138,14 @@ func validateStringArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, en
 
 	}
 
-	log.Validate.Printf("validateStringArrayEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateStringArrayEntry end: entry=%s\n", entryName)
 
 	return a, nil
 }
 
 func validateArrayArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version, validate func(pdf.Array) bool) (pdf.Array, error) {
 
-	log.Validate.Printf("validateArrayArrayEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateArrayArrayEntry begin: entry=%s\n", entryName)
 
 	a, err := validateArrayEntry(xRefTable, d, dictName, entryName, required, sinceVersion, validate)
 	if err != nil || a == nil {
@@ -1163,14 +1174,14 @@ func validateArrayArrayEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, ent
 
 	}
 
-	log.Validate.Printf("validateArrayArrayEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateArrayArrayEntry end: entry=%s\n", entryName)
 
 	return a, nil
 }
 
 func validateStringOrStreamEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version) error {
 
-	log.Validate.Printf("validateStringOrStreamEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateStringOrStreamEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -1185,7 +1196,7 @@ func validateStringOrStreamEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName,
 		if required {
 			return errors.Errorf("pdfcpu: validateStringOrStreamEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateStringOrStreamEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateStringOrStreamEntry end: optional entry %s is nil\n", entryName)
 		return nil
 	}
 
@@ -1204,14 +1215,14 @@ func validateStringOrStreamEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName,
 		return errors.Errorf("pdfcpu: validateStringOrStreamEntry: dict=%s entry=%s invalid type", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateStringOrStreamEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateStringOrStreamEntry end: entry=%s\n", entryName)
 
 	return nil
 }
 
 func validateNameOrStringEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version) error {
 
-	log.Validate.Printf("validateNameOrStringEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateNameOrStringEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -1226,7 +1237,7 @@ func validateNameOrStringEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, e
 		if required {
 			return errors.Errorf("pdfcpu: validateNameOrStringEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateNameOrStringEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateNameOrStringEntry end: optional entry %s is nil\n", entryName)
 		return nil
 	}
 
@@ -1245,14 +1256,14 @@ func validateNameOrStringEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, e
 		return errors.Errorf("pdfcpu: validateNameOrStringEntry: dict=%s entry=%s invalid type", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateNameOrStringEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateNameOrStringEntry end: entry=%s\n", entryName)
 
 	return nil
 }
 
 func validateIntOrStringEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version) error {
 
-	log.Validate.Printf("validateIntOrStringEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateIntOrStringEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -1267,7 +1278,7 @@ func validateIntOrStringEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, en
 		if required {
 			return errors.Errorf("pdfcpu: validateIntOrStringEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateIntOrStringEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateIntOrStringEntry end: optional entry %s is nil\n", entryName)
 		return nil
 	}
 
@@ -1286,14 +1297,14 @@ func validateIntOrStringEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, en
 		return errors.Errorf("pdfcpu: validateIntOrStringEntry: dict=%s entry=%s invalid type", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateIntOrStringEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateIntOrStringEntry end: entry=%s\n", entryName)
 
 	return nil
 }
 
 func validateIntOrDictEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version) error {
 
-	log.Validate.Printf("validateIntOrDictEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateIntOrDictEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -1308,7 +1319,7 @@ func validateIntOrDictEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entr
 		if required {
 			return errors.Errorf("pdfcpu: validateIntOrDictEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateIntOrDictEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateIntOrDictEntry end: optional entry %s is nil\n", entryName)
 		return nil
 	}
 
@@ -1327,14 +1338,14 @@ func validateIntOrDictEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entr
 		return errors.Errorf("pdfcpu: validateIntOrDictEntry: dict=%s entry=%s invalid type", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateIntOrDictEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateIntOrDictEntry end: entry=%s\n", entryName)
 
 	return nil
 }
 
 func validateBooleanOrStreamEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version) error {
 
-	log.Validate.Printf("validateBooleanOrStreamEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateBooleanOrStreamEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -1349,7 +1360,7 @@ func validateBooleanOrStreamEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName
 		if required {
 			return errors.Errorf("pdfcpu: validateBooleanOrStreamEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateBooleanOrStreamEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateBooleanOrStreamEntry end: optional entry %s is nil\n", entryName)
 		return nil
 	}
 
@@ -1368,14 +1379,14 @@ func validateBooleanOrStreamEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName
 		return errors.Errorf("pdfcpu: validateBooleanOrStreamEntry: dict=%s entry=%s invalid type", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateBooleanOrStreamEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateBooleanOrStreamEntry end: entry=%s\n", entryName)
 
 	return nil
 }
 
 func validateStreamDictOrDictEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version) error {
 
-	log.Validate.Printf("validateStreamDictOrDictEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateStreamDictOrDictEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -1390,7 +1401,7 @@ func validateStreamDictOrDictEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictNam
 		if required {
 			return errors.Errorf("pdfcpu: validateStreamDictOrDictEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateStreamDictOrDictEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateStreamDictOrDictEntry end: optional entry %s is nil\n", entryName)
 		return nil
 	}
 
@@ -1412,14 +1423,14 @@ func validateStreamDictOrDictEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictNam
 		return errors.Errorf("pdfcpu: validateStreamDictOrDictEntry: dict=%s entry=%s invalid type", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateStreamDictOrDictEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateStreamDictOrDictEntry end: entry=%s\n", entryName)
 
 	return nil
 }
 
 func validateIntegerOrArrayOfIntegerEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version) error {
 
-	log.Validate.Printf("validateIntegerOrArrayOfIntegerEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateIntegerOrArrayOfIntegerEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -1434,7 +1445,7 @@ func validateIntegerOrArrayOfIntegerEntry(xRefTable *pdf.XRefTable, d pdf.Dict,
 		if required {
 			return errors.Errorf("pdfcpu: validateIntegerOrArrayOfIntegerEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateIntegerOrArrayOfIntegerEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateIntegerOrArrayOfIntegerEntry end: optional entry %s is nil\n", entryName)
 		return nil
 	}
 
@@ -1473,14 +1484,14 @@ func validateIntegerOrArrayOfIntegerEntry(xRefTable *pdf.XRefTable, d pdf.Dict,
 		return errors.Errorf("pdfcpu: validateIntegerOrArrayOfIntegerEntry: dict=%s entry=%s invalid type", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateIntegerOrArrayOfIntegerEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateIntegerOrArrayOfIntegerEntry end: entry=%s\n", entryName)
 
 	return nil
 }
 
 func validateNameOrArrayOfNameEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version) error {
 
-	log.Validate.Printf("validateNameOrArrayOfNameEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateNameOrArrayOfNameEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -1495,7 +1506,7 @@ func validateNameOrArrayOfNameEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictNa
 		if required {
 			return errors.Errorf("pdfcpu: validateNameOrArrayOfNameEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateNameOrArrayOfNameEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateNameOrArrayOfNameEntry end: optional entry %s is nil\n", entryName)
 		return nil
 	}
 
@@ -1535,14 +1546,14 @@ func validateNameOrArrayOfNameEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictNa
 		return errors.Errorf("pdfcpu: validateNameOrArrayOfNameEntry: dict=%s entry=%s invalid type", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateNameOrArrayOfNameEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateNameOrArrayOfNameEntry end: entry=%s\n", entryName)
 
 	return nil
 }
 
 func validateBooleanOrArrayOfBooleanEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version) error {
 
-	log.Validate.Printf("validateBooleanOrArrayOfBooleanEntry begin: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateBooleanOrArrayOfBooleanEntry begin: entry=%s\n", entryName)
 
 	o, err := d.Entry(dictName, entryName, required)
 	if err != nil || o == nil {
@@ -1557,7 +1568,7 @@ func validateBooleanOrArrayOfBooleanEntry(xRefTable *pdf.XRefTable, d pdf.Dict,
 		if required {
 			return errors.Errorf("pdfcpu: validateBooleanOrArrayOfBooleanEntry: dict=%s required entry=%s is nil", dictName, entryName)
 		}
-		log.Validate.Printf("validateBooleanOrArrayOfBooleanEntry end: optional entry %s is nil\n", entryName)
+		xRefTable.Log.Validate().Printf("validateBooleanOrArrayOfBooleanEntry end: optional entry %s is nil\n", entryName)
 		return nil
 	}
 
@@ -1596,7 +1607,7 @@ func validateBooleanOrArrayOfBooleanEntry(xRefTable *pdf.XRefTable, d pdf.Dict,
 		return errors.Errorf("pdfcpu: validateBooleanOrArrayOfBooleanEntry: dict=%s entry=%s invalid type", dictName, entryName)
 	}
 
-	log.Validate.Printf("validateBooleanOrArrayOfBooleanEntry end: entry=%s\n", entryName)
+	xRefTable.Log.Validate().Printf("validateBooleanOrArrayOfBooleanEntry end: entry=%s\n", entryName)
 
 	return nil
 }
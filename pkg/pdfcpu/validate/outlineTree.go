@@ -113,7 +113,7 @@ func validateOutlineTree(xRefTable *pdf.XRefTable, first, last *pdf.IndirectRef)
 		}
 
 		if firstChild != nil && (xRefTable.ValidationMode == pdf.ValidationRelaxed ||
-			xRefTable.ValidationMode == pdf.ValidationStrict && lastChild != nil) {
+			xRefTable.StrictValidation() && lastChild != nil) {
 			// Recurse into subtree.
 			err = validateOutlineTree(xRefTable, firstChild, lastChild)
 			if err != nil {
@@ -126,7 +126,7 @@ func validateOutlineTree(xRefTable *pdf.XRefTable, first, last *pdf.IndirectRef)
 
 	}
 
-	if xRefTable.ValidationMode == pdf.ValidationStrict && objNumber != last.ObjectNumber.Value() {
+	if xRefTable.StrictValidation() && objNumber != last.ObjectNumber.Value() {
 		return errors.Errorf("pdfcpu: validateOutlineTree: corrupted child list %d <> %d\n", objNumber, last.ObjectNumber)
 	}
 
@@ -164,7 +164,7 @@ func validateOutlines(xRefTable *pdf.XRefTable, rootDict pdf.Dict, required bool
 		return nil
 	}
 
-	if xRefTable.ValidationMode == pdf.ValidationStrict && last == nil {
+	if xRefTable.StrictValidation() && last == nil {
 		return errors.New("pdfcpu: validateOutlines: corrupted, root needs both first and last")
 	}
 
@@ -17,7 +17,8 @@ limitations under the License.
 package validate
 
 import (
-	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"fmt"
+
 	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pkg/errors"
 )
@@ -769,6 +770,12 @@ func validatePageDict(xRefTable *pdf.XRefTable, d pdf.Dict, objNumber, genNumber
 		return err
 	}
 
+	if hasContents && xRefTable.ValidationMode == pdf.ValidationPedantic {
+		if err = validatePageContentSyntax(xRefTable, d); err != nil {
+			return err
+		}
+	}
+
 	// Resources
 	err = validatePageResources(xRefTable, d, hasResources, hasContents)
 	if err != nil {
@@ -797,7 +804,7 @@ func validatePageDict(xRefTable *pdf.XRefTable, d pdf.Dict, objNumber, genNumber
 		return err
 	}
 
-	if hasPieceInfo && lm == nil && xRefTable.ValidationMode == pdf.ValidationStrict {
+	if hasPieceInfo && lm == nil && xRefTable.StrictValidation() {
 		return errors.New("pdfcpu: validatePageDict: missing \"LastModified\" (required by \"PieceInfo\")")
 	}
 
@@ -900,7 +907,7 @@ func validateResources(xRefTable *pdf.XRefTable, d pdf.Dict) (hasResources bool,
 		xRefTable.PageCount = *pageCount
 	}
 
-	log.Validate.Printf("validateResources: This page node has %d pages\n", *pageCount)
+	xRefTable.Log.Validate().Printf("validateResources: This page node has %d pages\n", *pageCount)
 
 	// Resources: optional, dict
 	o, ok := d.Find("Resources")
@@ -935,6 +942,10 @@ func validatePagesDict(xRefTable *pdf.XRefTable, d pdf.Dict, objNr, genNumber in
 
 	for _, o := range kidsArray {
 
+		if err := xRefTable.Canceled(); err != nil {
+			return curPage, err
+		}
+
 		if o == nil {
 			continue
 		}
@@ -945,7 +956,7 @@ func validatePagesDict(xRefTable *pdf.XRefTable, d pdf.Dict, objNr, genNumber in
 			return curPage, errors.New("pdfcpu: validatePagesDict: missing indirect reference for kid")
 		}
 
-		log.Validate.Printf("validatePagesDict: PageNode: %s\n", ir)
+		xRefTable.Log.Validate().Printf("validatePagesDict: PageNode: %s\n", ir)
 
 		objNumber := ir.ObjectNumber.Value()
 		genNumber := ir.GenerationNumber.Value()
@@ -976,6 +987,7 @@ func validatePagesDict(xRefTable *pdf.XRefTable, d pdf.Dict, objNr, genNumber in
 			curPage++
 			xRefTable.CurPage = curPage
 			err = validatePageDict(xRefTable, pageNodeDict, objNumber, genNumber, hasResources, hasMediaBox)
+			err = collect(xRefTable, fmt.Sprintf("Root/Pages/%d", curPage), pdf.RulePage, err)
 
 		default:
 			return curPage, errors.Errorf("pdfcpu: validatePagesDict: Unexpected dict type: %s", dictType)
@@ -0,0 +1,311 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pdfcpu/pdfcpu/pkg/contentstream"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// Pedantic validation flags deviations readers usually tolerate.
+// It is meant for authors of PDF writers who want to produce clean output.
+
+// Implementation limits, see PDF 32000-1:2008 Annex C.
+const (
+	maxNameLength   = 127
+	maxStringLength = 32767
+	maxQNesting     = 28
+)
+
+type opClass int
+
+const (
+	opGeneralGState opClass = iota
+	opSpecialGState
+	opPath
+	opText
+	opTextState
+	opTextObject
+	opColor
+	opXObject
+	opInlineImage
+	opMarkedContent
+	opCompatibility
+	opType3
+)
+
+// contentOperators maps all content stream operators to their category, see PDF 32000-1:2008 Table A.1.
+var contentOperators = map[string]opClass{
+	"w": opGeneralGState, "J": opGeneralGState, "j": opGeneralGState, "M": opGeneralGState,
+	"d": opGeneralGState, "ri": opGeneralGState, "i": opGeneralGState, "gs": opGeneralGState,
+
+	"q": opSpecialGState, "Q": opSpecialGState, "cm": opSpecialGState,
+
+	"m": opPath, "l": opPath, "c": opPath, "v": opPath, "y": opPath, "h": opPath, "re": opPath,
+	"S": opPath, "s": opPath, "f": opPath, "F": opPath, "f*": opPath, "B": opPath, "B*": opPath,
+	"b": opPath, "b*": opPath, "n": opPath, "W": opPath, "W*": opPath, "sh": opPath,
+
+	"BT": opTextObject, "ET": opTextObject,
+
+	"Tc": opTextState, "Tw": opTextState, "Tz": opTextState, "TL": opTextState,
+	"Tf": opTextState, "Tr": opTextState, "Ts": opTextState,
+
+	"Td": opText, "TD": opText, "Tm": opText, "T*": opText,
+	"Tj": opText, "TJ": opText, "'": opText, "\"": opText,
+
+	"CS": opColor, "cs": opColor, "SC": opColor, "SCN": opColor, "sc": opColor, "scn": opColor,
+	"G": opColor, "g": opColor, "RG": opColor, "rg": opColor, "K": opColor, "k": opColor,
+
+	"Do": opXObject,
+
+	"BI": opInlineImage,
+
+	"MP": opMarkedContent, "DP": opMarkedContent, "BMC": opMarkedContent, "BDC": opMarkedContent, "EMC": opMarkedContent,
+
+	"BX": opCompatibility, "EX": opCompatibility,
+
+	"d0": opType3, "d1": opType3,
+}
+
+// allowedInTextObject returns true if operators of class c may appear between BT and ET, see PDF 32000-1:2008 Figure 9.
+func allowedInTextObject(c opClass) bool {
+	switch c {
+	case opSpecialGState, opPath, opXObject, opInlineImage:
+		return false
+	}
+	return true
+}
+
+func nameTooLong(n string) bool {
+	return len(pdf.Name(n).Value()) > maxNameLength
+}
+
+// checkLimits returns an error if o exceeds an implementation limit.
+func checkLimits(o pdf.Object) error {
+
+	switch o := o.(type) {
+
+	case pdf.Name:
+		if nameTooLong(string(o)) {
+			return errors.Errorf("pdfcpu: name exceeds %d bytes: %.32s..", maxNameLength, o)
+		}
+
+	case pdf.StringLiteral:
+		if s, err := pdf.Unescape(o.Value()); err == nil && len(s) > maxStringLength {
+			return errors.Errorf("pdfcpu: string exceeds %d bytes", maxStringLength)
+		}
+
+	case pdf.HexLiteral:
+		if len(o)/2 > maxStringLength {
+			return errors.Errorf("pdfcpu: string exceeds %d bytes", maxStringLength)
+		}
+
+	case pdf.Dict:
+		for k, v := range o {
+			if nameTooLong(k) {
+				return errors.Errorf("pdfcpu: dict key exceeds %d bytes: %.32s..", maxNameLength, k)
+			}
+			if err := checkLimits(v); err != nil {
+				return err
+			}
+		}
+
+	case pdf.StreamDict:
+		return checkLimits(o.Dict)
+
+	case pdf.Array:
+		for _, v := range o {
+			if err := checkLimits(v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateImplementationLimits checks all objects against the implementation limits of PDF 32000-1:2008 Annex C.
+func validateImplementationLimits(xRefTable *pdf.XRefTable) error {
+
+	var objNrs []int
+	for objNr := range xRefTable.Table {
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Ints(objNrs)
+
+	for _, objNr := range objNrs {
+		entry := xRefTable.Table[objNr]
+		if entry.Free || entry.Object == nil {
+			continue
+		}
+		xRefTable.CurObj = objNr
+		err := checkLimits(entry.Object)
+		if err = collect(xRefTable, fmt.Sprintf("XRef/%d", objNr), pdf.RuleLimits, err); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateReadWarnings turns anomalies tolerated while reading into errors.
+func validateReadWarnings(xRefTable *pdf.XRefTable) error {
+	if xRefTable.Report != nil || len(xRefTable.Warnings) == 0 {
+		// Read anomalies have been added to the report already.
+		return nil
+	}
+	return errors.Errorf("pdfcpu: %s", xRefTable.Warnings[0])
+}
+
+type contentChecker struct {
+	xRefTable *pdf.XRefTable
+	path      string
+	inText    bool
+	compat    int // BX nesting level
+	qLevel    int
+}
+
+func (cc *contentChecker) finding(format string, args ...interface{}) error {
+	return collect(cc.xRefTable, cc.path, pdf.RuleSyntax, errors.Errorf("pdfcpu: content stream: "+format, args...))
+}
+
+func (cc *contentChecker) checkOperands(op contentstream.Operation) error {
+	for _, o := range op.Operands {
+		if err := checkLimits(o); err != nil {
+			if err = collect(cc.xRefTable, cc.path, pdf.RuleLimits, err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (cc *contentChecker) checkTextObject(op string) error {
+	switch op {
+	case "BT":
+		if cc.inText {
+			return cc.finding("nested BT")
+		}
+		cc.inText = true
+	case "ET":
+		if !cc.inText {
+			return cc.finding("ET outside text object")
+		}
+		cc.inText = false
+	}
+	return nil
+}
+
+func (cc *contentChecker) checkGState(op string) error {
+	switch op {
+	case "q":
+		cc.qLevel++
+		if cc.qLevel == maxQNesting+1 {
+			return collect(cc.xRefTable, cc.path, pdf.RuleLimits, errors.Errorf("pdfcpu: content stream: q nesting exceeds %d", maxQNesting))
+		}
+	case "Q":
+		if cc.qLevel == 0 {
+			return cc.finding("Q without matching q")
+		}
+		cc.qLevel--
+	}
+	return nil
+}
+
+func (cc *contentChecker) check(op contentstream.Operation) error {
+
+	if err := cc.checkOperands(op); err != nil {
+		return err
+	}
+
+	c, ok := contentOperators[op.Operator]
+	if !ok {
+		if cc.compat > 0 {
+			// Unknown operators are ignored within compatibility sections.
+			return nil
+		}
+		return cc.finding("unknown operator %s", op.Operator)
+	}
+
+	if cc.inText && !allowedInTextObject(c) {
+		if err := cc.finding("operator %s inside text object", op.Operator); err != nil {
+			return err
+		}
+	}
+
+	switch c {
+
+	case opTextObject:
+		return cc.checkTextObject(op.Operator)
+
+	case opText:
+		if !cc.inText {
+			return cc.finding("operator %s outside text object", op.Operator)
+		}
+
+	case opSpecialGState:
+		return cc.checkGState(op.Operator)
+
+	case opCompatibility:
+		if op.Operator == "BX" {
+			cc.compat++
+		} else if cc.compat > 0 {
+			cc.compat--
+		}
+	}
+
+	return nil
+}
+
+// validatePageContentSyntax checks the operators of the content of page dict d.
+func validatePageContentSyntax(xRefTable *pdf.XRefTable, d pdf.Dict) error {
+
+	bb, err := xRefTable.PageContent(d)
+	if err != nil || len(bb) == 0 {
+		// Missing or undecodable content is beyond the scope of syntax checks.
+		return nil
+	}
+
+	cc := &contentChecker{xRefTable: xRefTable, path: fmt.Sprintf("Root/Pages/%d/Contents", xRefTable.CurPage)}
+
+	ops, err := contentstream.Parse(bb)
+	if err != nil {
+		return collect(xRefTable, cc.path, pdf.RuleSyntax, err)
+	}
+
+	for _, op := range ops {
+		if err := cc.check(op); err != nil {
+			return err
+		}
+	}
+
+	if cc.inText {
+		if err := cc.finding("missing ET"); err != nil {
+			return err
+		}
+	}
+
+	if cc.qLevel > 0 {
+		return cc.finding("%d unbalanced q", cc.qLevel)
+	}
+
+	return nil
+}
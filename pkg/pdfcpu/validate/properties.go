@@ -17,7 +17,6 @@ limitations under the License.
 package validate
 
 import (
-	"github.com/pdfcpu/pdfcpu/pkg/log"
 	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pkg/errors"
 )
@@ -54,23 +53,23 @@ func validatePropertiesDict(xRefTable *pdf.XRefTable, o pdf.Object) error {
 
 	for key, val := range d {
 
-		log.Validate.Printf("validatePropertiesDict: key=%s val=%v\n", key, val)
+		xRefTable.Log.Validate().Printf("validatePropertiesDict: key=%s val=%v\n", key, val)
 
 		switch key {
 
 		case "Metadata":
-			log.Validate.Printf("validatePropertiesDict: recognized key \"%s\"\n", key)
+			xRefTable.Log.Validate().Printf("validatePropertiesDict: recognized key \"%s\"\n", key)
 			// see above
 
 		case "Contents":
-			log.Validate.Printf("validatePropertiesDict: recognized key \"%s\"\n", key)
+			xRefTable.Log.Validate().Printf("validatePropertiesDict: recognized key \"%s\"\n", key)
 			_, err = validateStreamDict(xRefTable, val)
 			if err != nil {
 				return err
 			}
 
 		case "Resources":
-			log.Validate.Printf("validatePropertiesDict: recognized key \"%s\"\n", key)
+			xRefTable.Log.Validate().Printf("validatePropertiesDict: recognized key \"%s\"\n", key)
 			_, err = validateResourceDict(xRefTable, val)
 			if err != nil {
 				return err
@@ -89,7 +88,7 @@ func validatePropertiesDict(xRefTable *pdf.XRefTable, o pdf.Object) error {
 		//case "Lang": -> default
 
 		default:
-			log.Validate.Printf("validatePropertiesDict: processing unrecognized key \"%s\"\n", key)
+			xRefTable.Log.Validate().Printf("validatePropertiesDict: processing unrecognized key \"%s\"\n", key)
 			_, err = xRefTable.Dereference(val)
 			if err != nil {
 				return err
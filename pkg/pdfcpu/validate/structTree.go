@@ -88,7 +88,7 @@ func validateObjectReferenceDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 
 	// Obj: required, indirect reference
 	ir := d.IndirectRefEntry("Obj")
-	if xRefTable.ValidationMode == pdf.ValidationStrict && ir == nil {
+	if xRefTable.StrictValidation() && ir == nil {
 		return errors.New("pdfcpu: validateObjectReferenceDict: missing required entry \"Obj\"")
 	}
 
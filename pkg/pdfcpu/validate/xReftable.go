@@ -30,11 +30,28 @@ import (
 	"github.com/pkg/errors"
 )
 
+// collect records err as a finding at path if xRefTable collects a validation report and returns err otherwise.
+// The finding refers to the object dereferenced last.
+func collect(xRefTable *pdf.XRefTable, path, rule string, err error) error {
+	if err == nil || xRefTable.Report == nil {
+		return err
+	}
+	xRefTable.Report.Add(xRefTable.CurObj, path, rule, err.Error())
+	return nil
+}
+
 // XRefTable validates a PDF cross reference table obeying the validation mode.
+// If xRefTable.Report is set validation continues after errors and records all findings in the report.
 func XRefTable(xRefTable *pdf.XRefTable) error {
 
 	log.Info.Println("validating")
-	log.Validate.Println("*** validateXRefTable begin ***")
+	xRefTable.Log.Validate().Println("*** validateXRefTable begin ***")
+
+	if r := xRefTable.Report; r != nil {
+		for _, s := range xRefTable.Warnings {
+			r.Add(0, "XRef", pdf.RuleRead, s)
+		}
+	}
 
 	// Validate root object(aka the document catalog) and page tree.
 	err := validateRootObject(xRefTable)
@@ -42,9 +59,13 @@ func XRefTable(xRefTable *pdf.XRefTable) error {
 		return err
 	}
 
+	if err = xRefTable.Canceled(); err != nil {
+		return err
+	}
+
 	// Validate document information dictionary.
 	err = validateDocumentInfoObject(xRefTable)
-	if err != nil {
+	if err = collect(xRefTable, "Trailer/Info", pdf.RuleInfo, err); err != nil {
 		return err
 	}
 
@@ -54,9 +75,18 @@ func XRefTable(xRefTable *pdf.XRefTable) error {
 		return err
 	}
 
-	xRefTable.Valid = true
+	if xRefTable.ValidationMode == pdf.ValidationPedantic {
+		if err = validateReadWarnings(xRefTable); err != nil {
+			return err
+		}
+		if err = validateImplementationLimits(xRefTable); err != nil {
+			return err
+		}
+	}
+
+	xRefTable.Valid = xRefTable.Report == nil || xRefTable.Report.Valid
 
-	log.Validate.Println("*** validateXRefTable end ***")
+	xRefTable.Log.Validate().Println("*** validateXRefTable end ***")
 
 	return nil
 }
@@ -810,26 +840,50 @@ func validateNeedsRendering(xRefTable *pdf.XRefTable, rootDict pdf.Dict, require
 	return err
 }
 
+func uriErrorString(resp string) string {
+	switch resp {
+	case "i":
+		return "invalid url"
+	case "s":
+		return "severe error"
+	}
+	return fmt.Sprintf("status=%s", resp)
+}
+
 func logURIError(xRefTable *pdf.XRefTable, pages []int) {
 	fmt.Println()
 	for _, page := range pages {
 		for uri, resp := range xRefTable.URIs[page] {
 			if resp != "" {
-				var s string
-				switch resp {
-				case "i":
-					s = "invalid url"
-				case "s":
-					s = "severe error"
-				default:
-					s = fmt.Sprintf("status=%s", resp)
-				}
-				log.CLI.Printf("Page %d: %s %s\n", page, uri, s)
+				log.CLI.Printf("Page %d: %s %s\n", page, uri, uriErrorString(resp))
 			}
 		}
 	}
 }
 
+// reportBrokenLinks records a finding for each broken link.
+func reportBrokenLinks(xRefTable *pdf.XRefTable) {
+	pages := []int{}
+	for i := range xRefTable.URIs {
+		pages = append(pages, i)
+	}
+	sort.Ints(pages)
+
+	for _, page := range pages {
+		uris := []string{}
+		for uri, resp := range xRefTable.URIs[page] {
+			if resp != "" {
+				uris = append(uris, uri)
+			}
+		}
+		sort.Strings(uris)
+		for _, uri := range uris {
+			msg := fmt.Sprintf("broken link %s: %s", uri, uriErrorString(xRefTable.URIs[page][uri]))
+			xRefTable.Report.Add(0, fmt.Sprintf("Root/Pages/%d/Annots", page), pdf.RuleLinks, msg)
+		}
+	}
+}
+
 func checkForBrokenLinks(xRefTable *pdf.XRefTable) error {
 	var httpErr bool
 	log.CLI.Println("validating URIs..")
@@ -883,7 +937,7 @@ func checkForBrokenLinks(xRefTable *pdf.XRefTable) error {
 
 func validateRootObject(xRefTable *pdf.XRefTable) error {
 
-	log.Validate.Println("*** validateRootObject begin ***")
+	xRefTable.Log.Validate().Println("*** validateRootObject begin ***")
 
 	// => 7.7.2 Document Catalog
 
@@ -926,69 +980,85 @@ func validateRootObject(xRefTable *pdf.XRefTable) error {
 
 	// Type
 	_, err = validateNameEntry(xRefTable, d, "rootDict", "Type", REQUIRED, pdf.V10, func(s string) bool { return s == "Catalog" })
-	if err != nil {
+	if err = collect(xRefTable, "Root/Type", "Type", err); err != nil {
 		return err
 	}
 
 	// Pages
 	rootPageNodeDict, err := validatePages(xRefTable, d)
-	if err != nil {
+	if err = collect(xRefTable, "Root/Pages", "Pages", err); err != nil {
 		return err
 	}
 
 	for _, f := range []struct {
+		entryName    string
 		validate     func(xRefTable *pdf.XRefTable, d pdf.Dict, required bool, sinceVersion pdf.Version) (err error)
 		required     bool
 		sinceVersion pdf.Version
 	}{
-		{validateRootVersion, OPTIONAL, pdf.V14},
-		{validateExtensions, OPTIONAL, pdf.V10},
-		{validatePageLabels, OPTIONAL, pdf.V13},
-		{validateNames, OPTIONAL, pdf.V12},
-		{validateNamedDestinations, OPTIONAL, pdf.V11},
-		{validateViewerPreferences, OPTIONAL, pdf.V12},
-		{validatePageLayout, OPTIONAL, pdf.V10},
-		{validatePageMode, OPTIONAL, pdf.V10},
-		{validateOutlines, OPTIONAL, pdf.V10},
-		{validateThreads, OPTIONAL, pdf.V11},
-		{validateOpenAction, OPTIONAL, pdf.V11},
-		{validateRootAdditionalActions, OPTIONAL, pdf.V14},
-		{validateURI, OPTIONAL, pdf.V11},
-		{validateAcroForm, OPTIONAL, pdf.V12},
-		{validateRootMetadata, OPTIONAL, pdf.V14},
-		{validateStructTree, OPTIONAL, pdf.V13},
-		{validateMarkInfo, OPTIONAL, pdf.V14},
-		{validateLang, OPTIONAL, pdf.V10},
-		{validateSpiderInfo, OPTIONAL, pdf.V13},
-		{validateOutputIntents, OPTIONAL, pdf.V14},
-		{validateRootPieceInfo, OPTIONAL, pdf.V14},
-		{validateOCProperties, OPTIONAL, pdf.V15},
-		{validatePermissions, OPTIONAL, pdf.V15},
-		{validateLegal, OPTIONAL, pdf.V17},
-		{validateRequirements, OPTIONAL, pdf.V17},
-		{validateCollection, OPTIONAL, pdf.V17},
-		{validateNeedsRendering, OPTIONAL, pdf.V17},
+		{"Version", validateRootVersion, OPTIONAL, pdf.V14},
+		{"Extensions", validateExtensions, OPTIONAL, pdf.V10},
+		{"PageLabels", validatePageLabels, OPTIONAL, pdf.V13},
+		{"Names", validateNames, OPTIONAL, pdf.V12},
+		{"Dests", validateNamedDestinations, OPTIONAL, pdf.V11},
+		{"ViewerPreferences", validateViewerPreferences, OPTIONAL, pdf.V12},
+		{"PageLayout", validatePageLayout, OPTIONAL, pdf.V10},
+		{"PageMode", validatePageMode, OPTIONAL, pdf.V10},
+		{"Outlines", validateOutlines, OPTIONAL, pdf.V10},
+		{"Threads", validateThreads, OPTIONAL, pdf.V11},
+		{"OpenAction", validateOpenAction, OPTIONAL, pdf.V11},
+		{"AA", validateRootAdditionalActions, OPTIONAL, pdf.V14},
+		{"URI", validateURI, OPTIONAL, pdf.V11},
+		{"AcroForm", validateAcroForm, OPTIONAL, pdf.V12},
+		{"Metadata", validateRootMetadata, OPTIONAL, pdf.V14},
+		{"StructTreeRoot", validateStructTree, OPTIONAL, pdf.V13},
+		{"MarkInfo", validateMarkInfo, OPTIONAL, pdf.V14},
+		{"Lang", validateLang, OPTIONAL, pdf.V10},
+		{"SpiderInfo", validateSpiderInfo, OPTIONAL, pdf.V13},
+		{"OutputIntents", validateOutputIntents, OPTIONAL, pdf.V14},
+		{"PieceInfo", validateRootPieceInfo, OPTIONAL, pdf.V14},
+		{"OCProperties", validateOCProperties, OPTIONAL, pdf.V15},
+		{"Perms", validatePermissions, OPTIONAL, pdf.V15},
+		{"Legal", validateLegal, OPTIONAL, pdf.V17},
+		{"Requirements", validateRequirements, OPTIONAL, pdf.V17},
+		{"Collection", validateCollection, OPTIONAL, pdf.V17},
+		{"NeedsRendering", validateNeedsRendering, OPTIONAL, pdf.V17},
 	} {
+		if err := xRefTable.Canceled(); err != nil {
+			return err
+		}
 		if !f.required && xRefTable.Version() < f.sinceVersion {
 			// Ignore optional fields if currentVersion < sinceVersion
 			// This is really a workaround for explicitly extending relaxed validation.
 			continue
 		}
 		err = f.validate(xRefTable, d, f.required, f.sinceVersion)
-		if err != nil {
+		if err = collect(xRefTable, "Root/"+f.entryName, f.entryName, err); err != nil {
 			return err
 		}
 	}
 
+	if rootPageNodeDict == nil {
+		// The page tree is corrupt and has been reported already.
+		return nil
+	}
+
 	// Validate remainder of annotations after AcroForm validation only.
 	_, err = validatePagesAnnotations(xRefTable, rootPageNodeDict, 0)
+	if err = collect(xRefTable, "Root/Pages", pdf.RuleAnnot, err); err != nil {
+		return err
+	}
 
 	if xRefTable.ValidateLinks && len(xRefTable.URIs) > 0 {
 		err = checkForBrokenLinks(xRefTable)
+		if err != nil && xRefTable.Report != nil {
+			reportBrokenLinks(xRefTable)
+			err = nil
+		}
 	}
 
 	if err == nil {
-		log.Validate.Println("*** validateRootObject end ***")
+		xRefTable.Log.Validate().Println("*** validateRootObject end ***")
 	}
 
 	return err
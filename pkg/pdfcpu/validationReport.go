@@ -0,0 +1,124 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import "fmt"
+
+// Severities of validation findings.
+const (
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// Rules of validation findings not bound to a specific catalog entry.
+const (
+	RuleRead  = "Read"  // Anomaly encountered and repaired while reading.
+	RuleInfo  = "Info"  // Document information dict.
+	RulePage  = "Page"  // Page dict including its resources and content streams.
+	RuleAnnot = "Annot" // Page annotations.
+	RuleLinks = "Links" // Broken links.
+
+	// Pedantic validation only.
+	RuleSyntax = "Syntax" // Content stream syntax.
+	RuleLimits = "Limits" // Implementation limits.
+)
+
+// ValidationFinding is a problem found during validation.
+type ValidationFinding struct {
+	ObjNr    int    `json:"objNr,omitempty"` // The offending object or the last object dereferenced.
+	Path     string `json:"path"`            // Location in the document graph, eg. Root/Pages/3/Annots
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"` // The catalog entry or one of the Rule constants.
+	Message  string `json:"message"`
+}
+
+func (f ValidationFinding) String() string {
+	s := fmt.Sprintf("%s %s: %s", f.Severity, f.Path, f.Message)
+	if f.ObjNr > 0 {
+		s += fmt.Sprintf(" (obj#:%d)", f.ObjNr)
+	}
+	return s
+}
+
+// ValidationReport collects all findings of a validation run.
+//
+// The validation mode acts as profile deciding which findings are fatal:
+// In strict and pedantic mode every finding is an error.
+// In relaxed mode read anomalies, problems of the document information dict and broken links are warnings.
+type ValidationReport struct {
+	Mode     string              `json:"mode"`
+	Valid    bool                `json:"valid"` // true if there are no errors.
+	Findings []ValidationFinding `json:"findings"`
+	mode     int
+}
+
+// NewValidationReport returns an empty report for validationMode.
+func NewValidationReport(validationMode int) *ValidationReport {
+	return &ValidationReport{
+		Mode:     validationModeString(validationMode),
+		Valid:    true,
+		Findings: []ValidationFinding{},
+		mode:     validationMode,
+	}
+}
+
+func (r *ValidationReport) severity(rule string) string {
+	if r.mode == ValidationStrict || r.mode == ValidationPedantic {
+		return SeverityError
+	}
+	switch rule {
+	case RuleRead, RuleInfo, RuleLinks:
+		return SeverityWarning
+	}
+	return SeverityError
+}
+
+// Add records a finding for rule at path.
+func (r *ValidationReport) Add(objNr int, path, rule, msg string) {
+	sev := r.severity(rule)
+	if sev == SeverityError {
+		r.Valid = false
+	}
+	r.Findings = append(r.Findings, ValidationFinding{ObjNr: objNr, Path: path, Severity: sev, Rule: rule, Message: msg})
+}
+
+// Errors returns the number of findings with severity error.
+func (r *ValidationReport) Errors() int {
+	i := 0
+	for _, f := range r.Findings {
+		if f.Severity == SeverityError {
+			i++
+		}
+	}
+	return i
+}
+
+// List returns the findings of r in human readable form.
+func (r *ValidationReport) List() []string {
+	ss := make([]string, 0, len(r.Findings)+1)
+	for _, f := range r.Findings {
+		ss = append(ss, f.String())
+	}
+	s := "validation ok"
+	if !r.Valid {
+		s = fmt.Sprintf("validation failed: %d errors", r.Errors())
+	}
+	if w := len(r.Findings) - r.Errors(); w > 0 {
+		s += fmt.Sprintf(", %d warnings", w)
+	}
+	return append(ss, fmt.Sprintf("%s (mode=%s)", s, r.Mode))
+}
@@ -66,9 +66,17 @@ func Write(ctx *Context) (err error) {
 		return err
 	}
 
-	// Since we support PDF Collections (since V1.7) for file attachments
-	// we need to generate V1.7 PDF files.
-	if err = writeHeader(ctx.Write, V17); err != nil {
+	if err = ctx.Canceled(); err != nil {
+		return err
+	}
+
+	// PDF 1.7 unless configured otherwise since we support PDF Collections (since V1.7) for file attachments.
+	v, err := ctx.versionForWriting()
+	if err != nil {
+		return err
+	}
+
+	if err = writeHeader(ctx.Write, v); err != nil {
 		return err
 	}
 
@@ -77,21 +85,25 @@ func Write(ctx *Context) (err error) {
 		ctx.RootDict.Delete("Version")
 	}
 
-	log.Write.Printf("offset after writeHeader: %d\n", ctx.Write.Offset)
+	ctx.Log.Write().Printf("offset after writeHeader: %d\n", ctx.Write.Offset)
 
 	// Write root object(aka the document catalog) and page tree.
 	if err = writeRootObject(ctx); err != nil {
 		return err
 	}
 
-	log.Write.Printf("offset after writeRootObject: %d\n", ctx.Write.Offset)
+	ctx.Log.Write().Printf("offset after writeRootObject: %d\n", ctx.Write.Offset)
+
+	if err = ctx.Canceled(); err != nil {
+		return err
+	}
 
 	// Write document information dictionary.
 	if err = ctx.writeDocumentInfoDict(); err != nil {
 		return err
 	}
 
-	log.Write.Printf("offset after writeInfoObject: %d\n", ctx.Write.Offset)
+	ctx.Log.Write().Printf("offset after writeInfoObject: %d\n", ctx.Write.Offset)
 
 	// Write offspec additional streams as declared in pdf trailer.
 	if err = writeAdditionalStreams(ctx); err != nil {
@@ -147,10 +159,22 @@ func WriteIncrement(ctx *Context) error {
 
 func prepareContextForWriting(ctx *Context) error {
 
+	if ctx.AuditLog {
+		if err := ctx.appendAuditEntry(); err != nil {
+			return err
+		}
+	}
+
 	if err := ensureInfoDictAndFileID(ctx); err != nil {
 		return err
 	}
 
+	if ctx.Canonical {
+		// Object streams hide objects from text tools.
+		ctx.WriteObjectStream = false
+		ctx.WriteXRefStream = false
+	}
+
 	return handleEncryption(ctx)
 }
 
@@ -215,18 +239,6 @@ func writeRootEntry(ctx *Context, d Dict, dictName, entryName string, statsAttr
 	return nil
 }
 
-// Write root entry to object stream.
-func writeRootEntryToObjStream(ctx *Context, d Dict, dictName, entryName string, statsAttr int) error {
-
-	ctx.Write.WriteToObjectStream = true
-
-	if err := writeRootEntry(ctx, d, dictName, entryName, statsAttr); err != nil {
-		return err
-	}
-
-	return stopObjectStream(ctx)
-}
-
 // Write page tree.
 func writePages(ctx *Context, rootDict Dict) error {
 
@@ -257,7 +269,7 @@ func writeRootObject(ctx *Context) error {
 	objNumber := int(catalog.ObjectNumber)
 	genNumber := int(catalog.GenerationNumber)
 
-	log.Write.Printf("*** writeRootObject: begin offset=%d *** %s\n", ctx.Write.Offset, catalog)
+	ctx.Log.Write().Printf("*** writeRootObject: begin offset=%d *** %s\n", ctx.Write.Offset, catalog)
 
 	// Ensure corresponding and accurate name tree object graphs.
 	// if !ctx.ApplyReducedFeatureSet() {
@@ -266,6 +278,11 @@ func writeRootObject(ctx *Context) error {
 	}
 	// }
 
+	// Keep the structure tree and the form consistent with the pages getting written.
+	if err := ctx.pruneForWriting(); err != nil {
+		return err
+	}
+
 	d, err := xRefTable.DereferenceDict(catalog)
 	if err != nil {
 		return err
@@ -278,7 +295,7 @@ func writeRootObject(ctx *Context) error {
 	dictName := "rootDict"
 
 	// if ctx.ApplyReducedFeatureSet() {
-	// 	log.Write.Println("writeRootObject - reducedFeatureSet:exclude complex entries.")
+	// 	ctx.Log.Write().Println("writeRootObject - reducedFeatureSet:exclude complex entries.")
 	// 	d.Delete("Names")
 	// 	d.Delete("Dests")
 	// 	d.Delete("Outlines")
@@ -292,9 +309,9 @@ func writeRootObject(ctx *Context) error {
 		return err
 	}
 
-	log.Write.Printf("writeRootObject: %s\n", d)
+	ctx.Log.Write().Printf("writeRootObject: %s\n", d)
 
-	log.Write.Printf("writeRootObject: new offset after rootDict = %d\n", ctx.Write.Offset)
+	ctx.Log.Write().Printf("writeRootObject: new offset after rootDict = %d\n", ctx.Write.Offset)
 
 	if err = writeRootEntry(ctx, d, dictName, "Version", RootVersion); err != nil {
 		return err
@@ -304,6 +321,9 @@ func writeRootObject(ctx *Context) error {
 		return err
 	}
 
+	// Embed all remaining eligible objects reachable from the catalog into object streams.
+	ctx.Write.WriteToObjectStream = true
+
 	for _, e := range []struct {
 		entryName string
 		statsAttr int
@@ -328,7 +348,7 @@ func writeRootObject(ctx *Context) error {
 		}
 	}
 
-	if err = writeRootEntryToObjStream(ctx, d, dictName, "StructTreeRoot", RootStructTreeRoot); err != nil {
+	if err = writeRootEntry(ctx, d, dictName, "StructTreeRoot", RootStructTreeRoot); err != nil {
 		return err
 	}
 
@@ -353,14 +373,18 @@ func writeRootObject(ctx *Context) error {
 		}
 	}
 
-	log.Write.Printf("*** writeRootObject: end offset=%d ***\n", ctx.Write.Offset)
+	if err = stopObjectStream(ctx); err != nil {
+		return err
+	}
+
+	ctx.Log.Write().Printf("*** writeRootObject: end offset=%d ***\n", ctx.Write.Offset)
 
 	return nil
 }
 
 func writeTrailerDict(ctx *Context) error {
 
-	log.Write.Printf("writeTrailerDict begin\n")
+	ctx.Log.Write().Printf("writeTrailerDict begin\n")
 
 	w := ctx.Write
 	xRefTable := ctx.XRefTable
@@ -393,18 +417,18 @@ func writeTrailerDict(ctx *Context) error {
 		d.Insert("Prev", Integer(*ctx.Write.OffsetPrevXRef))
 	}
 
-	if _, err := w.WriteString(d.PDFString()); err != nil {
+	if _, err := w.WriteString(ctx.pdfString(d)); err != nil {
 		return err
 	}
 
-	log.Write.Printf("writeTrailerDict end\n")
+	ctx.Log.Write().Printf("writeTrailerDict end\n")
 
 	return nil
 }
 
 func writeXRefSubsection(ctx *Context, start int, size int) error {
 
-	log.Write.Printf("writeXRefSubsection: start=%d size=%d\n", start, size)
+	ctx.Log.Write().Printf("writeXRefSubsection: start=%d size=%d\n", start, size)
 
 	w := ctx.Write
 
@@ -442,8 +466,8 @@ func writeXRefSubsection(ctx *Context, start int, size int) error {
 		}
 	}
 
-	log.Write.Printf("\n%s\n", strings.Join(lines, ""))
-	log.Write.Printf("writeXRefSubsection: end\n")
+	ctx.Log.Write().Printf("\n%s\n", strings.Join(lines, ""))
+	ctx.Log.Write().Printf("writeXRefSubsection: end\n")
 
 	return nil
 }
@@ -469,7 +493,7 @@ func deleteRedundantObjects(ctx *Context) {
 
 	xRefTable := ctx.XRefTable
 
-	log.Write.Printf("deleteRedundantObjects begin: Size=%d\n", *xRefTable.Size)
+	ctx.Log.Write().Printf("deleteRedundantObjects begin: Size=%d\n", *xRefTable.Size)
 
 	for i := 0; i < *xRefTable.Size; i++ {
 
@@ -489,7 +513,7 @@ func deleteRedundantObjects(ctx *Context) {
 			// Resources may be cross referenced from different objects
 			// eg. font descriptors may be shared by different font dicts.
 			// Try to remove this object from the list of the potential duplicate objects.
-			log.Write.Printf("deleteRedundantObjects: remove duplicate obj #%d\n", i)
+			ctx.Log.Write().Printf("deleteRedundantObjects: remove duplicate obj #%d\n", i)
 			delete(ctx.Optimize.DuplicateFontObjs, i)
 			delete(ctx.Optimize.DuplicateImageObjs, i)
 			delete(ctx.Optimize.DuplicateInfoObjects, i)
@@ -506,13 +530,13 @@ func deleteRedundantObjects(ctx *Context) {
 
 				if *entry.Offset == *xRefTable.OffsetPrimaryHintTable {
 					xRefTable.LinearizationObjs[i] = true
-					log.Write.Printf("deleteRedundantObjects: primaryHintTable at obj #%d\n", i)
+					ctx.Log.Write().Printf("deleteRedundantObjects: primaryHintTable at obj #%d\n", i)
 				}
 
 				if xRefTable.OffsetOverflowHintTable != nil &&
 					*entry.Offset == *xRefTable.OffsetOverflowHintTable {
 					xRefTable.LinearizationObjs[i] = true
-					log.Write.Printf("deleteRedundantObjects: overflowHintTable at obj #%d\n", i)
+					ctx.Log.Write().Printf("deleteRedundantObjects: overflowHintTable at obj #%d\n", i)
 				}
 
 			}
@@ -523,7 +547,7 @@ func deleteRedundantObjects(ctx *Context) {
 
 	}
 
-	log.Write.Println("deleteRedundantObjects end")
+	ctx.Log.Write().Println("deleteRedundantObjects end")
 }
 
 func sortedWritableKeys(ctx *Context) []int {
@@ -547,7 +571,7 @@ func writeXRefTable(ctx *Context) error {
 	keys := sortedWritableKeys(ctx)
 
 	objCount := len(keys)
-	log.Write.Printf("xref has %d entries\n", objCount)
+	ctx.Log.Write().Printf("xref has %d entries\n", objCount)
 
 	if _, err := ctx.Write.WriteString("xref"); err != nil {
 		return err
@@ -631,7 +655,7 @@ func int64ToBuf(i int64, byteCount int) (buf []byte) {
 
 func createXRefStream(ctx *Context, i1, i2, i3 int, objNrs []int) ([]byte, *Array, error) {
 
-	log.Write.Println("createXRefStream begin")
+	ctx.Log.Write().Println("createXRefStream begin")
 
 	xRefTable := ctx.XRefTable
 
@@ -641,7 +665,7 @@ func createXRefStream(ctx *Context, i1, i2, i3 int, objNrs []int) ([]byte, *Arra
 	)
 
 	objCount := len(objNrs)
-	log.Write.Printf("createXRefStream: xref has %d entries\n", objCount)
+	ctx.Log.Write().Printf("createXRefStream: xref has %d entries\n", objCount)
 
 	start := objNrs[0]
 	size := 0
@@ -655,7 +679,7 @@ func createXRefStream(ctx *Context, i1, i2, i3 int, objNrs []int) ([]byte, *Arra
 		if entry.Free {
 
 			// unused
-			log.Write.Printf("createXRefStream: unused i=%d nextFreeAt:%d gen:%d\n", j, int(*entry.Offset), int(*entry.Generation))
+			ctx.Log.Write().Printf("createXRefStream: unused i=%d nextFreeAt:%d gen:%d\n", j, int(*entry.Offset), int(*entry.Generation))
 
 			s1 = int64ToBuf(0, i1)
 			s2 = int64ToBuf(*entry.Offset, i2)
@@ -664,7 +688,7 @@ func createXRefStream(ctx *Context, i1, i2, i3 int, objNrs []int) ([]byte, *Arra
 		} else if entry.Compressed {
 
 			// in use, compressed into object stream
-			log.Write.Printf("createXRefStream: compressed i=%d at objstr %d[%d]\n", j, int(*entry.ObjectStream), int(*entry.ObjectStreamInd))
+			ctx.Log.Write().Printf("createXRefStream: compressed i=%d at objstr %d[%d]\n", j, int(*entry.ObjectStream), int(*entry.ObjectStreamInd))
 
 			s1 = int64ToBuf(2, i1)
 			s2 = int64ToBuf(int64(*entry.ObjectStream), i2)
@@ -678,7 +702,7 @@ func createXRefStream(ctx *Context, i1, i2, i3 int, objNrs []int) ([]byte, *Arra
 			}
 
 			// in use, uncompressed
-			log.Write.Printf("createXRefStream: used i=%d offset:%d gen:%d\n", j, int(off), int(*entry.Generation))
+			ctx.Log.Write().Printf("createXRefStream: used i=%d offset:%d gen:%d\n", j, int(off), int(*entry.Generation))
 
 			s1 = int64ToBuf(1, i1)
 			s2 = int64ToBuf(off, i2)
@@ -686,7 +710,7 @@ func createXRefStream(ctx *Context, i1, i2, i3 int, objNrs []int) ([]byte, *Arra
 
 		}
 
-		log.Write.Printf("createXRefStream: written: %x %x %x \n", s1, s2, s3)
+		ctx.Log.Write().Printf("createXRefStream: written: %x %x %x \n", s1, s2, s3)
 
 		buf = append(buf, s1...)
 		buf = append(buf, s2...)
@@ -708,14 +732,14 @@ func createXRefStream(ctx *Context, i1, i2, i3 int, objNrs []int) ([]byte, *Arra
 	a = append(a, Integer(start))
 	a = append(a, Integer(size))
 
-	log.Write.Println("createXRefStream end")
+	ctx.Log.Write().Println("createXRefStream end")
 
 	return buf, &a, nil
 }
 
 func writeXRefStream(ctx *Context) error {
 
-	log.Write.Println("writeXRefStream begin")
+	ctx.Log.Write().Println("writeXRefStream begin")
 
 	xRefTable := ctx.XRefTable
 	xRefStreamDict := NewXRefStreamDict(ctx)
@@ -768,7 +792,7 @@ func writeXRefStream(ctx *Context) error {
 		return err
 	}
 
-	log.Write.Printf("writeXRefStream: xRefStreamDict: %s\n", xRefStreamDict)
+	ctx.Log.Write().Printf("writeXRefStream: xRefStreamDict: %s\n", xRefStreamDict)
 
 	if err = writeStreamDictObject(ctx, objNumber, 0, xRefStreamDict.StreamDict); err != nil {
 		return err
@@ -792,7 +816,7 @@ func writeXRefStream(ctx *Context) error {
 		return err
 	}
 
-	log.Write.Println("writeXRefStream end")
+	ctx.Log.Write().Println("writeXRefStream end")
 
 	return nil
 }
@@ -813,7 +837,7 @@ func writeEncryptDict(ctx *Context) error {
 		return err
 	}
 
-	return writeObject(ctx, objNumber, genNumber, d.PDFString())
+	return writeObject(ctx, objNumber, genNumber, ctx.pdfString(d))
 }
 
 func setupEncryption(ctx *Context) error {
@@ -889,7 +913,7 @@ func updateEncryption(ctx *Context) error {
 		ctx.OwnerPW = *ctx.OwnerPWNew
 	}
 
-	if ctx.E.R == 5 {
+	if ctx.E.R >= 5 {
 
 		if err = calcOAndU(ctx, d); err != nil {
 			return err
@@ -946,6 +970,13 @@ func handleEncryption(ctx *Context) error {
 
 	}
 
+	if ctx.Encrypt != nil && ctx.EncKey != nil && ctx.E != nil && ctx.E.R == 6 {
+		// AES-256 revision 6 is part of PDF 2.0, for PDF 1.7 declare Adobe extension level 8.
+		if err := ensureADBEExtensionLevel(ctx, 8); err != nil {
+			return err
+		}
+	}
+
 	// write xrefstream if using xrefstream only.
 	if ctx.Encrypt != nil && ctx.EncKey != nil && !ctx.Read.UsingXRefStreams {
 		ctx.WriteObjectStream = false
@@ -955,6 +986,37 @@ func handleEncryption(ctx *Context) error {
 	return nil
 }
 
+// ensureADBEExtensionLevel declares at least Adobe extension level l to PDF 1.7 in the catalog.
+func ensureADBEExtensionLevel(ctx *Context, l int) error {
+
+	d, err := ctx.DereferenceDict(ctx.RootDict["Extensions"])
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		d = NewDict()
+		ctx.RootDict.Update("Extensions", d)
+	}
+
+	o, err := ctx.Dereference(d["ADBE"])
+	if err != nil {
+		return err
+	}
+	if adbe, ok := o.(Dict); ok {
+		if i := adbe.IntEntry("ExtensionLevel"); i != nil && *i >= l {
+			return nil
+		}
+	}
+
+	d.Update("ADBE", Dict(map[string]Object{
+		"Type":           Name("DeveloperExtensions"),
+		"BaseVersion":    Name("1.7"),
+		"ExtensionLevel": Integer(l),
+	}))
+
+	return nil
+}
+
 func writeXRef(ctx *Context) error {
 
 	if ctx.WriteXRefStream {
@@ -18,9 +18,9 @@ package pdfcpu
 
 import (
 	"bytes"
-	"encoding/gob"
 	"image"
 	"image/color"
+	"image/jpeg"
 	"image/png"
 	"io"
 	"os"
@@ -686,16 +686,13 @@ func renderFlateEncodedImage(xRefTable *XRefTable, sd *StreamDict, thumb bool, r
 }
 
 func renderGrayToPng(im *PDFImage, resourceName string) (io.Reader, string, error) {
-	bb := bytes.NewReader(im.sd.Content)
-	dec := gob.NewDecoder(bb)
-
-	var img image.Gray
-	if err := dec.Decode(&img); err != nil {
+	img, err := jpeg.Decode(bytes.NewReader(im.sd.Content))
+	if err != nil {
 		return nil, "", err
 	}
 
 	var buf bytes.Buffer
-	if err := png.Encode(&buf, &img); err != nil {
+	if err := png.Encode(&buf, img); err != nil {
 		return nil, "", err
 	}
 
@@ -703,16 +700,13 @@ func renderGrayToPng(im *PDFImage, resourceName string) (io.Reader, string, erro
 }
 
 func renderRGBToPng(im *PDFImage, resourceName string) (io.Reader, string, error) {
-	bb := bytes.NewReader(im.sd.Content)
-	dec := gob.NewDecoder(bb)
-
-	var img image.YCbCr
-	if err := dec.Decode(&img); err != nil {
+	img, err := jpeg.Decode(bytes.NewReader(im.sd.Content))
+	if err != nil {
 		return nil, "", err
 	}
 
 	var buf bytes.Buffer
-	if err := png.Encode(&buf, &img); err != nil {
+	if err := png.Encode(&buf, img); err != nil {
 		return nil, "", err
 	}
 
@@ -720,11 +714,8 @@ func renderRGBToPng(im *PDFImage, resourceName string) (io.Reader, string, error
 }
 
 func renderCMYKToPng(im *PDFImage, resourceName string) (io.Reader, string, error) {
-	bb := bytes.NewReader(im.sd.Content)
-	dec := gob.NewDecoder(bb)
-
-	var img image.CMYK
-	if err := dec.Decode(&img); err != nil {
+	img, err := jpeg.Decode(bytes.NewReader(im.sd.Content))
+	if err != nil {
 		return nil, "", err
 	}
 
@@ -732,8 +723,7 @@ func renderCMYKToPng(im *PDFImage, resourceName string) (io.Reader, string, erro
 
 	for y := 0; y < im.h; y++ {
 		for x := 0; x < im.w; x++ {
-			c := img.At(x, y)
-			a := c.(color.CMYK)
+			a := color.CMYKModel.Convert(img.At(x, y)).(color.CMYK)
 			r, g, b := color.CMYKToRGB(255-a.C, 255-a.M, 255-a.Y, 255-a.K)
 			img1.SetRGBA(x, y, color.RGBA{r, g, b, 255})
 		}
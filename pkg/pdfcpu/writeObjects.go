@@ -19,7 +19,6 @@ package pdfcpu
 import (
 	"fmt"
 
-	"github.com/pdfcpu/pdfcpu/pkg/log"
 	"github.com/pkg/errors"
 )
 
@@ -68,7 +67,7 @@ func startObjectStream(ctx *Context) error {
 	// See 7.5.7 Object streams
 	// When new object streams and compressed objects are created, they shall always be assigned new object numbers.
 
-	log.Write.Println("startObjectStream begin")
+	ctx.Log.Write().Println("startObjectStream begin")
 
 	objStreamDict := NewObjectStreamDict()
 
@@ -79,14 +78,14 @@ func startObjectStream(ctx *Context) error {
 
 	ctx.Write.CurrentObjStream = &objNr
 
-	log.Write.Printf("startObjectStream end: %d\n", objNr)
+	ctx.Log.Write().Printf("startObjectStream end: %d\n", objNr)
 
 	return nil
 }
 
 func stopObjectStream(ctx *Context) error {
 
-	log.Write.Println("stopObjectStream begin")
+	ctx.Log.Write().Println("stopObjectStream begin")
 
 	xRefTable := ctx.XRefTable
 
@@ -96,7 +95,7 @@ func stopObjectStream(ctx *Context) error {
 
 	if ctx.Write.CurrentObjStream == nil {
 		ctx.Write.WriteToObjectStream = false
-		log.Write.Println("stopObjectStream end (no content)")
+		ctx.Log.Write().Println("stopObjectStream end (no content)")
 		return nil
 	}
 
@@ -119,7 +118,7 @@ func stopObjectStream(ctx *Context) error {
 	osd.StreamDict.Insert("N", Integer(osd.ObjCount))
 
 	// for each objStream execute at the end right before xRefStreamDict gets written.
-	log.Write.Printf("stopObjectStream: objStreamDict: %s\n", osd)
+	ctx.Log.Write().Printf("stopObjectStream: objStreamDict: %s\n", osd)
 
 	if err := writeStreamDictObject(ctx, *ctx.Write.CurrentObjStream, 0, osd.StreamDict); err != nil {
 		return err
@@ -131,14 +130,14 @@ func stopObjectStream(ctx *Context) error {
 	ctx.Write.CurrentObjStream = nil
 	ctx.Write.WriteToObjectStream = false
 
-	log.Write.Println("stopObjectStream end")
+	ctx.Log.Write().Println("stopObjectStream end")
 
 	return nil
 }
 
 func writeToObjectStream(ctx *Context, objNumber, genNumber int) (ok bool, err error) {
 
-	log.Write.Printf("addToObjectStream begin, obj#:%d gen#:%d\n", objNumber, genNumber)
+	ctx.Log.Write().Printf("addToObjectStream begin, obj#:%d gen#:%d\n", objNumber, genNumber)
 
 	w := ctx.Write
 
@@ -171,14 +170,20 @@ func writeToObjectStream(ctx *Context, objNumber, genNumber int) (ok bool, err e
 		w.SetWriteOffset(objNumber) // for a compressed obj this is supposed to be a fake offset. value does not matter.
 
 		// Append to prolog & content
-		err = objStreamDict.AddObject(objNumber, entry)
+		e := entry
+		if ctx.rewritesObjects() {
+			e1 := *entry
+			e1.Object = ctx.writeForm(entry.Object)
+			e = &e1
+		}
+		err = objStreamDict.AddObject(objNumber, e)
 		if err != nil {
 			return false, err
 		}
 
 		objStrEntry.Object = objStreamDict
 
-		log.Write.Printf("writeObject end, obj#%d written to objectStream #%d\n", objNumber, *ctx.Write.CurrentObjStream)
+		ctx.Log.Write().Printf("writeObject end, obj#%d written to objectStream #%d\n", objNumber, *ctx.Write.CurrentObjStream)
 
 		if objStreamDict.ObjCount == ObjectStreamMaxObjects {
 			err = stopObjectStream(ctx)
@@ -192,14 +197,14 @@ func writeToObjectStream(ctx *Context, objNumber, genNumber int) (ok bool, err e
 
 	}
 
-	log.Write.Printf("addToObjectStream end, obj#:%d gen#:%d\n", objNumber, genNumber)
+	ctx.Log.Write().Printf("addToObjectStream end, obj#:%d gen#:%d\n", objNumber, genNumber)
 
 	return ok, nil
 }
 
 func writeObject(ctx *Context, objNumber, genNumber int, s string) error {
 
-	log.Write.Printf("writeObject begin, obj#:%d gen#:%d <%s>\n", objNumber, genNumber, s)
+	ctx.Log.Write().Printf("writeObject begin, obj#:%d gen#:%d <%s>\n", objNumber, genNumber, s)
 
 	w := ctx.Write
 
@@ -232,7 +237,7 @@ func writeObject(ctx *Context, objNumber, genNumber int, s string) error {
 	// Write-offset for next object.
 	w.Offset += int64(written + i + j)
 
-	log.Write.Printf("writeObject end, %d bytes written\n", written+i+j)
+	ctx.Log.Write().Printf("writeObject end, %d bytes written\n", written+i+j)
 
 	return nil
 }
@@ -283,8 +288,8 @@ func writeStringLiteralObject(ctx *Context, objNumber, genNumber int, stringLite
 
 	sl := stringLiteral
 
-	if ctx.EncKey != nil {
-		s1, err := encryptString(stringLiteral.Value(), objNumber, genNumber, ctx.EncKey, ctx.AES4Strings, ctx.E.R)
+	if stringsEncrypted(ctx, objNumber) {
+		s1, err := encryptString(stringLiteral.Value(), objNumber, genNumber, ctx.EncKey, ctx.AES4Strings, ctx.E.R, ctx.Rand())
 		if err != nil {
 			return err
 		}
@@ -292,7 +297,7 @@ func writeStringLiteralObject(ctx *Context, objNumber, genNumber int, stringLite
 		sl = StringLiteral(*s1)
 	}
 
-	return writeObject(ctx, objNumber, genNumber, sl.PDFString())
+	return writeObject(ctx, objNumber, genNumber, ctx.pdfString(sl))
 }
 
 func writeHexLiteralObject(ctx *Context, objNumber, genNumber int, hexLiteral HexLiteral) error {
@@ -308,8 +313,8 @@ func writeHexLiteralObject(ctx *Context, objNumber, genNumber int, hexLiteral He
 
 	hl := hexLiteral
 
-	if ctx.EncKey != nil {
-		s1, err := encryptString(hexLiteral.Value(), objNumber, genNumber, ctx.EncKey, ctx.AES4Strings, ctx.E.R)
+	if stringsEncrypted(ctx, objNumber) {
+		s1, err := encryptString(hexLiteral.Value(), objNumber, genNumber, ctx.EncKey, ctx.AES4Strings, ctx.E.R, ctx.Rand())
 		if err != nil {
 			return err
 		}
@@ -317,7 +322,7 @@ func writeHexLiteralObject(ctx *Context, objNumber, genNumber int, hexLiteral He
 		hl = HexLiteral(*s1)
 	}
 
-	return writeObject(ctx, objNumber, genNumber, hl.PDFString())
+	return writeObject(ctx, objNumber, genNumber, ctx.pdfString(hl))
 }
 
 func writeIntegerObject(ctx *Context, objNumber, genNumber int, integer Integer) error {
@@ -345,7 +350,7 @@ func writeFloatObject(ctx *Context, objNumber, genNumber int, float Float) error
 		return nil
 	}
 
-	return writeObject(ctx, objNumber, genNumber, float.PDFString())
+	return writeObject(ctx, objNumber, genNumber, ctx.pdfString(float))
 }
 
 func writeDictObject(ctx *Context, objNumber, genNumber int, d Dict) error {
@@ -359,14 +364,14 @@ func writeDictObject(ctx *Context, objNumber, genNumber int, d Dict) error {
 		return nil
 	}
 
-	if ctx.EncKey != nil {
-		_, err := encryptDeepObject(d, objNumber, genNumber, ctx.EncKey, ctx.AES4Strings, ctx.E.R)
+	if stringsEncrypted(ctx, objNumber) {
+		_, err := encryptDeepObject(d, objNumber, genNumber, ctx.EncKey, ctx.AES4Strings, ctx.E.R, ctx.Rand())
 		if err != nil {
 			return err
 		}
 	}
 
-	return writeObject(ctx, objNumber, genNumber, d.PDFString())
+	return writeObject(ctx, objNumber, genNumber, ctx.pdfString(d))
 }
 
 func writeArrayObject(ctx *Context, objNumber, genNumber int, a Array) error {
@@ -380,14 +385,14 @@ func writeArrayObject(ctx *Context, objNumber, genNumber int, a Array) error {
 		return nil
 	}
 
-	if ctx.EncKey != nil {
-		_, err := encryptDeepObject(a, objNumber, genNumber, ctx.EncKey, ctx.AES4Strings, ctx.E.R)
+	if stringsEncrypted(ctx, objNumber) {
+		_, err := encryptDeepObject(a, objNumber, genNumber, ctx.EncKey, ctx.AES4Strings, ctx.E.R, ctx.Rand())
 		if err != nil {
 			return err
 		}
 	}
 
-	return writeObject(ctx, objNumber, genNumber, a.PDFString())
+	return writeObject(ctx, objNumber, genNumber, ctx.pdfString(a))
 }
 
 func writeStream(w *WriteContext, sd StreamDict) (int64, error) {
@@ -421,7 +426,7 @@ func handleIndirectLength(ctx *Context, ir *IndirectRef) error {
 	genNr := int(ir.GenerationNumber)
 
 	if ctx.Write.HasWriteOffset(objNr) {
-		log.Write.Printf("*** handleIndirectLength: object #%d already written offset=%d ***\n", objNr, ctx.Write.Offset)
+		ctx.Log.Write().Printf("*** handleIndirectLength: object #%d already written offset=%d ***\n", objNr, ctx.Write.Offset)
 	} else {
 		length, err := ctx.DereferenceInteger(*ir)
 		if err != nil || length == nil {
@@ -438,7 +443,7 @@ func handleIndirectLength(ctx *Context, ir *IndirectRef) error {
 
 func writeStreamDictObject(ctx *Context, objNumber, genNumber int, sd StreamDict) error {
 
-	log.Write.Printf("writeStreamDictObject begin: object #%d\n%v", objNumber, sd)
+	ctx.Log.Write().Printf("writeStreamDictObject begin: object #%d\n%v", objNumber, sd)
 
 	var inObjStream bool
 
@@ -457,13 +462,15 @@ func writeStreamDictObject(ctx *Context, objNumber, genNumber int, sd StreamDict
 
 	var err error
 
-	// Unless the "Identity" crypt filter is used we have to encrypt.
-	isXRefStreamDict := sd.Type() != nil && *sd.Type() == "XRef"
-	if ctx.EncKey != nil &&
-		!isXRefStreamDict &&
-		!(len(sd.FilterPipeline) == 1 && sd.FilterPipeline[0].Name == "Crypt") {
+	// Unless exempt like XRefStreams or streams using the "Identity" crypt filter we have to encrypt.
+	crypt, aes, err := streamCrypt(ctx, &sd, objNumber)
+	if err != nil {
+		return err
+	}
 
-		sd.Raw, err = encryptStream(sd.Raw, objNumber, genNumber, ctx.EncKey, ctx.AES4Streams, ctx.E.R)
+	if crypt {
+
+		sd.Raw, err = encryptStream(sd.Raw, objNumber, genNumber, ctx.EncKey, aes, ctx.E.R, ctx.Rand())
 		if err != nil {
 			return err
 		}
@@ -481,7 +488,7 @@ func writeStreamDictObject(ctx *Context, objNumber, genNumber int, sd StreamDict
 	}
 
 	// Note: Lines that are not part of stream object data are limited to no more than 255 characters.
-	pdfString := sd.PDFString()
+	pdfString := ctx.pdfString(sd)
 	_, err = ctx.Write.WriteString(pdfString)
 	if err != nil {
 		return err
@@ -501,12 +508,15 @@ func writeStreamDictObject(ctx *Context, objNumber, genNumber int, sd StreamDict
 
 	ctx.Write.Offset += written
 	ctx.Write.BinaryTotalSize += *sd.StreamLength
+	if c := binaryCategory(sd); c >= 0 {
+		ctx.Write.binarySizes[c] += *sd.StreamLength
+	}
 
 	if inObjStream {
 		ctx.Write.WriteToObjectStream = true
 	}
 
-	log.Write.Printf("writeStreamDictObject end: object #%d written=%d\n", objNumber, written)
+	ctx.Log.Write().Printf("writeStreamDictObject end: object #%d written=%d\n", objNumber, written)
 
 	return nil
 }
@@ -516,7 +526,8 @@ func writeDirectObject(ctx *Context, o Object) error {
 	switch o := o.(type) {
 
 	case Dict:
-		for k, v := range o {
+		for _, k := range ctx.dictKeys(o) {
+			v := o[k]
 			if ctx.writingPages && (k == "Dest" || k == "D") {
 				ctx.dest = true
 			}
@@ -526,7 +537,7 @@ func writeDirectObject(ctx *Context, o Object) error {
 			}
 			ctx.dest = false
 		}
-		log.Write.Printf("writeDirectObject: end offset=%d\n", ctx.Write.Offset)
+		ctx.Log.Write().Printf("writeDirectObject: end offset=%d\n", ctx.Write.Offset)
 
 	case Array:
 		for i, v := range o {
@@ -538,10 +549,10 @@ func writeDirectObject(ctx *Context, o Object) error {
 				return err
 			}
 		}
-		log.Write.Printf("writeDirectObject: end offset=%d\n", ctx.Write.Offset)
+		ctx.Log.Write().Printf("writeDirectObject: end offset=%d\n", ctx.Write.Offset)
 
 	default:
-		log.Write.Printf("writeDirectObject: end, direct obj - nothing written: offset=%d\n%v\n", ctx.Write.Offset, o)
+		ctx.Log.Write().Printf("writeDirectObject: end, direct obj - nothing written: offset=%d\n%v\n", ctx.Write.Offset, o)
 
 	}
 
@@ -568,7 +579,8 @@ func writeDeepDict(ctx *Context, d Dict, objNr, genNr int) error {
 		return err
 	}
 
-	for k, v := range d {
+	for _, k := range ctx.dictKeys(d) {
+		v := d[k]
 		if ctx.writingPages && (k == "Dest" || k == "D") {
 			ctx.dest = true
 		}
@@ -584,8 +596,8 @@ func writeDeepDict(ctx *Context, d Dict, objNr, genNr int) error {
 
 func writeDeepStreamDict(ctx *Context, sd *StreamDict, objNr, genNr int) error {
 
-	if ctx.EncKey != nil {
-		_, err := encryptDeepObject(*sd, objNr, genNr, ctx.EncKey, ctx.AES4Strings, ctx.E.R)
+	if stringsEncrypted(ctx, objNr) {
+		_, err := encryptDeepObject(*sd, objNr, genNr, ctx.EncKey, ctx.AES4Strings, ctx.E.R, ctx.Rand())
 		if err != nil {
 			return err
 		}
@@ -596,8 +608,8 @@ func writeDeepStreamDict(ctx *Context, sd *StreamDict, objNr, genNr int) error {
 		return err
 	}
 
-	for _, v := range sd.Dict {
-		_, _, err = writeDeepObject(ctx, v)
+	for _, k := range ctx.dictKeys(sd.Dict) {
+		_, _, err = writeDeepObject(ctx, sd.Dict[k])
 		if err != nil {
 			return err
 		}
@@ -632,7 +644,7 @@ func writeIndirectObject(ctx *Context, ir IndirectRef) (Object, error) {
 	genNr := int(ir.GenerationNumber)
 
 	if ctx.Write.HasWriteOffset(objNr) {
-		log.Write.Printf("writeIndirectObject end: object #%d already written.\n", objNr)
+		ctx.Log.Write().Printf("writeIndirectObject end: object #%d already written.\n", objNr)
 		return nil, nil
 	}
 
@@ -641,7 +653,7 @@ func writeIndirectObject(ctx *Context, ir IndirectRef) (Object, error) {
 		return nil, errors.Wrapf(err, "writeIndirectObject: unable to dereference indirect object #%d", objNr)
 	}
 
-	log.Write.Printf("writeIndirectObject: object #%d gets writeoffset: %d\n", objNr, ctx.Write.Offset)
+	ctx.Log.Write().Printf("writeIndirectObject: object #%d gets writeoffset: %d\n", objNr, ctx.Write.Offset)
 
 	if o == nil {
 
@@ -650,7 +662,7 @@ func writeIndirectObject(ctx *Context, ir IndirectRef) (Object, error) {
 			return nil, err
 		}
 
-		log.Write.Printf("writeIndirectObject: end, obj#%d resolved to nil, offset=%d\n", objNr, ctx.Write.Offset)
+		ctx.Log.Write().Printf("writeIndirectObject: end, obj#%d resolved to nil, offset=%d\n", objNr, ctx.Write.Offset)
 		return nil, nil
 	}
 
@@ -693,7 +705,7 @@ func writeIndirectObject(ctx *Context, ir IndirectRef) (Object, error) {
 
 func writeDeepObject(ctx *Context, objIn Object) (objOut Object, written bool, err error) {
 
-	log.Write.Printf("writeDeepObject: begin offset=%d\n%s\n", ctx.Write.Offset, objIn)
+	ctx.Log.Write().Printf("writeDeepObject: begin offset=%d\n%s\n", ctx.Write.Offset, objIn)
 
 	ir, ok := objIn.(IndirectRef)
 	if !ok {
@@ -703,7 +715,7 @@ func writeDeepObject(ctx *Context, objIn Object) (objOut Object, written bool, e
 	objOut, err = writeIndirectObject(ctx, ir)
 	if err == nil {
 		written = true
-		log.Write.Printf("writeDeepObject: end offset=%d\n", ctx.Write.Offset)
+		ctx.Log.Write().Printf("writeDeepObject: end offset=%d\n", ctx.Write.Offset)
 	}
 
 	return objOut, written, err
@@ -713,11 +725,11 @@ func writeEntry(ctx *Context, d Dict, dictName, entryName string) (Object, error
 
 	o, found := d.Find(entryName)
 	if !found || o == nil {
-		log.Write.Printf("writeEntry end: entry %s is nil\n", entryName)
+		ctx.Log.Write().Printf("writeEntry end: entry %s is nil\n", entryName)
 		return nil, nil
 	}
 
-	log.Write.Printf("writeEntry begin: dict=%s entry=%s offset=%d\n", dictName, entryName, ctx.Write.Offset)
+	ctx.Log.Write().Printf("writeEntry begin: dict=%s entry=%s offset=%d\n", dictName, entryName, ctx.Write.Offset)
 
 	o, _, err := writeDeepObject(ctx, o)
 	if err != nil {
@@ -725,11 +737,11 @@ func writeEntry(ctx *Context, d Dict, dictName, entryName string) (Object, error
 	}
 
 	if o == nil {
-		log.Write.Printf("writeEntry end: dict=%s entry=%s resolved to nil, offset=%d\n", dictName, entryName, ctx.Write.Offset)
+		ctx.Log.Write().Printf("writeEntry end: dict=%s entry=%s resolved to nil, offset=%d\n", dictName, entryName, ctx.Write.Offset)
 		return nil, nil
 	}
 
-	log.Write.Printf("writeEntry end: dict=%s entry=%s offset=%d\n", dictName, entryName, ctx.Write.Offset)
+	ctx.Log.Write().Printf("writeEntry end: dict=%s entry=%s offset=%d\n", dictName, entryName, ctx.Write.Offset)
 
 	return o, nil
 }
@@ -17,7 +17,6 @@ limitations under the License.
 package pdfcpu
 
 import (
-	"github.com/pdfcpu/pdfcpu/pkg/log"
 	"github.com/pkg/errors"
 )
 
@@ -42,11 +41,11 @@ func writePageDict(ctx *Context, ir *IndirectRef, pageDict Dict, pageNr int) err
 	genNr := ir.GenerationNumber.Value()
 
 	if ctx.Write.HasWriteOffset(objNr) {
-		log.Write.Printf("writePageDict: object #%d already written.\n", objNr)
+		ctx.Log.Write().Printf("writePageDict: object #%d already written.\n", objNr)
 		return nil
 	}
 
-	log.Write.Printf("writePageDict: logical pageNr=%d object #%d gets writeoffset: %d\n", pageNr, objNr, ctx.Write.Offset)
+	ctx.Log.Write().Printf("writePageDict: logical pageNr=%d object #%d gets writeoffset: %d\n", pageNr, objNr, ctx.Write.Offset)
 
 	dictName := "pageDict"
 
@@ -54,7 +53,7 @@ func writePageDict(ctx *Context, ir *IndirectRef, pageDict Dict, pageNr int) err
 		return err
 	}
 
-	log.Write.Printf("writePageDict: new offset = %d\n", ctx.Write.Offset)
+	ctx.Log.Write().Printf("writePageDict: new offset = %d\n", ctx.Write.Offset)
 
 	if ir := pageDict.IndirectRefEntry("Parent"); ir == nil {
 		return errors.New("pdfcpu: writePageDict: missing parent")
@@ -102,7 +101,7 @@ func writePageDict(ctx *Context, ir *IndirectRef, pageDict Dict, pageNr int) err
 
 	ctx.writingPages = false
 
-	log.Write.Printf("*** writePageDict end: obj#%d offset=%d ***\n", objNr, ctx.Write.Offset)
+	ctx.Log.Write().Printf("*** writePageDict end: obj#%d offset=%d ***\n", objNr, ctx.Write.Offset)
 
 	return nil
 }
@@ -110,7 +109,7 @@ func writePageDict(ctx *Context, ir *IndirectRef, pageDict Dict, pageNr int) err
 func pageNodeDict(ctx *Context, o Object) (d Dict, indRef *IndirectRef, err error) {
 
 	if o == nil {
-		log.Write.Println("pageNodeDict: is nil")
+		ctx.Log.Write().Println("pageNodeDict: is nil")
 		return nil, nil, nil
 	}
 
@@ -119,7 +118,7 @@ func pageNodeDict(ctx *Context, o Object) (d Dict, indRef *IndirectRef, err erro
 	if !ok {
 		return nil, nil, errors.New("pdfcpu: pageNodeDict: missing indirect reference")
 	}
-	log.Write.Printf("pageNodeDict: PageNode: %s\n", ir)
+	ctx.Log.Write().Printf("pageNodeDict: PageNode: %s\n", ir)
 
 	d, err = ctx.DereferenceDict(ir)
 	if err != nil {
@@ -144,6 +143,10 @@ func writeKids(ctx *Context, a Array, pageNr *int) (Array, int, error) {
 
 	for _, o := range a {
 
+		if err := ctx.Canceled(); err != nil {
+			return nil, 0, err
+		}
+
 		d, ir, err := pageNodeDict(ctx, o)
 		if err != nil {
 			return nil, 0, err
@@ -168,21 +171,21 @@ func writeKids(ctx *Context, a Array, pageNr *int) (Array, int, error) {
 		case "Page":
 			*pageNr++
 			if len(ctx.Write.SelectedPages) > 0 {
-				log.Write.Printf("selectedPages: %v\n", ctx.Write.SelectedPages)
+				ctx.Log.Write().Printf("selectedPages: %v\n", ctx.Write.SelectedPages)
 				writePage := ctx.Write.SelectedPages[*pageNr]
 				if ctx.Cmd == REMOVEPAGES {
 					writePage = !writePage
 				}
 				if writePage {
-					log.Write.Printf("writeKids: writing page:%d\n", *pageNr)
+					ctx.Log.Write().Printf("writeKids: writing page:%d\n", *pageNr)
 					err = writePageDict(ctx, ir, d, *pageNr)
 					kids = append(kids, o)
 					count++
 				} else {
-					log.Write.Printf("writeKids: skipping page:%d\n", *pageNr)
+					ctx.Log.Write().Printf("writeKids: skipping page:%d\n", *pageNr)
 				}
 			} else {
-				log.Write.Printf("writeKids: writing page anyway:%d\n", *pageNr)
+				ctx.Log.Write().Printf("writeKids: writing page anyway:%d\n", *pageNr)
 				err = writePageDict(ctx, ir, d, *pageNr)
 				kids = append(kids, o)
 				count++
@@ -213,7 +216,7 @@ func containsSelectedPages(ctx *Context, from, thru int) bool {
 
 func writePagesDict(ctx *Context, ir *IndirectRef, pageNr *int) (skip bool, writtenPages int, err error) {
 
-	log.Write.Printf("writePagesDict: begin pageNr=%d\n", *pageNr)
+	ctx.Log.Write().Printf("writePagesDict: begin pageNr=%d\n", *pageNr)
 
 	dictName := "pagesDict"
 	objNr := int(ir.ObjectNumber)
@@ -232,12 +235,12 @@ func writePagesDict(ctx *Context, ir *IndirectRef, pageNr *int) (skip bool, writ
 	// In these cases the selected pages to be written or to be removed are defined in ctx.Write.SelectedPages.
 	if len(ctx.Write.SelectedPages) > 0 {
 		c := int(countOrig.(Integer))
-		log.Write.Printf("writePagesDict: checking page range %d - %d \n", *pageNr+1, *pageNr+c)
+		ctx.Log.Write().Printf("writePagesDict: checking page range %d - %d \n", *pageNr+1, *pageNr+c)
 		if ctx.Cmd == REMOVEPAGES ||
 			((ctx.Cmd == TRIM) && containsSelectedPages(ctx, *pageNr+1, *pageNr+c)) {
-			log.Write.Println("writePagesDict: process this subtree")
+			ctx.Log.Write().Println("writePagesDict: process this subtree")
 		} else {
-			log.Write.Println("writePagesDict: skip this subtree")
+			ctx.Log.Write().Println("writePagesDict: skip this subtree")
 			*pageNr += c
 			return true, 0, nil
 		}
@@ -252,7 +255,7 @@ func writePagesDict(ctx *Context, ir *IndirectRef, pageNr *int) (skip bool, writ
 
 	d.Update("Kids", kidsNew)
 	d.Update("Count", Integer(countNew))
-	log.Write.Printf("writePagesDict: writing pageDict for obj=%d page=%d\n%s", objNr, *pageNr, d)
+	ctx.Log.Write().Printf("writePagesDict: writing pageDict for obj=%d page=%d\n%s", objNr, *pageNr, d)
 
 	if err = writeDictObject(ctx, objNr, genNr, d); err != nil {
 		return false, 0, err
@@ -277,7 +280,37 @@ func writePagesDict(ctx *Context, ir *IndirectRef, pageNr *int) (skip bool, writ
 	d.Update("Kids", kidsOrig)
 	d.Update("Count", countOrig)
 
-	log.Write.Printf("writePagesDict: end pageNr=%d\n", *pageNr)
+	ctx.Log.Write().Printf("writePagesDict: end pageNr=%d\n", *pageNr)
 
 	return false, countNew, nil
 }
+
+// pruneForWriting removes structure and form fields related to the pages not getting written during TRIM and REMOVEPAGES.
+func (ctx *Context) pruneForWriting() error {
+
+	if len(ctx.Write.SelectedPages) == 0 || (ctx.Cmd != TRIM && ctx.Cmd != REMOVEPAGES) {
+		return nil
+	}
+
+	objNrs, err := ctx.pageObjNrs()
+	if err != nil {
+		return err
+	}
+
+	removed := IntSet{}
+	for i, objNr := range objNrs {
+		writePage := ctx.Write.SelectedPages[i+1]
+		if ctx.Cmd == REMOVEPAGES {
+			writePage = !writePage
+		}
+		if !writePage {
+			removed[objNr] = true
+		}
+	}
+
+	if err := ctx.PruneStructTree(removed); err != nil {
+		return err
+	}
+
+	return ctx.PruneAcroForm(removed)
+}
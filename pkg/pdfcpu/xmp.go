@@ -0,0 +1,401 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// XMP namespaces of the properties corresponding to document information dict entries.
+const (
+	nsRDF = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsDC  = "http://purl.org/dc/elements/1.1/"
+	nsXMP = "http://ns.adobe.com/xap/1.0/"
+	nsPDF = "http://ns.adobe.com/pdf/1.3/"
+)
+
+// xmpPacket is the skeleton of a new XMP metadata stream.
+const xmpPacket = "<?xpacket begin=\"\ufeff\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n" +
+	"<x:xmpmeta xmlns:x=\"adobe:ns:meta/\">\n" +
+	"<rdf:RDF xmlns:rdf=\"" + nsRDF + "\">\n" +
+	"</rdf:RDF>\n" +
+	"</x:xmpmeta>\n" +
+	"<?xpacket end=\"w\"?>"
+
+var (
+	rdfRDF         = xml.Name{Space: nsRDF, Local: "RDF"}
+	rdfDescription = xml.Name{Space: nsRDF, Local: "Description"}
+	rdfLi          = xml.Name{Space: nsRDF, Local: "li"}
+	rdfAbout       = xml.Name{Space: nsRDF, Local: "about"}
+)
+
+// XMP value types.
+const (
+	xmpText = iota
+	xmpDate
+	xmpLangAlt // Language alternative, we use x-default only.
+	xmpSeq     // Ordered array, we use a single item.
+)
+
+// xmpProperty is the XMP counterpart of a document information dict entry.
+type xmpProperty struct {
+	name xml.Name
+	kind int
+	text func(m *DocumentMetadata) *string
+	date func(m *DocumentMetadata) *time.Time
+}
+
+var xmpProperties = []xmpProperty{
+	{name: xml.Name{Space: nsDC, Local: "title"}, kind: xmpLangAlt, text: func(m *DocumentMetadata) *string { return &m.Title }},
+	{name: xml.Name{Space: nsDC, Local: "creator"}, kind: xmpSeq, text: func(m *DocumentMetadata) *string { return &m.Author }},
+	{name: xml.Name{Space: nsDC, Local: "description"}, kind: xmpLangAlt, text: func(m *DocumentMetadata) *string { return &m.Subject }},
+	{name: xml.Name{Space: nsPDF, Local: "Keywords"}, kind: xmpText, text: func(m *DocumentMetadata) *string { return &m.Keywords }},
+	{name: xml.Name{Space: nsXMP, Local: "CreatorTool"}, kind: xmpText, text: func(m *DocumentMetadata) *string { return &m.Creator }},
+	{name: xml.Name{Space: nsPDF, Local: "Producer"}, kind: xmpText, text: func(m *DocumentMetadata) *string { return &m.Producer }},
+	{name: xml.Name{Space: nsXMP, Local: "CreateDate"}, kind: xmpDate, date: func(m *DocumentMetadata) *time.Time { return &m.CreationDate }},
+	{name: xml.Name{Space: nsXMP, Local: "ModifyDate"}, kind: xmpDate, date: func(m *DocumentMetadata) *time.Time { return &m.ModDate }},
+	{name: xml.Name{Space: nsXMP, Local: "MetadataDate"}, kind: xmpDate, date: func(m *DocumentMetadata) *time.Time { return &m.ModDate }},
+}
+
+var xmpPrefixes = map[string]string{nsDC: "dc", nsXMP: "xmp", nsPDF: "pdf"}
+
+func xmpPropertyFor(n xml.Name) (xmpProperty, bool) {
+	for _, p := range xmpProperties {
+		if p.name == n {
+			return p, true
+		}
+	}
+	return xmpProperty{}, false
+}
+
+func parseXMPDate(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02T15:04:05", "2006-01-02", "2006-01", "2006"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// set assigns the XMP value s to the corresponding field of m unless already set.
+func (p xmpProperty) set(m *DocumentMetadata, s string) {
+	if p.text != nil {
+		if f := p.text(m); *f == "" {
+			*f = s
+		}
+		return
+	}
+	if f := p.date(m); f.IsZero() {
+		if t, ok := parseXMPDate(s); ok {
+			*f = t
+		}
+	}
+}
+
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// element returns the XMP property element for value v.
+func (p xmpProperty) element(v string) string {
+	qn := xmpPrefixes[p.name.Space] + ":" + p.name.Local
+	v = xmlEscape(v)
+	switch p.kind {
+	case xmpLangAlt:
+		v = "<rdf:Alt><rdf:li xml:lang=\"x-default\">" + v + "</rdf:li></rdf:Alt>"
+	case xmpSeq:
+		v = "<rdf:Seq><rdf:li>" + v + "</rdf:li></rdf:Seq>"
+	}
+	return "<" + qn + ">" + v + "</" + qn + ">"
+}
+
+// xmpValues returns the XMP property values for all fields set in m.
+func (m DocumentMetadata) xmpValues() map[xml.Name]string {
+	vv := map[xml.Name]string{}
+	for _, p := range xmpProperties {
+		if p.text != nil {
+			if s := *p.text(&m); s != "" {
+				vv[p.name] = s
+			}
+			continue
+		}
+		if t := *p.date(&m); !t.IsZero() {
+			vv[p.name] = t.Format(time.RFC3339)
+		}
+	}
+	return vv
+}
+
+// xmpValue consumes the remaining tokens of a property element and returns its value.
+func xmpValue(dec *xml.Decoder, p xmpProperty) (string, error) {
+	var (
+		ss []string
+		sb strings.Builder
+	)
+
+	for depth := 1; depth > 0; {
+		t, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			depth++
+			if t.Name == rdfLi {
+				sb.Reset()
+			}
+		case xml.EndElement:
+			depth--
+			if t.Name == rdfLi {
+				ss = append(ss, strings.TrimSpace(sb.String()))
+			}
+		case xml.CharData:
+			sb.Write(t)
+		}
+	}
+
+	if len(ss) == 0 {
+		return strings.TrimSpace(sb.String()), nil
+	}
+	if p.kind == xmpLangAlt {
+		return ss[0], nil
+	}
+	return strings.Join(ss, ", "), nil
+}
+
+// parseXMP returns the metadata found in the XMP packet b.
+// Properties may be given as elements or as attributes of rdf:Description.
+func parseXMP(b []byte) (DocumentMetadata, error) {
+	var (
+		m     DocumentMetadata
+		stack []xml.Name
+	)
+
+	dec := xml.NewDecoder(bytes.NewReader(b))
+
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			return m, nil
+		}
+		if err != nil {
+			return m, err
+		}
+
+		switch t := t.(type) {
+
+		case xml.StartElement:
+			if len(stack) > 0 && stack[len(stack)-1] == rdfDescription {
+				if p, ok := xmpPropertyFor(t.Name); ok {
+					s, err := xmpValue(dec, p)
+					if err != nil {
+						return m, err
+					}
+					p.set(&m, s)
+					continue
+				}
+			}
+			stack = append(stack, t.Name)
+			if t.Name == rdfDescription {
+				for _, a := range t.Attr {
+					if p, ok := xmpPropertyFor(a.Name); ok {
+						p.set(&m, a.Value)
+					}
+				}
+			}
+
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// stripAttrs removes the attributes names from the raw start tag s.
+func stripAttrs(s string, names []xml.Name, prefixes map[string][]string) string {
+	for _, n := range names {
+		for _, prefix := range prefixes[n.Space] {
+			re := regexp.MustCompile(`\s+` + regexp.QuoteMeta(prefix) + `:` + regexp.QuoteMeta(n.Local) + `\s*=\s*("[^"]*"|'[^']*')`)
+			s = re.ReplaceAllString(s, "")
+		}
+	}
+	return s
+}
+
+func xmpDescription(vv map[xml.Name]string, about string) string {
+	var sb strings.Builder
+	sb.WriteString("<rdf:Description xmlns:rdf=\"" + nsRDF + "\" rdf:about=\"" + xmlEscape(about) + "\"")
+	for _, ns := range []string{nsDC, nsXMP, nsPDF} {
+		sb.WriteString(" xmlns:" + xmpPrefixes[ns] + "=\"" + ns + "\"")
+	}
+	sb.WriteString(">\n")
+	for _, p := range xmpProperties {
+		if v, ok := vv[p.name]; ok {
+			sb.WriteString(p.element(v) + "\n")
+		}
+	}
+	sb.WriteString("</rdf:Description>\n")
+	return sb.String()
+}
+
+type xmpEdit struct {
+	from, to int64
+	s        string
+}
+
+// xmpDescriptionState tracks a top level rdf:Description while patching.
+type xmpDescriptionState struct {
+	from  int64 // Offset of the start tag.
+	depth int   // Element nesting depth.
+	edits int   // Number of edits before this description.
+	keep  bool  // Any properties left.
+}
+
+// keepsAttrs returns true if start carries any property attributes not replaced by vv.
+func keepsAttrs(start xml.StartElement, vv map[xml.Name]string) bool {
+	for _, a := range start.Attr {
+		if a.Name.Space == "xmlns" || a.Name.Space == "" && a.Name.Local == "xmlns" || a.Name == rdfAbout {
+			continue
+		}
+		if _, ok := vv[a.Name]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// patchXMP replaces the properties vv in the XMP packet b leaving everything else untouched.
+// Existing occurrences get removed and the new values go into a separate rdf:Description.
+// Descriptions left without properties are dropped.
+func patchXMP(b []byte, vv map[xml.Name]string) ([]byte, error) {
+	var (
+		edits    []xmpEdit
+		stack    []xml.Name
+		desc     *xmpDescriptionState
+		about    *string
+		insertAt int64 = -1
+	)
+
+	prefixes := map[string][]string{}
+
+	dec := xml.NewDecoder(bytes.NewReader(b))
+
+	for {
+		off := dec.InputOffset()
+		t, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := t.(type) {
+
+		case xml.StartElement:
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" {
+					prefixes[a.Value] = append(prefixes[a.Value], a.Name.Local)
+				}
+			}
+
+			if len(stack) > 0 && stack[len(stack)-1] == rdfDescription {
+				if _, ok := vv[t.Name]; ok {
+					if err := dec.Skip(); err != nil {
+						return nil, err
+					}
+					edits = append(edits, xmpEdit{from: off, to: dec.InputOffset()})
+					continue
+				}
+				if desc != nil && len(stack) == desc.depth {
+					desc.keep = true
+				}
+			}
+
+			stack = append(stack, t.Name)
+
+			if t.Name != rdfDescription {
+				continue
+			}
+
+			if len(stack) > 1 && stack[len(stack)-2] == rdfRDF {
+				desc = &xmpDescriptionState{from: off, depth: len(stack), edits: len(edits), keep: keepsAttrs(t, vv)}
+			}
+
+			var names []xml.Name
+			for _, a := range t.Attr {
+				if a.Name == rdfAbout && about == nil {
+					s := a.Value
+					about = &s
+				}
+				if _, ok := vv[a.Name]; ok {
+					names = append(names, a.Name)
+				}
+			}
+			if len(names) > 0 {
+				to := dec.InputOffset()
+				edits = append(edits, xmpEdit{from: off, to: to, s: stripAttrs(string(b[off:to]), names, prefixes)})
+			}
+
+		case xml.CharData:
+			if desc != nil && len(stack) == desc.depth && len(bytes.TrimSpace(t)) > 0 {
+				desc.keep = true
+			}
+
+		case xml.EndElement:
+			if desc != nil && len(stack) == desc.depth {
+				if !desc.keep {
+					edits = append(edits[:desc.edits], xmpEdit{from: desc.from, to: dec.InputOffset()})
+				}
+				desc = nil
+			}
+			stack = stack[:len(stack)-1]
+			if t.Name == rdfRDF && insertAt < 0 {
+				insertAt = off
+			}
+		}
+	}
+	if insertAt < 0 {
+		return nil, errors.New("pdfcpu: XMP metadata: missing rdf:RDF")
+	}
+
+	if about == nil {
+		s := ""
+		about = &s
+	}
+
+	edits = append(edits, xmpEdit{from: insertAt, to: insertAt, s: xmpDescription(vv, *about)})
+
+	var buf bytes.Buffer
+	var i int64
+	for _, e := range edits {
+		buf.Write(b[i:e.from])
+		buf.WriteString(e.s)
+		i = e.to
+	}
+	buf.Write(b[i:])
+
+	return buf.Bytes(), nil
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package pdfcpu
 
 import (
+	"context"
+	"crypto/rand"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -25,6 +27,7 @@ import (
 	"path"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pdfcpu/pdfcpu/pkg/filter"
@@ -75,7 +78,10 @@ type Enc struct {
 	OE, UE     []byte
 	Perms      []byte
 	L, P, R, V int
-	Emd        bool // encrypt meta data
+	Emd        bool   // encrypt meta data
+	StmF, StrF string // crypt filters for streams and strings
+	EFF        string // crypt filter for embedded file streams
+	EmptyUPW   bool   // document opens without user password
 	ID         []byte
 }
 
@@ -130,6 +136,13 @@ type XRefTable struct {
 
 	Tagged bool // File is using tags. This is important for ???
 
+	// Non fatal anomalies found while reading and validating.
+	Warnings []string
+	warnMu   sync.Mutex
+
+	// Loggers for reading, validating, optimizing and writing this document, see Configuration.Loggers.
+	Log log.ContextLoggers
+
 	// Validation
 	CurPage        int                       // current page during validation
 	CurObj         int                       // current object during validation, the last dereferenced object
@@ -137,13 +150,79 @@ type XRefTable struct {
 	ValidateLinks  bool                      // check for broken links in LinkAnnotations/URIDicts.
 	Valid          bool                      // true means successful validated against ISO 32000.
 	URIs           map[int]map[string]string // URIs for link checking
+	Report         *ValidationReport         // collects all findings instead of failing on the first one if set.
 
 	Optimized   bool
 	Watermarked bool
+
+	// Cancellation
+	cancelCtx context.Context // stops long running operations once done, see SetCancelContext.
+
+	clock Clock // source of dates written into the document, see SetClock.
+
+	rnd io.Reader // source of randomness, see SetRand.
+}
+
+// SetCancelContext makes long running operations like reading, validating, optimizing and writing
+// stop with c's error once c is done.
+func (xRefTable *XRefTable) SetCancelContext(c context.Context) {
+	xRefTable.cancelCtx = c
+}
+
+// Canceled returns a non nil error if the context set via SetCancelContext is done.
+func (xRefTable *XRefTable) Canceled() error {
+	if xRefTable.cancelCtx == nil {
+		return nil
+	}
+	return xRefTable.cancelCtx.Err()
+}
+
+// SetClock makes c the source of creation and modification dates, annotation dates and file identifiers.
+func (xRefTable *XRefTable) SetClock(c Clock) {
+	xRefTable.clock = c
+}
+
+// Now returns the current time according to the clock set via SetClock, defaults to the system time.
+func (xRefTable *XRefTable) Now() time.Time {
+	if xRefTable.clock == nil {
+		return time.Now()
+	}
+	return xRefTable.clock.Now()
+}
+
+// lockedReader serializes reads from a source of randomness shared by cloned contexts.
+type lockedReader struct {
+	mu sync.Mutex
+	r  io.Reader
+}
+
+func (lr *lockedReader) Read(p []byte) (int, error) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	return lr.r.Read(p)
+}
+
+// SetRand makes r the source of randomness for encryption keys, initialization vectors, salts and font subset tags.
+// Clones share r, so reads get serialized and r need not be safe for concurrent use.
+func (xRefTable *XRefTable) SetRand(r io.Reader) {
+	if r != nil {
+		if _, ok := r.(*lockedReader); !ok {
+			r = &lockedReader{r: r}
+		}
+	}
+	xRefTable.rnd = r
+}
+
+// Rand returns the source of randomness set via SetRand, defaults to crypto/rand.Reader.
+func (xRefTable *XRefTable) Rand() io.Reader {
+	if xRefTable.rnd == nil {
+		return rand.Reader
+	}
+	return xRefTable.rnd
 }
 
 // NewXRefTable creates a new XRefTable.
-func newXRefTable(validationMode int, validateLinks bool) (xRefTable *XRefTable) {
+func newXRefTable(conf *Configuration) (xRefTable *XRefTable) {
 	return &XRefTable{
 		Table:             map[int]*XRefTableEntry{},
 		Names:             map[string]*Node{},
@@ -152,8 +231,9 @@ func newXRefTable(validationMode int, validateLinks bool) (xRefTable *XRefTable)
 		PageAnnots:        map[int]PgAnnots{},
 		PageThumbs:        map[int]IndirectRef{},
 		Stats:             NewPDFStats(),
-		ValidationMode:    validationMode,
-		ValidateLinks:     validateLinks,
+		ValidationMode:    conf.ValidationMode,
+		ValidateLinks:     conf.ValidateLinks,
+		Log:               log.NewContextLoggers(conf.Loggers),
 		URIs:              map[int]map[string]string{},
 	}
 }
@@ -189,6 +269,11 @@ func (xRefTable *XRefTable) ParseRootVersion() (v *string, err error) {
 	return rootDict.NameEntry("Version"), nil
 }
 
+// StrictValidation returns true if validation sticks to the letter of the spec, ie. in strict and pedantic mode.
+func (xRefTable *XRefTable) StrictValidation() bool {
+	return xRefTable.ValidationMode == ValidationStrict || xRefTable.ValidationMode == ValidationPedantic
+}
+
 // ValidateVersion validates against the xRefTable's version.
 func (xRefTable *XRefTable) ValidateVersion(element string, sinceVersion Version) error {
 
@@ -326,7 +411,7 @@ func (xRefTable *XRefTable) InsertAndUseRecycled(xRefTableEntry XRefTableEntry)
 	// This is because pdfcpu does not reuse objects
 	// in an incremental fashion like laid out in the PDF spec.
 
-	log.Write.Println("InsertAndUseRecycled: begin")
+	xRefTable.Log.Write().Println("InsertAndUseRecycled: begin")
 
 	// Get Next free object from freelist.
 	freeListHeadEntry, err := xRefTable.Free(0)
@@ -338,7 +423,7 @@ func (xRefTable *XRefTable) InsertAndUseRecycled(xRefTableEntry XRefTableEntry)
 	if *freeListHeadEntry.Offset == 0 {
 		xRefTableEntry.RefCount = 1
 		objNr = xRefTable.InsertNew(xRefTableEntry)
-		log.Write.Printf("InsertAndUseRecycled: end, new objNr=%d\n", objNr)
+		xRefTable.Log.Write().Printf("InsertAndUseRecycled: end, new objNr=%d\n", objNr)
 		return objNr, nil
 	}
 
@@ -361,7 +446,7 @@ func (xRefTable *XRefTable) InsertAndUseRecycled(xRefTableEntry XRefTableEntry)
 	xRefTableEntry.RefCount = 1
 	xRefTable.Table[objNr] = &xRefTableEntry
 
-	log.Write.Printf("InsertAndUseRecycled: end, recycled objNr=%d\n", objNr)
+	xRefTable.Log.Write().Printf("InsertAndUseRecycled: end, recycled objNr=%d\n", objNr)
 
 	return objNr, nil
 }
@@ -384,6 +469,15 @@ func (xRefTable *XRefTable) IndRefForNewObject(obj Object) (*IndirectRef, error)
 	return NewIndirectRef(objNr, *xRefTableEntry.Generation), nil
 }
 
+// Warn records a non fatal anomaly.
+func (xRefTable *XRefTable) Warn(format string, args ...interface{}) {
+	s := fmt.Sprintf(format, args...)
+	xRefTable.Log.Info().Printf("warning: %s\n", s)
+	xRefTable.warnMu.Lock()
+	xRefTable.Warnings = append(xRefTable.Warnings, s)
+	xRefTable.warnMu.Unlock()
+}
+
 // NewStreamDictForBuf creates a streamDict for buf.
 func (xRefTable *XRefTable) NewStreamDictForBuf(buf []byte) (*StreamDict, error) {
 	sd := StreamDict{
@@ -430,8 +524,8 @@ func (xRefTable *XRefTable) NewEmbeddedStreamDict(r io.Reader, modDate time.Time
 }
 
 // NewFileSpectDictForAttachment returns a fileSpecDict for a.
-func (xRefTable *XRefTable) NewFileSpectDictForAttachment(a Attachment) (*IndirectRef, error) {
-	modTime := time.Now()
+func (xRefTable *XRefTable) NewFileSpectDictForAttachment(a Attachment, checkSum bool) (*IndirectRef, error) {
+	modTime := xRefTable.Now()
 	if a.ModTime != nil {
 		modTime = *a.ModTime
 	}
@@ -440,6 +534,16 @@ func (xRefTable *XRefTable) NewFileSpectDictForAttachment(a Attachment) (*Indire
 		return nil, err
 	}
 
+	if checkSum {
+		esd, _, err := xRefTable.DereferenceStreamDict(*sd)
+		if err != nil {
+			return nil, err
+		}
+		if err = xRefTable.UpdateEmbeddedFileParams(esd); err != nil {
+			return nil, err
+		}
+	}
+
 	d, err := xRefTable.NewFileSpecDict(a.ID, encodeUTF16String(a.ID), a.Desc, *sd)
 	if err != nil {
 		return nil, err
@@ -1136,11 +1240,11 @@ func (xRefTable *XRefTable) bindNameTreeNode(name string, n *Node, root bool) er
 // BindNameTrees syncs up the internal name tree cache with the xreftable.
 func (xRefTable *XRefTable) BindNameTrees() error {
 
-	log.Write.Println("BindNameTrees..")
+	xRefTable.Log.Write().Println("BindNameTrees..")
 
 	// Iterate over internal name tree rep.
 	for k, v := range xRefTable.Names {
-		log.Write.Printf("bindNameTree: %s\n", k)
+		xRefTable.Log.Write().Printf("bindNameTree: %s\n", k)
 		if err := xRefTable.bindNameTreeNode(k, v, true); err != nil {
 			return err
 		}
@@ -1370,8 +1474,7 @@ func (xRefTable *XRefTable) IDFirstElement() (id []byte, err error) {
 		return nil, errors.New("pdfcpu: ID must contain hex literals or string literals")
 	}
 
-	//return Unescape(sl.Value())
-	return []byte(sl), nil
+	return Unescape(sl.Value())
 }
 
 // InheritedPageAttrs represents all inherited page attributes.
@@ -1436,7 +1539,7 @@ func (xRefTable *XRefTable) consolidateResources(obj Object, pAttrs *InheritedPa
 			}
 			pAttrs.resources[k] = o.Clone()
 		}
-		log.Write.Printf("pA:\n%s\n", pAttrs.resources)
+		xRefTable.Log.Write().Printf("pA:\n%s\n", pAttrs.resources)
 		return nil
 	}
 
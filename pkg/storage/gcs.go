@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"path"
+	"time"
+)
+
+// GCSClient is the subset of a Google Cloud Storage client used by GCS.
+type GCSClient interface {
+	// NewRangeReader returns a reader for n bytes of object starting at off.
+	NewRangeReader(ctx context.Context, bucket, object string, off, n int64) (io.ReadCloser, error)
+
+	// NewWriter returns a writer uploading object, the upload completes on Close.
+	// Canceling ctx aborts the upload.
+	NewWriter(ctx context.Context, bucket, object string) io.WriteCloser
+
+	// Attrs returns size and modification time of object.
+	Attrs(ctx context.Context, bucket, object string) (size int64, updated time.Time, err error)
+}
+
+// gcsStorage is a Storage backed by a Cloud Storage bucket.
+type gcsStorage struct {
+	client GCSClient
+	bucket string
+	prefix string
+}
+
+// GCS returns a Storage for the objects of bucket with names starting with prefix.
+func GCS(client GCSClient, bucket, prefix string) Storage {
+	return &gcsStorage{client: client, bucket: bucket, prefix: prefix}
+}
+
+func (s *gcsStorage) object(name string) string {
+	return path.Join(s.prefix, name)
+}
+
+func (s *gcsStorage) Open(name string) (io.ReadSeekCloser, error) {
+	fi, err := s.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	object := s.object(name)
+	return newRangeReader(fi.Size(), func(off, n int64) (io.ReadCloser, error) {
+		return s.client.NewRangeReader(context.Background(), s.bucket, object, off, n)
+	}), nil
+}
+
+func (s *gcsStorage) Create(name string) (io.WriteCloser, error) {
+	c, cancel := context.WithCancel(context.Background())
+	return &gcsWriter{WriteCloser: s.client.NewWriter(c, s.bucket, s.object(name)), cancel: cancel}, nil
+}
+
+// gcsWriter is an object upload to a Cloud Storage bucket.
+type gcsWriter struct {
+	io.WriteCloser
+	cancel context.CancelFunc
+}
+
+func (w *gcsWriter) Close() error {
+	defer w.cancel()
+	return w.WriteCloser.Close()
+}
+
+// CloseWithError aborts the upload.
+func (w *gcsWriter) CloseWithError(err error) error {
+	w.cancel()
+	w.WriteCloser.Close()
+	return err
+}
+
+func (s *gcsStorage) Stat(name string) (fs.FileInfo, error) {
+	size, updated, err := s.client.Attrs(context.Background(), s.bucket, s.object(name))
+	if err != nil {
+		return nil, err
+	}
+	return objectInfo{name: path.Base(name), size: size, modTime: updated}, nil
+}
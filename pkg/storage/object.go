@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ChunkSize is the number of bytes fetched per ranged read from object storage.
+var ChunkSize int64 = 1 << 20
+
+// rangeReader is an io.ReadSeekCloser for an object of known size fetching chunks by ranged reads.
+// Reading a PDF file touches the end of the file first, then the cross reference data and finally the objects,
+// so only the chunks actually needed get downloaded.
+type rangeReader struct {
+	size   int64
+	off    int64
+	fetch  func(off, n int64) (io.ReadCloser, error)
+	buf    []byte
+	bufOff int64
+}
+
+func newRangeReader(size int64, fetch func(off, n int64) (io.ReadCloser, error)) *rangeReader {
+	return &rangeReader{size: size, fetch: fetch}
+}
+
+func (rr *rangeReader) load() error {
+	n := ChunkSize
+	if rr.off+n > rr.size {
+		n = rr.size - rr.off
+	}
+	rc, err := rr.fetch(rr.off, n)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(rc, buf); err != nil {
+		return err
+	}
+	rr.buf, rr.bufOff = buf, rr.off
+	return nil
+}
+
+func (rr *rangeReader) Read(p []byte) (int, error) {
+	if rr.off >= rr.size {
+		return 0, io.EOF
+	}
+	if rr.off < rr.bufOff || rr.off >= rr.bufOff+int64(len(rr.buf)) {
+		if err := rr.load(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, rr.buf[rr.off-rr.bufOff:])
+	rr.off += int64(n)
+	return n, nil
+}
+
+func (rr *rangeReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += rr.off
+	case io.SeekEnd:
+		offset += rr.size
+	default:
+		return 0, errors.New("pdfcpu: storage: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("pdfcpu: storage: negative position")
+	}
+	rr.off = offset
+	return offset, nil
+}
+
+func (rr *rangeReader) Close() error {
+	rr.buf = nil
+	return nil
+}
+
+// pipeWriter streams the data written into an upload running concurrently.
+type pipeWriter struct {
+	*io.PipeWriter
+	done chan error
+}
+
+func newPipeWriter(upload func(r io.Reader) error) *pipeWriter {
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := upload(pr)
+		pr.CloseWithError(err)
+		done <- err
+	}()
+	return &pipeWriter{PipeWriter: pw, done: done}
+}
+
+// Close finishes the upload and returns its result.
+func (pw *pipeWriter) Close() error {
+	if err := pw.PipeWriter.Close(); err != nil {
+		return err
+	}
+	return <-pw.done
+}
+
+// CloseWithError aborts the upload.
+func (pw *pipeWriter) CloseWithError(err error) error {
+	pw.PipeWriter.CloseWithError(err)
+	<-pw.done
+	return err
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"path"
+	"time"
+)
+
+// S3Client is the subset of an Amazon S3 client used by S3.
+type S3Client interface {
+	// GetObject returns n bytes of the object key starting at off.
+	GetObject(ctx context.Context, bucket, key string, off, n int64) (io.ReadCloser, error)
+
+	// PutObject uploads the object key reading its content from body, eg. using an upload manager.
+	// The upload must fail without storing the object if reading body fails.
+	PutObject(ctx context.Context, bucket, key string, body io.Reader) error
+
+	// HeadObject returns size and modification time of the object key.
+	HeadObject(ctx context.Context, bucket, key string) (size int64, lastModified time.Time, err error)
+}
+
+// s3Storage is a Storage backed by an S3 bucket.
+type s3Storage struct {
+	client S3Client
+	bucket string
+	prefix string
+}
+
+// S3 returns a Storage for the objects of bucket with keys starting with prefix.
+func S3(client S3Client, bucket, prefix string) Storage {
+	return &s3Storage{client: client, bucket: bucket, prefix: prefix}
+}
+
+func (s *s3Storage) key(name string) string {
+	return path.Join(s.prefix, name)
+}
+
+func (s *s3Storage) Open(name string) (io.ReadSeekCloser, error) {
+	fi, err := s.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	key := s.key(name)
+	return newRangeReader(fi.Size(), func(off, n int64) (io.ReadCloser, error) {
+		return s.client.GetObject(context.Background(), s.bucket, key, off, n)
+	}), nil
+}
+
+func (s *s3Storage) Create(name string) (io.WriteCloser, error) {
+	key := s.key(name)
+	return newPipeWriter(func(r io.Reader) error {
+		return s.client.PutObject(context.Background(), s.bucket, key, r)
+	}), nil
+}
+
+func (s *s3Storage) Stat(name string) (fs.FileInfo, error) {
+	size, modTime, err := s.client.HeadObject(context.Background(), s.bucket, s.key(name))
+	if err != nil {
+		return nil, err
+	}
+	return objectInfo{name: path.Base(name), size: size, modTime: modTime}, nil
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package storage provides storage adapters for reading and writing PDF files
+// from local directories or object storage like Amazon S3 and Google Cloud Storage.
+//
+// The object storage adapters depend on small client interfaces instead of vendor SDKs.
+// Wire them up using a thin wrapper around the SDK client of your choice, eg. for aws-sdk-go-v2:
+//
+//	func (c s3Client) GetObject(ctx context.Context, bucket, key string, off, n int64) (io.ReadCloser, error) {
+//		out, err := c.client.GetObject(ctx, &s3.GetObjectInput{
+//			Bucket: &bucket,
+//			Key:    &key,
+//			Range:  aws.String(fmt.Sprintf("bytes=%d-%d", off, off+n-1)),
+//		})
+//		if err != nil {
+//			return nil, err
+//		}
+//		return out.Body, nil
+//	}
+package storage
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Storage provides access to named files.
+// Names are slash separated paths as defined by io/fs.
+// Implementations return errors wrapping fs.ErrNotExist for missing files.
+type Storage interface {
+	// Open opens name for reading.
+	Open(name string) (io.ReadSeekCloser, error)
+
+	// Create creates or truncates name for writing.
+	// The file is complete once Close returned without error.
+	Create(name string) (io.WriteCloser, error)
+
+	// Stat returns the file info for name.
+	Stat(name string) (fs.FileInfo, error)
+}
+
+// ErrorCloser is implemented by the writers returned by Create.
+// CloseWithError aborts an unfinished write, so no partial file gets stored under its name.
+type ErrorCloser interface {
+	CloseWithError(err error) error
+}
+
+// dir is a Storage rooted at a local directory.
+type dir string
+
+// Dir returns a Storage for the local directory root.
+func Dir(root string) Storage {
+	return dir(root)
+}
+
+func (d dir) path(name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", errors.Wrapf(fs.ErrInvalid, "pdfcpu: storage: invalid name: %s", name)
+	}
+	return filepath.Join(string(d), filepath.FromSlash(name)), nil
+}
+
+func (d dir) Open(name string) (io.ReadSeekCloser, error) {
+	fn, err := d.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(fn)
+}
+
+func (d dir) Create(name string) (io.WriteCloser, error) {
+	fn, err := d.path(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(fn), os.ModePerm); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(fn)
+	if err != nil {
+		return nil, err
+	}
+	return dirFile{f}, nil
+}
+
+// dirFile is a file of a dir being written.
+type dirFile struct {
+	*os.File
+}
+
+// CloseWithError closes and removes the partially written file.
+func (f dirFile) CloseWithError(err error) error {
+	f.File.Close()
+	os.Remove(f.Name())
+	return err
+}
+
+func (d dir) Stat(name string) (fs.FileInfo, error) {
+	fn, err := d.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(fn)
+}
+
+// fsys adapts a Storage to fs.FS.
+type fsys struct {
+	s Storage
+}
+
+// FS returns s as fs.FS, eg. for resolving external streams via Configuration.FileSystem.
+func FS(s Storage) fs.FS {
+	return fsys{s}
+}
+
+type fsFile struct {
+	io.ReadSeekCloser
+	fi fs.FileInfo
+}
+
+func (f fsFile) Stat() (fs.FileInfo, error) {
+	return f.fi, nil
+}
+
+func (f fsys) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	fi, err := f.s.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	rsc, err := f.s.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return fsFile{rsc, fi}, nil
+}
+
+// objectInfo is the fs.FileInfo of an object in a bucket.
+type objectInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (oi objectInfo) Name() string       { return oi.name }
+func (oi objectInfo) Size() int64        { return oi.size }
+func (oi objectInfo) Mode() fs.FileMode  { return 0444 }
+func (oi objectInfo) ModTime() time.Time { return oi.modTime }
+func (oi objectInfo) IsDir() bool        { return false }
+func (oi objectInfo) Sys() interface{}   { return nil }
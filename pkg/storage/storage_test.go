@@ -0,0 +1,208 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// bucket is an in-memory object store implementing S3Client and GCSClient.
+type bucket struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	gets    int
+}
+
+func newBucket() *bucket {
+	return &bucket{objects: map[string][]byte{}}
+}
+
+func (b *bucket) object(name string) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	bb, ok := b.objects[name]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return bb, nil
+}
+
+func (b *bucket) put(name string, r io.Reader) error {
+	bb, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects[name] = bb
+	return nil
+}
+
+func (b *bucket) rangeReader(name string, off, n int64) (io.ReadCloser, error) {
+	bb, err := b.object(name)
+	if err != nil {
+		return nil, err
+	}
+	b.gets++
+	return ioutil.NopCloser(bytes.NewReader(bb[off : off+n])), nil
+}
+
+func (b *bucket) GetObject(ctx context.Context, bucket, key string, off, n int64) (io.ReadCloser, error) {
+	return b.rangeReader(bucket+"/"+key, off, n)
+}
+
+func (b *bucket) PutObject(ctx context.Context, bucket, key string, body io.Reader) error {
+	return b.put(bucket+"/"+key, body)
+}
+
+func (b *bucket) HeadObject(ctx context.Context, bucket, key string) (int64, time.Time, error) {
+	bb, err := b.object(bucket + "/" + key)
+	return int64(len(bb)), time.Time{}, err
+}
+
+func (b *bucket) NewRangeReader(ctx context.Context, bucket, object string, off, n int64) (io.ReadCloser, error) {
+	return b.rangeReader(bucket+"/"+object, off, n)
+}
+
+type bucketWriter struct {
+	bytes.Buffer
+	ctx  context.Context
+	b    *bucket
+	name string
+}
+
+func (w *bucketWriter) Close() error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	return w.b.put(w.name, &w.Buffer)
+}
+
+func (b *bucket) NewWriter(ctx context.Context, bucket, object string) io.WriteCloser {
+	return &bucketWriter{ctx: ctx, b: b, name: bucket + "/" + object}
+}
+
+func (b *bucket) Attrs(ctx context.Context, bucket, object string) (int64, time.Time, error) {
+	return b.HeadObject(ctx, bucket, object)
+}
+
+func testStorage(t *testing.T, s Storage) {
+	t.Helper()
+
+	data := bytes.Repeat([]byte("0123456789"), 100)
+
+	w, err := s.Create("dir/file.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := s.Stat("dir/file.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Size() != int64(len(data)) || fi.Name() != "file.pdf" {
+		t.Fatalf("Stat: got %s with %d bytes\n", fi.Name(), fi.Size())
+	}
+
+	f, err := s.Open("dir/file.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(-15, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 10)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "5678901234" {
+		t.Fatalf("read at end: got %s\n", buf)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	bb, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(bb, data) {
+		t.Fatalf("read all: content differs\n")
+	}
+
+	bb, err = fs.ReadFile(FS(s), "dir/file.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(bb, data) {
+		t.Fatalf("fs.ReadFile: content differs\n")
+	}
+
+	if _, err := s.Open("missing.pdf"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Open missing file: want fs.ErrNotExist, got %v\n", err)
+	}
+
+	w, err = s.Create("dir/aborted.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	abortErr := errors.New("write failed")
+	if err := w.(ErrorCloser).CloseWithError(abortErr); err != abortErr {
+		t.Fatalf("CloseWithError: want %v, got %v\n", abortErr, err)
+	}
+	if _, err := s.Stat("dir/aborted.pdf"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Stat aborted file: want fs.ErrNotExist, got %v\n", err)
+	}
+}
+
+func TestStorage(t *testing.T) {
+	defer func(n int64) { ChunkSize = n }(ChunkSize)
+	ChunkSize = 64
+
+	testStorage(t, Dir(t.TempDir()))
+
+	b := newBucket()
+	testStorage(t, S3(b, "s3bucket", "prefix"))
+	if b.gets < 2 {
+		t.Fatalf("S3: want ranged reads, got %d reads\n", b.gets)
+	}
+	if _, ok := b.objects["s3bucket/prefix/dir/file.pdf"]; !ok {
+		t.Fatalf("S3: missing object\n")
+	}
+
+	testStorage(t, GCS(newBucket(), "gcsbucket", ""))
+}
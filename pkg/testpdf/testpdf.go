@@ -0,0 +1,316 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testpdf synthesizes minimal valid PDF files for unit testing
+// so there is no need to commit binary fixtures.
+//
+//	rs, err := testpdf.Reader(
+//		testpdf.Page{Text: "Hello"},
+//		testpdf.Page{MediaBox: pdfcpu.RectForFormat("Letter"), Images: []testpdf.Image{{Width: 10, Height: 10}}},
+//	)
+package testpdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultFont     = "Helvetica"
+	defaultFontSize = 12
+	margin          = 36
+)
+
+// Image describes a uniformly colored DeviceRGB image.
+type Image struct {
+	Width, Height int                // Dimensions in pixels.
+	Color         pdfcpu.SimpleColor // Fill color.
+	Rect          *pdfcpu.Rectangle  // Placement in user space, defaults to Width x Height at the lower left corner of the media box.
+}
+
+// Page describes a page to be synthesized.
+type Page struct {
+	MediaBox *pdfcpu.Rectangle // Defaults to A4.
+	CropBox  *pdfcpu.Rectangle // Optional page boundaries.
+	BleedBox *pdfcpu.Rectangle
+	TrimBox  *pdfcpu.Rectangle
+	ArtBox   *pdfcpu.Rectangle
+	Rotate   int     // A multiple of 90.
+	Text     string  // Optional text rendered at the upper left corner, may contain \n.
+	Font     string  // Core font used for Text, defaults to Helvetica.
+	FontSize int     // Defaults to 12.
+	Images   []Image // Images rendered onto this page.
+}
+
+// Pages returns n A4 pages each displaying its page number.
+func Pages(n int) []Page {
+	pp := make([]Page, n)
+	for i := range pp {
+		pp[i].Text = fmt.Sprintf("Page %d", i+1)
+	}
+	return pp
+}
+
+func coreFontDict(fontName string) pdfcpu.Dict {
+	d := pdfcpu.NewDict()
+	d.InsertName("Type", "Font")
+	d.InsertName("Subtype", "Type1")
+	d.InsertName("BaseFont", fontName)
+	if fontName != "Symbol" && fontName != "ZapfDingbats" {
+		d.InsertName("Encoding", "WinAnsiEncoding")
+	}
+	return d
+}
+
+func imageBytes(img Image) []byte {
+	px := []byte{byte(img.Color.R * 255), byte(img.Color.G * 255), byte(img.Color.B * 255)}
+	return bytes.Repeat(px, img.Width*img.Height)
+}
+
+func createImage(xRefTable *pdfcpu.XRefTable, img Image) (*pdfcpu.IndirectRef, error) {
+	if img.Width <= 0 || img.Height <= 0 {
+		return nil, errors.Errorf("pdfcpu: testpdf: invalid image dimensions %dx%d", img.Width, img.Height)
+	}
+
+	sd, err := xRefTable.NewStreamDictForBuf(imageBytes(img))
+	if err != nil {
+		return nil, err
+	}
+	sd.InsertName("Type", "XObject")
+	sd.InsertName("Subtype", "Image")
+	sd.InsertInt("Width", img.Width)
+	sd.InsertInt("Height", img.Height)
+	sd.InsertName("ColorSpace", pdfcpu.DeviceRGBCS)
+	sd.InsertInt("BitsPerComponent", 8)
+
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+
+	return xRefTable.IndRefForNewObject(*sd)
+}
+
+func renderText(p Page, mediaBox *pdfcpu.Rectangle, w io.Writer, fm pdfcpu.FontMap) error {
+	fontName := p.Font
+	if fontName == "" {
+		fontName = defaultFont
+	}
+	if !font.IsCoreFont(fontName) {
+		return errors.Errorf("pdfcpu: testpdf: %s is not a core font", fontName)
+	}
+
+	fontSize := p.FontSize
+	if fontSize <= 0 {
+		fontSize = defaultFontSize
+	}
+
+	td := pdfcpu.TextDescriptor{
+		Text:     p.Text,
+		FontName: fontName,
+		FontKey:  fm.EnsureKey(fontName),
+		FontSize: fontSize,
+		Scale:    1.,
+		ScaleAbs: true,
+		X:        mediaBox.LL.X + margin,
+		Y:        mediaBox.UR.Y - margin,
+	}
+
+	pdfcpu.WriteMultiLine(w, mediaBox, nil, td)
+
+	return nil
+}
+
+func renderImages(xRefTable *pdfcpu.XRefTable, p Page, mediaBox *pdfcpu.Rectangle, w io.Writer) (pdfcpu.Dict, error) {
+	d := pdfcpu.NewDict()
+
+	for i, img := range p.Images {
+		ir, err := createImage(xRefTable, img)
+		if err != nil {
+			return nil, err
+		}
+		id := fmt.Sprintf("Im%d", i)
+		d.Insert(id, *ir)
+
+		r := img.Rect
+		if r == nil {
+			r = pdfcpu.RectForWidthAndHeight(mediaBox.LL.X, mediaBox.LL.Y, float64(img.Width), float64(img.Height))
+		}
+		fmt.Fprintf(w, "q %.2f 0 0 %.2f %.2f %.2f cm /%s Do Q ", r.Width(), r.Height(), r.LL.X, r.LL.Y, id)
+	}
+
+	return d, nil
+}
+
+func createPage(xRefTable *pdfcpu.XRefTable, parent pdfcpu.IndirectRef, p Page) (*pdfcpu.IndirectRef, error) {
+	if p.Rotate%90 != 0 {
+		return nil, errors.Errorf("pdfcpu: testpdf: invalid rotation %d", p.Rotate)
+	}
+
+	mediaBox := p.MediaBox
+	if mediaBox == nil {
+		mediaBox = pdfcpu.RectForFormat("A4")
+	}
+
+	pageDict := pdfcpu.Dict(map[string]pdfcpu.Object{
+		"Type":     pdfcpu.Name("Page"),
+		"Parent":   parent,
+		"MediaBox": mediaBox.Array(),
+	})
+
+	for k, r := range map[string]*pdfcpu.Rectangle{"CropBox": p.CropBox, "BleedBox": p.BleedBox, "TrimBox": p.TrimBox, "ArtBox": p.ArtBox} {
+		if r != nil {
+			pageDict.Insert(k, r.Array())
+		}
+	}
+
+	if p.Rotate != 0 {
+		pageDict.InsertInt("Rotate", p.Rotate)
+	}
+
+	var buf bytes.Buffer
+	resDict := pdfcpu.NewDict()
+
+	imgDict, err := renderImages(xRefTable, p, mediaBox, &buf)
+	if err != nil {
+		return nil, err
+	}
+	if imgDict.Len() > 0 {
+		resDict.Insert("XObject", imgDict)
+	}
+
+	if p.Text != "" {
+		fm := pdfcpu.FontMap{}
+		if err := renderText(p, mediaBox, &buf, fm); err != nil {
+			return nil, err
+		}
+		fontDict := pdfcpu.NewDict()
+		for k, fontName := range fm {
+			ir, err := xRefTable.IndRefForNewObject(coreFontDict(fontName))
+			if err != nil {
+				return nil, err
+			}
+			fontDict.Insert(k, *ir)
+		}
+		resDict.Insert("Font", fontDict)
+	}
+
+	if resDict.Len() > 0 {
+		pageDict.Insert("Resources", resDict)
+	}
+
+	sd, err := xRefTable.NewStreamDictForBuf(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+	ir, err := xRefTable.IndRefForNewObject(*sd)
+	if err != nil {
+		return nil, err
+	}
+	pageDict.Insert("Contents", *ir)
+
+	return xRefTable.IndRefForNewObject(pageDict)
+}
+
+// Context returns a context for a new PDF document made up of pages.
+func Context(pages ...Page) (*pdfcpu.Context, error) {
+	if len(pages) == 0 {
+		return nil, errors.New("pdfcpu: testpdf: need at least one page")
+	}
+
+	ctx, err := pdfcpu.CreateContextWithXRefTable(nil, pdfcpu.PaperSize["A4"])
+	if err != nil {
+		return nil, err
+	}
+
+	pagesIndRef, err := ctx.Pages()
+	if err != nil {
+		return nil, err
+	}
+
+	pagesDict, err := ctx.DereferenceDict(*pagesIndRef)
+	if err != nil {
+		return nil, err
+	}
+
+	kids := pdfcpu.Array{}
+	for _, p := range pages {
+		ir, err := createPage(ctx.XRefTable, *pagesIndRef, p)
+		if err != nil {
+			return nil, err
+		}
+		kids = append(kids, *ir)
+	}
+
+	pagesDict.Update("Kids", kids)
+	pagesDict.Update("Count", pdfcpu.Integer(len(kids)))
+	ctx.PageCount = len(kids)
+
+	return ctx, nil
+}
+
+// Bytes returns a new PDF document made up of pages.
+func Bytes(pages ...Page) ([]byte, error) {
+	ctx, err := Context(pages...)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := api.WriteContext(ctx, &buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Reader returns a new PDF document made up of pages.
+func Reader(pages ...Page) (io.ReadSeeker, error) {
+	bb, err := Bytes(pages...)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(bb), nil
+}
+
+// File writes a new PDF document made up of pages to outFile.
+func File(outFile string, pages ...Page) error {
+	ctx, err := Context(pages...)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(outFile)
+	if err != nil {
+		return err
+	}
+
+	if err := api.WriteContext(ctx, f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testpdf
+
+import (
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestReader(t *testing.T) {
+	msg := "TestReader"
+
+	pages := append(Pages(2), Page{
+		MediaBox: pdfcpu.RectForFormat("Letter"),
+		CropBox:  pdfcpu.Rect(10, 10, 500, 700),
+		Rotate:   90,
+		Text:     "Courier\nsecond line",
+		Font:     "Courier",
+		Images: []Image{
+			{Width: 4, Height: 4, Color: pdfcpu.SimpleColor{R: 1}},
+			{Width: 2, Height: 8, Rect: pdfcpu.Rect(100, 100, 200, 300)},
+		},
+	})
+
+	rs, err := Reader(pages...)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.Validate(rs, nil); err != nil {
+		t.Fatalf("%s validate: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContext(rs, pdfcpu.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("%s read: %v\n", msg, err)
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ctx.PageCount != 3 {
+		t.Fatalf("%s: pageCount: got %d, want 3\n", msg, ctx.PageCount)
+	}
+
+	d, _, _, err := ctx.PageDict(3, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if r := d.IntEntry("Rotate"); r == nil || *r != 90 {
+		t.Errorf("%s: missing rotation\n", msg)
+	}
+	if _, found := d.Find("CropBox"); !found {
+		t.Errorf("%s: missing cropBox\n", msg)
+	}
+
+	resDict, err := ctx.DereferenceDict(d["Resources"])
+	if err != nil || resDict == nil {
+		t.Fatalf("%s: missing resources\n", msg)
+	}
+	if imgs := resDict.DictEntry("XObject"); imgs.Len() != 2 {
+		t.Errorf("%s: images: got %d, want 2\n", msg, imgs.Len())
+	}
+}
+
+func TestInvalidPage(t *testing.T) {
+	for _, p := range []Page{
+		{Rotate: 45},
+		{Text: "x", Font: "Unknown"},
+		{Images: []Image{{Width: 0, Height: 1}}},
+	} {
+		if _, err := Bytes(p); err == nil {
+			t.Errorf("TestInvalidPage: missing error for %+v\n", p)
+		}
+	}
+
+	if _, err := Bytes(); err == nil {
+		t.Errorf("TestInvalidPage: missing error for zero pages\n")
+	}
+}